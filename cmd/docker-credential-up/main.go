@@ -19,10 +19,13 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 
 	"github.com/docker/docker-credential-helpers/credentials"
 
+	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/credhelper"
+	"github.com/upbound/up/internal/tokenstore"
 	"github.com/upbound/up/internal/version"
 )
 
@@ -55,10 +58,15 @@ func main() {
 		domain = u.Hostname()
 	}
 
-	// Build credential helper and defer execution to Docker.
-	h := credhelper.New(
+	opts := []credhelper.Opt{
 		credhelper.WithDomain(domain),
 		credhelper.WithProfile(os.Getenv(profileEnv)),
-	)
+	}
+	if p, err := config.GetDefaultPath(); err == nil {
+		opts = append(opts, credhelper.WithTokenStore(tokenstore.Default(filepath.Join(filepath.Dir(p), tokenstore.DefaultFile))))
+	}
+
+	// Build credential helper and defer execution to Docker.
+	h := credhelper.New(opts...)
 	credentials.Serve(h)
 }
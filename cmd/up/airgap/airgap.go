@@ -0,0 +1,62 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package airgap contains commands for moving installs into disconnected
+// environments.
+package airgap
+
+import (
+	"github.com/alecthomas/kong"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/upbound/up/internal/feature"
+	"github.com/upbound/up/internal/install/airgap"
+	"github.com/upbound/up/internal/upbound"
+)
+
+// BeforeReset is the first hook to run.
+func (c *Cmd) BeforeReset(p *kong.Path, maturity feature.Maturity) error {
+	return feature.HideMaturity(p, maturity)
+}
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *Cmd) AfterApply(kongCtx *kong.Context) error {
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	kongCtx.Bind(airgap.New(
+		airgap.WithRemoteOptions(remote.WithAuthFromKeychain(authn.NewMultiKeychain(
+			authn.NewKeychainFromHelper(
+				upCtx.CredentialHelper(c.Flags.Profile),
+			),
+			authn.DefaultKeychain,
+		))),
+		airgap.WithNameOptions(name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname())),
+	))
+	return nil
+}
+
+// Cmd contains commands for air-gapped installs.
+type Cmd struct {
+	Export exportCmd `cmd:"" maturity:"alpha" help:"Bundle charts and the images they need into a single archive."`
+	Import importCmd `cmd:"" maturity:"alpha" help:"Push the images of a bundle to a registry and rewrite chart values to use it."`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
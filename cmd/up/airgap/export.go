@@ -0,0 +1,99 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"context"
+	"os"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/install/airgap"
+	"github.com/upbound/up/internal/install/uxp"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+)
+
+// exportCmd bundles charts and images.
+type exportCmd struct {
+	Output   string            `short:"o" type:"path" default:"bundle.tar.gz" help:"Path of the bundle."`
+	Chart    []string          `type:"existingfile" help:"Chart archives to bundle, e.g. of UXP or Spaces. The images their templates reference are bundled too."`
+	Values   []string          `type:"existingfile" help:"Values files used to render the charts, merged in order."`
+	Set      map[string]string `help:"Values used to render the charts."`
+	Package  []string          `help:"Additional images to bundle, such as provider and function packages."`
+	LockFile string            `type:"path" help:"Also bundle the packages pinned in this lock file."`
+}
+
+func (c *exportCmd) Help() string {
+	return `
+The export command bundles charts together with every image they need into a
+single archive that can be carried into a disconnected environment. Images are
+found by rendering the charts with the supplied values. Provider and function
+packages are bundled with --package or --lock-file:
+
+  up alpha airgap export --chart universal-crossplane-1.14.0-up.1.tgz \
+    --package xpkg.upbound.io/upbound/provider-aws-s3:v0.47.0 -o uxp.tar.gz
+`
+}
+
+// Run executes the export command.
+func (c *exportCmd) Run(ctx context.Context, p pterm.TextPrinter, b *airgap.Bundler) error {
+	fs := afero.NewOsFs()
+	values, err := uxp.Values(fs, c.Values, c.Set)
+	if err != nil {
+		return err
+	}
+	charts := make([]airgap.Chart, len(c.Chart))
+	for i, ch := range c.Chart {
+		charts[i] = airgap.Chart{Path: ch, Values: values}
+	}
+	images := append([]string{}, c.Package...)
+	if c.LockFile != "" {
+		l, err := lock.Read(fs, c.LockFile)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range l.Packages {
+			images = append(images, pkg.Package+"@"+pkg.Digest)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "up-airgap-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck // best effort clean up
+
+	m, err := b.Export(ctx, dir, charts, images)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(c.Output)
+	if err != nil {
+		return err
+	}
+	if err := airgap.Pack(dir, f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	for _, i := range m.Images {
+		p.Printfln("bundled %s", i.Reference)
+	}
+	p.Printfln("bundle written to %s", c.Output)
+	return nil
+}
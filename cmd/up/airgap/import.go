@@ -0,0 +1,119 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/internal/install/airgap"
+	"github.com/upbound/up/internal/install/uxp"
+)
+
+// importCmd pushes the images of a bundle to a registry.
+type importCmd struct {
+	Bundle   string `arg:"" type:"existingfile" help:"Path of the bundle."`
+	Registry string `arg:"" help:"Registry to push to, optionally with a path prefix, e.g. registry.example.org/upbound."`
+
+	Output string            `short:"o" type:"path" default:"." help:"Directory the bundled charts and their rewritten values are written to."`
+	Values []string          `type:"existingfile" help:"Values files the charts will be installed with, merged in order."`
+	Set    map[string]string `help:"Values the charts will be installed with."`
+}
+
+func (c *importCmd) Help() string {
+	return `
+The import command pushes every image of a bundle to a registry, keeping the
+repository path and tag of each image. The bundled charts are written to the
+output directory together with a values file per chart that points the chart
+at the registry. Install with that values file, e.g.:
+
+  up alpha airgap import uxp.tar.gz registry.example.org/upbound
+  up uxp install --bundle universal-crossplane-1.14.0-up.1.tgz \
+    --values universal-crossplane-values.yaml
+`
+}
+
+// Run executes the import command.
+func (c *importCmd) Run(ctx context.Context, p pterm.TextPrinter, b *airgap.Bundler) error {
+	values, err := uxp.Values(afero.NewOsFs(), c.Values, c.Set)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "up-airgap-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck // best effort clean up
+
+	f, err := os.Open(c.Bundle)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read only
+	if err := airgap.Unpack(f, dir); err != nil {
+		return err
+	}
+	m, err := airgap.ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	mappings, err := b.Import(ctx, dir, c.Registry)
+	if err != nil {
+		return err
+	}
+	for _, mp := range mappings {
+		p.Printfln("pushed %s to %s", mp.Source, mp.Destination)
+	}
+
+	if err := os.MkdirAll(c.Output, 0o755); err != nil {
+		return err
+	}
+	for _, path := range m.ChartPaths(dir) {
+		ch, err := loader.Load(path)
+		if err != nil {
+			return err
+		}
+		rewritten, err := airgap.RewriteValues(ch, values, mappings)
+		if err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(rewritten)
+		if err != nil {
+			return err
+		}
+		vf := filepath.Join(c.Output, fmt.Sprintf("%s-values.yaml", ch.Name()))
+		if err := os.WriteFile(vf, out, 0o644); err != nil { //nolint:gosec // values are not sensitive
+			return err
+		}
+		b, err := os.ReadFile(path) //nolint:gosec // reading the bundled chart is intended
+		if err != nil {
+			return err
+		}
+		cf := filepath.Join(c.Output, filepath.Base(path))
+		if err := os.WriteFile(cf, b, 0o644); err != nil { //nolint:gosec // charts are not sensitive
+			return err
+		}
+		p.Printfln("chart %s written with values %s", cf, vf)
+	}
+	return nil
+}
@@ -56,7 +56,7 @@ type createCmd struct {
 }
 
 // Run executes the create command.
-func (c *createCmd) Run(p pterm.TextPrinter, cc *configurations.Client, gc *gitsources.Client, upCtx *upbound.Context) error {
+func (c *createCmd) Run(ctx context.Context, p pterm.TextPrinter, cc *configurations.Client, gc *gitsources.Client, upCtx *upbound.Context) error {
 	if upCtx.Profile.IsSpace() {
 		return fmt.Errorf("create is not supported for Spaces profile %q", upCtx.ProfileName)
 	}
@@ -71,17 +71,17 @@ func (c *createCmd) Run(p pterm.TextPrinter, cc *configurations.Client, gc *gits
 	}
 
 	// Step 1: Authorize and install the GitHub app, if it needs to be installed.
-	err := c.handleLogin(gc, upCtx)
+	err := c.handleLogin(ctx, gc, upCtx)
 	if err != nil {
 		return err
 	}
 
 	// Step 2: Create the configuration
-	return c.handleCreate(cc, upCtx)
+	return c.handleCreate(ctx, cc, upCtx)
 }
 
 // handleLogin uses the gitsources login API to authorize and install the GitHub app
-func (c *createCmd) handleLogin(gc *gitsources.Client, upCtx *upbound.Context) error { //nolint:gocyclo
+func (c *createCmd) handleLogin(ctx context.Context, gc *gitsources.Client, upCtx *upbound.Context) error { //nolint:gocyclo
 	s := authServer{
 		debugLevel: upCtx.DebugLevel,
 		session:    upCtx.Profile.Session,
@@ -93,7 +93,7 @@ func (c *createCmd) handleLogin(gc *gitsources.Client, upCtx *upbound.Context) e
 	}
 	defer s.shutdown() //nolint:errcheck
 
-	r, err := gc.Login(context.Background(), port)
+	r, err := gc.Login(ctx, port)
 	if err != nil {
 		return err
 	}
@@ -139,7 +139,7 @@ func (c *createCmd) handleLogin(gc *gitsources.Client, upCtx *upbound.Context) e
 }
 
 // handleCreate will create the configuration.
-func (c *createCmd) handleCreate(cc *configurations.Client, upCtx *upbound.Context) error {
+func (c *createCmd) handleCreate(ctx context.Context, cc *configurations.Client, upCtx *upbound.Context) error {
 	params := configurations.ConfigurationCreateParameters{
 		Name:       c.Name,
 		Context:    c.Context,
@@ -148,7 +148,7 @@ func (c *createCmd) handleCreate(cc *configurations.Client, upCtx *upbound.Conte
 		Repo:       c.Repo,
 		Private:    c.Private,
 	}
-	_, err := cc.Create(context.Background(), upCtx.Account, &params)
+	_, err := cc.Create(ctx, upCtx.Account, &params)
 	return err
 }
 
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
 
 	"k8s.io/client-go/dynamic"
@@ -36,6 +37,7 @@ import (
 	"github.com/upbound/up/internal/controlplane/cloud"
 	"github.com/upbound/up/internal/controlplane/space"
 	"github.com/upbound/up/internal/kube"
+	"github.com/upbound/up/internal/tokenstore"
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/upterm"
 )
@@ -51,11 +53,11 @@ type ctpConnector interface {
 }
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *connectCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+func (c *connectCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context, log logging.Logger) error {
 	c.stdin = os.Stdin
 
 	if upCtx.Profile.IsSpace() {
-		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		kubeconfig, err := upCtx.GetKubeClientConfig()
 		if err != nil {
 			return err
 		}
@@ -63,12 +65,12 @@ func (c *connectCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) e
 		if err != nil {
 			return err
 		}
-		c.client = space.New(client)
-	} else {
-		if c.Token == "" {
-			return fmt.Errorf("--token must be specified")
+		caps, err := upCtx.Capabilities(context.Background())
+		if err != nil {
+			log.Debug("Cannot negotiate Space capabilities", "error", err)
 		}
-
+		c.client = space.New(client, space.WithLogger(log), space.WithTelemetry(upCtx.Telemetry), space.WithCapabilities(caps))
+	} else {
 		if c.Token == "-" {
 			b, err := io.ReadAll(c.stdin)
 			if err != nil {
@@ -85,13 +87,18 @@ func (c *connectCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) e
 		cfgclient := configurations.NewClient(cfg)
 
 		// The cloud client needs the proxy endpoint and a PAT token for
-		// setting up communication with Upbound Cloud.
+		// setting up communication with Upbound Cloud. If no token is
+		// supplied, the one stored when logging in with a PAT is used.
 		c.client = cloud.New(
 			ctpclient,
 			cfgclient,
 			upCtx.Account,
 			cloud.WithToken(c.Token),
+			cloud.WithTokenStore(upCtx.Tokens, tokenstore.TokenKey(upCtx.ProfileName)),
 			cloud.WithProxyEndpoint(upCtx.ProxyEndpoint),
+			cloud.WithClientCertificate(upCtx.HTTP.ClientCertificate),
+			cloud.WithLogger(log),
+			cloud.WithTelemetry(upCtx.Telemetry),
 		)
 	}
 
@@ -102,7 +109,7 @@ func (c *connectCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) e
 // getCmd gets a single control plane in an account on Upbound.
 type connectCmd struct {
 	Name  string `arg:"" required:"" help:"Name of control plane." predictor:"ctps"`
-	Token string `help:"API token used to authenticate. Required for Upbound Cloud; ignored otherwise."`
+	Token string `help:"API token used to authenticate. Defaults to the token stored by 'up login --token' for Upbound Cloud; ignored otherwise."`
 
 	stdin  io.Reader
 	client ctpConnector
@@ -20,17 +20,23 @@ import (
 	"io"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/pterm/pterm"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
 	"sigs.k8s.io/yaml"
 
 	"github.com/upbound/up-sdk-go/service/accounts"
 	"github.com/upbound/up-sdk-go/service/tokens"
 
+	"github.com/upbound/up/internal/controlplane/space"
 	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/connector"
 	"github.com/upbound/up/internal/install/helm"
 	"github.com/upbound/up/internal/kube"
 	"github.com/upbound/up/internal/upbound"
@@ -40,11 +46,20 @@ var (
 	mcpRepoURL = urlMustParse("https://charts.upbound.io/beta")
 )
 
+type ctpKubeconfigGetter interface {
+	GetKubeConfig(ctx context.Context, name string) (*api.Config, error)
+}
+
 const (
 	connectorName = "mcp-connector"
 
 	errReadParametersFile     = "unable to read parameters file"
 	errParseInstallParameters = "unable to parse install parameters"
+	errGetKubeconfig          = "unable to get control plane kubeconfig"
+
+	// secretFmt is the name of the secret the kubeconfig of a Space control
+	// plane is written to.
+	secretFmt = "mcp-kubeconfig-%s"
 )
 
 // AfterApply sets default values in command after assignment and validation.
@@ -69,12 +84,32 @@ func (c *installCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) e
 	if err != nil {
 		return err
 	}
-	c.mgr = mgr
 	client, err := kubernetes.NewForConfig(kubeconfig)
 	if err != nil {
 		return err
 	}
-	c.kClient = client
+	dyn, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	c.connector = connector.New(mgr, client, dyn,
+		connector.WithNamespace(c.InstallationNamespace),
+		connector.WithTimeout(c.Timeout),
+	)
+
+	// In a Space, the kubeconfig of the control plane is read from the Space
+	// and wired into the connector, unless a secret was supplied.
+	if upCtx.Profile.IsSpace() && c.ControlPlaneSecret == "" {
+		spaceConfig, err := upCtx.GetKubeClientConfig()
+		if err != nil {
+			return err
+		}
+		spaceClient, err := dynamic.NewForConfig(spaceConfig)
+		if err != nil {
+			return err
+		}
+		c.ctpClient = space.New(spaceClient, space.WithTelemetry(upCtx.Telemetry))
+	}
 
 	base := map[string]any{}
 	if c.File != nil {
@@ -97,29 +132,30 @@ func (c *installCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) e
 // installCmd connects the current cluster to a control plane in an account on
 // Upbound.
 type installCmd struct {
-	mgr     install.Manager
-	parser  install.ParameterParser
-	kClient kubernetes.Interface
+	connector *connector.Connector
+	parser    install.ParameterParser
+	ctpClient ctpKubeconfigGetter
 
 	Name      string `arg:"" required:"" help:"Name of control plane." predictor:"ctps"`
 	Namespace string `arg:"" required:"" help:"Namespace in the control plane where the claims of the cluster will be stored."`
 
-	Token                 string `help:"API token used to authenticate. If not provided, a new robot and a token will be created."`
-	ClusterName           string `help:"Name of the cluster connecting to the control plane. If not provided, the namespace argument value will be used."`
-	Kubeconfig            string `type:"existingfile" help:"Override the default kubeconfig path."`
-	InstallationNamespace string `short:"n" env:"MCP_CONNECTOR_NAMESPACE" default:"kube-system" help:"Kubernetes namespace for MCP Connector. Default is kube-system."`
-	ControlPlaneSecret    string `help:"Name of the secret that contains the kubeconfig for a control plane."`
+	Token                 string        `help:"API token used to authenticate. If not provided, a new robot and a token will be created."`
+	ClusterName           string        `help:"Name of the cluster connecting to the control plane. If not provided, the namespace argument value will be used."`
+	Kubeconfig            string        `type:"existingfile" help:"Override the default kubeconfig path."`
+	InstallationNamespace string        `short:"n" env:"MCP_CONNECTOR_NAMESPACE" default:"kube-system" help:"Kubernetes namespace for MCP Connector. Default is kube-system."`
+	ControlPlaneSecret    string        `help:"Name of the secret that contains the kubeconfig for a control plane."`
+	Timeout               time.Duration `default:"5m" help:"How long to wait for the connector to become healthy."`
 
 	install.CommonParams
 }
 
 // Run executes the connect command.
-func (c *installCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
 	token := "not defined"
 	var err error
 
 	if !upCtx.Profile.IsSpace() {
-		token, err = c.getToken(p, upCtx)
+		token, err = c.getToken(ctx, p, upCtx)
 		if err != nil {
 			return errors.Wrap(err, "failed to get token")
 		}
@@ -131,33 +167,28 @@ func (c *installCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
 	// Some of these settings are only applicable if pointing to an Upbound
 	// Cloud control plane. We leave them consistent since they won't impact
 	// our ability to point the connector at Space control plane.
-	params["mcp"] = map[string]any{
-		"account":   upCtx.Account,
-		"name":      c.Name,
-		"namespace": c.Namespace,
-		"host":      fmt.Sprintf("%s://%s", upCtx.ProxyEndpoint.Scheme, upCtx.ProxyEndpoint.Host),
-		"token":     token,
-	}
-
-	// If the control-plane-secret has been specified, disable provisioning
-	// the mcp-kubeconfig secret in favor of the supplied secret name.
-	if c.ControlPlaneSecret != "" {
-		v := params["mcp"]
-		param := v.(map[string]any)
-		param["secret"] = map[string]any{
-			"name":      c.ControlPlaneSecret,
-			"provision": false,
+	cfg := connector.Config{
+		Account:      upCtx.Account,
+		ControlPlane: c.Name,
+		Namespace:    c.Namespace,
+		Host:         fmt.Sprintf("%s://%s", upCtx.ProxyEndpoint.Scheme, upCtx.ProxyEndpoint.Host),
+		Token:        token,
+		Secret:       c.ControlPlaneSecret,
+	}
+	if c.ctpClient != nil {
+		kc, err := c.ctpClient.GetKubeConfig(ctx, c.Name)
+		if err != nil {
+			return errors.Wrap(err, errGetKubeconfig)
 		}
-
-		params["mcp"] = param
-	}
-
-	p.Printfln("Installing %s to kube-system. This may take a few minutes.", connectorName)
-	if err = c.mgr.Install("", params); err != nil {
-		return err
+		cfg.Kubeconfig, err = clientcmd.Write(*kc)
+		if err != nil {
+			return errors.Wrap(err, errGetKubeconfig)
+		}
+		cfg.Secret = fmt.Sprintf(secretFmt, c.Name)
 	}
 
-	if _, err = c.mgr.GetCurrentVersion(); err != nil {
+	p.Printfln("Installing %s to %s. This may take a few minutes.", connectorName, c.InstallationNamespace)
+	if err := c.connector.Connect(ctx, cfg, params); err != nil {
 		return err
 	}
 
@@ -166,7 +197,7 @@ func (c *installCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
 	return nil
 }
 
-func (c *installCmd) getToken(p pterm.TextPrinter, upCtx *upbound.Context) (string, error) {
+func (c *installCmd) getToken(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) (string, error) {
 	if c.Token != "" {
 		return c.Token, nil
 	}
@@ -183,13 +214,13 @@ func (c *installCmd) getToken(p pterm.TextPrinter, upCtx *upbound.Context) (stri
 	// This is why this command is currently under alpha because we need to be
 	// able to connect for organizations in a scalable way, i.e. every cluster
 	// should have its own robot account.
-	a, err := accounts.NewClient(cfg).Get(context.Background(), upCtx.Profile.ID)
+	a, err := accounts.NewClient(cfg).Get(ctx, upCtx.Profile.ID)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get account details")
 	}
 	p.Printfln("Creating an API token for the user %s. This token will be "+
 		"used to authenticate the cluster.", a.User.Username)
-	resp, err := tokens.NewClient(cfg).Create(context.Background(), &tokens.TokenCreateParameters{
+	resp, err := tokens.NewClient(cfg).Create(ctx, &tokens.TokenCreateParameters{
 		Attributes: tokens.TokenAttributes{
 			Name: c.ClusterName,
 		},
@@ -15,10 +15,15 @@
 package connector
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/alecthomas/kong"
 	"github.com/pterm/pterm"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 
-	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/connector"
 	"github.com/upbound/up/internal/install/helm"
 	"github.com/upbound/up/internal/kube"
 	"github.com/upbound/up/internal/upbound"
@@ -46,23 +51,37 @@ func (c *uninstallCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context)
 	if err != nil {
 		return err
 	}
-	c.mgr = mgr
+	client, err := kubernetes.NewForConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	dyn, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	c.connector = connector.New(mgr, client, dyn, connector.WithNamespace(c.InstallationNamespace))
 	return nil
 }
 
 // uninstallCmd uninstalls UXP.
 type uninstallCmd struct {
-	mgr install.Manager
+	connector *connector.Connector
 
 	ClusterName           string `help:"Name of the cluster connecting to the control plane. If not provided, the namespace argument value will be used."`
 	Namespace             string `arg:"" required:"" help:"Namespace in the control plane where the claims of the cluster will be stored."`
 	Kubeconfig            string `type:"existingfile" help:"Override the default kubeconfig path."`
 	InstallationNamespace string `short:"n" env:"MCP_CONNECTOR_NAMESPACE" default:"kube-system" help:"Kubernetes namespace for MCP Connector. Default is kube-system."`
+	ControlPlaneSecret    string `help:"Name of the secret that contains the kubeconfig for a control plane. Deleted only if it was written when connecting."`
+	ControlPlane          string `help:"Name of the control plane the cluster is connected to. In a Space, the kubeconfig secret written when connecting to it is deleted."`
 }
 
 // Run executes the uninstall command.
-func (c *uninstallCmd) Run(p pterm.TextPrinter) error {
-	if err := c.mgr.Uninstall(); err != nil {
+func (c *uninstallCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	secret := c.ControlPlaneSecret
+	if secret == "" && c.ControlPlane != "" && upCtx.Profile.IsSpace() {
+		secret = fmt.Sprintf(secretFmt, c.ControlPlane)
+	}
+	if err := c.connector.Disconnect(ctx, secret); err != nil {
 		return err
 	}
 	p.Printfln("MCP Connector uninstalled")
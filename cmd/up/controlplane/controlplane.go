@@ -22,18 +22,14 @@ import (
 
 	cp "github.com/upbound/up-sdk-go/service/controlplanes"
 	"github.com/upbound/up/cmd/up/controlplane/connector"
+	"github.com/upbound/up/cmd/up/controlplane/fleet"
 	"github.com/upbound/up/cmd/up/controlplane/kubeconfig"
 	"github.com/upbound/up/cmd/up/controlplane/pkg"
 	"github.com/upbound/up/cmd/up/controlplane/pullsecret"
-	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/feature"
+	"github.com/upbound/up/internal/printer"
 	"github.com/upbound/up/internal/upbound"
-	"github.com/upbound/up/internal/upterm"
-)
-
-var (
-	cloudfieldNames = []string{"NAME", "ID", "STATUS", "CONFIGURATION", "CONFIGURATION STATUS"}
-	spacefieldNames = []string{"NAME", "ID", "STATUS", "MESSAGE", "CONNECTION NAME", "CONNECTION NAMESPACE"}
 )
 
 // BeforeReset is the first hook to run.
@@ -100,6 +96,8 @@ type Cmd struct {
 	Configuration pkg.Cmd `cmd:"" set:"package_type=Configuration" help:"Manage Configurations."`
 	Provider      pkg.Cmd `cmd:"" set:"package_type=Provider" help:"Manage Providers."`
 
+	Fleet fleet.Cmd `cmd:"" maturity:"alpha" help:"Apply an operation to many control planes at once."`
+
 	PullSecret pullsecret.Cmd `cmd:"" help:"Manage package pull secrets."`
 
 	Kubeconfig kubeconfig.Cmd `cmd:"" name:"kubeconfig" help:"Manage control plane kubeconfig data."`
@@ -115,44 +113,10 @@ local Spaces are supported. Use the "profile" management command to switch
 between different Upbound profiles or to connect to a local Space.`
 }
 
-func extractCloudFields(obj any) []string {
-	id, readyStatus := "unknown", "unknown"
-
-	resp, ok := obj.(*controlplane.Response)
-	if !ok {
-		return []string{"", id, readyStatus}
-	}
-
-	return []string{
-		resp.Name,
-		resp.ID,
-		resp.Status,
-		resp.Cfg,
-		resp.CfgStatus,
-	}
-}
-
-func extractSpaceFields(obj any) []string {
-	id, readyStatus := "unknown", "unknown"
-
-	resp, ok := obj.(*controlplane.Response)
-	if !ok {
-		return []string{"", id, readyStatus}
-	}
-
-	return []string{
-		resp.Name,
-		resp.ID,
-		resp.Status,
-		resp.Message,
-		resp.ConnName,
-		resp.ConnNamespace,
-	}
-}
-
-func tabularPrint(obj any, printer upterm.ObjectPrinter, upCtx *upbound.Context) error {
+func tabularPrint(obj any, pr *printer.Printer, upCtx *upbound.Context) error {
+	pr.DefaultFormat(config.Format(upCtx.Profile.Format))
 	if upCtx.Profile.IsSpace() {
-		return printer.Print(obj, spacefieldNames, extractSpaceFields)
+		return pr.Print(obj, printer.SpaceControlPlaneColumns)
 	}
-	return printer.Print(obj, cloudfieldNames, extractCloudFields)
+	return pr.Print(obj, printer.CloudControlPlaneColumns)
 }
@@ -16,14 +16,18 @@ package controlplane
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
 	"k8s.io/client-go/dynamic"
 
 	"github.com/upbound/up-sdk-go/service/configurations"
 	cp "github.com/upbound/up-sdk-go/service/controlplanes"
 
+	"github.com/upbound/up/internal/cleanup"
 	"github.com/upbound/up/internal/controlplane"
 	"github.com/upbound/up/internal/controlplane/cloud"
 	"github.com/upbound/up/internal/controlplane/space"
@@ -32,6 +36,8 @@ import (
 
 type ctpCreator interface {
 	Create(ctx context.Context, name string, opts controlplane.Options) (*controlplane.Response, error)
+	CreateFromTemplate(ctx context.Context, name string, t *controlplane.Template) (*controlplane.Response, error)
+	Delete(ctx context.Context, name string) error
 }
 
 // createCmd creates a control plane on Upbound.
@@ -44,14 +50,20 @@ type createCmd struct {
 	SecretName      string `help:"The name of the control plane's secret. Defaults to 'kubeconfig-{control plane name}'. Only applicable for Space control planes."`
 	SecretNamespace string `default:"default" help:"The name of namespace for the control plane's secret. Only applicable for Space control planes."`
 
+	CrossplaneVersion string `help:"The version of Crossplane to run. Defaults to the version chosen by the Space. Only applicable for Space control planes."`
+	CrossplaneChannel string `enum:",None,Patch,Stable,Rapid" default:"" help:"The channel used to automatically upgrade Crossplane. One of None, Patch, Stable, or Rapid. Only applicable for Space control planes."`
+	Class             string `help:"The class of the control plane, which determines the resources allocated to it. Only applicable for Space control planes."`
+
+	Template string `type:"existingfile" help:"Path to a YAML control plane template to create the control plane from. When set, the configuration, secret, and class flags are ignored."`
+
 	client ctpCreator
 }
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context, log logging.Logger) error {
 
 	if upCtx.Profile.IsSpace() {
-		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		kubeconfig, err := upCtx.GetKubeClientConfig()
 		if err != nil {
 			return err
 		}
@@ -59,7 +71,11 @@ func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 		if err != nil {
 			return err
 		}
-		c.client = space.New(client)
+		caps, err := upCtx.Capabilities(context.Background())
+		if err != nil {
+			log.Debug("Cannot negotiate Space capabilities", "error", err)
+		}
+		c.client = space.New(client, space.WithLogger(log), space.WithTelemetry(upCtx.Telemetry), space.WithCapabilities(caps))
 	} else {
 		cfg, err := upCtx.BuildSDKConfig()
 		if err != nil {
@@ -68,7 +84,7 @@ func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 		ctpclient := cp.NewClient(cfg)
 		cfgclient := configurations.NewClient(cfg)
 
-		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account)
+		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account, cloud.WithLogger(log), cloud.WithTelemetry(upCtx.Telemetry))
 	}
 
 	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
@@ -76,19 +92,43 @@ func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 }
 
 // Run executes the create command.
-func (c *createCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
-	_, err := c.client.Create(
-		ctx,
-		c.Name,
-		controlplane.Options{
-			SecretName:      c.SecretName,
-			SecretNamespace: c.SecretNamespace,
-		},
-	)
+func (c *createCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, hooks *cleanup.Hooks) error {
+	// If the command is interrupted the control plane may or may not have
+	// been created, so attempt to delete it either way.
+	remove := hooks.Add(fmt.Sprintf("control plane %s", c.Name), func(ctx context.Context) error {
+		err := c.client.Delete(ctx, c.Name)
+		if controlplane.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+	_, err := c.create(ctx)
 	if err != nil {
 		return err
 	}
+	remove()
 
 	p.Printfln("%s created", c.Name)
 	return nil
 }
+
+func (c *createCmd) create(ctx context.Context) (*controlplane.Response, error) {
+	if c.Template != "" {
+		t, err := controlplane.ReadTemplate(afero.NewOsFs(), c.Template)
+		if err != nil {
+			return nil, err
+		}
+		return c.client.CreateFromTemplate(ctx, c.Name, t)
+	}
+	return c.client.Create(
+		ctx,
+		c.Name,
+		controlplane.Options{
+			SecretName:        c.SecretName,
+			SecretNamespace:   c.SecretNamespace,
+			CrossplaneVersion: c.CrossplaneVersion,
+			CrossplaneChannel: c.CrossplaneChannel,
+			Class:             c.Class,
+		},
+	)
+}
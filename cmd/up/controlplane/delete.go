@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
 	"k8s.io/client-go/dynamic"
 
@@ -42,10 +43,10 @@ type deleteCmd struct {
 }
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *deleteCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+func (c *deleteCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context, log logging.Logger) error {
 
 	if upCtx.Profile.IsSpace() {
-		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		kubeconfig, err := upCtx.GetKubeClientConfig()
 		if err != nil {
 			return err
 		}
@@ -53,7 +54,11 @@ func (c *deleteCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 		if err != nil {
 			return err
 		}
-		c.client = space.New(client)
+		caps, err := upCtx.Capabilities(context.Background())
+		if err != nil {
+			log.Debug("Cannot negotiate Space capabilities", "error", err)
+		}
+		c.client = space.New(client, space.WithLogger(log), space.WithTelemetry(upCtx.Telemetry), space.WithCapabilities(caps))
 	} else {
 		cfg, err := upCtx.BuildSDKConfig()
 		if err != nil {
@@ -62,7 +67,7 @@ func (c *deleteCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 		ctpclient := cp.NewClient(cfg)
 		cfgclient := configurations.NewClient(cfg)
 
-		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account)
+		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account, cloud.WithLogger(log), cloud.WithTelemetry(upCtx.Telemetry))
 	}
 	return nil
 }
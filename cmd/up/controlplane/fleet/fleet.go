@@ -0,0 +1,134 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/pterm/pterm"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/internal/feature"
+	"github.com/upbound/up/internal/fleet"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errNotSpace       = "fleet operations are only supported for Space profiles"
+	errFmtLabel       = "invalid label %q, must be key=value"
+	errNoControlPlane = "no control planes match the selector"
+)
+
+// BeforeReset is the first hook to run.
+func (c *Cmd) BeforeReset(p *kong.Path, maturity feature.Maturity) error {
+	return feature.HideMaturity(p, maturity)
+}
+
+// AfterApply constructs the Space clients used by all fleet subcommands.
+func (c *Cmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+	if !upCtx.Profile.IsSpace() {
+		return errors.New(errNotSpace)
+	}
+	kubeconfig, err := upCtx.GetKubeClientConfig()
+	if err != nil {
+		return err
+	}
+	dyn, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	kube, err := kubernetes.NewForConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	kongCtx.BindTo(dyn, (*dynamic.Interface)(nil))
+	kongCtx.BindTo(kube, (*kubernetes.Interface)(nil))
+	return nil
+}
+
+// Cmd contains commands for operating on many control planes at once.
+type Cmd struct {
+	SetChannel              setChannelCmd              `cmd:"" help:"Set the Crossplane auto-upgrade channel of control planes."`
+	Pause                   pauseCmd                   `cmd:"" help:"Pause reconciliation of control planes."`
+	Resume                  resumeCmd                  `cmd:"" help:"Resume reconciliation of control planes."`
+	SetConfigurationVersion setConfigurationVersionCmd `cmd:"" help:"Set the version of a Configuration installed in control planes."`
+}
+
+func (c *Cmd) Help() string {
+	return `
+Apply an operation to every control plane of the current Space that matches a
+selector. Control planes are selected by group, name patterns and labels. Use
+--canary to apply the operation to a few control planes first; the rollout is
+aborted if any of them fails.`
+}
+
+// selectorFlags are the flags common to all fleet subcommands.
+type selectorFlags struct {
+	Group       string   `short:"g" help:"The group to select control planes from. Defaults to all groups."`
+	Name        []string `help:"Select control planes whose name matches the pattern, e.g. 'prod-*'. Repeatable."`
+	Label       []string `short:"l" help:"Select control planes with the label, as key=value. Repeatable."`
+	Concurrency int      `default:"5" help:"The number of control planes operated on at once."`
+	Canary      int      `default:"0" help:"The number of control planes operated on first, one at a time, before the rest."`
+	DryRun      bool     `help:"Only print the control planes that would be operated on."`
+}
+
+func (f *selectorFlags) selector() (fleet.Selector, error) {
+	labels := make(map[string]string, len(f.Label))
+	for _, l := range f.Label {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok || k == "" {
+			return fleet.Selector{}, errors.Errorf(errFmtLabel, l)
+		}
+		labels[k] = v
+	}
+	return fleet.Selector{Group: f.Group, Names: f.Name, Labels: labels}, nil
+}
+
+// run applies the operation to the selected control planes, printing each
+// result as it becomes available.
+func (f *selectorFlags) run(ctx context.Context, p pterm.TextPrinter, log logging.Logger, dyn dynamic.Interface, op fleet.Operation) error {
+	r := fleet.New(dyn,
+		fleet.WithConcurrency(f.Concurrency),
+		fleet.WithCanaries(f.Canary),
+		fleet.WithLogger(log),
+		fleet.WithProgress(func(res fleet.Result) {
+			p.Println(res.String())
+		}),
+	)
+	s, err := f.selector()
+	if err != nil {
+		return err
+	}
+	targets, err := r.Select(ctx, s)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return errors.New(errNoControlPlane)
+	}
+	if f.DryRun {
+		for _, t := range targets {
+			p.Printfln("%s: would %s", t, op.Name())
+		}
+		return nil
+	}
+	p.Printfln("Applying %q to %d control planes", op.Name(), len(targets))
+	return r.Apply(ctx, targets, op).Err()
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/pterm/pterm"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/internal/fleet"
+	"github.com/upbound/up/internal/upbound"
+)
+
+// setChannelCmd sets the Crossplane auto-upgrade channel of control planes.
+type setChannelCmd struct {
+	Channel string `arg:"" required:"" help:"The channel. One of None, Patch, Stable, or Rapid."`
+
+	selectorFlags `embed:""`
+}
+
+// Run executes the set-channel command.
+func (c *setChannelCmd) Run(ctx context.Context, p pterm.TextPrinter, log logging.Logger, dyn dynamic.Interface) error {
+	op, err := fleet.NewSetChannel(dyn, c.Channel)
+	if err != nil {
+		return err
+	}
+	return c.run(ctx, p, log, dyn, op)
+}
+
+// pauseCmd pauses reconciliation of control planes.
+type pauseCmd struct {
+	selectorFlags `embed:""`
+}
+
+// Run executes the pause command.
+func (c *pauseCmd) Run(ctx context.Context, p pterm.TextPrinter, log logging.Logger, dyn dynamic.Interface) error {
+	return c.run(ctx, p, log, dyn, fleet.NewPause(dyn, true))
+}
+
+// resumeCmd resumes reconciliation of control planes.
+type resumeCmd struct {
+	selectorFlags `embed:""`
+}
+
+// Run executes the resume command.
+func (c *resumeCmd) Run(ctx context.Context, p pterm.TextPrinter, log logging.Logger, dyn dynamic.Interface) error {
+	return c.run(ctx, p, log, dyn, fleet.NewPause(dyn, false))
+}
+
+// setConfigurationVersionCmd sets the version of a Configuration installed in
+// control planes.
+type setConfigurationVersionCmd struct {
+	Configuration string `arg:"" required:"" help:"Name of the Configuration in each control plane."`
+	Version       string `arg:"" required:"" help:"The version, i.e. package tag, to set."`
+
+	selectorFlags `embed:""`
+}
+
+// Run executes the set-configuration-version command.
+func (c *setConfigurationVersionCmd) Run(ctx context.Context, p pterm.TextPrinter, log logging.Logger, upCtx *upbound.Context, dyn dynamic.Interface, kube kubernetes.Interface) error {
+	connect := fleet.SecretConnector(dyn, kube, upCtx.WrapTransport)
+	return c.run(ctx, p, log, dyn, fleet.NewSetConfigurationVersion(connect, c.Configuration, c.Version))
+}
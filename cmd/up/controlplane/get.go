@@ -18,28 +18,40 @@ import (
 	"context"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/upbound/up-sdk-go/service/configurations"
 	cp "github.com/upbound/up-sdk-go/service/controlplanes"
 
 	"github.com/upbound/up/internal/controlplane"
 	"github.com/upbound/up/internal/controlplane/cloud"
+	"github.com/upbound/up/internal/controlplane/health"
 	"github.com/upbound/up/internal/controlplane/space"
+	"github.com/upbound/up/internal/printer"
 	"github.com/upbound/up/internal/upbound"
-	"github.com/upbound/up/internal/upterm"
+)
+
+const (
+	errHealthUnsupported = "--health is only supported for Space control planes"
+	errCheckHealth       = "cannot check control plane health"
 )
 
 type ctpGetter interface {
 	Get(ctx context.Context, name string) (*controlplane.Response, error)
 }
 
+type healthFn func(ctx context.Context, name string) (*health.Summary, error)
+
 // AfterApply sets default values in command after assignment and validation.
-func (c *getCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+func (c *getCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context, log logging.Logger) error {
 
 	if upCtx.Profile.IsSpace() {
-		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		kubeconfig, err := upCtx.GetKubeClientConfig()
 		if err != nil {
 			return err
 		}
@@ -47,8 +59,17 @@ func (c *getCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error
 		if err != nil {
 			return err
 		}
-		c.client = space.New(client)
+		caps, err := upCtx.Capabilities(context.Background())
+		if err != nil {
+			log.Debug("Cannot negotiate Space capabilities", "error", err)
+		}
+		sc := space.New(client, space.WithLogger(log), space.WithTelemetry(upCtx.Telemetry), space.WithCapabilities(caps))
+		c.client = sc
+		c.health = spaceHealth(sc, upCtx)
 	} else {
+		if c.Health {
+			return errors.New(errHealthUnsupported)
+		}
 		cfg, err := upCtx.BuildSDKConfig()
 		if err != nil {
 			return err
@@ -56,7 +77,7 @@ func (c *getCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error
 		ctpclient := cp.NewClient(cfg)
 		cfgclient := configurations.NewClient(cfg)
 
-		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account)
+		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account, cloud.WithLogger(log), cloud.WithTelemetry(upCtx.Telemetry))
 	}
 
 	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
@@ -65,13 +86,40 @@ func (c *getCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error
 
 // getCmd gets a single control plane in an account on Upbound.
 type getCmd struct {
-	Name string `arg:"" required:"" help:"Name of control plane." predictor:"ctps"`
+	Name   string `arg:"" required:"" help:"Name of control plane." predictor:"ctps"`
+	Health bool   `help:"Connect to the control plane and summarize the health of Crossplane, its packages and providers. Only applicable for Space control planes."`
 
 	client ctpGetter
+	health healthFn
+}
+
+// spaceHealth returns a healthFn that checks Space control planes using the
+// kubeconfig in their connection secret.
+func spaceHealth(sc *space.Client, upCtx *upbound.Context) healthFn {
+	return func(ctx context.Context, name string) (*health.Summary, error) {
+		kc, err := sc.GetKubeConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := clientcmd.NewDefaultClientConfig(*kc, nil).ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		upCtx.WrapKubeConfig(cfg)
+		kube, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dyn, err := dynamic.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return health.New(kube, dyn).Check(ctx)
+	}
 }
 
 // Run executes the get command.
-func (c *getCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter, upCtx *upbound.Context) error {
+func (c *getCmd) Run(ctx context.Context, pr *printer.Printer, p pterm.TextPrinter, upCtx *upbound.Context) error {
 	ctp, err := c.client.Get(ctx, c.Name)
 	if controlplane.IsNotFound(err) {
 		p.Printfln("Control plane %s not found", c.Name)
@@ -80,8 +128,24 @@ func (c *getCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.
 	if err != nil {
 		return err
 	}
+	if c.Health {
+		ctp.Health, err = c.health(ctx, c.Name)
+		if err != nil {
+			return errors.Wrap(err, errCheckHealth)
+		}
+	}
 
-	return tabularPrint(ctp, printer, upCtx)
+	if err := tabularPrint(ctp, pr, upCtx); err != nil {
+		return err
+	}
+	if ctp.Health != nil && !pr.Structured() {
+		p.Println()
+		p.Printfln("Health: %s (score %d)", ctp.Health.Level, ctp.Health.Score)
+		for _, f := range ctp.Health.Findings {
+			p.Printfln("  %s", f)
+		}
+	}
+	return nil
 }
 
 // EmptyControlPlaneConfiguration returns an empty ControlPlaneConfiguration with default values.
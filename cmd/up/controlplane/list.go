@@ -18,17 +18,19 @@ import (
 	"context"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
 	"k8s.io/client-go/dynamic"
 
 	"github.com/upbound/up-sdk-go/service/configurations"
 	cp "github.com/upbound/up-sdk-go/service/controlplanes"
 
+	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/controlplane"
 	"github.com/upbound/up/internal/controlplane/cloud"
 	"github.com/upbound/up/internal/controlplane/space"
+	"github.com/upbound/up/internal/printer"
 	"github.com/upbound/up/internal/upbound"
-	"github.com/upbound/up/internal/upterm"
 )
 
 type ctpLister interface {
@@ -41,10 +43,10 @@ type listCmd struct {
 }
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
+func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context, log logging.Logger) error {
 
 	if upCtx.Profile.IsSpace() {
-		kubeconfig, err := upCtx.Profile.GetKubeClientConfig()
+		kubeconfig, err := upCtx.GetKubeClientConfig()
 		if err != nil {
 			return err
 		}
@@ -52,7 +54,11 @@ func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) erro
 		if err != nil {
 			return err
 		}
-		c.client = space.New(client)
+		caps, err := upCtx.Capabilities(context.Background())
+		if err != nil {
+			log.Debug("Cannot negotiate Space capabilities", "error", err)
+		}
+		c.client = space.New(client, space.WithLogger(log), space.WithTelemetry(upCtx.Telemetry), space.WithCapabilities(caps))
 	} else {
 		cfg, err := upCtx.BuildSDKConfig()
 		if err != nil {
@@ -61,7 +67,7 @@ func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) erro
 		ctpclient := cp.NewClient(cfg)
 		cfgclient := configurations.NewClient(cfg)
 
-		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account)
+		c.client = cloud.New(ctpclient, cfgclient, upCtx.Account, cloud.WithLogger(log), cloud.WithTelemetry(upCtx.Telemetry))
 	}
 
 	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
@@ -69,16 +75,17 @@ func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) erro
 }
 
 // Run executes the list command.
-func (c *listCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter, upCtx *upbound.Context) error {
+func (c *listCmd) Run(ctx context.Context, pr *printer.Printer, p pterm.TextPrinter, upCtx *upbound.Context) error {
 	l, err := c.client.List(ctx)
 	if err != nil {
 		return err
 	}
 
-	if len(l) == 0 {
+	pr.DefaultFormat(config.Format(upCtx.Profile.Format))
+	if len(l) == 0 && !pr.Structured() {
 		p.Println("No control planes found")
 		return nil
 	}
 
-	return tabularPrint(l, printer, upCtx)
+	return tabularPrint(l, pr, upCtx)
 }
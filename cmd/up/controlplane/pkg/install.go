@@ -21,7 +21,9 @@ import (
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pterm/pterm"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,9 +36,13 @@ import (
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/upterm"
 	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/sign"
 )
 
-const errUnknownPkgType = "provided package type is unknown"
+const (
+	errUnknownPkgType = "provided package type is unknown"
+	errNoVerifiers    = "--verify-key or --certificate-identity must be specified with --signature-policy"
+)
 
 // Supported package kinds.
 const (
@@ -102,6 +108,9 @@ type installCmd struct {
 	Name               string        `help:"Name of ${package_type}."`
 	PackagePullSecrets []string      `help:"List of secrets used to pull ${package_type}."`
 	Wait               time.Duration `short:"w" help:"Wait duration for successful ${package_type} installation."`
+
+	SignaturePolicy string           `enum:"ignore,warn,enforce" default:"ignore" help:"Whether to refuse (enforce) or warn about (warn) a ${package_type} without a trusted signature. Unless ignored, the verified digest is installed rather than the tag."`
+	Verify          sign.VerifyFlags `embed:""`
 }
 
 // Run executes the install command.
@@ -110,6 +119,12 @@ func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upboun
 	if err != nil {
 		return err
 	}
+	// Install the digest that was verified, if any, rather than a tag that
+	// may since have been repointed.
+	ref, err = c.checkSignature(ctx, p, upCtx, ref)
+	if err != nil {
+		return err
+	}
 	if c.Name == "" {
 		c.Name = xpkg.ToDNSLabel(ref.Context().RepositoryStr())
 	}
@@ -161,3 +176,34 @@ func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upboun
 	s.Success(fmt.Sprintf("%s installed and healthy", c.Name))
 	return nil
 }
+
+// checkSignature applies the signature policy to the package and returns the
+// reference it should be installed from.
+func (c *installCmd) checkSignature(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, ref name.Reference) (name.Reference, error) {
+	mode := sign.PolicyMode(c.SignaturePolicy)
+	if mode == sign.PolicyIgnore {
+		return ref, nil
+	}
+	if !c.Verify.Enabled() {
+		return nil, errors.New(errNoVerifiers)
+	}
+	vs, err := c.Verify.Verifiers()
+	if err != nil {
+		return nil, err
+	}
+	kc := authn.NewMultiKeychain(
+		authn.NewKeychainFromHelper(
+			upCtx.CredentialHelper(upCtx.ProfileName),
+		),
+		authn.DefaultKeychain,
+	)
+	policy := &sign.Policy{
+		Mode:      mode,
+		Verifiers: vs,
+		Warn: func(err error) {
+			p.Printfln("Warning: %s", err)
+		},
+		Options: []remote.Option{remote.WithAuthFromKeychain(kc)},
+	}
+	return policy.Check(ctx, ref)
+}
@@ -28,12 +28,15 @@ import (
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/golang-jwt/jwt"
+	"github.com/pkg/browser"
 	"github.com/pterm/pterm"
 
 	"github.com/upbound/up-sdk-go/service/userinfo"
+	upauth "github.com/upbound/up/internal/auth"
 	uphttp "github.com/upbound/up/internal/http"
 	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/tokenstore"
 	"github.com/upbound/up/internal/upbound"
 )
 
@@ -47,12 +50,15 @@ const (
 	errNoUserOrToken  = "either username or token must be provided"
 	errNoIDInToken    = "token is missing ID"
 	errUpdateConfig   = "unable to update config file"
+	errStoreToken     = "unable to store token"
+	errClearToken     = "unable to remove stale token"
 )
 
 // BeforeApply sets default values in login before assignment and validation.
 func (c *loginCmd) BeforeApply() error { //nolint:unparam
 	c.stdin = os.Stdin
 	c.prompter = input.NewPrompter()
+	c.openBrowser = browser.OpenURL
 	return nil
 }
 
@@ -77,7 +83,8 @@ func (c *loginCmd) AfterApply(kongCtx *kong.Context) error {
 		Transport: tr,
 	}
 	kongCtx.Bind(upCtx)
-	if c.Token != "" {
+	c.prompt = kongCtx.Stderr
+	if c.Token != "" || c.Device {
 		return nil
 	}
 	if c.Username == "" {
@@ -100,13 +107,16 @@ func (c *loginCmd) AfterApply(kongCtx *kong.Context) error {
 // loginCmd adds a user or token profile with session token to the up config
 // file.
 type loginCmd struct {
-	client   uphttp.Client
-	stdin    io.Reader
-	prompter input.Prompter
+	client      uphttp.Client
+	stdin       io.Reader
+	prompter    input.Prompter
+	prompt      io.Writer
+	openBrowser func(url string) error
 
 	Username string `short:"u" env:"UP_USER" xor:"identifier" help:"Username used to execute command."`
 	Password string `short:"p" env:"UP_PASSWORD" help:"Password for specified user. '-' to read from stdin."`
 	Token    string `short:"t" env:"UP_TOKEN" xor:"identifier" help:"Token used to execute command. '-' to read from stdin."`
+	Device   bool   `xor:"identifier" help:"Log in by authorizing a device code in a browser, possibly on another machine."`
 
 	// Common Upbound API configuration
 	Flags upbound.Flags `embed:""`
@@ -114,6 +124,13 @@ type loginCmd struct {
 
 // Run executes the login command.
 func (c *loginCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error { // nolint:gocyclo
+	// If profile name was not provided and no default exists, set name to 'default'.
+	if upCtx.ProfileName == "" {
+		upCtx.ProfileName = profile.DefaultName
+	}
+	if c.Device {
+		return c.deviceLogin(ctx, p, upCtx)
+	}
 	if c.Token == "-" {
 		b, err := io.ReadAll(c.stdin)
 		if err != nil {
@@ -155,25 +172,69 @@ func (c *loginCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 		return errors.Wrap(err, errLoginFailed)
 	}
 
-	// If profile name was not provided and no default exists, set name to 'default'.
-	if upCtx.ProfileName == "" {
-		upCtx.ProfileName = profile.DefaultName
+	if c.Token != "" {
+		if err := upCtx.Tokens.Set(tokenstore.TokenKey(upCtx.ProfileName), c.Token); err != nil {
+			return errors.Wrap(err, errStoreToken)
+		}
+	}
+	// A refresh token of an earlier device login could otherwise replace
+	// this session once it expires.
+	if err := upCtx.Tokens.Delete(tokenstore.RefreshKey(upCtx.ProfileName)); err != nil && !tokenstore.IsNotFound(err) {
+		return errors.Wrap(err, errClearToken)
+	}
+	if err := saveProfile(ctx, upCtx, profType, auth.ID, session); err != nil {
+		return err
+	}
+	p.Printfln("%s logged in", auth.ID)
+	return nil
+}
+
+// deviceLogin logs in using the OAuth2 device authorization grant. The access
+// token is used as the session, and the refresh token is stored so that the
+// session can be refreshed once it expires.
+func (c *loginCmd) deviceLogin(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	t, err := upauth.NewDeviceFlow(
+		upauth.WithDeviceEndpoint(upCtx.APIEndpoint),
+		upauth.WithDeviceClient(c.client),
+		upauth.WithPrompt(c.prompt),
+		upauth.WithBrowser(c.openBrowser),
+	).Login(ctx)
+	if err != nil {
+		return errors.Wrap(err, errLoginFailed)
 	}
 
+	if t.RefreshToken != "" {
+		if err := upCtx.Tokens.Set(tokenstore.RefreshKey(upCtx.ProfileName), t.RefreshToken); err != nil {
+			return errors.Wrap(err, errStoreToken)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	if err := saveProfile(ctx, upCtx, profile.User, "", t.AccessToken); err != nil {
+		return err
+	}
+	p.Printfln("%s logged in", upCtx.Profile.ID)
+	return nil
+}
+
+// saveProfile stores the supplied session in the token store and writes the
+// profile of the Context to the config file as the default profile. If the ID
+// or the account of the profile are not known they are looked up using the
+// session.
+func saveProfile(ctx context.Context, upCtx *upbound.Context, profType profile.Type, id, session string) error {
 	// Re-initialize profile for this login.
-	profile := profile.Profile{
+	upCtx.Profile = profile.Profile{
 		Type: profType,
-		ID:   auth.ID,
+		ID:   id,
 		// Set session early so that it can be used to fetch user info if
 		// necessary.
 		Session: session,
 		// Carry over existing config.
 		BaseConfig: upCtx.Profile.BaseConfig,
 	}
-	upCtx.Profile = profile
 
 	// If the default account is not set, the user's personal account is used.
-	if upCtx.Account == "" {
+	if upCtx.Account == "" || id == "" {
 		conf, err := upCtx.BuildSDKConfig()
 		if err != nil {
 			return errors.Wrap(err, errLoginFailed)
@@ -182,11 +243,22 @@ func (c *loginCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 		if err != nil {
 			return errors.Wrap(err, errLoginFailed)
 		}
-		upCtx.Account = info.User.Username
+		if upCtx.Account == "" {
+			upCtx.Account = info.User.Username
+		}
+		if upCtx.Profile.ID == "" {
+			upCtx.Profile.ID = info.User.Username
+		}
 	}
 	upCtx.Profile.Account = upCtx.Account
 
-	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(upCtx.ProfileName, upCtx.Profile); err != nil {
+	// Tokens are kept in the token store rather than in the config file.
+	if err := upCtx.Tokens.Set(tokenstore.SessionKey(upCtx.ProfileName), session); err != nil {
+		return errors.Wrap(err, errStoreToken)
+	}
+	stored := upCtx.Profile
+	stored.Session = ""
+	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(upCtx.ProfileName, stored); err != nil {
 		return errors.Wrap(err, errLoginFailed)
 	}
 	if err := upCtx.Cfg.SetDefaultUpboundProfile(upCtx.ProfileName); err != nil {
@@ -195,7 +267,6 @@ func (c *loginCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
 		return errors.Wrap(err, errUpdateConfig)
 	}
-	p.Printfln("%s logged in", auth.ID)
 	return nil
 }
 
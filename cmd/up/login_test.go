@@ -17,9 +17,12 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"testing/iotest"
 
@@ -28,9 +31,13 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
 
+	"github.com/upbound/up-sdk-go/service/userinfo"
+	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/http/mocks"
 	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/tokenstore"
 	"github.com/upbound/up/internal/upbound"
 )
 
@@ -76,6 +83,94 @@ func TestRun(t *testing.T) {
 	}
 }
 
+func TestRunDevice(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/oauth/device/code":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"device_code":      "cool-device-code",
+				"user_code":        "ABCD-EFGH",
+				"verification_uri": "https://accounts.upbound.io/device",
+				"expires_in":       60,
+				"interval":         1,
+			})
+		case "/v1/oauth/token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("device_code") != "cool-device-code" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token":  "cool-session",
+				"refresh_token": "cool-refresh",
+				"expires_in":    3600,
+			})
+		case "/v1/self":
+			if c, err := r.Cookie(upbound.CookieName); err != nil || c.Value != "cool-session" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(userinfo.GetResponse{User: userinfo.User{Username: "cool-user"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	tokens := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+	cfg := &config.Config{}
+	upCtx := &upbound.Context{
+		APIEndpoint: u,
+		Cfg:         cfg,
+		CfgSrc: &config.MockSource{
+			UpdateConfigFn: func(*config.Config) error { return nil },
+		},
+		Tokens: tokens,
+	}
+	prompt := &bytes.Buffer{}
+	cmd := &loginCmd{
+		client: srv.Client(),
+		prompt: prompt,
+		Device: true,
+	}
+
+	out := &bytes.Buffer{}
+	if err := cmd.Run(context.Background(), pterm.DefaultBasicText.WithWriter(out), upCtx); err != nil {
+		t.Fatalf("Run(...): %s", err)
+	}
+
+	if !strings.Contains(prompt.String(), "ABCD-EFGH") {
+		t.Errorf("Run(...): prompt %q does not include the user code", prompt.String())
+	}
+	if diff := cmp.Diff("cool-user logged in\n", out.String()); diff != "" {
+		t.Errorf("Run(...): -want output, +got output:\n%s", diff)
+	}
+	for key, want := range map[string]string{
+		tokenstore.SessionKey(profile.DefaultName): "cool-session",
+		tokenstore.RefreshKey(profile.DefaultName): "cool-refresh",
+	} {
+		got, err := tokens.Get(key)
+		if err != nil {
+			t.Fatalf("Get(%q): %s", key, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Get(%q): -want, +got:\n%s", key, diff)
+		}
+	}
+	name, p, err := cfg.GetDefaultUpboundProfile()
+	if err != nil {
+		t.Fatalf("GetDefaultUpboundProfile(): %s", err)
+	}
+	if diff := cmp.Diff(profile.DefaultName, name); diff != "" {
+		t.Errorf("GetDefaultUpboundProfile(): -want name, +got name:\n%s", diff)
+	}
+	want := profile.Profile{Type: profile.User, ID: "cool-user", Account: "cool-user"}
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("GetDefaultUpboundProfile(): -want profile, +got profile:\n%s", diff)
+	}
+}
+
 func TestConstructAuth(t *testing.T) {
 	type args struct {
 		username string
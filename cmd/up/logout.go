@@ -17,20 +17,17 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"sort"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/pterm/pterm"
 
-	"github.com/upbound/up-sdk-go"
-
+	upauth "github.com/upbound/up/internal/auth"
 	"github.com/upbound/up/internal/upbound"
 )
 
 const (
-	logoutPath = "/v1/logout"
-
 	errLogoutFailed      = "unable to logout"
 	errRemoveTokenFailed = "failed to remove token"
 )
@@ -46,13 +43,15 @@ func (c *logoutCmd) AfterApply(kongCtx *kong.Context) error {
 	if err != nil {
 		return err
 	}
-	c.client = cfg.Client
+	c.client = upauth.NewSessionClient(cfg.Client)
 	return nil
 }
 
 // logoutCmd invalidates a stored session token for a given profile.
 type logoutCmd struct {
-	client up.Client
+	client *upauth.SessionClient
+
+	Everywhere bool `help:"Remove the tokens of all profiles from this machine, not only those of the current profile. The current token is revoked."`
 
 	// Common Upbound API configuration
 	Flags upbound.Flags `embed:""`
@@ -66,14 +65,16 @@ func (c *logoutCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound
 
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
-	req, err := c.client.NewRequest(ctx, http.MethodPost, logoutPath, "", nil)
-	if err != nil {
-		return errors.Wrap(err, errLogoutFailed)
+	if c.Everywhere {
+		return c.logoutEverywhere(ctx, p, upCtx)
 	}
-	if err := c.client.Do(req, nil); err != nil {
+	if err := c.client.Logout(ctx); err != nil {
 		return errors.Wrap(err, errLogoutFailed)
 	}
-	// Logout is successful, remove token from config and update.
+	// Logout is successful, remove tokens from the token store and config.
+	if err := upauth.ClearTokens(upCtx.Tokens, []string{upCtx.ProfileName}); err != nil {
+		return errors.Wrap(err, errRemoveTokenFailed)
+	}
 	upCtx.Profile.Session = ""
 	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(upCtx.ProfileName, upCtx.Profile); err != nil {
 		return errors.Wrap(err, errRemoveTokenFailed)
@@ -85,3 +86,35 @@ func (c *logoutCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound
 	p.Printfln("%s logged out", upCtx.Profile.ID)
 	return nil
 }
+
+// logoutEverywhere revokes the current token and removes the tokens of all
+// profiles from the token store and config. Local tokens are removed even if
+// the current token could not be revoked.
+func (c *logoutCmd) logoutEverywhere(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	names := make([]string, 0, len(upCtx.Cfg.Upbound.Profiles))
+	for name := range upCtx.Cfg.Upbound.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	logoutErr := c.client.Logout(ctx)
+	if err := upauth.ClearTokens(upCtx.Tokens, names); err != nil {
+		return errors.Wrap(err, errRemoveTokenFailed)
+	}
+	for _, name := range names {
+		prof := upCtx.Cfg.Upbound.Profiles[name]
+		prof.Session = ""
+		if err := upCtx.Cfg.AddOrUpdateUpboundProfile(name, prof); err != nil {
+			return errors.Wrap(err, errRemoveTokenFailed)
+		}
+	}
+	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
+		return errors.Wrap(err, errUpdateConfig)
+	}
+	if logoutErr != nil {
+		return errors.Wrap(logoutErr, errLogoutFailed)
+	}
+
+	p.Printfln("%s logged out of %d profiles", upCtx.Profile.ID, len(names))
+	return nil
+}
@@ -20,11 +20,16 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
 	"github.com/willabides/kongplete"
 
+	"github.com/upbound/up/cmd/up/airgap"
 	"github.com/upbound/up/cmd/up/configuration"
 	"github.com/upbound/up/cmd/up/configuration/template"
 	"github.com/upbound/up/cmd/up/controlplane"
@@ -32,13 +37,19 @@ import (
 	"github.com/upbound/up/cmd/up/profile"
 	"github.com/upbound/up/cmd/up/repository"
 	"github.com/upbound/up/cmd/up/robot"
+	"github.com/upbound/up/cmd/up/session"
 	"github.com/upbound/up/cmd/up/space"
 	"github.com/upbound/up/cmd/up/upbound"
 	"github.com/upbound/up/cmd/up/uxp"
 	"github.com/upbound/up/cmd/up/xpkg"
 	"github.com/upbound/up/cmd/up/xpls"
+	"github.com/upbound/up/internal/cleanup"
 	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/failure"
 	"github.com/upbound/up/internal/feature"
+	uplogging "github.com/upbound/up/internal/logging"
+	uprinter "github.com/upbound/up/internal/printer"
+	"github.com/upbound/up/internal/telemetry"
 	"github.com/upbound/up/internal/upterm"
 	"github.com/upbound/up/internal/version"
 
@@ -49,6 +60,10 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
+// cleanupTimeout bounds how long up spends undoing partially completed work
+// after a command was interrupted or timed out.
+const cleanupTimeout = 30 * time.Second
+
 type versionFlag bool
 
 // BeforeApply indicates that we want to execute the logic before running any
@@ -60,7 +75,10 @@ func (v versionFlag) BeforeApply(ctx *kong.Context) error { // nolint:unparam
 }
 
 // AfterApply configures global settings before executing commands.
-func (c *cli) AfterApply(ctx *kong.Context) error { //nolint:unparam
+func (c *cli) AfterApply(ctx *kong.Context) error {
+	if err := uprinter.ValidateFormat(c.Format); err != nil {
+		return err
+	}
 	if c.Quiet {
 		ctx.Stdout, ctx.Stderr = io.Discard, io.Discard
 	}
@@ -80,6 +98,24 @@ func (c *cli) AfterApply(ctx *kong.Context) error { //nolint:unparam
 
 	ctx.Bind(printer)
 	ctx.Bind(c.Quiet)
+
+	popts := []uprinter.Option{
+		uprinter.WithWriter(ctx.Stdout),
+		uprinter.WithFormat(c.Format),
+		uprinter.WithColumns(c.Columns...),
+		uprinter.WithSortBy(c.SortBy),
+	}
+	if c.NoHeaders {
+		popts = append(popts, uprinter.WithNoHeaders())
+	}
+	ctx.Bind(uprinter.New(popts...))
+
+	log := uplogging.New(
+		uplogging.WithVerbosity(c.LogVerbosity),
+		uplogging.WithFormat(uplogging.Format(c.LogFormat)),
+		uplogging.WithWriter(ctx.Stderr),
+	)
+	ctx.BindTo(log, (*logging.Logger)(nil))
 	return nil
 }
 
@@ -94,10 +130,17 @@ func (c *cli) BeforeReset(ctx *kong.Context, p *kong.Path) error {
 }
 
 type cli struct {
-	Format  config.Format    `name:"format" enum:"default,json,yaml" default:"default" help:"Format for get/list commands. Can be: json, yaml, default"`
-	Version versionFlag      `short:"v" name:"version" help:"Print version and exit."`
-	Quiet   config.QuietFlag `short:"q" name:"quiet" help:"Suppress all output."`
-	Pretty  bool             `name:"pretty" help:"Pretty print output."`
+	Format    config.Format    `name:"format" default:"" help:"Format for get/list commands. Can be: json, yaml, wide, default, jsonpath=<expression>, or go-template=<template>. Defaults to the format of the current profile."`
+	Columns   []string         `name:"columns" help:"Columns to include in get/list tables, in order."`
+	SortBy    string           `name:"sort-by" help:"Column to sort get/list output by."`
+	NoHeaders bool             `name:"no-headers" help:"Omit the header row of get/list tables."`
+	Version   versionFlag      `short:"v" name:"version" help:"Print version and exit."`
+	Quiet     config.QuietFlag `short:"q" name:"quiet" help:"Suppress all output."`
+	Pretty    bool             `name:"pretty" help:"Pretty print output."`
+
+	LogVerbosity   int           `name:"log-verbosity" default:"0" help:"Verbosity of logs written to stderr. 0 logs informational messages, 1 adds debug messages."`
+	LogFormat      string        `name:"log-format" enum:"text,json" default:"text" help:"Format of logs written to stderr. Can be: text, json."`
+	CommandTimeout time.Duration `name:"command-timeout" env:"UP_TIMEOUT" default:"0s" help:"Maximum time a command may run before it is cancelled. Zero means no timeout."`
 
 	License licenseCmd `cmd:"" help:"Print Up license information."`
 
@@ -111,6 +154,8 @@ type cli struct {
 	Profile            profile.Cmd                  `cmd:"" help:"Interact with Upbound profiles or local Spaces."`
 	Repository         repository.Cmd               `cmd:"" name:"repository" aliases:"repo" help:"Interact with repositories."`
 	Robot              robot.Cmd                    `cmd:"" name:"robot" help:"Interact with robots."`
+	Session            session.Cmd                  `cmd:"" help:"Interact with sessions and personal access tokens."`
+	Telemetry          telemetryCmd                 `cmd:"" help:"Configure anonymized usage telemetry."`
 	UXP                uxp.Cmd                      `cmd:"" help:"Interact with UXP."`
 	XPKG               xpkg.Cmd                     `cmd:"" help:"Interact with UXP packages."`
 	XPLS               xpls.Cmd                     `cmd:"" help:"Start xpls language server."`
@@ -138,12 +183,22 @@ type alpha struct {
 	ControlPlane controlplane.Cmd `cmd:"" hidden:"" name:"controlplane" aliases:"ctp" help:"Interact with control planes of the current profile, both in the cloud and in a local space."`
 	Upbound      upbound.Cmd      `cmd:"" maturity:"alpha" help:"Interact with Upbound."`
 	XPKG         xpkg.Cmd         `cmd:"" maturity:"alpha" help:"Interact with UXP packages."`
+	Airgap       airgap.Cmd       `cmd:"" maturity:"alpha" help:"Move UXP and Spaces installs into disconnected environments."`
 }
 
 func main() {
 	c := cli{}
 
+	// Defaults are read before parsing so that they can resolve flags that
+	// were not supplied on the command line or through the environment.
+	defaults, err := readDefaults()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "up: error: %s\n", err)
+		os.Exit(failure.ExitUsage)
+	}
+
 	parser := kong.Must(&c,
+		kong.Resolvers(defaults.Resolver(os.Getenv)),
 		kong.Name("up"),
 		kong.Description("The Upbound CLI"),
 		kong.Help(func(options kong.HelpOptions, ctx *kong.Context) error {
@@ -176,18 +231,96 @@ func main() {
 	}
 
 	kongCtx, err := parser.Parse(os.Args[1:])
-	parser.FatalIfErrorf(err)
+	// Hooks run while parsing, so only treat otherwise unclassified parse
+	// errors as usage errors.
+	var perr *kong.ParseError
+	if errors.As(err, &perr) && failure.KindOf(err) == failure.KindUnknown {
+		err = failure.Usage(err)
+	}
+	fatalIfErrorf(parser, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if c.CommandTimeout > 0 {
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeout(ctx, c.CommandTimeout)
+		defer timeoutCancel()
+	}
+
+	// The first interrupt cancels the command so that it can stop in-flight
+	// requests and clean up. A second interrupt exits immediately.
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt)
 	defer signal.Stop(sigCh)
 	go func() {
-		defer cancel()
+		<-sigCh
+		fmt.Fprintln(kongCtx.Stderr, "Interrupted, stopping. Press Ctrl+C again to exit immediately.")
+		cancel()
 		<-sigCh
 		kongCtx.Exit(1)
 	}()
 
+	hooks := &cleanup.Hooks{}
 	kongCtx.BindTo(ctx, (*context.Context)(nil))
-	kongCtx.FatalIfErrorf(kongCtx.Run())
+	kongCtx.Bind(hooks)
+
+	// Telemetry is only collected for Upbound profiles if the user has not
+	// opted out, and not before the notice was shown. Nothing is written to
+	// disk or sent over the network otherwise. Events are spooled when the
+	// command exits and sent in the background of a later invocation.
+	tel := newTelemetry(kongCtx)
+	switch {
+	case tel == nil:
+	case bool(c.Quiet) && !tel.NoticeShown():
+		// The notice can't be shown, so nothing is collected yet.
+		tel = nil
+	case tel.ShowNotice(kongCtx.Stderr):
+		// Nothing is collected until the user had a chance to opt out.
+		tel = nil
+	}
+	if tel != nil {
+		telemetry.DefaultRecorder = tel
+		go tel.Flush(ctx)
+	}
+
+	start := time.Now()
+	err = kongCtx.Run()
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && err != nil {
+			err = errors.Wrapf(err, "timed out after %s", c.CommandTimeout)
+		}
+		if hooks.Len() > 0 {
+			fmt.Fprintln(kongCtx.Stderr, "Cleaning up partially completed work...")
+			cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), cleanupTimeout)
+			if cerr := hooks.Run(cleanupCtx); cerr != nil {
+				fmt.Fprintln(kongCtx.Stderr, cerr)
+			}
+			cleanupCancel()
+		}
+	}
+	if tel != nil {
+		tel.Record(telemetry.Command(kongCtx.Command(), err, time.Since(start)))
+		tel.Close()
+	}
+	fatalIfErrorf(kongCtx.Kong, err)
+}
+
+// readDefaults reads the defaults file, if any.
+func readDefaults() (*config.Defaults, error) {
+	path, err := config.GetDefaultsPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.ReadDefaults(afero.NewOsFs(), path)
+}
+
+// fatalIfErrorf reports the supplied error like kong does, but exits with the
+// code of the kind of failure so that automation can tell failures apart.
+func fatalIfErrorf(k *kong.Kong, err error) {
+	if err == nil {
+		return
+	}
+	exit, code := k.Exit, failure.ExitCode(err)
+	k.Exit = func(int) { exit(code) }
+	k.FatalIfErrorf(err)
 }
@@ -0,0 +1,107 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/upbound"
+)
+
+// TestCLI guards against flags of subcommands colliding with global flags,
+// which kong only detects when the parser is constructed.
+func TestCLI(t *testing.T) {
+	if _, err := kong.New(&cli{}); err != nil {
+		t.Errorf("kong.New(...): %v", err)
+	}
+}
+
+func TestTelemetryAPIEndpoint(t *testing.T) {
+	conf := &config.Config{Upbound: config.Upbound{
+		Default: "cloud",
+		Profiles: map[string]profile.Profile{
+			"cloud":  {ID: "someone", Type: profile.User, BaseConfig: map[string]string{"domain": "https://acme.example"}},
+			"custom": {ID: "someone", Type: profile.User, APIEndpoint: "https://api.custom.example"},
+			"space":  {Type: profile.Space},
+		},
+	}}
+	type withFlags struct {
+		Flags upbound.Flags `embed:""`
+	}
+	type withoutFlags struct{}
+
+	cases := map[string]struct {
+		reason string
+		cli    any
+		args   []string
+		want   string
+	}{
+		"DefaultDomain": {
+			reason: "The API of the --domain flag's default should be used.",
+			cli:    &withFlags{},
+			want:   "https://api.upbound.io",
+		},
+		"DomainFlag": {
+			reason: "The API of the --domain should be used.",
+			cli:    &withFlags{},
+			args:   []string{"--domain=https://other.example"},
+			want:   "https://api.other.example",
+		},
+		"ProfileEndpoint": {
+			reason: "The API endpoint of the profile should be used.",
+			cli:    &withFlags{},
+			args:   []string{"--profile=custom"},
+			want:   "https://api.custom.example",
+		},
+		"ProfileDomain": {
+			reason: "The domain of the profile should be used by commands without a --domain flag.",
+			cli:    &withoutFlags{},
+			want:   "https://api.acme.example",
+		},
+		"SpaceProfile": {
+			reason: "Nothing should be sent for Space profiles.",
+			cli:    &withFlags{},
+			args:   []string{"--profile=space"},
+		},
+		"MissingProfile": {
+			reason: "Nothing should be sent without an Upbound profile.",
+			cli:    &withFlags{},
+			args:   []string{"--profile=missing"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parser, err := kong.New(tc.cli)
+			if err != nil {
+				t.Fatalf("kong.New(...): %v", err)
+			}
+			kongCtx, err := parser.Parse(tc.args)
+			if err != nil {
+				t.Fatalf("Parse(...): %v", err)
+			}
+			got := ""
+			if u := telemetryAPIEndpoint(kongCtx, conf); u != nil {
+				got = u.String()
+			}
+			if got != tc.want {
+				t.Errorf("\n%s\ntelemetryAPIEndpoint(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
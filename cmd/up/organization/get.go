@@ -39,11 +39,11 @@ type getCmd struct {
 }
 
 // Run executes the get command.
-func (c *getCmd) Run(printer upterm.ObjectPrinter, oc *organizations.Client, upCtx *upbound.Context) error {
+func (c *getCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, oc *organizations.Client, upCtx *upbound.Context) error {
 
 	// The get command accepts a name, but the get API call takes an ID
 	// Therefore we get all orgs and find the one the user requested
-	orgs, err := oc.List(context.Background())
+	orgs, err := oc.List(ctx)
 	if err != nil {
 		return err
 	}
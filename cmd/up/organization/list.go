@@ -39,8 +39,8 @@ type listCmd struct{}
 var fieldNames = []string{"ID", "NAME", "ROLE"}
 
 // Run executes the list command.
-func (c *listCmd) Run(printer upterm.ObjectPrinter, p pterm.TextPrinter, oc *organizations.Client, upCtx *upbound.Context) error {
-	orgs, err := oc.List(context.Background())
+func (c *listCmd) Run(ctx context.Context, printer upterm.ObjectPrinter, p pterm.TextPrinter, oc *organizations.Client, upCtx *upbound.Context) error {
+	orgs, err := oc.List(ctx)
 	if err != nil {
 		return err
 	}
@@ -16,6 +16,7 @@ package config
 
 // Cmd contains commands for configuring Upbound Profiles.
 type Cmd struct {
-	Set   setCmd   `cmd:"" help:"Set base configuration key, value pair in the Upbound Profile."`
-	UnSet unsetCmd `cmd:"" name:"unset" help:"Unset base configuration key, value pair in the Upbound Profile."`
+	Set     setCmd     `cmd:"" help:"Set base configuration key, value pair in the Upbound Profile."`
+	UnSet   unsetCmd   `cmd:"" name:"unset" help:"Unset base configuration key, value pair in the Upbound Profile."`
+	Migrate migrateCmd `cmd:"" help:"Write the format and base configuration of the Upbound Profile to the defaults file, which applies to all profiles."`
 }
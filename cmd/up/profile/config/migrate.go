@@ -0,0 +1,60 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const errFmtDefaultsExist = "defaults file %s already exists, use --force to overwrite it"
+
+type migrateCmd struct {
+	Force bool `help:"Overwrite an existing defaults file."`
+
+	fs afero.Fs
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *migrateCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+	return nil
+}
+
+// Run executes the migrate command.
+func (c *migrateCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	path, err := config.GetDefaultsPath()
+	if err != nil {
+		return err
+	}
+	if _, err := c.fs.Stat(path); err == nil && !c.Force {
+		return errors.Errorf(errFmtDefaultsExist, path)
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	d := config.MigrateDefaults(upCtx.Cfg)
+	if err := config.WriteDefaults(c.fs, path, d); err != nil {
+		return err
+	}
+	p.Printfln("Wrote defaults of the current profile to %s", path)
+	return nil
+}
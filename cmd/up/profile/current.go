@@ -33,12 +33,12 @@ type output struct {
 
 // Run executes the current command.
 func (c *currentCmd) Run(ctx *kong.Context, upCtx *upbound.Context) error {
-	name, prof, err := upCtx.Cfg.GetDefaultUpboundProfile()
+	name, prof, err := upCtx.Profiles.Current()
 	if err != nil {
 		return err
 	}
 
-	redacted := profile.Redacted{Profile: prof}
+	redacted := profile.Redacted{Profile: withStoredSession(upCtx, name, prof)}
 
 	b, err := json.MarshalIndent(output{
 		Name:    name,
@@ -15,8 +15,6 @@
 package profile
 
 import (
-	"sort"
-
 	"github.com/alecthomas/kong"
 	"github.com/pterm/pterm"
 
@@ -34,45 +32,21 @@ type listCmd struct{}
 
 // Run executes the list command.
 func (c *listCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, ctx *kong.Context, upCtx *upbound.Context) error {
-	profiles, err := upCtx.Cfg.GetUpboundProfiles()
-	if err != nil {
+	profiles, err := upCtx.Profiles.List()
+	if err != nil || len(profiles) == 0 {
 		p.Println(errNoProfiles)
 		return nil // nolint:nilerr
 	}
 
-	redacted := make(map[string]profile.Redacted)
-	for k, v := range profiles {
-		redacted[k] = profile.Redacted{Profile: v}
-	}
-	if len(redacted) == 0 {
-		p.Println(errNoProfiles)
-		return nil
-	}
-
-	// sort the redacted profiles by name so that we have a consistent listing
-	profileNames := make([]string, 0, len(redacted))
-	for k := range redacted {
-		profileNames = append(profileNames, k)
-	}
-	sort.Strings(profileNames)
-
-	dprofile, _, err := upCtx.Cfg.GetDefaultUpboundProfile()
-	if err != nil {
-		return err
-	}
-
-	data := make([][]string, len(redacted)+1)
-	cursor := ""
-
+	data := make([][]string, len(profiles)+1)
 	data[0] = []string{"CURRENT", "NAME", "TYPE", "ACCOUNT", "KUBECONFIG", "KUBECONTEXT"}
-	for i, name := range profileNames {
-		if name == dprofile {
+	for i, np := range profiles {
+		cursor := ""
+		if np.Current {
 			cursor = "*"
 		}
-		prof := redacted[name]
-		data[i+1] = []string{cursor, name, string(prof.Type), prof.Account, prof.Kubeconfig, prof.KubeContext}
-
-		cursor = "" // reset cursor
+		prof := profile.Redacted{Profile: np.Profile}
+		data[i+1] = []string{cursor, np.Name, string(prof.Type), prof.Account, prof.Kubeconfig, prof.KubeContext}
 	}
 
 	return pt.WithHasHeader().WithData(data).Render()
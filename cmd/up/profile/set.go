@@ -26,6 +26,7 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 
+	"github.com/upbound/up/internal/capability"
 	"github.com/upbound/up/internal/profile"
 	"github.com/upbound/up/internal/upbound"
 
@@ -46,7 +47,8 @@ type setCmd struct {
 type spaceCmd struct {
 	Kube upbound.KubeFlags `embed:""`
 
-	getClient func() (kubernetes.Interface, error)
+	getClient  func() (kubernetes.Interface, error)
+	findSpaces func(ctx context.Context) ([]capability.Candidate, error)
 }
 
 //go:embed space_help.txt
@@ -77,6 +79,7 @@ func (c *spaceCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 		KubeContext: c.Kube.GetContext(),
 		// Carry over existing config.
 		BaseConfig: upCtx.Profile.BaseConfig,
+		Format:     upCtx.Profile.Format,
 	}
 
 	installed, err := c.checkForSpaces(ctx)
@@ -84,7 +87,7 @@ func (c *spaceCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 		return err
 	}
 	if !installed {
-		return errors.New(errNoSpace)
+		return c.noSpace(ctx)
 	}
 
 	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(upCtx.ProfileName, prof); err != nil {
@@ -114,6 +117,22 @@ func (c *spaceCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.
 	return nil
 }
 
+// noSpace returns an error suggesting other kubeconfig contexts that serve a
+// Space, if there are any.
+func (c *spaceCmd) noSpace(ctx context.Context) error {
+	find := c.findSpaces
+	if find == nil {
+		find = func(ctx context.Context) ([]capability.Candidate, error) {
+			return capability.NewFinder().FindInFile(ctx, c.Kube.Kubeconfig)
+		}
+	}
+	found, err := find(ctx)
+	if err != nil || len(found) == 0 {
+		return errors.New(errNoSpace)
+	}
+	return &capability.NoSpaceError{Context: c.Kube.GetContext(), Candidates: found}
+}
+
 func (c *spaceCmd) checkForSpaces(ctx context.Context) (bool, error) {
 	kubeconfig := c.Kube.GetConfig()
 	var kClient kubernetes.Interface
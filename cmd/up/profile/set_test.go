@@ -32,6 +32,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	"github.com/upbound/up/internal/capability"
 	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/profile"
 	"github.com/upbound/up/internal/upbound"
@@ -71,8 +72,9 @@ func TestSpaceCmd_Run(t *testing.T) {
 	}
 
 	type args struct {
-		ctx       *upbound.Context
-		getClient func() (kubernetes.Interface, error)
+		ctx        *upbound.Context
+		getClient  func() (kubernetes.Interface, error)
+		findSpaces func(ctx context.Context) ([]capability.Candidate, error)
 	}
 	type want struct {
 		cfg *config.Config
@@ -118,12 +120,37 @@ func TestSpaceCmd_Run(t *testing.T) {
 				getClient: func() (kubernetes.Interface, error) {
 					return fake.NewSimpleClientset(), nil
 				},
+				findSpaces: func(ctx context.Context) ([]capability.Candidate, error) {
+					return nil, nil
+				},
 			},
 			want: want{
 				err: errors.New(errNoSpace),
 				cfg: &config.Config{},
 			},
 		},
+		"NoSpacesSuggestContexts": {
+			reason: "Other kubeconfig contexts that serve a Space should be suggested.",
+			args: args{
+				ctx: &upbound.Context{
+					Account: "test-account",
+					Cfg:     &config.Config{},
+				},
+				getClient: func() (kubernetes.Interface, error) {
+					return fake.NewSimpleClientset(), nil
+				},
+				findSpaces: func(ctx context.Context) ([]capability.Candidate, error) {
+					return []capability.Candidate{{Context: "other-context", Version: "v1.3.0"}}, nil
+				},
+			},
+			want: want{
+				err: &capability.NoSpaceError{
+					Context:    "default-context",
+					Candidates: []capability.Candidate{{Context: "other-context", Version: "v1.3.0"}},
+				},
+				cfg: &config.Config{},
+			},
+		},
 		"KubeClientError": {
 			reason: "The kube clients returns a non-NotFound error.",
 			args: args{
@@ -285,7 +312,7 @@ func TestSpaceCmd_Run(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			cmd := &spaceCmd{Kube: upbound.KubeFlags{Kubeconfig: kubeconfig}, getClient: tc.args.getClient}
+			cmd := &spaceCmd{Kube: upbound.KubeFlags{Kubeconfig: kubeconfig}, getClient: tc.args.getClient, findSpaces: tc.args.findSpaces}
 			if diff := cmp.Diff(nil, cmd.AfterApply(nil), test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nspaceCmd.AfterApply(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
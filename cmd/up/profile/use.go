@@ -30,9 +30,5 @@ type useCmd struct {
 
 // Run executes the Use command.
 func (c *useCmd) Run(upCtx *upbound.Context) error {
-	if err := upCtx.Cfg.SetDefaultUpboundProfile(c.Name); err != nil {
-		return err
-	}
-
-	return errors.Wrap(upCtx.CfgSrc.UpdateConfig(upCtx.Cfg), errUpdateProfile)
+	return errors.Wrap(upCtx.Profiles.Switch(c.Name), errUpdateProfile)
 }
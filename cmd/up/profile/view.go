@@ -22,6 +22,7 @@ import (
 	"github.com/pterm/pterm"
 
 	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/tokenstore"
 	"github.com/upbound/up/internal/upbound"
 )
 
@@ -41,7 +42,7 @@ func (c *viewCmd) Run(p pterm.TextPrinter, ctx *kong.Context, upCtx *upbound.Con
 
 	redacted := make(map[string]profile.Redacted)
 	for k, v := range profiles {
-		redacted[k] = profile.Redacted{Profile: v}
+		redacted[k] = profile.Redacted{Profile: withStoredSession(upCtx, k, v)}
 	}
 
 	b, err := json.MarshalIndent(redacted, "", "    ")
@@ -51,3 +52,16 @@ func (c *viewCmd) Run(p pterm.TextPrinter, ctx *kong.Context, upCtx *upbound.Con
 	fmt.Fprintln(ctx.Stdout, string(b))
 	return nil
 }
+
+// withStoredSession returns the supplied profile with its session read from
+// the token store if the config file doesn't carry one, so that a session is
+// reported as redacted rather than missing.
+func withStoredSession(upCtx *upbound.Context, name string, p profile.Profile) profile.Profile {
+	if p.Session != "" || p.IsSpace() || upCtx.Tokens == nil {
+		return p
+	}
+	if s, err := upCtx.Tokens.Get(tokenstore.SessionKey(name)); err == nil {
+		p.Session = s
+	}
+	return p
+}
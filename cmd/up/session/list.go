@@ -0,0 +1,51 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/auth"
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/printer"
+	"github.com/upbound/up/internal/upbound"
+)
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *listCmd) AfterApply(kongCtx *kong.Context) error {
+	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
+	return nil
+}
+
+// listCmd lists the sessions and personal access tokens of the user.
+type listCmd struct{}
+
+// Run executes the list command.
+func (c *listCmd) Run(ctx context.Context, pr *printer.Printer, p pterm.TextPrinter, sc *auth.SessionClient, upCtx *upbound.Context) error {
+	ss, err := sc.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr.DefaultFormat(config.Format(upCtx.Profile.Format))
+	if len(ss) == 0 && !pr.Structured() {
+		p.Println("No sessions found")
+		return nil
+	}
+	return pr.Print(ss, printer.SessionColumns)
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/auth"
+)
+
+// revokeCmd revokes a session or personal access token.
+type revokeCmd struct {
+	Kind auth.SessionKind `arg:"" required:"" enum:"session,token" help:"Kind of credential to revoke. Either session or token."`
+	ID   uuid.UUID        `arg:"" required:"" help:"ID of the session or personal access token."`
+}
+
+// Run executes the revoke command.
+func (c *revokeCmd) Run(ctx context.Context, p pterm.TextPrinter, sc *auth.SessionClient) error {
+	if err := sc.Revoke(ctx, c.Kind, c.ID); err != nil {
+		return err
+	}
+	p.Printfln("%s %s revoked", c.Kind, c.ID)
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/upbound/up/internal/auth"
+	"github.com/upbound/up/internal/upbound"
+)
+
+// AfterApply constructs and binds a session client to any subcommands that
+// have Run() methods that receive it.
+func (c *Cmd) AfterApply(kongCtx *kong.Context) error {
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	if upCtx.Profile.IsSpace() {
+		return fmt.Errorf("sessions are not supported for space profile %q", upCtx.ProfileName)
+	}
+	cfg, err := upCtx.BuildSDKConfig()
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	kongCtx.Bind(auth.NewSessionClient(cfg.Client))
+	return nil
+}
+
+// Cmd contains commands for interacting with sessions and personal access
+// tokens.
+type Cmd struct {
+	List   listCmd   `cmd:"" help:"List active sessions and personal access tokens."`
+	Revoke revokeCmd `cmd:"" help:"Revoke a session or personal access token."`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
@@ -15,5 +15,7 @@
 package billing
 
 type Cmd struct {
-	Export exportCmd `cmd:"" help:"Export a billing report for submission to Upbound."`
+	Export   exportCmd   `cmd:"" help:"Export a billing report for submission to Upbound."`
+	Validate validateCmd `cmd:"" help:"Check an exported billing report for missing usage data."`
+	Snapshot snapshotCmd `cmd:"" help:"Count managed resources directly from the control planes of a Space."`
 }
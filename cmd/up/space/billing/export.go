@@ -16,11 +16,13 @@ package billing
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"context"
 	_ "embed"
 	"fmt"
+	"io"
 	"io/fs"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -28,32 +30,53 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/alecthomas/kong"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
 	gcpopt "google.golang.org/api/option"
+	gcphttp "google.golang.org/api/transport/http"
 
+	uphttp "github.com/upbound/up/internal/http"
+	"github.com/upbound/up/internal/metrics"
 	usageaws "github.com/upbound/up/internal/usage/aws"
 	"github.com/upbound/up/internal/usage/azure"
+	"github.com/upbound/up/internal/usage/encryption"
 	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/event/reader"
 	"github.com/upbound/up/internal/usage/gcp"
+	usageprometheus "github.com/upbound/up/internal/usage/prometheus"
 	"github.com/upbound/up/internal/usage/report"
+	reportcsv "github.com/upbound/up/internal/usage/report/file/csv"
+	reportparquet "github.com/upbound/up/internal/usage/report/file/parquet"
 	reporttar "github.com/upbound/up/internal/usage/report/file/tar"
+	"github.com/upbound/up/internal/usage/sender"
 	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
 const (
-	providerAWS   = "aws"
-	providerGCP   = "gcp"
-	providerAzure = "azure"
+	providerAWS        = "aws"
+	providerGCP        = "gcp"
+	providerAzure      = "azure"
+	providerPrometheus = "prometheus"
+
+	formatTGZ     = "tgz"
+	formatCSV     = "csv"
+	formatParquet = "parquet"
 
 	errFmtProviderNotSupported = "%q is not supported"
 )
 
+// dateRange is a billing period parsed with a usagetime.RangeParser. The start
+// of the range is inclusive and the end is exclusive.
 type dateRange usagetime.Range
 
 func (d *dateRange) Decode(ctx *kong.DecodeContext) error {
@@ -61,25 +84,11 @@ func (d *dateRange) Decode(ctx *kong.DecodeContext) error {
 	if err := ctx.Scan.PopValueInto("date range", &value); err != nil {
 		return err
 	}
-
-	parts := strings.SplitN(value, "/", 2)
-	if len(parts) != 2 {
-		fmt.Printf("%s\n", parts)
-		return fmt.Errorf("invalid format")
-	}
-
-	start, err := time.Parse(time.DateOnly, parts[0])
+	r, err := usagetime.RangeParser{}.Parse(value)
 	if err != nil {
 		return err
 	}
-	d.Start = start
-
-	end, err := time.Parse(time.DateOnly, parts[1])
-	if err != nil {
-		return err
-	}
-	d.End = end
-
+	*d = dateRange(r)
 	return nil
 }
 
@@ -93,27 +102,64 @@ func (p provider) Validate() error {
 		return nil
 	case providerAzure:
 		return nil
+	case providerPrometheus:
+		return nil
 	default:
 		return fmt.Errorf(errFmtProviderNotSupported, p)
 	}
 }
 
 type exportCmd struct {
-	Out string `optional:"" short:"o" env:"UP_BILLING_OUT" default:"upbound_billing_report.tgz" help:"Name of the output file."`
+	Out    string `optional:"" short:"o" env:"UP_BILLING_OUT" default:"upbound_billing_report.tgz" help:"Name of the output file."`
+	Format string `optional:"" name:"report-format" enum:"tgz,csv,parquet" env:"UP_BILLING_FORMAT" default:"tgz" help:"Format of the report. Must be one of: tgz, csv, parquet. The metadata of a CSV report is written next to it to a file with the suffix .meta.json."`
+
+	Compression string `enum:"none,gzip,zstd" default:"gzip" env:"UP_BILLING_COMPRESSION" group:"Archive" help:"Compression of tgz reports. Must be one of: none, gzip, zstd."`
+	MaxPartSize int    `env:"UP_BILLING_MAX_PART_SIZE" group:"Archive" help:"Split tgz reports into archives of about this many bytes of uncompressed usage data, listed in an index. Zero disables splitting."`
 
 	// TODO(branden): Make storage params optional and fetch missing values from spaces cluster.
-	Provider            provider `required:"" enum:"aws,gcp,azure," env:"UP_BILLING_PROVIDER" group:"Storage" help:"Storage provider. Must be one of: aws, gcp, azure."`
-	Bucket              string   `required:"" env:"UP_BILLING_BUCKET" group:"Storage" help:"Storage bucket."`
-	Endpoint            string   `env:"UP_BILLING_ENDPOINT" group:"Storage" help:"Custom storage endpoint."`
+	Provider            provider `required:"" enum:"aws,gcp,azure,prometheus," env:"UP_BILLING_PROVIDER" group:"Storage" help:"Storage provider. Must be one of: aws, gcp, azure, prometheus."`
+	Bucket              string   `optional:"" env:"UP_BILLING_BUCKET" group:"Storage" help:"Storage bucket. Required for --provider=aws, gcp, and azure."`
+	Endpoint            string   `env:"UP_BILLING_ENDPOINT" group:"Storage" help:"Custom storage endpoint. For --provider=prometheus, the address of the Prometheus server."`
 	Account             string   `required:"" env:"UP_BILLING_ACCOUNT" group:"Storage" help:"Name of the Upbound account whose billing report is being collected."`
 	AzureStorageAccount string   `optional:"" env:"UP_AZURE_STORAGE_ACCOUNT" group:"Storage" help:"Name of the Azure storage account. Required for --provider=azure."`
+	AWSProfile          string   `optional:"" name:"aws-profile" env:"UP_AWS_PROFILE" group:"Storage" help:"Name of the AWS profile to load credentials and configuration from. Only supported for --provider=aws."`
+	AWSRegion           string   `optional:"" name:"aws-region" env:"UP_AWS_REGION" group:"Storage" help:"AWS region of the bucket. Only supported for --provider=aws."`
+	AWSRoleARN          string   `optional:"" name:"aws-role-arn" env:"UP_AWS_ROLE_ARN" group:"Storage" help:"ARN of an AWS IAM role to assume before reading the bucket. Only supported for --provider=aws."`
+	AWSExternalID       string   `optional:"" name:"aws-external-id" env:"UP_AWS_EXTERNAL_ID" group:"Storage" help:"External ID to use when assuming --aws-role-arn."`
 
 	BillingMonth    time.Time  `format:"2006-01" required:"" xor:"billingperiod" env:"UP_BILLING_MONTH" group:"Billing period" help:"Export a report for a billing period of one calendar month. Format: 2006-01."`
-	BillingCustom   *dateRange `required:"" xor:"billingperiod" env:"UP_BILLING_CUSTOM" group:"Billing period" help:"Export a report for a custom billing period. Date range is inclusive. Format: 2006-01-02/2006-01-02."`
+	BillingCustom   *dateRange `required:"" xor:"billingperiod" env:"UP_BILLING_CUSTOM" group:"Billing period" help:"Export a report for a custom billing period. Accepts an inclusive date range such as 2006-01-02/2006-01-02, a month such as 2006-01, or an expression such as \"last month\" or \"last 7d\"."`
 	ForceIncomplete bool       `env:"UP_BILLING_FORCE_INCOMPLETE" group:"Billing period" help:"Export a report for an incomplete billing period."`
 
+	WindowUnit     string        `enum:"hour,day,month" default:"hour" env:"UP_BILLING_WINDOW_UNIT" group:"Windows" help:"Calendar unit of the windows that usage is aggregated over. Must be one of: hour, day, month."`
+	WindowOffset   time.Duration `env:"UP_BILLING_WINDOW_OFFSET" group:"Windows" help:"Shift window boundaries from the start of their unit, e.g. 6h. Must be a whole number of hours."`
+	WindowTimezone string        `default:"UTC" env:"UP_BILLING_WINDOW_TIMEZONE" group:"Windows" help:"IANA timezone in which window boundaries are computed, e.g. America/New_York."`
+
+	Dedup bool `env:"UP_BILLING_DEDUP" help:"Drop duplicate usage events, such as events read by overlapping windows. The number of events read and dropped is printed and recorded in the report's metadata."`
+
+	Estimate bool `env:"UP_BILLING_ESTIMATE" help:"List the usage data in storage and print its size without exporting a report. Not supported for --provider=prometheus."`
+
+	Checkpoint string `env:"UP_BILLING_CHECKPOINT" help:"Record export progress in this file. If the export is interrupted, running the same command again resumes it from the last checkpoint. Only supported for --report-format=csv without encryption."`
+
+	MetricsAddr string `env:"UP_BILLING_METRICS_ADDR" group:"Metrics" help:"Serve Prometheus metrics at /metrics on this address while exporting, e.g. :8080."`
+	MetricsFile string `env:"UP_BILLING_METRICS_FILE" group:"Metrics" help:"Write Prometheus metrics to this file once the export finishes."`
+
+	EncryptRecipient []string `env:"UP_BILLING_ENCRYPT_RECIPIENT" group:"Encryption" help:"Encrypt the report for the OpenPGP public key in this file. Repeatable."`
+	EncryptArmor     bool     `env:"UP_BILLING_ENCRYPT_ARMOR" group:"Encryption" help:"Write an ASCII armored encrypted report."`
+
+	UploadEndpoint string `env:"UP_BILLING_UPLOAD_ENDPOINT" group:"Upload" help:"Upload the report to the Upbound usage ingestion API at this endpoint once it is exported."`
+	UploadToken    string `env:"UP_BILLING_UPLOAD_TOKEN" group:"Upload" help:"Bearer token used to authenticate the upload."`
+
 	outAbs        string
 	billingPeriod usagetime.Range
+	metrics       *metrics.Registry
+	dedup         *reader.Deduplicator
+	encrypter     *encryption.Encrypter
+	uploadURL     *url.URL
+	windowSpec    usagetime.WindowSpec
+	checkpoints   *report.CheckpointFile
+	resume        report.Checkpoint
+	log           logging.Logger
 }
 
 //go:embed export_help.txt
@@ -124,6 +170,18 @@ func (c *exportCmd) Help() string {
 }
 
 func (c *exportCmd) Validate() error {
+	if c.Provider == providerPrometheus {
+		if c.Endpoint == "" {
+			return fmt.Errorf("--endpoint must be set for --provider=prometheus")
+		}
+		if c.Estimate {
+			// Prometheus usage is queried rather than stored as objects, so
+			// there is nothing to size.
+			return fmt.Errorf("--estimate is not supported for --provider=prometheus")
+		}
+	} else if c.Bucket == "" {
+		return fmt.Errorf("--bucket must be set for --provider=%s", c.Provider)
+	}
 	if c.Provider == providerAzure {
 		if c.AzureStorageAccount == "" {
 			return fmt.Errorf("--azure-storage-account must be set for --provider=azure")
@@ -132,6 +190,45 @@ func (c *exportCmd) Validate() error {
 			return fmt.Errorf("--endpoint is not supported for --provider=azure")
 		}
 	}
+	if c.Provider != providerAWS && (c.AWSProfile != "" || c.AWSRegion != "" || c.AWSRoleARN != "") {
+		return fmt.Errorf("--aws-profile, --aws-region, and --aws-role-arn are only supported for --provider=aws")
+	}
+	if c.AWSExternalID != "" && c.AWSRoleARN == "" {
+		return fmt.Errorf("--aws-external-id requires --aws-role-arn")
+	}
+	if c.EncryptArmor && len(c.EncryptRecipient) == 0 {
+		return fmt.Errorf("--encrypt-armor requires --encrypt-recipient")
+	}
+	if err := c.loadRecipients(); err != nil {
+		return err
+	}
+	if c.Format != formatTGZ && (c.Compression != string(reporttar.CompressionGzip) || c.MaxPartSize != 0) {
+		return fmt.Errorf("--compression and --max-part-size are only supported for --report-format=tgz")
+	}
+	if c.MaxPartSize < 0 {
+		return fmt.Errorf("--max-part-size must not be negative")
+	}
+	if c.MaxPartSize > 0 {
+		if c.encrypter != nil {
+			return fmt.Errorf("--max-part-size is not supported with --encrypt-recipient")
+		}
+		if c.UploadEndpoint != "" {
+			return fmt.Errorf("--max-part-size is not supported with --upload-endpoint")
+		}
+	}
+	if c.UploadToken != "" && c.UploadEndpoint == "" {
+		return fmt.Errorf("--upload-token requires --upload-endpoint")
+	}
+	if c.UploadEndpoint != "" {
+		if c.Estimate {
+			return fmt.Errorf("--upload-endpoint is not supported with --estimate")
+		}
+		u, err := url.Parse(c.UploadEndpoint)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("--upload-endpoint must be an absolute URL")
+		}
+		c.uploadURL = u
+	}
 
 	// Get billing period.
 	var err error
@@ -140,105 +237,559 @@ func (c *exportCmd) Validate() error {
 		return errors.Wrap(err, "error getting billing period")
 	}
 
+	c.windowSpec, err = c.getWindowSpec()
+	if err != nil {
+		return err
+	}
+
 	// Validate billing period.
 	now := time.Now()
 	if !c.ForceIncomplete && c.billingPeriod.Start.Before(now) && c.billingPeriod.End.After(now) {
 		return fmt.Errorf("billing period is incomplete, use --force-incomplete to continue")
 	}
 
-	// Validate output filename.
+	if c.Estimate {
+		if c.Checkpoint != "" {
+			return fmt.Errorf("--checkpoint is not supported with --estimate")
+		}
+		return nil
+	}
+
+	if err := c.loadCheckpoint(); err != nil {
+		return err
+	}
+
+	// Validate output filename. Split reports are written next to it, and
+	// the index takes its place.
 	c.outAbs, err = filepath.Abs(c.Out)
 	if err != nil {
 		return err
 	}
+	if c.MaxPartSize > 0 {
+		c.outAbs = filepath.Join(filepath.Dir(c.outAbs), reporttar.IndexFile(c.splitName()))
+	}
 	_, err = os.Stat(c.outAbs)
+	if !c.resume.IsZero() {
+		if err != nil {
+			return errors.Wrapf(err, "cannot resume export to \"%s\"", c.Out)
+		}
+		return nil
+	}
 	if !errors.Is(err, fs.ErrNotExist) {
-		return fmt.Errorf("file \"%s\" already exists", c.Out)
+		return fmt.Errorf("file \"%s\" already exists", c.outAbs)
+	}
+	return nil
+}
+
+// splitName returns the name of the archives of a split report, which is the
+// output filename without its archive extension.
+func (c *exportCmd) splitName() string {
+	name := filepath.Base(c.Out)
+	for _, ext := range []string{".tgz", ".tar.gz", ".tar.zst", ".tar"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
+// loadCheckpoint reads the checkpoint of an interrupted export, if any.
+func (c *exportCmd) loadCheckpoint() error {
+	if c.Checkpoint == "" {
+		return nil
+	}
+	if c.Format != formatCSV {
+		return fmt.Errorf("--checkpoint is only supported for --report-format=csv")
 	}
+	if c.encrypter != nil {
+		return fmt.Errorf("--checkpoint is not supported with --encrypt-recipient")
+	}
+	c.checkpoints = report.NewCheckpointFile(c.Checkpoint)
+	cp, err := c.checkpoints.ReadCheckpoint()
+	if err != nil {
+		return err
+	}
+	if !cp.IsZero() && (!cp.TimeRange.Start.Equal(c.billingPeriod.Start) || !cp.TimeRange.End.Equal(c.billingPeriod.End)) {
+		return fmt.Errorf("checkpoint \"%s\" belongs to an export of a different billing period", c.Checkpoint)
+	}
+	c.resume = cp
 	return nil
 }
 
-func (c *exportCmd) Run() error {
-	fmt.Printf(
-		"Exporting billing report for Upbound account %s from %s to %s.\n",
-		c.Account,
-		formatTimestamp(c.billingPeriod.Start),
-		formatTimestamp(c.billingPeriod.End),
+func (c *exportCmd) Run(p pterm.TextPrinter, log logging.Logger) error {
+	c.log = log
+	c.log.Info("Exporting billing report",
+		"account", c.Account,
+		"start", formatTimestamp(c.billingPeriod.Start),
+		"end", formatTimestamp(c.billingPeriod.End),
 	)
-	fmt.Printf("\n")
-	fmt.Printf("Reading usage data from storage...\n")
-	fmt.Printf("Provider: %s\n", c.Provider)
-	fmt.Printf("Bucket: %s\n", c.Bucket)
-	if c.Endpoint != "" {
-		fmt.Printf("Endpoint: %s\n", c.Endpoint)
+	c.log.Info("Reading usage data from storage", "provider", c.Provider, "bucket", c.Bucket, "endpoint", c.Endpoint)
+
+	if c.MetricsAddr != "" || c.MetricsFile != "" {
+		c.metrics = metrics.New()
+		defer c.dumpMetrics()
 	}
 
+	if c.Estimate {
+		return c.estimateReport(p)
+	}
+
+	if c.Dedup {
+		c.dedup = reader.NewDeduplicator()
+	}
+	if !c.resume.IsZero() {
+		c.log.Info("Resuming from checkpoint", "checkpoint", c.Checkpoint, "after", formatTimestamp(c.resume.Window.End))
+	}
 	if err := c.collectReport(); err != nil {
+		if c.checkpoints != nil {
+			c.log.Info("Export progress saved. Run the same command again to resume.", "checkpoint", c.Checkpoint)
+			return err
+		}
 		c.cleanupOnError()
 		return err
 	}
+	if c.checkpoints != nil {
+		if err := c.checkpoints.Remove(); err != nil {
+			return errors.Wrap(err, "error removing checkpoint")
+		}
+	}
+
+	p.Printfln("Billing report saved to %s", c.outAbs)
+	if c.dedup != nil {
+		s := c.dedup.Stats()
+		p.Printfln("Dropped %d duplicate events of %d read", s.Dropped, s.Read)
+	}
 
-	fmt.Printf("\n")
-	fmt.Printf("Billing report saved to %s\n", c.outAbs)
+	if c.uploadURL == nil {
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	id, err := c.uploadReport(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error uploading report")
+	}
+	p.Printfln("Billing report uploaded as %s", id)
 	return nil
 }
 
+// uploadReport uploads the exported report to the usage ingestion API and
+// returns the ID of the upload.
+func (c *exportCmd) uploadReport(ctx context.Context, opts ...sender.SenderModifierFn) (string, error) {
+	f, err := os.Open(c.outAbs)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() // nolint:errcheck
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	s := sender.NewSender(append([]sender.SenderModifierFn{
+		sender.WithEndpoint(c.uploadURL),
+		sender.WithToken(c.UploadToken),
+		sender.WithAccount(c.Account),
+		sender.WithLogger(c.log),
+	}, opts...)...)
+	u, err := s.Send(ctx, f, fi.Size())
+	if err != nil {
+		return "", err
+	}
+	return u.ID, nil
+}
+
 func (c *exportCmd) cleanupOnError() {
-	if err := os.Remove(c.outAbs); err != nil {
-		fmt.Fprintf(os.Stderr, "error cleaning up: %s", err)
+	files := []string{c.outAbs}
+	if c.Format == formatCSV {
+		files = append(files, c.metaFile())
+	}
+	if c.MaxPartSize > 0 {
+		parts, _ := filepath.Glob(filepath.Join(filepath.Dir(c.outAbs), c.splitName()+".part-*"))
+		files = append(files, parts...)
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.log.Info("Cannot clean up partial report", "error", err)
+		}
 	}
 }
 
-func (c *exportCmd) collectReport() error {
+func (c *exportCmd) dumpMetrics() {
+	if c.MetricsFile == "" {
+		return
+	}
+	f, err := os.Create(c.MetricsFile)
+	if err != nil {
+		c.log.Info("Cannot write metrics", "error", err)
+		return
+	}
+	defer f.Close() // nolint:errcheck
+	if err := c.metrics.Dump(f); err != nil {
+		c.log.Info("Cannot write metrics", "error", err)
+	}
+}
+
+// serveMetrics serves metrics until the supplied context is done, if an
+// address was supplied.
+func (c *exportCmd) serveMetrics(ctx context.Context) {
+	if c.MetricsAddr == "" {
+		return
+	}
+	go func() {
+		if err := c.metrics.Serve(ctx, c.MetricsAddr); err != nil {
+			c.log.Info("Cannot serve metrics", "error", err)
+		}
+	}()
+}
+
+func (c *exportCmd) estimateReport(p pterm.TextPrinter) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
+	c.serveMetrics(ctx)
 
-	// Make event window iterator.
-	window := time.Hour
-	var iter event.WindowIterator
-	var err error
-	switch c.Provider {
-	case providerGCP:
-		iter, err = c.getGCPIter(ctx, window)
-	case providerAWS:
-		iter, err = c.getAWSIter(window)
-	case providerAzure:
-		iter, err = c.getAzureIter(window)
-	default:
-		return fmt.Errorf(errFmtProviderNotSupported, c.Provider)
+	iter, err := c.getIter(ctx)
+	if err != nil {
+		return err
 	}
+	est, err := (&report.Collector{Iter: iter, TimeRange: c.billingPeriod}).Estimate(ctx, c.billingPeriod)
 	if err != nil {
 		return err
 	}
 
+	p.Printfln("Objects: %d", est.Objects)
+	p.Printfln("Size: %d bytes", est.Bytes)
+	return nil
+}
+
+func (c *exportCmd) collectReport() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	c.serveMetrics(ctx)
+
+	iter, err := c.getIter(ctx)
+	if err != nil {
+		return err
+	}
+
+	meta := report.Meta{
+		UpboundAccount: c.Account,
+		TimeRange:      c.billingPeriod,
+		CollectedAt:    time.Now(),
+		Windows:        report.NewWindows(c.windowSpec),
+	}
+	if c.dedup != nil {
+		meta.Dedup = &reader.DedupStats{}
+	}
+	if c.checkpoints != nil {
+		return c.collectCheckpointedReport(ctx, iter, meta)
+	}
+	if c.MaxPartSize > 0 {
+		return c.collectSplitReport(ctx, iter, meta)
+	}
+
 	// Make report writer.
 	f, err := os.Create(c.outAbs)
 	if err != nil {
 		return errors.Wrap(err, "error creating report")
 	}
 	defer f.Close() // nolint:errcheck
-	gw := gzip.NewWriter(f)
-	tw := tar.NewWriter(gw)
-	rw, err := reporttar.NewWriter(tw, report.Meta{
-		UpboundAccount: c.Account,
-		TimeRange:      c.billingPeriod,
-		CollectedAt:    time.Now(),
-	})
+	var w io.Writer = f
+	var ew io.WriteCloser
+	if c.encrypter != nil {
+		ew, err = c.encrypter.Encrypt(f)
+		if err != nil {
+			return errors.Wrap(err, "error creating report")
+		}
+		w = ew
+	}
+	rw, err := c.newReportWriter(w, meta)
 	if err != nil {
 		return errors.Wrap(err, "error creating report")
 	}
 
 	// Write report.
-	if err := report.MaxResourceCountPerGVKPerMXP(ctx, iter, rw); err != nil {
+	if err := c.newCollector(iter, rw).Collect(ctx); err != nil {
+		return err
+	}
+	c.recordDedupStats(meta)
+	if err := rw.Close(); err != nil {
+		return err
+	}
+	if ew != nil {
+		return ew.Close()
+	}
+	return nil
+}
+
+// collectCheckpointedReport collects a CSV report, recording a checkpoint
+// after each window. When resuming, rows are appended to the report written
+// by the interrupted export.
+func (c *exportCmd) collectCheckpointedReport(ctx context.Context, iter event.WindowIterator, meta report.Meta) error {
+	// The metadata is written when the report is closed, so it is replaced
+	// when the export is resumed.
+	mf, err := os.Create(c.metaFile())
+	if err != nil {
+		return errors.Wrap(err, "error creating report metadata")
+	}
+	defer mf.Close() // nolint:errcheck
+
+	var f *os.File
+	var rw *reportcsv.Writer
+	if c.resume.IsZero() {
+		f, err = os.Create(c.outAbs)
+		if err != nil {
+			return errors.Wrap(err, "error creating report")
+		}
+		rw, err = reportcsv.NewWriter(f, meta, reportcsv.WithMetaWriter(mf))
+	} else {
+		f, err = os.OpenFile(c.outAbs, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return errors.Wrap(err, "error opening report")
+		}
+		rw = reportcsv.NewAppendWriter(f, meta, reportcsv.WithMetaWriter(mf))
+	}
+	defer f.Close() // nolint:errcheck
+	if err != nil {
+		return errors.Wrap(err, "error creating report")
+	}
+
+	cw := &syncedCheckpoints{report: rw, file: f, cw: c.checkpoints}
+	if err := cw.sync(); err != nil {
+		return err
+	}
+	col := c.newCollector(iter, rw)
+	col.Checkpoints = cw
+	if err := col.Resume(ctx, c.resume); err != nil {
+		// Drop rows of the interrupted window so they aren't written twice
+		// when the export is resumed.
+		_ = f.Truncate(cw.size)
 		return err
 	}
+	c.recordDedupStats(meta)
 	if err := rw.Close(); err != nil {
 		return err
 	}
-	if err := tw.Close(); err != nil {
+	if err := mf.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// collectSplitReport collects a report into archives of at most
+// --max-part-size bytes of usage data, and an index listing them.
+func (c *exportCmd) collectSplitReport(ctx context.Context, iter event.WindowIterator, meta report.Meta) error {
+	sw, err := reporttar.NewSplitWriter(afero.NewOsFs(), filepath.Dir(c.outAbs), c.splitName(), meta,
+		reporttar.WithCompression(reporttar.Compression(c.Compression)),
+		reporttar.WithMaxPartSize(c.MaxPartSize),
+	)
+	if err != nil {
+		return errors.Wrap(err, "error creating report")
+	}
+	if err := c.newCollector(iter, sw).Collect(ctx); err != nil {
+		return err
+	}
+	c.recordDedupStats(meta)
+	return sw.Close()
+}
+
+// newCollector returns a collector that writes the usage read from iter to w.
+func (c *exportCmd) newCollector(iter event.WindowIterator, w event.Writer) *report.Collector {
+	return &report.Collector{
+		Iter:      iter,
+		Writer:    w,
+		Dedup:     c.dedup,
+		Progress:  &report.MetricsProgress{Registry: c.metrics, Source: string(c.Provider)},
+		TimeRange: c.billingPeriod,
+	}
+}
+
+// recordDedupStats records the number of events read and dropped as
+// duplicates in the report metadata. Report writers write their metadata when
+// closed, so it must be called before closing them.
+func (c *exportCmd) recordDedupStats(meta report.Meta) {
+	if c.dedup != nil && meta.Dedup != nil {
+		*meta.Dedup = c.dedup.Stats()
+	}
+}
+
+// syncedCheckpoints flushes the rows of a report to disk before recording a
+// checkpoint, so that every window recorded as done is in the report. It
+// tracks the size of the report at the last checkpoint.
+type syncedCheckpoints struct {
+	report *reportcsv.Writer
+	file   *os.File
+	cw     report.CheckpointWriter
+	size   int64
+}
+
+func (s *syncedCheckpoints) WriteCheckpoint(cp report.Checkpoint) error {
+	if err := s.sync(); err != nil {
+		return err
+	}
+	return s.cw.WriteCheckpoint(cp)
+}
+
+func (s *syncedCheckpoints) sync() error {
+	if err := s.report.Flush(); err != nil {
+		return errors.Wrap(err, "error writing report")
+	}
+	if err := s.file.Sync(); err != nil {
+		return errors.Wrap(err, "error writing report")
+	}
+	fi, err := s.file.Stat()
+	if err != nil {
+		return errors.Wrap(err, "error writing report")
+	}
+	s.size = fi.Size()
+	return nil
+}
+
+// loadRecipients reads the public keys the report is encrypted for, if any.
+func (c *exportCmd) loadRecipients() error {
+	if len(c.EncryptRecipient) == 0 {
+		return nil
+	}
+	opts := []encryption.Option{}
+	for _, path := range c.EncryptRecipient {
+		keys, err := readKeys(path)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, encryption.WithRecipients(keys...))
+	}
+	if c.EncryptArmor {
+		opts = append(opts, encryption.WithArmor())
+	}
+	e, err := encryption.NewEncrypter(opts...)
+	if err != nil {
+		return err
+	}
+	c.encrypter = e
+	return nil
+}
+
+// readKeys reads OpenPGP keys from a file.
+func readKeys(path string) (openpgp.EntityList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening key file")
+	}
+	defer f.Close() // nolint:errcheck
+	keys, err := encryption.ReadKeys(f)
+	return keys, errors.Wrapf(err, "error reading key file %s", path)
+}
+
+// reportWriter is a usage report writer that must be closed when finished.
+type reportWriter interface {
+	event.Writer
+	Close() error
+}
+
+func (c *exportCmd) newReportWriter(w io.Writer, meta report.Meta) (reportWriter, error) {
+	switch c.Format {
+	case formatCSV:
+		mw, closers, err := c.createMetaFile()
+		if err != nil {
+			return nil, err
+		}
+		rw, err := reportcsv.NewWriter(w, meta, reportcsv.WithMetaWriter(mw))
+		if err != nil {
+			return nil, err
+		}
+		return &closingWriter{reportWriter: rw, closers: closers}, nil
+	case formatParquet:
+		return reportparquet.NewWriter(w, meta)
+	default:
+		cw, err := reporttar.NewCompressor(w, reporttar.Compression(c.Compression))
+		if err != nil {
+			return nil, err
+		}
+		tw := tar.NewWriter(cw)
+		rw, err := reporttar.NewWriter(tw, meta)
+		if err != nil {
+			return nil, err
+		}
+		return &closingWriter{reportWriter: rw, closers: []io.Closer{tw, cw}}, nil
+	}
+}
+
+// metaFile returns the path of the file the metadata of a CSV report is
+// written to.
+func (c *exportCmd) metaFile() string {
+	return c.outAbs + reportcsv.MetaFileSuffix
+}
+
+// createMetaFile creates the file the metadata of a CSV report is written to.
+// The metadata is encrypted like the report. The returned closers must be
+// closed in order once the metadata is written.
+func (c *exportCmd) createMetaFile() (io.Writer, []io.Closer, error) {
+	f, err := os.Create(c.metaFile())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error creating report metadata")
+	}
+	if c.encrypter == nil {
+		return f, []io.Closer{f}, nil
+	}
+	ew, err := c.encrypter.Encrypt(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, errors.Wrap(err, "error creating report metadata")
+	}
+	return ew, []io.Closer{ew, f}, nil
+}
+
+// closingWriter writes a usage report with a report writer and closes the
+// writers it writes to after it, such as the writers of a compressed tar
+// archive.
+type closingWriter struct {
+	reportWriter
+	closers []io.Closer
+}
+
+// Close closes the report writer followed by the writers it writes to.
+func (w *closingWriter) Close() error {
+	if err := w.reportWriter.Close(); err != nil {
 		return err
 	}
-	return gw.Close()
+	for _, c := range w.closers {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getWindowSpec returns the calendar windows that usage is aggregated over.
+func (c *exportCmd) getWindowSpec() (usagetime.WindowSpec, error) {
+	loc, err := time.LoadLocation(c.WindowTimezone)
+	if err != nil {
+		return usagetime.WindowSpec{}, errors.Wrap(err, "invalid --window-timezone")
+	}
+	if c.WindowOffset%time.Hour != 0 {
+		return usagetime.WindowSpec{}, fmt.Errorf("--window-offset must be a whole number of hours")
+	}
+	return usagetime.WindowSpec{
+		Unit:     usagetime.WindowUnit(c.WindowUnit),
+		Offset:   c.WindowOffset,
+		Location: loc,
+	}, nil
+}
+
+// getIter returns an event window iterator for the storage provider.
+func (c *exportCmd) getIter(ctx context.Context) (event.WindowIterator, error) {
+	window := time.Hour
+	switch c.Provider {
+	case providerGCP:
+		return c.getGCPIter(ctx, window)
+	case providerAWS:
+		return c.getAWSIter(window)
+	case providerAzure:
+		return c.getAzureIter(window)
+	case providerPrometheus:
+		return c.getPrometheusIter(window)
+	default:
+		return nil, fmt.Errorf(errFmtProviderNotSupported, c.Provider)
+	}
 }
 
 func (c *exportCmd) getGCPIter(ctx context.Context, window time.Duration) (event.WindowIterator, error) {
@@ -246,27 +797,48 @@ func (c *exportCmd) getGCPIter(ctx context.Context, window time.Duration) (event
 	if c.Endpoint != "" {
 		opts = append(opts, gcpopt.WithEndpoint(c.Endpoint))
 	}
+	if c.metrics != nil {
+		// Supplying an HTTP client skips authentication, so wrap the
+		// metrics transport in one that authenticates.
+		rt, err := gcphttp.NewTransport(ctx, c.metricsTransport(providerGCP), append(opts, gcpopt.WithScopes(storage.ScopeReadOnly))...)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating storage transport")
+		}
+		opts = append(opts, gcpopt.WithHTTPClient(&http.Client{Transport: rt}))
+	}
 	gcsCli, err := storage.NewClient(ctx, opts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating storage client")
 	}
 	bkt := gcsCli.Bucket(c.Bucket)
-	return gcp.NewWindowIterator(bkt, c.Account, c.billingPeriod, window)
+	if c.metrics != nil {
+		bkt = bkt.Retryer(storage.WithPolicy(storage.RetryNever))
+	}
+	return gcp.NewWindowIterator(bkt, c.Account, c.billingPeriod, window, usagetime.WithCalendarWindows(c.windowSpec))
 }
 
 func (c *exportCmd) getAWSIter(window time.Duration) (event.WindowIterator, error) {
-	sess, err := session.NewSession(&aws.Config{})
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating aws session")
+	opts := []usageaws.ClientOption{}
+	if c.AWSProfile != "" {
+		opts = append(opts, usageaws.WithProfile(c.AWSProfile))
+	}
+	if c.AWSRegion != "" {
+		opts = append(opts, usageaws.WithRegion(c.AWSRegion))
 	}
-	config := &aws.Config{}
 	if c.Endpoint != "" {
-		config = &aws.Config{
-			Endpoint: aws.String(c.Endpoint),
-		}
+		opts = append(opts, usageaws.WithEndpoint(c.Endpoint))
+	}
+	if c.AWSRoleARN != "" {
+		opts = append(opts, usageaws.WithRole(c.AWSRoleARN, c.AWSExternalID))
+	}
+	if c.metrics != nil {
+		opts = append(opts, usageaws.WithHTTPClient(&http.Client{Transport: c.metricsTransport(providerAWS)}), usageaws.WithMaxRetries(0))
 	}
-	s3client := s3.New(sess, config)
-	return usageaws.NewWindowIterator(s3client, c.Bucket, c.Account, c.billingPeriod, window)
+	s3client, err := usageaws.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return usageaws.NewWindowIterator(s3client, c.Bucket, c.Account, c.billingPeriod, window, usagetime.WithCalendarWindows(c.windowSpec))
 }
 
 func (c *exportCmd) getAzureIter(window time.Duration) (event.WindowIterator, error) {
@@ -274,46 +846,62 @@ func (c *exportCmd) getAzureIter(window time.Duration) (event.WindowIterator, er
 	if err != nil {
 		return nil, err
 	}
-	cli, err := azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", c.AzureStorageAccount), cred, nil)
+	var opts *azblob.ClientOptions
+	if c.metrics != nil {
+		opts = &azblob.ClientOptions{ClientOptions: azcore.ClientOptions{
+			Transport: &http.Client{Transport: c.metricsTransport(providerAzure)},
+			// A negative number of retries disables them.
+			Retry: policy.RetryOptions{MaxRetries: -1},
+		}}
+	}
+	cli, err := azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", c.AzureStorageAccount), cred, opts)
 	if err != nil {
 		return nil, err
 	}
 	containerCli := cli.ServiceClient().NewContainerClient(c.Bucket)
-	return azure.NewWindowIterator(containerCli, c.Account, c.billingPeriod, window)
+	return azure.NewWindowIterator(containerCli, c.Account, c.billingPeriod, window, usagetime.WithCalendarWindows(c.windowSpec))
+}
+
+// metricsTransport returns a transport that retries failed requests and
+// records their latency, downloaded bytes and retries in the metrics registry,
+// labelled with the supplied storage provider. Clients that use it should not
+// retry requests themselves.
+func (c *exportCmd) metricsTransport(source string) http.RoundTripper {
+	return uphttp.NewTransport(
+		uphttp.WithBaseTransport(c.metrics.Transport(uphttp.DefaultConfig().Transport(nil), source)),
+		uphttp.WithOnRetry(c.metrics.OnRetry(source)),
+	)
+}
+
+func (c *exportCmd) getPrometheusIter(window time.Duration) (event.WindowIterator, error) {
+	rt := promapi.DefaultRoundTripper
+	if c.metrics != nil {
+		rt = c.metricsTransport(providerPrometheus)
+	}
+	cli, err := promapi.NewClient(promapi.Config{Address: c.Endpoint, RoundTripper: rt})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating prometheus client")
+	}
+	return usageprometheus.NewWindowIterator(promv1.NewAPI(cli), c.billingPeriod, window, usageprometheus.WithCalendarWindows(c.windowSpec))
 }
 
 func (c *exportCmd) getBillingPeriod() (usagetime.Range, error) {
-	if !c.BillingMonth.IsZero() {
-		start := time.Date(c.BillingMonth.Year(), c.BillingMonth.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return billingPeriod(c.BillingMonth, c.BillingCustom)
+}
+
+// billingPeriod returns the time range covered by a billing month or a custom
+// billing period.
+func billingPeriod(month time.Time, custom *dateRange) (usagetime.Range, error) {
+	if !month.IsZero() {
+		start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
 		return usagetime.Range{
 			Start: start,
 			End:   start.AddDate(0, 1, 0),
 		}, nil
 	}
 
-	if c.BillingCustom != nil {
-		return usagetime.Range{
-			Start: time.Date(
-				c.BillingCustom.Start.Year(),
-				c.BillingCustom.Start.Month(),
-				c.BillingCustom.Start.Day(),
-				0,
-				0,
-				0,
-				0,
-				time.UTC,
-			),
-			End: time.Date(
-				c.BillingCustom.End.Year(),
-				c.BillingCustom.End.Month(),
-				c.BillingCustom.End.Day(),
-				0,
-				0,
-				0,
-				0,
-				time.UTC,
-			).AddDate(0, 0, 1),
-		}, nil
+	if custom != nil {
+		return usagetime.Range(*custom), nil
 	}
 
 	return usagetime.Range{}, fmt.Errorf("billing period is not set")
@@ -15,13 +15,33 @@
 package billing
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
+	"github.com/upbound/up/internal/http/mocks"
+	"github.com/upbound/up/internal/usage/event/reader"
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+	reporttar "github.com/upbound/up/internal/usage/report/file/tar"
+	"github.com/upbound/up/internal/usage/sender"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
 	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
@@ -59,11 +79,11 @@ func TestGetBillingPeriod(t *testing.T) {
 			},
 		},
 		"BillingCustom": {
-			reason: "A custom billing period should cover the start of the start date to the end of the end date.",
+			reason: "A custom billing period should cover the parsed date range.",
 			args: args{
 				billingCustom: &dateRange{
 					Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
-					End:   time.Date(2006, 5, 7, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 8, 0, 0, 0, 0, time.UTC),
 				},
 			},
 			want: want{
@@ -92,3 +112,380 @@ func TestGetBillingPeriod(t *testing.T) {
 		})
 	}
 }
+
+func TestDateRangeDecode(t *testing.T) {
+	type want struct {
+		r   *dateRange
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		value  string
+		want   want
+	}{
+		"DateRange": {
+			reason: "A pair of dates should cover the start of the start date to the end of the end date.",
+			value:  "2006-05-04/2006-05-07",
+			want: want{r: &dateRange{
+				Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2006, 5, 8, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Month": {
+			reason: "A month should cover the entire month.",
+			value:  "2006-05",
+			want: want{r: &dateRange{
+				Start: time.Date(2006, 5, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2006, 6, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Invalid": {
+			reason: "An unsupported expression should return an error.",
+			value:  "2006-05-04/soon",
+			want:   want{err: errors.New(`"soon" is not a date or RFC3339 timestamp`)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cli := &struct {
+				Custom *dateRange
+			}{}
+			parser, err := kong.New(cli)
+			if err != nil {
+				t.Fatalf("kong.New(...): %s", err)
+			}
+			_, err = parser.Parse([]string{"--custom=" + tc.value})
+			if diff := cmp.Diff(tc.want.err, errors.Cause(err), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDecode(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, cli.Custom); diff != "" {
+				t.Errorf("\n%s\nDecode(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUploadReport(t *testing.T) {
+	report := []byte("report")
+	out := filepath.Join(t.TempDir(), "report.tgz")
+	if err := os.WriteFile(out, report, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	endpoint, _ := url.Parse("https://usage.example.com")
+
+	var received []byte
+	var auth string
+	do := func(req *http.Request) (*http.Response, error) {
+		respond := func(body string) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+		auth = req.Header.Get("Authorization")
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/v1/usage/acct/uploads":
+			return respond(`{"id":"up1"}`)
+		case req.Method == http.MethodGet:
+			return respond(fmt.Sprintf(`{"id":"up1","offset":%d}`, len(received)))
+		case req.Method == http.MethodPut:
+			b, _ := io.ReadAll(req.Body)
+			received = append(received, b...)
+			return respond(fmt.Sprintf(`{"id":"up1","offset":%d}`, len(received)))
+		default:
+			sum, _ := sender.Checksum(bytes.NewReader(received), int64(len(received)))
+			return respond(fmt.Sprintf(`{"id":"up1","sha256":%q}`, sum))
+		}
+	}
+
+	c := &exportCmd{Account: "acct", UploadToken: "token", outAbs: out, uploadURL: endpoint, log: logging.NewNopLogger()}
+	id, err := c.uploadReport(context.Background(), sender.WithClient(&mocks.MockClient{DoFn: do}))
+	if err != nil {
+		t.Fatalf("uploadReport(...): %s", err)
+	}
+	if diff := cmp.Diff("up1", id); diff != "" {
+		t.Errorf("uploadReport(...): -want id, +got id:\n%s", diff)
+	}
+	if diff := cmp.Diff(report, received); diff != "" {
+		t.Errorf("uploadReport(...): -want received, +got received:\n%s", diff)
+	}
+	if diff := cmp.Diff("Bearer token", auth); diff != "" {
+		t.Errorf("uploadReport(...): -want authorization, +got authorization:\n%s", diff)
+	}
+}
+
+func TestCollectCheckpointedReport(t *testing.T) {
+	period := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+	}
+	w1 := usagetime.Range{Start: period.Start, End: period.Start.Add(time.Hour)}
+	w2 := usagetime.Range{Start: w1.End, End: period.End}
+	read := func(v float64) usagetesting.ReadResult {
+		return usagetesting.ReadResult{Event: model.MXPGVKEvent{
+			Name:  "kube_managedresource_uid",
+			Value: v,
+			Tags:  model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+		}}
+	}
+	newIter := func(second ...usagetesting.ReadResult) *usagetesting.MockWindowIterator {
+		return &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+			{Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{read(2)}}, Window: w1},
+			{Reader: &usagetesting.MockReader{Reads: second}, Window: w2},
+		}}
+	}
+	meta := report.Meta{UpboundAccount: "acct", TimeRange: period, Dedup: &reader.DedupStats{}}
+
+	dir := t.TempDir()
+	c := &exportCmd{
+		Account:       "acct",
+		Format:        formatCSV,
+		Checkpoint:    filepath.Join(dir, "checkpoint.json"),
+		outAbs:        filepath.Join(dir, "report.csv"),
+		billingPeriod: period,
+		dedup:         reader.NewDeduplicator(),
+	}
+	if err := c.loadCheckpoint(); err != nil {
+		t.Fatalf("loadCheckpoint(): %s", err)
+	}
+
+	// Interrupt the export while reading the second window.
+	errBoom := errors.New("boom")
+	err := c.collectCheckpointedReport(context.Background(), newIter(read(5), usagetesting.ReadResult{Err: errBoom}), meta)
+	if diff := cmp.Diff(errBoom, err, test.EquateErrors()); diff != "" {
+		t.Errorf("collectCheckpointedReport(...): -want err, +got err:\n%s", diff)
+	}
+
+	// Resume the export, as a new process would.
+	c.resume = report.Checkpoint{}
+	c.dedup = reader.NewDeduplicator()
+	if err := c.loadCheckpoint(); err != nil {
+		t.Fatalf("loadCheckpoint(): %s", err)
+	}
+	if diff := cmp.Diff(report.Checkpoint{TimeRange: period, Window: w1}, c.resume); diff != "" {
+		t.Errorf("loadCheckpoint(): -want checkpoint, +got checkpoint:\n%s", diff)
+	}
+	if err := c.collectCheckpointedReport(context.Background(), newIter(read(5)), meta); err != nil {
+		t.Fatalf("collectCheckpointedReport(...): %s", err)
+	}
+
+	got, err := os.ReadFile(c.outAbs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "schema_version,account,name,mxp_id,group,version,kind,timestamp,timestamp_end,value\n" +
+		"1,acct,max_resource_count_per_gvk_per_mxp,mxp1,example.com,v1,Thing,2006-05-04T03:00:00Z,2006-05-04T04:00:00Z,2\n" +
+		"1,acct,max_resource_count_per_gvk_per_mxp,mxp1,example.com,v1,Thing,2006-05-04T04:00:00Z,2006-05-04T05:00:00Z,5\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("collectCheckpointedReport(...): -want report, +got report:\n%s", diff)
+	}
+
+	b, err := os.ReadFile(c.metaFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotMeta := report.Meta{}
+	if err := json.Unmarshal(b, &gotMeta); err != nil {
+		t.Fatal(err)
+	}
+	// Only the events of the resumed window are read again.
+	wantMeta := report.Meta{UpboundAccount: "acct", TimeRange: period, Dedup: &reader.DedupStats{Read: 1}}
+	if diff := cmp.Diff(wantMeta, gotMeta); diff != "" {
+		t.Errorf("collectCheckpointedReport(...): -want metadata, +got metadata:\n%s", diff)
+	}
+}
+
+func TestGetIterCalendarWindows(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data is not available: %s", err)
+	}
+	c := &exportCmd{
+		Provider:       providerPrometheus,
+		Endpoint:       "http://localhost:9090",
+		WindowUnit:     "day",
+		WindowOffset:   6 * time.Hour,
+		WindowTimezone: "America/New_York",
+		billingPeriod: usagetime.Range{
+			Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2006, 5, 6, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	c.windowSpec, err = c.getWindowSpec()
+	if err != nil {
+		t.Fatalf("getWindowSpec(): %s", err)
+	}
+	iter, err := c.getIter(context.Background())
+	if err != nil {
+		t.Fatalf("getIter(...): %s", err)
+	}
+
+	got := []usagetime.Range{}
+	for iter.More() {
+		_, window, err := iter.Next()
+		if err != nil {
+			t.Fatalf("Next(): %s", err)
+		}
+		got = append(got, window)
+	}
+
+	// Days start at 06:00 New York time, which is 10:00 UTC during daylight
+	// saving time.
+	want := []usagetime.Range{
+		{Start: c.billingPeriod.Start, End: time.Date(2006, 5, 4, 6, 0, 0, 0, ny)},
+		{Start: time.Date(2006, 5, 4, 6, 0, 0, 0, ny), End: time.Date(2006, 5, 5, 6, 0, 0, 0, ny)},
+		{Start: time.Date(2006, 5, 5, 6, 0, 0, 0, ny), End: c.billingPeriod.End},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApproxTime(0)); diff != "" {
+		t.Errorf("getIter(...): -want windows, +got windows:\n%s", diff)
+	}
+}
+
+func TestCollectSplitReport(t *testing.T) {
+	w1 := usagetime.Range{Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC), End: time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC)}
+	w2 := usagetime.Range{Start: w1.End, End: w1.End.Add(time.Hour)}
+	read := func(v float64) usagetesting.ReadResult {
+		return usagetesting.ReadResult{Event: model.MXPGVKEvent{
+			Name:  "kube_managedresource_uid",
+			Value: v,
+			Tags:  model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+		}}
+	}
+	iter := &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+		{Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{read(2)}}, Window: w1},
+		{Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{read(5)}}, Window: w2},
+	}}
+
+	dir := t.TempDir()
+	c := &exportCmd{}
+	parser, err := kong.New(c)
+	if err != nil {
+		t.Fatalf("kong.New(...): %s", err)
+	}
+	if _, err := parser.Parse([]string{
+		"--provider=prometheus",
+		"--endpoint=http://localhost:9090",
+		"--account=acct",
+		"--billing-month=2006-05",
+		"--out=" + filepath.Join(dir, "report.tgz"),
+		"--compression=zstd",
+		"--max-part-size=1",
+	}); err != nil {
+		t.Fatalf("Parse(...): %s", err)
+	}
+	if diff := cmp.Diff(filepath.Join(dir, "report.index.json"), c.outAbs); diff != "" {
+		t.Errorf("Validate(): -want output, +got output:\n%s", diff)
+	}
+
+	meta := report.Meta{UpboundAccount: "acct", TimeRange: c.billingPeriod}
+	if err := c.collectSplitReport(context.Background(), iter, meta); err != nil {
+		t.Fatalf("collectSplitReport(...): %s", err)
+	}
+
+	b, err := os.ReadFile(c.outAbs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reporttar.Index{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	// Each window exceeds the maximum part size, so each is written to its
+	// own archive.
+	want := reporttar.Index{
+		Meta:        meta,
+		Compression: reporttar.CompressionZstd,
+		Parts: []reporttar.IndexPart{
+			{File: "report.part-0001.tar.zst", Windows: []usagetime.Range{w1}, Events: 1},
+			{File: "report.part-0002.tar.zst", Windows: []usagetime.Range{w2}, Events: 1},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApproxTime(0)); diff != "" {
+		t.Errorf("collectSplitReport(...): -want index, +got index:\n%s", diff)
+	}
+	for _, p := range want.Parts {
+		if _, err := os.Stat(filepath.Join(dir, p.File)); err != nil {
+			t.Errorf("collectSplitReport(...): %s", err)
+		}
+	}
+}
+
+func TestValidateArchiveFlags(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		args   []string
+		want   error
+	}{
+		"CompressionWithCSV": {
+			reason: "Compression is only supported for tgz reports.",
+			args:   []string{"--report-format=csv", "--compression=zstd"},
+			want:   errors.New("--compression and --max-part-size are only supported for --report-format=tgz"),
+		},
+		"SplitWithUpload": {
+			reason: "Split reports cannot be uploaded.",
+			args:   []string{"--max-part-size=1000", "--upload-endpoint=https://usage.example.com"},
+			want:   errors.New("--max-part-size is not supported with --upload-endpoint"),
+		},
+		"NegativePartSize": {
+			reason: "The maximum part size must not be negative.",
+			args:   []string{"--max-part-size=-1"},
+			want:   errors.New("--max-part-size must not be negative"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parser, err := kong.New(&exportCmd{})
+			if err != nil {
+				t.Fatalf("kong.New(...): %s", err)
+			}
+			args := append([]string{
+				"--provider=prometheus",
+				"--endpoint=http://localhost:9090",
+				"--account=acct",
+				"--billing-month=2006-05",
+				"--out=" + filepath.Join(t.TempDir(), "report.tgz"),
+			}, tc.args...)
+			_, err = parser.Parse(args)
+			if diff := cmp.Diff(tc.want, errors.Cause(err), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCollectReportDedup(t *testing.T) {
+	w := usagetime.Range{Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC), End: time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC)}
+	read := usagetesting.ReadResult{Event: model.MXPGVKEvent{
+		Name:  "kube_managedresource_uid",
+		Value: 2,
+		Tags:  model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+	}}
+	iter := &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+		{Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{read, read}}, Window: w},
+	}}
+
+	dir := t.TempDir()
+	c := &exportCmd{
+		Compression:   string(reporttar.CompressionGzip),
+		MaxPartSize:   1,
+		Out:           filepath.Join(dir, "report.tgz"),
+		outAbs:        filepath.Join(dir, "report.index.json"),
+		billingPeriod: usagetime.Range{Start: w.Start, End: w.End},
+		dedup:         reader.NewDeduplicator(),
+	}
+	meta := report.Meta{UpboundAccount: "acct", TimeRange: c.billingPeriod, Dedup: &reader.DedupStats{}}
+	if err := c.collectSplitReport(context.Background(), iter, meta); err != nil {
+		t.Fatalf("collectSplitReport(...): %s", err)
+	}
+
+	b, err := os.ReadFile(c.outAbs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reporttar.Index{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := &reader.DedupStats{Read: 2, Dropped: 1}
+	if diff := cmp.Diff(want, got.Meta.Dedup); diff != "" {
+		t.Errorf("collectSplitReport(...): -want dedup stats, +got dedup stats:\n%s", diff)
+	}
+}
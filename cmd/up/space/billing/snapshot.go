@@ -0,0 +1,98 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/internal/fleet"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/usage/live"
+)
+
+type snapshotCmd struct {
+	Kube upbound.KubeFlags `embed:""`
+
+	Group       string `short:"g" help:"Count control planes in this group. Defaults to all groups."`
+	Concurrency int    `default:"5" help:"The number of control planes counted at once."`
+	Out         string `short:"o" help:"Write the snapshot to this file instead of stdout."`
+}
+
+func (c *snapshotCmd) Help() string {
+	return `
+Count the managed resources of every control plane in a Space by connecting to
+each control plane's API server, and write a snapshot report in JSON. Use this
+for Spaces that do not export usage data to object storage. The snapshot only
+reflects usage at the time it is taken; it is not a substitute for a billing
+report exported with "up space billing export".`
+}
+
+// AfterApply loads the kubeconfig of the Space cluster.
+func (c *snapshotCmd) AfterApply() error {
+	return c.Kube.AfterApply()
+}
+
+func (c *snapshotCmd) Run(ctx context.Context, kongCtx *kong.Context, log logging.Logger) error {
+	cfg := c.Kube.GetConfig()
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "error creating kubernetes client")
+	}
+	kube, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "error creating kubernetes client")
+	}
+
+	col := live.New(dyn, fleet.SecretConnector(dyn, kube, nil),
+		live.WithConcurrency(c.Concurrency),
+		live.WithProgress(func(res fleet.Result) {
+			log.Info(res.String())
+		}),
+		live.WithLogger(log),
+	)
+	snap, err := col.Snapshot(ctx, fleet.Selector{Group: c.Group})
+	if err != nil {
+		return errors.Wrap(err, "error collecting usage")
+	}
+
+	var w io.Writer = kongCtx.Stdout
+	if c.Out != "" {
+		f, err := os.Create(c.Out)
+		if err != nil {
+			return errors.Wrap(err, "error creating snapshot file")
+		}
+		defer f.Close() // nolint:errcheck
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snap); err != nil {
+		return errors.Wrap(err, "error writing snapshot")
+	}
+	if len(snap.Failures) > 0 {
+		return fmt.Errorf("%d control planes could not be counted", len(snap.Failures))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/usage"
+	"github.com/upbound/up/internal/usage/encryption"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+type validateCmd struct {
+	Report string `arg:"" type:"existingfile" help:"Path to a billing report exported with --report-format=tgz."`
+
+	BillingMonth  time.Time  `format:"2006-01" required:"" xor:"billingperiod" env:"UP_BILLING_MONTH" group:"Billing period" help:"Validate the report for a billing period of one calendar month. Format: 2006-01."`
+	BillingCustom *dateRange `required:"" xor:"billingperiod" env:"UP_BILLING_CUSTOM" group:"Billing period" help:"Validate the report for a custom billing period. Accepts an inclusive date range such as 2006-01-02/2006-01-02, a month such as 2006-01, or an expression such as \"last month\" or \"last 7d\"."`
+
+	DecryptionKey        string `type:"existingfile" env:"UP_BILLING_DECRYPTION_KEY" group:"Encryption" help:"Path to the OpenPGP private key used to decrypt an encrypted report."`
+	DecryptionPassphrase string `env:"UP_BILLING_DECRYPTION_PASSPHRASE" group:"Encryption" help:"Passphrase of --decryption-key, if it is protected by one."`
+
+	billingPeriod usagetime.Range
+}
+
+func (c *validateCmd) Validate() error {
+	var err error
+	c.billingPeriod, err = billingPeriod(c.BillingMonth, c.BillingCustom)
+	if err != nil {
+		return errors.Wrap(err, "error getting billing period")
+	}
+	return nil
+}
+
+func (c *validateCmd) Run(p pterm.TextPrinter) error {
+	f, err := os.Open(c.Report)
+	if err != nil {
+		return errors.Wrap(err, "error opening report")
+	}
+	defer f.Close() // nolint:errcheck
+	var keys openpgp.EntityList
+	if c.DecryptionKey != "" {
+		if keys, err = readKeys(c.DecryptionKey); err != nil {
+			return err
+		}
+	}
+	r, err := encryption.NewReader(f, keys, []byte(c.DecryptionPassphrase))
+	if err != nil {
+		return errors.Wrap(err, "error opening report")
+	}
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrap(err, "error opening report")
+	}
+	defer gr.Close() // nolint:errcheck
+
+	gaps, err := usage.Validate(tar.NewReader(gr), c.billingPeriod)
+	if err != nil {
+		return err
+	}
+
+	p.Printfln("Windows: %d", gaps.Windows)
+	for _, w := range gaps.MissingWindows {
+		p.Printfln("Missing: %s to %s", formatTimestamp(w.Start), formatTimestamp(w.End))
+	}
+	for _, w := range gaps.EmptyWindows {
+		p.Printfln("Empty: %s to %s", formatTimestamp(w.Start), formatTimestamp(w.End))
+	}
+	for _, e := range gaps.Errors {
+		p.Printfln("Error: %s", e)
+	}
+	if !gaps.Complete() {
+		return fmt.Errorf("billing report is incomplete")
+	}
+	p.Printfln("Billing report is complete.")
+	return nil
+}
@@ -18,15 +18,18 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/pterm/pterm"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/install/uninstall"
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/upterm"
 )
@@ -44,6 +47,9 @@ type destroyCmd struct {
 
 	Confirmed bool `name:"yes-really-delete-space-and-all-data" type:"bool" help:"Bypass safety checks and destroy Spaces"`
 	Orphan    bool `name:"orphan" type:"bool" help:"Remove Space components but retain Control Planes and data"`
+	Force     bool `name:"force" type:"bool" help:"Remove the finalizers of Control Planes that are not deleted in time, orphaning them"`
+
+	Timeout time.Duration `default:"10m" help:"How long to wait for Control Planes to be deleted."`
 }
 
 // AfterApply sets default values in command after assignment and validation.
@@ -84,7 +90,18 @@ func (c *destroyCmd) AfterApply(kongCtx *kong.Context) error {
 	if err != nil {
 		return err
 	}
-	kongCtx.Bind(mgr)
+	dyn, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	opts := []uninstall.Option{
+		uninstall.WithForce(c.Force),
+		uninstall.WithStepTimeout(c.Timeout),
+	}
+	if !c.Orphan {
+		opts = append(opts, uninstall.WithSteps(uninstall.SpacesSteps()...))
+	}
+	kongCtx.Bind(uninstall.New(mgr, dyn, opts...))
 
 	// NOTE(tnthornton) we currently only have support for stylized output.
 	pterm.EnableStyling()
@@ -139,12 +156,16 @@ func (c *destroyCmd) getKubeconfig(upCtx *upbound.Context) (*rest.Config, error)
 	if !upCtx.Profile.IsSpace() {
 		return nil, fmt.Errorf("destroy is not supported for non-space profile %q", upCtx.ProfileName)
 	}
-	return upCtx.Profile.GetKubeClientConfig()
+	return upCtx.GetKubeClientConfig()
 }
 
 // Run executes the uninstall command.
-func (c *destroyCmd) Run(ctx context.Context, kClient *kubernetes.Clientset, mgr *helm.Installer) error {
-	if err := mgr.Uninstall(); err != nil {
+func (c *destroyCmd) Run(ctx context.Context, kClient *kubernetes.Clientset, u *uninstall.Uninstaller) error {
+	r, err := u.Uninstall(ctx)
+	for _, o := range r.Orphaned {
+		pterm.Warning.Printfln("Orphaned %s", o)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -18,7 +18,7 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"strings"
+	"net/http"
 	"time"
 
 	"github.com/alecthomas/kong"
@@ -29,6 +29,7 @@ import (
 	"golang.org/x/exp/maps"
 	"helm.sh/helm/v3/pkg/chart"
 	corev1 "k8s.io/api/core/v1"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -43,6 +44,8 @@ import (
 	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/install"
 	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/install/preflight"
+	"github.com/upbound/up/internal/install/spaces"
 	"github.com/upbound/up/internal/kube"
 	"github.com/upbound/up/internal/profile"
 	"github.com/upbound/up/internal/resources"
@@ -72,6 +75,9 @@ var (
 const (
 	defaultTimeout = 30 * time.Second
 
+	// preflightTimeout bounds each connectivity check.
+	preflightTimeout = 10 * time.Second
+
 	defaultImagePullSecret = "upbound-pull-secret"
 	ns                     = "upbound-system"
 
@@ -97,7 +103,7 @@ type initCmd struct {
 	Yes           bool   `name:"yes" type:"bool" help:"Answer yes to all questions"`
 	PublicIngress bool   `name:"public-ingress" type:"bool" help:"For AKS,EKS,GKE expose ingress publically"`
 
-	helmMgr    install.Manager
+	spaces     *spaces.Installer
 	prereqs    *prerequisites.Manager
 	parser     install.ParameterParser
 	kClient    kubernetes.Interface
@@ -185,7 +191,20 @@ func (c *initCmd) AfterApply(kongCtx *kong.Context, quiet config.QuietFlag) erro
 	if err != nil {
 		return err
 	}
-	c.helmMgr = mgr
+	crdClient, err := apixv1client.NewForConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	var report preflight.ReportFn
+	if !quiet {
+		report = upterm.PrintPreflightReport
+	}
+	c.spaces = spaces.New(mgr, kClient,
+		spaces.WithNamespace(ns),
+		spaces.WithChecks(spaces.DefaultChecks(kClient, crdClient, &http.Client{Timeout: preflightTimeout}, ns, c.Registry.Endpoint, upCtx.APIEndpoint)...),
+		spaces.WithReportFn(report),
+		spaces.WithInstallOptions(initVersionBounds, upVersionBounds),
+	)
 
 	base := map[string]any{}
 	if c.File != nil {
@@ -214,6 +233,11 @@ func (c *initCmd) Run(ctx context.Context, upCtx *upbound.Context) error {
 		return errors.Wrap(err, errParseInstallParameters)
 	}
 	overrideRegistry(c.Registry.Repository.String(), params)
+	for k, v := range spaces.ProfileValues(upCtx.Profile) {
+		if _, ok := params[k]; !ok {
+			params[k] = v
+		}
+	}
 	ensureAccount(params)
 
 	// check if required prerequisites are installed
@@ -239,7 +263,7 @@ func (c *initCmd) Run(ctx context.Context, upCtx *upbound.Context) error {
 				return nil
 			}
 		}
-		if err := c.installPrereqs(); err != nil {
+		if err := c.installPrereqs(ctx); err != nil {
 			return err
 		}
 	}
@@ -295,7 +319,7 @@ func (c *initCmd) createOrUpdateProfile(acct string, upCtx *upbound.Context) err
 	return nil
 }
 
-func (c *initCmd) installPrereqs() error {
+func (c *initCmd) installPrereqs(ctx context.Context) error {
 	status := c.prereqs.Check()
 	for i, p := range status.NotInstalled {
 		if err := upterm.WrapWithSuccessSpinner(
@@ -305,7 +329,7 @@ func (c *initCmd) installPrereqs() error {
 				len(status.NotInstalled),
 			),
 			upterm.CheckmarkSuccessSpinner,
-			p.Install,
+			func() error { return p.Install(ctx) },
 		); err != nil {
 			fmt.Println()
 			fmt.Println()
@@ -379,10 +403,7 @@ func getAcct(params map[string]any) string {
 
 func (c *initCmd) deploySpace(ctx context.Context, params map[string]any) error {
 	install := func() error {
-		if err := c.helmMgr.Install(strings.TrimPrefix(c.Version, "v"), params, initVersionBounds, upVersionBounds); err != nil {
-			return err
-		}
-		return nil
+		return c.spaces.Install(ctx, c.Version, params)
 	}
 
 	if c.quiet {
@@ -89,7 +89,7 @@ func (c *CertManager) GetName() string {
 }
 
 // Install performs a Helm install of the chart.
-func (c *CertManager) Install() error {
+func (c *CertManager) Install(ctx context.Context) error {
 	if c.IsInstalled() {
 		// nothing to do
 		return nil
@@ -98,7 +98,7 @@ func (c *CertManager) Install() error {
 	// create namespace before creating chart.
 	_, err := c.kclient.CoreV1().
 		Namespaces().
-		Create(context.Background(),
+		Create(ctx,
 			&corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: chartName,
@@ -108,7 +108,7 @@ func (c *CertManager) Install() error {
 		return errors.Wrap(err, fmt.Sprintf(errFmtCreateNamespace, chartName))
 	}
 
-	return c.mgr.Install(version, values)
+	return c.mgr.Install(ctx, version, values)
 }
 
 // IsInstalled checks if cert-manager has been installed in the target cluster.
@@ -92,7 +92,7 @@ func (c *IngressNginx) GetName() string {
 }
 
 // Install performs a Helm install of the chart.
-func (c *IngressNginx) Install() error { //nolint:gocyclo
+func (c *IngressNginx) Install(ctx context.Context) error { //nolint:gocyclo
 	if c.IsInstalled() {
 		// nothing to do
 		return nil
@@ -101,7 +101,7 @@ func (c *IngressNginx) Install() error { //nolint:gocyclo
 	// create namespace before creating chart.
 	_, err := c.kclient.CoreV1().
 		Namespaces().
-		Create(context.Background(),
+		Create(ctx,
 			&corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: chartName,
@@ -111,7 +111,7 @@ func (c *IngressNginx) Install() error { //nolint:gocyclo
 		return errors.Wrap(err, fmt.Sprintf(errFmtCreateNamespace, chartName))
 	}
 
-	if err := c.mgr.Install(version, c.values); err != nil {
+	if err := c.mgr.Install(ctx, version, c.values); err != nil {
 		return err
 	}
 
@@ -120,7 +120,7 @@ func (c *IngressNginx) Install() error { //nolint:gocyclo
 			AppsV1().
 			Deployments(chartName).
 			Get(
-				context.Background(),
+				ctx,
 				"ingress-nginx-controller",
 				metav1.GetOptions{},
 			)
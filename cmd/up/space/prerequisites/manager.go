@@ -15,6 +15,8 @@
 package prerequisites
 
 import (
+	"context"
+
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"k8s.io/client-go/rest"
 
@@ -35,7 +37,7 @@ var (
 type Prerequisite interface {
 	GetName() string
 
-	Install() error
+	Install(ctx context.Context) error
 	IsInstalled() bool
 }
 
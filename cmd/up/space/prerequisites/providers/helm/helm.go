@@ -106,7 +106,7 @@ func (h *Helm) GetName() string {
 }
 
 // Install performs a kubectl apply of the package.
-func (h *Helm) Install() error { //nolint:gocyclo
+func (h *Helm) Install(ctx context.Context) error { //nolint:gocyclo
 	if h.IsInstalled() {
 		// nothing to do
 		return nil
@@ -143,7 +143,7 @@ func (h *Helm) Install() error { //nolint:gocyclo
 	_, err := h.dClient.
 		Resource(pkgGVR).
 		Create(
-			context.Background(),
+			ctx,
 			p.GetUnstructured(),
 			metav1.CreateOptions{},
 		)
@@ -151,9 +151,6 @@ func (h *Helm) Install() error { //nolint:gocyclo
 		return err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	for {
 		p, err := h.dClient.Resource(pkgGVR).Get(ctx, pkgName, metav1.GetOptions{})
 		if err != nil && !kerrors.IsNotFound(err) {
@@ -175,6 +172,9 @@ func (h *Helm) Install() error { //nolint:gocyclo
 			); err == nil {
 			break
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
 
 	return h.createProviderConfig()
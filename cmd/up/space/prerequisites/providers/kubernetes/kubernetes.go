@@ -105,7 +105,7 @@ func (k *Kubernetes) GetName() string {
 }
 
 // Install performs a Helm install of the chart.
-func (k *Kubernetes) Install() error { //nolint:gocyclo
+func (k *Kubernetes) Install(ctx context.Context) error { //nolint:gocyclo
 	if k.IsInstalled() {
 		// nothing to do
 		return nil
@@ -142,7 +142,7 @@ func (k *Kubernetes) Install() error { //nolint:gocyclo
 	_, err := k.dClient.
 		Resource(pkgGVR).
 		Create(
-			context.Background(),
+			ctx,
 			p.GetUnstructured(),
 			metav1.CreateOptions{},
 		)
@@ -151,9 +151,6 @@ func (k *Kubernetes) Install() error { //nolint:gocyclo
 		return err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	for {
 		p, err := k.dClient.Resource(pkgGVR).Get(ctx, pkgName, metav1.GetOptions{})
 		if err != nil && !kerrors.IsNotFound(err) {
@@ -175,6 +172,9 @@ func (k *Kubernetes) Install() error { //nolint:gocyclo
 			); err == nil {
 			break
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
 
 	return k.createProviderConfig()
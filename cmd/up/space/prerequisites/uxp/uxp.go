@@ -88,7 +88,7 @@ func (u *UXP) GetName() string {
 }
 
 // Install performs a Helm install of the chart.
-func (u *UXP) Install() error {
+func (u *UXP) Install(ctx context.Context) error {
 	if u.IsInstalled() {
 		// nothing to do
 		return nil
@@ -96,7 +96,7 @@ func (u *UXP) Install() error {
 	// create namespace before creating chart.
 	_, err := u.kclient.CoreV1().
 		Namespaces().
-		Create(context.Background(),
+		Create(ctx,
 			&corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: ns,
@@ -105,7 +105,7 @@ func (u *UXP) Install() error {
 	if err != nil && !kerrors.IsAlreadyExists(err) {
 		return errors.Wrap(err, fmt.Sprintf(errFmtCreateNamespace, ns))
 	}
-	return u.mgr.Install(version, map[string]any{})
+	return u.mgr.Install(ctx, version, map[string]any{})
 }
 
 // IsInstalled checks if UXP has been installed in the target cluster.
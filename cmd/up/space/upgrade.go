@@ -32,6 +32,7 @@ import (
 	"github.com/upbound/up/internal/input"
 	"github.com/upbound/up/internal/install"
 	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/install/spaces"
 	"github.com/upbound/up/internal/kube"
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/upterm"
@@ -57,7 +58,7 @@ type upgradeCmd struct {
 
 	Rollback bool `help:"Rollback to previously installed version on failed upgrade."`
 
-	helmMgr    install.Manager
+	spaces     *spaces.Installer
 	parser     install.ParameterParser
 	prompter   input.Prompter
 	pullSecret *kube.ImagePullApplicator
@@ -115,7 +116,10 @@ func (c *upgradeCmd) AfterApply(quiet config.QuietFlag) error { //nolint:gocyclo
 	if err != nil {
 		return err
 	}
-	c.helmMgr = ins
+	c.spaces = spaces.New(ins, kClient,
+		spaces.WithNamespace(ns),
+		spaces.WithUpgradeOptions(upgradeUpVersionBounds, upgradeFromVersionBounds, upgradeVersionBounds),
+	)
 	base := map[string]any{}
 	if c.File != nil {
 		defer c.File.Close() //nolint:errcheck,gosec
@@ -166,14 +170,11 @@ func (c *upgradeCmd) getKubeconfig(upCtx *upbound.Context) (*rest.Config, error)
 	if !upCtx.Profile.IsSpace() {
 		return nil, fmt.Errorf("upgrade is not supported for non-space profile %q", upCtx.ProfileName)
 	}
-	return upCtx.Profile.GetKubeClientConfig()
+	return upCtx.GetKubeClientConfig()
 }
 
 // Run executes the upgrade command.
 func (c *upgradeCmd) Run(ctx context.Context) error {
-	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
-	defer cancel()
-
 	params, err := c.parser.Parse()
 	if err != nil {
 		return errors.Wrap(err, errParseUpgradeParameters)
@@ -181,11 +182,13 @@ func (c *upgradeCmd) Run(ctx context.Context) error {
 	overrideRegistry(c.Registry.Repository.String(), params)
 
 	// Create or update image pull secret.
-	if err := c.pullSecret.Apply(ctx, defaultImagePullSecret, ns, c.Registry.Username, c.Registry.Password, c.Registry.Endpoint.String()); err != nil {
+	sctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	if err := c.pullSecret.Apply(sctx, defaultImagePullSecret, ns, c.Registry.Username, c.Registry.Password, c.Registry.Endpoint.String()); err != nil {
 		return errors.Wrap(err, errCreateImagePullSecret)
 	}
 
-	if err := c.upgradeUpbound(params); err != nil {
+	if err := c.upgradeUpbound(ctx, params); err != nil {
 		return err
 	}
 
@@ -204,13 +207,13 @@ func upgradeUpVersionBounds(_ string, ch *chart.Chart) error {
 	return upVersionBounds(ch)
 }
 
-func (c *upgradeCmd) upgradeUpbound(params map[string]any) error {
+func (c *upgradeCmd) upgradeUpbound(ctx context.Context, params map[string]any) error {
 	version := strings.TrimPrefix(c.Version, "v")
+	var migrated []spaces.Migration
 	upgrade := func() error {
-		if err := c.helmMgr.Upgrade(version, params, upgradeUpVersionBounds, upgradeFromVersionBounds, upgradeVersionBounds); err != nil {
-			return err
-		}
-		return nil
+		var err error
+		migrated, err = c.spaces.Upgrade(ctx, version, params)
+		return err
 	}
 
 	verb := "Upgrading"
@@ -227,6 +230,9 @@ func (c *upgradeCmd) upgradeUpbound(params map[string]any) error {
 		fmt.Println()
 		return err
 	}
+	for _, m := range migrated {
+		pterm.Info.Printfln("Migrated values for v%s: %s", m.Version, m.Description)
+	}
 
 	return nil
 }
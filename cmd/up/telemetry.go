@@ -0,0 +1,193 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/telemetry"
+	"github.com/upbound/up/internal/version"
+)
+
+const (
+	// defaultDomain is the Upbound domain of commands that don't take a
+	// --domain flag.
+	defaultDomain = "https://upbound.io"
+
+	apiSubdomain = "api."
+)
+
+// telemetryCmd configures anonymized usage telemetry.
+type telemetryCmd struct {
+	Enable  telemetryEnableCmd  `cmd:"" help:"Opt in to anonymized usage telemetry."`
+	Disable telemetryDisableCmd `cmd:"" help:"Opt out of anonymized usage telemetry."`
+	Status  telemetryStatusCmd  `cmd:"" help:"Show whether anonymized usage telemetry is collected."`
+}
+
+func (c *telemetryCmd) Help() string {
+	return `
+up records the commands that are run, the category of any errors and their
+durations, and sends them to the Upbound API of the current profile. Arguments,
+flag values, names and credentials are never recorded. Nothing is recorded for
+Space profiles or without an Upbound profile.
+
+Telemetry is also disabled when UP_TELEMETRY_DISABLED or DO_NOT_TRACK is set to
+a true value. Set UP_TELEMETRY_ENDPOINT to send events to a different URL.
+`
+}
+
+type telemetryEnableCmd struct{}
+
+// Run executes the enable command.
+func (c *telemetryEnableCmd) Run(p pterm.TextPrinter) error {
+	if err := setTelemetryDisabled(false); err != nil {
+		return err
+	}
+	p.Printfln("Telemetry enabled")
+	return nil
+}
+
+type telemetryDisableCmd struct{}
+
+// Run executes the disable command.
+func (c *telemetryDisableCmd) Run(p pterm.TextPrinter) error {
+	if err := setTelemetryDisabled(true); err != nil {
+		return err
+	}
+	p.Printfln("Telemetry disabled")
+	return nil
+}
+
+type telemetryStatusCmd struct{}
+
+// Run executes the status command.
+func (c *telemetryStatusCmd) Run(p pterm.TextPrinter) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	switch {
+	case telemetry.Disabled(os.Getenv):
+		p.Printfln("Telemetry is disabled by the environment")
+	case conf.Telemetry.Disabled:
+		p.Printfln("Telemetry is disabled")
+	default:
+		p.Printfln("Telemetry is enabled")
+	}
+	return nil
+}
+
+func readConfig() (*config.Config, error) {
+	src := config.NewFSSource()
+	if err := src.Initialize(); err != nil {
+		return nil, err
+	}
+	return config.Extract(src)
+}
+
+func setTelemetryDisabled(disabled bool) error {
+	src := config.NewFSSource()
+	if err := src.Initialize(); err != nil {
+		return err
+	}
+	conf, err := config.Extract(src)
+	if err != nil {
+		return err
+	}
+	conf.Telemetry.Disabled = disabled
+	return src.UpdateConfig(conf)
+}
+
+// newTelemetry returns a telemetry client that sends events to the Upbound
+// API targeted by the selected command. It returns nil if the user opted out,
+// or if the command targets a Space profile or no Upbound profile at all, so
+// that air-gapped and Spaces-only installs never record anything.
+func newTelemetry(kongCtx *kong.Context) *telemetry.Client {
+	if telemetry.Disabled(os.Getenv) {
+		return nil
+	}
+	conf, err := readConfig()
+	if err != nil || conf.Telemetry.Disabled {
+		return nil
+	}
+	api := telemetryAPIEndpoint(kongCtx, conf)
+	if api == nil {
+		return nil
+	}
+	endpoint := os.Getenv(telemetry.EnvEndpoint)
+	if endpoint == "" {
+		endpoint = telemetry.Endpoint(api)
+	}
+	return telemetry.New(
+		telemetry.WithVersion(version.GetVersion()),
+		telemetry.WithEndpoint(endpoint),
+	)
+}
+
+// telemetryAPIEndpoint returns the Upbound API endpoint of the profile the
+// selected command uses, resolved like upbound.NewFromFlags does, or nil if
+// it uses a Space profile or none.
+func telemetryAPIEndpoint(kongCtx *kong.Context, conf *config.Config) *url.URL {
+	name := conf.Upbound.Default
+	var domain, override *url.URL
+	for _, f := range kongCtx.Flags() {
+		switch f.Name {
+		case "profile":
+			if s, ok := kongCtx.FlagValue(f).(string); ok && s != "" {
+				name = s
+			}
+		case "domain":
+			if u, ok := kongCtx.FlagValue(f).(*url.URL); ok {
+				domain = u
+			}
+		case "override-api-endpoint":
+			if u, ok := kongCtx.FlagValue(f).(*url.URL); ok {
+				override = u
+			}
+		}
+	}
+	p, err := conf.GetUpboundProfile(name)
+	if err != nil || p.IsSpace() {
+		return nil
+	}
+	if override != nil {
+		return override
+	}
+	if p.APIEndpoint != "" {
+		u, err := url.Parse(p.APIEndpoint)
+		if err != nil {
+			return nil
+		}
+		return u
+	}
+	if domain == nil {
+		base, _ := conf.GetBaseConfig(name)
+		d := base["domain"]
+		if d == "" {
+			d = defaultDomain
+		}
+		if domain, err = url.Parse(d); err != nil {
+			return nil
+		}
+	}
+	u := *domain
+	u.Host = apiSubdomain + u.Host
+	return &u
+}
@@ -16,18 +16,16 @@ package uxp
 
 import (
 	"context"
-	"io"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
-	corev1 "k8s.io/api/core/v1"
-	kerrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"sigs.k8s.io/yaml"
+	"github.com/spf13/afero"
 
 	"github.com/upbound/up/internal/install"
 	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/install/uxp"
+	"github.com/upbound/up/internal/upbound"
 )
 
 const (
@@ -36,7 +34,7 @@ const (
 )
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *installCmd) AfterApply(insCtx *install.Context) error {
+func (c *installCmd) AfterApply(insCtx *install.Context, upCtx *upbound.Context, log logging.Logger) error {
 	repo := RepoURL
 	if c.Unstable {
 		repo = uxpUnstableRepoURL
@@ -46,66 +44,39 @@ func (c *installCmd) AfterApply(insCtx *install.Context) error {
 		repo,
 		helm.WithNamespace(insCtx.Namespace),
 		helm.WithChart(c.Bundle),
-		helm.WithAlternateChart(alternateChartName))
+		helm.WithAlternateChart(alternateChartName),
+		helm.Atomic(c.Atomic))
 	if err != nil {
 		return err
 	}
-	c.mgr = mgr
-	client, err := kubernetes.NewForConfig(insCtx.Kubeconfig)
+	c.installer, err = c.licenseFlags.installer(mgr, insCtx, upCtx, log)
 	if err != nil {
 		return err
 	}
-	c.kClient = client
-	base := map[string]any{}
-	if c.File != nil {
-		defer c.File.Close() //nolint:errcheck,gosec
-		b, err := io.ReadAll(c.File)
-		if err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-		if err := yaml.Unmarshal(b, &base); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-		if err := c.File.Close(); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-	}
-	c.parser = helm.NewParser(base, c.Set)
-	return nil
+	c.files, err = valueFiles(c.File, c.Values)
+	return err
 }
 
 // installCmd installs UXP.
 type installCmd struct {
-	mgr     install.Manager
-	parser  install.ParameterParser
-	kClient kubernetes.Interface
+	installer *uxp.Installer
+	files     []string
 
 	Version  string `arg:"" optional:"" help:"UXP version to install."`
 	Unstable bool   `help:"Allow installing unstable versions."`
+	Atomic   bool   `help:"Uninstall UXP again if the install fails."`
 
+	licenseFlags
 	install.CommonParams
 }
 
 // Run executes the install command.
-func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter, insCtx *install.Context) error {
-	// Create namespace if it does not exist.
-	_, err := c.kClient.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: insCtx.Namespace,
-		},
-	}, metav1.CreateOptions{})
-	if err != nil && !kerrors.IsAlreadyExists(err) {
-		return err
-	}
-	params, err := c.parser.Parse()
+func (c *installCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
+	params, err := uxp.Values(afero.NewOsFs(), c.files, c.Set)
 	if err != nil {
 		return errors.Wrap(err, errParseInstallParameters)
 	}
-	if err = c.mgr.Install(c.Version, params); err != nil {
-		return err
-	}
-
-	curVer, err := c.mgr.GetCurrentVersion()
+	curVer, err := c.installer.Install(ctx, c.Version, params)
 	if err != nil {
 		return err
 	}
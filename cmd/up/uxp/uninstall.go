@@ -15,12 +15,17 @@
 package uxp
 
 import (
+	"context"
 	"net/url"
+	"time"
 
 	"github.com/pterm/pterm"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	"k8s.io/client-go/dynamic"
 
 	"github.com/upbound/up/internal/install"
 	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/install/uninstall"
 )
 
 // AfterApply sets default values in command after assignment and validation.
@@ -34,20 +39,51 @@ func (c *uninstallCmd) AfterApply(insCtx *install.Context) error {
 	if err != nil {
 		return err
 	}
-	c.mgr = mgr
+	dyn, err := dynamic.NewForConfig(insCtx.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	opts := []uninstall.Option{
+		uninstall.WithForce(c.Force),
+		uninstall.WithStepTimeout(c.Timeout),
+	}
+	if c.Cleanup {
+		crds, err := apixv1client.NewForConfig(insCtx.Kubeconfig)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, uninstall.WithSteps(uninstall.UXPSteps(dyn, crds)...))
+	}
+	c.uninstaller = uninstall.New(mgr, dyn, opts...)
 	return nil
 }
 
 // uninstallCmd uninstalls UXP.
 type uninstallCmd struct {
-	mgr install.Manager
+	uninstaller *uninstall.Uninstaller
+
+	Cleanup bool          `help:"Delete claims, composite resources, managed resources and packages in dependency order before uninstalling."`
+	Force   bool          `help:"With --cleanup, remove the finalizers of resources that are not deleted in time, orphaning them."`
+	Timeout time.Duration `default:"5m" help:"With --cleanup, how long to wait for each kind of resource to be deleted."`
 }
 
 // Run executes the uninstall command.
-func (c *uninstallCmd) Run(p pterm.TextPrinter, insCtx *install.Context) error {
-	if err := c.mgr.Uninstall(); err != nil {
+func (c *uninstallCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
+	r, err := c.uninstaller.Uninstall(ctx)
+	printReport(p, r)
+	if err != nil {
 		return err
 	}
 	p.Printfln("UXP uninstalled")
 	return nil
 }
+
+// printReport prints the resources an uninstall deleted and orphaned.
+func printReport(p pterm.TextPrinter, r *uninstall.Report) {
+	for _, o := range r.Deleted {
+		p.Printfln("deleted %s", o)
+	}
+	for _, o := range r.Orphaned {
+		p.Printfln("orphaned %s; its external resources may still exist", o)
+	}
+}
@@ -15,14 +15,17 @@
 package uxp
 
 import (
-	"io"
+	"context"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/pterm/pterm"
-	"sigs.k8s.io/yaml"
+	"github.com/spf13/afero"
 
 	"github.com/upbound/up/internal/install"
 	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/install/uxp"
+	"github.com/upbound/up/internal/upbound"
 )
 
 const (
@@ -30,12 +33,12 @@ const (
 )
 
 // AfterApply sets default values in command after assignment and validation.
-func (c *upgradeCmd) AfterApply(insCtx *install.Context) error {
+func (c *upgradeCmd) AfterApply(insCtx *install.Context, upCtx *upbound.Context, log logging.Logger) error {
 	repo := RepoURL
 	if c.Unstable {
 		repo = uxpUnstableRepoURL
 	}
-	ins, err := helm.NewManager(insCtx.Kubeconfig,
+	mgr, err := helm.NewManager(insCtx.Kubeconfig,
 		chartName,
 		repo,
 		helm.WithNamespace(insCtx.Namespace),
@@ -46,29 +49,18 @@ func (c *upgradeCmd) AfterApply(insCtx *install.Context) error {
 	if err != nil {
 		return err
 	}
-	c.mgr = ins
-	base := map[string]any{}
-	if c.File != nil {
-		defer c.File.Close() //nolint:errcheck,gosec
-		b, err := io.ReadAll(c.File)
-		if err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-		if err := yaml.Unmarshal(b, &base); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
-		if err := c.File.Close(); err != nil {
-			return errors.Wrap(err, errReadParametersFile)
-		}
+	c.installer, err = c.licenseFlags.installer(mgr, insCtx, upCtx, log)
+	if err != nil {
+		return err
 	}
-	c.parser = helm.NewParser(base, c.Set)
-	return nil
+	c.files, err = valueFiles(c.File, c.Values)
+	return err
 }
 
 // upgradeCmd upgrades UXP.
 type upgradeCmd struct {
-	mgr    install.Manager
-	parser install.ParameterParser
+	installer *uxp.Installer
+	files     []string
 
 	Version string `arg:"" optional:"" help:"UXP version to upgrade to."`
 
@@ -76,19 +68,17 @@ type upgradeCmd struct {
 	Force    bool `help:"Force upgrade even if versions are incompatible."`
 	Unstable bool `help:"Allow installing unstable versions."`
 
+	licenseFlags
 	install.CommonParams
 }
 
 // Run executes the upgrade command.
-func (c *upgradeCmd) Run(p pterm.TextPrinter, insCtx *install.Context) error {
-	params, err := c.parser.Parse()
+func (c *upgradeCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
+	params, err := uxp.Values(afero.NewOsFs(), c.files, c.Set)
 	if err != nil {
 		return errors.Wrap(err, errParseUpgradeParameters)
 	}
-	if err := c.mgr.Upgrade(c.Version, params); err != nil {
-		return err
-	}
-	curVer, err := c.mgr.GetCurrentVersion()
+	curVer, err := c.installer.Upgrade(ctx, c.Version, params)
 	if err != nil {
 		return err
 	}
@@ -15,18 +15,38 @@
 package uxp
 
 import (
+	"net/http"
 	"net/url"
+	"os"
+	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"k8s.io/client-go/kubernetes"
 
+	uphttp "github.com/upbound/up/internal/http"
 	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/uxp"
 	"github.com/upbound/up/internal/kube"
+	"github.com/upbound/up/internal/license"
+	"github.com/upbound/up/internal/tokenstore"
 	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/version"
 )
 
 const (
 	chartName          = "universal-crossplane"
 	alternateChartName = "crossplane"
+
+	licenseOrgID     = "upbound"
+	licenseProductID = "uxp"
+
+	// preflightTimeout bounds each connectivity check.
+	preflightTimeout = 10 * time.Second
+	// licenseTimeout bounds each license access key request.
+	licenseTimeout = 30 * time.Second
 )
 
 var (
@@ -48,6 +68,7 @@ func (c *Cmd) AfterApply(kongCtx *kong.Context) error {
 	if upCtx.WrapTransport != nil {
 		kubeconfig.Wrap(upCtx.WrapTransport)
 	}
+	kongCtx.Bind(upCtx)
 	kongCtx.Bind(&install.Context{
 		Kubeconfig: kubeconfig,
 		Namespace:  c.Namespace,
@@ -55,6 +76,60 @@ func (c *Cmd) AfterApply(kongCtx *kong.Context) error {
 	return nil
 }
 
+// licenseFlags configure acquiring a license access key for UXP.
+type licenseFlags struct {
+	License    bool     `help:"Acquire a license access key for the UXP version with the current profile and write it to an image pull secret used by UXP."`
+	PullSecret string   `default:"uxp-pull-secret" help:"Name of the image pull secret the license access key is written to."`
+	RobotToken string   `env:"UP_LICENSE_ROBOT_TOKEN" help:"Acquire the license access key by exchanging this organization robot token instead of using the current profile's credentials."`
+	Values     []string `type:"existingfile" help:"Values files, merged in order. Later files override earlier ones and --set overrides all files."`
+}
+
+// installer returns a UXP installer that uses the supplied manager.
+func (f licenseFlags) installer(mgr install.Manager, insCtx *install.Context, upCtx *upbound.Context, log logging.Logger) (*uxp.Installer, error) {
+	client, err := kubernetes.NewForConfig(insCtx.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	hc := &http.Client{Timeout: preflightTimeout}
+	opts := []uxp.Option{
+		uxp.WithNamespace(insCtx.Namespace),
+		uxp.WithChecks(uxp.DefaultChecks(client, hc, insCtx.Namespace, upCtx.RegistryEndpoint, upCtx.APIEndpoint)...),
+		uxp.WithPullSecret(f.PullSecret, upCtx.RegistryEndpoint.Hostname()),
+		uxp.WithReportFn(upterm.PrintPreflightReport),
+	}
+	if f.License {
+		endpoint := *upCtx.APIEndpoint
+		lopts := []license.ProviderModifierFn{
+			license.WithEndpoint(&endpoint),
+			license.WithOrgID(licenseOrgID),
+			license.WithProductID(licenseProductID),
+			license.WithUserAgent(license.UserAgent(version.GetVersion(), licenseProductID)),
+			license.WithTimeout(licenseTimeout),
+			license.WithTransportOptions(upCtx.TransportOptions(uphttp.BearerToken)...),
+			license.WithTokenStore(upCtx.Tokens, tokenstore.SessionKey(upCtx.ProfileName)),
+			license.WithLogger(log),
+			license.WithTelemetry(upCtx.Telemetry),
+		}
+		if f.RobotToken != "" {
+			lopts = append(lopts, license.WithRobotToken(f.RobotToken))
+		}
+		opts = append(opts, uxp.WithLicense(license.NewProvider(lopts...), upCtx.Profile.Session))
+	}
+	return uxp.New(mgr, client, opts...), nil
+}
+
+// valueFiles returns the supplied parameters file followed by the supplied
+// values files.
+func valueFiles(params *os.File, values []string) ([]string, error) {
+	if params == nil {
+		return values, nil
+	}
+	if err := params.Close(); err != nil {
+		return nil, errors.Wrap(err, errReadParametersFile)
+	}
+	return append([]string{params.Name()}, values...), nil
+}
+
 // Cmd contains commands for managing UXP.
 type Cmd struct {
 	Install   installCmd   `cmd:"" help:"Install UXP."`
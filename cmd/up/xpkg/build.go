@@ -18,10 +18,13 @@ import (
 	"context"
 	"io"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/parser"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
@@ -30,6 +33,7 @@ import (
 	"github.com/upbound/up/internal/xpkg"
 	"github.com/upbound/up/internal/xpkg/parser/examples"
 	"github.com/upbound/up/internal/xpkg/parser/yaml"
+	"github.com/upbound/up/internal/xpkg/sbom"
 )
 
 const (
@@ -37,6 +41,11 @@ const (
 	errBuildPackage    = "failed to build package"
 	errImageDigest     = "failed to get package digest"
 	errCreatePackage   = "failed to create package file"
+	errGenerateSBOM    = "failed to generate SBOM"
+	errWriteSBOM       = "failed to write SBOM file"
+
+	errPlatformNoController  = "--platform requires --controller"
+	errFmtMissingControllers = "controller image %s is not available for platforms: %s"
 )
 
 // AfterApply constructs and binds Upbound-specific context to any subcommands
@@ -95,6 +104,7 @@ func (c *buildCmd) AfterApply() error {
 	// NOTE(hasheddan): we currently only support fetching controller image from
 	// daemon, but may opt to support additional sources in the future.
 	c.fetch = daemonFetch
+	c.fetchPlatform = registryPlatformFetch
 
 	return nil
 }
@@ -106,6 +116,8 @@ type buildCmd struct {
 	root    string
 	fetch   fetchFn
 
+	fetchPlatform platformFetchFn
+
 	Name         string   `optional:"" xor:"xpkg-build-out" help:"[DEPRECATED: use --output] Name of the package to be built. Uses name in crossplane.yaml if not specified. Does not correspond to package tag."`
 	Output       string   `optional:"" short:"o" xor:"xpkg-build-out" help:"Path for package output."`
 	Controller   string   `help:"Controller image used as base for package."`
@@ -113,6 +125,11 @@ type buildCmd struct {
 	ExamplesRoot string   `short:"e" help:"Path to package examples directory." default:"./examples"`
 	AuthExt      string   `short:"a" help:"Path to an authentication extension file." default:"auth.yaml"`
 	Ignore       []string `help:"Paths, specified relative to --package-root, to exclude from the package."`
+	Platform     []string `help:"Platforms to build the package for, using the <OS>_<arch> syntax, e.g. linux_arm64. One package is built per platform from the matching image of --controller, which is fetched from its registry. Push the packages together to publish a multi-arch package."`
+
+	SourceDateEpoch *int64 `env:"SOURCE_DATE_EPOCH" help:"Unix timestamp recorded as the package creation time. Builds of the same sources with the same timestamp produce the same digest."`
+
+	SBOM string `enum:",spdx,cyclonedx" default:"" help:"Generate an SBOM in the given format next to the package. It is attached to the package on push. One of: spdx, cyclonedx."`
 }
 
 func (c *buildCmd) Help() string {
@@ -135,20 +152,76 @@ Even more details can be found in the xpkg reference document.`
 }
 
 // Run executes the build command.
-func (c *buildCmd) Run(ctx context.Context, p pterm.TextPrinter) error { //nolint:gocyclo
+func (c *buildCmd) Run(ctx context.Context, p pterm.TextPrinter) error {
 	var buildOpts []xpkg.BuildOpt
-	if c.Controller != "" {
-		ref, err := name.ParseReference(c.Controller)
-		if err != nil {
+	if c.SourceDateEpoch != nil {
+		buildOpts = append(buildOpts, xpkg.WithTimestamp(time.Unix(*c.SourceDateEpoch, 0)))
+	}
+	if len(c.Platform) == 0 {
+		if c.Controller != "" {
+			ref, err := name.ParseReference(c.Controller)
+			if err != nil {
+				return err
+			}
+			base, err := c.fetch(ctx, ref)
+			if err != nil {
+				return err
+			}
+			buildOpts = append(buildOpts, xpkg.WithController(base))
+		}
+		return c.build(ctx, p, "", buildOpts...)
+	}
+
+	controllers, err := c.fetchControllers(ctx)
+	if err != nil {
+		return err
+	}
+	for i, pl := range c.Platform {
+		if err := c.build(ctx, p, pl, append(buildOpts, xpkg.WithController(controllers[i]))...); err != nil {
 			return err
 		}
-		base, err := c.fetch(ctx, ref)
+	}
+	return nil
+}
+
+// fetchControllers fetches the controller image for every platform to build
+// for from the registry. It fails if the controller image is not available
+// for any of the platforms.
+func (c *buildCmd) fetchControllers(ctx context.Context) ([]v1.Image, error) {
+	if c.Controller == "" {
+		return nil, errors.New(errPlatformNoController)
+	}
+	ref, err := name.ParseReference(c.Controller)
+	if err != nil {
+		return nil, err
+	}
+	imgs := make([]v1.Image, len(c.Platform))
+	var missing []string
+	for i, s := range c.Platform {
+		pl, err := xpkg.ParsePlatform(s)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		buildOpts = append(buildOpts, xpkg.WithController(base))
+		img, err := c.fetchPlatform(ctx, ref, pl)
+		if err == nil {
+			err = xpkg.CheckPlatform(img, pl)
+		}
+		if err != nil {
+			missing = append(missing, s)
+			continue
+		}
+		imgs[i] = img
 	}
-	img, meta, err := c.builder.Build(ctx, buildOpts...)
+	if len(missing) > 0 {
+		return nil, errors.Errorf(errFmtMissingControllers, c.Controller, strings.Join(missing, ", "))
+	}
+	return imgs, nil
+}
+
+// build builds the package and writes it to the output path. Packages built
+// for a platform have the platform appended to their file name.
+func (c *buildCmd) build(ctx context.Context, p pterm.TextPrinter, platform string, opts ...xpkg.BuildOpt) error {
+	img, meta, err := c.builder.Build(ctx, opts...)
 	if err != nil {
 		return errors.Wrap(err, errBuildPackage)
 	}
@@ -170,6 +243,14 @@ func (c *buildCmd) Run(ctx context.Context, p pterm.TextPrinter) error { //nolin
 		}
 		output = xpkg.BuildPath(c.root, pkgName)
 	}
+	if platform != "" {
+		pl, err := xpkg.ParsePlatform(platform)
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(output)
+		output = strings.TrimSuffix(output, ext) + "-" + xpkg.PlatformSuffix(pl) + ext
+	}
 
 	f, err := c.fs.Create(output)
 	if err != nil {
@@ -181,6 +262,20 @@ func (c *buildCmd) Run(ctx context.Context, p pterm.TextPrinter) error { //nolin
 		return err
 	}
 	p.Printfln("xpkg saved to %s", output)
+	p.Printfln("digest: %s", hash)
+
+	if c.SBOM == "" {
+		return nil
+	}
+	doc, err := sbom.Generate(ctx, img, sbom.Format(c.SBOM))
+	if err != nil {
+		return errors.Wrap(err, errGenerateSBOM)
+	}
+	sp := sbom.SidecarPath(output, sbom.Format(c.SBOM))
+	if err := afero.WriteFile(c.fs, sp, doc, 0o644); err != nil {
+		return errors.Wrap(err, errWriteSBOM)
+	}
+	p.Printfln("sbom saved to %s", sp)
 	return nil
 }
 
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -28,13 +29,16 @@ import (
 	"github.com/upbound/up/internal/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep"
 	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
 	"github.com/upbound/up/internal/xpkg/dep/manager"
 	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+	"github.com/upbound/up/internal/xpkg/pkgcache"
 	"github.com/upbound/up/internal/xpkg/workspace"
 )
 
 const (
 	errMetaFileNotFound = "crossplane.yaml file not found in current directory"
+	errVendorCache      = "failed to create vendor directory"
 )
 
 // AfterApply constructs and binds Upbound-specific context to any subcommands
@@ -43,6 +47,7 @@ func (c *depCmd) AfterApply(kongCtx *kong.Context, p pterm.TextPrinter) error {
 	kongCtx.Bind(pterm.DefaultBulletList.WithWriter(kongCtx.Stdout))
 	ctx := context.Background()
 	fs := afero.NewOsFs()
+	c.fs = fs
 
 	cache, err := cache.NewLocal(c.CacheDir)
 	if err != nil {
@@ -54,7 +59,10 @@ func (c *depCmd) AfterApply(kongCtx *kong.Context, p pterm.TextPrinter) error {
 	// only parse the workspace if we aren't attempting to clean the cache
 	if !c.CleanCache {
 
-		r := image.NewResolver()
+		r, err := newResolver()
+		if err != nil {
+			return err
+		}
 
 		m, err := manager.New(
 			manager.WithCache(cache),
@@ -71,6 +79,7 @@ func (c *depCmd) AfterApply(kongCtx *kong.Context, p pterm.TextPrinter) error {
 		if err != nil {
 			return err
 		}
+		c.wd = wd
 
 		ws, err := workspace.New(wd, workspace.WithFS(fs), workspace.WithPrinter(p))
 		if err != nil {
@@ -93,12 +102,16 @@ type depCmd struct {
 	c  *cache.Local
 	m  *manager.Manager
 	ws *workspace.Workspace
+	fs afero.Fs
+	wd string
 
 	// TODO(@tnthornton) remove cacheDir flag. Having a user supplied flag
 	// can result in broken behavior between xpls and dep. CacheDir should
 	// only be supplied by the Config.
 	CacheDir   string `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
 	CleanCache bool   `short:"c" help:"Clean dep cache."`
+	Lock       bool   `help:"Write the versions and digests the dependencies resolved to to up.lock."`
+	Vendor     string `help:"Store the dependencies pinned in up.lock in this directory for offline builds." type:"path"`
 
 	Package string `arg:"" optional:"" help:"Package to be added."`
 }
@@ -112,6 +125,11 @@ server.
 
 If a package (e.g. provider-foo@v0.42.0 or provider-foo for latest) is specified,
 it will be added to the crossplane.yaml file in the current directory as dependency. 
+
+With --lock, the version and digest every dependency (including transitive
+dependencies) resolved to are written to up.lock in the current directory.
+With --vendor, the packages pinned in up.lock are stored in the supplied
+directory, which can then be used as the cache directory for offline builds.
 `
 }
 
@@ -129,6 +147,14 @@ func (c *depCmd) Run(ctx context.Context, p pterm.TextPrinter, pb *pterm.BulletL
 		return nil
 	}
 
+	if c.Vendor != "" {
+		if err := c.vendor(ctx); err != nil {
+			return err
+		}
+		p.Printfln("Dependencies in %s vendored to %s", lock.DefaultFile, c.Vendor)
+		return nil
+	}
+
 	if c.Package != "" {
 		if err := c.userSuppliedDep(ctx); err != nil {
 			return err
@@ -145,6 +171,12 @@ func (c *depCmd) Run(ctx context.Context, p pterm.TextPrinter, pb *pterm.BulletL
 		p.Printfln("No dependencies specified")
 		return nil
 	}
+	if c.Lock {
+		if err := c.writeLock(ctx, deps); err != nil {
+			return err
+		}
+		p.Printfln("Dependencies locked in %s", lock.DefaultFile)
+	}
 	p.Printfln("Dependencies added to xpkg cache:")
 	li := make([]pterm.BulletListItem, len(deps))
 	for i, d := range deps {
@@ -212,3 +244,44 @@ func (c *depCmd) metaSuppliedDeps(ctx context.Context) ([]v1beta1.Dependency, er
 
 	return resolvedDeps, nil
 }
+
+func (c *depCmd) writeLock(ctx context.Context, deps []v1beta1.Dependency) error {
+	l, err := lock.NewResolver(c.m).Lock(ctx, deps)
+	if err != nil {
+		return err
+	}
+	return lock.Write(c.fs, filepath.Join(c.wd, lock.DefaultFile), l)
+}
+
+func (c *depCmd) vendor(ctx context.Context) error {
+	l, err := lock.Read(c.fs, filepath.Join(c.wd, lock.DefaultFile))
+	if err != nil {
+		return err
+	}
+	r, err := newResolver()
+	if err != nil {
+		return err
+	}
+	vc, err := cache.NewLocal(c.Vendor, cache.WithFS(c.fs))
+	if err != nil {
+		return errors.Wrap(err, errVendorCache)
+	}
+	m, err := manager.New(
+		manager.WithCache(vc),
+		manager.WithResolver(r),
+	)
+	if err != nil {
+		return err
+	}
+	return lock.NewResolver(m).Vendor(ctx, l)
+}
+
+// newResolver returns an image resolver that caches the package images it
+// fetches in the default package cache.
+func newResolver() (*image.Resolver, error) {
+	pc, err := pkgcache.Default()
+	if err != nil {
+		return nil, err
+	}
+	return image.NewResolver(image.WithFetcher(image.NewCachedFetcher(image.NewLocalFetcher(), pc))), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/printer"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/xpkg/inspect"
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *inspectCmd) AfterApply(kongCtx *kong.Context) error {
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+
+	i, err := inspect.New(inspect.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return err
+	}
+	c.i = i
+	return nil
+}
+
+// inspectCmd shows the contents of a package.
+type inspectCmd struct {
+	i *inspect.Inspector
+
+	Package string `arg:"" help:"Package to inspect. Must be a valid OCI image reference."`
+	Show    string `arg:"" optional:"" enum:"summary,xrds,compositions,crds,provider-configs,dependencies" default:"summary" help:"Contents to show. One of: summary, xrds, compositions, crds, provider-configs, dependencies."`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *inspectCmd) Help() string {
+	return `
+The inspect command pulls a package and shows its contents without installing
+it into a cluster, e.g. all XRDs a Configuration ships:
+
+  up xpkg inspect xpkg.upbound.io/upbound/platform-ref-aws:v0.6.0 xrds
+`
+}
+
+// Run executes the inspect command.
+func (c *inspectCmd) Run(ctx context.Context, pr *printer.Printer, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	pkg, err := c.i.Inspect(ctx, c.Package)
+	if err != nil {
+		return err
+	}
+	pr.DefaultFormat(config.Format(upCtx.Profile.Format))
+
+	switch c.Show {
+	case "xrds":
+		return pr.Print(pkg.XRDs, printer.XRDColumns)
+	case "compositions":
+		return pr.Print(pkg.Compositions, printer.CompositionColumns)
+	case "crds":
+		return pr.Print(pkg.CRDs, printer.CRDColumns)
+	case "provider-configs":
+		return pr.Print(pkg.ProviderConfigs(), printer.CRDColumns)
+	case "dependencies":
+		return pr.Print(pkg.Dependencies, printer.DependencyColumns)
+	}
+
+	if pr.Structured() {
+		return pr.Print(pkg, nil)
+	}
+	p.Printfln("Name:          %s", pkg.Name())
+	p.Printfln("Type:          %s", pkg.Type)
+	p.Printfln("Reference:     %s", pkg.Ref)
+	p.Printfln("Digest:        %s", pkg.Digest)
+	p.Printfln("Dependencies:  %d", len(pkg.Dependencies))
+	p.Printfln("XRDs:          %d", len(pkg.XRDs))
+	p.Printfln("Compositions:  %d", len(pkg.Compositions))
+	p.Printfln("CRDs:          %d (%d provider configs)", len(pkg.CRDs), len(pkg.ProviderConfigs()))
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/printer"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/lint"
+	"github.com/upbound/up/internal/xpkg/parser/examples"
+	"github.com/upbound/up/internal/xpkg/parser/yaml"
+)
+
+const (
+	errParsePackage  = "failed to parse package"
+	errParseExamples = "failed to parse examples"
+	errFmtLintFailed = "package has %d lint findings with severity %s or higher"
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *lintCmd) AfterApply(kongCtx *kong.Context) error {
+	c.fs = afero.NewOsFs()
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	return nil
+}
+
+// lintCmd lints a package.
+type lintCmd struct {
+	fs afero.Fs
+
+	PackageRoot  string            `short:"f" help:"Path to package directory." default:"."`
+	ExamplesRoot string            `short:"e" help:"Path to package examples directory." default:"./examples"`
+	AuthExt      string            `help:"Path to an authentication extension file." default:"auth.yaml"`
+	Ignore       []string          `help:"Paths, specified relative to --package-root, to exclude from the package."`
+	Disable      []string          `help:"Rules to disable."`
+	Severity     map[string]string `help:"Override the severity of rules, e.g. --severity missing-examples=error."`
+	FailOn       string            `enum:"info,warning,error,none" default:"error" help:"Fail if there are findings with this severity or higher. One of: info, warning, error, none."`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *lintCmd) Help() string {
+	return `
+The lint command checks the package in the local file system for common issues
+without building it. Each finding has a severity of info, warning or error.
+The following rules are run:
+
+  missing-description     The package and the spec fields of its XRDs should
+                          be described.
+  deprecated-api-version  Objects and examples should not use deprecated API
+                          versions.
+  patch-field-path        Composition patches should only reference fields of
+                          the composite resource that exist.
+  missing-examples        Every XRD should have an example of its composite
+                          resource or claim.
+
+Use --format=json or --format=yaml for machine-readable output.`
+}
+
+// Run executes the lint command.
+func (c *lintCmd) Run(ctx context.Context, pr *printer.Printer, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	opts := []lint.Option{lint.WithDisabledRules(c.Disable...)}
+	for rule, s := range c.Severity {
+		sev, err := lint.ParseSeverity(s)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, lint.WithSeverity(rule, sev))
+	}
+
+	pkg, err := c.parse(ctx)
+	if err != nil {
+		return err
+	}
+	report := lint.New(opts...).Lint(pkg)
+
+	pr.DefaultFormat(config.Format(upCtx.Profile.Format))
+	switch {
+	case pr.Structured():
+		if err := pr.Print(report, nil); err != nil {
+			return err
+		}
+	case len(report.Findings) == 0:
+		p.Println("No lint findings.")
+	default:
+		if err := pr.Print(report.Findings, printer.LintFindingColumns); err != nil {
+			return err
+		}
+	}
+
+	if c.FailOn == "none" {
+		return nil
+	}
+	if n := report.Count(lint.Severity(c.FailOn)); n > 0 {
+		return errors.Errorf(errFmtLintFailed, n, c.FailOn)
+	}
+	return nil
+}
+
+// parse parses the package and its examples from the file system.
+func (c *lintCmd) parse(ctx context.Context) (*lint.Package, error) {
+	root, err := filepath.Abs(c.PackageRoot)
+	if err != nil {
+		return nil, err
+	}
+	ex, err := filepath.Abs(c.ExamplesRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgReader, err := parser.NewFsBackend(
+		c.fs,
+		parser.FsDir(root),
+		parser.FsFilters(
+			append(
+				buildFilters(root, c.Ignore),
+				xpkg.SkipContains(c.ExamplesRoot), xpkg.SkipContains(c.AuthExt))...),
+	).Init(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePackage)
+	}
+	pp, err := yaml.New()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := pp.Parse(ctx, pkgReader)
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePackage)
+	}
+	if err := xpkg.OneMeta(parsed); err != nil {
+		return nil, err
+	}
+	pkg := &lint.Package{Meta: parsed.GetMeta()[0], Objects: parsed.GetObjects()}
+
+	exReader, err := parser.NewFsBackend(
+		c.fs,
+		parser.FsDir(ex),
+		parser.FsFilters(buildFilters(ex, c.Ignore)...),
+	).Init(ctx)
+	if os.IsNotExist(err) {
+		return pkg, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errParseExamples)
+	}
+	exs, err := examples.New().Parse(ctx, exReader)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseExamples)
+	}
+	pkg.Examples = exs.GetObjects()
+	return pkg, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/mirror"
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *mirrorCmd) AfterApply(kongCtx *kong.Context) error {
+	c.fs = afero.NewOsFs()
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	return nil
+}
+
+// mirrorCmd copies a package to another registry.
+type mirrorCmd struct {
+	fs afero.Fs
+
+	Package  string `arg:"" help:"Package to mirror. Must be a valid OCI image reference."`
+	Registry string `arg:"" help:"Registry to mirror to, optionally with a path prefix, e.g. registry.example.org/mirror."`
+	LockFile string `help:"Also mirror the dependencies pinned in this lock file." type:"path"`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *mirrorCmd) Help() string {
+	return `
+The mirror command copies a package to another registry, keeping its
+repository path, tag and digest. Signatures and artifacts attached to the
+package, such as SBOMs, are copied too, so they remain valid. With --lock-file,
+every dependency pinned in the lock file is copied by its locked digest as well,
+e.g. to promote a package from a development registry to an air-gapped
+production registry:
+
+  up xpkg mirror registry.dev.example.org/acme/platform:v1.0.0 registry.prod.example.org --lock-file up.lock
+`
+}
+
+// Run executes the mirror command.
+func (c *mirrorCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	nopts := []name.Option{name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname())}
+	m := mirror.New(
+		mirror.WithRemoteOptions(remote.WithAuthFromKeychain(registryKeychain(upCtx, c.Flags.Profile))),
+		mirror.WithNameOptions(nopts...),
+	)
+
+	src, err := name.ParseReference(c.Package, nopts...)
+	if err != nil {
+		return err
+	}
+	repo, err := mirror.Rebase(src.Context(), c.Registry, nopts...)
+	if err != nil {
+		return err
+	}
+	var dst name.Reference = repo.Digest(src.Identifier())
+	if t, ok := src.(name.Tag); ok {
+		dst = repo.Tag(t.TagStr())
+	}
+	d, err := m.Copy(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+	p.Printfln("xpkg mirrored to %s", dst)
+	p.Printfln("digest: %s", d)
+
+	if c.LockFile == "" {
+		return nil
+	}
+	l, err := lock.Read(c.fs, c.LockFile)
+	if err != nil {
+		return err
+	}
+	copies, err := m.CopyLocked(ctx, l, c.Registry)
+	if err != nil {
+		return err
+	}
+	for _, cp := range copies {
+		p.Printfln("dependency mirrored to %s (%s)", cp.Destination, cp.Digest)
+	}
+	return nil
+}
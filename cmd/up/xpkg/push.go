@@ -16,7 +16,6 @@ package xpkg
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,18 +25,16 @@ import (
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
-	"github.com/google/go-containerregistry/pkg/v1/empty"
-	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/upbound/up-sdk-go/service/repositories"
-	"github.com/upbound/up/internal/credhelper"
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/sbom"
+	"github.com/upbound/up/internal/xpkg/sign"
 )
 
 const (
@@ -46,7 +43,8 @@ const (
 	errCreateRepo        = "failed to create repository"
 	errGetwd             = "failed to get working directory while searching for package"
 	errFindPackageinWd   = "failed to find a package in current working directory"
-	errBuildImage        = "failed to build image from layers"
+	errNoTags            = "at least one tag must be supplied"
+	errReadSBOM          = "failed to read SBOM file"
 )
 
 // AfterApply constructs and binds Upbound-specific context to any subcommands
@@ -66,15 +64,18 @@ type pushCmd struct {
 	fs afero.Fs
 
 	Tag     string   `arg:"" help:"Tag of the package to be pushed. Must be a valid OCI image tag."`
-	Package []string `short:"f" help:"Path to packages. If not specified and only one package exists in current directory it will be used."`
+	Tags    []string `short:"t" help:"Additional tags to push the package to."`
+	Package []string `short:"f" help:"Path to packages. Multiple packages, e.g. built with --platform, are pushed as a multi-arch index and must each be built for a distinct platform. If not specified and only one package exists in current directory it will be used."`
 	Create  bool     `help:"Create repository on push if it does not exist."`
 
+	Sign sign.SignFlags `embed:""`
+
 	// Common Upbound API configuration
 	Flags upbound.Flags `embed:""`
 }
 
 // Run runs the push cmd.
-func (c *pushCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { //nolint:gocyclo
+func (c *pushCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error { //nolint:gocyclo
 	// If package is not defined, attempt to find single package in current
 	// directory.
 	if len(c.Package) == 0 {
@@ -97,24 +98,83 @@ func (c *pushCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { //nol
 		}
 		imgs = append(imgs, img)
 	}
-	return PushImages(p, upCtx, imgs, c.Tag, c.Create, c.Flags.Profile)
-}
-
-func PushImages(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, t string, create bool, profile string) error { //nolint:gocyclo
-	tag, err := name.NewTag(t, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	var signer sign.Signer
+	if c.Sign.Enabled() {
+		s, err := c.Sign.Signer()
+		if err != nil {
+			return err
+		}
+		signer = s
+	}
+	if err := PushImages(ctx, p, upCtx, imgs, append([]string{c.Tag}, c.Tags...), c.Create, c.Flags.Profile); err != nil {
+		return err
+	}
+	ref, err := name.NewTag(c.Tag, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
 	if err != nil {
 		return err
 	}
+	auth := remote.WithAuthFromKeychain(registryKeychain(upCtx, c.Flags.Profile))
+	// NOTE: SBOMs are only attached when pushing a single package, as
+	// multi-arch pushes would require attaching one SBOM per platform image.
+	if len(c.Package) == 1 {
+		if err := c.attachSBOMs(ctx, p, ref, c.Package[0], auth); err != nil {
+			return err
+		}
+	}
+	if signer == nil {
+		return nil
+	}
+	d, err := sign.Sign(ctx, ref, signer, auth)
+	if err != nil {
+		return err
+	}
+	p.Printfln("xpkg %s signed", d)
+	return nil
+}
 
-	kc := authn.NewMultiKeychain(
+// attachSBOMs attaches any SBOMs generated next to the package at the supplied
+// path by the build command to the pushed package.
+func (c *pushCmd) attachSBOMs(ctx context.Context, p pterm.TextPrinter, ref name.Reference, pkg string, opts ...remote.Option) error {
+	for _, f := range []sbom.Format{sbom.SPDX, sbom.CycloneDX} {
+		doc, err := afero.ReadFile(c.fs, sbom.SidecarPath(filepath.Clean(pkg), f))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, errReadSBOM)
+		}
+		d, err := sbom.Attach(ctx, ref, doc, f, opts...)
+		if err != nil {
+			return err
+		}
+		p.Printfln("%s sbom attached as %s", f, d)
+	}
+	return nil
+}
+
+// registryKeychain returns a keychain that resolves registry credentials from
+// the supplied profile, falling back to the Docker config.
+func registryKeychain(upCtx *upbound.Context, profile string) authn.Keychain {
+	return authn.NewMultiKeychain(
 		authn.NewKeychainFromHelper(
-			credhelper.New(
-				credhelper.WithDomain(upCtx.Domain.Hostname()),
-				credhelper.WithProfile(profile),
-			),
+			upCtx.CredentialHelper(profile),
 		),
 		authn.DefaultKeychain,
 	)
+}
+
+// PushImages pushes the supplied packages to the supplied tags, creating the
+// repository first if requested.
+func PushImages(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, tags []string, create bool, profile string) error {
+	if len(tags) == 0 {
+		return errors.New(errNoTags)
+	}
+	tag, err := name.NewTag(tags[0], name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return err
+	}
+
+	kc := registryKeychain(upCtx, profile)
 
 	if create {
 		if !strings.Contains(tag.RegistryStr(), upCtx.RegistryEndpoint.Hostname()) {
@@ -128,129 +188,23 @@ func PushImages(p pterm.TextPrinter, upCtx *upbound.Context, imgs []v1.Image, t
 		if err != nil {
 			return err
 		}
-		if err := repositories.NewClient(cfg).CreateOrUpdate(context.Background(), parts[0], parts[1]); err != nil {
+		if err := repositories.NewClient(cfg).CreateOrUpdate(ctx, parts[0], parts[1]); err != nil {
 			return errors.Wrap(err, errCreateRepo)
 		}
 	}
 
-	adds := make([]mutate.IndexAddendum, len(imgs))
-
-	// NOTE(hasheddan): the errgroup context is passed to each image write,
-	// meaning that if one fails it will cancel others that are in progress.
-	g, ctx := errgroup.WithContext(context.Background())
-	for i, img := range imgs {
-		// pin range variables for use in go func
-		i, img := i, img
-		g.Go(func() error {
-			// annotate image layers
-			aimg, err := annotate(img)
-			if err != nil {
-				return err
-			}
-
-			var t name.Reference = tag
-			if len(imgs) > 1 {
-				d, err := aimg.Digest()
-				if err != nil {
-					return err
-				}
-				t, err = name.NewDigest(fmt.Sprintf("%s@%s", tag.Repository.Name(), d.String()), name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
-				if err != nil {
-					return err
-				}
-
-				mt, err := aimg.MediaType()
-				if err != nil {
-					return err
-				}
-
-				conf, err := aimg.ConfigFile()
-				if err != nil {
-					return err
-				}
-
-				adds[i] = mutate.IndexAddendum{
-					Add: aimg,
-					Descriptor: v1.Descriptor{
-						MediaType: mt,
-						Platform: &v1.Platform{
-							Architecture: conf.Architecture,
-							OS:           conf.OS,
-							OSVersion:    conf.OSVersion,
-						},
-					},
-				}
-			}
-			if err := remote.Write(t, aimg, remote.WithAuthFromKeychain(kc), remote.WithContext(ctx)); err != nil {
-				return err
-			}
-			return nil
-		})
-	}
-
-	// Error if writing any images failed.
-	if err := g.Wait(); err != nil {
+	pusher := xpkg.NewPusher(
+		xpkg.WithKeychain(kc),
+		xpkg.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()),
+	)
+	d, err := pusher.Push(ctx, imgs, tags...)
+	if err != nil {
 		return err
 	}
 
-	// If we pushed more than one xpkg then we need to write index.
-	if len(imgs) > 1 {
-		if err := remote.WriteIndex(tag, mutate.AppendManifests(empty.Index, adds...), remote.WithAuthFromKeychain(kc)); err != nil {
-			return err
-		}
+	for _, t := range tags {
+		p.Printfln("xpkg pushed to %s", t)
 	}
-
-	p.Printfln("xpkg pushed to %s", tag.String())
+	p.Printfln("digest: %s", d.String())
 	return nil
 }
-
-// annotate reads in the layers of the given v1.Image and annotates the xpkg
-// layers with their corresponding annotations, returning a new v1.Image
-// containing the annotation details.
-func annotate(i v1.Image) (v1.Image, error) { //nolint:gocyclo
-	cfgFile, err := i.ConfigFile()
-	if err != nil {
-		return nil, err
-	}
-
-	layers, err := i.Layers()
-	if err != nil {
-		return nil, err
-	}
-
-	addendums := make([]mutate.Addendum, 0)
-
-	for _, l := range layers {
-		d, err := l.Digest()
-		if err != nil {
-			return nil, err
-		}
-		if annotation, ok := cfgFile.Config.Labels[xpkg.Label(d.String())]; ok {
-			addendums = append(addendums, mutate.Addendum{
-				Layer: l,
-				Annotations: map[string]string{
-					xpkg.AnnotationKey: annotation,
-				},
-			})
-			continue
-		}
-		addendums = append(addendums, mutate.Addendum{
-			Layer: l,
-		})
-	}
-
-	// we didn't find any annotations, return original image
-	if len(addendums) == 0 {
-		return i, nil
-	}
-
-	img := empty.Image
-	for _, a := range addendums {
-		img, err = mutate.Append(img, a)
-		if err != nil {
-			return nil, errors.Wrap(err, errBuildImage)
-		}
-	}
-
-	return mutate.ConfigFile(img, cfgFile)
-}
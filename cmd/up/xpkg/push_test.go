@@ -0,0 +1,84 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/tokenstore"
+	"github.com/upbound/up/internal/upbound"
+)
+
+func TestPushImagesStoredSession(t *testing.T) {
+	const session = "stored-session"
+
+	// A registry that only accepts the session of the profile.
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pw, ok := r.BasicAuth(); !ok || pw != session {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	// Logging in writes the session to the token store, not the config file.
+	tokens := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+	if err := tokens.Set(tokenstore.SessionKey("default"), session); err != nil {
+		t.Fatalf("Set(...): %s", err)
+	}
+	cfg := &config.Config{}
+	if err := cfg.AddOrUpdateUpboundProfile("default", profile.Profile{Type: profile.User, ID: "someone", Account: "example"}); err != nil {
+		t.Fatalf("AddOrUpdateUpboundProfile(...): %s", err)
+	}
+	if err := cfg.SetDefaultUpboundProfile("default"); err != nil {
+		t.Fatalf("SetDefaultUpboundProfile(...): %s", err)
+	}
+	upCtx := &upbound.Context{
+		Domain:           &url.URL{Host: u.Hostname()},
+		RegistryEndpoint: u,
+		CfgSrc: &config.MockSource{
+			InitializeFn: func() error { return nil },
+			GetConfigFn:  func() (*config.Config, error) { return cfg, nil },
+		},
+		Tokens: tokens,
+	}
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("random.Image(...): %s", err)
+	}
+	p := pterm.DefaultBasicText.WithWriter(io.Discard)
+	if err := PushImages(context.Background(), p, upCtx, []v1.Image{img}, []string{u.Host + "/example/pkg:v1"}, false, "default"); err != nil {
+		t.Errorf("PushImages(...): pushing with a session only in the token store: %s", err)
+	}
+}
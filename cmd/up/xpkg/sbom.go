@@ -0,0 +1,71 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"os"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/xpkg/sbom"
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *sbomCmd) AfterApply(kongCtx *kong.Context) error {
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	return nil
+}
+
+// sbomCmd fetches the SBOM attached to a package.
+type sbomCmd struct {
+	Package string `arg:"" help:"Package to fetch the SBOM of. Must be a valid OCI image reference."`
+	Format  string `arg:"" optional:"" enum:"spdx,cyclonedx" default:"spdx" help:"Format of the SBOM to fetch. One of: spdx, cyclonedx."`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *sbomCmd) Help() string {
+	return `
+The sbom command fetches the software bill of materials attached to a package
+in a registry and writes it to stdout. SBOMs are generated by
+"up xpkg build --sbom" and attached to the package by "up xpkg push":
+
+  up xpkg sbom xpkg.upbound.io/upbound/platform-ref-aws:v0.6.0 cyclonedx
+`
+}
+
+// Run executes the sbom command.
+func (c *sbomCmd) Run(ctx context.Context, upCtx *upbound.Context) error {
+	ref, err := name.ParseReference(c.Package, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return err
+	}
+	doc, err := sbom.Fetch(ctx, ref, sbom.Format(c.Format), remote.WithAuthFromKeychain(registryKeychain(upCtx, c.Flags.Profile)))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(doc)
+	return err
+}
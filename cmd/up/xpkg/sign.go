@@ -0,0 +1,145 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/xpkg/sign"
+)
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *signCmd) AfterApply(kongCtx *kong.Context) error {
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	return nil
+}
+
+// signCmd signs a package in a registry.
+type signCmd struct {
+	Package string `arg:"" help:"Package to sign. Must be a valid OCI image reference."`
+
+	Sign sign.SignFlags `embed:""`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *signCmd) Help() string {
+	return `
+The sign command signs a package that was pushed to a registry and pushes the
+signature next to it, in the same format as cosign.
+
+Sign with a key, e.g. one generated with "cosign generate-key-pair":
+
+  up xpkg sign xpkg.upbound.io/acme/platform:v1.0.0 --sign-key cosign.key
+
+Or keylessly, with a short-lived certificate issued for an OIDC identity. The
+signature is recorded in the Rekor transparency log:
+
+  up xpkg sign xpkg.upbound.io/acme/platform:v1.0.0 --sign-keyless --identity-token $TOKEN
+`
+}
+
+// Run executes the sign command.
+func (c *signCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	ref, err := name.ParseReference(c.Package, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return err
+	}
+	s, err := c.Sign.Signer()
+	if err != nil {
+		return err
+	}
+	d, err := sign.Sign(ctx, ref, s, remote.WithAuthFromKeychain(registryKeychain(upCtx, c.Flags.Profile)))
+	if err != nil {
+		return err
+	}
+	p.Printfln("xpkg %s signed", d)
+	return nil
+}
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *verifyCmd) AfterApply(kongCtx *kong.Context) error {
+	upCtx, err := upbound.NewFromFlags(c.Flags)
+	if err != nil {
+		return err
+	}
+	kongCtx.Bind(upCtx)
+	return nil
+}
+
+// verifyCmd verifies the signature of a package in a registry.
+type verifyCmd struct {
+	Package string `arg:"" help:"Package to verify. Must be a valid OCI image reference."`
+
+	Verify sign.VerifyFlags `embed:""`
+
+	// Common Upbound API configuration
+	Flags upbound.Flags `embed:""`
+}
+
+func (c *verifyCmd) Help() string {
+	return `
+The verify command checks that a package in a registry has a signature made
+with a trusted key or by a trusted keyless identity, and prints the verified
+digest.
+
+Verify with a public key, e.g. one generated with "cosign generate-key-pair":
+
+  up xpkg verify xpkg.upbound.io/acme/platform:v1.0.0 --verify-key cosign.pub
+
+Or keylessly, trusting an OIDC identity certified by the supplied roots, whose
+signature was recorded in the transparency log with the supplied key:
+
+  up xpkg verify xpkg.upbound.io/acme/platform:v1.0.0 \
+    --certificate-identity ci@acme.io \
+    --certificate-oidc-issuer https://token.actions.githubusercontent.com \
+    --certificate-roots fulcio-roots.pem \
+    --rekor-key rekor.pub
+
+The transparency log entry, its inclusion proof and the log's signed
+checkpoint are verified offline. Signed certificate timestamps are not.
+`
+}
+
+// Run executes the verify command.
+func (c *verifyCmd) Run(ctx context.Context, p pterm.TextPrinter, upCtx *upbound.Context) error {
+	ref, err := name.ParseReference(c.Package, name.WithDefaultRegistry(upCtx.RegistryEndpoint.Hostname()))
+	if err != nil {
+		return err
+	}
+	vs, err := c.Verify.Verifiers()
+	if err != nil {
+		return err
+	}
+	d, err := sign.Verify(ctx, ref, vs, remote.WithAuthFromKeychain(registryKeychain(upCtx, c.Flags.Profile)))
+	if err != nil {
+		return err
+	}
+	p.Printfln("xpkg %s has a trusted signature", d)
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
@@ -34,6 +35,8 @@ import (
 
 	"github.com/upbound/up/internal/upbound"
 	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+	"github.com/upbound/up/internal/xpkg/pkgcache"
 )
 
 const (
@@ -64,6 +67,24 @@ func registryFetch(ctx context.Context, r name.Reference) (v1.Image, error) {
 	return remote.Image(r, remote.WithContext(ctx))
 }
 
+// cachedRegistryFetch fetches a package from the registry, serving it from
+// the supplied cache if it has been fetched before.
+func cachedRegistryFetch(c *pkgcache.Cache) fetchFn {
+	f := image.NewCachedFetcher(image.NewLocalFetcher(), c)
+	return func(ctx context.Context, r name.Reference) (v1.Image, error) {
+		return f.Fetch(ctx, r)
+	}
+}
+
+// platformFetchFn fetches the image for a platform from a source.
+type platformFetchFn func(context.Context, name.Reference, v1.Platform) (v1.Image, error)
+
+// registryPlatformFetch fetches the image for the supplied platform from the
+// registry.
+func registryPlatformFetch(ctx context.Context, r name.Reference, p v1.Platform) (v1.Image, error) {
+	return remote.Image(r, remote.WithContext(ctx), remote.WithPlatform(p), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+}
+
 // daemonFetch fetches a package from the Docker daemon.
 func daemonFetch(ctx context.Context, r name.Reference) (v1.Image, error) {
 	return daemon.Image(r, daemon.WithContext(ctx))
@@ -80,6 +101,9 @@ func xpkgFetch(path string) fetchFn {
 func (c *xpExtractCmd) AfterApply() error {
 	c.fs = afero.NewOsFs()
 	c.fetch = registryFetch
+	if pc, err := pkgcache.Default(); err == nil {
+		c.fetch = cachedRegistryFetch(pc)
+	}
 	if c.FromDaemon {
 		c.fetch = daemonFetch
 	}
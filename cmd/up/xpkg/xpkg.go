@@ -32,6 +32,12 @@ type Cmd struct {
 	Init      initCmd      `cmd:"" help:"Initialize a package, by default in the current directory."`
 	Dep       depCmd       `cmd:"" help:"Manage package dependencies in the filesystem and populate the cache, e.g. used by the Crossplane Language Server."`
 	Push      pushCmd      `cmd:"" help:"Push a package."`
+	Inspect   inspectCmd   `cmd:"" help:"Show the contents of a package without installing it."`
+	Lint      lintCmd      `cmd:"" maturity:"alpha" help:"Check a package for common issues."`
+	Mirror    mirrorCmd    `cmd:"" maturity:"alpha" help:"Copy a package and its locked dependencies to another registry."`
+	Sign      signCmd      `cmd:"" maturity:"alpha" help:"Sign a package in a registry."`
+	Verify    verifyCmd    `cmd:"" maturity:"alpha" help:"Verify the signature of a package in a registry."`
+	SBOM      sbomCmd      `cmd:"" name:"sbom" maturity:"alpha" help:"Fetch the SBOM attached to a package in a registry."`
 	Batch     batchCmd     `cmd:"" maturity:"alpha" help:"Batch build and push a family of service-scoped provider packages."`
 }
 
@@ -0,0 +1,246 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit contains a client for the audit logs of Upbound accounts.
+package audit
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/upbound/up-sdk-go"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	// DefaultPageSize is the number of events requested per page.
+	DefaultPageSize = 100
+
+	accountsPath = "v1/accounts"
+	auditBody    = "auditLogs"
+
+	paramSince  = "since"
+	paramUntil  = "until"
+	paramActor  = "actor"
+	paramAction = "action"
+	paramCursor = "cursor"
+	paramSize   = "size"
+
+	errListEvents     = "cannot list audit events"
+	errInvalidRange   = "the end of the time range must not be before its start"
+	errFmtCursorCycle = "audit log returned cursor %q more than once"
+)
+
+// An Action is something that happened in an account.
+type Action string
+
+// Well-known actions. The audit log may contain others.
+const (
+	ActionControlPlaneCreate Action = "controlPlane.create"
+	ActionControlPlaneDelete Action = "controlPlane.delete"
+	ActionTokenUse           Action = "token.use"
+)
+
+// An Actor is who performed an action.
+type Actor struct {
+	// Type of the actor, e.g. user or robot.
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// A Target is what an action was performed on.
+type Target struct {
+	// Type of the target, e.g. controlPlane or token.
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// An Event is an entry in the audit log.
+type Event struct {
+	ID       string
+	Time     time.Time
+	Action   Action
+	Actor    Actor
+	Target   Target
+	SourceIP string `json:",omitempty" yaml:",omitempty"`
+}
+
+// Filter selects the events returned. Empty fields match everything.
+type Filter struct {
+	// Since excludes events before this time.
+	Since time.Time
+	// Until excludes events at or after this time.
+	Until time.Time
+	// Actors restricts events to those performed by any of these actors,
+	// identified by name or ID.
+	Actors []string
+	// Actions restricts events to any of these actions.
+	Actions []Action
+}
+
+// Validate returns an error if the filter can never match an event.
+func (f Filter) Validate() error {
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return failure.Usage(errors.New(errInvalidRange))
+	}
+	return nil
+}
+
+// A Page of events, oldest first.
+type Page struct {
+	Events []Event
+	// NextCursor requests the next page of events. It is empty if this is
+	// the last page.
+	NextCursor string
+}
+
+type eventAttributes struct {
+	Time     time.Time `json:"time"`
+	Action   Action    `json:"action"`
+	Actor    Actor     `json:"actor"`
+	Target   Target    `json:"target"`
+	SourceIP string    `json:"sourceIP"`
+}
+
+type eventData struct {
+	ID         string          `json:"id"`
+	Attributes eventAttributes `json:"attributes"`
+}
+
+type eventsMeta struct {
+	NextCursor string `json:"nextCursor"`
+}
+
+type eventsResponse struct {
+	Data []eventData `json:"data"`
+	Meta eventsMeta  `json:"meta"`
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithPageSize sets the number of events requested per page.
+func WithPageSize(n int) Option {
+	return func(c *Client) {
+		c.pageSize = n
+	}
+}
+
+// WithLogger overrides the default logger.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// Client reads the audit log of an account on Upbound.
+type Client struct {
+	api      up.Client
+	account  string
+	pageSize int
+	log      logging.Logger
+}
+
+// New constructs a new Client for the audit log of the named account.
+func New(api up.Client, account string, opts ...Option) *Client {
+	c := &Client{
+		api:      api,
+		account:  account,
+		pageSize: DefaultPageSize,
+		log:      logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// List returns the page of events matching the supplied filter that starts
+// at the supplied cursor. An empty cursor requests the first page.
+func (c *Client) List(ctx context.Context, f Filter, cursor string) (*Page, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodGet, accountsPath, path.Join(c.account, auditBody), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, errListEvents)
+	}
+	q := req.URL.Query()
+	if !f.Since.IsZero() {
+		q.Set(paramSince, f.Since.UTC().Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		q.Set(paramUntil, f.Until.UTC().Format(time.RFC3339))
+	}
+	for _, a := range f.Actors {
+		q.Add(paramActor, a)
+	}
+	for _, a := range f.Actions {
+		q.Add(paramAction, string(a))
+	}
+	if cursor != "" {
+		q.Set(paramCursor, cursor)
+	}
+	q.Set(paramSize, strconv.Itoa(c.pageSize))
+	req.URL.RawQuery = q.Encode()
+
+	c.log.Debug("Listing audit events", "account", c.account, "cursor", cursor)
+	res := &eventsResponse{}
+	if err := c.api.Do(req, res); err != nil {
+		return nil, errors.Wrap(err, errListEvents)
+	}
+	p := &Page{Events: make([]Event, len(res.Data)), NextCursor: res.Meta.NextCursor}
+	for i, d := range res.Data {
+		p.Events[i] = Event{
+			ID:       d.ID,
+			Time:     d.Attributes.Time,
+			Action:   d.Attributes.Action,
+			Actor:    d.Attributes.Actor,
+			Target:   d.Attributes.Target,
+			SourceIP: d.Attributes.SourceIP,
+		}
+	}
+	return p, nil
+}
+
+// ListAll returns every event matching the supplied filter, following
+// pagination until the audit log is exhausted.
+func (c *Client) ListAll(ctx context.Context, f Filter) ([]Event, error) {
+	events := []Event{}
+	seen := map[string]bool{}
+	cursor := ""
+	for {
+		p, err := c.List(ctx, f, cursor)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, p.Events...)
+		if p.NextCursor == "" {
+			return events, nil
+		}
+		if seen[p.NextCursor] {
+			return nil, errors.Errorf(errFmtCursorCycle, p.NextCursor)
+		}
+		seen[p.NextCursor] = true
+		cursor = p.NextCursor
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+// mockAPI responds to requests with the JSON registered for their cursor.
+type mockAPI struct {
+	pages   map[string]string
+	err     error
+	queries []url.Values
+}
+
+func (m *mockAPI) NewRequest(ctx context.Context, method, prefix, urlPath string, _ interface{}) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, "https://api.upbound.io/"+prefix+"/"+urlPath, &bytes.Buffer{})
+}
+
+func (m *mockAPI) Do(req *http.Request, obj interface{}) error {
+	if req.URL.Path != "/v1/accounts/acme/auditLogs" {
+		return errors.Errorf("unexpected path %q", req.URL.Path)
+	}
+	q := req.URL.Query()
+	m.queries = append(m.queries, q)
+	if m.err != nil {
+		return m.err
+	}
+	return json.Unmarshal([]byte(m.pages[q.Get(paramCursor)]), obj)
+}
+
+func TestList(t *testing.T) {
+	errBoom := errors.New("boom")
+	since := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	type args struct {
+		f      Filter
+		cursor string
+	}
+	type want struct {
+		page  *Page
+		query url.Values
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		api    *mockAPI
+		args   args
+		want   want
+	}{
+		"InvalidRange": {
+			reason: "A time range that ends before it starts should be rejected without a request.",
+			api:    &mockAPI{},
+			args: args{
+				f: Filter{Since: until, Until: since},
+			},
+			want: want{
+				err: failure.Usage(errors.New(errInvalidRange)),
+			},
+		},
+		"Error": {
+			reason: "An error listing events should be returned.",
+			api:    &mockAPI{err: errBoom},
+			want: want{
+				query: url.Values{paramSize: {"100"}},
+				err:   errors.Wrap(errBoom, errListEvents),
+			},
+		},
+		"Filtered": {
+			reason: "Filters and the cursor should be sent as query parameters and events returned.",
+			api: &mockAPI{
+				pages: map[string]string{
+					"abc": `{"data":[{"id":"1","attributes":{"time":"2023-10-01T01:00:00Z","action":"controlPlane.create","actor":{"type":"user","id":"42","name":"jane"},"target":{"type":"controlPlane","name":"prod"},"sourceIP":"10.0.0.1"}}],"meta":{"nextCursor":"def"}}`,
+				},
+			},
+			args: args{
+				f: Filter{
+					Since:   since,
+					Until:   until,
+					Actors:  []string{"jane", "ci-robot"},
+					Actions: []Action{ActionControlPlaneCreate, ActionControlPlaneDelete},
+				},
+				cursor: "abc",
+			},
+			want: want{
+				page: &Page{
+					Events: []Event{{
+						ID:       "1",
+						Time:     since.Add(time.Hour),
+						Action:   ActionControlPlaneCreate,
+						Actor:    Actor{Type: "user", ID: "42", Name: "jane"},
+						Target:   Target{Type: "controlPlane", Name: "prod"},
+						SourceIP: "10.0.0.1",
+					}},
+					NextCursor: "def",
+				},
+				query: url.Values{
+					paramSince:  {"2023-10-01T00:00:00Z"},
+					paramUntil:  {"2023-10-02T00:00:00Z"},
+					paramActor:  {"jane", "ci-robot"},
+					paramAction: {"controlPlane.create", "controlPlane.delete"},
+					paramCursor: {"abc"},
+					paramSize:   {"100"},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(tc.api, "acme").List(context.Background(), tc.args.f, tc.args.cursor)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nList(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.page, got); diff != "" {
+				t.Errorf("\n%s\nList(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			var query url.Values
+			if len(tc.api.queries) > 0 {
+				query = tc.api.queries[0]
+			}
+			if diff := cmp.Diff(tc.want.query, query); diff != "" {
+				t.Errorf("\n%s\nList(...): -want query, +got query:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestListAll(t *testing.T) {
+	type want struct {
+		ids []string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		api    *mockAPI
+		want   want
+	}{
+		"FollowsCursor": {
+			reason: "Pages should be requested until there is no next cursor.",
+			api: &mockAPI{
+				pages: map[string]string{
+					"":    `{"data":[{"id":"1"},{"id":"2"}],"meta":{"nextCursor":"abc"}}`,
+					"abc": `{"data":[{"id":"3"}],"meta":{}}`,
+				},
+			},
+			want: want{
+				ids: []string{"1", "2", "3"},
+			},
+		},
+		"CursorCycle": {
+			reason: "A cursor returned more than once should fail rather than loop forever.",
+			api: &mockAPI{
+				pages: map[string]string{
+					"":    `{"data":[{"id":"1"}],"meta":{"nextCursor":"abc"}}`,
+					"abc": `{"data":[{"id":"2"}],"meta":{"nextCursor":"abc"}}`,
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtCursorCycle, "abc"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			events, err := New(tc.api, "acme", WithPageSize(2)).ListAll(context.Background(), Filter{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nListAll(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var ids []string
+			for _, e := range events {
+				ids = append(ids, e.ID)
+			}
+			if diff := cmp.Diff(tc.want.ids, ids); diff != "" {
+				t.Errorf("\n%s\nListAll(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
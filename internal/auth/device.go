@@ -0,0 +1,345 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/pkg/browser"
+
+	uphttp "github.com/upbound/up/internal/http"
+)
+
+const (
+	// DefaultClientID is the OAuth2 client ID of up.
+	DefaultClientID = "up-cli"
+
+	devicePath = "/v1/oauth/device/code"
+	tokenPath  = "/v1/oauth/token"
+
+	grantTypeDeviceCode   = "urn:ietf:params:oauth:grant-type:device_code"
+	grantTypeRefreshToken = "refresh_token"
+
+	// Error codes returned by the token endpoint while polling. See
+	// https://www.rfc-editor.org/rfc/rfc8628#section-3.5
+	errCodeAuthorizationPending = "authorization_pending"
+	errCodeSlowDown             = "slow_down"
+	errCodeAccessDenied         = "access_denied"
+	errCodeExpiredToken         = "expired_token"
+
+	defaultPollInterval = 5 * time.Second
+	slowDownIncrement   = 5 * time.Second
+
+	errRequestDeviceCode = "unable to request device code"
+	errPollToken         = "unable to acquire token"
+	errRefreshToken      = "unable to refresh token"
+	errAccessDenied      = "authorization request was denied"
+	errExpiredDeviceCode = "device code expired before authorization completed"
+	errFmtTokenEndpoint  = "token endpoint returned %s"
+)
+
+// DeviceCode is the response to a device authorization request.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// Token is an OAuth2 token acquired through the device flow.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	ExpiresIn    int       `json:"expires_in,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Expired returns true if the token has an expiry that is before now.
+func (t *Token) Expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Before(t.Expiry)
+}
+
+// tokenError is the error body returned by the token endpoint.
+type tokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *tokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// DeviceFlow implements the OAuth2 device authorization grant, allowing users
+// on headless machines to authorize up from a browser on another device.
+type DeviceFlow struct {
+	client   uphttp.Client
+	endpoint *url.URL
+	clientID string
+	scopes   []string
+
+	out         io.Writer
+	openBrowser func(url string) error
+	now         func() time.Time
+	sleep       func(ctx context.Context, d time.Duration) error
+}
+
+// DeviceFlowModifierFn modifies the device flow.
+type DeviceFlowModifierFn func(*DeviceFlow)
+
+// WithDeviceEndpoint sets the authorization server endpoint.
+func WithDeviceEndpoint(endpoint *url.URL) DeviceFlowModifierFn {
+	return func(d *DeviceFlow) {
+		d.endpoint = endpoint
+	}
+}
+
+// WithClientID sets the OAuth2 client ID.
+func WithClientID(id string) DeviceFlowModifierFn {
+	return func(d *DeviceFlow) {
+		d.clientID = id
+	}
+}
+
+// WithScopes sets the scopes requested during authorization.
+func WithScopes(scopes ...string) DeviceFlowModifierFn {
+	return func(d *DeviceFlow) {
+		d.scopes = scopes
+	}
+}
+
+// WithDeviceClient sets the HTTP client used to talk to the authorization
+// server.
+func WithDeviceClient(c uphttp.Client) DeviceFlowModifierFn {
+	return func(d *DeviceFlow) {
+		d.client = c
+	}
+}
+
+// WithPrompt sets the writer the verification instructions are written to.
+func WithPrompt(w io.Writer) DeviceFlowModifierFn {
+	return func(d *DeviceFlow) {
+		d.out = w
+	}
+}
+
+// WithBrowser sets the function used to open the verification URL. Passing
+// nil disables opening a browser, e.g. on headless servers.
+func WithBrowser(fn func(url string) error) DeviceFlowModifierFn {
+	return func(d *DeviceFlow) {
+		d.openBrowser = fn
+	}
+}
+
+// NewDeviceFlow constructs a new device flow.
+func NewDeviceFlow(modifiers ...DeviceFlowModifierFn) *DeviceFlow {
+	d := &DeviceFlow{
+		client:      uphttp.NewClient(),
+		clientID:    DefaultClientID,
+		out:         io.Discard,
+		openBrowser: browser.OpenURL,
+		now:         time.Now,
+		sleep:       sleep,
+	}
+	for _, m := range modifiers {
+		m(d)
+	}
+	return d
+}
+
+// Login runs the full device flow: it requests a device code, instructs the
+// user to authorize it, and waits until a token is issued.
+func (d *DeviceFlow) Login(ctx context.Context) (*Token, error) {
+	dc, err := d.Authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u := dc.VerificationURIComplete
+	if u == "" {
+		u = dc.VerificationURI
+	}
+	fmt.Fprintf(d.out, "To authorize up, visit %s and enter the code: %s\n", dc.VerificationURI, dc.UserCode) // nolint:errcheck
+	if d.openBrowser != nil {
+		// Failing to open a browser is expected on headless machines; the
+		// user can still follow the printed instructions.
+		_ = d.openBrowser(u)
+	}
+	return d.Poll(ctx, dc)
+}
+
+// Authorize requests a device code from the authorization server.
+func (d *DeviceFlow) Authorize(ctx context.Context) (*DeviceCode, error) {
+	v := url.Values{}
+	v.Set("client_id", d.clientID)
+	if len(d.scopes) > 0 {
+		v.Set("scope", strings.Join(d.scopes, " "))
+	}
+	res, err := d.post(ctx, devicePath, v)
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestDeviceCode)
+	}
+	defer res.Body.Close() // nolint:gosec,errcheck
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestDeviceCode)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Wrap(parseTokenError(res.StatusCode, b), errRequestDeviceCode)
+	}
+	dc := &DeviceCode{}
+	if err := json.Unmarshal(b, dc); err != nil {
+		return nil, errors.Wrap(err, errRequestDeviceCode)
+	}
+	return dc, nil
+}
+
+// Poll polls the token endpoint until the device code is authorized, denied,
+// or expires.
+func (d *DeviceFlow) Poll(ctx context.Context, dc *DeviceCode) (*Token, error) {
+	interval := defaultPollInterval
+	if dc.Interval > 0 {
+		interval = time.Duration(dc.Interval) * time.Second
+	}
+	var deadline time.Time
+	if dc.ExpiresIn > 0 {
+		deadline = d.now().Add(time.Duration(dc.ExpiresIn) * time.Second)
+	}
+
+	v := url.Values{}
+	v.Set("grant_type", grantTypeDeviceCode)
+	v.Set("device_code", dc.DeviceCode)
+	v.Set("client_id", d.clientID)
+	for {
+		if !deadline.IsZero() && !d.now().Before(deadline) {
+			return nil, errors.New(errExpiredDeviceCode)
+		}
+		if err := d.sleep(ctx, interval); err != nil {
+			return nil, errors.Wrap(err, errPollToken)
+		}
+		t, err := d.token(ctx, v)
+		var te *tokenError
+		if !errors.As(err, &te) {
+			return t, errors.Wrap(err, errPollToken)
+		}
+		switch te.Code {
+		case errCodeAuthorizationPending:
+		case errCodeSlowDown:
+			interval += slowDownIncrement
+		case errCodeAccessDenied:
+			return nil, errors.New(errAccessDenied)
+		case errCodeExpiredToken:
+			return nil, errors.New(errExpiredDeviceCode)
+		default:
+			return nil, errors.Wrap(err, errPollToken)
+		}
+	}
+}
+
+// Refresh exchanges a refresh token for a new token. If the server does not
+// issue a new refresh token, the supplied one is carried over.
+func (d *DeviceFlow) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	v := url.Values{}
+	v.Set("grant_type", grantTypeRefreshToken)
+	v.Set("refresh_token", refreshToken)
+	v.Set("client_id", d.clientID)
+	t, err := d.token(ctx, v)
+	if err != nil {
+		return nil, errors.Wrap(err, errRefreshToken)
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = refreshToken
+	}
+	return t, nil
+}
+
+// Token returns t if it is still valid, or a refreshed token if it has
+// expired and can be refreshed.
+func (d *DeviceFlow) Token(ctx context.Context, t *Token) (*Token, error) {
+	if !t.Expired(d.now()) || t.RefreshToken == "" {
+		return t, nil
+	}
+	return d.Refresh(ctx, t.RefreshToken)
+}
+
+func (d *DeviceFlow) token(ctx context.Context, v url.Values) (*Token, error) {
+	res, err := d.post(ctx, tokenPath, v)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close() // nolint:gosec,errcheck
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, parseTokenError(res.StatusCode, b)
+	}
+	t := &Token{}
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, err
+	}
+	if t.ExpiresIn > 0 {
+		t.Expiry = d.now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+func (d *DeviceFlow) post(ctx context.Context, path string, v url.Values) (*http.Response, error) {
+	u := *d.endpoint
+	u.Path = path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return d.client.Do(req)
+}
+
+// parseTokenError returns the OAuth2 error in body, or a generic error
+// including the status code if the body is not an OAuth2 error.
+func parseTokenError(status int, body []byte) error {
+	te := &tokenError{}
+	if err := json.Unmarshal(body, te); err == nil && te.Code != "" {
+		return te
+	}
+	return errors.Errorf(errFmtTokenEndpoint, http.StatusText(status))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
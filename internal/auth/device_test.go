@@ -0,0 +1,226 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/http/mocks"
+)
+
+// sequence returns a mock client that responds with the supplied status codes
+// and bodies in order, repeating the last one.
+func sequence(reqs *[]*http.Request, responses ...string) *mocks.MockClient {
+	i := 0
+	return &mocks.MockClient{
+		DoFn: func(req *http.Request) (*http.Response, error) {
+			if reqs != nil {
+				*reqs = append(*reqs, req)
+			}
+			r := responses[i]
+			if i < len(responses)-1 {
+				i++
+			}
+			status := http.StatusOK
+			if strings.Contains(r, `"error"`) {
+				status = http.StatusBadRequest
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(r)),
+			}, nil
+		},
+	}
+}
+
+func testFlow(c *mocks.MockClient, slept *[]time.Duration) *DeviceFlow {
+	endpoint, _ := url.Parse("https://api.test.com")
+	now := time.Unix(0, 0)
+	d := NewDeviceFlow(
+		WithDeviceEndpoint(endpoint),
+		WithClientID("up-cli"),
+		WithDeviceClient(c),
+		WithBrowser(nil),
+	)
+	d.now = func() time.Time { return now }
+	d.sleep = func(_ context.Context, dur time.Duration) error {
+		*slept = append(*slept, dur)
+		now = now.Add(dur)
+		return nil
+	}
+	return d
+}
+
+func TestPoll(t *testing.T) {
+	type want struct {
+		token *Token
+		slept []time.Duration
+		err   error
+	}
+	cases := map[string]struct {
+		reason    string
+		code      *DeviceCode
+		responses []string
+		want      want
+	}{
+		"PendingThenSuccess": {
+			reason: "We should keep polling while authorization is pending.",
+			code:   &DeviceCode{DeviceCode: "dc", Interval: 1, ExpiresIn: 60},
+			responses: []string{
+				`{"error": "authorization_pending"}`,
+				`{"access_token": "at", "refresh_token": "rt", "expires_in": 3600}`,
+			},
+			want: want{
+				token: &Token{AccessToken: "at", RefreshToken: "rt", ExpiresIn: 3600, Expiry: time.Unix(3602, 0)},
+				slept: []time.Duration{time.Second, time.Second},
+			},
+		},
+		"SlowDown": {
+			reason: "We should increase the polling interval when asked to slow down.",
+			code:   &DeviceCode{DeviceCode: "dc", Interval: 1, ExpiresIn: 60},
+			responses: []string{
+				`{"error": "slow_down"}`,
+				`{"access_token": "at"}`,
+			},
+			want: want{
+				token: &Token{AccessToken: "at"},
+				slept: []time.Duration{time.Second, 6 * time.Second},
+			},
+		},
+		"AccessDenied": {
+			reason: "We should stop polling if the user denies the request.",
+			code:   &DeviceCode{DeviceCode: "dc"},
+			responses: []string{
+				`{"error": "access_denied"}`,
+			},
+			want: want{
+				slept: []time.Duration{defaultPollInterval},
+				err:   errors.New(errAccessDenied),
+			},
+		},
+		"Expired": {
+			reason: "We should stop polling once the device code expires.",
+			code:   &DeviceCode{DeviceCode: "dc", Interval: 5, ExpiresIn: 10},
+			responses: []string{
+				`{"error": "authorization_pending"}`,
+			},
+			want: want{
+				slept: []time.Duration{5 * time.Second, 5 * time.Second},
+				err:   errors.New(errExpiredDeviceCode),
+			},
+		},
+		"UnknownError": {
+			reason: "We should return unexpected token endpoint errors.",
+			code:   &DeviceCode{DeviceCode: "dc", Interval: 1},
+			responses: []string{
+				`{"error": "invalid_client", "error_description": "unknown client"}`,
+			},
+			want: want{
+				slept: []time.Duration{time.Second},
+				err:   errors.Wrap(&tokenError{Code: "invalid_client", Description: "unknown client"}, errPollToken),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var slept []time.Duration
+			got, err := testFlow(sequence(nil, tc.responses...), &slept).Poll(context.Background(), tc.code)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPoll(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.token, got); diff != "" {
+				t.Errorf("\n%s\nPoll(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.slept, slept); diff != "" {
+				t.Errorf("\n%s\nPoll(...): -want intervals, +got intervals:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	var reqs []*http.Request
+	var slept []time.Duration
+	var out bytes.Buffer
+	var opened string
+	d := testFlow(sequence(&reqs,
+		`{"device_code": "dc", "user_code": "ABCD-EFGH", "verification_uri": "https://upbound.io/device", "verification_uri_complete": "https://upbound.io/device?code=ABCD-EFGH", "interval": 1}`,
+		`{"access_token": "at"}`,
+	), &slept)
+	d.out = &out
+	d.openBrowser = func(u string) error {
+		opened = u
+		return errors.New("no browser")
+	}
+
+	got, err := d.Login(context.Background())
+	if err != nil {
+		t.Fatalf("Login(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&Token{AccessToken: "at"}, got); diff != "" {
+		t.Errorf("Login(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("https://upbound.io/device?code=ABCD-EFGH", opened); diff != "" {
+		t.Errorf("Login(...): -want opened URL, +got opened URL:\n%s", diff)
+	}
+	if !strings.Contains(out.String(), "ABCD-EFGH") {
+		t.Errorf("Login(...): expected instructions to include user code, got %q", out.String())
+	}
+	if diff := cmp.Diff([]string{devicePath, tokenPath}, []string{reqs[0].URL.Path, reqs[1].URL.Path}); diff != "" {
+		t.Errorf("Login(...): -want paths, +got paths:\n%s", diff)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	var reqs []*http.Request
+	var slept []time.Duration
+	d := testFlow(sequence(&reqs, `{"access_token": "new", "expires_in": 60}`), &slept)
+
+	expired := &Token{AccessToken: "old", RefreshToken: "rt", Expiry: time.Unix(0, 0)}
+	got, err := d.Token(context.Background(), expired)
+	if err != nil {
+		t.Fatalf("Token(...): unexpected error: %v", err)
+	}
+	want := &Token{AccessToken: "new", RefreshToken: "rt", ExpiresIn: 60, Expiry: time.Unix(60, 0)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Token(...): -want, +got:\n%s", diff)
+	}
+	if err := reqs[0].ParseForm(); err != nil {
+		t.Fatalf("ParseForm(): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("rt", reqs[0].PostForm.Get("refresh_token")); diff != "" {
+		t.Errorf("Token(...): -want refresh token, +got refresh token:\n%s", diff)
+	}
+
+	valid := &Token{AccessToken: "old", RefreshToken: "rt", Expiry: time.Unix(120, 0)}
+	got, err = d.Token(context.Background(), valid)
+	if err != nil {
+		t.Fatalf("Token(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(valid, got); diff != "" {
+		t.Errorf("Token(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/uuid"
+
+	"github.com/upbound/up-sdk-go"
+	"github.com/upbound/up-sdk-go/service/common"
+
+	"github.com/upbound/up/internal/tokenstore"
+)
+
+const (
+	sessionsPath = "v1/sessions"
+	tokensPath   = "v1/tokens"
+	logoutPath   = "v1/logout"
+
+	metaCurrent    = "current"
+	metaCreatedAt  = "createdAt"
+	metaLastUsedAt = "lastUsedAt"
+	attrName       = "name"
+
+	errListSessions = "cannot list sessions"
+	errListTokens   = "cannot list personal access tokens"
+	errLogout       = "cannot revoke current token"
+	errFmtRevoke    = "cannot revoke %s %s"
+	errFmtUnknown   = "unknown session kind %q"
+	errFmtClearKey  = "cannot remove %s from token store"
+)
+
+// SessionKind is the kind of credential a Session is.
+type SessionKind string
+
+// Session kinds.
+const (
+	// SessionKindSession is a session created by logging in.
+	SessionKindSession SessionKind = "session"
+	// SessionKindToken is a personal access token.
+	SessionKindToken SessionKind = "token"
+)
+
+// A Session is an active credential of the user, either a session created by
+// logging in or a personal access token.
+type Session struct {
+	ID         uuid.UUID
+	Kind       SessionKind
+	Name       string     `json:",omitempty" yaml:",omitempty"`
+	CreatedAt  *time.Time `json:",omitempty" yaml:",omitempty"`
+	LastUsedAt *time.Time `json:",omitempty" yaml:",omitempty"`
+	// Current is true for the credential used to list sessions.
+	Current bool
+}
+
+// listResponse is the response to listing sessions or tokens.
+type listResponse struct {
+	DataSet []common.DataSet `json:"data"`
+}
+
+// SessionClient lists and revokes the sessions and personal access tokens of
+// the user.
+type SessionClient struct {
+	api up.Client
+}
+
+// NewSessionClient constructs a new SessionClient.
+func NewSessionClient(api up.Client) *SessionClient {
+	return &SessionClient{api: api}
+}
+
+// List the active sessions and personal access tokens of the user, oldest
+// first.
+func (c *SessionClient) List(ctx context.Context) ([]Session, error) {
+	sessions, err := c.list(ctx, sessionsPath, SessionKindSession)
+	if err != nil {
+		return nil, errors.Wrap(err, errListSessions)
+	}
+	tokens, err := c.list(ctx, tokensPath, SessionKindToken)
+	if err != nil {
+		return nil, errors.Wrap(err, errListTokens)
+	}
+	all := make([]Session, 0, len(sessions)+len(tokens))
+	all = append(all, sessions...)
+	all = append(all, tokens...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return timeOrZero(all[i].CreatedAt).Before(timeOrZero(all[j].CreatedAt))
+	})
+	return all, nil
+}
+
+func (c *SessionClient) list(ctx context.Context, prefix string, kind SessionKind) ([]Session, error) {
+	req, err := c.api.NewRequest(ctx, http.MethodGet, prefix, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	res := &listResponse{}
+	if err := c.api.Do(req, res); err != nil {
+		return nil, err
+	}
+	out := make([]Session, len(res.DataSet))
+	for i, d := range res.DataSet {
+		out[i] = Session{
+			ID:         d.ID,
+			Kind:       kind,
+			Current:    d.Meta[metaCurrent] == true,
+			CreatedAt:  parseTime(d.Meta[metaCreatedAt]),
+			LastUsedAt: parseTime(d.Meta[metaLastUsedAt]),
+		}
+		if n, ok := d.AttributeSet[attrName]; ok {
+			out[i].Name = fmt.Sprint(n)
+		}
+	}
+	return out, nil
+}
+
+// Revoke the supplied session or personal access token. It can no longer be
+// used to authenticate.
+func (c *SessionClient) Revoke(ctx context.Context, kind SessionKind, id uuid.UUID) error {
+	prefix := ""
+	switch kind {
+	case SessionKindSession:
+		prefix = sessionsPath
+	case SessionKindToken:
+		prefix = tokensPath
+	default:
+		return errors.Errorf(errFmtUnknown, kind)
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodDelete, prefix, id.String(), nil)
+	if err != nil {
+		return errors.Wrapf(err, errFmtRevoke, kind, id)
+	}
+	return errors.Wrapf(c.api.Do(req, nil), errFmtRevoke, kind, id)
+}
+
+// Logout revokes the token used to authenticate the client.
+func (c *SessionClient) Logout(ctx context.Context) error {
+	req, err := c.api.NewRequest(ctx, http.MethodPost, logoutPath, "", nil)
+	if err != nil {
+		return errors.Wrap(err, errLogout)
+	}
+	return errors.Wrap(c.api.Do(req, nil), errLogout)
+}
+
+// ClearTokens removes the session, personal access, and refresh tokens of
+// the supplied profiles from the supplied store.
+func ClearTokens(store tokenstore.Store, profiles []string) error {
+	for _, p := range profiles {
+		for _, k := range []string{tokenstore.SessionKey(p), tokenstore.TokenKey(p), tokenstore.RefreshKey(p)} {
+			if err := store.Delete(k); err != nil && !tokenstore.IsNotFound(err) {
+				return errors.Wrapf(err, errFmtClearKey, k)
+			}
+		}
+	}
+	return nil
+}
+
+func parseTime(v any) *time.Time {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/tokenstore"
+)
+
+var (
+	sessionID = uuid.MustParse("5d7b4a38-7d3c-4d2a-9b0e-6b0f3b1a2c4d")
+	tokenID   = uuid.MustParse("9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d")
+)
+
+// mockAPI responds to requests with the JSON registered for their path.
+type mockAPI struct {
+	responses map[string]string
+	errs      map[string]error
+	reqs      []string
+}
+
+func (m *mockAPI) NewRequest(ctx context.Context, method, prefix, urlPath string, _ interface{}) (*http.Request, error) {
+	u := "https://api.upbound.io/" + prefix
+	if urlPath != "" {
+		u += "/" + urlPath
+	}
+	return http.NewRequestWithContext(ctx, method, u, &bytes.Buffer{})
+}
+
+func (m *mockAPI) Do(req *http.Request, obj interface{}) error {
+	key := req.Method + " " + req.URL.Path
+	m.reqs = append(m.reqs, key)
+	if err := m.errs[key]; err != nil {
+		return err
+	}
+	if obj == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(m.responses[key]), obj)
+}
+
+func TestSessionClientList(t *testing.T) {
+	errBoom := errors.New("boom")
+	created := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	older := created.Add(-time.Hour)
+	used := created.Add(time.Minute)
+
+	type want struct {
+		sessions []Session
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		api    *mockAPI
+		want   want
+	}{
+		"ErrorListSessions": {
+			reason: "An error listing sessions should be returned.",
+			api: &mockAPI{
+				errs: map[string]error{"GET /v1/sessions": errBoom},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errListSessions),
+			},
+		},
+		"ErrorListTokens": {
+			reason: "An error listing personal access tokens should be returned.",
+			api: &mockAPI{
+				responses: map[string]string{"GET /v1/sessions": `{"data":[]}`},
+				errs:      map[string]error{"GET /v1/tokens": errBoom},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errListTokens),
+			},
+		},
+		"Success": {
+			reason: "Sessions and tokens should be returned oldest first.",
+			api: &mockAPI{
+				responses: map[string]string{
+					"GET /v1/sessions": `{"data":[{"id":"` + sessionID.String() + `","meta":{"current":true,"createdAt":"` + created.Format(time.RFC3339) + `","lastUsedAt":"` + used.Format(time.RFC3339) + `"}}]}`,
+					"GET /v1/tokens":   `{"data":[{"id":"` + tokenID.String() + `","attributes":{"name":"ci"},"meta":{"createdAt":"` + older.Format(time.RFC3339) + `"}}]}`,
+				},
+			},
+			want: want{
+				sessions: []Session{
+					{ID: tokenID, Kind: SessionKindToken, Name: "ci", CreatedAt: &older},
+					{ID: sessionID, Kind: SessionKindSession, CreatedAt: &created, LastUsedAt: &used, Current: true},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewSessionClient(tc.api).List(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nList(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sessions, got); diff != "" {
+				t.Errorf("\n%s\nList(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSessionClientRevoke(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		kind SessionKind
+		id   uuid.UUID
+	}
+	type want struct {
+		reqs []string
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		api    *mockAPI
+		args   args
+		want   want
+	}{
+		"UnknownKind": {
+			reason: "Revoking an unknown kind of credential should fail without a request.",
+			api:    &mockAPI{},
+			args:   args{kind: "robot", id: sessionID},
+			want: want{
+				err: errors.Errorf(errFmtUnknown, "robot"),
+			},
+		},
+		"Session": {
+			reason: "Revoking a session should delete it.",
+			api:    &mockAPI{},
+			args:   args{kind: SessionKindSession, id: sessionID},
+			want: want{
+				reqs: []string{"DELETE /v1/sessions/" + sessionID.String()},
+			},
+		},
+		"Token": {
+			reason: "Revoking a personal access token should delete it.",
+			api:    &mockAPI{},
+			args:   args{kind: SessionKindToken, id: tokenID},
+			want: want{
+				reqs: []string{"DELETE /v1/tokens/" + tokenID.String()},
+			},
+		},
+		"Error": {
+			reason: "An error revoking a credential should be returned.",
+			api: &mockAPI{
+				errs: map[string]error{"DELETE /v1/tokens/" + tokenID.String(): errBoom},
+			},
+			args: args{kind: SessionKindToken, id: tokenID},
+			want: want{
+				reqs: []string{"DELETE /v1/tokens/" + tokenID.String()},
+				err:  errors.Wrapf(errBoom, errFmtRevoke, SessionKindToken, tokenID),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewSessionClient(tc.api).Revoke(context.Background(), tc.args.kind, tc.args.id)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRevoke(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reqs, tc.api.reqs); diff != "" {
+				t.Errorf("\n%s\nRevoke(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestClearTokens(t *testing.T) {
+	store := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+	for k, v := range map[string]string{
+		tokenstore.SessionKey("a"): "session",
+		tokenstore.RefreshKey("a"): "refresh",
+		tokenstore.TokenKey("b"):   "token",
+		tokenstore.TokenKey("c"):   "kept",
+	} {
+		if err := store.Set(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ClearTokens(store, []string{"a", "b", "missing"}); err != nil {
+		t.Errorf("ClearTokens(...): unexpected error: %v", err)
+	}
+	for _, k := range []string{tokenstore.SessionKey("a"), tokenstore.RefreshKey("a"), tokenstore.TokenKey("b")} {
+		if _, err := store.Get(k); !tokenstore.IsNotFound(err) {
+			t.Errorf("ClearTokens(...): %s: want not found, got %v", k, err)
+		}
+	}
+	if got, err := store.Get(tokenstore.TokenKey("c")); err != nil || got != "kept" {
+		t.Errorf("ClearTokens(...): token of other profile was removed: %q, %v", got, err)
+	}
+}
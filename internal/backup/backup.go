@@ -0,0 +1,322 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup manages the backups of control planes in a Space: where
+// they are stored, when they are taken, and restoring from them.
+package backup
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/capability"
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	// DefaultPollInterval is how often the status of a backup or restore is
+	// checked while waiting for it to complete.
+	DefaultPollInterval = 5 * time.Second
+	// DefaultTimeout is how long to wait for a backup or restore to
+	// complete.
+	DefaultTimeout = 30 * time.Minute
+
+	errFmtCreate        = "cannot create %s %q"
+	errFmtGet           = "cannot get %s %q"
+	errFmtUpdate        = "cannot update %s %q"
+	errFmtDelete        = "cannot delete %s %q"
+	errFmtList          = "cannot list %s"
+	errFmtBackupFailed  = "backup %q failed: %s"
+	errFmtRestoreFailed = "restore %q failed: %s"
+	errNoControlPlane   = "a control plane is required"
+	errNoConfig         = "a backup config is required"
+)
+
+var (
+	backups   = resources.BackupGVK.GroupVersion().WithResource("backups")
+	configs   = resources.SharedBackupConfigGVK.GroupVersion().WithResource("sharedbackupconfigs")
+	schedules = resources.BackupScheduleGVK.GroupVersion().WithResource("backupschedules")
+	restores  = resources.RestoreGVK.GroupVersion().WithResource("restores")
+)
+
+// A Phase of a backup or restore.
+type Phase string
+
+// Phases of a backup or restore.
+const (
+	PhasePending    Phase = "Pending"
+	PhaseInProgress Phase = "InProgress"
+	PhaseFailed     Phase = "Failed"
+	PhaseCompleted  Phase = "Completed"
+)
+
+// Done returns true if the phase is final.
+func (p Phase) Done() bool {
+	return p == PhaseFailed || p == PhaseCompleted
+}
+
+// Status of a backup or restore.
+type Status struct {
+	Phase Phase
+	// Message explains the phase, e.g. why a backup failed.
+	Message string
+}
+
+type phased interface {
+	GetPhase() string
+	GetCondition(ct xpv1.ConditionType) xpv1.Condition
+}
+
+func status(p phased) Status {
+	s := Status{Phase: Phase(p.GetPhase()), Message: p.GetCondition(xpv1.TypeReady).Message}
+	if s.Phase == "" {
+		s.Phase = PhasePending
+	}
+	return s
+}
+
+// BackupStatus returns the status of the supplied backup.
+func BackupStatus(b *resources.Backup) Status {
+	return status(b)
+}
+
+// RestoreStatus returns the status of the supplied restore.
+func RestoreStatus(r *resources.Restore) Status {
+	return status(r)
+}
+
+// Client manages the backups of control planes in a Space. Backups,
+// schedules and restores are created in the group of the control plane they
+// apply to.
+type Client struct {
+	c            dynamic.Interface
+	log          logging.Logger
+	caps         *capability.Capabilities
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithLogger sets the logger used by the Client.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// WithCapabilities makes the Client fail early if the Space does not support
+// backups.
+func WithCapabilities(caps *capability.Capabilities) Option {
+	return func(c *Client) {
+		c.caps = caps
+	}
+}
+
+// WithPollInterval sets how often the status of a backup or restore is
+// checked while waiting for it to complete.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = d
+	}
+}
+
+// WithTimeout sets how long to wait for a backup or restore to complete.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// New instantiates a new Client.
+func New(c dynamic.Interface, opts ...Option) *Client {
+	cl := &Client{
+		c:            c,
+		log:          logging.NewNopLogger(),
+		pollInterval: DefaultPollInterval,
+		timeout:      DefaultTimeout,
+	}
+	for _, o := range opts {
+		o(cl)
+	}
+	return cl
+}
+
+// BackupOptions for creating a Backup.
+type BackupOptions struct {
+	ControlPlane string
+	// Config is the name of the SharedBackupConfig to use.
+	Config string
+	// TTL is how long the backup is retained, e.g. 168h.
+	TTL string
+	// DeletionPolicy is whether the backup's data is deleted along with
+	// the Backup.
+	DeletionPolicy xpv1.DeletionPolicy
+}
+
+// CreateBackup creates a Backup of a control plane in the supplied group.
+func (c *Client) CreateBackup(ctx context.Context, group, name string, opts BackupOptions) (*resources.Backup, error) {
+	if opts.ControlPlane == "" {
+		return nil, errors.New(errNoControlPlane)
+	}
+	if opts.Config == "" {
+		return nil, errors.New(errNoConfig)
+	}
+	b := &resources.Backup{}
+	b.SetNamespace(group)
+	b.SetName(name)
+	b.SetControlPlane(opts.ControlPlane)
+	b.SetConfigReference(resources.BackupConfigReference{Kind: resources.SharedBackupConfigGVK.Kind, Name: opts.Config})
+	if opts.TTL != "" {
+		b.SetTTL(opts.TTL)
+	}
+	if opts.DeletionPolicy != "" {
+		b.SetDeletionPolicy(opts.DeletionPolicy)
+	}
+	u, err := c.create(ctx, backups, b.GetUnstructured())
+	if err != nil {
+		return nil, err
+	}
+	return &resources.Backup{Unstructured: *u}, nil
+}
+
+// GetBackup gets a Backup.
+func (c *Client) GetBackup(ctx context.Context, group, name string) (*resources.Backup, error) {
+	u, err := c.get(ctx, backups, group, name)
+	if err != nil {
+		return nil, err
+	}
+	return &resources.Backup{Unstructured: *u}, nil
+}
+
+// ListBackups lists the Backups of the supplied group, or of all groups if
+// the group is empty. If a control plane is supplied, only its backups are
+// listed.
+func (c *Client) ListBackups(ctx context.Context, group, controlPlane string) ([]*resources.Backup, error) {
+	items, err := c.list(ctx, backups, group)
+	if err != nil {
+		return nil, err
+	}
+	out := []*resources.Backup{}
+	for i := range items {
+		b := &resources.Backup{Unstructured: items[i]}
+		if controlPlane != "" && b.GetControlPlane() != controlPlane {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// DeleteBackup deletes a Backup.
+func (c *Client) DeleteBackup(ctx context.Context, group, name string) error {
+	return c.delete(ctx, backups, group, name)
+}
+
+// WaitForBackup waits for a Backup to complete. An error is returned if the
+// backup failed.
+func (c *Client) WaitForBackup(ctx context.Context, group, name string) (*resources.Backup, error) {
+	var b *resources.Backup
+	err := c.wait(ctx, func(ctx context.Context) (Status, error) {
+		var err error
+		b, err = c.GetBackup(ctx, group, name)
+		if err != nil {
+			return Status{}, err
+		}
+		return BackupStatus(b), nil
+	}, func(s Status) error {
+		return errors.Errorf(errFmtBackupFailed, name, s.Message)
+	})
+	return b, err
+}
+
+// wait polls for the status of a backup or restore until it is done.
+func (c *Client) wait(ctx context.Context, get func(context.Context) (Status, error), failed func(Status) error) error {
+	var s Status
+	err := wait.PollUntilContextTimeout(ctx, c.pollInterval, c.timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		s, err = get(ctx)
+		if err != nil {
+			return false, err
+		}
+		c.log.Debug("Waiting for completion", "phase", s.Phase)
+		return s.Phase.Done(), nil
+	})
+	if err != nil {
+		return err
+	}
+	if s.Phase == PhaseFailed {
+		return failed(s)
+	}
+	return nil
+}
+
+func (c *Client) create(ctx context.Context, gvr schema.GroupVersionResource, u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err := c.caps.Require(capability.FeatureBackups); err != nil {
+		return nil, err
+	}
+	c.log.Debug("Creating", "resource", gvr.Resource, "group", u.GetNamespace(), "name", u.GetName())
+	out, err := c.c.Resource(gvr).Namespace(u.GetNamespace()).Create(ctx, u, metav1.CreateOptions{})
+	return out, errors.Wrapf(err, errFmtCreate, gvr.Resource, u.GetName())
+}
+
+func (c *Client) get(ctx context.Context, gvr schema.GroupVersionResource, group, name string) (*unstructured.Unstructured, error) {
+	if err := c.caps.Require(capability.FeatureBackups); err != nil {
+		return nil, err
+	}
+	u, err := c.c.Resource(gvr).Namespace(group).Get(ctx, name, metav1.GetOptions{})
+	return u, errors.Wrapf(err, errFmtGet, gvr.Resource, name)
+}
+
+func (c *Client) update(ctx context.Context, gvr schema.GroupVersionResource, u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	out, err := c.c.Resource(gvr).Namespace(u.GetNamespace()).Update(ctx, u, metav1.UpdateOptions{})
+	return out, errors.Wrapf(err, errFmtUpdate, gvr.Resource, u.GetName())
+}
+
+func (c *Client) list(ctx context.Context, gvr schema.GroupVersionResource, group string) ([]unstructured.Unstructured, error) {
+	if err := c.caps.Require(capability.FeatureBackups); err != nil {
+		return nil, err
+	}
+	l, err := c.c.Resource(gvr).Namespace(group).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtList, gvr.Resource)
+	}
+	sort.Slice(l.Items, func(i, j int) bool {
+		if l.Items[i].GetNamespace() != l.Items[j].GetNamespace() {
+			return l.Items[i].GetNamespace() < l.Items[j].GetNamespace()
+		}
+		return l.Items[i].GetName() < l.Items[j].GetName()
+	})
+	return l.Items, nil
+}
+
+func (c *Client) delete(ctx context.Context, gvr schema.GroupVersionResource, group, name string) error {
+	if err := c.caps.Require(capability.FeatureBackups); err != nil {
+		return err
+	}
+	c.log.Debug("Deleting", "resource", gvr.Resource, "group", group, "name", name)
+	err := c.c.Resource(gvr).Namespace(group).Delete(ctx, name, metav1.DeleteOptions{})
+	return errors.Wrapf(err, errFmtDelete, gvr.Resource, name)
+}
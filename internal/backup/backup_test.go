@@ -0,0 +1,215 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/upbound/up/internal/capability"
+	"github.com/upbound/up/internal/resources"
+)
+
+func newFake(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		backups:   "BackupList",
+		configs:   "SharedBackupConfigList",
+		schedules: "BackupScheduleList",
+		restores:  "RestoreList",
+	}, objs...)
+}
+
+func backup(group, name, ctp, phase, msg string) *unstructured.Unstructured {
+	b := &resources.Backup{}
+	b.SetNamespace(group)
+	b.SetName(name)
+	b.SetControlPlane(ctp)
+	u := b.GetUnstructured()
+	if phase != "" {
+		u.Object["status"] = map[string]any{
+			"phase":      phase,
+			"conditions": []any{map[string]any{"type": "Ready", "status": "False", "message": msg}},
+		}
+	}
+	return u
+}
+
+func TestCreateBackup(t *testing.T) {
+	type args struct {
+		caps *capability.Capabilities
+		opts BackupOptions
+	}
+	type want struct {
+		spec map[string]any
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoControlPlane": {
+			reason: "A control plane is required.",
+			args: args{
+				opts: BackupOptions{Config: "default"},
+			},
+			want: want{
+				err: errors.New(errNoControlPlane),
+			},
+		},
+		"Unsupported": {
+			reason: "Creating a backup should fail early if the Space does not support backups.",
+			args: args{
+				caps: &capability.Capabilities{Target: capability.TargetSpace, Version: "v1.2.0"},
+				opts: BackupOptions{ControlPlane: "ctp1", Config: "default"},
+			},
+			want: want{
+				err: &capability.UnsupportedError{Feature: capability.FeatureBackups, Target: capability.TargetSpace, Required: "v1.3", Found: "v1.2.0"},
+			},
+		},
+		"Success": {
+			reason: "The backup should reference the control plane and config.",
+			args: args{
+				opts: BackupOptions{ControlPlane: "ctp1", Config: "default", TTL: "168h", DeletionPolicy: xpv1.DeletionDelete},
+			},
+			want: want{
+				spec: map[string]any{
+					"controlPlane":   "ctp1",
+					"configRef":      map[string]any{"kind": "SharedBackupConfig", "name": "default"},
+					"ttl":            "168h",
+					"deletionPolicy": "Delete",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(newFake(), WithCapabilities(tc.args.caps)).CreateBackup(context.Background(), "default", "b1", tc.args.opts)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreateBackup(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.spec, got.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nCreateBackup(...): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestListBackups(t *testing.T) {
+	c := New(newFake(backup("b", "b1", "ctp1", "", ""), backup("a", "b2", "ctp2", "", ""), backup("a", "b1", "ctp1", "", "")))
+
+	cases := map[string]struct {
+		reason string
+		group  string
+		ctp    string
+		want   []string
+	}{
+		"All": {
+			reason: "Backups of all groups should be listed, sorted by group and name.",
+			want:   []string{"a/b1", "a/b2", "b/b1"},
+		},
+		"ControlPlane": {
+			reason: "Only backups of the supplied control plane should be listed.",
+			group:  "a",
+			ctp:    "ctp1",
+			want:   []string{"a/b1"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l, err := c.ListBackups(context.Background(), tc.group, tc.ctp)
+			if err != nil {
+				t.Fatalf("\n%s\nListBackups(...): %v", tc.reason, err)
+			}
+			got := []string{}
+			for _, b := range l {
+				got = append(got, b.GetNamespace()+"/"+b.GetName())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nListBackups(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWaitForBackup(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		phases []string
+		want   error
+	}{
+		"Completed": {
+			reason: "Waiting should return once the backup completes.",
+			phases: []string{"", string(PhaseInProgress), string(PhaseCompleted)},
+		},
+		"Failed": {
+			reason: "Waiting should return an error if the backup fails.",
+			phases: []string{string(PhaseInProgress), string(PhaseFailed)},
+			want:   errors.Errorf(errFmtBackupFailed, "b1", "bucket not found"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := newFake()
+			calls := 0
+			dyn.PrependReactor("get", "backups", func(k8stesting.Action) (bool, runtime.Object, error) {
+				phase := tc.phases[calls]
+				if calls < len(tc.phases)-1 {
+					calls++
+				}
+				return true, backup("default", "b1", "ctp1", phase, "bucket not found"), nil
+			})
+			c := New(dyn, WithPollInterval(time.Millisecond), WithTimeout(5*time.Second))
+			_, err := c.WaitForBackup(context.Background(), "default", "b1")
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWaitForBackup(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	s := &resources.BackupSchedule{}
+	s.SetNamespace("default")
+	s.SetName("nightly")
+	s.SetSchedule("@daily")
+	c := New(newFake(s.GetUnstructured()))
+
+	got, err := c.UpdateSchedule(context.Background(), "default", "nightly", "@hourly", true)
+	if err != nil {
+		t.Fatalf("UpdateSchedule(...): %v", err)
+	}
+	if diff := cmp.Diff("@hourly", got.GetSchedule()); diff != "" {
+		t.Errorf("UpdateSchedule(...): -want schedule, +got schedule:\n%s", diff)
+	}
+	if !got.IsSuspended() {
+		t.Errorf("UpdateSchedule(...): want suspended schedule")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	errNoProvider = "an object storage provider is required"
+	errNoBucket   = "a bucket is required"
+)
+
+// ConfigOptions for creating a SharedBackupConfig.
+type ConfigOptions struct {
+	// Provider of object storage, e.g. AWS, Azure, or GCP.
+	Provider string
+	Bucket   string
+	// Credentials references a secret in the group containing object
+	// storage credentials.
+	Credentials *xpv1.SecretKeySelector
+}
+
+// CreateConfig creates a SharedBackupConfig in the supplied group.
+func (c *Client) CreateConfig(ctx context.Context, group, name string, opts ConfigOptions) (*resources.SharedBackupConfig, error) {
+	if opts.Provider == "" {
+		return nil, errors.New(errNoProvider)
+	}
+	if opts.Bucket == "" {
+		return nil, errors.New(errNoBucket)
+	}
+	s := &resources.SharedBackupConfig{}
+	s.SetNamespace(group)
+	s.SetName(name)
+	s.SetProvider(opts.Provider)
+	s.SetBucket(opts.Bucket)
+	if opts.Credentials != nil {
+		s.SetCredentialsSecretReference(opts.Credentials)
+	}
+	u, err := c.create(ctx, configs, s.GetUnstructured())
+	if err != nil {
+		return nil, err
+	}
+	return &resources.SharedBackupConfig{Unstructured: *u}, nil
+}
+
+// GetConfig gets a SharedBackupConfig.
+func (c *Client) GetConfig(ctx context.Context, group, name string) (*resources.SharedBackupConfig, error) {
+	u, err := c.get(ctx, configs, group, name)
+	if err != nil {
+		return nil, err
+	}
+	return &resources.SharedBackupConfig{Unstructured: *u}, nil
+}
+
+// ListConfigs lists the SharedBackupConfigs of the supplied group, or of all
+// groups if the group is empty.
+func (c *Client) ListConfigs(ctx context.Context, group string) ([]*resources.SharedBackupConfig, error) {
+	items, err := c.list(ctx, configs, group)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*resources.SharedBackupConfig, len(items))
+	for i := range items {
+		out[i] = &resources.SharedBackupConfig{Unstructured: items[i]}
+	}
+	return out, nil
+}
+
+// DeleteConfig deletes a SharedBackupConfig.
+func (c *Client) DeleteConfig(ctx context.Context, group, name string) error {
+	return c.delete(ctx, configs, group, name)
+}
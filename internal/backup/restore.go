@@ -0,0 +1,97 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const errNoBackup = "a backup is required"
+
+// RestoreOptions for creating a Restore.
+type RestoreOptions struct {
+	ControlPlane string
+	// Backup is the name of the Backup to restore from.
+	Backup string
+}
+
+// CreateRestore creates a Restore of a control plane in the supplied group.
+func (c *Client) CreateRestore(ctx context.Context, group, name string, opts RestoreOptions) (*resources.Restore, error) {
+	if opts.ControlPlane == "" {
+		return nil, errors.New(errNoControlPlane)
+	}
+	if opts.Backup == "" {
+		return nil, errors.New(errNoBackup)
+	}
+	r := &resources.Restore{}
+	r.SetNamespace(group)
+	r.SetName(name)
+	r.SetControlPlane(opts.ControlPlane)
+	r.SetSource(resources.RestoreSource{Kind: resources.BackupGVK.Kind, Name: opts.Backup})
+	u, err := c.create(ctx, restores, r.GetUnstructured())
+	if err != nil {
+		return nil, err
+	}
+	return &resources.Restore{Unstructured: *u}, nil
+}
+
+// GetRestore gets a Restore.
+func (c *Client) GetRestore(ctx context.Context, group, name string) (*resources.Restore, error) {
+	u, err := c.get(ctx, restores, group, name)
+	if err != nil {
+		return nil, err
+	}
+	return &resources.Restore{Unstructured: *u}, nil
+}
+
+// ListRestores lists the Restores of the supplied group, or of all groups if
+// the group is empty.
+func (c *Client) ListRestores(ctx context.Context, group string) ([]*resources.Restore, error) {
+	items, err := c.list(ctx, restores, group)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*resources.Restore, len(items))
+	for i := range items {
+		out[i] = &resources.Restore{Unstructured: items[i]}
+	}
+	return out, nil
+}
+
+// DeleteRestore deletes a Restore. The restored control plane is kept.
+func (c *Client) DeleteRestore(ctx context.Context, group, name string) error {
+	return c.delete(ctx, restores, group, name)
+}
+
+// WaitForRestore waits for a Restore to complete. An error is returned if
+// the restore failed.
+func (c *Client) WaitForRestore(ctx context.Context, group, name string) (*resources.Restore, error) {
+	var r *resources.Restore
+	err := c.wait(ctx, func(ctx context.Context) (Status, error) {
+		var err error
+		r, err = c.GetRestore(ctx, group, name)
+		if err != nil {
+			return Status{}, err
+		}
+		return RestoreStatus(r), nil
+	}, func(s Status) error {
+		return errors.Errorf(errFmtRestoreFailed, name, s.Message)
+	})
+	return r, err
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const errNoSchedule = "a schedule is required"
+
+// ScheduleOptions for creating a BackupSchedule.
+type ScheduleOptions struct {
+	ControlPlane string
+	// Schedule is a cron schedule, e.g. "0 2 * * *" or "@daily".
+	Schedule string
+	// Config is the name of the SharedBackupConfig to use.
+	Config string
+	// TTL is how long each backup is retained, e.g. 168h.
+	TTL string
+	// Suspended creates the schedule without taking backups.
+	Suspended bool
+}
+
+// CreateSchedule creates a BackupSchedule for a control plane in the
+// supplied group.
+func (c *Client) CreateSchedule(ctx context.Context, group, name string, opts ScheduleOptions) (*resources.BackupSchedule, error) {
+	switch {
+	case opts.ControlPlane == "":
+		return nil, errors.New(errNoControlPlane)
+	case opts.Schedule == "":
+		return nil, errors.New(errNoSchedule)
+	case opts.Config == "":
+		return nil, errors.New(errNoConfig)
+	}
+	s := &resources.BackupSchedule{}
+	s.SetNamespace(group)
+	s.SetName(name)
+	s.SetControlPlane(opts.ControlPlane)
+	s.SetSchedule(opts.Schedule)
+	s.SetConfigReference(resources.BackupConfigReference{Kind: resources.SharedBackupConfigGVK.Kind, Name: opts.Config})
+	if opts.TTL != "" {
+		s.SetTTL(opts.TTL)
+	}
+	if opts.Suspended {
+		s.SetSuspended(true)
+	}
+	u, err := c.create(ctx, schedules, s.GetUnstructured())
+	if err != nil {
+		return nil, err
+	}
+	return &resources.BackupSchedule{Unstructured: *u}, nil
+}
+
+// GetSchedule gets a BackupSchedule.
+func (c *Client) GetSchedule(ctx context.Context, group, name string) (*resources.BackupSchedule, error) {
+	u, err := c.get(ctx, schedules, group, name)
+	if err != nil {
+		return nil, err
+	}
+	return &resources.BackupSchedule{Unstructured: *u}, nil
+}
+
+// ListSchedules lists the BackupSchedules of the supplied group, or of all
+// groups if the group is empty.
+func (c *Client) ListSchedules(ctx context.Context, group string) ([]*resources.BackupSchedule, error) {
+	items, err := c.list(ctx, schedules, group)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*resources.BackupSchedule, len(items))
+	for i := range items {
+		out[i] = &resources.BackupSchedule{Unstructured: items[i]}
+	}
+	return out, nil
+}
+
+// UpdateSchedule updates the cron schedule of a BackupSchedule and whether
+// it is suspended.
+func (c *Client) UpdateSchedule(ctx context.Context, group, name, schedule string, suspended bool) (*resources.BackupSchedule, error) {
+	s, err := c.GetSchedule(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+	if schedule != "" {
+		s.SetSchedule(schedule)
+	}
+	s.SetSuspended(suspended)
+	u, err := c.update(ctx, schedules, s.GetUnstructured())
+	if err != nil {
+		return nil, err
+	}
+	return &resources.BackupSchedule{Unstructured: *u}, nil
+}
+
+// DeleteSchedule deletes a BackupSchedule. Backups already taken are kept.
+func (c *Client) DeleteSchedule(ctx context.Context, group, name string) error {
+	return c.delete(ctx, schedules, group, name)
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capability
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// DefaultTTL is how long discovered capabilities are cached.
+	DefaultTTL = 1 * time.Hour
+
+	errWriteCache = "cannot write capabilities cache"
+)
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// A Cache caches capabilities per profile on disk so that negotiation does
+// not cost a round trip for every command.
+type Cache struct {
+	fs  afero.Fs
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// CacheOption modifies a Cache.
+type CacheOption func(*Cache)
+
+// WithFS sets the filesystem used by the Cache.
+func WithFS(fs afero.Fs) CacheOption {
+	return func(c *Cache) {
+		c.fs = fs
+	}
+}
+
+// WithDir sets the directory capabilities are cached in.
+func WithDir(dir string) CacheOption {
+	return func(c *Cache) {
+		c.dir = dir
+	}
+}
+
+// WithTTL sets how long cached capabilities are considered fresh.
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// NewCache constructs a new Cache.
+func NewCache(opts ...CacheOption) *Cache {
+	c := &Cache{
+		fs:  afero.NewOsFs(),
+		ttl: DefaultTTL,
+		now: time.Now,
+	}
+	if h, err := os.UserHomeDir(); err == nil {
+		c.dir = filepath.Join(h, ".up", "cache", "capabilities")
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Get returns the cached capabilities of the supplied profile, using the
+// Discoverer if none are cached or they have expired. Failing to read or
+// write the cache is not an error; the cache is only an optimization.
+func (c *Cache) Get(ctx context.Context, profile string, d Discoverer) (*Capabilities, error) {
+	if caps := c.read(profile); caps != nil && c.now().Sub(caps.DiscoveredAt) < c.ttl {
+		return caps, nil
+	}
+	caps, err := d.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.write(profile, caps)
+	return caps, nil
+}
+
+// Invalidate drops the cached capabilities of the supplied profile, e.g.
+// after the Space was upgraded.
+func (c *Cache) Invalidate(profile string) error {
+	err := c.fs.Remove(c.path(profile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *Cache) path(profile string) string {
+	return filepath.Join(c.dir, unsafeChars.ReplaceAllString(profile, "_")+".json")
+}
+
+func (c *Cache) read(profile string) *Capabilities {
+	b, err := afero.ReadFile(c.fs, c.path(profile))
+	if err != nil {
+		return nil
+	}
+	caps := &Capabilities{}
+	if err := json.Unmarshal(b, caps); err != nil {
+		return nil
+	}
+	return caps
+}
+
+func (c *Cache) write(profile string, caps *Capabilities) error {
+	b, err := json.Marshal(caps)
+	if err != nil {
+		return errors.Wrap(err, errWriteCache)
+	}
+	if err := c.fs.MkdirAll(c.dir, 0o755); err != nil {
+		return errors.Wrap(err, errWriteCache)
+	}
+	return errors.Wrap(afero.WriteFile(c.fs, c.path(profile), b, 0o600), errWriteCache)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capability
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type countingDiscoverer struct {
+	caps  *Capabilities
+	err   error
+	calls int
+}
+
+func (d *countingDiscoverer) Discover(_ context.Context) (*Capabilities, error) {
+	d.calls++
+	return d.caps, d.err
+}
+
+func TestCacheGet(t *testing.T) {
+	errBoom := errors.New("boom")
+	fresh := &Capabilities{Target: TargetSpace, Version: "v1.3.0", DiscoveredAt: now}
+	stale := &Capabilities{Target: TargetSpace, Version: "v1.2.0", DiscoveredAt: now.Add(-2 * DefaultTTL)}
+
+	type want struct {
+		caps  *Capabilities
+		calls int
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		cached *Capabilities
+		d      *countingDiscoverer
+		want   want
+	}{
+		"Miss": {
+			reason: "Capabilities should be discovered when none are cached.",
+			d:      &countingDiscoverer{caps: fresh},
+			want:   want{caps: fresh, calls: 1},
+		},
+		"Hit": {
+			reason: "Fresh cached capabilities should be returned without discovery.",
+			cached: fresh,
+			d:      &countingDiscoverer{err: errBoom},
+			want:   want{caps: fresh},
+		},
+		"Expired": {
+			reason: "Expired cached capabilities should be rediscovered.",
+			cached: stale,
+			d:      &countingDiscoverer{caps: fresh},
+			want:   want{caps: fresh, calls: 1},
+		},
+		"DiscoverError": {
+			reason: "Errors discovering capabilities should be returned.",
+			d:      &countingDiscoverer{err: errBoom},
+			want:   want{err: errBoom, calls: 1},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewCache(WithFS(afero.NewMemMapFs()), WithDir("/cache"))
+			c.now = func() time.Time { return now }
+			if tc.cached != nil {
+				if err := c.write("my/space", tc.cached); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := c.Get(context.Background(), "my/space", tc.d)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.caps, got); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, tc.d.calls); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want discoveries, +got discoveries:\n%s", tc.reason, diff)
+			}
+			if tc.want.err == nil {
+				if diff := cmp.Diff(tc.want.caps, c.read("my/space")); diff != "" {
+					t.Errorf("\n%s\nGet(...): -want cached, +got cached:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
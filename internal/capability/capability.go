@@ -0,0 +1,265 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package capability negotiates which APIs and features a Space or Upbound
+// Cloud supports, so that clients can adapt their requests or fail early with
+// a clear error.
+package capability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// TargetSpace identifies capabilities of a Space.
+	TargetSpace = "space"
+	// TargetCloud identifies capabilities of Upbound Cloud.
+	TargetCloud = "cloud"
+
+	// DefaultNamespace is the namespace the Spaces controller runs in.
+	DefaultNamespace = "upbound-system"
+
+	controllerName = "spaces-controller"
+	labelVersion   = "app.kubernetes.io/version"
+
+	errDiscoverAPIs = "cannot discover APIs served by Space"
+	errGetVersion   = "cannot determine Space version"
+)
+
+// A Feature is a capability a client may depend on.
+type Feature string
+
+// Features.
+const (
+	FeatureControlPlanes Feature = "controlplanes"
+	FeatureGroups        Feature = "groups"
+	FeatureBackups       Feature = "backups"
+	FeatureQuery         Feature = "query"
+	FeatureUsageUpload   Feature = "usage-upload"
+)
+
+// MinVersions is the first Space release that supports each feature. It is
+// only used to tell users which version to upgrade to.
+var MinVersions = map[Feature]string{
+	FeatureControlPlanes: "v1.0",
+	FeatureGroups:        "v1.2",
+	FeatureBackups:       "v1.3",
+	FeatureQuery:         "v1.2",
+	FeatureUsageUpload:   "v1.0",
+}
+
+// featureAPIs maps features to the API group versions that serve them in a
+// Space. A feature is supported if any of its group versions is served.
+var featureAPIs = map[Feature][]schema.GroupVersion{
+	FeatureControlPlanes: {{Group: "spaces.upbound.io", Version: "v1beta1"}, {Group: "spaces.upbound.io", Version: "v1alpha1"}},
+	FeatureBackups:       {{Group: "spaces.upbound.io", Version: "v1alpha1"}},
+	FeatureQuery:         {{Group: "query.spaces.upbound.io", Version: "v1alpha1"}},
+}
+
+// Capabilities advertised by a Space or Upbound Cloud.
+type Capabilities struct {
+	Target string `json:"target"`
+	// Version of the Space, if known.
+	Version string `json:"version,omitempty"`
+	// APIs lists the served API group versions, e.g. spaces.upbound.io/v1beta1.
+	APIs         []string         `json:"apis,omitempty"`
+	Features     map[Feature]bool `json:"features"`
+	DiscoveredAt time.Time        `json:"discoveredAt"`
+}
+
+// Serves returns true if the supplied group version is served. A nil
+// *Capabilities serves everything, so that clients behave as before when no
+// negotiation took place.
+func (c *Capabilities) Serves(gv schema.GroupVersion) bool {
+	if c == nil {
+		return true
+	}
+	for _, a := range c.APIs {
+		if a == gv.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// Has returns true if the supplied feature is supported. A nil
+// *Capabilities supports every feature.
+func (c *Capabilities) Has(f Feature) bool {
+	if c == nil {
+		return true
+	}
+	return c.Features[f]
+}
+
+// Require returns an *UnsupportedError if the supplied feature is not
+// supported.
+func (c *Capabilities) Require(f Feature) error {
+	if c.Has(f) {
+		return nil
+	}
+	return &UnsupportedError{Feature: f, Target: c.Target, Required: MinVersions[f], Found: c.Version}
+}
+
+// An UnsupportedError is returned when a feature is not supported by the
+// target Space or Upbound Cloud.
+type UnsupportedError struct {
+	Feature  Feature
+	Target   string
+	Required string
+	Found    string
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Target == TargetCloud {
+		return fmt.Sprintf("%s is not supported by Upbound Cloud", e.Feature)
+	}
+	msg := fmt.Sprintf("Space %s required for %s", e.Required, e.Feature)
+	if e.Required == "" {
+		msg = fmt.Sprintf("%s is not supported by this Space", e.Feature)
+	}
+	if e.Found != "" {
+		msg = fmt.Sprintf("%s, found %s", msg, e.Found)
+	}
+	return msg
+}
+
+// IsUnsupported returns true if the supplied error indicates a feature is not
+// supported.
+func IsUnsupported(err error) bool {
+	var e *UnsupportedError
+	return errors.As(err, &e)
+}
+
+// A Discoverer discovers capabilities.
+type Discoverer interface {
+	Discover(ctx context.Context) (*Capabilities, error)
+}
+
+// Space discovers the capabilities of a Space from the APIs it serves and
+// the version of its controller.
+type Space struct {
+	disc      discovery.DiscoveryInterface
+	kube      kubernetes.Interface
+	namespace string
+	now       func() time.Time
+}
+
+// NewSpace returns a Discoverer for a Space.
+func NewSpace(disc discovery.DiscoveryInterface, kube kubernetes.Interface) *Space {
+	return &Space{disc: disc, kube: kube, namespace: DefaultNamespace, now: time.Now}
+}
+
+// Discover the capabilities of the Space.
+func (s *Space) Discover(ctx context.Context) (*Capabilities, error) {
+	groups, err := s.disc.ServerGroups()
+	if err != nil {
+		return nil, errors.Wrap(err, errDiscoverAPIs)
+	}
+	c := &Capabilities{
+		Target:       TargetSpace,
+		Features:     map[Feature]bool{},
+		DiscoveredAt: s.now().UTC(),
+	}
+	for _, g := range groups.Groups {
+		if !strings.HasSuffix(g.Name, "upbound.io") {
+			continue
+		}
+		for _, v := range g.Versions {
+			c.APIs = append(c.APIs, v.GroupVersion)
+		}
+	}
+	sort.Strings(c.APIs)
+	for f, gvs := range featureAPIs {
+		for _, gv := range gvs {
+			if c.Serves(gv) {
+				c.Features[f] = true
+			}
+		}
+	}
+	// Groups are namespaces, so they are supported wherever control planes
+	// are namespaced, i.e. from v1beta1.
+	c.Features[FeatureGroups] = c.Serves(schema.GroupVersion{Group: "spaces.upbound.io", Version: "v1beta1"})
+	// Usage is uploaded by the Space itself.
+	c.Features[FeatureUsageUpload] = true
+
+	v, err := s.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.Version = v
+	return c, nil
+}
+
+// version returns the version of the Spaces controller, or an empty string
+// if it cannot be read, e.g. for lack of permission.
+func (s *Space) version(ctx context.Context) (string, error) {
+	d, err := s.kube.AppsV1().Deployments(s.namespace).Get(ctx, controllerName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) || kerrors.IsForbidden(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errGetVersion)
+	}
+	if v := d.GetLabels()[labelVersion]; v != "" {
+		return normalize(v), nil
+	}
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if i := strings.LastIndex(c.Image, ":"); i > 0 && !strings.Contains(c.Image[i:], "/") {
+			return normalize(c.Image[i+1:]), nil
+		}
+	}
+	return "", nil
+}
+
+func normalize(v string) string {
+	if strings.HasPrefix(v, "v") {
+		return v
+	}
+	return "v" + v
+}
+
+// Cloud discovers the capabilities of Upbound Cloud, which always runs the
+// latest release.
+type Cloud struct {
+	now func() time.Time
+}
+
+// NewCloud returns a Discoverer for Upbound Cloud.
+func NewCloud() *Cloud {
+	return &Cloud{now: time.Now}
+}
+
+// Discover the capabilities of Upbound Cloud.
+func (c *Cloud) Discover(_ context.Context) (*Capabilities, error) {
+	return &Capabilities{
+		Target: TargetCloud,
+		Features: map[Feature]bool{
+			FeatureControlPlanes: true,
+			FeatureUsageUpload:   true,
+		},
+		DiscoveredAt: c.now().UTC(),
+	}, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capability
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func resources(gvs ...string) []*metav1.APIResourceList {
+	l := make([]*metav1.APIResourceList, len(gvs))
+	for i, gv := range gvs {
+		l[i] = &metav1.APIResourceList{GroupVersion: gv}
+	}
+	return l
+}
+
+func controller(labels map[string]string, image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: controllerName, Namespace: DefaultNamespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "controller", Image: image}}},
+			},
+		},
+	}
+}
+
+func TestSpaceDiscover(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		resources []*metav1.APIResourceList
+		objs      []runtime.Object
+		want      *Capabilities
+	}{
+		"Current": {
+			reason:    "A Space serving v1beta1 and the query API should support every feature, with the version read from the controller label.",
+			resources: resources("v1", "spaces.upbound.io/v1beta1", "spaces.upbound.io/v1alpha1", "query.spaces.upbound.io/v1alpha1"),
+			objs:      []runtime.Object{controller(map[string]string{labelVersion: "1.3.0"}, "xpkg.upbound.io/spaces-controller:v1.3.0")},
+			want: &Capabilities{
+				Target:  TargetSpace,
+				Version: "v1.3.0",
+				APIs:    []string{"query.spaces.upbound.io/v1alpha1", "spaces.upbound.io/v1alpha1", "spaces.upbound.io/v1beta1"},
+				Features: map[Feature]bool{
+					FeatureControlPlanes: true,
+					FeatureGroups:        true,
+					FeatureBackups:       true,
+					FeatureQuery:         true,
+					FeatureUsageUpload:   true,
+				},
+				DiscoveredAt: now,
+			},
+		},
+		"Legacy": {
+			reason:    "A Space serving only v1alpha1 should not support groups or queries, with the version read from the controller image.",
+			resources: resources("spaces.upbound.io/v1alpha1"),
+			objs:      []runtime.Object{controller(nil, "xpkg.upbound.io/spaces-controller:1.1.2")},
+			want: &Capabilities{
+				Target:  TargetSpace,
+				Version: "v1.1.2",
+				APIs:    []string{"spaces.upbound.io/v1alpha1"},
+				Features: map[Feature]bool{
+					FeatureControlPlanes: true,
+					FeatureGroups:        false,
+					FeatureBackups:       true,
+					FeatureUsageUpload:   true,
+				},
+				DiscoveredAt: now,
+			},
+		},
+		"UnknownVersion": {
+			reason:    "A missing controller should leave the version unknown rather than fail.",
+			resources: resources("spaces.upbound.io/v1beta1"),
+			want: &Capabilities{
+				Target: TargetSpace,
+				APIs:   []string{"spaces.upbound.io/v1beta1"},
+				Features: map[Feature]bool{
+					FeatureControlPlanes: true,
+					FeatureGroups:        true,
+					FeatureUsageUpload:   true,
+				},
+				DiscoveredAt: now,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := kfake.NewSimpleClientset(tc.objs...)
+			disc := &fakediscovery.FakeDiscovery{Fake: &kube.Fake}
+			kube.Resources = tc.resources
+			s := NewSpace(disc, kube)
+			s.now = func() time.Time { return now }
+
+			got, err := s.Discover(context.Background())
+			if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDiscover(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDiscover(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		caps    *Capabilities
+		feature Feature
+		want    string
+	}{
+		"Unknown": {
+			reason:  "Without negotiated capabilities every feature should be assumed supported.",
+			feature: FeatureQuery,
+		},
+		"Supported": {
+			reason:  "A supported feature should not return an error.",
+			caps:    &Capabilities{Target: TargetSpace, Features: map[Feature]bool{FeatureQuery: true}},
+			feature: FeatureQuery,
+		},
+		"OldSpace": {
+			reason:  "An unsupported feature should name the required and found Space versions.",
+			caps:    &Capabilities{Target: TargetSpace, Version: "v1.1.0"},
+			feature: FeatureQuery,
+			want:    "Space v1.2 required for query, found v1.1.0",
+		},
+		"UnknownSpaceVersion": {
+			reason:  "An unknown Space version should be omitted from the error.",
+			caps:    &Capabilities{Target: TargetSpace},
+			feature: FeatureBackups,
+			want:    "Space v1.3 required for backups",
+		},
+		"Cloud": {
+			reason:  "Features missing from Upbound Cloud should not mention Space versions.",
+			caps:    &Capabilities{Target: TargetCloud},
+			feature: FeatureQuery,
+			want:    "query is not supported by Upbound Cloud",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.caps.Require(tc.feature)
+			got := ""
+			if err != nil {
+				got = err.Error()
+				if !IsUnsupported(errors.Wrap(err, "wrapped")) {
+					t.Errorf("\n%s\nIsUnsupported(...): want true for %q", tc.reason, got)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRequire(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	// DefaultProbeTimeout is how long probing a kubeconfig context for a
+	// Space may take by default.
+	DefaultProbeTimeout = 5 * time.Second
+
+	errLoadKubeconfig = "cannot load kubeconfig"
+)
+
+// A Candidate is a kubeconfig context whose cluster serves a Space.
+type Candidate struct {
+	Context string
+	Server  string
+	// Version of the Space, if known.
+	Version string
+	// Current is true for the current context of the kubeconfig.
+	Current bool
+}
+
+func (c Candidate) String() string {
+	if c.Version == "" {
+		return c.Context
+	}
+	return fmt.Sprintf("%s (%s)", c.Context, c.Version)
+}
+
+// A Prober returns the capabilities of the cluster at the supplied REST
+// config.
+type Prober func(ctx context.Context, cfg *rest.Config) (*Capabilities, error)
+
+// ProbeSpace returns the capabilities of the Space served at the supplied
+// REST config.
+func ProbeSpace(ctx context.Context, cfg *rest.Config) (*Capabilities, error) {
+	disc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	kube, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpace(disc, kube).Discover(ctx)
+}
+
+// A Finder finds Spaces among the contexts of a kubeconfig.
+type Finder struct {
+	probe   Prober
+	timeout time.Duration
+	log     logging.Logger
+}
+
+// FinderOption modifies a Finder.
+type FinderOption func(*Finder)
+
+// WithProber sets how contexts are probed for a Space.
+func WithProber(p Prober) FinderOption {
+	return func(f *Finder) {
+		f.probe = p
+	}
+}
+
+// WithProbeTimeout sets how long probing a single context may take.
+func WithProbeTimeout(d time.Duration) FinderOption {
+	return func(f *Finder) {
+		f.timeout = d
+	}
+}
+
+// WithLogger sets the logger of the Finder.
+func WithLogger(l logging.Logger) FinderOption {
+	return func(f *Finder) {
+		f.log = l
+	}
+}
+
+// NewFinder constructs a new Finder.
+func NewFinder(opts ...FinderOption) *Finder {
+	f := &Finder{
+		probe:   ProbeSpace,
+		timeout: DefaultProbeTimeout,
+		log:     logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	return f
+}
+
+// FindInFile finds Spaces among the contexts of the kubeconfig at the
+// supplied path, or of the default kubeconfig if the path is empty.
+func (f *Finder) FindInFile(ctx context.Context, path string) ([]Candidate, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = path
+	conf, err := rules.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, errLoadKubeconfig)
+	}
+	return f.Find(ctx, conf), nil
+}
+
+// Find probes every context of the supplied kubeconfig concurrently and
+// returns those that serve a Space, current context first. Contexts that
+// cannot be reached or do not serve a Space are skipped.
+func (f *Finder) Find(ctx context.Context, conf *api.Config) []Candidate {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		found []Candidate
+	)
+	for name := range conf.Contexts {
+		name := name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, ok := f.probeContext(ctx, conf, name)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			found = append(found, c)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Current != found[j].Current {
+			return found[i].Current
+		}
+		return found[i].Context < found[j].Context
+	})
+	return found
+}
+
+func (f *Finder) probeContext(ctx context.Context, conf *api.Config, name string) (Candidate, bool) {
+	cfg, err := clientcmd.NewNonInteractiveClientConfig(*conf, name, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		f.log.Debug("Cannot build client for kubeconfig context", "context", name, "error", err)
+		return Candidate{}, false
+	}
+	cfg.Timeout = f.timeout
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	caps, err := f.probe(ctx, cfg)
+	if err != nil {
+		f.log.Debug("Cannot probe kubeconfig context for a Space", "context", name, "error", err)
+		return Candidate{}, false
+	}
+	if !caps.Has(FeatureControlPlanes) {
+		return Candidate{}, false
+	}
+	return Candidate{
+		Context: name,
+		Server:  cfg.Host,
+		Version: caps.Version,
+		Current: name == conf.CurrentContext,
+	}, true
+}
+
+// A NoSpaceError is returned when a kubeconfig context does not serve a
+// Space, listing the contexts that do.
+type NoSpaceError struct {
+	Context    string
+	Candidates []Candidate
+}
+
+func (e *NoSpaceError) Error() string {
+	msg := fmt.Sprintf("kubeconfig context %q does not serve a Space", e.Context)
+	if len(e.Candidates) == 0 {
+		return msg
+	}
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = c.String()
+	}
+	return fmt.Sprintf("%s. Did you mean %s?", msg, strings.Join(names, ", "))
+}
+
+// Kind returns the kind of failure a NoSpaceError is.
+func (e *NoSpaceError) Kind() failure.Kind {
+	return failure.KindUsage
+}
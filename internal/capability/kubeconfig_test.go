@@ -0,0 +1,118 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+func kubeconfig(current string, servers map[string]string) *api.Config {
+	conf := api.NewConfig()
+	for name, server := range servers {
+		conf.Clusters[name] = &api.Cluster{Server: server}
+		conf.AuthInfos[name] = &api.AuthInfo{}
+		conf.Contexts[name] = &api.Context{Cluster: name, AuthInfo: name}
+	}
+	conf.CurrentContext = current
+	return conf
+}
+
+func TestFind(t *testing.T) {
+	// Servers are probed by host, standing in for clusters.
+	probe := func(_ context.Context, cfg *rest.Config) (*Capabilities, error) {
+		switch cfg.Host {
+		case "https://space-a":
+			return &Capabilities{Target: TargetSpace, Version: "v1.3.0", Features: map[Feature]bool{FeatureControlPlanes: true}}, nil
+		case "https://space-b":
+			return &Capabilities{Target: TargetSpace, Features: map[Feature]bool{FeatureControlPlanes: true}}, nil
+		case "https://kind":
+			return &Capabilities{Target: TargetSpace, Features: map[Feature]bool{}}, nil
+		default:
+			return nil, errors.New("connection refused")
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		conf   *api.Config
+		want   []Candidate
+	}{
+		"NoContexts": {
+			reason: "An empty kubeconfig should have no candidates.",
+			conf:   api.NewConfig(),
+		},
+		"NoSpaces": {
+			reason: "Clusters that do not serve a Space or cannot be reached should be skipped.",
+			conf:   kubeconfig("kind", map[string]string{"kind": "https://kind", "down": "https://down"}),
+		},
+		"Spaces": {
+			reason: "Contexts serving a Space should be returned, current context first.",
+			conf: kubeconfig("b", map[string]string{
+				"a":    "https://space-a",
+				"b":    "https://space-b",
+				"kind": "https://kind",
+				"down": "https://down",
+			}),
+			want: []Candidate{
+				{Context: "b", Server: "https://space-b", Current: true},
+				{Context: "a", Server: "https://space-a", Version: "v1.3.0"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NewFinder(WithProber(probe)).Find(context.Background(), tc.conf)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nFind(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNoSpaceError(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    *NoSpaceError
+		want   string
+	}{
+		"NoCandidates": {
+			reason: "Without candidates only the context should be reported.",
+			err:    &NoSpaceError{Context: "kind"},
+			want:   `kubeconfig context "kind" does not serve a Space`,
+		},
+		"Candidates": {
+			reason: "Candidates should be suggested along with their versions.",
+			err: &NoSpaceError{Context: "kind", Candidates: []Candidate{
+				{Context: "a", Version: "v1.3.0"},
+				{Context: "b"},
+			}},
+			want: `kubeconfig context "kind" does not serve a Space. Did you mean a (v1.3.0), b?`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.err.Error()); diff != "" {
+				t.Errorf("\n%s\nError(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
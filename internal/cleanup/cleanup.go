@@ -0,0 +1,88 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cleanup registers functions that undo partially completed work when
+// a command is interrupted or times out.
+package cleanup
+
+import (
+	"context"
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const errFmtCleanup = "cannot clean up %s"
+
+// A Func undoes partially completed work. It is called with a fresh context,
+// since the context of the interrupted command is already done.
+type Func func(ctx context.Context) error
+
+type hook struct {
+	id   int
+	name string
+	fn   Func
+}
+
+// Hooks is a set of cleanup functions. The zero value is ready to use.
+type Hooks struct {
+	mu    sync.Mutex
+	next  int
+	hooks []hook
+}
+
+// Add registers a cleanup function described by name. The returned function
+// removes it again, and should be called once the work it would undo has
+// completed successfully.
+func (h *Hooks) Add(name string, fn Func) (remove func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.next
+	h.next++
+	h.hooks = append(h.hooks, hook{id: id, name: name, fn: fn})
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for i, hk := range h.hooks {
+			if hk.id == id {
+				h.hooks = append(h.hooks[:i], h.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of registered cleanup functions.
+func (h *Hooks) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.hooks)
+}
+
+// Run calls every registered cleanup function, most recently added first,
+// and removes them. All functions are called even if some fail.
+func (h *Hooks) Run(ctx context.Context) error {
+	h.mu.Lock()
+	hooks := h.hooks
+	h.hooks = nil
+	h.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, errors.Wrapf(err, errFmtCleanup, hooks[i].name))
+		}
+	}
+	return errors.Join(errs...)
+}
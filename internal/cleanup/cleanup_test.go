@@ -0,0 +1,100 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cleanup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestHooksRun(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		calls []string
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		add    []string
+		remove []string
+		fail   map[string]bool
+		want   want
+	}{
+		"Empty": {
+			reason: "Running no hooks should succeed.",
+			want:   want{calls: []string{}},
+		},
+		"ReverseOrder": {
+			reason: "Hooks should run most recently added first.",
+			add:    []string{"a", "b", "c"},
+			want:   want{calls: []string{"c", "b", "a"}},
+		},
+		"Removed": {
+			reason: "Removed hooks should not run.",
+			add:    []string{"a", "b", "c"},
+			remove: []string{"b"},
+			want:   want{calls: []string{"c", "a"}},
+		},
+		"Errors": {
+			reason: "Every hook should run even if some fail, and their errors should be returned.",
+			add:    []string{"a", "b", "c"},
+			fail:   map[string]bool{"a": true, "c": true},
+			want: want{
+				calls: []string{"c", "b", "a"},
+				err: errors.Join(
+					errors.Wrapf(errBoom, errFmtCleanup, "c"),
+					errors.Wrapf(errBoom, errFmtCleanup, "a"),
+				),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := &Hooks{}
+			calls := []string{}
+			removers := map[string]func(){}
+			for _, n := range tc.add {
+				n := n
+				removers[n] = h.Add(n, func(context.Context) error {
+					calls = append(calls, n)
+					if tc.fail[n] {
+						return errBoom
+					}
+					return nil
+				})
+			}
+			for _, n := range tc.remove {
+				removers[n]()
+			}
+
+			err := h.Run(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(0, h.Len()); diff != "" {
+				t.Errorf("\n%s\nLen(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -50,11 +50,19 @@ const (
 	Default Format = "default"
 	JSON    Format = "json"
 	YAML    Format = "yaml"
+	Wide    Format = "wide"
 )
 
 // Config is format for the up configuration file.
 type Config struct {
-	Upbound Upbound `json:"upbound"`
+	Upbound   Upbound   `json:"upbound"`
+	Telemetry Telemetry `json:"telemetry,omitempty"`
+}
+
+// Telemetry contains configuration for anonymized usage telemetry.
+type Telemetry struct {
+	// Disabled opts out of telemetry.
+	Disabled bool `json:"disabled,omitempty"`
 }
 
 // Extract performs extraction of configuration from the provided source.
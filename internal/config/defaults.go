@@ -0,0 +1,312 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultsFile is the name of the file in ConfigDir that sets default
+	// values of flags.
+	DefaultsFile = "defaults.yaml"
+	// EnvDefaultsFile overrides the path of the defaults file.
+	EnvDefaultsFile = "UP_DEFAULTS_FILE"
+	// DefaultsVersion is the current defaults file format version.
+	DefaultsVersion = "v1"
+
+	errReadDefaults      = "cannot read defaults file"
+	errParseDefaults     = "cannot parse defaults file"
+	errWriteDefaults     = "cannot write defaults file"
+	errFmtDefaultsVer    = "unsupported defaults file version %q"
+	errFmtInvalidFormat  = "%s: invalid format %q"
+	errFmtInvalidTimeout = "%s: invalid %s %q"
+	errFmtUnknownCommand = "defaults for unknown command %q"
+	errFmtUnknownFlag    = "%s: unknown flag %q"
+)
+
+// Defaults are default values of flags, read from a file. Values are layered:
+// the defaults file is overridden by environment variables, which are
+// overridden by flags supplied on the command line.
+type Defaults struct {
+	// Version of the defaults file format.
+	Version string `json:"version"`
+	// Global settings apply to every command.
+	Global Settings `json:"global,omitempty"`
+	// Commands settings apply to a command and its subcommands, keyed by
+	// command path, e.g. "controlplane list". Settings of more specific
+	// commands override those of less specific ones.
+	Commands map[string]Settings `json:"commands,omitempty"`
+}
+
+// Settings are default values of flags.
+type Settings struct {
+	// Account used to execute commands.
+	Account string `json:"account,omitempty"`
+	// Profile used to execute commands.
+	Profile string `json:"profile,omitempty"`
+	// KubeContext is the kubeconfig context of the Space to use.
+	KubeContext string `json:"kubeContext,omitempty"`
+	// Format of get and list commands.
+	Format Format `json:"format,omitempty"`
+	// CommandTimeout is the maximum time a command may run, e.g. 5m.
+	CommandTimeout string `json:"commandTimeout,omitempty"`
+	// HTTPResponseHeaderTimeout is the maximum time to wait for an API to
+	// respond to a request, e.g. 60s.
+	HTTPResponseHeaderTimeout string `json:"httpResponseHeaderTimeout,omitempty"`
+	// Flags are default values of any other flags, keyed by flag name.
+	Flags map[string]string `json:"flags,omitempty"`
+}
+
+// values returns the default values of the settings, keyed by flag name.
+// Typed settings take precedence over Flags.
+func (s Settings) values() map[string]string {
+	v := make(map[string]string, len(s.Flags)+6)
+	for k, val := range s.Flags {
+		v[k] = val
+	}
+	typed := map[string]string{
+		"account":                      s.Account,
+		"profile":                      s.Profile,
+		"kubecontext":                  s.KubeContext,
+		"format":                       string(s.Format),
+		"command-timeout":              s.CommandTimeout,
+		"http-response-header-timeout": s.HTTPResponseHeaderTimeout,
+	}
+	for k, val := range typed {
+		if val != "" {
+			v[k] = val
+		}
+	}
+	return v
+}
+
+// validate returns an error if the settings are invalid.
+func (s Settings) validate(scope string) error {
+	if !validFormat(s.Format) {
+		return errors.Errorf(errFmtInvalidFormat, scope, s.Format)
+	}
+	timeouts := map[string]string{
+		"commandTimeout":            s.CommandTimeout,
+		"httpResponseHeaderTimeout": s.HTTPResponseHeaderTimeout,
+	}
+	for name, t := range timeouts {
+		if t == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(t); err != nil || d < 0 {
+			return errors.Errorf(errFmtInvalidTimeout, scope, name, t)
+		}
+	}
+	return nil
+}
+
+// validFormat returns true if the supplied format is one of the known formats
+// or a JSONPath or Go template format.
+func validFormat(f Format) bool {
+	switch f {
+	case "", Default, Wide, JSON, YAML:
+		return true
+	}
+	return strings.HasPrefix(string(f), "jsonpath=") || strings.HasPrefix(string(f), "go-template=")
+}
+
+// Validate returns an error if the defaults are invalid.
+func (d *Defaults) Validate() error {
+	if d.Version != DefaultsVersion {
+		return errors.Errorf(errFmtDefaultsVer, d.Version)
+	}
+	if err := d.Global.validate("global"); err != nil {
+		return err
+	}
+	for _, path := range d.commandPaths() {
+		if err := d.Commands[path].validate(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commandPaths returns the command paths with settings, sorted.
+func (d *Defaults) commandPaths() []string {
+	paths := make([]string, 0, len(d.Commands))
+	for p := range d.Commands {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// GetDefaultsPath returns the path of the defaults file, which may be
+// overridden by the EnvDefaultsFile environment variable.
+func GetDefaultsPath() (string, error) {
+	if p := os.Getenv(EnvDefaultsFile); p != "" {
+		return p, nil
+	}
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h, ConfigDir, DefaultsFile), nil
+}
+
+// ReadDefaults reads the defaults file at the supplied path. A missing file
+// is not an error; it sets no defaults.
+func ReadDefaults(fs afero.Fs, path string) (*Defaults, error) {
+	b, err := afero.ReadFile(fs, path)
+	if os.IsNotExist(err) {
+		return &Defaults{Version: DefaultsVersion}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errReadDefaults)
+	}
+	d := &Defaults{}
+	if err := yaml.UnmarshalStrict(b, d); err != nil {
+		return nil, errors.Wrap(err, errParseDefaults)
+	}
+	return d, errors.Wrap(d.Validate(), errParseDefaults)
+}
+
+// WriteDefaults writes the defaults file to the supplied path.
+func WriteDefaults(fs afero.Fs, path string, d *Defaults) error {
+	b, err := yaml.Marshal(d)
+	if err != nil {
+		return errors.Wrap(err, errWriteDefaults)
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, errWriteDefaults)
+	}
+	return errors.Wrap(afero.WriteFile(fs, path, b, 0o600), errWriteDefaults)
+}
+
+// MigrateDefaults returns the defaults equivalent to the format and persisted
+// base config of the default profile of the supplied config, so that they
+// apply regardless of which profile is selected.
+func MigrateDefaults(c *Config) *Defaults {
+	d := &Defaults{Version: DefaultsVersion}
+	_, p, err := c.GetDefaultUpboundProfile()
+	if err != nil {
+		return d
+	}
+	d.Global.Format = Format(p.Format)
+	for k, v := range p.BaseConfig {
+		if d.Global.Flags == nil {
+			d.Global.Flags = map[string]string{}
+		}
+		// Base config is keyed by the JSON name of flags, which uses
+		// underscores where flag names use hyphens.
+		d.Global.Flags[strings.ReplaceAll(k, "_", "-")] = v
+	}
+	return d
+}
+
+// A DefaultsResolver resolves the values of flags that were not supplied on
+// the command line or through environment variables from Defaults.
+type DefaultsResolver struct {
+	defaults *Defaults
+	getenv   func(string) string
+}
+
+// Resolver returns a kong resolver that sets flags to their defaults. Flags
+// set through an environment variable, as read by the supplied function, are
+// not resolved so that environment variables take precedence.
+func (d *Defaults) Resolver(getenv func(string) string) *DefaultsResolver {
+	return &DefaultsResolver{defaults: d, getenv: getenv}
+}
+
+// Validate returns an error if the defaults refer to a command that does not
+// exist, or set a flag that the command does not have.
+func (r *DefaultsResolver) Validate(app *kong.Application) error {
+	cmds := map[string]*kong.Node{}
+	_ = kong.Visit(app.Node, func(n kong.Visitable, next kong.Next) error {
+		if node, ok := n.(*kong.Node); ok && node.Type == kong.CommandNode {
+			cmds[strings.Join(commandPath(node), " ")] = node
+		}
+		return next(nil)
+	})
+	for _, path := range r.defaults.commandPaths() {
+		node, ok := cmds[path]
+		if !ok {
+			return errors.Errorf(errFmtUnknownCommand, path)
+		}
+		for name := range r.defaults.Commands[path].Flags {
+			if !hasFlag(node, name) {
+				return errors.Errorf(errFmtUnknownFlag, path, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve the default value of the supplied flag.
+func (r *DefaultsResolver) Resolve(ctx *kong.Context, _ *kong.Path, flag *kong.Flag) (any, error) {
+	for _, env := range flag.Envs {
+		if r.getenv(env) != "" {
+			return nil, nil
+		}
+	}
+
+	layers := []Settings{r.defaults.Global}
+	if n := ctx.Selected(); n != nil {
+		path := commandPath(n)
+		for i := 1; i <= len(path); i++ {
+			if s, ok := r.defaults.Commands[strings.Join(path[:i], " ")]; ok {
+				layers = append(layers, s)
+			}
+		}
+	}
+
+	var value any
+	for _, s := range layers {
+		if v, ok := s.values()[flag.Name]; ok {
+			value = v
+		}
+	}
+	return value, nil
+}
+
+// commandPath returns the names of the supplied command and its ancestors,
+// from the root down.
+func commandPath(n *kong.Node) []string {
+	var path []string
+	for ; n != nil; n = n.Parent {
+		if n.Type == kong.CommandNode {
+			path = append([]string{n.Name}, path...)
+		}
+	}
+	return path
+}
+
+// hasFlag returns true if the supplied command or one of its ancestors has
+// a flag with the supplied name.
+func hasFlag(n *kong.Node, name string) bool {
+	for ; n != nil; n = n.Parent {
+		for _, f := range n.Flags {
+			if f.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
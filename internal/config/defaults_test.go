@@ -0,0 +1,262 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/profile"
+)
+
+func TestReadDefaults(t *testing.T) {
+	type want struct {
+		d   *Defaults
+		err error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		contents *string
+		want     want
+	}{
+		"Missing": {
+			reason: "A missing defaults file should set no defaults.",
+			want: want{
+				d: &Defaults{Version: DefaultsVersion},
+			},
+		},
+		"Valid": {
+			reason: "A valid defaults file should be parsed.",
+			contents: pointerTo(`
+version: v1
+global:
+  account: acme
+  format: wide
+commands:
+  controlplane list:
+    format: json
+    commandTimeout: 30s
+`),
+			want: want{
+				d: &Defaults{
+					Version: DefaultsVersion,
+					Global:  Settings{Account: "acme", Format: Wide},
+					Commands: map[string]Settings{
+						"controlplane list": {Format: JSON, CommandTimeout: "30s"},
+					},
+				},
+			},
+		},
+		"UnknownVersion": {
+			reason:   "A defaults file of an unknown version should be rejected.",
+			contents: pointerTo("version: v2\n"),
+			want: want{
+				err: errors.Wrap(errors.Errorf(errFmtDefaultsVer, "v2"), errParseDefaults),
+			},
+		},
+		"InvalidFormat": {
+			reason:   "A defaults file with an invalid format should be rejected.",
+			contents: pointerTo("version: v1\nglobal:\n  format: xml\n"),
+			want: want{
+				err: errors.Wrap(errors.Errorf(errFmtInvalidFormat, "global", "xml"), errParseDefaults),
+			},
+		},
+		"InvalidTimeout": {
+			reason:   "A defaults file with an invalid timeout should be rejected.",
+			contents: pointerTo("version: v1\ncommands:\n  space billing:\n    commandTimeout: soon\n"),
+			want: want{
+				err: errors.Wrap(errors.Errorf(errFmtInvalidTimeout, "space billing", "commandTimeout", "soon"), errParseDefaults),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tc.contents != nil {
+				_ = afero.WriteFile(fs, "defaults.yaml", []byte(*tc.contents), 0o600)
+			}
+			got, err := ReadDefaults(fs, "defaults.yaml")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadDefaults(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.d, got); diff != "" {
+				t.Errorf("\n%s\nReadDefaults(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func pointerTo(s string) *string {
+	return &s
+}
+
+type testCLI struct {
+	Format string `name:"format"`
+
+	Controlplane struct {
+		List struct {
+			Account string `name:"account" env:"TEST_ACCOUNT"`
+		} `cmd:""`
+	} `cmd:"" name:"controlplane"`
+}
+
+func TestDefaultsResolver(t *testing.T) {
+	d := &Defaults{
+		Version: DefaultsVersion,
+		Global:  Settings{Account: "global", Format: Wide},
+		Commands: map[string]Settings{
+			"controlplane":      {Account: "controlplane"},
+			"controlplane list": {Format: JSON},
+		},
+	}
+
+	type args struct {
+		args []string
+		env  map[string]string
+	}
+	type want struct {
+		format  string
+		account string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Defaults": {
+			reason: "Settings of more specific commands should override less specific ones.",
+			args: args{
+				args: []string{"controlplane", "list"},
+			},
+			want: want{
+				format:  "json",
+				account: "controlplane",
+			},
+		},
+		"Env": {
+			reason: "Environment variables should override defaults.",
+			args: args{
+				args: []string{"controlplane", "list"},
+				env:  map[string]string{"TEST_ACCOUNT": "env"},
+			},
+			want: want{
+				format:  "json",
+				account: "env",
+			},
+		},
+		"Flags": {
+			reason: "Flags should override environment variables and defaults.",
+			args: args{
+				args: []string{"--format=yaml", "controlplane", "list", "--account=flag"},
+				env:  map[string]string{"TEST_ACCOUNT": "env"},
+			},
+			want: want{
+				format:  "yaml",
+				account: "flag",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.args.env {
+				t.Setenv(k, v)
+			}
+			getenv := func(k string) string { return tc.args.env[k] }
+
+			cli := &testCLI{}
+			parser, err := kong.New(cli, kong.Resolvers(d.Resolver(getenv)))
+			if err != nil {
+				t.Fatalf("kong.New(...): %v", err)
+			}
+			if _, err := parser.Parse(tc.args.args); err != nil {
+				t.Fatalf("\n%s\nParse(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.format, cli.Format); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want format, +got format:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.account, cli.Controlplane.List.Account); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want account, +got account:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultsResolverValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		commands map[string]Settings
+		want     error
+	}{
+		"Valid": {
+			reason:   "Defaults of existing flags of existing commands should be valid.",
+			commands: map[string]Settings{"controlplane list": {Flags: map[string]string{"account": "acme"}}},
+		},
+		"UnknownCommand": {
+			reason:   "Defaults of a command that does not exist should be invalid.",
+			commands: map[string]Settings{"controlplane get": {}},
+			want:     errors.Errorf(errFmtUnknownCommand, "controlplane get"),
+		},
+		"UnknownFlag": {
+			reason:   "Defaults of a flag the command does not have should be invalid.",
+			commands: map[string]Settings{"controlplane": {Flags: map[string]string{"account": "acme"}}},
+			want:     errors.Errorf(errFmtUnknownFlag, "controlplane", "account"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := &Defaults{Version: DefaultsVersion, Commands: tc.commands}
+			parser, err := kong.New(&testCLI{}, kong.Resolvers(d.Resolver(func(string) string { return "" })))
+			if err != nil {
+				t.Fatalf("kong.New(...): %v", err)
+			}
+			_, err = parser.Parse([]string{"controlplane", "list"})
+			if diff := cmp.Diff(tc.want, errors.Cause(err), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMigrateDefaults(t *testing.T) {
+	c := &Config{Upbound: Upbound{
+		Default: "default",
+		Profiles: map[string]profile.Profile{
+			"default": {
+				Format:     "yaml",
+				BaseConfig: map[string]string{"http_dial_timeout": "5s"},
+			},
+		},
+	}}
+	want := &Defaults{
+		Version: DefaultsVersion,
+		Global: Settings{
+			Format: YAML,
+			Flags:  map[string]string{"http-dial-timeout": "5s"},
+		},
+	}
+	if diff := cmp.Diff(want, MigrateDefaults(c)); diff != "" {
+		t.Errorf("MigrateDefaults(...): -want, +got:\n%s", diff)
+	}
+}
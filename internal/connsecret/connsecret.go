@@ -0,0 +1,323 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connsecret propagates the connection secrets of claims in a control
+// plane to namespaces of other clusters, e.g. where applications consume
+// them.
+package connsecret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// AnnotationSource is set on propagated secrets to the namespace and
+	// name of the secret they were copied from. Secrets without it are never
+	// overwritten.
+	AnnotationSource = "up.upbound.io/connection-secret-source"
+
+	// DefaultSettle is the default time a watch waits for events to settle
+	// before syncing.
+	DefaultSettle = 250 * time.Millisecond
+
+	errFmtMapKind     = "failed to map kind %s"
+	errFmtGetClaim    = "failed to get claim %s"
+	errFmtNoSecretRef = "claim %s does not write a connection secret"
+	errFmtGetSource   = "failed to get connection secret %s"
+	errFmtGetTarget   = "failed to get target secret %s"
+	errFmtNotOwned    = "target secret %s exists and was not propagated by up"
+	errFmtWriteTarget = "failed to write target secret %s"
+	errFmtMissingKey  = "connection secret has no key %q"
+	errSourceDeleted  = "connection secret was deleted"
+)
+
+// Ref references a claim.
+type Ref struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+// String returns the reference in kind.group/namespace/name form.
+func (r Ref) String() string {
+	gk := schema.FromAPIVersionAndKind(r.APIVersion, r.Kind).GroupKind()
+	return fmt.Sprintf("%s/%s/%s", gk, r.Namespace, r.Name)
+}
+
+// A Target is the secret a connection secret is propagated to.
+type Target struct {
+	Namespace string
+	// Name defaults to the name of the connection secret.
+	Name string
+}
+
+// Syncer propagates connection secrets.
+type Syncer struct {
+	dyn    dynamic.Interface
+	mapper meta.RESTMapper
+	src    kubernetes.Interface
+	dst    kubernetes.Interface
+
+	keys   map[string]string
+	only   bool
+	settle time.Duration
+	resync time.Duration
+	log    logging.Logger
+}
+
+// Option modifies a Syncer.
+type Option func(*Syncer)
+
+// WithKeyMap renames keys of the connection secret; keys are source keys and
+// values the keys they are written to in the target secret. Keys that are
+// not mapped are copied unchanged.
+func WithKeyMap(m map[string]string) Option {
+	return func(s *Syncer) {
+		s.keys = m
+	}
+}
+
+// WithOnlyMappedKeys copies only keys of the connection secret that are
+// mapped by WithKeyMap. Syncing fails if any mapped key is missing.
+func WithOnlyMappedKeys() Option {
+	return func(s *Syncer) {
+		s.only = true
+	}
+}
+
+// WithSettle sets how long a watch waits for events to settle before syncing.
+func WithSettle(d time.Duration) Option {
+	return func(s *Syncer) {
+		s.settle = d
+	}
+}
+
+// WithResync sets how often a watch resyncs, which also repairs changes made
+// to the target secret. Zero disables resyncing.
+func WithResync(d time.Duration) Option {
+	return func(s *Syncer) {
+		s.resync = d
+	}
+}
+
+// WithLogger sets the logger used by the Syncer.
+func WithLogger(l logging.Logger) Option {
+	return func(s *Syncer) {
+		s.log = l
+	}
+}
+
+// New constructs a Syncer that reads claims and their connection secrets from
+// a control plane through dyn and src, and writes them to another cluster
+// through dst.
+func New(dyn dynamic.Interface, mapper meta.RESTMapper, src, dst kubernetes.Interface, opts ...Option) *Syncer {
+	s := &Syncer{
+		dyn:    dyn,
+		mapper: mapper,
+		src:    src,
+		dst:    dst,
+		settle: DefaultSettle,
+		log:    logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Resolve returns the connection secret of the referenced claim.
+func (s *Syncer) Resolve(ctx context.Context, claim Ref) (types.NamespacedName, error) {
+	gvk := schema.FromAPIVersionAndKind(claim.APIVersion, claim.Kind)
+	m, err := s.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return types.NamespacedName{}, errors.Wrapf(err, errFmtMapKind, gvk)
+	}
+	u, err := s.dyn.Resource(m.Resource).Namespace(claim.Namespace).Get(ctx, claim.Name, metav1.GetOptions{})
+	if err != nil {
+		return types.NamespacedName{}, errors.Wrapf(err, errFmtGetClaim, claim)
+	}
+	name, _ := fieldpath.Pave(u.Object).GetString("spec.writeConnectionSecretToRef.name")
+	if name == "" {
+		return types.NamespacedName{}, errors.Errorf(errFmtNoSecretRef, claim)
+	}
+	return types.NamespacedName{Namespace: claim.Namespace, Name: name}, nil
+}
+
+// Sync copies the connection secret of the referenced claim to the target
+// once, and returns the written secret.
+func (s *Syncer) Sync(ctx context.Context, claim Ref, t Target) (*corev1.Secret, error) {
+	src, err := s.Resolve(ctx, claim)
+	if err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, src, t)
+}
+
+func (s *Syncer) sync(ctx context.Context, src types.NamespacedName, t Target) (*corev1.Secret, error) {
+	in, err := s.src.CoreV1().Secrets(src.Namespace).Get(ctx, src.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtGetSource, src)
+	}
+	data, err := s.remap(in.Data)
+	if err != nil {
+		return nil, err
+	}
+	if t.Name == "" {
+		t.Name = src.Name
+	}
+	dst := types.NamespacedName{Namespace: t.Namespace, Name: t.Name}
+
+	out, err := s.dst.CoreV1().Secrets(t.Namespace).Get(ctx, t.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		out = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   t.Namespace,
+				Name:        t.Name,
+				Annotations: map[string]string{AnnotationSource: src.String()},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: data,
+		}
+		s.log.Debug("Creating target secret", "source", src.String(), "target", dst.String())
+		out, err = s.dst.CoreV1().Secrets(t.Namespace).Create(ctx, out, metav1.CreateOptions{})
+		return out, errors.Wrapf(err, errFmtWriteTarget, dst)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtGetTarget, dst)
+	}
+	if out.GetAnnotations()[AnnotationSource] != src.String() {
+		return nil, errors.Errorf(errFmtNotOwned, dst)
+	}
+	if reflect.DeepEqual(out.Data, data) {
+		return out, nil
+	}
+	out.Data = data
+	s.log.Debug("Updating target secret", "source", src.String(), "target", dst.String())
+	out, err = s.dst.CoreV1().Secrets(t.Namespace).Update(ctx, out, metav1.UpdateOptions{})
+	return out, errors.Wrapf(err, errFmtWriteTarget, dst)
+}
+
+func (s *Syncer) remap(in map[string][]byte) (map[string][]byte, error) {
+	out := make(map[string][]byte, len(in))
+	if s.only {
+		for from, to := range s.keys {
+			v, ok := in[from]
+			if !ok {
+				return nil, errors.Errorf(errFmtMissingKey, from)
+			}
+			out[to] = v
+		}
+		return out, nil
+	}
+	for k, v := range in {
+		if to, ok := s.keys[k]; ok {
+			k = to
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// An Event is the outcome of a sync during a watch. Err is set if the sync
+// failed; the watch continues regardless.
+type Event struct {
+	Secret *corev1.Secret
+	Err    error
+}
+
+// Watch syncs the connection secret of the referenced claim to the target
+// once, and again whenever the connection secret changes. An event is sent
+// for each sync. The returned channel is closed when the context is
+// cancelled. The target secret is left in place if the connection secret is
+// deleted.
+func (s *Syncer) Watch(ctx context.Context, claim Ref, t Target) (<-chan Event, error) {
+	src, err := s.Resolve(ctx, claim)
+	if err != nil {
+		return nil, err
+	}
+
+	// Events arriving during a sync are coalesced into a single subsequent
+	// sync.
+	trigger := make(chan bool, 1)
+	notify := func(deleted bool) {
+		select {
+		case trigger <- deleted:
+		default:
+		}
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(s.src, s.resync,
+		informers.WithNamespace(src.Namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.FieldSelector = fields.OneTermEqualSelector("metadata.name", src.Name).String()
+		}),
+	)
+	_, _ = factory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { notify(false) },
+		UpdateFunc: func(any, any) { notify(false) },
+		DeleteFunc: func(any) { notify(true) },
+	})
+	factory.Start(ctx.Done())
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer factory.Shutdown()
+
+		for {
+			var deleted bool
+			select {
+			case <-ctx.Done():
+				return
+			case deleted = <-trigger:
+			}
+
+			// Let closely spaced events settle before syncing.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.settle):
+			}
+
+			e := Event{Err: errors.New(errSourceDeleted)}
+			if !deleted {
+				e.Secret, e.Err = s.sync(ctx, src, t)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case events <- e:
+			}
+		}
+	}()
+	return events, nil
+}
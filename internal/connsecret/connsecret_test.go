@@ -0,0 +1,220 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connsecret
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var claimRef = Ref{APIVersion: "example.org/v1", Kind: "Database", Namespace: "default", Name: "db"}
+
+func mapper() meta.RESTMapper {
+	m := meta.NewDefaultRESTMapper(nil)
+	m.Add(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Database"}, meta.RESTScopeNamespace)
+	return m
+}
+
+func claim(secret string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{}}}
+	u.SetAPIVersion(claimRef.APIVersion)
+	u.SetKind(claimRef.Kind)
+	u.SetNamespace(claimRef.Namespace)
+	u.SetName(claimRef.Name)
+	if secret != "" {
+		u.Object["spec"] = map[string]any{"writeConnectionSecretToRef": map[string]any{"name": secret}}
+	}
+	return u
+}
+
+func secret(ns, name, source string, data map[string]string) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{},
+	}
+	if source != "" {
+		s.SetAnnotations(map[string]string{AnnotationSource: source})
+	}
+	for k, v := range data {
+		s.Data[k] = []byte(v)
+	}
+	return s
+}
+
+func TestSync(t *testing.T) {
+	conn := secret("default", "db-conn", "", map[string]string{"username": "admin", "password": "hunter2"})
+
+	type args struct {
+		claim  *unstructured.Unstructured
+		dst    []runtime.Object
+		opts   []Option
+		target Target
+	}
+	type want struct {
+		secret *corev1.Secret
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoSecretRef": {
+			reason: "A claim that does not write a connection secret should return an error.",
+			args: args{
+				claim:  claim(""),
+				target: Target{Namespace: "app"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtNoSecretRef, claimRef),
+			},
+		},
+		"Create": {
+			reason: "A missing target secret should be created with the name of the connection secret.",
+			args: args{
+				claim:  claim("db-conn"),
+				target: Target{Namespace: "app"},
+			},
+			want: want{
+				secret: secret("app", "db-conn", "default/db-conn", map[string]string{"username": "admin", "password": "hunter2"}),
+			},
+		},
+		"Remap": {
+			reason: "Mapped keys should be renamed, and other keys copied unchanged.",
+			args: args{
+				claim:  claim("db-conn"),
+				opts:   []Option{WithKeyMap(map[string]string{"username": "DB_USER"})},
+				target: Target{Namespace: "app", Name: "db"},
+			},
+			want: want{
+				secret: secret("app", "db", "default/db-conn", map[string]string{"DB_USER": "admin", "password": "hunter2"}),
+			},
+		},
+		"OnlyMapped": {
+			reason: "Only mapped keys should be copied if requested.",
+			args: args{
+				claim:  claim("db-conn"),
+				opts:   []Option{WithKeyMap(map[string]string{"password": "DB_PASSWORD"}), WithOnlyMappedKeys()},
+				target: Target{Namespace: "app", Name: "db"},
+			},
+			want: want{
+				secret: secret("app", "db", "default/db-conn", map[string]string{"DB_PASSWORD": "hunter2"}),
+			},
+		},
+		"MissingMappedKey": {
+			reason: "A mapped key missing from the connection secret should return an error if only mapped keys are copied.",
+			args: args{
+				claim:  claim("db-conn"),
+				opts:   []Option{WithKeyMap(map[string]string{"endpoint": "DB_HOST"}), WithOnlyMappedKeys()},
+				target: Target{Namespace: "app"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtMissingKey, "endpoint"),
+			},
+		},
+		"Update": {
+			reason: "A previously propagated target secret should be updated.",
+			args: args{
+				claim:  claim("db-conn"),
+				dst:    []runtime.Object{secret("app", "db-conn", "default/db-conn", map[string]string{"username": "old"})},
+				target: Target{Namespace: "app"},
+			},
+			want: want{
+				secret: secret("app", "db-conn", "default/db-conn", map[string]string{"username": "admin", "password": "hunter2"}),
+			},
+		},
+		"NotOwned": {
+			reason: "A target secret that was not propagated by up should not be overwritten.",
+			args: args{
+				claim:  claim("db-conn"),
+				dst:    []runtime.Object{secret("app", "db-conn", "", map[string]string{"username": "other"})},
+				target: Target{Namespace: "app"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtNotOwned, "app/db-conn"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), tc.args.claim)
+			s := New(dyn, mapper(), fake.NewSimpleClientset(conn.DeepCopy()), fake.NewSimpleClientset(tc.args.dst...), tc.args.opts...)
+			got, err := s.Sync(context.Background(), claimRef, tc.args.target)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.secret, got); diff != "" {
+				t.Errorf("\n%s\nSync(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), claim("db-conn"))
+	src := fake.NewSimpleClientset(secret("default", "db-conn", "", map[string]string{"password": "hunter2"}))
+	dst := fake.NewSimpleClientset()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := New(dyn, mapper(), src, dst, WithSettle(10*time.Millisecond)).Watch(ctx, claimRef, Target{Namespace: "app"})
+	if err != nil {
+		t.Fatalf("Watch(...): %v", err)
+	}
+	next := func() Event {
+		select {
+		case e := <-events:
+			return e
+		case <-ctx.Done():
+			t.Fatal("Watch(...): timed out waiting for sync")
+			return Event{}
+		}
+	}
+
+	if e := next(); e.Err != nil {
+		t.Fatalf("Watch(...): initial sync: %v", e.Err)
+	}
+	rotated := secret("default", "db-conn", "", map[string]string{"password": "rotated"})
+	if _, err := src.CoreV1().Secrets("default").Update(ctx, rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update(...): %v", err)
+	}
+	e := next()
+	if e.Err != nil {
+		t.Fatalf("Watch(...): sync after update: %v", e.Err)
+	}
+	if diff := cmp.Diff("rotated", string(e.Secret.Data["password"])); diff != "" {
+		t.Errorf("Watch(...): -want password, +got password:\n%s", diff)
+	}
+
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/fleet"
+)
+
+const (
+	// DefaultInterval is how often control planes are listed by default.
+	DefaultInterval = 10 * time.Second
+	// DefaultEventBuffer is how many events are buffered by default.
+	DefaultEventBuffer = 32
+
+	errListControlPlanes = "cannot list control planes"
+	errNoSelection       = "no control plane is selected"
+	errFmtUnsupported    = "action %q is not supported"
+	errFmtPending        = "action %q on control plane %q is in progress"
+	errFmtAction         = "cannot %s control plane %q"
+)
+
+// An Action that can be performed on a control plane.
+type Action string
+
+// Actions supported by the browser. Which are available depends on the
+// ActionFns the Controller was constructed with.
+const (
+	ActionConnect Action = "connect"
+	ActionPause   Action = "pause"
+	ActionResume  Action = "resume"
+	ActionDelete  Action = "delete"
+)
+
+// An ActionFn performs an action on the named control plane.
+type ActionFn func(ctx context.Context, name string) error
+
+// FleetAction returns an ActionFn that applies the supplied fleet operation,
+// e.g. fleet.NewPause, to control planes in the supplied group.
+func FleetAction(op fleet.Operation, group string) ActionFn {
+	return func(ctx context.Context, name string) error {
+		return op.Apply(ctx, fleet.Target{Group: group, Name: name})
+	}
+}
+
+// EventType is the type of an Event.
+type EventType string
+
+// Event types.
+const (
+	// EventRefreshed is emitted when control planes were listed.
+	EventRefreshed EventType = "Refreshed"
+	// EventRefreshFailed is emitted when listing control planes failed.
+	EventRefreshFailed EventType = "RefreshFailed"
+	// EventActionStarted is emitted when an action starts.
+	EventActionStarted EventType = "ActionStarted"
+	// EventActionSucceeded is emitted when an action succeeds.
+	EventActionSucceeded EventType = "ActionSucceeded"
+	// EventActionFailed is emitted when an action fails.
+	EventActionFailed EventType = "ActionFailed"
+)
+
+// An Event indicates the Model changed and should be rendered again.
+type Event struct {
+	Type EventType
+	// ControlPlane and Action are only set for action events.
+	ControlPlane string
+	Action       Action
+	// Err is only set for failure events.
+	Err error
+}
+
+// A Lister lists control planes.
+type Lister interface {
+	List(ctx context.Context) ([]*controlplane.Response, error)
+}
+
+// A Controller keeps a Model up to date with the control planes returned by
+// a Lister and performs actions on the selected control plane.
+type Controller struct {
+	lister   Lister
+	model    *Model
+	actions  map[Action]ActionFn
+	interval time.Duration
+	now      func() time.Time
+	log      logging.Logger
+
+	mu     sync.RWMutex
+	events chan Event
+	closed bool
+}
+
+// Option modifies the Controller.
+type Option func(*Controller)
+
+// WithAction makes the supplied action available, performed by the supplied
+// function.
+func WithAction(a Action, fn ActionFn) Option {
+	return func(c *Controller) {
+		c.actions[a] = fn
+	}
+}
+
+// WithInterval sets how often control planes are listed.
+func WithInterval(d time.Duration) Option {
+	return func(c *Controller) {
+		c.interval = d
+	}
+}
+
+// WithEventBuffer sets how many events are buffered before emitting an event
+// blocks.
+func WithEventBuffer(n int) Option {
+	return func(c *Controller) {
+		c.events = make(chan Event, n)
+	}
+}
+
+// WithClock overrides the function used to get the current time.
+func WithClock(now func() time.Time) Option {
+	return func(c *Controller) {
+		c.now = now
+	}
+}
+
+// WithLogger overrides the default logger.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Controller) {
+		c.log = l
+	}
+}
+
+// NewController returns a Controller that lists control planes with the
+// supplied Lister.
+func NewController(l Lister, opts ...Option) *Controller {
+	c := &Controller{
+		lister:   l,
+		model:    NewModel(),
+		actions:  map[Action]ActionFn{},
+		interval: DefaultInterval,
+		events:   make(chan Event, DefaultEventBuffer),
+		now:      time.Now,
+		log:      logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Model returns the Model maintained by the Controller.
+func (c *Controller) Model() *Model {
+	return c.model
+}
+
+// Events returns the stream of events. It is closed when Run returns.
+func (c *Controller) Events() <-chan Event {
+	return c.events
+}
+
+// Actions returns whether each action is supported.
+func (c *Controller) Actions() map[Action]bool {
+	supported := make(map[Action]bool, len(c.actions))
+	for a := range c.actions {
+		supported[a] = true
+	}
+	return supported
+}
+
+// Run lists control planes immediately and then at the configured interval
+// until the supplied context is done.
+func (c *Controller) Run(ctx context.Context) {
+	defer func() {
+		c.mu.Lock()
+		c.closed = true
+		close(c.events)
+		c.mu.Unlock()
+	}()
+
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+	for {
+		// Failures are emitted as events and recorded in the Model.
+		_ = c.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Refresh lists control planes and updates the Model.
+func (c *Controller) Refresh(ctx context.Context) error {
+	ctps, err := c.lister.List(ctx)
+	if err != nil {
+		err = errors.Wrap(err, errListControlPlanes)
+		c.log.Debug("Cannot refresh control planes", "error", err)
+		c.model.SetError(err)
+		c.emit(ctx, Event{Type: EventRefreshFailed, Err: err})
+		return err
+	}
+	c.model.SetControlPlanes(ctps, c.now())
+	c.emit(ctx, Event{Type: EventRefreshed})
+	return nil
+}
+
+// Do performs the supplied action on the selected control plane and then
+// refreshes the Model. Only one action per control plane may be in progress.
+func (c *Controller) Do(ctx context.Context, a Action) error {
+	name := c.model.Selected()
+	if name == "" {
+		return errors.New(errNoSelection)
+	}
+	return c.DoTo(ctx, a, name)
+}
+
+// DoTo performs the supplied action on the named control plane and then
+// refreshes the Model. Only one action per control plane may be in progress.
+func (c *Controller) DoTo(ctx context.Context, a Action, name string) error {
+	fn, ok := c.actions[a]
+	if !ok {
+		return errors.Errorf(errFmtUnsupported, a)
+	}
+	if !c.model.start(name, a) {
+		return errors.Errorf(errFmtPending, c.model.State().Pending[name], name)
+	}
+
+	c.emit(ctx, Event{Type: EventActionStarted, ControlPlane: name, Action: a})
+	err := fn(ctx, name)
+	c.model.finish(name)
+	if err != nil {
+		err = errors.Wrapf(err, errFmtAction, a, name)
+		c.log.Debug("Action failed", "action", a, "controlplane", name, "error", err)
+		c.emit(ctx, Event{Type: EventActionFailed, ControlPlane: name, Action: a, Err: err})
+		return err
+	}
+	c.emit(ctx, Event{Type: EventActionSucceeded, ControlPlane: name, Action: a})
+
+	// The action succeeded even if the refresh does not.
+	_ = c.Refresh(ctx)
+	return nil
+}
+
+// emit sends an event, blocking until it is buffered or the context is done.
+// Events are dropped once Run has returned.
+func (c *Controller) emit(ctx context.Context, e Event) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.events <- e:
+	case <-ctx.Done():
+	}
+}
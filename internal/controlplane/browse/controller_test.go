@@ -0,0 +1,179 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+type listFn func(ctx context.Context) ([]*controlplane.Response, error)
+
+func (fn listFn) List(ctx context.Context) ([]*controlplane.Response, error) {
+	return fn(ctx)
+}
+
+func drain(c *Controller) []Event {
+	var events []Event
+	for {
+		select {
+		case e := <-c.Events():
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestControllerDo(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		action  Action
+		actions map[Action]ActionFn
+	}
+	type want struct {
+		names  []string
+		events []Event
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unsupported": {
+			reason: "Performing an action the Controller was not constructed with should fail.",
+			args: args{
+				action: ActionPause,
+			},
+			want: want{
+				names: []string{"a", "b"},
+				err:   errors.Errorf(errFmtUnsupported, ActionPause),
+			},
+		},
+		"ActionFailed": {
+			reason: "A failed action should be returned and emitted.",
+			args: args{
+				action: ActionPause,
+				actions: map[Action]ActionFn{
+					ActionPause: func(ctx context.Context, name string) error { return errBoom },
+				},
+			},
+			want: want{
+				names: []string{"a", "b"},
+				events: []Event{
+					{Type: EventActionStarted, ControlPlane: "a", Action: ActionPause},
+					{Type: EventActionFailed, ControlPlane: "a", Action: ActionPause, Err: errors.Wrapf(errBoom, errFmtAction, ActionPause, "a")},
+				},
+				err: errors.Wrapf(errBoom, errFmtAction, ActionPause, "a"),
+			},
+		},
+		"Delete": {
+			reason: "A successful action should be emitted and the control planes refreshed.",
+			args: args{
+				action: ActionDelete,
+			},
+			want: want{
+				names: []string{"b"},
+				events: []Event{
+					{Type: EventActionStarted, ControlPlane: "a", Action: ActionDelete},
+					{Type: EventActionSucceeded, ControlPlane: "a", Action: ActionDelete},
+					{Type: EventRefreshed},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			names := []string{"a", "b"}
+			l := listFn(func(ctx context.Context) ([]*controlplane.Response, error) {
+				return ctps(names...), nil
+			})
+			opts := []Option{WithAction(ActionDelete, func(ctx context.Context, name string) error {
+				names = names[1:]
+				return nil
+			})}
+			for a, fn := range tc.args.actions {
+				opts = append(opts, WithAction(a, fn))
+			}
+			c := NewController(l, opts...)
+			if err := c.Refresh(context.Background()); err != nil {
+				t.Fatalf("Refresh(...): unexpected error: %v", err)
+			}
+			drain(c)
+
+			err := c.Do(context.Background(), tc.args.action)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, drain(c), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+			got := []string{}
+			for _, ctp := range c.Model().State().ControlPlanes {
+				got = append(got, ctp.Name)
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestControllerRun(t *testing.T) {
+	errBoom := errors.New("boom")
+	calls := 0
+	l := listFn(func(ctx context.Context) ([]*controlplane.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, errBoom
+		}
+		return ctps("a"), nil
+	})
+	c := NewController(l, WithInterval(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	want := []Event{
+		{Type: EventRefreshFailed, Err: errors.Wrap(errBoom, errListControlPlanes)},
+		{Type: EventRefreshed},
+	}
+	var got []Event
+	for e := range c.Events() {
+		got = append(got, e)
+		if len(got) == len(want) {
+			cancel()
+			break
+		}
+	}
+	if diff := cmp.Diff(want, got, test.EquateErrors()); diff != "" {
+		t.Errorf("Run(...): -want events, +got events:\n%s", diff)
+	}
+	s := c.Model().State()
+	if s.Err != nil || s.SelectedControlPlane() == nil || s.SelectedControlPlane().Name != "a" {
+		t.Errorf("Run(...): unexpected state after refresh: %+v", s)
+	}
+}
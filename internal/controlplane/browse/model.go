@@ -0,0 +1,203 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package browse maintains the state behind an interactive control plane
+// browser. It is independent of how the state is rendered: a terminal UI
+// reads the Model's State, calls the Controller in response to key presses,
+// and redraws when the Controller emits an Event.
+package browse
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+// State is a point in time copy of a Model.
+type State struct {
+	// ControlPlanes sorted by name.
+	ControlPlanes []*controlplane.Response
+	// Selected is the index of the selected control plane, or -1 if there
+	// are no control planes.
+	Selected int
+	// Pending are the actions in progress, by control plane name.
+	Pending map[string]Action
+	// RefreshedAt is when the control planes were last listed successfully.
+	RefreshedAt time.Time
+	// Err is the error of the last refresh, if it failed.
+	Err error
+}
+
+// SelectedControlPlane returns the selected control plane, or nil if there
+// are no control planes.
+func (s State) SelectedControlPlane() *controlplane.Response {
+	if s.Selected < 0 || s.Selected >= len(s.ControlPlanes) {
+		return nil
+	}
+	return s.ControlPlanes[s.Selected]
+}
+
+// A Model is the list of control planes being browsed and the selection
+// within it. It is safe for concurrent use.
+type Model struct {
+	mu          sync.RWMutex
+	ctps        []*controlplane.Response
+	selected    string
+	pending     map[string]Action
+	refreshedAt time.Time
+	err         error
+}
+
+// NewModel returns an empty Model.
+func NewModel() *Model {
+	return &Model{pending: map[string]Action{}}
+}
+
+// State returns a copy of the Model's state.
+func (m *Model) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s := State{
+		ControlPlanes: make([]*controlplane.Response, len(m.ctps)),
+		Selected:      m.index(),
+		Pending:       make(map[string]Action, len(m.pending)),
+		RefreshedAt:   m.refreshedAt,
+		Err:           m.err,
+	}
+	copy(s.ControlPlanes, m.ctps)
+	for k, v := range m.pending {
+		s.Pending[k] = v
+	}
+	return s
+}
+
+// SetControlPlanes replaces the control planes of the Model. The selection
+// follows the selected control plane by name. If it no longer exists, the
+// control plane that took its place is selected.
+func (m *Model) SetControlPlanes(ctps []*controlplane.Response, at time.Time) {
+	sorted := make([]*controlplane.Response, len(ctps))
+	copy(sorted, ctps)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.index()
+	m.ctps = sorted
+	m.refreshedAt = at
+	m.err = nil
+	if m.find(m.selected) >= 0 {
+		return
+	}
+	m.selected = ""
+	if len(sorted) == 0 {
+		return
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	m.selected = sorted[i].Name
+}
+
+// SetError records that refreshing the control planes failed. The previously
+// listed control planes are kept.
+func (m *Model) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+// Select the control plane with the supplied name. It returns false if there
+// is no such control plane.
+func (m *Model) Select(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.find(name) < 0 {
+		return false
+	}
+	m.selected = name
+	return true
+}
+
+// Move the selection by the supplied number of control planes, e.g. -1 to
+// select the previous control plane. The selection stops at the first and
+// last control plane.
+func (m *Model) Move(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.ctps) == 0 {
+		return
+	}
+	i := m.index() + n
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(m.ctps) {
+		i = len(m.ctps) - 1
+	}
+	m.selected = m.ctps[i].Name
+}
+
+// Selected returns the name of the selected control plane, or an empty
+// string if there are no control planes.
+func (m *Model) Selected() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.selected
+}
+
+// start records that an action on the named control plane is in progress.
+// It returns false if another action on it is already in progress.
+func (m *Model) start(name string, a Action) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.pending[name]; ok {
+		return false
+	}
+	m.pending[name] = a
+	return true
+}
+
+// finish records that the action on the named control plane completed.
+func (m *Model) finish(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, name)
+}
+
+// index returns the index of the selected control plane, or -1. The caller
+// must hold the lock.
+func (m *Model) index() int {
+	return m.find(m.selected)
+}
+
+// find returns the index of the named control plane, or -1. The caller must
+// hold the lock.
+func (m *Model) find(name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, ctp := range m.ctps {
+		if ctp.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+func ctps(names ...string) []*controlplane.Response {
+	r := make([]*controlplane.Response, len(names))
+	for i, n := range names {
+		r[i] = &controlplane.Response{Name: n}
+	}
+	return r
+}
+
+func TestModelSetControlPlanes(t *testing.T) {
+	type args struct {
+		initial []*controlplane.Response
+		sel     string
+		updated []*controlplane.Response
+	}
+	type want struct {
+		names    []string
+		selected string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SelectFirst": {
+			reason: "The first control plane should be selected when nothing was selected.",
+			args: args{
+				updated: ctps("b", "a"),
+			},
+			want: want{
+				names:    []string{"a", "b"},
+				selected: "a",
+			},
+		},
+		"FollowSelection": {
+			reason: "The selection should follow the selected control plane by name.",
+			args: args{
+				initial: ctps("b", "c"),
+				sel:     "c",
+				updated: ctps("a", "b", "c"),
+			},
+			want: want{
+				names:    []string{"a", "b", "c"},
+				selected: "c",
+			},
+		},
+		"SelectedRemoved": {
+			reason: "The control plane that took the place of a removed one should be selected.",
+			args: args{
+				initial: ctps("a", "b", "c"),
+				sel:     "b",
+				updated: ctps("a", "c"),
+			},
+			want: want{
+				names:    []string{"a", "c"},
+				selected: "c",
+			},
+		},
+		"LastRemoved": {
+			reason: "The new last control plane should be selected if the selected last one was removed.",
+			args: args{
+				initial: ctps("a", "b"),
+				sel:     "b",
+				updated: ctps("a"),
+			},
+			want: want{
+				names:    []string{"a"},
+				selected: "a",
+			},
+		},
+		"AllRemoved": {
+			reason: "Nothing should be selected if there are no control planes.",
+			args: args{
+				initial: ctps("a"),
+				sel:     "a",
+			},
+			want: want{
+				names: []string{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := NewModel()
+			m.SetControlPlanes(tc.args.initial, time.Time{})
+			if tc.args.sel != "" {
+				m.Select(tc.args.sel)
+			}
+			m.SetControlPlanes(tc.args.updated, time.Time{})
+
+			s := m.State()
+			names := make([]string, len(s.ControlPlanes))
+			for i, ctp := range s.ControlPlanes {
+				names[i] = ctp.Name
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("\n%s\nSetControlPlanes(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.selected, m.Selected()); diff != "" {
+				t.Errorf("\n%s\nSetControlPlanes(...): -want selected, +got selected:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestModelMove(t *testing.T) {
+	m := NewModel()
+	m.SetControlPlanes(ctps("a", "b", "c"), time.Time{})
+
+	steps := []struct {
+		n    int
+		want string
+	}{
+		{n: 1, want: "b"},
+		{n: 5, want: "c"},
+		{n: -1, want: "b"},
+		{n: -5, want: "a"},
+	}
+	for _, s := range steps {
+		m.Move(s.n)
+		if diff := cmp.Diff(s.want, m.Selected()); diff != "" {
+			t.Errorf("Move(%d): -want, +got:\n%s", s.n, diff)
+		}
+	}
+}
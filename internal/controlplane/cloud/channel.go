@@ -0,0 +1,93 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/resources"
+	"github.com/upbound/up/internal/telemetry"
+)
+
+const (
+	errChannelNotConfigured = "auto-upgrade channel management is not configured"
+	errGetChannel           = "cannot get auto-upgrade channel"
+	errSetChannel           = "cannot set auto-upgrade channel"
+)
+
+var _ controlplane.ChannelManager = &Client{}
+
+type autoUpgrade struct {
+	Channel string `json:"channel"`
+}
+
+type crossplaneSpec struct {
+	AutoUpgrade autoUpgrade `json:"autoUpgrade"`
+}
+
+type channelParameters struct {
+	Crossplane crossplaneSpec `json:"crossplane"`
+}
+
+type channelResponse struct {
+	ControlPlane struct {
+		Crossplane crossplaneSpec `json:"crossplane"`
+	} `json:"controlPlane"`
+}
+
+// GetChannel returns the Crossplane auto-upgrade channel of the ControlPlane
+// corresponding to the given name.
+func (c *Client) GetChannel(ctx context.Context, name string) (_ string, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane get channel", time.Now(), &err)
+	if c.api == nil {
+		return "", errors.New(errChannelNotConfigured)
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodGet, ctpPath, path.Join(c.account, name), nil)
+	if err != nil {
+		return "", errors.Wrap(err, errGetChannel)
+	}
+	resp := &channelResponse{}
+	if err := c.api.Do(req, resp); err != nil {
+		return "", errors.Wrap(classify(err), errGetChannel)
+	}
+	return resp.ControlPlane.Crossplane.AutoUpgrade.Channel, nil
+}
+
+// SetChannel sets the Crossplane auto-upgrade channel of the ControlPlane
+// corresponding to the given name.
+func (c *Client) SetChannel(ctx context.Context, name, channel string) (err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane set channel", time.Now(), &err)
+	if c.api == nil {
+		return errors.New(errChannelNotConfigured)
+	}
+	ch, err := resources.ParseCrossplaneChannel(channel)
+	if err != nil {
+		return err
+	}
+	c.log.Debug("Setting auto-upgrade channel", "controlplane", name, "channel", ch)
+	req, err := c.api.NewRequest(ctx, http.MethodPatch, ctpPath, path.Join(c.account, name), &channelParameters{
+		Crossplane: crossplaneSpec{AutoUpgrade: autoUpgrade{Channel: string(ch)}},
+	})
+	if err != nil {
+		return errors.Wrap(err, errSetChannel)
+	}
+	return errors.Wrap(classify(c.api.Do(req, nil)), errSetChannel)
+}
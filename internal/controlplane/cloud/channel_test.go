@@ -0,0 +1,139 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+func TestGetChannel(t *testing.T) {
+	type want struct {
+		channel string
+		reqs    []request
+		err     bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		api    *mockAPI
+		want   want
+	}{
+		"NotConfigured": {
+			reason: "Getting the channel should fail if no API client was supplied.",
+			want:   want{err: true},
+		},
+		"Success": {
+			reason: "The channel of the control plane should be returned.",
+			api: &mockAPI{resp: map[string]any{
+				"controlPlane": map[string]any{
+					"crossplane": map[string]any{"autoUpgrade": map[string]any{"channel": "Rapid"}},
+				},
+			}},
+			want: want{
+				channel: "Rapid",
+				reqs:    []request{{Method: http.MethodGet, Path: "v1/controlPlanes/demo/ctp1"}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []Option{}
+			if tc.api != nil {
+				opts = append(opts, WithAPI(tc.api))
+			}
+			got, err := New(nil, nil, acct, opts...).GetChannel(context.Background(), "ctp1")
+
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nGetChannel(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+			if diff := cmp.Diff(tc.want.channel, got); diff != "" {
+				t.Errorf("\n%s\nGetChannel(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.api != nil {
+				if diff := cmp.Diff(tc.want.reqs, tc.api.reqs); diff != "" {
+					t.Errorf("\n%s\nGetChannel(...): -want requests, +got requests:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSetChannel(t *testing.T) {
+	type want struct {
+		reqs  []request
+		check func(error) bool
+	}
+
+	cases := map[string]struct {
+		reason  string
+		api     *mockAPI
+		channel string
+		want    want
+	}{
+		"InvalidChannel": {
+			reason:  "Unknown channels should be rejected without calling the API.",
+			api:     &mockAPI{},
+			channel: "Fast",
+			want:    want{check: func(err error) bool { return err != nil }},
+		},
+		"NotFound": {
+			reason:  "A missing control plane should return a not found error.",
+			api:     &mockAPI{err: sdkNotFound},
+			channel: "stable",
+			want: want{
+				reqs:  []request{{Method: http.MethodPatch, Path: "v1/controlPlanes/demo/ctp1", Body: `{"crossplane":{"autoUpgrade":{"channel":"Stable"}}}`}},
+				check: controlplane.IsNotFound,
+			},
+		},
+		"PermissionDenied": {
+			reason:  "A forbidden update should return a permission denied error.",
+			api:     &mockAPI{err: &sdkerrs.Error{Status: http.StatusForbidden, Title: http.StatusText(http.StatusForbidden)}},
+			channel: "None",
+			want: want{
+				reqs:  []request{{Method: http.MethodPatch, Path: "v1/controlPlanes/demo/ctp1", Body: `{"crossplane":{"autoUpgrade":{"channel":"None"}}}`}},
+				check: controlplane.IsPermissionDenied,
+			},
+		},
+		"Success": {
+			reason:  "The channel should be normalized and sent to the API.",
+			api:     &mockAPI{},
+			channel: "rapid",
+			want: want{
+				reqs:  []request{{Method: http.MethodPatch, Path: "v1/controlPlanes/demo/ctp1", Body: `{"crossplane":{"autoUpgrade":{"channel":"Rapid"}}}`}},
+				check: func(err error) bool { return err == nil },
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := New(nil, nil, acct, WithAPI(tc.api)).SetChannel(context.Background(), "ctp1", tc.channel)
+
+			if !tc.want.check(err) {
+				t.Errorf("\n%s\nSetChannel(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.reqs, tc.api.reqs); diff != "" {
+				t.Errorf("\n%s\nSetChannel(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
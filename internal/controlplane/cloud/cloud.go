@@ -18,22 +18,34 @@ import (
 	"context"
 	"net/url"
 	"path"
+	"time"
 
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/pointer"
 
+	"github.com/upbound/up-sdk-go"
 	sdkerrs "github.com/upbound/up-sdk-go/errors"
 	"github.com/upbound/up-sdk-go/service/common"
 	"github.com/upbound/up-sdk-go/service/configurations"
 	"github.com/upbound/up-sdk-go/service/controlplanes"
 
 	"github.com/upbound/up/internal/controlplane"
+	uphttp "github.com/upbound/up/internal/http"
 	"github.com/upbound/up/internal/kube"
+	"github.com/upbound/up/internal/telemetry"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 const (
 	maxItems = 100
 
 	notAvailable = "n/a"
+
+	errGetToken          = "cannot get token"
+	errClassUnsupported  = "control plane classes are not supported by Upbound Cloud"
+	errLabelsUnsupported = "control plane labels are not supported by Upbound Cloud"
 )
 
 type ctpClient interface {
@@ -45,6 +57,7 @@ type ctpClient interface {
 
 type cfgGetter interface {
 	Get(ctx context.Context, account, name string) (*configurations.ConfigurationResponse, error)
+	List(ctx context.Context, account string) (*configurations.ConfigurationListResponse, error)
 }
 
 type Option func(*Client)
@@ -55,12 +68,61 @@ func WithToken(t string) Option {
 	}
 }
 
+// WithTokenStore sets the store the PAT is read from when no token was
+// supplied with WithToken.
+func WithTokenStore(s tokenstore.Store, key string) Option {
+	return func(c *Client) {
+		c.tokens = s
+		c.tokenKey = key
+	}
+}
+
 func WithProxyEndpoint(p *url.URL) Option {
 	return func(c *Client) {
 		c.proxy = p
 	}
 }
 
+// WithLogger sets the logger used by the Client.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// WithTelemetry sets the Recorder operations of the Client are recorded with.
+// Operations are recorded with the telemetry.DefaultRecorder by default.
+func WithTelemetry(r telemetry.Recorder) Option {
+	return func(c *Client) {
+		c.telemetry = r
+	}
+}
+
+// WithClientCertificate sets the client certificate presented to the proxy
+// by control plane kubeconfigs.
+func WithClientCertificate(cc uphttp.ClientCertificate) Option {
+	return func(c *Client) {
+		c.cert = cc
+	}
+}
+
+// WithAPI sets the Upbound API client used for operations that are not
+// covered by the SDK, such as managing auto-upgrade channels.
+func WithAPI(api up.Client) Option {
+	return func(c *Client) {
+		c.api = api
+	}
+}
+
+// WithTransfer sets the Upbound API client and accounts client required to
+// transfer control planes between accounts.
+func WithTransfer(api up.Client, accts accountGetter) Option {
+	return func(c *Client) {
+		c.api = api
+		c.accts = accts
+	}
+}
+
 // Client is the client used for interacting with the ControlPlanes API in
 // Upbound Cloud.
 type Client struct {
@@ -71,16 +133,31 @@ type Client struct {
 	account string
 	// Cloud PAT for Control Plane Kubeconfig.
 	token string
+	// Store and key the PAT is read from if token is not set.
+	tokens   tokenstore.Store
+	tokenKey string
 	// Proxy Endppint corresponding to Upbound Cloud's Proxy.
 	proxy *url.URL
+	// Client certificate for Control Plane Kubeconfig.
+	cert uphttp.ClientCertificate
+
+	// API client for operations not covered by the SDK, and account getter
+	// used to transfer control planes.
+	api   up.Client
+	accts accountGetter
+
+	log       logging.Logger
+	telemetry telemetry.Recorder
 }
 
 // New instantiates a new Client.
 func New(ctp ctpClient, cfg cfgGetter, account string, opts ...Option) *Client {
 	c := &Client{
-		ctp:     ctp,
-		cfg:     cfg,
-		account: account,
+		ctp:       ctp,
+		cfg:       cfg,
+		account:   account,
+		log:       logging.NewNopLogger(),
+		telemetry: telemetry.Deferred,
 	}
 
 	for _, o := range opts {
@@ -90,7 +167,8 @@ func New(ctp ctpClient, cfg cfgGetter, account string, opts ...Option) *Client {
 }
 
 // Get the ControlPlane corresponding to the given ControlPlane name.
-func (c *Client) Get(ctx context.Context, name string) (*controlplane.Response, error) {
+func (c *Client) Get(ctx context.Context, name string) (_ *controlplane.Response, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane get", time.Now(), &err)
 	resp, err := c.ctp.Get(ctx, c.account, name)
 
 	if sdkerrs.IsNotFound(err) {
@@ -101,30 +179,74 @@ func (c *Client) Get(ctx context.Context, name string) (*controlplane.Response,
 		return nil, err
 	}
 
-	return convert(resp), nil
+	r := convert(resp)
+	if r.Cfg != notAvailable && c.cfg != nil {
+		cfg, err := c.cfg.Get(ctx, c.account, r.Cfg)
+		if err != nil {
+			c.log.Debug("Cannot get latest configuration version", "configuration", r.Cfg, "error", err)
+			return r, nil
+		}
+		r.CfgLatestVersion = pointer.StringDeref(cfg.LatestVersion, "")
+	}
+	return r, nil
 }
 
 // List all ControlPlanes within the Upbound Cloud account.
-func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
+func (c *Client) List(ctx context.Context) (_ []*controlplane.Response, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane list", time.Now(), &err)
 	l, err := c.ctp.List(ctx, c.account, common.WithSize(maxItems))
 	if err != nil {
 		return nil, err
 	}
+	latest := c.latestVersions(ctx)
 	resps := []*controlplane.Response{}
 	for _, r := range l.ControlPlanes {
 		cp := r
-		resps = append(resps, convert(&cp))
+		resp := convert(&cp)
+		resp.CfgLatestVersion = latest[resp.Cfg]
+		resps = append(resps, resp)
 	}
 	return resps, nil
 }
 
+// latestVersions returns the latest version of each configuration in the
+// account, by name. Configurations are only used to annotate control planes,
+// so failing to list them is not an error.
+func (c *Client) latestVersions(ctx context.Context) map[string]string {
+	latest := map[string]string{}
+	if c.cfg == nil {
+		return latest
+	}
+	l, err := c.cfg.List(ctx, c.account)
+	if err != nil {
+		c.log.Debug("Cannot list configurations", "error", err)
+		return latest
+	}
+	for _, cfg := range l.Configurations {
+		if cfg.Name != nil && cfg.LatestVersion != nil {
+			latest[*cfg.Name] = *cfg.LatestVersion
+		}
+	}
+	return latest
+}
+
 // Create a new ControlPlane with the given name and the supplied Options.
-func (c *Client) Create(ctx context.Context, name string, opts controlplane.Options) (*controlplane.Response, error) {
+func (c *Client) Create(ctx context.Context, name string, opts controlplane.Options) (_ *controlplane.Response, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane create", time.Now(), &err)
+	// The Upbound Cloud API has no notion of classes. Fail rather than
+	// silently creating a control plane of a different size.
+	if opts.Class != "" {
+		return nil, errors.New(errClassUnsupported)
+	}
+	if len(opts.Labels) > 0 {
+		return nil, errors.New(errLabelsUnsupported)
+	}
 	// Get the UUID from the Configuration name, if it exists.
 	cfg, err := c.cfg.Get(ctx, c.account, opts.ConfigurationName)
 	if err != nil {
 		return nil, err
 	}
+	c.log.Debug("Resolved configuration", "configuration", opts.ConfigurationName, "id", cfg.ID)
 
 	resp, err := c.ctp.Create(ctx, c.account, &controlplanes.ControlPlaneCreateParameters{
 		Name:            name,
@@ -135,12 +257,21 @@ func (c *Client) Create(ctx context.Context, name string, opts controlplane.Opti
 		return nil, err
 	}
 
-	return convert(resp), nil
+	r := convert(resp)
+	r.CfgLatestVersion = pointer.StringDeref(cfg.LatestVersion, "")
+	return r, nil
+}
+
+// CreateFromTemplate creates a new ControlPlane with the given name and the
+// parameters of the supplied Template.
+func (c *Client) CreateFromTemplate(ctx context.Context, name string, t *controlplane.Template) (*controlplane.Response, error) {
+	return c.Create(ctx, name, t.Options())
 }
 
 // Delete the ControlPlane corresponding to the given ControlPlane name.
-func (c *Client) Delete(ctx context.Context, name string) error {
-	err := c.ctp.Delete(ctx, c.account, name)
+func (c *Client) Delete(ctx context.Context, name string) (err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane delete", time.Now(), &err)
+	err = c.ctp.Delete(ctx, c.account, name)
 	if sdkerrs.IsNotFound(err) {
 		return controlplane.NewNotFound(err)
 	}
@@ -148,35 +279,50 @@ func (c *Client) Delete(ctx context.Context, name string) error {
 }
 
 // GetKubeConfig for the given Control Plane.
-func (c *Client) GetKubeConfig(ctx context.Context, name string) (*api.Config, error) {
-	return kube.BuildControlPlaneKubeconfig(
+func (c *Client) GetKubeConfig(ctx context.Context, name string) (_ *api.Config, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane kubeconfig", time.Now(), &err)
+	token := c.token
+	if token == "" && c.tokens != nil {
+		c.log.Debug("Reading token from store", "key", c.tokenKey)
+		t, err := c.tokens.Get(c.tokenKey)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetToken)
+		}
+		token = t
+	}
+	if token == "" {
+		c.log.Info("No token available; the control plane kubeconfig will not authenticate", "controlplane", name)
+	}
+	conf := kube.BuildControlPlaneKubeconfig(
 		c.proxy,
 		path.Join(c.account, name),
-		c.token,
+		token,
 		false,
-	), nil
+	)
+	kube.SetKubeconfigClientCertificate(conf, c.cert)
+	return conf, nil
 }
 
 func convert(ctp *controlplanes.ControlPlaneResponse) *controlplane.Response {
 
-	var cfgName string
-	var cfgStatus string
+	resp := &controlplane.Response{
+		ID:     ctp.ControlPlane.ID.String(),
+		Name:   ctp.ControlPlane.Name,
+		Status: string(ctp.Status),
+	}
 	// All Upbound managed control planes in an account should be associated to a configuration.
 	// However, we should still list all control planes and indicate where this isn't the case.
-	if ctp.ControlPlane.Configuration.Name != nil && ctp.ControlPlane.Configuration != EmptyControlPlaneConfiguration() {
-		cfgName = *ctp.ControlPlane.Configuration.Name
-		cfgStatus = string(ctp.ControlPlane.Configuration.Status)
+	cfg := ctp.ControlPlane.Configuration
+	if cfg.Name != nil && cfg != EmptyControlPlaneConfiguration() {
+		resp.Cfg = *cfg.Name
+		resp.CfgStatus = string(cfg.Status)
+		resp.CfgCurrentVersion = pointer.StringDeref(cfg.CurrentVersion, "")
+		resp.CfgDesiredVersion = pointer.StringDeref(cfg.DesiredVersion, "")
+		resp.CfgSyncedAt = cfg.SyncedAt
 	} else {
-		cfgName, cfgStatus = notAvailable, notAvailable
-	}
-
-	return &controlplane.Response{
-		ID:        ctp.ControlPlane.ID.String(),
-		Name:      ctp.ControlPlane.Name,
-		Status:    string(ctp.Status),
-		Cfg:       cfgName,
-		CfgStatus: cfgStatus,
+		resp.Cfg, resp.CfgStatus = notAvailable, notAvailable
 	}
+	return resp
 }
 
 // EmptyControlPlaneConfiguration returns an empty ControlPlaneConfiguration with default values.
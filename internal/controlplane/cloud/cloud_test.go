@@ -17,19 +17,27 @@ package cloud
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
+	"github.com/spf13/afero"
 	"k8s.io/utils/pointer"
 
+	"github.com/upbound/up-sdk-go"
 	sdkerrs "github.com/upbound/up-sdk-go/errors"
 	"github.com/upbound/up-sdk-go/service/common"
+	"github.com/upbound/up-sdk-go/service/configurations"
 	"github.com/upbound/up-sdk-go/service/controlplanes"
 
 	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/http/mocks"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 var (
@@ -66,6 +74,20 @@ var (
 		CfgStatus: string(controlplanes.ConfigurationReady),
 	}
 
+	syncedAt = time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	ctpVersioned = controlplanes.ControlPlane{
+		Name: "ctp1",
+		ID:   uuid.MustParse("00000000-0000-0000-0000-000000000000"),
+		Configuration: controlplanes.ControlPlaneConfiguration{
+			Name:           pointer.String("cfg1"),
+			Status:         controlplanes.ConfigurationReady,
+			CurrentVersion: pointer.String("v1"),
+			DesiredVersion: pointer.String("v2"),
+			SyncedAt:       &syncedAt,
+		},
+	}
+
 	ctp2Resp = &controlplane.Response{
 		Name:      "ctp2",
 		ID:        "00000000-0000-0000-0000-000000000001",
@@ -97,6 +119,19 @@ func (m *mockCTPClient) List(ctx context.Context, account string, opts ...common
 	return m.ListFn(ctx, account, opts...)
 }
 
+func ctpVersionedResp(latest string) *controlplane.Response {
+	return &controlplane.Response{
+		Name:              "ctp1",
+		ID:                "00000000-0000-0000-0000-000000000000",
+		Cfg:               "cfg1",
+		CfgStatus:         string(controlplanes.ConfigurationReady),
+		CfgCurrentVersion: "v1",
+		CfgDesiredVersion: "v2",
+		CfgSyncedAt:       &syncedAt,
+		CfgLatestVersion:  latest,
+	}
+}
+
 func TestGet(t *testing.T) {
 	type args struct {
 		ctp  ctpClient
@@ -143,6 +178,48 @@ func TestGet(t *testing.T) {
 				resp: ctp1Resp,
 			},
 		},
+		"SuccessWithVersions": {
+			reason: "The response should include the current, desired and latest versions of the configuration.",
+			args: args{
+				ctp: &mockCTPClient{
+					GetFn: func(ctx context.Context, account, name string) (*controlplanes.ControlPlaneResponse, error) {
+						return &controlplanes.ControlPlaneResponse{
+							ControlPlane: ctpVersioned,
+						}, nil
+					},
+				},
+				cfg: &mockCfgClient{
+					GetFn: func(ctx context.Context, account, name string) (*configurations.ConfigurationResponse, error) {
+						return &configurations.ConfigurationResponse{Name: pointer.String("cfg1"), LatestVersion: pointer.String("v3")}, nil
+					},
+				},
+				name: "ctp1",
+			},
+			want: want{
+				resp: ctpVersionedResp("v3"),
+			},
+		},
+		"ConfigurationError": {
+			reason: "Failing to get the configuration should not fail getting the control plane.",
+			args: args{
+				ctp: &mockCTPClient{
+					GetFn: func(ctx context.Context, account, name string) (*controlplanes.ControlPlaneResponse, error) {
+						return &controlplanes.ControlPlaneResponse{
+							ControlPlane: ctpVersioned,
+						}, nil
+					},
+				},
+				cfg: &mockCfgClient{
+					GetFn: func(ctx context.Context, account, name string) (*configurations.ConfigurationResponse, error) {
+						return nil, errors.New("boom")
+					},
+				},
+				name: "ctp1",
+			},
+			want: want{
+				resp: ctpVersionedResp(""),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -160,6 +237,91 @@ func TestGet(t *testing.T) {
 	}
 }
 
+type mockCfgClient struct {
+	GetFn  func(ctx context.Context, account, name string) (*configurations.ConfigurationResponse, error)
+	ListFn func(ctx context.Context, account string) (*configurations.ConfigurationListResponse, error)
+}
+
+func (m *mockCfgClient) Get(ctx context.Context, account, name string) (*configurations.ConfigurationResponse, error) {
+	return m.GetFn(ctx, account, name)
+}
+
+func (m *mockCfgClient) List(ctx context.Context, account string) (*configurations.ConfigurationListResponse, error) {
+	return m.ListFn(ctx, account)
+}
+
+func TestCreate(t *testing.T) {
+	type args struct {
+		ctp  ctpClient
+		cfg  cfgGetter
+		opts controlplane.Options
+	}
+	type want struct {
+		resp *controlplane.Response
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ErrorClassUnsupported": {
+			reason: "Supplying a class should fail, since Upbound Cloud does not support classes.",
+			args: args{
+				opts: controlplane.Options{ConfigurationName: "cfg1", Class: "small"},
+			},
+			want: want{
+				err: errors.New(errClassUnsupported),
+			},
+		},
+		"ErrorLabelsUnsupported": {
+			reason: "Supplying labels should fail, since Upbound Cloud does not support labels.",
+			args: args{
+				opts: controlplane.Options{ConfigurationName: "cfg1", Labels: map[string]string{"env": "dev"}},
+			},
+			want: want{
+				err: errors.New(errLabelsUnsupported),
+			},
+		},
+		"Success": {
+			reason: "The control plane should be created with the ID of the supplied configuration.",
+			args: args{
+				cfg: &mockCfgClient{
+					GetFn: func(ctx context.Context, account, name string) (*configurations.ConfigurationResponse, error) {
+						return &configurations.ConfigurationResponse{ID: ctp2.ID}, nil
+					},
+				},
+				ctp: &mockCTPClient{
+					CreateFn: func(ctx context.Context, account string, params *controlplanes.ControlPlaneCreateParameters) (*controlplanes.ControlPlaneResponse, error) {
+						if params.ConfigurationID != ctp2.ID {
+							return nil, fmt.Errorf("unexpected configuration ID %s", params.ConfigurationID)
+						}
+						return &controlplanes.ControlPlaneResponse{ControlPlane: ctp1}, nil
+					},
+				},
+				opts: controlplane.Options{ConfigurationName: "cfg1"},
+			},
+			want: want{
+				resp: ctp1Resp,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := New(tc.args.ctp, tc.args.cfg, acct)
+			got, err := c.Create(context.Background(), "ctp1", tc.args.opts)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resp, got); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	type args struct {
 		ctp  ctpClient
@@ -291,6 +453,37 @@ func TestList(t *testing.T) {
 				},
 			},
 		},
+		"LatestVersions": {
+			reason: "Each control plane should include the latest version of its configuration.",
+			args: args{
+				ctp: &mockCTPClient{
+					ListFn: func(ctx context.Context, account string, opts ...common.ListOption) (*controlplanes.ControlPlaneListResponse, error) {
+						return &controlplanes.ControlPlaneListResponse{
+							ControlPlanes: []controlplanes.ControlPlaneResponse{
+								{
+									ControlPlane: ctpVersioned,
+								},
+							},
+						}, nil
+					},
+				},
+				cfg: &mockCfgClient{
+					ListFn: func(ctx context.Context, account string) (*configurations.ConfigurationListResponse, error) {
+						return &configurations.ConfigurationListResponse{
+							Configurations: []configurations.ConfigurationResponse{
+								{Name: pointer.String("cfg1"), LatestVersion: pointer.String("v2")},
+								{Name: pointer.String("cfg2"), LatestVersion: pointer.String("v9")},
+							},
+						}, nil
+					},
+				},
+			},
+			want: want{
+				resp: []*controlplane.Response{
+					ctpVersionedResp("v2"),
+				},
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -332,6 +525,17 @@ func TestConvert(t *testing.T) {
 				resp: ctp1Resp,
 			},
 		},
+		"ConfigurationVersions": {
+			reason: "The current and desired versions of the configuration should be returned.",
+			args: args{
+				ctp: &controlplanes.ControlPlaneResponse{
+					ControlPlane: ctpVersioned,
+				},
+			},
+			want: want{
+				resp: ctpVersionedResp(""),
+			},
+		},
 		"ConfigurationNotAssociated": {
 			reason: "If a configuration is not associated with the control plane, response has n/a for configuration name and status.",
 			args: args{
@@ -365,3 +569,80 @@ func TestConvert(t *testing.T) {
 		})
 	}
 }
+
+func TestReplay(t *testing.T) {
+	cfg := up.NewConfig(func(conf *up.Config) {
+		conf.Client = up.NewClient(func(u *up.HTTPClient) {
+			u.HTTP = &http.Client{Transport: mocks.RecordOrReplay(t, "testdata/controlplanes.json")}
+			u.UserAgent = "up-cli"
+		})
+	})
+	c := New(controlplanes.NewClient(cfg), nil, acct)
+	replaySyncedAt := time.Date(2023, 4, 5, 18, 30, 12, 0, time.UTC)
+
+	got, err := c.Get(context.Background(), "ctp1")
+	if err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	want := &controlplane.Response{
+		ID:                "00000000-0000-0000-0000-000000000000",
+		Name:              "ctp1",
+		Status:            string(controlplanes.StatusReady),
+		Cfg:               "cfg1",
+		CfgStatus:         string(controlplanes.ConfigurationReady),
+		CfgCurrentVersion: "v0.1.0",
+		CfgDesiredVersion: "v0.1.0",
+		CfgSyncedAt:       &replaySyncedAt,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Get(...): -want, +got:\n%s", diff)
+	}
+
+	_, err = c.Get(context.Background(), "ctp-dne")
+	if diff := cmp.Diff(controlplane.NewNotFound(errors.New(`Not Found: control plane "ctp-dne" not found`)), err, test.EquateErrors()); diff != "" {
+		t.Errorf("Get(...): -want error, +got error:\n%s", diff)
+	}
+
+	list, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("List(...): unexpected error: %v", err)
+	}
+	// The recorded list response does not include when ctp1 was synced.
+	wantCtp1 := *want
+	wantCtp1.CfgSyncedAt = nil
+	wantList := []*controlplane.Response{
+		&wantCtp1,
+		{
+			ID:        "00000000-0000-0000-0000-000000000001",
+			Name:      "ctp2",
+			Status:    string(controlplanes.StatusProvisioning),
+			Cfg:       notAvailable,
+			CfgStatus: notAvailable,
+		},
+	}
+	if diff := cmp.Diff(wantList, list); diff != "" {
+		t.Errorf("List(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetKubeConfigTokenStore(t *testing.T) {
+	store := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+	key := tokenstore.TokenKey("default")
+	proxy, _ := url.Parse("https://proxy.upbound.io/v1/controlPlanes")
+
+	c := New(nil, nil, acct, WithProxyEndpoint(proxy), WithTokenStore(store, key))
+	if _, err := c.GetKubeConfig(context.Background(), "ctp"); !tokenstore.IsNotFound(err) {
+		t.Fatalf("GetKubeConfig(...): want not found error, got %v", err)
+	}
+
+	if err := store.Set(key, "stored-pat"); err != nil {
+		t.Fatalf("Set(...): unexpected error: %v", err)
+	}
+	conf, err := c.GetKubeConfig(context.Background(), "ctp")
+	if err != nil {
+		t.Fatalf("GetKubeConfig(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("stored-pat", conf.AuthInfos[conf.CurrentContext].Token); diff != "" {
+		t.Errorf("GetKubeConfig(...): -want token, +got token:\n%s", diff)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+	"github.com/upbound/up-sdk-go/service/accounts"
+	"github.com/upbound/up-sdk-go/service/common"
+	"github.com/upbound/up-sdk-go/service/controlplanes"
+
+	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/telemetry"
+)
+
+const (
+	ctpPath      = "v1/controlPlanes"
+	transferPath = "transfer"
+
+	errTransferNotConfigured = "control plane transfer is not configured"
+	errFmtSameAccount        = "control plane is already in account %q"
+	errFmtGetTargetAccount   = "cannot get target account %q"
+	errFmtTargetConfig       = "configuration %q does not exist in target account %q"
+	errFmtCountControlPlanes = "cannot count control planes in target account %q"
+	errTransfer              = "cannot transfer control plane"
+)
+
+type accountGetter interface {
+	Get(ctx context.Context, name string) (*accounts.AccountResponse, error)
+}
+
+type transferParameters struct {
+	Account string `json:"account"`
+}
+
+// Transfer moves the ControlPlane corresponding to the given name to the
+// target account. The configuration of the control plane must exist in the
+// target account, and the target account must have capacity for another
+// control plane.
+func (c *Client) Transfer(ctx context.Context, name, target string) (_ *controlplane.Response, err error) { //nolint:gocyclo
+	defer telemetry.Observe(c.telemetry, "controlplane transfer", time.Now(), &err)
+	if c.api == nil || c.accts == nil {
+		return nil, errors.New(errTransferNotConfigured)
+	}
+	if target == c.account {
+		return nil, errors.Errorf(errFmtSameAccount, target)
+	}
+
+	src, err := c.ctp.Get(ctx, c.account, name)
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	acct, err := c.accts.Get(ctx, target)
+	if err != nil {
+		return nil, errors.Wrapf(classify(err), errFmtGetTargetAccount, target)
+	}
+
+	if cfg := src.ControlPlane.Configuration.Name; cfg != nil && *cfg != "" {
+		if _, err := c.cfg.Get(ctx, target, *cfg); err != nil {
+			return nil, errors.Wrapf(classify(err), errFmtTargetConfig, *cfg, target)
+		}
+	}
+
+	if org := acct.Organization; org != nil && org.ReservedEnvironments > 0 {
+		l, err := c.ctp.List(ctx, target, common.WithSize(maxItems))
+		if err != nil {
+			return nil, errors.Wrapf(classify(err), errFmtCountControlPlanes, target)
+		}
+		n := l.Count
+		if n < len(l.ControlPlanes) {
+			n = len(l.ControlPlanes)
+		}
+		if n >= org.ReservedEnvironments {
+			return nil, controlplane.NewCapacityExceeded(target, org.ReservedEnvironments)
+		}
+	}
+
+	c.log.Debug("Transferring control plane", "controlplane", name, "from", c.account, "to", target)
+	req, err := c.api.NewRequest(ctx, http.MethodPost, ctpPath, path.Join(c.account, name, transferPath), &transferParameters{Account: target})
+	if err != nil {
+		return nil, errors.Wrap(err, errTransfer)
+	}
+	resp := &controlplanes.ControlPlaneResponse{}
+	if err := c.api.Do(req, resp); err != nil {
+		return nil, errors.Wrap(classify(err), errTransfer)
+	}
+	return convert(resp), nil
+}
+
+// classify wraps Upbound API errors as typed control plane errors.
+func classify(err error) error {
+	if sdkerrs.IsNotFound(err) {
+		return controlplane.NewNotFound(err)
+	}
+	var serr *sdkerrs.Error
+	if errors.As(err, &serr) && (serr.Status == http.StatusForbidden || serr.Status == http.StatusUnauthorized) {
+		return controlplane.NewPermissionDenied(err)
+	}
+	return err
+}
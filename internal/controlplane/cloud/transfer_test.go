@@ -0,0 +1,237 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+	"github.com/upbound/up-sdk-go/service/accounts"
+	"github.com/upbound/up-sdk-go/service/common"
+	"github.com/upbound/up-sdk-go/service/configurations"
+	"github.com/upbound/up-sdk-go/service/controlplanes"
+	"github.com/upbound/up-sdk-go/service/organizations"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+// request is a request sent through mockAPI.
+type request struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+type mockAPI struct {
+	reqs []request
+	// resp is encoded as JSON and decoded into the response object.
+	resp any
+	err  error
+}
+
+func (m *mockAPI) NewRequest(ctx context.Context, method, prefix, urlPath string, body interface{}) (*http.Request, error) {
+	r := request{Method: method, Path: prefix + "/" + urlPath}
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = string(b)
+	}
+	m.reqs = append(m.reqs, r)
+	return http.NewRequestWithContext(ctx, method, "https://api.upbound.io/"+r.Path, nil)
+}
+
+func (m *mockAPI) Do(_ *http.Request, obj interface{}) error {
+	if m.err != nil {
+		return m.err
+	}
+	if obj == nil || m.resp == nil {
+		return nil
+	}
+	b, err := json.Marshal(m.resp)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, obj)
+}
+
+type mockAccounts struct {
+	resp *accounts.AccountResponse
+	err  error
+}
+
+func (m *mockAccounts) Get(_ context.Context, _ string) (*accounts.AccountResponse, error) {
+	return m.resp, m.err
+}
+
+func TestTransfer(t *testing.T) {
+	forbidden := &sdkerrs.Error{Status: http.StatusForbidden, Title: http.StatusText(http.StatusForbidden)}
+	org := func(reserved int) *accounts.AccountResponse {
+		return &accounts.AccountResponse{
+			Account:      accounts.Account{Name: "target", Type: accounts.AccountOrganization},
+			Organization: &organizations.Organization{Name: "target", ReservedEnvironments: reserved},
+		}
+	}
+	ctps := func(account string, n int) func(context.Context, string, ...common.ListOption) (*controlplanes.ControlPlaneListResponse, error) {
+		return func(_ context.Context, a string, _ ...common.ListOption) (*controlplanes.ControlPlaneListResponse, error) {
+			if a != account {
+				return nil, sdkNotFound
+			}
+			return &controlplanes.ControlPlaneListResponse{ControlPlanes: make([]controlplanes.ControlPlaneResponse, n), Count: n}, nil
+		}
+	}
+	getCtp1 := func(_ context.Context, _, name string) (*controlplanes.ControlPlaneResponse, error) {
+		if name != "ctp1" {
+			return nil, sdkNotFound
+		}
+		return &controlplanes.ControlPlaneResponse{ControlPlane: ctp1}, nil
+	}
+	cfgIn := func(account string) *mockCfgClient {
+		return &mockCfgClient{
+			GetFn: func(_ context.Context, a, _ string) (*configurations.ConfigurationResponse, error) {
+				if a != account {
+					return nil, sdkNotFound
+				}
+				return &configurations.ConfigurationResponse{}, nil
+			},
+		}
+	}
+
+	type args struct {
+		ctp    ctpClient
+		cfg    cfgGetter
+		accts  accountGetter
+		api    *mockAPI
+		name   string
+		target string
+	}
+	type want struct {
+		resp *controlplane.Response
+		reqs []request
+		// check classifies the error, since typed errors wrap SDK errors.
+		check func(error) bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotConfigured": {
+			reason: "Transfers should fail if no API client was supplied.",
+			args:   args{name: "ctp1", target: "target"},
+			want:   want{check: func(err error) bool { return err != nil && err.Error() == errTransferNotConfigured }},
+		},
+		"SameAccount": {
+			reason: "Transferring to the source account should fail.",
+			args:   args{accts: &mockAccounts{}, api: &mockAPI{}, name: "ctp1", target: acct},
+			want:   want{check: func(err error) bool { return err != nil }},
+		},
+		"ControlPlaneNotFound": {
+			reason: "A missing control plane should return a not found error.",
+			args: args{
+				ctp:    &mockCTPClient{GetFn: getCtp1},
+				accts:  &mockAccounts{resp: org(0)},
+				api:    &mockAPI{},
+				name:   "ctp-dne",
+				target: "target",
+			},
+			want: want{check: controlplane.IsNotFound},
+		},
+		"ConfigurationMissing": {
+			reason: "A configuration missing from the target account should return a not found error.",
+			args: args{
+				ctp:    &mockCTPClient{GetFn: getCtp1},
+				cfg:    cfgIn(acct),
+				accts:  &mockAccounts{resp: org(0)},
+				api:    &mockAPI{},
+				name:   "ctp1",
+				target: "target",
+			},
+			want: want{check: controlplane.IsNotFound},
+		},
+		"CapacityExceeded": {
+			reason: "A target organization at its control plane limit should return a capacity exceeded error.",
+			args: args{
+				ctp:    &mockCTPClient{GetFn: getCtp1, ListFn: ctps("target", 2)},
+				cfg:    cfgIn("target"),
+				accts:  &mockAccounts{resp: org(2)},
+				api:    &mockAPI{},
+				name:   "ctp1",
+				target: "target",
+			},
+			want: want{check: controlplane.IsCapacityExceeded},
+		},
+		"PermissionDenied": {
+			reason: "A forbidden transfer should return a permission denied error.",
+			args: args{
+				ctp:    &mockCTPClient{GetFn: getCtp1, ListFn: ctps("target", 1)},
+				cfg:    cfgIn("target"),
+				accts:  &mockAccounts{resp: org(2)},
+				api:    &mockAPI{err: forbidden},
+				name:   "ctp1",
+				target: "target",
+			},
+			want: want{
+				reqs:  []request{{Method: http.MethodPost, Path: "v1/controlPlanes/demo/ctp1/transfer", Body: `{"account":"target"}`}},
+				check: controlplane.IsPermissionDenied,
+			},
+		},
+		"Success": {
+			reason: "A valid transfer should be requested and return the transferred control plane.",
+			args: args{
+				ctp:    &mockCTPClient{GetFn: getCtp1},
+				cfg:    cfgIn("target"),
+				accts:  &mockAccounts{resp: &accounts.AccountResponse{Account: accounts.Account{Name: "target", Type: accounts.AccountUser}}},
+				api:    &mockAPI{resp: &controlplanes.ControlPlaneResponse{ControlPlane: ctp1}},
+				name:   "ctp1",
+				target: "target",
+			},
+			want: want{
+				resp:  ctp1Resp,
+				reqs:  []request{{Method: http.MethodPost, Path: "v1/controlPlanes/demo/ctp1/transfer", Body: `{"account":"target"}`}},
+				check: func(err error) bool { return err == nil },
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []Option{}
+			if tc.args.api != nil {
+				opts = append(opts, WithTransfer(tc.args.api, tc.args.accts))
+			}
+			c := New(tc.args.ctp, tc.args.cfg, acct, opts...)
+			got, err := c.Transfer(context.Background(), tc.args.name, tc.args.target)
+
+			if !tc.want.check(err) {
+				t.Errorf("\n%s\nTransfer(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.resp, got); diff != "" {
+				t.Errorf("\n%s\nTransfer(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.args.api != nil {
+				if diff := cmp.Diff(tc.want.reqs, tc.args.api.reqs); diff != "" {
+					t.Errorf("\n%s\nTransfer(...): -want requests, +got requests:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
@@ -14,6 +14,13 @@
 
 package controlplane
 
+import (
+	"context"
+	"time"
+
+	"github.com/upbound/up/internal/controlplane/health"
+)
+
 // Response is a normalized ControlPlane response.
 // NOTE(tnthornton) this is expected to be different in the near future as
 // cloud and spaces APIs converge.
@@ -22,10 +29,34 @@ type Response struct {
 	Name    string
 	Message string
 	Status  string
+	Class   string
 
 	Cfg       string
 	CfgStatus string
+	// CfgCurrentVersion is the version of the configuration the control
+	// plane is running, and CfgDesiredVersion the version it is moving to.
+	CfgCurrentVersion string     `json:",omitempty" yaml:",omitempty"`
+	CfgDesiredVersion string     `json:",omitempty" yaml:",omitempty"`
+	CfgSyncedAt       *time.Time `json:",omitempty" yaml:",omitempty"`
+	// CfgLatestVersion is the latest version of the configuration, if known.
+	CfgLatestVersion string `json:",omitempty" yaml:",omitempty"`
 
 	ConnName      string
 	ConnNamespace string
+
+	// Health is only set if it was requested.
+	Health *health.Summary `json:",omitempty" yaml:",omitempty"`
+}
+
+// CfgUpToDate returns true if the control plane is running the latest version
+// of its configuration, or if the latest version is not known.
+func (r *Response) CfgUpToDate() bool {
+	return r.CfgLatestVersion == "" || r.CfgCurrentVersion == r.CfgLatestVersion
+}
+
+// ChannelManager gets and sets the Crossplane auto-upgrade channel of control
+// planes. Channels are one of None, Patch, Stable, or Rapid.
+type ChannelManager interface {
+	GetChannel(ctx context.Context, name string) (string, error)
+	SetChannel(ctx context.Context, name, channel string) error
 }
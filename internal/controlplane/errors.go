@@ -16,8 +16,11 @@ package controlplane
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/failure"
 )
 
 // notFoundError is an error indicating the resource is not found.
@@ -52,3 +55,150 @@ func IsNotFound(err error) bool {
 	var nferr notFound
 	return errors.As(err, &nferr) && nferr.NotFound()
 }
+
+// permissionDeniedError is an error indicating the caller is not permitted to
+// perform an operation.
+type permissionDeniedError struct {
+	err error
+}
+
+// Error calls the underlying error's Error method.
+func (p *permissionDeniedError) Error() string {
+	return fmt.Sprintf("permission denied: %s", p.err.Error())
+}
+
+// PermissionDenied indicates that this is a permission denied error.
+func (p *permissionDeniedError) PermissionDenied() bool {
+	return true
+}
+
+// NewPermissionDenied wraps an existing error as a permission denied error.
+func NewPermissionDenied(err error) error {
+	return &permissionDeniedError{
+		err: err,
+	}
+}
+
+// permissionDenied indicates the caller is not permitted to perform an
+// operation.
+type permissionDenied interface {
+	PermissionDenied() bool
+}
+
+// IsPermissionDenied checks whether an error implements the permissionDenied
+// interface.
+func IsPermissionDenied(err error) bool {
+	var pderr permissionDenied
+	return errors.As(err, &pderr) && pderr.PermissionDenied()
+}
+
+// capacityExceededError is an error indicating an account cannot hold any
+// more control planes.
+type capacityExceededError struct {
+	account string
+	limit   int
+}
+
+// Error returns a message naming the account and its limit.
+func (c *capacityExceededError) Error() string {
+	return fmt.Sprintf("account %q has reached its limit of %d control planes", c.account, c.limit)
+}
+
+// CapacityExceeded indicates that this is a capacity exceeded error.
+func (c *capacityExceededError) CapacityExceeded() bool {
+	return true
+}
+
+// NewCapacityExceeded returns an error indicating the supplied account has
+// reached its limit of control planes.
+func NewCapacityExceeded(account string, limit int) error {
+	return &capacityExceededError{
+		account: account,
+		limit:   limit,
+	}
+}
+
+// capacityExceeded indicates an account cannot hold any more control planes.
+type capacityExceeded interface {
+	CapacityExceeded() bool
+}
+
+// IsCapacityExceeded checks whether an error implements the capacityExceeded
+// interface.
+func IsCapacityExceeded(err error) bool {
+	var ceerr capacityExceeded
+	return errors.As(err, &ceerr) && ceerr.CapacityExceeded()
+}
+
+// A Conflict is a field of a control plane that another field manager owns.
+type Conflict struct {
+	// Manager is the field manager that owns the field.
+	Manager string
+	// Field is the path of the field, e.g. .spec.class.
+	Field string
+}
+
+// String returns the field and the manager that owns it.
+func (c Conflict) String() string {
+	if c.Manager == "" {
+		return c.Field
+	}
+	return fmt.Sprintf("%s (managed by %s)", c.Field, c.Manager)
+}
+
+// conflictError is an error indicating an update of a control plane conflicts
+// with fields owned by other field managers.
+type conflictError struct {
+	name      string
+	conflicts []Conflict
+}
+
+// Error returns a message listing the conflicting fields and their managers.
+func (c *conflictError) Error() string {
+	fields := make([]string, len(c.conflicts))
+	for i, cf := range c.conflicts {
+		fields[i] = cf.String()
+	}
+	return fmt.Sprintf("control plane %q has conflicting fields: %s", c.name, strings.Join(fields, ", "))
+}
+
+// Kind returns the kind of failure a conflict error is.
+func (c *conflictError) Kind() failure.Kind {
+	return failure.KindConflict
+}
+
+// Conflicts returns the conflicting fields.
+func (c *conflictError) Conflicts() []Conflict {
+	return c.conflicts
+}
+
+// NewConflict returns an error indicating an update of the supplied control
+// plane conflicts with fields owned by other field managers.
+func NewConflict(name string, conflicts []Conflict) error {
+	return &conflictError{
+		name:      name,
+		conflicts: conflicts,
+	}
+}
+
+// conflicted indicates an update conflicts with fields owned by other field
+// managers.
+type conflicted interface {
+	Conflicts() []Conflict
+}
+
+// IsConflict checks whether an error implements the conflicted interface.
+func IsConflict(err error) bool {
+	var cerr conflicted
+	return errors.As(err, &cerr)
+}
+
+// Conflicts returns the conflicting fields of a conflict error, or nil if the
+// error is not a conflict error.
+func Conflicts(err error) []Conflict {
+	var cerr conflicted
+	if !errors.As(err, &cerr) {
+		return nil
+	}
+	return cerr.Conflicts()
+}
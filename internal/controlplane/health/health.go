@@ -0,0 +1,290 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health summarizes the health of a control plane.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	// DefaultNamespace is the namespace Crossplane is installed in.
+	DefaultNamespace = "crossplane-system"
+
+	// DefaultRestartThreshold is the number of container restarts above
+	// which a provider pod is considered unhealthy.
+	DefaultRestartThreshold = 5
+
+	labelApp      = "app=crossplane"
+	labelProvider = "pkg.crossplane.io/provider"
+
+	typeInstalled xpv1.ConditionType = "Installed"
+	typeHealthy   xpv1.ConditionType = "Healthy"
+
+	errListDeployments = "cannot list Crossplane deployments"
+	errListPods        = "cannot list provider pods"
+	errFmtListPackages = "cannot list %s"
+)
+
+var packageResources = []schema.GroupVersionResource{
+	{Group: "pkg.crossplane.io", Version: "v1", Resource: "providers"},
+	{Group: "pkg.crossplane.io", Version: "v1", Resource: "configurations"},
+	{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "functions"},
+}
+
+// A Level of health. Levels are ordered; higher levels are worse.
+type Level int
+
+// Levels of health.
+const (
+	Green Level = iota
+	Yellow
+	Red
+)
+
+// String returns the name of the level.
+func (l Level) String() string {
+	switch l {
+	case Green:
+		return "Green"
+	case Yellow:
+		return "Yellow"
+	default:
+		return "Red"
+	}
+}
+
+// MarshalText marshals the level as its name.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// A Finding is a reason a control plane is not fully healthy.
+type Finding struct {
+	Level     Level
+	Component string
+	Reason    string
+}
+
+// String returns a one line description of the finding.
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s: %s", f.Level, f.Component, f.Reason)
+}
+
+// A Summary of the health of a control plane.
+type Summary struct {
+	// Level is the worst level of all findings.
+	Level Level
+	// Score is between 0 and 100, where 100 is fully healthy.
+	Score int
+	// Findings, worst first.
+	Findings []Finding
+}
+
+// Weights by which each finding lowers the score of a summary.
+var weights = map[Level]int{
+	Green:  0,
+	Yellow: 10,
+	Red:    40,
+}
+
+// Summarize the supplied findings.
+func Summarize(findings []Finding) *Summary {
+	s := &Summary{Level: Green, Score: 100, Findings: findings}
+	sort.SliceStable(s.Findings, func(i, j int) bool {
+		return s.Findings[i].Level > s.Findings[j].Level
+	})
+	for _, f := range findings {
+		if f.Level > s.Level {
+			s.Level = f.Level
+		}
+		s.Score -= weights[f.Level]
+	}
+	if s.Score < 0 {
+		s.Score = 0
+	}
+	return s
+}
+
+// Checker checks the health of a control plane.
+type Checker struct {
+	kube      kubernetes.Interface
+	dyn       dynamic.Interface
+	namespace string
+	restarts  int32
+}
+
+// Option modifies a Checker.
+type Option func(*Checker)
+
+// WithNamespace sets the namespace Crossplane and its providers run in.
+func WithNamespace(ns string) Option {
+	return func(c *Checker) {
+		c.namespace = ns
+	}
+}
+
+// WithRestartThreshold sets the number of container restarts above which a
+// provider pod is considered unhealthy.
+func WithRestartThreshold(n int32) Option {
+	return func(c *Checker) {
+		c.restarts = n
+	}
+}
+
+// New returns a Checker for the control plane served by the supplied
+// clients.
+func New(kube kubernetes.Interface, dyn dynamic.Interface, opts ...Option) *Checker {
+	c := &Checker{
+		kube:      kube,
+		dyn:       dyn,
+		namespace: DefaultNamespace,
+		restarts:  DefaultRestartThreshold,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Check the health of Crossplane, its packages and provider pods, and
+// summarize the findings.
+func (c *Checker) Check(ctx context.Context) (*Summary, error) {
+	var findings []Finding
+	for _, check := range []func(context.Context) ([]Finding, error){c.crossplane, c.packages, c.providerPods} {
+		f, err := check(ctx)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, f...)
+	}
+	return Summarize(findings), nil
+}
+
+func (c *Checker) crossplane(ctx context.Context) ([]Finding, error) {
+	l, err := c.kube.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: labelApp})
+	if err != nil {
+		return nil, errors.Wrap(err, errListDeployments)
+	}
+	if len(l.Items) == 0 {
+		return []Finding{{Level: Red, Component: "crossplane", Reason: fmt.Sprintf("no Crossplane deployment found in namespace %s", c.namespace)}}, nil
+	}
+	var findings []Finding
+	for _, d := range l.Items {
+		if f, ok := deploymentFinding(d); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+func deploymentFinding(d appsv1.Deployment) (Finding, bool) {
+	want := int32(1)
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	switch {
+	case d.Status.AvailableReplicas == 0 && want > 0:
+		return Finding{Level: Red, Component: d.GetName(), Reason: "no replicas are available"}, true
+	case d.Status.AvailableReplicas < want:
+		return Finding{Level: Yellow, Component: d.GetName(), Reason: fmt.Sprintf("%d of %d replicas are available", d.Status.AvailableReplicas, want)}, true
+	}
+	return Finding{}, false
+}
+
+func (c *Checker) packages(ctx context.Context) ([]Finding, error) {
+	var findings []Finding
+	for _, gvr := range packageResources {
+		l, err := c.dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		// Older versions of Crossplane do not serve all kinds of package.
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtListPackages, gvr.Resource)
+		}
+		for i := range l.Items {
+			p := &resources.Package{Unstructured: l.Items[i]}
+			component := fmt.Sprintf("%s/%s", p.GetKind(), p.GetName())
+			if cnd := p.GetCondition(typeInstalled); cnd.Status != corev1.ConditionTrue {
+				findings = append(findings, Finding{Level: Red, Component: component, Reason: reason("not installed", cnd)})
+				continue
+			}
+			if cnd := p.GetCondition(typeHealthy); cnd.Status != corev1.ConditionTrue {
+				findings = append(findings, Finding{Level: Yellow, Component: component, Reason: reason("not healthy", cnd)})
+			}
+		}
+	}
+	return findings, nil
+}
+
+func reason(prefix string, cnd xpv1.Condition) string {
+	if cnd.Message != "" {
+		return fmt.Sprintf("%s: %s", prefix, cnd.Message)
+	}
+	if cnd.Reason != "" {
+		return fmt.Sprintf("%s: %s", prefix, cnd.Reason)
+	}
+	return prefix
+}
+
+func (c *Checker) providerPods(ctx context.Context) ([]Finding, error) {
+	l, err := c.kube.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: labelProvider})
+	if err != nil {
+		return nil, errors.Wrap(err, errListPods)
+	}
+	var findings []Finding
+	for _, p := range l.Items {
+		if f, ok := c.podFinding(p); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+func (c *Checker) podFinding(p corev1.Pod) (Finding, bool) {
+	component := p.GetLabels()[labelProvider]
+	if component == "" {
+		component = p.GetName()
+	}
+	for _, cs := range p.Status.ContainerStatuses {
+		if w := cs.State.Waiting; w != nil && w.Reason == "CrashLoopBackOff" {
+			return Finding{Level: Red, Component: component, Reason: fmt.Sprintf("pod %s is crash looping", p.GetName())}, true
+		}
+		if cs.RestartCount > c.restarts {
+			return Finding{Level: Yellow, Component: component, Reason: fmt.Sprintf("pod %s restarted %d times", p.GetName(), cs.RestartCount)}, true
+		}
+	}
+	for _, cnd := range p.Status.Conditions {
+		if cnd.Type == corev1.PodReady && cnd.Status != corev1.ConditionTrue {
+			return Finding{Level: Yellow, Component: component, Reason: fmt.Sprintf("pod %s is not ready", p.GetName())}, true
+		}
+	}
+	return Finding{}, false
+}
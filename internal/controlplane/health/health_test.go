@@ -0,0 +1,128 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func crossplane(available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "crossplane", Namespace: DefaultNamespace, Labels: map[string]string{"app": "crossplane"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(2)},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+	}
+}
+
+func providerPod(name string, restarts int32, waiting string) *corev1.Pod {
+	cs := corev1.ContainerStatus{RestartCount: restarts}
+	if waiting != "" {
+		cs.State.Waiting = &corev1.ContainerStateWaiting{Reason: waiting}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: DefaultNamespace, Labels: map[string]string{labelProvider: "provider-aws"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{cs},
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func pkg(kind, name string, installed, healthy corev1.ConditionStatus, msg string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("pkg.crossplane.io/v1")
+	u.SetKind(kind)
+	u.SetName(name)
+	u.Object["status"] = map[string]any{
+		"conditions": []any{
+			map[string]any{"type": "Installed", "status": string(installed), "message": msg},
+			map[string]any{"type": "Healthy", "status": string(healthy), "message": msg},
+		},
+	}
+	return u
+}
+
+func TestCheck(t *testing.T) {
+	type args struct {
+		kube []runtime.Object
+		dyn  []runtime.Object
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   *Summary
+	}{
+		"Healthy": {
+			reason: "A control plane without findings should be green with a full score.",
+			args: args{
+				kube: []runtime.Object{crossplane(2), providerPod("provider-aws-1", 0, "")},
+				dyn:  []runtime.Object{pkg("Provider", "provider-aws", corev1.ConditionTrue, corev1.ConditionTrue, "")},
+			},
+			want: &Summary{Level: Green, Score: 100},
+		},
+		"NoCrossplane": {
+			reason: "A control plane without a Crossplane deployment should be red.",
+			want: &Summary{Level: Red, Score: 60, Findings: []Finding{
+				{Level: Red, Component: "crossplane", Reason: "no Crossplane deployment found in namespace crossplane-system"},
+			}},
+		},
+		"Degraded": {
+			reason: "Findings should be scored, and sorted worst first.",
+			args: args{
+				kube: []runtime.Object{crossplane(1), providerPod("provider-aws-1", 0, "CrashLoopBackOff"), providerPod("provider-aws-2", 10, "")},
+				dyn: []runtime.Object{
+					pkg("Provider", "provider-aws", corev1.ConditionTrue, corev1.ConditionFalse, "unhealthy revision"),
+					pkg("Configuration", "platform", corev1.ConditionFalse, corev1.ConditionFalse, "cannot resolve dependencies"),
+				},
+			},
+			want: &Summary{Level: Red, Score: 0, Findings: []Finding{
+				{Level: Red, Component: "Configuration/platform", Reason: "not installed: cannot resolve dependencies"},
+				{Level: Red, Component: "provider-aws", Reason: "pod provider-aws-1 is crash looping"},
+				{Level: Yellow, Component: "crossplane", Reason: "1 of 2 replicas are available"},
+				{Level: Yellow, Component: "Provider/provider-aws", Reason: "not healthy: unhealthy revision"},
+				{Level: Yellow, Component: "provider-aws", Reason: "pod provider-aws-2 restarted 10 times"},
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				packageResources[0]: "ProviderList",
+				packageResources[1]: "ConfigurationList",
+				packageResources[2]: "FunctionList",
+			}, tc.args.dyn...)
+			got, err := New(fake.NewSimpleClientset(tc.args.kube...), dyn).Check(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\nCheck(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -23,4 +23,16 @@ type Options struct {
 	Description string
 
 	ConfigurationName string
+
+	// Crossplane version, only applicable to Space control planes.
+	CrossplaneVersion string
+	// Crossplane auto-upgrade channel, only applicable to Space control
+	// planes.
+	CrossplaneChannel string
+	// Class of the control plane, e.g. small or dedicated, which determines
+	// the resources allocated to it. Only applicable to Space control
+	// planes; Upbound Cloud rejects it.
+	Class string
+	// Labels of the control plane, only applicable to Space control planes.
+	Labels map[string]string
 }
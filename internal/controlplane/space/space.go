@@ -16,44 +16,127 @@ package space
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"time"
 
 	xpcommonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
-	corev1 "k8s.io/api/core/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 
+	"github.com/upbound/up/internal/capability"
 	"github.com/upbound/up/internal/controlplane"
 	"github.com/upbound/up/internal/resources"
+	"github.com/upbound/up/internal/telemetry"
 )
 
+// FieldManager is the default field manager of control planes applied by the
+// Client.
+const FieldManager = "up-cli"
+
 var (
 	resource      = resources.ControlPlaneGVK.GroupVersion().WithResource("controlplanes")
 	kubeconfigFmt = "kubeconfig-%s"
+
+	// conflictManagerRE extracts the field manager from the message of a
+	// field manager conflict, e.g. `conflict with "kubectl" using v1`.
+	conflictManagerRE = regexp.MustCompile(`conflict with "([^"]*)"`)
 )
 
 // Client is the client used for interacting with the ControlPlanes API in an
 // Upbound Space.
 type Client struct {
-	c dynamic.Interface
+	c         dynamic.Interface
+	log       logging.Logger
+	telemetry telemetry.Recorder
+	resource  schema.GroupVersionResource
+	err       error
+
+	fieldManager string
+	force        bool
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithLogger sets the logger used by the Client.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// WithTelemetry sets the Recorder operations of the Client are recorded with.
+// Operations are recorded with the telemetry.DefaultRecorder by default.
+func WithTelemetry(r telemetry.Recorder) Option {
+	return func(c *Client) {
+		c.telemetry = r
+	}
+}
+
+// WithFieldManager sets the field manager that owns the fields of control
+// planes created and applied by the Client.
+func WithFieldManager(name string) Option {
+	return func(c *Client) {
+		c.fieldManager = name
+	}
+}
+
+// WithForceConflicts makes Apply take ownership of fields owned by other field
+// managers rather than fail with a conflict error.
+func WithForceConflicts() Option {
+	return func(c *Client) {
+		c.force = true
+	}
+}
+
+// WithCapabilities adapts the Client to the capabilities of the Space,
+// falling back to older ControlPlane API versions where necessary. Every
+// request fails if the Space does not serve ControlPlanes at all.
+func WithCapabilities(caps *capability.Capabilities) Option {
+	return func(c *Client) {
+		for _, v := range []string{"v1beta1", "v1alpha1"} {
+			gvr := resource.GroupResource().WithVersion(v)
+			if caps.Serves(gvr.GroupVersion()) {
+				c.resource = gvr
+				return
+			}
+		}
+		c.err = caps.Require(capability.FeatureControlPlanes)
+	}
 }
 
 // New instantiates a new Client.
-func New(c dynamic.Interface) *Client {
-	return &Client{
-		c: c,
+func New(c dynamic.Interface, opts ...Option) *Client {
+	cl := &Client{
+		c:            c,
+		log:          logging.NewNopLogger(),
+		telemetry:    telemetry.Deferred,
+		resource:     resource,
+		fieldManager: FieldManager,
+	}
+	for _, o := range opts {
+		o(cl)
 	}
+	return cl
 }
 
 // Get the ControlPlane corresponding to the given ControlPlane name.
-func (c *Client) Get(ctx context.Context, name string) (*controlplane.Response, error) {
+func (c *Client) Get(ctx context.Context, name string) (_ *controlplane.Response, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane get", time.Now(), &err)
+	if c.err != nil {
+		return nil, c.err
+	}
 	u, err := c.c.
-		Resource(resource).
+		Resource(c.resource).
 		Get(
 			ctx,
 			name,
@@ -71,9 +154,13 @@ func (c *Client) Get(ctx context.Context, name string) (*controlplane.Response,
 }
 
 // List all ControlPlanes within the Space.
-func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
+func (c *Client) List(ctx context.Context) (_ []*controlplane.Response, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane list", time.Now(), &err)
+	if c.err != nil {
+		return nil, c.err
+	}
 	list, err := c.c.
-		Resource(resource).
+		Resource(c.resource).
 		List(
 			ctx,
 			metav1.ListOptions{},
@@ -82,6 +169,7 @@ func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
 		return nil, err
 	}
 
+	c.log.Debug("Listed control planes", "count", len(list.Items))
 	resps := []*controlplane.Response{}
 	for _, u := range list.Items {
 		resps = append(resps, convert(&resources.ControlPlane{Unstructured: u}))
@@ -90,8 +178,44 @@ func (c *Client) List(ctx context.Context) ([]*controlplane.Response, error) {
 	return resps, nil
 }
 
-// Create a new ControlPlane with the given name and the supplied Options.
-func (c *Client) Create(ctx context.Context, name string, opts controlplane.Options) (*controlplane.Response, error) {
+// Create a new ControlPlane with the given name and the supplied Options. The
+// control plane is server-side applied, so the Client's field manager owns the
+// fields it sets. Create fails if the control plane already exists.
+func (c *Client) Create(ctx context.Context, name string, opts controlplane.Options) (_ *controlplane.Response, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane create", time.Now(), &err)
+	if c.err != nil {
+		return nil, c.err
+	}
+	_, err = c.c.Resource(c.resource).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return nil, kerrors.NewAlreadyExists(c.resource.GroupResource(), name)
+	}
+	if !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+	return c.apply(ctx, name, opts)
+}
+
+// CreateFromTemplate creates a new ControlPlane with the given name and the
+// parameters of the supplied Template.
+func (c *Client) CreateFromTemplate(ctx context.Context, name string, t *controlplane.Template) (*controlplane.Response, error) {
+	return c.Create(ctx, name, t.Options())
+}
+
+// Apply creates the ControlPlane with the given name and the supplied Options,
+// or updates it if it already exists. Only the fields set by the Options are
+// owned by the Client's field manager. Updating a field owned by another field
+// manager fails with a conflict error listing the conflicting managers, unless
+// the Client forces conflicts.
+func (c *Client) Apply(ctx context.Context, name string, opts controlplane.Options) (_ *controlplane.Response, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane apply", time.Now(), &err)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.apply(ctx, name, opts)
+}
+
+func (c *Client) apply(ctx context.Context, name string, opts controlplane.Options) (*controlplane.Response, error) {
 	o := calculateSecret(name, opts)
 
 	ctp := &resources.ControlPlane{}
@@ -100,14 +224,34 @@ func (c *Client) Create(ctx context.Context, name string, opts controlplane.Opti
 		Name:      o.SecretName,
 		Namespace: o.SecretNamespace,
 	})
+	if o.CrossplaneVersion != "" {
+		ctp.SetCrossplaneVersion(o.CrossplaneVersion)
+	}
+	if o.CrossplaneChannel != "" {
+		ctp.SetCrossplaneChannel(resources.CrossplaneChannel(o.CrossplaneChannel))
+	}
+	if o.Class != "" {
+		ctp.SetClass(o.Class)
+	}
+	if len(o.Labels) > 0 {
+		ctp.SetLabels(o.Labels)
+	}
+	u := ctp.GetUnstructured()
+	u.SetAPIVersion(c.resource.GroupVersion().String())
+	u.SetKind(resources.ControlPlaneGVK.Kind)
 
+	c.log.Debug("Applying control plane", "controlplane", name, "fieldManager", c.fieldManager, "force", c.force)
 	u, err := c.c.
-		Resource(resource).
-		Create(
+		Resource(c.resource).
+		Apply(
 			ctx,
-			ctp.GetUnstructured(),
-			metav1.CreateOptions{},
+			name,
+			u,
+			metav1.ApplyOptions{FieldManager: c.fieldManager, Force: c.force},
 		)
+	if kerrors.IsConflict(err) {
+		return nil, conflictError(name, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -115,10 +259,37 @@ func (c *Client) Create(ctx context.Context, name string, opts controlplane.Opti
 	return convert(&resources.ControlPlane{Unstructured: *u}), nil
 }
 
+// conflictError converts a field manager conflict returned by the API server
+// into a conflict error listing the conflicting fields and managers.
+func conflictError(name string, err error) error {
+	var conflicts []controlplane.Conflict
+	var status kerrors.APIStatus
+	if errors.As(err, &status) && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			if cause.Type != metav1.CauseTypeFieldManagerConflict {
+				continue
+			}
+			cf := controlplane.Conflict{Field: cause.Field}
+			if m := conflictManagerRE.FindStringSubmatch(cause.Message); m != nil {
+				cf.Manager = m[1]
+			}
+			conflicts = append(conflicts, cf)
+		}
+	}
+	if len(conflicts) == 0 {
+		return err
+	}
+	return controlplane.NewConflict(name, conflicts)
+}
+
 // Delete the ControlPlane corresponding to the given ControlPlane name.
-func (c *Client) Delete(ctx context.Context, name string) error {
-	err := c.c.
-		Resource(resource).
+func (c *Client) Delete(ctx context.Context, name string) (err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane delete", time.Now(), &err)
+	if c.err != nil {
+		return c.err
+	}
+	err = c.c.
+		Resource(c.resource).
 		Delete(
 			ctx,
 			name,
@@ -131,8 +302,74 @@ func (c *Client) Delete(ctx context.Context, name string) error {
 	return err
 }
 
+var _ controlplane.ChannelManager = &Client{}
+
+// GetChannel returns the Crossplane auto-upgrade channel of the ControlPlane
+// corresponding to the given name.
+func (c *Client) GetChannel(ctx context.Context, name string) (_ string, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane get channel", time.Now(), &err)
+	if c.err != nil {
+		return "", c.err
+	}
+	u, err := c.c.
+		Resource(c.resource).
+		Get(
+			ctx,
+			name,
+			metav1.GetOptions{},
+		)
+	if kerrors.IsNotFound(err) {
+		return "", controlplane.NewNotFound(err)
+	}
+	if err != nil {
+		return "", err
+	}
+	ctp := &resources.ControlPlane{Unstructured: *u}
+	return string(ctp.GetCrossplaneChannel()), nil
+}
+
+// SetChannel sets the Crossplane auto-upgrade channel of the ControlPlane
+// corresponding to the given name.
+func (c *Client) SetChannel(ctx context.Context, name, channel string) (err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane set channel", time.Now(), &err)
+	if c.err != nil {
+		return c.err
+	}
+	ch, err := resources.ParseCrossplaneChannel(channel)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"crossplane": map[string]any{
+				"autoUpgrade": map[string]any{
+					"channel": string(ch),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	c.log.Debug("Setting auto-upgrade channel", "controlplane", name, "channel", ch)
+	_, err = c.c.
+		Resource(c.resource).
+		Patch(
+			ctx,
+			name,
+			types.MergePatchType,
+			patch,
+			metav1.PatchOptions{},
+		)
+	if kerrors.IsNotFound(err) {
+		return controlplane.NewNotFound(err)
+	}
+	return err
+}
+
 // GetKubeConfig for the given Control Plane.
-func (c *Client) GetKubeConfig(ctx context.Context, name string) (*api.Config, error) {
+func (c *Client) GetKubeConfig(ctx context.Context, name string) (_ *api.Config, err error) {
+	defer telemetry.Observe(c.telemetry, "controlplane kubeconfig", time.Now(), &err)
 
 	// get the control plane
 	r, err := c.Get(ctx, name)
@@ -141,6 +378,7 @@ func (c *Client) GetKubeConfig(ctx context.Context, name string) (*api.Config, e
 	}
 
 	// get the corresponding kubeconfig secret
+	c.log.Debug("Reading control plane kubeconfig", "controlplane", name, "namespace", r.ConnNamespace, "secret", r.ConnName)
 	u, err := c.c.
 		Resource(schema.GroupVersionResource{
 			Group:    "",
@@ -156,14 +394,17 @@ func (c *Client) GetKubeConfig(ctx context.Context, name string) (*api.Config, e
 	if kerrors.IsNotFound(err) {
 		return nil, controlplane.NewNotFound(err)
 	}
+	if err != nil {
+		return nil, err
+	}
 
-	// marshal into secret
-	var s corev1.Secret
-	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &s); err != nil {
+	kubeconfig, err := resources.GetBytes(u.Object, "data.kubeconfig")
+	if err != nil {
+		c.log.Info("Control plane connection secret has no kubeconfig", "controlplane", name, "error", err)
 		return nil, err
 	}
 
-	return clientcmd.Load(s.Data["kubeconfig"])
+	return clientcmd.Load(kubeconfig)
 }
 
 func convert(ctp *resources.ControlPlane) *controlplane.Response {
@@ -178,6 +419,7 @@ func convert(ctp *resources.ControlPlane) *controlplane.Response {
 		Name:          ctp.GetName(),
 		Message:       cnd.Message,
 		Status:        string(cnd.Reason),
+		Class:         ctp.GetClass(),
 		ConnName:      ref.Name,
 		ConnNamespace: ref.Namespace,
 	}
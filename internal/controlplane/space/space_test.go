@@ -22,16 +22,21 @@ import (
 	xpcommonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
 	cgotesting "k8s.io/client-go/testing"
 
+	"github.com/upbound/up/internal/capability"
 	"github.com/upbound/up/internal/controlplane"
 	"github.com/upbound/up/internal/resources"
+	"github.com/upbound/up/internal/telemetry"
 )
 
 var (
@@ -118,6 +123,397 @@ func TestGet(t *testing.T) {
 	}
 }
 
+// withApply makes the fake client handle server-side apply patches by
+// creating the object if it does not exist and merging the patch into it
+// otherwise.
+func withApply(c *fake.FakeDynamicClient) *fake.FakeDynamicClient {
+	c.PrependReactor("patch", ctpresource, func(action cgotesting.Action) (bool, runtime.Object, error) {
+		pa, ok := action.(cgotesting.PatchAction)
+		if !ok || pa.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		gvr := pa.GetResource()
+		patch := &unstructured.Unstructured{}
+		if err := patch.UnmarshalJSON(pa.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		existing, err := c.Tracker().Get(gvr, pa.GetNamespace(), pa.GetName())
+		if kerrors.IsNotFound(err) {
+			return true, patch, c.Tracker().Create(gvr, patch, pa.GetNamespace())
+		}
+		if err != nil {
+			return true, nil, err
+		}
+		u := existing.(*unstructured.Unstructured).DeepCopy()
+		merge(u.Object, patch.Object)
+		return true, u, c.Tracker().Update(gvr, u, pa.GetNamespace())
+	})
+	return c
+}
+
+// merge recursively merges src into dst.
+func merge(dst, src map[string]any) {
+	for k, v := range src {
+		sm, ok := v.(map[string]any)
+		dm, dok := dst[k].(map[string]any)
+		if ok && dok {
+			merge(dm, sm)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+func TestCreate(t *testing.T) {
+	existing := &resources.ControlPlane{}
+	existing.SetGroupVersionKind(resources.ControlPlaneGVK)
+	existing.SetName("ctp1")
+	_ = unstructured.SetNestedField(existing.Object, "large", "spec", "class")
+
+	type args struct {
+		objs []runtime.Object
+		name string
+		opts controlplane.Options
+	}
+	type want struct {
+		spec map[string]any
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Defaults": {
+			reason: "Only the connection secret should be set if no other options are supplied.",
+			args: args{
+				name: "ctp1",
+				opts: controlplane.Options{SecretNamespace: "default"},
+			},
+			want: want{
+				spec: map[string]any{
+					"writeConnectionSecretToRef": map[string]any{
+						"name":      "kubeconfig-ctp1",
+						"namespace": "default",
+					},
+				},
+			},
+		},
+		"CrossplaneAndClass": {
+			reason: "The Crossplane version, auto-upgrade channel, and class should be set if supplied.",
+			args: args{
+				name: "ctp1",
+				opts: controlplane.Options{
+					SecretName:        "secret",
+					SecretNamespace:   "default",
+					CrossplaneVersion: "1.14.1-up.1",
+					CrossplaneChannel: "Stable",
+					Class:             "small",
+				},
+			},
+			want: want{
+				spec: map[string]any{
+					"writeConnectionSecretToRef": map[string]any{
+						"name":      "secret",
+						"namespace": "default",
+					},
+					"crossplane": map[string]any{
+						"version": "1.14.1-up.1",
+						"autoUpgrade": map[string]any{
+							"channel": "Stable",
+						},
+					},
+					"class": "small",
+				},
+			},
+		},
+		"AlreadyExists": {
+			reason: "Creating a control plane that already exists should fail.",
+			args: args{
+				objs: []runtime.Object{existing.GetUnstructured()},
+				name: "ctp1",
+				opts: controlplane.Options{SecretNamespace: "default", Class: "small"},
+			},
+			want: want{
+				spec: map[string]any{
+					"class": "large",
+				},
+				err: kerrors.NewAlreadyExists(resource.GroupResource(), "ctp1"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := withApply(fake.NewSimpleDynamicClient(scheme, tc.args.objs...))
+			_, err := New(client).Create(context.Background(), tc.args.name, tc.args.opts)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			u, err := client.Resource(resource).Get(context.Background(), tc.args.name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("\n%s\nGet(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.spec, u.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateFromTemplate(t *testing.T) {
+	tmpl := &controlplane.Template{
+		Name:   "small-dev",
+		Class:  "small",
+		Secret: controlplane.TemplateSecret{Namespace: "ctp-secrets"},
+		Labels: map[string]string{"env": "dev"},
+	}
+
+	client := withApply(fake.NewSimpleDynamicClient(scheme))
+	if _, err := New(client).CreateFromTemplate(context.Background(), "ctp1", tmpl); err != nil {
+		t.Fatalf("CreateFromTemplate(...): unexpected error: %v", err)
+	}
+
+	u, err := client.Resource(resource).Get(context.Background(), "ctp1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	wantSpec := map[string]any{
+		"writeConnectionSecretToRef": map[string]any{
+			"name":      "kubeconfig-ctp1",
+			"namespace": "ctp-secrets",
+		},
+		"class": "small",
+	}
+	if diff := cmp.Diff(wantSpec, u.Object["spec"]); diff != "" {
+		t.Errorf("CreateFromTemplate(...): -want spec, +got spec:\n%s", diff)
+	}
+	if diff := cmp.Diff(tmpl.Labels, u.GetLabels()); diff != "" {
+		t.Errorf("CreateFromTemplate(...): -want labels, +got labels:\n%s", diff)
+	}
+}
+
+func TestApply(t *testing.T) {
+	existing := &resources.ControlPlane{}
+	existing.SetGroupVersionKind(resources.ControlPlaneGVK)
+	existing.SetName("ctp1")
+	_ = unstructured.SetNestedField(existing.Object, "large", "spec", "class")
+
+	conflict := kerrors.NewApplyConflict([]metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldManagerConflict,
+		Field:   ".spec.class",
+		Message: `conflict with "kubectl-edit" using spaces.upbound.io/v1beta1`,
+	}}, "Apply failed with 1 conflict")
+
+	type args struct {
+		objs []runtime.Object
+		err  error
+		name string
+		opts controlplane.Options
+	}
+	type want struct {
+		spec      any
+		conflicts []controlplane.Conflict
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Create": {
+			reason: "Applying a control plane that does not exist should create it.",
+			args: args{
+				name: "ctp1",
+				opts: controlplane.Options{SecretNamespace: "default", Class: "small"},
+			},
+			want: want{
+				spec: map[string]any{
+					"writeConnectionSecretToRef": map[string]any{
+						"name":      "kubeconfig-ctp1",
+						"namespace": "default",
+					},
+					"class": "small",
+				},
+			},
+		},
+		"Update": {
+			reason: "Applying a control plane that exists should update it.",
+			args: args{
+				objs: []runtime.Object{existing.GetUnstructured()},
+				name: "ctp1",
+				opts: controlplane.Options{SecretNamespace: "default", CrossplaneChannel: "Stable"},
+			},
+			want: want{
+				spec: map[string]any{
+					"writeConnectionSecretToRef": map[string]any{
+						"name":      "kubeconfig-ctp1",
+						"namespace": "default",
+					},
+					"crossplane": map[string]any{
+						"autoUpgrade": map[string]any{
+							"channel": "Stable",
+						},
+					},
+					"class": "large",
+				},
+			},
+		},
+		"Conflict": {
+			reason: "Applying a field owned by another field manager should return a conflict error.",
+			args: args{
+				objs: []runtime.Object{existing.GetUnstructured()},
+				err:  conflict,
+				name: "ctp1",
+				opts: controlplane.Options{SecretNamespace: "default", Class: "small"},
+			},
+			want: want{
+				spec: map[string]any{
+					"class": "large",
+				},
+				conflicts: []controlplane.Conflict{{Manager: "kubectl-edit", Field: ".spec.class"}},
+				err:       controlplane.NewConflict("ctp1", []controlplane.Conflict{{Manager: "kubectl-edit", Field: ".spec.class"}}),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := withApply(fake.NewSimpleDynamicClient(scheme, tc.args.objs...))
+			if tc.args.err != nil {
+				client.PrependReactor("patch", ctpresource, func(action cgotesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.args.err
+				})
+			}
+			_, err := New(client).Apply(context.Background(), tc.args.name, tc.args.opts)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conflicts, controlplane.Conflicts(err)); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want conflicts, +got conflicts:\n%s", tc.reason, diff)
+			}
+
+			u, err := client.Resource(resource).Get(context.Background(), tc.args.name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("\n%s\nGet(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.spec, u.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithCapabilities(t *testing.T) {
+	type want struct {
+		resource schema.GroupVersionResource
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		caps   *capability.Capabilities
+		want   want
+	}{
+		"Unknown": {
+			reason: "The latest API version should be used if capabilities are unknown.",
+			want:   want{resource: resource},
+		},
+		"Legacy": {
+			reason: "v1alpha1 should be used if the Space does not serve v1beta1.",
+			caps:   &capability.Capabilities{APIs: []string{"spaces.upbound.io/v1alpha1"}},
+			want:   want{resource: controlPlaneGRV.WithVersion("v1alpha1")},
+		},
+		"Unsupported": {
+			reason: "Requests should fail if the Space serves no ControlPlane API.",
+			caps:   &capability.Capabilities{Target: capability.TargetSpace, Version: "v0.9.0"},
+			want: want{
+				resource: resource,
+				err:      &capability.UnsupportedError{Feature: capability.FeatureControlPlanes, Target: capability.TargetSpace, Required: "v1.0", Found: "v0.9.0"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+				resource:                                "ControlPlaneList",
+				controlPlaneGRV.WithVersion("v1alpha1"): "ControlPlaneList",
+			})
+			c := New(client, WithCapabilities(tc.caps))
+			if diff := cmp.Diff(tc.want.resource, c.resource); diff != "" {
+				t.Errorf("\n%s\nWithCapabilities(...): -want resource, +got resource:\n%s", tc.reason, diff)
+			}
+			_, err := c.List(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nList(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetChannel(t *testing.T) {
+	ctp1 := &resources.ControlPlane{}
+	ctp1.SetName("ctp1")
+	ctp1.SetCrossplaneVersion("1.14.1-up.1")
+	ctp1.SetCrossplaneChannel(resources.CrossplaneChannelStable)
+
+	type want struct {
+		channel string
+		err     bool
+	}
+
+	cases := map[string]struct {
+		reason  string
+		name    string
+		channel string
+		want    want
+	}{
+		"InvalidChannel": {
+			reason:  "Unknown channels should be rejected without changing the control plane.",
+			name:    "ctp1",
+			channel: "Fast",
+			want:    want{channel: "Stable", err: true},
+		},
+		"NotFound": {
+			reason:  "Setting the channel of a missing control plane should fail.",
+			name:    "ctp-dne",
+			channel: "Rapid",
+			want:    want{channel: "Stable", err: true},
+		},
+		"Success": {
+			reason:  "The channel should be normalized and set without changing other fields.",
+			name:    "ctp1",
+			channel: "rapid",
+			want:    want{channel: "Rapid"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := New(fake.NewSimpleDynamicClient(scheme, ctp1.GetUnstructured().DeepCopy()))
+			err := c.SetChannel(context.Background(), tc.name, tc.channel)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nSetChannel(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+
+			got, err := c.GetChannel(context.Background(), "ctp1")
+			if err != nil {
+				t.Fatalf("\n%s\nGetChannel(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.channel, got); diff != "" {
+				t.Errorf("\n%s\nGetChannel(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			u, err := c.c.Resource(resource).Get(context.Background(), "ctp1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("\n%s\nGet(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff("1.14.1-up.1", (&resources.ControlPlane{Unstructured: *u}).GetCrossplaneVersion()); diff != "" {
+				t.Errorf("\n%s\nSetChannel(...): -want version, +got version:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	ctp1 := &resources.ControlPlane{}
 	ctp1.SetName("ctp1")
@@ -181,6 +577,32 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+type recorder struct {
+	events []telemetry.Event
+}
+
+func (r *recorder) Record(e telemetry.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestWithTelemetry(t *testing.T) {
+	ctp1 := &resources.ControlPlane{}
+	ctp1.SetName("ctp1")
+
+	r := &recorder{}
+	c := New(fake.NewSimpleDynamicClient(scheme, ctp1.GetUnstructured()), WithTelemetry(r))
+	_, _ = c.Get(context.Background(), "ctp1")
+	_ = c.Delete(context.Background(), "ctp-dne")
+
+	want := []telemetry.Event{
+		{Type: telemetry.TypeOperation, Name: "controlplane get", Category: telemetry.CategoryNone},
+		{Type: telemetry.TypeOperation, Name: "controlplane delete", Category: telemetry.CategoryNotFound},
+	}
+	if diff := cmp.Diff(want, r.events, cmpopts.IgnoreFields(telemetry.Event{}, "Duration", "Timestamp")); diff != "" {
+		t.Errorf("Record(...): -want, +got:\n%s", diff)
+	}
+}
+
 func TestList(t *testing.T) {
 	scheme.AddKnownTypeWithName(schema.GroupVersionKind{
 		Group:   "spaces.upbound.io",
@@ -0,0 +1,88 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	errReadTemplate   = "failed to read control plane template"
+	errParseTemplate  = "failed to parse control plane template"
+	errTemplateNoName = "control plane template has no name"
+)
+
+// A Template standardizes the parameters control planes are created with, so
+// that control planes created from it are alike across environments.
+type Template struct {
+	// Name of the template.
+	Name string `json:"name"`
+	// Configuration is the name of the configuration control planes run.
+	// Required for Upbound Cloud control planes.
+	Configuration string `json:"configuration,omitempty"`
+	// Class of the control planes. Only applicable to Space control planes.
+	Class string `json:"class,omitempty"`
+	// Secret configures the connection secrets of the control planes.
+	Secret TemplateSecret `json:"secret,omitempty"`
+	// Labels applied to the control planes. Only applicable to Space control
+	// planes.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// TemplateSecret configures the connection secret of a control plane created
+// from a Template.
+type TemplateSecret struct {
+	// Name of the secret. Defaults to 'kubeconfig-{control plane name}',
+	// which is usually what a template wants.
+	Name string `json:"name,omitempty"`
+	// Namespace of the secret.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Options returns the Options to create a control plane from the template
+// with.
+func (t *Template) Options() Options {
+	return Options{
+		SecretName:        t.Secret.Name,
+		SecretNamespace:   t.Secret.Namespace,
+		ConfigurationName: t.Configuration,
+		Class:             t.Class,
+		Labels:            t.Labels,
+	}
+}
+
+// ParseTemplate parses a YAML control plane template. Unknown fields are
+// rejected so that typos do not silently change the control planes created.
+func ParseTemplate(b []byte) (*Template, error) {
+	t := &Template{}
+	if err := yaml.UnmarshalStrict(b, t); err != nil {
+		return nil, errors.Wrap(err, errParseTemplate)
+	}
+	if t.Name == "" {
+		return nil, errors.New(errTemplateNoName)
+	}
+	return t, nil
+}
+
+// ReadTemplate reads the YAML control plane template at the supplied path.
+func ReadTemplate(fs afero.Fs, path string) (*Template, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadTemplate)
+	}
+	return ParseTemplate(b)
+}
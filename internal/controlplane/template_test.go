@@ -0,0 +1,109 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controlplane
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestReadTemplate(t *testing.T) {
+	type args struct {
+		contents string
+	}
+	type want struct {
+		tmpl *Template
+		opts Options
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "A template should be parsed into the options it creates control planes with.",
+			args: args{
+				contents: `
+name: small-dev
+configuration: platform-ref-aws
+class: small
+secret:
+  namespace: ctp-secrets
+labels:
+  env: dev
+`,
+			},
+			want: want{
+				tmpl: &Template{
+					Name:          "small-dev",
+					Configuration: "platform-ref-aws",
+					Class:         "small",
+					Secret:        TemplateSecret{Namespace: "ctp-secrets"},
+					Labels:        map[string]string{"env": "dev"},
+				},
+				opts: Options{
+					ConfigurationName: "platform-ref-aws",
+					Class:             "small",
+					SecretNamespace:   "ctp-secrets",
+					Labels:            map[string]string{"env": "dev"},
+				},
+			},
+		},
+		"ErrorNoName": {
+			reason: "A template without a name should be rejected.",
+			args: args{
+				contents: "class: small\n",
+			},
+			want: want{
+				err: errors.New(errTemplateNoName),
+			},
+		},
+		"ErrorUnknownField": {
+			reason: "A template with an unknown field should be rejected.",
+			args: args{
+				contents: "name: small-dev\nclas: small\n",
+			},
+			want: want{
+				err: errors.Wrap(errors.New(`error unmarshaling JSON: while decoding JSON: json: unknown field "clas"`), errParseTemplate),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			_ = afero.WriteFile(fs, "template.yaml", []byte(tc.args.contents), 0600)
+
+			got, err := ReadTemplate(fs, "template.yaml")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadTemplate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.tmpl, got); diff != "" {
+				t.Errorf("\n%s\nReadTemplate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if got == nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.opts, got.Options()); diff != "" {
+				t.Errorf("\n%s\nOptions(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
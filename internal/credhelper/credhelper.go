@@ -23,6 +23,7 @@ import (
 
 	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 const (
@@ -32,6 +33,7 @@ const (
 	errGetDefaultProfile = "unable to get default profile in config"
 	errGetProfile        = "unable to get specified profile in config"
 	errUnsupportedDomain = "supplied server URL is not supported"
+	errGetSession        = "unable to get session token"
 )
 
 const (
@@ -45,6 +47,7 @@ type Helper struct {
 	profile string
 	domain  string
 	src     config.Source
+	tokens  tokenstore.Store
 }
 
 // Opt sets a helper option.
@@ -78,6 +81,14 @@ func WithSource(src config.Source) Opt {
 	}
 }
 
+// WithTokenStore sets the store session tokens are read from when they are
+// not present in the config.
+func WithTokenStore(s tokenstore.Store) Opt {
+	return func(h *Helper) {
+		h.tokens = s
+	}
+}
+
 // New constructs a new Docker credential helper.
 func New(opts ...Opt) *Helper {
 	h := &Helper{
@@ -120,16 +131,24 @@ func (h *Helper) Get(serverURL string) (string, string, error) {
 		return "", "", errors.Wrap(err, errExtractConfig)
 	}
 	var p profile.Profile
-	if h.profile == "" {
-		_, p, err = conf.GetDefaultUpboundProfile()
+	name := h.profile
+	if name == "" {
+		name, p, err = conf.GetDefaultUpboundProfile()
 		if err != nil {
 			return "", "", errors.Wrap(err, errGetDefaultProfile)
 		}
 	} else {
-		p, err = conf.GetUpboundProfile(h.profile)
+		p, err = conf.GetUpboundProfile(name)
 		if err != nil {
 			return "", "", errors.Wrap(err, errGetProfile)
 		}
 	}
-	return defaultDockerUser, p.Session, nil
+	if p.Session != "" || h.tokens == nil {
+		return defaultDockerUser, p.Session, nil
+	}
+	s, err := h.tokens.Get(tokenstore.SessionKey(name))
+	if err != nil && !tokenstore.IsNotFound(err) {
+		return "", "", errors.Wrap(err, errGetSession)
+	}
+	return defaultDockerUser, s, nil
 }
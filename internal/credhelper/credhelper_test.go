@@ -21,9 +21,11 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/docker/docker-credential-helpers/credentials"
 	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
 
 	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 // TODO(hasheddan): these tests are testing through to the underlying config
@@ -166,6 +168,38 @@ func TestGet(t *testing.T) {
 				secret: testSecret,
 			},
 		},
+		"SuccessTokenStore": {
+			reason: "If the profile has no session we should return the one in the token store.",
+			args: args{
+				server: testServer,
+			},
+			opts: []Opt{
+				WithProfile(testProfile),
+				WithSource(&config.MockSource{
+					InitializeFn: func() error {
+						return nil
+					},
+					GetConfigFn: func() (*config.Config, error) {
+						return &config.Config{
+							Upbound: config.Upbound{
+								Profiles: map[string]profile.Profile{
+									testProfile: {},
+								},
+							},
+						}, nil
+					},
+				}),
+				WithTokenStore(func() tokenstore.Store {
+					s := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+					_ = s.Set(tokenstore.SessionKey(testProfile), testSecret)
+					return s
+				}()),
+			},
+			want: want{
+				user:   defaultDockerUser,
+				secret: testSecret,
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
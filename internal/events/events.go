@@ -0,0 +1,209 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events aggregates Kubernetes events about Crossplane resources in a
+// control plane.
+package events
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errListEvents = "failed to list events"
+)
+
+// Event is one or more occurrences of the same event about an object.
+type Event struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// A Group is the events about a single object, most recent first.
+type Group struct {
+	Object corev1.ObjectReference `json:"object"`
+	Events []Event                `json:"events"`
+}
+
+// LastSeen returns when the most recent event in the group was seen.
+func (g Group) LastSeen() time.Time {
+	if len(g.Events) == 0 {
+		return time.Time{}
+	}
+	return g.Events[0].LastSeen
+}
+
+// Filter selects the events that are collected. Empty fields match
+// everything.
+type Filter struct {
+	// Namespace restricts events to a single namespace. Events about cluster
+	// scoped resources are recorded in the default namespace.
+	Namespace string
+	// Type restricts events to a type, i.e. Normal or Warning.
+	Type string
+	// Objects restricts events to those about the supplied objects, e.g. a
+	// claim and the resources it composes.
+	Objects []types.UID
+	// Since drops events last seen before the supplied time.
+	Since time.Time
+	// Limit caps the number of events per object. Zero means no limit.
+	Limit int
+}
+
+// Collector collects and aggregates events.
+type Collector struct {
+	kube kubernetes.Interface
+}
+
+// New constructs a new Collector.
+func New(kube kubernetes.Interface) *Collector {
+	return &Collector{kube: kube}
+}
+
+// Collect returns the events about Crossplane resources matching the supplied
+// filter, grouped by involved object. Repeated events, i.e. those with the
+// same type, reason and message, are merged. Groups are sorted with the most
+// recently active object first.
+func (c *Collector) Collect(ctx context.Context, f Filter) ([]Group, error) {
+	opts := metav1.ListOptions{}
+	if f.Type != "" {
+		opts.FieldSelector = "type=" + f.Type
+	}
+	l, err := c.kube.CoreV1().Events(f.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, errListEvents)
+	}
+
+	uids := map[types.UID]bool{}
+	for _, u := range f.Objects {
+		uids[u] = true
+	}
+
+	type key struct{ typ, reason, message string }
+	groups := map[types.UID]*Group{}
+	merged := map[types.UID]map[key]int{}
+	for _, e := range l.Items {
+		if !f.matches(e, uids) {
+			continue
+		}
+		obj := e.InvolvedObject
+		g, ok := groups[obj.UID]
+		if !ok {
+			g = &Group{Object: obj}
+			groups[obj.UID] = g
+			merged[obj.UID] = map[key]int{}
+		}
+
+		first, last := seen(e)
+		k := key{e.Type, e.Reason, e.Message}
+		if i, ok := merged[obj.UID][k]; ok {
+			ev := &g.Events[i]
+			ev.Count += count(e)
+			if first.Before(ev.FirstSeen) {
+				ev.FirstSeen = first
+			}
+			if last.After(ev.LastSeen) {
+				ev.LastSeen = last
+			}
+			continue
+		}
+		merged[obj.UID][k] = len(g.Events)
+		g.Events = append(g.Events, Event{
+			Type:      e.Type,
+			Reason:    e.Reason,
+			Message:   e.Message,
+			Count:     count(e),
+			FirstSeen: first,
+			LastSeen:  last,
+		})
+	}
+
+	out := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		sort.SliceStable(g.Events, func(i, j int) bool {
+			return g.Events[i].LastSeen.After(g.Events[j].LastSeen)
+		})
+		if f.Limit > 0 && len(g.Events) > f.Limit {
+			g.Events = g.Events[:f.Limit]
+		}
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LastSeen().Equal(out[j].LastSeen()) {
+			return out[i].LastSeen().After(out[j].LastSeen())
+		}
+		return out[i].Object.UID < out[j].Object.UID
+	})
+	return out, nil
+}
+
+func (f Filter) matches(e corev1.Event, uids map[types.UID]bool) bool {
+	if len(uids) > 0 && !uids[e.InvolvedObject.UID] {
+		return false
+	}
+	if f.Type != "" && e.Type != f.Type {
+		return false
+	}
+	if _, last := seen(e); !f.Since.IsZero() && last.Before(f.Since) {
+		return false
+	}
+	return IsCrossplaneResource(e.InvolvedObject)
+}
+
+// IsCrossplaneResource returns true if the referenced object is likely to be
+// a Crossplane resource, i.e. a claim, composite resource, managed resource
+// or package. Crossplane resources are all custom resources, so anything in
+// a built-in Kubernetes API group is excluded.
+func IsCrossplaneResource(ref corev1.ObjectReference) bool {
+	g := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).Group
+	if g == "" || !strings.Contains(g, ".") {
+		return false
+	}
+	return !strings.HasSuffix(g, ".k8s.io")
+}
+
+// seen returns when an event was first and last seen, accounting for events
+// recorded through the events.k8s.io API, which only set an event time.
+func seen(e corev1.Event) (time.Time, time.Time) {
+	first, last := e.FirstTimestamp.Time, e.LastTimestamp.Time
+	if last.IsZero() {
+		last = e.EventTime.Time
+	}
+	if first.IsZero() {
+		first = last
+	}
+	return first, last
+}
+
+func count(e corev1.Event) int32 {
+	if e.Count == 0 {
+		return 1
+	}
+	return e.Count
+}
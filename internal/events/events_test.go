@@ -0,0 +1,156 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+var (
+	now    = time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	claim  = corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Database", Name: "db", Namespace: "default", UID: "1"}
+	bucket = corev1.ObjectReference{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "b", UID: "2"}
+	pod    = corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Name: "p", Namespace: "default", UID: "3"}
+)
+
+func event(name string, obj corev1.ObjectReference, typ, reason, msg string, count int32, last time.Duration) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: obj,
+		Type:           typ,
+		Reason:         reason,
+		Message:        msg,
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(now.Add(last - time.Minute)),
+		LastTimestamp:  metav1.NewTime(now.Add(last)),
+	}
+}
+
+func TestCollect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	objs := []runtime.Object{
+		event("a", claim, corev1.EventTypeNormal, "Bound", "bound", 1, -10*time.Minute),
+		event("b", bucket, corev1.EventTypeWarning, "CannotCreate", "denied", 3, -5*time.Minute),
+		event("c", bucket, corev1.EventTypeWarning, "CannotCreate", "denied", 2, -time.Minute),
+		event("d", bucket, corev1.EventTypeNormal, "Created", "created", 1, -20*time.Minute),
+		event("e", pod, corev1.EventTypeWarning, "BackOff", "backoff", 1, 0),
+	}
+
+	type want struct {
+		groups []Group
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		filter  Filter
+		listErr error
+		want    want
+	}{
+		"All": {
+			reason: "Events about Crossplane resources should be grouped, merged and sorted most recent first.",
+			want: want{
+				groups: []Group{
+					{Object: bucket, Events: []Event{
+						{Type: corev1.EventTypeWarning, Reason: "CannotCreate", Message: "denied", Count: 5, FirstSeen: now.Add(-6 * time.Minute), LastSeen: now.Add(-time.Minute)},
+						{Type: corev1.EventTypeNormal, Reason: "Created", Message: "created", Count: 1, FirstSeen: now.Add(-21 * time.Minute), LastSeen: now.Add(-20 * time.Minute)},
+					}},
+					{Object: claim, Events: []Event{
+						{Type: corev1.EventTypeNormal, Reason: "Bound", Message: "bound", Count: 1, FirstSeen: now.Add(-11 * time.Minute), LastSeen: now.Add(-10 * time.Minute)},
+					}},
+				},
+			},
+		},
+		"WarningsForObjects": {
+			reason: "Filters should restrict events by type, object and limit.",
+			filter: Filter{Type: corev1.EventTypeWarning, Objects: []types.UID{claim.UID, bucket.UID}, Limit: 1},
+			want: want{
+				groups: []Group{
+					{Object: bucket, Events: []Event{
+						{Type: corev1.EventTypeWarning, Reason: "CannotCreate", Message: "denied", Count: 5, FirstSeen: now.Add(-6 * time.Minute), LastSeen: now.Add(-time.Minute)},
+					}},
+				},
+			},
+		},
+		"Since": {
+			reason: "Events last seen before the supplied time should be dropped.",
+			filter: Filter{Since: now.Add(-15 * time.Minute), Objects: []types.UID{bucket.UID}},
+			want: want{
+				groups: []Group{
+					{Object: bucket, Events: []Event{
+						{Type: corev1.EventTypeWarning, Reason: "CannotCreate", Message: "denied", Count: 5, FirstSeen: now.Add(-6 * time.Minute), LastSeen: now.Add(-time.Minute)},
+					}},
+				},
+			},
+		},
+		"ListError": {
+			reason:  "Errors listing events should be returned.",
+			listErr: errBoom,
+			want: want{
+				err: errors.Wrap(errBoom, errListEvents),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := kfake.NewSimpleClientset(objs...)
+			if tc.listErr != nil {
+				kube.PrependReactor("list", "events", func(ktesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.listErr
+				})
+			}
+			got, err := New(kube).Collect(context.Background(), tc.filter)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCollect(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.groups, got); diff != "" {
+				t.Errorf("\n%s\nCollect(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsCrossplaneResource(t *testing.T) {
+	cases := map[string]struct {
+		ref  corev1.ObjectReference
+		want bool
+	}{
+		"Core":    {ref: pod, want: false},
+		"Apps":    {ref: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment"}, want: false},
+		"BuiltIn": {ref: corev1.ObjectReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"}, want: false},
+		"Package": {ref: corev1.ObjectReference{APIVersion: "pkg.crossplane.io/v1", Kind: "Provider"}, want: true},
+		"Managed": {ref: bucket, want: true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsCrossplaneResource(tc.ref); got != tc.want {
+				t.Errorf("IsCrossplaneResource(%v): want %t, got %t", tc.ref, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package failure classifies errors into a small taxonomy of kinds, each
+// mapped to a distinct exit code, so that automation can tell failures apart
+// without parsing error messages. For example CI may retry only transient
+// failures.
+package failure
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+)
+
+// A Kind of failure.
+type Kind string
+
+// Kinds of failure.
+const (
+	// KindNone is the kind of a nil error.
+	KindNone Kind = ""
+	// KindUnknown is the kind of errors that could not be classified.
+	KindUnknown Kind = "Unknown"
+	// KindUsage indicates the command was invoked incorrectly, e.g. with an
+	// unknown flag or an invalid argument. Retrying will not help.
+	KindUsage Kind = "Usage"
+	// KindAuth indicates the caller is not authenticated, or is not
+	// permitted to perform an operation.
+	KindAuth Kind = "Auth"
+	// KindNotFound indicates a resource does not exist.
+	KindNotFound Kind = "NotFound"
+	// KindConflict indicates a resource already exists, or was concurrently
+	// modified.
+	KindConflict Kind = "Conflict"
+	// KindTransient indicates a failure that may succeed if retried, e.g. a
+	// timeout, network error, or server error.
+	KindTransient Kind = "Transient"
+)
+
+// Exit codes of each kind of failure.
+const (
+	ExitOK        = 0
+	ExitUnknown   = 1
+	ExitUsage     = 2
+	ExitAuth      = 3
+	ExitNotFound  = 4
+	ExitConflict  = 5
+	ExitTransient = 6
+)
+
+// ExitCode returns the exit code of the kind of failure.
+func (k Kind) ExitCode() int {
+	switch k {
+	case KindNone:
+		return ExitOK
+	case KindUsage:
+		return ExitUsage
+	case KindAuth:
+		return ExitAuth
+	case KindNotFound:
+		return ExitNotFound
+	case KindConflict:
+		return ExitConflict
+	case KindTransient:
+		return ExitTransient
+	}
+	return ExitUnknown
+}
+
+// kindError is an error of a particular kind.
+type kindError struct {
+	kind Kind
+	err  error
+}
+
+// Error calls the underlying error's Error method.
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+// Kind returns the kind of the error.
+func (e *kindError) Kind() Kind {
+	return e.kind
+}
+
+// Wrap marks an error as being of the supplied kind. The error's message is
+// unchanged. Wrap returns nil if the error is nil.
+func Wrap(k Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: k, err: err}
+}
+
+// Usage marks an error as a usage error.
+func Usage(err error) error {
+	return Wrap(KindUsage, err)
+}
+
+// Auth marks an error as an authentication or authorization error.
+func Auth(err error) error {
+	return Wrap(KindAuth, err)
+}
+
+// NotFound marks an error as a not found error.
+func NotFound(err error) error {
+	return Wrap(KindNotFound, err)
+}
+
+// Conflict marks an error as a conflict error.
+func Conflict(err error) error {
+	return Wrap(KindConflict, err)
+}
+
+// Transient marks an error as a transient error.
+func Transient(err error) error {
+	return Wrap(KindTransient, err)
+}
+
+// FromStatus marks an error caused by an HTTP response with the supplied
+// status code as being of the corresponding kind.
+func FromStatus(status int, err error) error {
+	return Wrap(statusKind(status), err)
+}
+
+// A kinded error knows its kind.
+type kinded interface {
+	Kind() Kind
+}
+
+// notFound is implemented by the not found errors of the internal clients.
+type notFound interface {
+	NotFound() bool
+}
+
+// permissionDenied is implemented by the permission denied errors of the
+// internal clients.
+type permissionDenied interface {
+	PermissionDenied() bool
+}
+
+// KindOf returns the kind of the supplied error. Errors explicitly marked
+// with a kind take precedence. Otherwise errors returned by the Kubernetes
+// and Upbound APIs, and network errors, are classified by their type.
+func KindOf(err error) Kind { //nolint:gocyclo
+	if err == nil {
+		return KindNone
+	}
+
+	var k kinded
+	if errors.As(err, &k) {
+		return k.Kind()
+	}
+	var nf notFound
+	if errors.As(err, &nf) && nf.NotFound() {
+		return KindNotFound
+	}
+	var pd permissionDenied
+	if errors.As(err, &pd) && pd.PermissionDenied() {
+		return KindAuth
+	}
+	var sdkErr *sdkerrs.Error
+	if errors.As(err, &sdkErr) {
+		return statusKind(sdkErr.Status)
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), kerrors.IsTimeout(err), kerrors.IsServerTimeout(err),
+		kerrors.IsTooManyRequests(err), kerrors.IsInternalError(err), kerrors.IsServiceUnavailable(err):
+		return KindTransient
+	case kerrors.IsNotFound(err):
+		return KindNotFound
+	case kerrors.IsUnauthorized(err), kerrors.IsForbidden(err):
+		return KindAuth
+	case kerrors.IsConflict(err), kerrors.IsAlreadyExists(err):
+		return KindConflict
+	case kerrors.IsInvalid(err), kerrors.IsBadRequest(err):
+		return KindUsage
+	case errors.As(err, &netErr):
+		return KindTransient
+	}
+	return KindUnknown
+}
+
+// ExitCode returns the exit code of the supplied error.
+func ExitCode(err error) int {
+	return KindOf(err).ExitCode()
+}
+
+// IsNotFound returns true if the supplied error indicates a resource does not
+// exist.
+func IsNotFound(err error) bool {
+	return KindOf(err) == KindNotFound
+}
+
+// IsTransient returns true if the supplied error may succeed if retried.
+func IsTransient(err error) bool {
+	return KindOf(err) == KindTransient
+}
+
+// statusKind returns the kind of failure indicated by an HTTP status code.
+func statusKind(status int) Kind {
+	switch {
+	case status < http.StatusBadRequest:
+		return KindUnknown
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return KindAuth
+	case status == http.StatusNotFound:
+		return KindNotFound
+	case status == http.StatusConflict:
+		return KindConflict
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return KindTransient
+	case status < http.StatusInternalServerError:
+		return KindUsage
+	case status == http.StatusNotImplemented:
+		return KindUnknown
+	}
+	return KindTransient
+}
@@ -0,0 +1,188 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package failure
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+)
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string  { return "not found" }
+func (notFoundError) NotFound() bool { return true }
+
+func TestKindOf(t *testing.T) {
+	gr := schema.GroupResource{Group: "spaces.upbound.io", Resource: "controlplanes"}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   Kind
+		code   int
+	}{
+		"Nil": {
+			reason: "A nil error should have no kind and exit successfully.",
+			want:   KindNone,
+			code:   ExitOK,
+		},
+		"Unknown": {
+			reason: "An unclassified error should be of unknown kind.",
+			err:    errors.New("boom"),
+			want:   KindUnknown,
+			code:   ExitUnknown,
+		},
+		"Explicit": {
+			reason: "An explicitly marked error should be of the marked kind, even if wrapped.",
+			err:    errors.Wrap(Usage(errors.New("bad flag")), "cannot run"),
+			want:   KindUsage,
+			code:   ExitUsage,
+		},
+		"ExplicitOverridesType": {
+			reason: "An explicit kind should take precedence over the kind of the underlying error.",
+			err:    Transient(kerrors.NewNotFound(gr, "ctp1")),
+			want:   KindTransient,
+			code:   ExitTransient,
+		},
+		"NotFoundInterface": {
+			reason: "Errors of the internal clients indicating a missing resource should be not found errors.",
+			err:    errors.Wrap(notFoundError{}, "cannot get"),
+			want:   KindNotFound,
+			code:   ExitNotFound,
+		},
+		"KubernetesForbidden": {
+			reason: "A forbidden Kubernetes API error should be an auth error.",
+			err:    kerrors.NewForbidden(gr, "ctp1", errors.New("nope")),
+			want:   KindAuth,
+			code:   ExitAuth,
+		},
+		"KubernetesAlreadyExists": {
+			reason: "An already exists Kubernetes API error should be a conflict error.",
+			err:    kerrors.NewAlreadyExists(gr, "ctp1"),
+			want:   KindConflict,
+			code:   ExitConflict,
+		},
+		"KubernetesTooManyRequests": {
+			reason: "A rate limited Kubernetes API error should be transient.",
+			err:    kerrors.NewTooManyRequests("slow down", 1),
+			want:   KindTransient,
+			code:   ExitTransient,
+		},
+		"UpboundServerError": {
+			reason: "An Upbound API server error should be transient.",
+			err:    errors.Wrap(&sdkerrs.Error{Status: http.StatusBadGateway, Title: "bad gateway"}, "cannot list"),
+			want:   KindTransient,
+			code:   ExitTransient,
+		},
+		"UpboundUnauthorized": {
+			reason: "An unauthorized Upbound API error should be an auth error.",
+			err:    &sdkerrs.Error{Status: http.StatusUnauthorized, Title: "unauthorized"},
+			want:   KindAuth,
+			code:   ExitAuth,
+		},
+		"DeadlineExceeded": {
+			reason: "A timeout should be transient.",
+			err:    errors.Wrap(context.DeadlineExceeded, "cannot list"),
+			want:   KindTransient,
+			code:   ExitTransient,
+		},
+		"Network": {
+			reason: "A network error should be transient.",
+			err:    &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			want:   KindTransient,
+			code:   ExitTransient,
+		},
+		"Canceled": {
+			reason: "A canceled command should be of unknown kind, since retrying is not appropriate.",
+			err:    context.Canceled,
+			want:   KindUnknown,
+			code:   ExitUnknown,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, KindOf(tc.err)); diff != "" {
+				t.Errorf("\n%s\nKindOf(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.code, ExitCode(tc.err)); diff != "" {
+				t.Errorf("\n%s\nExitCode(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		status int
+		want   Kind
+	}{
+		"BadRequest": {
+			reason: "A client error should be a usage error.",
+			status: http.StatusBadRequest,
+			want:   KindUsage,
+		},
+		"Forbidden": {
+			reason: "A forbidden response should be an auth error.",
+			status: http.StatusForbidden,
+			want:   KindAuth,
+		},
+		"NotFound": {
+			reason: "A not found response should be a not found error.",
+			status: http.StatusNotFound,
+			want:   KindNotFound,
+		},
+		"Conflict": {
+			reason: "A conflict response should be a conflict error.",
+			status: http.StatusConflict,
+			want:   KindConflict,
+		},
+		"TooManyRequests": {
+			reason: "A rate limited response should be transient.",
+			status: http.StatusTooManyRequests,
+			want:   KindTransient,
+		},
+		"ServiceUnavailable": {
+			reason: "A server error should be transient.",
+			status: http.StatusServiceUnavailable,
+			want:   KindTransient,
+		},
+		"NotImplemented": {
+			reason: "A not implemented response will not succeed if retried.",
+			status: http.StatusNotImplemented,
+			want:   KindUnknown,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := FromStatus(tc.status, errors.New("unexpected status"))
+			if diff := cmp.Diff(tc.want, KindOf(err)); diff != "" {
+				t.Errorf("\n%s\nFromStatus(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff("unexpected status", err.Error()); diff != "" {
+				t.Errorf("\n%s\nFromStatus(...): -want message, +got message:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
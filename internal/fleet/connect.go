@@ -0,0 +1,75 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	keyKubeconfig = "kubeconfig"
+
+	errGetControlPlane     = "cannot get control plane"
+	errNoConnectionSecret  = "control plane has no connection secret"
+	errGetConnectionSecret = "cannot get connection secret"
+	errNoKubeconfig        = "connection secret has no kubeconfig"
+	errParseKubeconfig     = "cannot parse kubeconfig"
+)
+
+// SecretConnector returns a ConnectFn that connects to control planes using
+// the kubeconfig in their connection secret. The supplied wrapper, if any,
+// wraps the transport of each connection.
+func SecretConnector(dyn dynamic.Interface, kube kubernetes.Interface, wrap transport.WrapperFunc) ConnectFn {
+	return func(ctx context.Context, t Target) (dynamic.Interface, error) {
+		u, err := dyn.Resource(Resource).Namespace(t.Group).Get(ctx, t.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, errGetControlPlane)
+		}
+		ref := (&resources.ControlPlane{Unstructured: *u}).GetConnectionSecretToReference()
+		if ref == nil || ref.Name == "" {
+			return nil, errors.New(errNoConnectionSecret)
+		}
+		ns := ref.Namespace
+		if ns == "" {
+			ns = t.Group
+		}
+		s, err := kube.CoreV1().Secrets(ns).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, errGetConnectionSecret)
+		}
+		kc, ok := s.Data[keyKubeconfig]
+		if !ok {
+			return nil, errors.New(errNoKubeconfig)
+		}
+		cfg, err := clientcmd.RESTConfigFromKubeConfig(kc)
+		if err != nil {
+			return nil, errors.Wrap(err, errParseKubeconfig)
+		}
+		if wrap != nil {
+			cfg.Wrap(wrap)
+		}
+		return dynamic.NewForConfig(cfg)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fleet applies operations across many control planes in a Space.
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	// DefaultConcurrency is the number of control planes operated on at
+	// once.
+	DefaultConcurrency = 5
+
+	errListControlPlanes = "cannot list control planes"
+	errFmtNamePattern    = "invalid name pattern %q"
+	errFmtCanaryFailed   = "canary %s failed"
+	errFmtFailed         = "%d of %d control planes failed"
+)
+
+// Resource is the ControlPlane resource operated on.
+var Resource = resources.ControlPlaneGVK.GroupVersion().WithResource("controlplanes")
+
+// A Target is a control plane selected by a Selector.
+type Target struct {
+	Group  string
+	Name   string
+	Labels map[string]string
+}
+
+// String returns the group and name of the target.
+func (t Target) String() string {
+	if t.Group == "" {
+		return t.Name
+	}
+	return t.Group + "/" + t.Name
+}
+
+// A Selector selects control planes. Empty fields match all control planes.
+type Selector struct {
+	// Group restricts selection to a single group.
+	Group string
+	// Names are shell file name patterns, as supported by path.Match. A
+	// control plane is selected if it matches any pattern.
+	Names []string
+	// Labels that selected control planes must all have.
+	Labels map[string]string
+}
+
+func (s Selector) matchName(name string) bool {
+	if len(s.Names) == 0 {
+		return true
+	}
+	for _, p := range s.Names {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// An Operation is applied to each selected control plane.
+type Operation interface {
+	// Name describes the operation, e.g. for progress output.
+	Name() string
+	// Apply the operation to the supplied control plane.
+	Apply(ctx context.Context, t Target) error
+}
+
+// A Result is the outcome of applying an operation to a control plane.
+type Result struct {
+	Target   Target
+	Canary   bool
+	Skipped  bool
+	Err      error
+	Duration time.Duration
+}
+
+// String returns a one line summary of the result.
+func (r Result) String() string {
+	switch {
+	case r.Skipped:
+		return fmt.Sprintf("%s: skipped: %v", r.Target, r.Err)
+	case r.Err != nil:
+		return fmt.Sprintf("%s: failed: %v", r.Target, r.Err)
+	default:
+		return fmt.Sprintf("%s: done in %s", r.Target, r.Duration.Round(time.Millisecond))
+	}
+}
+
+// A Report collects the results of a fleet operation, in the order control
+// planes were selected.
+type Report struct {
+	Results []Result
+}
+
+// Failed returns the results of control planes the operation failed for.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarizing failures, or nil if the operation was
+// applied to every selected control plane.
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Errorf(errFmtFailed, len(failed), len(r.Results))
+}
+
+// A ResultFn is called as each control plane is finished with.
+type ResultFn func(Result)
+
+// Runner applies operations across the control planes of a Space.
+type Runner struct {
+	dyn         dynamic.Interface
+	resource    schema.GroupVersionResource
+	concurrency int
+	canaries    int
+	progress    ResultFn
+	log         logging.Logger
+
+	mu sync.Mutex
+}
+
+// Option modifies a Runner.
+type Option func(*Runner)
+
+// WithConcurrency sets how many control planes are operated on at once.
+func WithConcurrency(n int) Option {
+	return func(r *Runner) {
+		r.concurrency = n
+	}
+}
+
+// WithCanaries sets how many control planes are operated on first, one at a
+// time, before the operation is rolled out to the rest. The rollout is
+// aborted if any canary fails.
+func WithCanaries(n int) Option {
+	return func(r *Runner) {
+		r.canaries = n
+	}
+}
+
+// WithProgress sets a function that is called with each result as it
+// becomes available. Calls are serialized.
+func WithProgress(fn ResultFn) Option {
+	return func(r *Runner) {
+		r.progress = fn
+	}
+}
+
+// WithLogger sets the logger used by the Runner.
+func WithLogger(l logging.Logger) Option {
+	return func(r *Runner) {
+		r.log = l
+	}
+}
+
+// New constructs a new Runner for the Space served by the supplied client.
+func New(dyn dynamic.Interface, opts ...Option) *Runner {
+	r := &Runner{
+		dyn:         dyn,
+		resource:    Resource,
+		concurrency: DefaultConcurrency,
+		progress:    func(Result) {},
+		log:         logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if r.concurrency < 1 {
+		r.concurrency = 1
+	}
+	return r
+}
+
+// Select returns the control planes matching the supplied selector, sorted
+// by group and name.
+func (r *Runner) Select(ctx context.Context, s Selector) ([]Target, error) {
+	for _, p := range s.Names {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, errors.Wrapf(err, errFmtNamePattern, p)
+		}
+	}
+	l, err := r.dyn.Resource(r.resource).Namespace(s.Group).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(s.Labels).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errListControlPlanes)
+	}
+	targets := []Target{}
+	for _, u := range l.Items {
+		if !s.matchName(u.GetName()) {
+			continue
+		}
+		targets = append(targets, Target{Group: u.GetNamespace(), Name: u.GetName(), Labels: u.GetLabels()})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].String() < targets[j].String()
+	})
+	return targets, nil
+}
+
+// Run applies the supplied operation to every control plane matching the
+// supplied selector. Failing control planes do not stop the rollout, unless
+// they are canaries. The returned error is only non-nil if control planes
+// could not be selected; per control plane failures are in the report.
+func (r *Runner) Run(ctx context.Context, s Selector, op Operation) (*Report, error) {
+	targets, err := r.Select(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	return r.Apply(ctx, targets, op), nil
+}
+
+// Apply applies the supplied operation to the supplied control planes.
+func (r *Runner) Apply(ctx context.Context, targets []Target, op Operation) *Report {
+	rep := &Report{Results: make([]Result, len(targets))}
+	canaries := r.canaries
+	if canaries > len(targets) {
+		canaries = len(targets)
+	}
+
+	for i := 0; i < canaries; i++ {
+		rep.Results[i] = r.apply(ctx, targets[i], op, true)
+		if err := rep.Results[i].Err; err != nil {
+			r.log.Info("Canary failed, aborting rollout", "controlplane", targets[i].String(), "error", err)
+			for j := i + 1; j < len(targets); j++ {
+				rep.Results[j] = Result{
+					Target:  targets[j],
+					Canary:  j < canaries,
+					Skipped: true,
+					Err:     errors.Errorf(errFmtCanaryFailed, targets[i]),
+				}
+				r.report(rep.Results[j])
+			}
+			return rep
+		}
+	}
+
+	sem := make(chan struct{}, r.concurrency)
+	wg := sync.WaitGroup{}
+	for i := canaries; i < len(targets); i++ {
+		i := i
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			rep.Results[i] = r.apply(ctx, targets[i], op, false)
+		}()
+	}
+	wg.Wait()
+	return rep
+}
+
+func (r *Runner) apply(ctx context.Context, t Target, op Operation, canary bool) Result {
+	r.log.Debug("Applying fleet operation", "operation", op.Name(), "controlplane", t.String(), "canary", canary)
+	start := time.Now()
+	res := Result{Target: t, Canary: canary}
+	if err := ctx.Err(); err != nil {
+		res.Skipped, res.Err = true, err
+	} else {
+		res.Err = op.Apply(ctx, t)
+	}
+	res.Duration = time.Since(start)
+	r.report(res)
+	return res
+}
+
+func (r *Runner) report(res Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress(res)
+}
@@ -0,0 +1,225 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+var scheme = runtime.NewScheme()
+
+func ctp(group, name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(resources.ControlPlaneGVK)
+	u.SetNamespace(group)
+	u.SetName(name)
+	u.SetLabels(labels)
+	return u
+}
+
+func newFake(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		Resource:         "ControlPlaneList",
+		configurationGVR: "ConfigurationList",
+	}, objs...)
+}
+
+type mockOp struct {
+	mu      sync.Mutex
+	applied []string
+	fail    map[string]error
+}
+
+func (o *mockOp) Name() string { return "mock" }
+
+func (o *mockOp) Apply(_ context.Context, t Target) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.applied = append(o.applied, t.String())
+	return o.fail[t.Name]
+}
+
+func TestSelect(t *testing.T) {
+	objs := []runtime.Object{
+		ctp("prod", "prod-a", map[string]string{"tier": "gold"}),
+		ctp("prod", "prod-b", map[string]string{"tier": "silver"}),
+		ctp("dev", "dev-a", map[string]string{"tier": "gold"}),
+	}
+
+	type want struct {
+		targets []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      Selector
+		want   want
+	}{
+		"All": {
+			reason: "An empty selector should select all control planes, sorted by group and name.",
+			want: want{
+				targets: []string{"dev/dev-a", "prod/prod-a", "prod/prod-b"},
+			},
+		},
+		"Group": {
+			reason: "Only control planes in the selected group should be selected.",
+			s:      Selector{Group: "prod"},
+			want: want{
+				targets: []string{"prod/prod-a", "prod/prod-b"},
+			},
+		},
+		"Names": {
+			reason: "Control planes matching any name pattern should be selected.",
+			s:      Selector{Names: []string{"*-b", "dev-*"}},
+			want: want{
+				targets: []string{"dev/dev-a", "prod/prod-b"},
+			},
+		},
+		"Labels": {
+			reason: "Only control planes with all selected labels should be selected.",
+			s:      Selector{Labels: map[string]string{"tier": "gold"}},
+			want: want{
+				targets: []string{"dev/dev-a", "prod/prod-a"},
+			},
+		},
+		"InvalidPattern": {
+			reason: "An invalid name pattern should return an error.",
+			s:      Selector{Names: []string{"["}},
+			want: want{
+				err: errors.Wrapf(errors.New("syntax error in pattern"), errFmtNamePattern, "["),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			targets, err := New(newFake(objs...)).Select(context.Background(), tc.s)
+			var got []string
+			for _, tgt := range targets {
+				got = append(got, tgt.String())
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSelect(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.targets, got); diff != "" {
+				t.Errorf("\n%s\nSelect(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	errBoom := errors.New("boom")
+	targets := []Target{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+
+	type args struct {
+		opts []Option
+		fail map[string]error
+	}
+	type want struct {
+		applied []string
+		results []Result
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "The operation should be applied to all control planes.",
+			args: args{
+				opts: []Option{WithConcurrency(2)},
+			},
+			want: want{
+				applied: []string{"a", "b", "c", "d"},
+				results: []Result{{Target: targets[0]}, {Target: targets[1]}, {Target: targets[2]}, {Target: targets[3]}},
+			},
+		},
+		"FailureDoesNotStopRollout": {
+			reason: "A failing control plane that is not a canary should not stop the rollout.",
+			args: args{
+				fail: map[string]error{"b": errBoom},
+			},
+			want: want{
+				applied: []string{"a", "b", "c", "d"},
+				results: []Result{{Target: targets[0]}, {Target: targets[1], Err: errBoom}, {Target: targets[2]}, {Target: targets[3]}},
+				err:     errors.Errorf(errFmtFailed, 1, 4),
+			},
+		},
+		"CanaryFailed": {
+			reason: "A failing canary should abort the rollout to the remaining control planes.",
+			args: args{
+				opts: []Option{WithCanaries(2)},
+				fail: map[string]error{"a": errBoom},
+			},
+			want: want{
+				applied: []string{"a"},
+				results: []Result{
+					{Target: targets[0], Canary: true, Err: errBoom},
+					{Target: targets[1], Canary: true, Skipped: true, Err: errors.Errorf(errFmtCanaryFailed, targets[0])},
+					{Target: targets[2], Skipped: true, Err: errors.Errorf(errFmtCanaryFailed, targets[0])},
+					{Target: targets[3], Skipped: true, Err: errors.Errorf(errFmtCanaryFailed, targets[0])},
+				},
+				err: errors.Errorf(errFmtFailed, 4, 4),
+			},
+		},
+		"CanariesSucceeded": {
+			reason: "Canaries should be applied first, then the rest of the control planes.",
+			args: args{
+				opts: []Option{WithCanaries(1)},
+			},
+			want: want{
+				applied: []string{"a", "b", "c", "d"},
+				results: []Result{{Target: targets[0], Canary: true}, {Target: targets[1]}, {Target: targets[2]}, {Target: targets[3]}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			op := &mockOp{fail: tc.args.fail}
+			var reported int
+			opts := append(tc.args.opts, WithProgress(func(Result) { reported++ }))
+			rep := New(newFake(), opts...).Apply(context.Background(), targets, op)
+
+			if diff := cmp.Diff(tc.want.applied, op.applied, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want applied, +got applied:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.results, rep.Results, test.EquateErrors(), cmpopts.IgnoreFields(Result{}, "Duration")); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want results, +got results:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, rep.Err(), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nErr(): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if reported != len(targets) {
+				t.Errorf("\n%s\nApply(...): want %d progress reports, got %d", tc.reason, len(targets), reported)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	errPatchControlPlane   = "cannot patch control plane"
+	errConnect             = "cannot connect to control plane"
+	errFmtGetConfiguration = "cannot get configuration %q"
+	errFmtParsePackage     = "cannot parse package %q"
+	errFmtUpdatePackage    = "cannot update configuration %q"
+)
+
+var configurationGVR = schema.GroupVersionResource{
+	Group:    "pkg.crossplane.io",
+	Version:  "v1",
+	Resource: "configurations",
+}
+
+// patch applies a JSON merge patch to the ControlPlane of the target.
+func patch(ctx context.Context, dyn dynamic.Interface, t Target, p map[string]any) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, errPatchControlPlane)
+	}
+	_, err = dyn.Resource(Resource).Namespace(t.Group).Patch(ctx, t.Name, types.MergePatchType, b, metav1.PatchOptions{})
+	return errors.Wrap(err, errPatchControlPlane)
+}
+
+// SetChannel sets the Crossplane auto-upgrade channel of control planes.
+type SetChannel struct {
+	dyn     dynamic.Interface
+	channel resources.CrossplaneChannel
+}
+
+// NewSetChannel returns an operation that sets the Crossplane auto-upgrade
+// channel of control planes to the supplied channel.
+func NewSetChannel(dyn dynamic.Interface, channel string) (*SetChannel, error) {
+	ch, err := resources.ParseCrossplaneChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return &SetChannel{dyn: dyn, channel: ch}, nil
+}
+
+// Name of the operation.
+func (o *SetChannel) Name() string {
+	return fmt.Sprintf("set channel to %s", o.channel)
+}
+
+// Apply the operation to the supplied control plane.
+func (o *SetChannel) Apply(ctx context.Context, t Target) error {
+	return patch(ctx, o.dyn, t, map[string]any{
+		"spec": map[string]any{
+			"crossplane": map[string]any{
+				"autoUpgrade": map[string]any{
+					"channel": string(o.channel),
+				},
+			},
+		},
+	})
+}
+
+// Pause pauses or resumes reconciliation of control planes.
+type Pause struct {
+	dyn    dynamic.Interface
+	paused bool
+}
+
+// NewPause returns an operation that pauses reconciliation of control planes
+// if paused is true, and resumes it otherwise.
+func NewPause(dyn dynamic.Interface, paused bool) *Pause {
+	return &Pause{dyn: dyn, paused: paused}
+}
+
+// Name of the operation.
+func (o *Pause) Name() string {
+	if o.paused {
+		return "pause"
+	}
+	return "resume"
+}
+
+// Apply the operation to the supplied control plane.
+func (o *Pause) Apply(ctx context.Context, t Target) error {
+	// A null value removes the annotation.
+	var v any
+	if o.paused {
+		v = "true"
+	}
+	return patch(ctx, o.dyn, t, map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				meta.AnnotationKeyReconciliationPaused: v,
+			},
+		},
+	})
+}
+
+// A ConnectFn returns a client for the API server of a control plane.
+type ConnectFn func(ctx context.Context, t Target) (dynamic.Interface, error)
+
+// SetConfigurationVersion upgrades or downgrades a Configuration installed
+// in control planes.
+type SetConfigurationVersion struct {
+	connect ConnectFn
+	name    string
+	version string
+}
+
+// NewSetConfigurationVersion returns an operation that sets the version of
+// the Configuration with the supplied name in control planes, connecting to
+// each control plane using the supplied function.
+func NewSetConfigurationVersion(connect ConnectFn, name, version string) *SetConfigurationVersion {
+	return &SetConfigurationVersion{connect: connect, name: name, version: version}
+}
+
+// Name of the operation.
+func (o *SetConfigurationVersion) Name() string {
+	return fmt.Sprintf("set configuration %s to %s", o.name, o.version)
+}
+
+// Apply the operation to the supplied control plane.
+func (o *SetConfigurationVersion) Apply(ctx context.Context, t Target) error {
+	dyn, err := o.connect(ctx, t)
+	if err != nil {
+		return errors.Wrap(err, errConnect)
+	}
+	r := dyn.Resource(configurationGVR)
+	u, err := r.Get(ctx, o.name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, errFmtGetConfiguration, o.name)
+	}
+	pkg := resources.Package{Unstructured: *u}
+	ref, err := name.ParseReference(pkg.GetPackage(), name.StrictValidation)
+	if err != nil {
+		return errors.Wrapf(err, errFmtParsePackage, pkg.GetPackage())
+	}
+	pkg.SetPackage(ref.Context().Tag(o.version).Name())
+	_, err = r.Update(ctx, &pkg.Unstructured, metav1.UpdateOptions{})
+	return errors.Wrapf(err, errFmtUpdatePackage, o.name)
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+func TestSetChannel(t *testing.T) {
+	dyn := newFake(ctp("prod", "a", nil))
+	op, err := NewSetChannel(dyn, "rapid")
+	if err != nil {
+		t.Fatalf("NewSetChannel(...): %v", err)
+	}
+	if err := op.Apply(context.Background(), Target{Group: "prod", Name: "a"}); err != nil {
+		t.Fatalf("Apply(...): %v", err)
+	}
+	u, _ := dyn.Resource(Resource).Namespace("prod").Get(context.Background(), "a", metav1.GetOptions{})
+	got, _, _ := unstructured.NestedString(u.Object, "spec", "crossplane", "autoUpgrade", "channel")
+	if diff := cmp.Diff(string(resources.CrossplaneChannelRapid), got); diff != "" {
+		t.Errorf("Apply(...): -want channel, +got channel:\n%s", diff)
+	}
+}
+
+func TestPause(t *testing.T) {
+	dyn := newFake(ctp("prod", "a", nil))
+	tgt := Target{Group: "prod", Name: "a"}
+	get := func() map[string]string {
+		u, _ := dyn.Resource(Resource).Namespace("prod").Get(context.Background(), "a", metav1.GetOptions{})
+		return u.GetAnnotations()
+	}
+
+	if err := NewPause(dyn, true).Apply(context.Background(), tgt); err != nil {
+		t.Fatalf("Apply(...): %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{meta.AnnotationKeyReconciliationPaused: "true"}, get()); diff != "" {
+		t.Errorf("pause Apply(...): -want annotations, +got annotations:\n%s", diff)
+	}
+	if err := NewPause(dyn, false).Apply(context.Background(), tgt); err != nil {
+		t.Fatalf("Apply(...): %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{}, get(), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("resume Apply(...): -want annotations, +got annotations:\n%s", diff)
+	}
+}
+
+func TestSetConfigurationVersion(t *testing.T) {
+	errBoom := errors.New("boom")
+	cfg := func(pkg string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("pkg.crossplane.io/v1")
+		u.SetKind("Configuration")
+		u.SetName("platform")
+		_ = unstructured.SetNestedField(u.Object, pkg, "spec", "package")
+		return u
+	}
+
+	type want struct {
+		pkg string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		pkg     string
+		connect func(dynamic.Interface) ConnectFn
+		want    want
+	}{
+		"ConnectError": {
+			reason: "An error connecting to the control plane should be returned.",
+			pkg:    "xpkg.upbound.io/acme/platform:v1.0.0",
+			connect: func(dynamic.Interface) ConnectFn {
+				return func(context.Context, Target) (dynamic.Interface, error) { return nil, errBoom }
+			},
+			want: want{
+				pkg: "xpkg.upbound.io/acme/platform:v1.0.0",
+				err: errors.Wrap(errBoom, errConnect),
+			},
+		},
+		"Retagged": {
+			reason: "The package of the configuration should be retagged with the version.",
+			pkg:    "xpkg.upbound.io/acme/platform:v1.0.0",
+			want: want{
+				pkg: "xpkg.upbound.io/acme/platform:v1.1.0",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := newFake(cfg(tc.pkg))
+			connect := func(context.Context, Target) (dynamic.Interface, error) { return dyn, nil }
+			if tc.connect != nil {
+				connect = tc.connect(dyn)
+			}
+			err := NewSetConfigurationVersion(connect, "platform", "v1.1.0").Apply(context.Background(), Target{Name: "a"})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			u, _ := dyn.Resource(configurationGVR).Get(context.Background(), "platform", metav1.GetOptions{})
+			got, _, _ := unstructured.NestedString(u.Object, "spec", "package")
+			if diff := cmp.Diff(tc.want.pkg, got); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want package, +got package:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
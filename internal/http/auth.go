@@ -0,0 +1,149 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/tokenstore"
+)
+
+const (
+	errPersistToken = "cannot persist refreshed token"
+)
+
+// A RefreshFunc returns a new token to replace one that was rejected.
+type RefreshFunc func(ctx context.Context) (string, error)
+
+// An ApplyFunc applies a token to a request.
+type ApplyFunc func(req *http.Request, token string)
+
+// BearerToken applies a token as an Authorization bearer token.
+func BearerToken(req *http.Request, token string) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+}
+
+// CookieToken returns an ApplyFunc that applies a token as the named cookie,
+// replacing any existing cookie with that name.
+func CookieToken(name string) ApplyFunc {
+	return func(req *http.Request, token string) {
+		cs := req.Cookies()
+		req.Header.Del("Cookie")
+		for _, c := range cs {
+			if c.Name != name {
+				req.AddCookie(c)
+			}
+		}
+		req.AddCookie(&http.Cookie{Name: name, Value: token})
+	}
+}
+
+// WithTokenRefresh refreshes the token of requests rejected with status 401
+// using the supplied RefreshFunc, then retries them once with the new token
+// applied by the supplied ApplyFunc. The new token is applied to all later
+// requests.
+func WithTokenRefresh(refresh RefreshFunc, apply ApplyFunc) TransportOption {
+	return func(t *Transport) {
+		t.refresh = refresh
+		t.apply = apply
+	}
+}
+
+// WithTokenStore persists tokens obtained by WithTokenRefresh to the supplied
+// store under the supplied key.
+func WithTokenStore(s tokenstore.Store, key string) TransportOption {
+	return func(t *Transport) {
+		t.tokens = s
+		t.tokenKey = key
+	}
+}
+
+// authTransport is an http.RoundTripper that refreshes rejected tokens.
+type authTransport struct {
+	base    http.RoundTripper
+	refresh RefreshFunc
+	apply   ApplyFunc
+	store   tokenstore.Store
+	key     string
+
+	mu    sync.Mutex
+	token string
+}
+
+// RoundTrip sends the supplied request, refreshing its token and retrying it
+// once if it is rejected with status 401.
+func (a *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	a.mu.Lock()
+	token := a.token
+	a.mu.Unlock()
+	if token != "" {
+		req = req.Clone(req.Context())
+		a.apply(req, token)
+	}
+
+	rsp, err := a.base.RoundTrip(req)
+	if err != nil || rsp.StatusCode != http.StatusUnauthorized || !rewindable(req) {
+		return rsp, err
+	}
+
+	fresh, err := a.refreshToken(req.Context(), token)
+	if err != nil {
+		// The token could not be refreshed, so the original rejection is
+		// the most useful response to return.
+		return rsp, nil //nolint:nilerr
+	}
+
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	_ = rsp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if req.Body != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, errRewindBody)
+		}
+		retry.Body = body
+	}
+	a.apply(retry, fresh)
+	return a.base.RoundTrip(retry)
+}
+
+// refreshToken returns a new token to replace the supplied rejected token. If
+// a concurrent request has already replaced it, its replacement is returned.
+func (a *authTransport) refreshToken(ctx context.Context, rejected string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != rejected {
+		return a.token, nil
+	}
+	token, err := a.refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	if a.store != nil {
+		if err := a.store.Set(a.key, token); err != nil {
+			return "", errors.Wrap(err, errPersistToken)
+		}
+	}
+	a.token = token
+	return token, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/tokenstore"
+)
+
+func TestTokenRefresh(t *testing.T) {
+	type want struct {
+		status    int
+		seen      []string
+		bodies    []string
+		refreshes int
+		stored    string
+	}
+	cases := map[string]struct {
+		reason  string
+		refresh RefreshFunc
+		apply   ApplyFunc
+		header  string
+		want    want
+	}{
+		"RefreshBearer": {
+			reason: "A rejected request should be retried once with a refreshed bearer token, which is persisted.",
+			refresh: func(context.Context) (string, error) {
+				return "fresh", nil
+			},
+			apply:  BearerToken,
+			header: "Authorization",
+			want: want{
+				status:    http.StatusOK,
+				seen:      []string{"Bearer stale", "Bearer fresh", "Bearer fresh"},
+				bodies:    []string{"payload", "payload", "payload"},
+				refreshes: 1,
+				stored:    "fresh",
+			},
+		},
+		"RefreshCookie": {
+			reason: "A refreshed session should replace the session cookie of the request.",
+			refresh: func(context.Context) (string, error) {
+				return "fresh", nil
+			},
+			apply:  CookieToken("SID"),
+			header: "Cookie",
+			want: want{
+				status:    http.StatusOK,
+				seen:      []string{"SID=stale; other=value", "other=value; SID=fresh", "other=value; SID=fresh"},
+				bodies:    []string{"payload", "payload", "payload"},
+				refreshes: 1,
+				stored:    "fresh",
+			},
+		},
+		"RefreshFailed": {
+			reason: "If the token cannot be refreshed the original rejection should be returned.",
+			refresh: func(context.Context) (string, error) {
+				return "", errors.New("boom")
+			},
+			apply:  BearerToken,
+			header: "Authorization",
+			want: want{
+				status:    http.StatusUnauthorized,
+				seen:      []string{"Bearer stale", "Bearer stale"},
+				bodies:    []string{"payload", "payload"},
+				refreshes: 2,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var seen, bodies []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				v := r.Header.Get(tc.header)
+				b, _ := io.ReadAll(r.Body)
+				seen = append(seen, v)
+				bodies = append(bodies, string(b))
+				// Only refreshed tokens are accepted.
+				if !strings.Contains(v, "fresh") {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			}))
+			defer srv.Close()
+
+			store := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+			refreshes := 0
+			refresh := func(ctx context.Context) (string, error) {
+				refreshes++
+				return tc.refresh(ctx)
+			}
+			c := NewClient(
+				WithTokenRefresh(refresh, tc.apply),
+				WithTokenStore(store, "key"),
+				WithMaxRetries(0),
+			)
+
+			var status int
+			// The second request should reuse the refreshed token without
+			// refreshing it again.
+			for i := 0; i < 2; i++ {
+				req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+				tc.apply(req, "stale")
+				if tc.header == "Cookie" {
+					req.Header.Set("Cookie", "SID=stale; other=value")
+				}
+				rsp, err := c.Do(req)
+				if err != nil {
+					t.Fatalf("\n%s\nDo(...): unexpected error: %v", tc.reason, err)
+				}
+				_ = rsp.Body.Close()
+				status = rsp.StatusCode
+			}
+
+			if diff := cmp.Diff(tc.want.status, status); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.seen, seen); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want credentials, +got credentials:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.bodies, bodies); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want bodies, +got bodies:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.refreshes, refreshes); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want refreshes, +got refreshes:\n%s", tc.reason, diff)
+			}
+			stored, _ := store.Get("key")
+			if diff := cmp.Diff(tc.want.stored, stored); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want stored token, +got stored token:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
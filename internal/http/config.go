@@ -0,0 +1,105 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Config tunes the timeouts and connection pooling of the transport used to
+// make requests. Zero values fall back to those of DefaultConfig, except
+// Timeout and ResponseHeaderTimeout, for which zero means no timeout.
+type Config struct {
+	// Timeout limits the total time taken by a request, including reading
+	// the response body.
+	Timeout time.Duration
+	// DialTimeout limits the time taken to establish a connection.
+	DialTimeout time.Duration
+	// KeepAlive is the interval between keep-alive probes.
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout limits the time taken by a TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout limits the time waited for response headers once
+	// a request has been written.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout limits the time an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// MaxIdleConns limits the number of idle connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the number of idle connections per host.
+	MaxIdleConnsPerHost int
+	// ClientCertificate is presented to servers that require mutual TLS.
+	ClientCertificate ClientCertificate
+}
+
+// DefaultConfig returns the default Config.
+func DefaultConfig() Config {
+	return Config{
+		DialTimeout:           30 * time.Second,
+		KeepAlive:             30 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+	}
+}
+
+// Transport returns an *http.Transport tuned by this Config that uses the
+// supplied TLS config, which may be nil. Proxies are configured from the
+// environment. Any ClientCertificate is loaded when a server first requests
+// it.
+func (c Config) Transport(tc *tls.Config) *http.Transport {
+	d := DefaultConfig()
+	if !c.ClientCertificate.IsZero() {
+		if tc == nil {
+			tc = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tc = tc.Clone()
+		tc.GetClientCertificate = c.ClientCertificate.getter()
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   orDuration(c.DialTimeout, d.DialTimeout),
+			KeepAlive: orDuration(c.KeepAlive, d.KeepAlive),
+		}).DialContext,
+		TLSClientConfig:       tc,
+		TLSHandshakeTimeout:   orDuration(c.TLSHandshakeTimeout, d.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: c.ResponseHeaderTimeout,
+		IdleConnTimeout:       orDuration(c.IdleConnTimeout, d.IdleConnTimeout),
+		MaxIdleConns:          orInt(c.MaxIdleConns, d.MaxIdleConns),
+		MaxIdleConnsPerHost:   orInt(c.MaxIdleConnsPerHost, d.MaxIdleConnsPerHost),
+		ForceAttemptHTTP2:     true,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+func orDuration(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return d
+}
+
+func orInt(i, def int) int {
+	if i == 0 {
+		return def
+	}
+	return i
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigTransport(t *testing.T) {
+	type want struct {
+		TLSHandshakeTimeout   time.Duration
+		ResponseHeaderTimeout time.Duration
+		IdleConnTimeout       time.Duration
+		MaxIdleConns          int
+		MaxIdleConnsPerHost   int
+	}
+	cases := map[string]struct {
+		reason string
+		c      Config
+		want   want
+	}{
+		"Defaults": {
+			reason: "Zero values should fall back to defaults, except for ResponseHeaderTimeout.",
+			c:      Config{},
+			want: want{
+				TLSHandshakeTimeout: 10 * time.Second,
+				IdleConnTimeout:     90 * time.Second,
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 10,
+			},
+		},
+		"Tuned": {
+			reason: "Supplied values should be used.",
+			c: Config{
+				TLSHandshakeTimeout:   time.Second,
+				ResponseHeaderTimeout: 2 * time.Second,
+				IdleConnTimeout:       3 * time.Second,
+				MaxIdleConns:          4,
+				MaxIdleConnsPerHost:   5,
+			},
+			want: want{
+				TLSHandshakeTimeout:   time.Second,
+				ResponseHeaderTimeout: 2 * time.Second,
+				IdleConnTimeout:       3 * time.Second,
+				MaxIdleConns:          4,
+				MaxIdleConnsPerHost:   5,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tr := tc.c.Transport(nil)
+			got := want{
+				TLSHandshakeTimeout:   tr.TLSHandshakeTimeout,
+				ResponseHeaderTimeout: tr.ResponseHeaderTimeout,
+				IdleConnTimeout:       tr.IdleConnTimeout,
+				MaxIdleConns:          tr.MaxIdleConns,
+				MaxIdleConnsPerHost:   tr.MaxIdleConnsPerHost,
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nTransport(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
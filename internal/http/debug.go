@@ -0,0 +1,300 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxBodyBytes is the default number of bytes of a request or
+	// response body that is logged.
+	DefaultMaxBodyBytes = 1024
+
+	// Redacted replaces sensitive values in debug logs.
+	Redacted = "REDACTED"
+
+	mediaTypeForm = "application/x-www-form-urlencoded"
+)
+
+const (
+	// DebugBasic logs the method, URL, status, and latency of each request.
+	DebugBasic = iota + 1
+	// DebugHeaders additionally logs request and response headers.
+	DebugHeaders
+	// DebugBodies additionally logs truncated request and response bodies.
+	DebugBodies
+)
+
+// sensitive contains substrings of header, query parameter, and JSON field
+// names whose values are redacted.
+var sensitive = []string{"authorization", "cookie", "token", "secret", "password", "apikey", "api-key", "api_key"}
+
+var (
+	// reJSONField matches a JSON string field.
+	reJSONField = regexp.MustCompile(`"([^"\\]+)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// reJSONOpenField matches a JSON string field that is cut off at the end
+	// of a truncated body.
+	reJSONOpenField = regexp.MustCompile(`"([^"\\]+)"(\s*:\s*)"(?:[^"\\]|\\.)*\\?$`)
+	// reParam matches a parameter of a URL encoded query string or form.
+	reParam = regexp.MustCompile(`([^&;=]+)=([^&;]*)`)
+)
+
+// A DebugOption modifies a DebugTransport.
+type DebugOption func(*DebugTransport)
+
+// WithVerbosity sets how much of each request and response is logged. See
+// DebugBasic, DebugHeaders, and DebugBodies. Nothing is logged if v is less
+// than DebugBasic.
+func WithVerbosity(v int) DebugOption {
+	return func(t *DebugTransport) {
+		t.verbosity = v
+	}
+}
+
+// WithDebugWriter sets where requests and responses are logged. The default
+// is os.Stderr.
+func WithDebugWriter(w io.Writer) DebugOption {
+	return func(t *DebugTransport) {
+		t.out = w
+	}
+}
+
+// WithMaxBodyBytes sets the number of bytes of each request and response body
+// that is logged.
+func WithMaxBodyBytes(n int) DebugOption {
+	return func(t *DebugTransport) {
+		t.maxBody = n
+	}
+}
+
+// A DebugTransport is an http.RoundTripper that logs requests and responses.
+// Credentials such as Authorization headers, cookies, and tokens are redacted
+// so that logs are safe to share.
+type DebugTransport struct {
+	base      http.RoundTripper
+	verbosity int
+	out       io.Writer
+	maxBody   int
+
+	now func() time.Time
+}
+
+// NewDebugTransport returns a DebugTransport that sends requests using the
+// supplied RoundTripper.
+func NewDebugTransport(rt http.RoundTripper, opts ...DebugOption) *DebugTransport {
+	t := &DebugTransport{
+		base:      rt,
+		verbosity: DebugBasic,
+		out:       os.Stderr,
+		maxBody:   DefaultMaxBodyBytes,
+		now:       time.Now,
+	}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// RoundTrip sends the supplied request, logging it and its response.
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.verbosity < DebugBasic {
+		return t.base.RoundTrip(req)
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "> %s %s\n", req.Method, RedactURL(req.URL))
+	if t.verbosity >= DebugHeaders {
+		writeHeaders(b, "> ", req.Header)
+	}
+	if t.verbosity >= DebugBodies && req.Body != nil && req.Body != http.NoBody {
+		t.writeRequestBody(b, req)
+	}
+
+	start := t.now()
+	rsp, err := t.base.RoundTrip(req)
+	latency := t.now().Sub(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(b, "< error after %s: %v\n", latency, err)
+		_, _ = io.WriteString(t.out, b.String())
+		return rsp, err
+	}
+
+	fmt.Fprintf(b, "< %s in %s\n", rsp.Status, latency)
+	if t.verbosity >= DebugHeaders {
+		writeHeaders(b, "< ", rsp.Header)
+	}
+	if t.verbosity >= DebugBodies && rsp.Body != nil && rsp.Body != http.NoBody {
+		t.writeResponseBody(b, rsp)
+	}
+	_, _ = io.WriteString(t.out, b.String())
+	return rsp, nil
+}
+
+// writeRequestBody logs the request body, if it can be read without
+// consuming it.
+func (t *DebugTransport) writeRequestBody(b *strings.Builder, req *http.Request) {
+	if req.GetBody == nil {
+		b.WriteString("> (body not logged)\n")
+		return
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		fmt.Fprintf(b, "> (cannot read body: %v)\n", err)
+		return
+	}
+	defer body.Close() //nolint:errcheck // Nothing to do.
+	peek, truncated := t.peek(body)
+	t.writeBody(b, "> ", req.Header.Get("Content-Type"), peek, truncated)
+}
+
+// writeResponseBody logs the response body, replacing it with a body that
+// still returns everything that was read.
+func (t *DebugTransport) writeResponseBody(b *strings.Builder, rsp *http.Response) {
+	peek, truncated := t.peek(rsp.Body)
+	rsp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), rsp.Body), rsp.Body}
+	t.writeBody(b, "< ", rsp.Header.Get("Content-Type"), peek, truncated)
+}
+
+// peek reads up to maxBody bytes of r, and returns whether there was more to
+// read.
+func (t *DebugTransport) peek(r io.Reader) ([]byte, bool) {
+	buf := make([]byte, t.maxBody+1)
+	n, _ := io.ReadFull(r, buf)
+	if n > t.maxBody {
+		return buf[:n], true
+	}
+	return buf[:n], false
+}
+
+// writeBody logs the supplied body of the supplied media type. The body is
+// redacted before it is truncated, so that a credential is not revealed when
+// the body is cut off in the middle of it.
+func (t *DebugTransport) writeBody(b *strings.Builder, prefix, contentType string, body []byte, truncated bool) {
+	s := RedactBody(contentType, string(body))
+	if truncated && len(s) > t.maxBody {
+		s = s[:t.maxBody]
+	}
+	b.WriteString(prefix)
+	b.WriteString(s)
+	if truncated {
+		b.WriteString("... (truncated)")
+	}
+	b.WriteString("\n")
+}
+
+// RedactHeader returns a copy of the supplied header with the values of
+// sensitive headers, such as Authorization and Set-Cookie, redacted.
+func RedactHeader(h http.Header) http.Header {
+	r := h.Clone()
+	for k, v := range r {
+		if !isSensitive(k) {
+			continue
+		}
+		for i := range v {
+			v[i] = Redacted
+		}
+	}
+	return r
+}
+
+func writeHeaders(b *strings.Builder, prefix string, h http.Header) {
+	h = RedactHeader(h)
+	names := make([]string, 0, len(h))
+	for k := range h {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		for _, v := range h[k] {
+			fmt.Fprintf(b, "%s%s: %s\n", prefix, k, v)
+		}
+	}
+}
+
+// RedactURL returns the supplied URL with any password and sensitive query
+// parameters redacted.
+func RedactURL(u *url.URL) string {
+	r := *u
+	if r.User != nil {
+		r.User = url.UserPassword(r.User.Username(), Redacted)
+	}
+	r.RawQuery = RedactQuery(r.RawQuery)
+	return r.String()
+}
+
+// RedactQuery returns the supplied URL encoded query string or form with the
+// values of sensitive parameters redacted. Parameters are kept in order, and
+// are redacted even if they are malformed.
+func RedactQuery(q string) string {
+	return reParam.ReplaceAllStringFunc(q, func(m string) string {
+		sm := reParam.FindStringSubmatch(m)
+		k, err := url.QueryUnescape(sm[1])
+		if err != nil {
+			k = sm[1]
+		}
+		if !isSensitive(k) {
+			return m
+		}
+		return sm[1] + "=" + Redacted
+	})
+}
+
+// RedactBody returns the supplied body of the supplied media type with the
+// values of sensitive parameters of URL encoded forms, or of sensitive JSON
+// string fields, redacted. A sensitive JSON string field that is cut off at the
+// end of the body is redacted too.
+func RedactBody(contentType, body string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil && mt == mediaTypeForm {
+		return RedactQuery(body)
+	}
+	body = reJSONField.ReplaceAllStringFunc(body, func(m string) string {
+		sm := reJSONField.FindStringSubmatch(m)
+		if !isSensitive(sm[1]) {
+			return m
+		}
+		return fmt.Sprintf("%q%s%q", sm[1], sm[2], Redacted)
+	})
+	return reJSONOpenField.ReplaceAllStringFunc(body, func(m string) string {
+		sm := reJSONOpenField.FindStringSubmatch(m)
+		if !isSensitive(sm[1]) {
+			return m
+		}
+		return fmt.Sprintf("%q%s\"%s", sm[1], sm[2], Redacted)
+	})
+}
+
+func isSensitive(name string) bool {
+	n := strings.ToLower(name)
+	for _, s := range sensitive {
+		if strings.Contains(n, s) {
+			return true
+		}
+	}
+	return false
+}
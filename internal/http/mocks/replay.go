@@ -0,0 +1,293 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	uphttp "github.com/upbound/up/internal/http"
+)
+
+const (
+	// UpdateFixturesEnv is the environment variable that, when set to true,
+	// causes RecordOrReplay to record fixtures rather than replay them.
+	UpdateFixturesEnv = "UPDATE_FIXTURES"
+
+	errReadFixture     = "cannot read fixture"
+	errParseFixture    = "cannot parse fixture"
+	errWriteFixture    = "cannot write fixture"
+	errReadBody        = "cannot read body"
+	errFmtNoResponse   = "no recorded response for %s %s"
+	errFmtMissingInter = "%d recorded interactions were not replayed"
+)
+
+var _ uphttp.Client = &Replayer{}
+
+// A Fixture is a sequence of recorded HTTP interactions.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// An Interaction is a recorded HTTP request and the response to it.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// A RecordedRequest is a sanitized HTTP request.
+type RecordedRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// A RecordedResponse is a sanitized HTTP response.
+type RecordedResponse struct {
+	StatusCode int             `json:"statusCode"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// A Recorder is an http.RoundTripper that records the interactions it sends
+// through an underlying RoundTripper. Credentials are redacted from recorded
+// interactions.
+type Recorder struct {
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder returns a Recorder that sends requests using the supplied
+// RoundTripper.
+func NewRecorder(base http.RoundTripper) *Recorder {
+	return &Recorder{base: base}
+}
+
+// RoundTrip sends the supplied request and records it and its response.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, errReadBody)
+		}
+		_ = req.Body.Close()
+		reqBody = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	rsp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	rspBody, err := io.ReadAll(rsp.Body)
+	_ = rsp.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, errReadBody)
+	}
+	rsp.Body = io.NopCloser(bytes.NewReader(rspBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixture.Interactions = append(r.fixture.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    uphttp.RedactURL(req.URL),
+			Header: uphttp.RedactHeader(req.Header),
+			Body:   encodeBody(req.Header, reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: rsp.StatusCode,
+			Header:     uphttp.RedactHeader(rsp.Header),
+			Body:       encodeBody(rsp.Header, rspBody),
+		},
+	})
+	return rsp, nil
+}
+
+// Fixture returns the interactions recorded so far.
+func (r *Recorder) Fixture() Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Fixture{Interactions: append([]Interaction(nil), r.fixture.Interactions...)}
+}
+
+// Save writes the interactions recorded so far to the supplied path as JSON.
+func (r *Recorder) Save(path string) error {
+	b, err := json.MarshalIndent(r.Fixture(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errWriteFixture)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, errWriteFixture)
+	}
+	return errors.Wrap(os.WriteFile(path, append(b, '\n'), 0o600), errWriteFixture)
+}
+
+// A Replayer is an http.RoundTripper and Client that responds to requests
+// with recorded responses. Each recorded interaction is replayed at most once,
+// in the order it was recorded, to the first request with the same method and
+// URL.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// NewReplayer returns a Replayer that replays the supplied Fixture.
+func NewReplayer(f Fixture) *Replayer {
+	return &Replayer{
+		interactions: f.Interactions,
+		replayed:     make([]bool, len(f.Interactions)),
+	}
+}
+
+// LoadReplayer returns a Replayer that replays the fixture at the supplied
+// path.
+func LoadReplayer(path string) (*Replayer, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, errReadFixture)
+	}
+	f := Fixture{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, errors.Wrap(err, errParseFixture)
+	}
+	return NewReplayer(f), nil
+}
+
+// RoundTrip returns the recorded response to the supplied request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+
+	u := uphttp.RedactURL(req.URL)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.replayed[i] || in.Request.Method != req.Method || in.Request.URL != u {
+			continue
+		}
+		r.replayed[i] = true
+		h := in.Response.Header.Clone()
+		if h == nil {
+			h = http.Header{}
+		}
+		body := decodeBody(in.Response.Body)
+		return &http.Response{
+			Status:        http.StatusText(in.Response.StatusCode),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        h,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, errors.Errorf(errFmtNoResponse, req.Method, u)
+}
+
+// Do returns the recorded response to the supplied request.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	return r.RoundTrip(req)
+}
+
+// Err returns an error if any recorded interactions were not replayed.
+func (r *Replayer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, ok := range r.replayed {
+		if !ok {
+			n++
+		}
+	}
+	if n > 0 {
+		return errors.Errorf(errFmtMissingInter, n)
+	}
+	return nil
+}
+
+// RecordOrReplay returns an http.RoundTripper for use in tests. By default it
+// replays the fixture at the supplied path, failing the test if any recorded
+// interaction is not replayed. When UPDATE_FIXTURES=true it instead sends
+// requests using http.DefaultTransport and records them to the supplied path
+// when the test completes.
+func RecordOrReplay(t testing.TB, path string) http.RoundTripper {
+	t.Helper()
+	if os.Getenv(UpdateFixturesEnv) == "true" {
+		rec := NewRecorder(http.DefaultTransport)
+		t.Cleanup(func() {
+			if err := rec.Save(path); err != nil {
+				t.Errorf("RecordOrReplay(...): %v", err)
+			}
+		})
+		return rec
+	}
+	rep, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("RecordOrReplay(...): %v", err)
+	}
+	t.Cleanup(func() {
+		if err := rep.Err(); err != nil {
+			t.Errorf("RecordOrReplay(...): %v", err)
+		}
+	})
+	return rep
+}
+
+// encodeBody returns the supplied body, redacted according to its content
+// type, as JSON. JSON bodies are stored as is so that fixtures are readable.
+// Other bodies are stored as a JSON string.
+func encodeBody(h http.Header, b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return nil
+	}
+	s := uphttp.RedactBody(h.Get("Content-Type"), string(b))
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	q, _ := json.Marshal(s)
+	return q
+}
+
+// decodeBody returns the body stored by encodeBody. JSON bodies are
+// compacted, undoing any indentation added when the fixture was written.
+func decodeBody(b json.RawMessage) []byte {
+	s := ""
+	if err := json.Unmarshal(b, &s); err == nil {
+		return []byte(s)
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, b); err != nil {
+		return b
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "SID=cookie")
+		_, _ = io.WriteString(w, `{"name":"cool","token":"t0k3n"}`)
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder(http.DefaultTransport)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/cool?token=t0k3n", strings.NewReader(`{"password":"hunter2"}`))
+	req.Header.Set("Authorization", "Bearer t0k3n")
+	rsp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip(...): unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(rsp.Body)
+	if diff := cmp.Diff(`{"name":"cool","token":"t0k3n"}`, string(body)); diff != "" {
+		t.Errorf("RoundTrip(...): recording should not modify the response body: -want, +got:\n%s", diff)
+	}
+
+	f := rec.Fixture()
+	if len(f.Interactions) != 1 {
+		t.Fatalf("Fixture(): want 1 interaction, got %d", len(f.Interactions))
+	}
+	in := f.Interactions[0]
+	got := []string{in.Request.URL, in.Request.Header.Get("Authorization"), string(in.Request.Body), in.Response.Header.Get("Set-Cookie"), string(in.Response.Body)}
+	want := []string{srv.URL + "/v1/cool?token=REDACTED", "REDACTED", `{"password":"REDACTED"}`, "REDACTED", `{"name":"cool","token":"REDACTED"}`}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Fixture(): credentials should be redacted: -want, +got:\n%s", diff)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("Save(...): unexpected error: %v", err)
+	}
+	rep, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("LoadReplayer(...): unexpected error: %v", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/v1/cool?token=other", nil)
+	rsp, err = rep.Do(req)
+	if err != nil {
+		t.Fatalf("Do(...): unexpected error: %v", err)
+	}
+	body, _ = io.ReadAll(rsp.Body)
+	if diff := cmp.Diff(`{"name":"cool","token":"REDACTED"}`, string(body)); diff != "" {
+		t.Errorf("Do(...): -want body, +got body:\n%s", diff)
+	}
+	if err := rep.Err(); err != nil {
+		t.Errorf("Err(): unexpected error: %v", err)
+	}
+
+	if _, err := rep.Do(req); err == nil {
+		t.Errorf("Do(...): want error replaying an interaction twice, got nil")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"crypto/tls"
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errLoadClientCert    = "cannot load client certificate"
+	errIncompleteKeyPair = "a client certificate and key must be supplied together"
+)
+
+// A ClientCertificate is a certificate and private key presented to servers
+// that require mutual TLS. Each may be supplied as a path to a PEM encoded
+// file or as PEM encoded data. Data takes precedence over paths.
+type ClientCertificate struct {
+	CertFile string
+	KeyFile  string
+	CertPEM  []byte
+	KeyPEM   []byte
+}
+
+// IsZero returns true if no certificate or key was supplied.
+func (c ClientCertificate) IsZero() bool {
+	return c.CertFile == "" && c.KeyFile == "" && len(c.CertPEM) == 0 && len(c.KeyPEM) == 0
+}
+
+// Load the certificate and key.
+func (c ClientCertificate) Load() (tls.Certificate, error) {
+	hasCert := c.CertFile != "" || len(c.CertPEM) > 0
+	hasKey := c.KeyFile != "" || len(c.KeyPEM) > 0
+	if hasCert != hasKey {
+		return tls.Certificate{}, errors.New(errIncompleteKeyPair)
+	}
+	if len(c.CertPEM) > 0 && len(c.KeyPEM) > 0 {
+		cert, err := tls.X509KeyPair(c.CertPEM, c.KeyPEM)
+		return cert, errors.Wrap(err, errLoadClientCert)
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	return cert, errors.Wrap(err, errLoadClientCert)
+}
+
+// getter returns a function suitable for use as a tls.Config's
+// GetClientCertificate. The certificate is loaded the first time it is
+// requested.
+func (c ClientCertificate) getter() func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	var (
+		once sync.Once
+		cert tls.Certificate
+		err  error
+	)
+	return func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		once.Do(func() { cert, err = c.Load() })
+		return &cert, err
+	}
+}
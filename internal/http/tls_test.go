@@ -0,0 +1,147 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+// selfSigned returns a PEM encoded self-signed client certificate and key.
+func selfSigned(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "up"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kder, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder})
+}
+
+func TestClientCertificateLoad(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		cc     ClientCertificate
+		want   error
+	}{
+		"Files": {
+			reason: "A certificate and key should be loaded from files.",
+			cc:     ClientCertificate{CertFile: certFile, KeyFile: keyFile},
+		},
+		"PEM": {
+			reason: "A certificate and key should be loaded from PEM data.",
+			cc:     ClientCertificate{CertPEM: certPEM, KeyPEM: keyPEM},
+		},
+		"MissingKey": {
+			reason: "A certificate without a key should return an error.",
+			cc:     ClientCertificate{CertFile: certFile},
+			want:   errors.New(errIncompleteKeyPair),
+		},
+		"InvalidPEM": {
+			reason: "Invalid PEM data should return an error.",
+			cc:     ClientCertificate{CertPEM: keyPEM, KeyPEM: keyPEM},
+			want:   errors.Wrap(errors.New("tls: failed to find certificate PEM data in certificate input, but did find a private key; PEM inputs may have been switched"), errLoadClientCert),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.cc.Load()
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoad(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConfigTransportClientCertificate(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	tlsCfg := srv.Client().Transport.(*http.Transport).TLSClientConfig
+
+	cases := map[string]struct {
+		reason  string
+		c       Config
+		wantErr bool
+	}{
+		"NoClientCertificate": {
+			reason:  "Requests to a server that requires mutual TLS should fail without a client certificate.",
+			c:       Config{},
+			wantErr: true,
+		},
+		"ClientCertificate": {
+			reason: "Requests to a server that requires mutual TLS should succeed with a client certificate.",
+			c:      Config{ClientCertificate: ClientCertificate{CertPEM: certPEM, KeyPEM: keyPEM}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &http.Client{Transport: tc.c.Transport(tlsCfg)}
+			rsp, err := c.Get(srv.URL)
+			if err == nil {
+				_ = rsp.Body.Close()
+			}
+			if diff := cmp.Diff(tc.wantErr, err != nil); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/tokenstore"
+)
+
+const (
+	// DefaultMaxRetries is the default number of times a request is retried.
+	DefaultMaxRetries = 4
+	// DefaultMinBackoff is the default time waited before the first retry.
+	DefaultMinBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff is the default maximum time waited between retries,
+	// including time requested by a Retry-After header.
+	DefaultMaxBackoff = 30 * time.Second
+
+	errRateLimit   = "cannot wait for rate limiter"
+	errRewindBody  = "cannot rewind request body to retry request"
+	errWaitBackoff = "cannot wait to retry request"
+)
+
+// A TransportOption modifies a Transport.
+type TransportOption func(*Transport)
+
+// WithBaseTransport sets the RoundTripper requests are sent with. The
+// default is tuned by DefaultConfig.
+func WithBaseTransport(rt http.RoundTripper) TransportOption {
+	return func(t *Transport) {
+		t.base = rt
+	}
+}
+
+// WithConfig sends requests using a transport tuned by the supplied Config.
+// The Config's Timeout applies to clients returned by NewClient.
+func WithConfig(c Config) TransportOption {
+	return func(t *Transport) {
+		t.base = c.Transport(nil)
+		t.timeout = c.Timeout
+	}
+}
+
+// WithMaxRetries sets the number of times a request that failed with a
+// retryable status is retried. Zero disables retries.
+func WithMaxRetries(n int) TransportOption {
+	return func(t *Transport) {
+		t.maxRetries = n
+	}
+}
+
+// WithBackoff sets the minimum and maximum time waited between retries. The
+// time waited doubles with each retry.
+func WithBackoff(minimum, maximum time.Duration) TransportOption {
+	return func(t *Transport) {
+		t.minBackoff = minimum
+		t.maxBackoff = maximum
+	}
+}
+
+// WithRateLimit limits the rate at which requests, including retries, are
+// sent to r requests per second with bursts of up to burst requests. Requests
+// are not rate limited by default.
+func WithRateLimit(r float64, burst int) TransportOption {
+	return func(t *Transport) {
+		t.limiter = rate.NewLimiter(rate.Limit(r), burst)
+	}
+}
+
+// RetryFn is called before a request is retried with the number of the
+// attempt that failed, starting at zero.
+type RetryFn func(req *http.Request, attempt int)
+
+// WithOnRetry sets a function that is called before each retry, e.g. to
+// record metrics.
+func WithOnRetry(fn RetryFn) TransportOption {
+	return func(t *Transport) {
+		t.onRetry = fn
+	}
+}
+
+// WithTracerProvider sets the TracerProvider request spans are created with.
+// The global TracerProvider is used by default.
+func WithTracerProvider(tp trace.TracerProvider) TransportOption {
+	return func(t *Transport) {
+		t.tracing = append(t.tracing, otelhttp.WithTracerProvider(tp))
+	}
+}
+
+// Transport is an http.RoundTripper that rate limits requests and retries
+// those that fail with status 429 or 5xx, honoring any Retry-After header.
+// Requests that aren't idempotent, such as POST, may have taken effect despite
+// failing, so they are only retried if the server explicitly asked for it with
+// status 429 or 503 and a Retry-After header.
+// If configured WithTokenRefresh, requests rejected with status 401 are
+// retried once with a refreshed token. Requests are only retried if their body
+// can be rewound.
+type Transport struct {
+	base       http.RoundTripper
+	timeout    time.Duration
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	limiter    *rate.Limiter
+	tracing    []otelhttp.Option
+	refresh    RefreshFunc
+	apply      ApplyFunc
+	tokens     tokenstore.Store
+	tokenKey   string
+	onRetry    RetryFn
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewTransport returns a RoundTripper that rate limits and retries requests
+// sent with a Transport, and creates an OpenTelemetry span per request.
+func NewTransport(opts ...TransportOption) http.RoundTripper {
+	return newTransport(opts...).traced()
+}
+
+// NewClient returns an *http.Client that sends requests using NewTransport.
+func NewClient(opts ...TransportOption) *http.Client {
+	t := newTransport(opts...)
+	return &http.Client{Transport: t.traced(), Timeout: t.timeout}
+}
+
+func newTransport(opts ...TransportOption) *Transport {
+	t := &Transport{
+		base:       DefaultConfig().Transport(nil),
+		maxRetries: DefaultMaxRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+		sleep:      sleep,
+	}
+	for _, o := range opts {
+		o(t)
+	}
+	if t.refresh != nil {
+		t.base = &authTransport{
+			base:    t.base,
+			refresh: t.refresh,
+			apply:   t.apply,
+			store:   t.tokens,
+			key:     t.tokenKey,
+		}
+	}
+	return t
+}
+
+func (t *Transport) traced() http.RoundTripper {
+	return otelhttp.NewTransport(t, t.tracing...)
+}
+
+// RoundTrip sends the supplied request, retrying it if it fails with a
+// retryable status.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.Wait(ctx); err != nil {
+				return nil, errors.Wrap(err, errRateLimit)
+			}
+		}
+
+		rsp, err := t.base.RoundTrip(req)
+		if err != nil || !retryable(req, rsp) || attempt >= t.maxRetries || !rewindable(req) {
+			return rsp, err
+		}
+
+		wait := t.backoff(attempt, rsp)
+		// Drain the body so that the connection can be reused.
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		_ = rsp.Body.Close()
+
+		if t.onRetry != nil {
+			t.onRetry(req, attempt)
+		}
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, errors.Wrap(err, errWaitBackoff)
+		}
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, errors.Wrap(err, errRewindBody)
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// backoff returns the time to wait before retrying a request after the
+// supplied attempt failed with the supplied response.
+func (t *Transport) backoff(attempt int, rsp *http.Response) time.Duration {
+	if d, ok := retryAfter(rsp.Header.Get("Retry-After"), time.Now()); ok {
+		if d > t.maxBackoff {
+			return t.maxBackoff
+		}
+		return d
+	}
+	d := t.minBackoff
+	for i := 0; i < attempt && d < t.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > t.maxBackoff {
+		return t.maxBackoff
+	}
+	return d
+}
+
+// retryable returns true if the supplied request, which failed with the
+// supplied response, should be retried.
+func retryable(req *http.Request, rsp *http.Response) bool {
+	s := rsp.StatusCode
+	if s != http.StatusTooManyRequests && (s < 500 || s == http.StatusNotImplemented) {
+		return false
+	}
+	if idempotent(req.Method) {
+		return true
+	}
+	return (s == http.StatusTooManyRequests || s == http.StatusServiceUnavailable) && rsp.Header.Get("Retry-After") != ""
+}
+
+// idempotent returns true if sending a request with the supplied method more
+// than once has the same effect as sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// rewindable returns true if the body of the supplied request can be sent
+// again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryAfter parses the supplied Retry-After header, which is either a number
+// of seconds or an HTTP date.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTransportRoundTrip(t *testing.T) {
+	type args struct {
+		method     string
+		statuses   []int
+		retryAfter string
+		body       func() io.Reader
+		opts       []TransportOption
+	}
+	type want struct {
+		status   int
+		attempts int
+		waits    []time.Duration
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "A successful request should not be retried.",
+			args: args{
+				statuses: []int{http.StatusOK},
+			},
+			want: want{
+				status:   http.StatusOK,
+				attempts: 1,
+			},
+		},
+		"RetryServerError": {
+			reason: "Requests that fail with a 5xx status should be retried with exponential backoff.",
+			args: args{
+				statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+				opts:     []TransportOption{WithBackoff(time.Second, time.Minute)},
+			},
+			want: want{
+				status:   http.StatusOK,
+				attempts: 3,
+				waits:    []time.Duration{time.Second, 2 * time.Second},
+			},
+		},
+		"RetryTooManyRequests": {
+			reason: "Requests that fail with status 429 should be retried after the time requested by Retry-After.",
+			args: args{
+				statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+				retryAfter: "7",
+			},
+			want: want{
+				status:   http.StatusOK,
+				attempts: 2,
+				waits:    []time.Duration{7 * time.Second},
+			},
+		},
+		"RetryAfterCapped": {
+			reason: "The time requested by Retry-After should be capped at the maximum backoff.",
+			args: args{
+				statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+				retryAfter: "3600",
+				opts:       []TransportOption{WithBackoff(time.Second, 10*time.Second)},
+			},
+			want: want{
+				status:   http.StatusOK,
+				attempts: 2,
+				waits:    []time.Duration{10 * time.Second},
+			},
+		},
+		"RetriesExhausted": {
+			reason: "The last response should be returned once all retries are exhausted.",
+			args: args{
+				statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+				opts:     []TransportOption{WithMaxRetries(1), WithBackoff(time.Second, time.Minute)},
+			},
+			want: want{
+				status:   http.StatusInternalServerError,
+				attempts: 2,
+				waits:    []time.Duration{time.Second},
+			},
+		},
+		"NotRetryable": {
+			reason: "Requests that fail with a 4xx status other than 429 should not be retried.",
+			args: args{
+				statuses: []int{http.StatusBadRequest, http.StatusOK},
+			},
+			want: want{
+				status:   http.StatusBadRequest,
+				attempts: 1,
+			},
+		},
+		"RetryRewindableBody": {
+			reason: "Requests with a body that can be rewound should be retried.",
+			args: args{
+				method:   http.MethodPut,
+				statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+				body:     func() io.Reader { return strings.NewReader("body") },
+				opts:     []TransportOption{WithBackoff(time.Second, time.Minute)},
+			},
+			want: want{
+				status:   http.StatusOK,
+				attempts: 2,
+				waits:    []time.Duration{time.Second},
+			},
+		},
+		"BodyNotRewindable": {
+			reason: "Requests with a body that cannot be rewound should not be retried.",
+			args: args{
+				method:   http.MethodPut,
+				statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+				body:     func() io.Reader { return io.LimitReader(strings.NewReader("body"), 4) },
+			},
+			want: want{
+				status:   http.StatusServiceUnavailable,
+				attempts: 1,
+			},
+		},
+		"PostNotRetriedOnServerError": {
+			reason: "Requests that aren't idempotent may have taken effect, so they should not be retried if they fail with a 5xx status.",
+			args: args{
+				method:   http.MethodPost,
+				statuses: []int{http.StatusInternalServerError, http.StatusOK},
+				body:     func() io.Reader { return strings.NewReader("body") },
+			},
+			want: want{
+				status:   http.StatusInternalServerError,
+				attempts: 1,
+			},
+		},
+		"PostNotRetriedOnBadGatewayWithRetryAfter": {
+			reason: "Requests that aren't idempotent should not be retried on a 5xx status other than 503, even with Retry-After.",
+			args: args{
+				method:     http.MethodPost,
+				statuses:   []int{http.StatusBadGateway, http.StatusOK},
+				retryAfter: "1",
+				body:       func() io.Reader { return strings.NewReader("body") },
+			},
+			want: want{
+				status:   http.StatusBadGateway,
+				attempts: 1,
+			},
+		},
+		"PostRetriedWhenAsked": {
+			reason: "Requests that aren't idempotent should be retried if the server asks for it with status 503 and Retry-After.",
+			args: args{
+				method:     http.MethodPost,
+				statuses:   []int{http.StatusServiceUnavailable, http.StatusOK},
+				retryAfter: "2",
+				body:       func() io.Reader { return strings.NewReader("body") },
+			},
+			want: want{
+				status:   http.StatusOK,
+				attempts: 2,
+				waits:    []time.Duration{2 * time.Second},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if b, _ := io.ReadAll(r.Body); tc.args.body != nil && string(b) != "body" {
+					t.Errorf("\n%s\nRoundTrip(...): unexpected request body %q", tc.reason, string(b))
+				}
+				if tc.args.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.args.retryAfter)
+				}
+				w.WriteHeader(tc.args.statuses[attempts])
+				attempts++
+			}))
+			defer srv.Close()
+
+			var waits []time.Duration
+			tr := newTransport(tc.args.opts...)
+			tr.sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			retries := 0
+			tr.onRetry = func(_ *http.Request, _ int) { retries++ }
+
+			var body io.Reader
+			if tc.args.body != nil {
+				body = tc.args.body()
+			}
+			method := tc.args.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, err := http.NewRequestWithContext(context.Background(), method, srv.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rsp, err := tr.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("\n%s\nRoundTrip(...): unexpected error: %v", tc.reason, err)
+			}
+			defer rsp.Body.Close() //nolint:errcheck // Nothing to do.
+
+			if diff := cmp.Diff(tc.want.status, rsp.StatusCode); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.attempts, attempts); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want attempts, +got attempts:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.waits, waits); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want waits, +got waits:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.attempts-1, retries); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want retries, +got retries:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTransportRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// Allow one request immediately, then one every 50ms.
+	c := NewClient(WithRateLimit(20, 1))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		rsp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get(...): unexpected error: %v", err)
+		}
+		_ = rsp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Get(...): three rate limited requests took %s, want at least 100ms", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	type want struct {
+		d  time.Duration
+		ok bool
+	}
+	cases := map[string]struct {
+		reason string
+		v      string
+		want   want
+	}{
+		"Empty": {
+			reason: "An empty header should not be parsed.",
+			want:   want{},
+		},
+		"Seconds": {
+			reason: "A number of seconds should be parsed.",
+			v:      "120",
+			want:   want{d: 2 * time.Minute, ok: true},
+		},
+		"Date": {
+			reason: "An HTTP date should be parsed relative to now.",
+			v:      now.Add(30 * time.Second).Format(http.TimeFormat),
+			want:   want{d: 30 * time.Second, ok: true},
+		},
+		"PastDate": {
+			reason: "An HTTP date in the past should mean retry immediately.",
+			v:      now.Add(-time.Minute).Format(http.TimeFormat),
+			want:   want{d: 0, ok: true},
+		},
+		"Invalid": {
+			reason: "An invalid header should not be parsed.",
+			v:      "soon",
+			want:   want{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, ok := retryAfter(tc.v, now)
+			if diff := cmp.Diff(tc.want, want{d: d, ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nretryAfter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
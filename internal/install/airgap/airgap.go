@@ -0,0 +1,362 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package airgap bundles the charts and images of an install into a single
+// archive and imports them into a registry of a disconnected environment.
+package airgap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/internal/xpkg/mirror"
+)
+
+const (
+	// chartsDir is the directory of the bundle holding chart archives.
+	chartsDir = "charts"
+
+	// imageDir is the directory of the bundle holding the OCI image layout.
+	imageDir = "images"
+
+	// manifestFile is the file of the bundle describing its content.
+	manifestFile = "bundle.yaml"
+
+	// refAnnotation annotates each image of the layout with the reference
+	// it was bundled from.
+	refAnnotation = "org.opencontainers.image.ref.name"
+
+	errFmtLoadChart   = "failed to load chart %s"
+	errFmtRenderChart = "failed to render chart %s"
+	errFmtParseRef    = "failed to parse image reference %s"
+	errFmtFetchImage  = "failed to fetch image %s"
+	errFmtPushImage   = "failed to push image %s"
+	errCreateLayout   = "failed to create image layout"
+	errFmtAppend      = "failed to add image %s to bundle"
+	errWriteManifest  = "failed to write bundle manifest"
+	errReadManifest   = "failed to read bundle manifest"
+	errReadLayout     = "failed to read image layout"
+	errFmtCopyChart   = "failed to add chart %s to bundle"
+	errFmtMissingRef  = "image %s of the bundle has no reference"
+)
+
+// A Chart is a chart archive bundled together with the values used to
+// resolve its images.
+type Chart struct {
+	Path   string
+	Values map[string]any
+}
+
+// An Image is an image of a bundle.
+type Image struct {
+	// Reference the image was bundled from.
+	Reference string `json:"reference"`
+
+	// Digest of the image or index.
+	Digest string `json:"digest"`
+}
+
+// A Manifest describes the content of a bundle.
+type Manifest struct {
+	Charts []string `json:"charts"`
+	Images []Image  `json:"images"`
+}
+
+// A Mapping maps a bundled image to where it was imported to.
+type Mapping struct {
+	Source      string
+	Destination string
+}
+
+// A Bundler exports and imports bundles.
+type Bundler struct {
+	remoteOpts []remote.Option
+	nameOpts   []name.Option
+}
+
+// An Option modifies a Bundler.
+type Option func(*Bundler)
+
+// WithRemoteOptions sets the options used to talk to registries.
+func WithRemoteOptions(o ...remote.Option) Option {
+	return func(b *Bundler) {
+		b.remoteOpts = o
+	}
+}
+
+// WithNameOptions sets the options used to parse image references.
+func WithNameOptions(o ...name.Option) Option {
+	return func(b *Bundler) {
+		b.nameOpts = o
+	}
+}
+
+// New returns a Bundler.
+func New(opts ...Option) *Bundler {
+	b := &Bundler{}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// ChartImages returns the images referenced by the manifests the supplied
+// chart renders with the supplied values, sorted and without duplicates.
+func ChartImages(ch *chart.Chart, values map[string]any) ([]string, error) {
+	rv, err := chartutil.ToRenderValues(ch, values, chartutil.ReleaseOptions{
+		Name:      ch.Name(),
+		Namespace: "default",
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, err
+	}
+	files, err := engine.Render(ch, rv)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for f, content := range files {
+		if !strings.HasSuffix(f, ".yaml") && !strings.HasSuffix(f, ".yml") {
+			continue
+		}
+		for _, doc := range strings.Split(content, "\n---") {
+			obj := map[string]any{}
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				// Templates that don't render to a single object, such
+				// as NOTES, can't reference images.
+				continue
+			}
+			collectImages(obj, seen)
+		}
+	}
+	imgs := make([]string, 0, len(seen))
+	for i := range seen {
+		imgs = append(imgs, i)
+	}
+	sort.Strings(imgs)
+	return imgs, nil
+}
+
+// collectImages adds every string value of an image field of the supplied
+// object to seen.
+func collectImages(v any, seen map[string]bool) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			if s, ok := e.(string); ok && k == "image" && s != "" {
+				seen[s] = true
+				continue
+			}
+			collectImages(e, seen)
+		}
+	case []any:
+		for _, e := range t {
+			collectImages(e, seen)
+		}
+	}
+}
+
+// Export writes a bundle of the supplied charts, the images they reference
+// and the supplied additional images, such as provider and function
+// packages, to the supplied directory. Archive the directory with Pack to
+// produce a single file.
+func (b *Bundler) Export(ctx context.Context, dir string, charts []Chart, images []string) (*Manifest, error) { //nolint:gocyclo
+	m := &Manifest{}
+	refs := map[string]bool{}
+	for _, i := range images {
+		refs[i] = true
+	}
+	if err := os.MkdirAll(filepath.Join(dir, chartsDir), 0o755); err != nil {
+		return nil, err
+	}
+	for _, c := range charts {
+		ch, err := loader.Load(c.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtLoadChart, c.Path)
+		}
+		imgs, err := ChartImages(ch, c.Values)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtRenderChart, c.Path)
+		}
+		for _, i := range imgs {
+			refs[i] = true
+		}
+		base := filepath.Base(c.Path)
+		if err := copyFile(c.Path, filepath.Join(dir, chartsDir, base)); err != nil {
+			return nil, errors.Wrapf(err, errFmtCopyChart, c.Path)
+		}
+		m.Charts = append(m.Charts, filepath.Join(chartsDir, base))
+	}
+
+	p, err := layout.Write(filepath.Join(dir, imageDir), empty.Index)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateLayout)
+	}
+	sorted := make([]string, 0, len(refs))
+	for r := range refs {
+		sorted = append(sorted, r)
+	}
+	sort.Strings(sorted)
+	for _, r := range sorted {
+		d, err := b.appendImage(ctx, p, r)
+		if err != nil {
+			return nil, err
+		}
+		m.Images = append(m.Images, Image{Reference: r, Digest: d.String()})
+	}
+
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, errWriteManifest)
+	}
+	return m, errors.Wrap(os.WriteFile(filepath.Join(dir, manifestFile), out, 0o644), errWriteManifest) //nolint:gosec // the manifest is not sensitive
+}
+
+// appendImage fetches the image or index with the supplied reference and
+// appends it to the supplied layout.
+func (b *Bundler) appendImage(ctx context.Context, p layout.Path, ref string) (v1.Hash, error) {
+	r, err := name.ParseReference(ref, b.nameOpts...)
+	if err != nil {
+		return v1.Hash{}, errors.Wrapf(err, errFmtParseRef, ref)
+	}
+	desc, err := remote.Get(r, append(b.remoteOpts, remote.WithContext(ctx))...)
+	if err != nil {
+		return v1.Hash{}, errors.Wrapf(err, errFmtFetchImage, ref)
+	}
+	ann := layout.WithAnnotations(map[string]string{refAnnotation: ref})
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return v1.Hash{}, errors.Wrapf(err, errFmtFetchImage, ref)
+		}
+		return desc.Digest, errors.Wrapf(p.AppendIndex(idx, ann), errFmtAppend, ref)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return v1.Hash{}, errors.Wrapf(err, errFmtFetchImage, ref)
+	}
+	return desc.Digest, errors.Wrapf(p.AppendImage(img, ann), errFmtAppend, ref)
+}
+
+// ReadManifest reads the manifest of the bundle in the supplied directory.
+func ReadManifest(dir string) (*Manifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, manifestFile)) //nolint:gosec // reading the bundle is intended
+	if err != nil {
+		return nil, errors.Wrap(err, errReadManifest)
+	}
+	m := &Manifest{}
+	return m, errors.Wrap(yaml.Unmarshal(b, m), errReadManifest)
+}
+
+// Import pushes every image of the bundle in the supplied directory to the
+// supplied registry, which may include a path prefix, keeping the
+// repository path and tag of each image. It returns where each image was
+// pushed to.
+func (b *Bundler) Import(ctx context.Context, dir, registry string) ([]Mapping, error) {
+	p, err := layout.FromPath(filepath.Join(dir, imageDir))
+	if err != nil {
+		return nil, errors.Wrap(err, errReadLayout)
+	}
+	idx, err := p.ImageIndex()
+	if err != nil {
+		return nil, errors.Wrap(err, errReadLayout)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, errors.Wrap(err, errReadLayout)
+	}
+	opts := append(b.remoteOpts, remote.WithContext(ctx)) //nolint:gocritic // appending to a copy is intended
+	mappings := make([]Mapping, 0, len(im.Manifests))
+	for _, desc := range im.Manifests {
+		ref := desc.Annotations[refAnnotation]
+		if ref == "" {
+			return nil, errors.Errorf(errFmtMissingRef, desc.Digest)
+		}
+		dst, err := b.destination(ref, registry)
+		if err != nil {
+			return nil, err
+		}
+		if desc.MediaType.IsIndex() {
+			ii, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, errors.Wrap(err, errReadLayout)
+			}
+			err = remote.WriteIndex(dst, ii, opts...)
+			if err != nil {
+				return nil, errors.Wrapf(err, errFmtPushImage, dst)
+			}
+		} else {
+			img, err := idx.Image(desc.Digest)
+			if err != nil {
+				return nil, errors.Wrap(err, errReadLayout)
+			}
+			if err := remote.Write(dst, img, opts...); err != nil {
+				return nil, errors.Wrapf(err, errFmtPushImage, dst)
+			}
+		}
+		mappings = append(mappings, Mapping{Source: ref, Destination: dst.String()})
+	}
+	return mappings, nil
+}
+
+// destination returns the reference the supplied image is imported to.
+// Images bundled by digest are pushed by digest, all others by tag.
+func (b *Bundler) destination(ref, registry string) (name.Reference, error) {
+	src, err := name.ParseReference(ref, b.nameOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtParseRef, ref)
+	}
+	repo, err := mirror.Rebase(src.Context(), registry, b.nameOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := src.(name.Tag); ok {
+		return repo.Tag(t.TagStr()), nil
+	}
+	return repo.Digest(src.Identifier()), nil
+}
+
+// ChartPaths returns the paths of the chart archives of the bundle in the
+// supplied directory.
+func (m *Manifest) ChartPaths(dir string) []string {
+	paths := make([]string, len(m.Charts))
+	for i, c := range m.Charts {
+		paths[i] = filepath.Join(dir, c)
+	}
+	return paths
+}
+
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src) //nolint:gosec // reading the chart is intended
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, b, 0o644) //nolint:gosec // charts are not sensitive
+}
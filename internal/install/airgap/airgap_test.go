@@ -0,0 +1,201 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: {{ .Values.init }}
+      containers:
+      - name: controller
+        image: {{ .Values.image.repository }}:{{ .Values.image.tag }}
+`
+
+func newRegistry(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func push(t *testing.T, ref string) v1.Hash {
+	t.Helper()
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(r, img); err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func newChart(t *testing.T, reg string) (*chart.Chart, string) {
+	t.Helper()
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "demo", Version: "1.0.0"},
+		Values: map[string]any{
+			"registry": reg,
+			"init":     reg + "/acme/init:v1",
+			"image":    map[string]any{"repository": reg + "/acme/controller", "tag": "v1"},
+		},
+		Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: []byte(deployment)}},
+	}
+	raw, err := yaml.Marshal(ch.Values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.Raw = []*chart.File{{Name: chartutil.ValuesfileName, Data: raw}}
+	path, err := chartutil.Save(ch, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, path
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newRegistry(t), newRegistry(t)
+	ch, path := newChart(t, src)
+	push(t, src+"/acme/init:v1")
+	controller := push(t, src+"/acme/controller:v1")
+	pkg := push(t, src+"/acme/provider:v2")
+
+	b := New()
+	dir := t.TempDir()
+	m, err := b.Export(ctx, dir, []Chart{{Path: path}}, []string{src + "/acme/provider:v2"})
+	if err != nil {
+		t.Fatalf("Export(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{"charts/demo-1.0.0.tgz"}, m.Charts); diff != "" {
+		t.Errorf("Export(...): -want charts, +got charts:\n%s", diff)
+	}
+	if diff := cmp.Diff(3, len(m.Images)); diff != "" {
+		t.Errorf("Export(...): -want images, +got images:\n%s", diff)
+	}
+
+	// Round trip the bundle through its archive.
+	var buf bytes.Buffer
+	if err := Pack(dir, &buf); err != nil {
+		t.Fatalf("Pack(...): %v", err)
+	}
+	out := t.TempDir()
+	if err := Unpack(&buf, out); err != nil {
+		t.Fatalf("Unpack(...): %v", err)
+	}
+	got, err := ReadManifest(out)
+	if err != nil {
+		t.Fatalf("ReadManifest(...): %v", err)
+	}
+	if diff := cmp.Diff(m, got); diff != "" {
+		t.Errorf("ReadManifest(...): -want, +got:\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(out, "charts", "demo-1.0.0.tgz")); err != nil {
+		t.Errorf("Unpack(...): chart missing: %v", err)
+	}
+
+	mappings, err := b.Import(ctx, out, dst+"/mirror")
+	if err != nil {
+		t.Fatalf("Import(...): %v", err)
+	}
+	for ref, want := range map[string]v1.Hash{
+		dst + "/mirror/acme/controller:v1": controller,
+		dst + "/mirror/acme/provider:v2":   pkg,
+	} {
+		r, err := name.ParseReference(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := remote.Head(r)
+		if err != nil {
+			t.Fatalf("Import(...): %s not pushed: %v", ref, err)
+		}
+		if diff := cmp.Diff(want, desc.Digest); diff != "" {
+			t.Errorf("Import(...): -want digest, +got digest of %s:\n%s", ref, diff)
+		}
+	}
+
+	values, err := RewriteValues(ch, map[string]any{"image": map[string]any{"tag": "v1"}}, mappings)
+	if err != nil {
+		t.Fatalf("RewriteValues(...): %v", err)
+	}
+	want := map[string]any{
+		"registry": dst + "/mirror",
+		"init":     dst + "/mirror/acme/init:v1",
+		"image":    map[string]any{"repository": dst + "/mirror/acme/controller"},
+	}
+	if diff := cmp.Diff(want, values); diff != "" {
+		t.Errorf("RewriteValues(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestUnpackRejectsEscapingPaths(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0o600, Size: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("evil")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unpack(&buf, t.TempDir()); err == nil {
+		t.Error("Unpack(...): expected error for path escaping the target directory")
+	}
+}
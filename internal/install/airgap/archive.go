@@ -0,0 +1,128 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errPack           = "failed to pack bundle"
+	errUnpack         = "failed to unpack bundle"
+	errFmtInvalidPath = "invalid path %q in bundle"
+)
+
+// Pack writes the bundle in the supplied directory to the supplied writer
+// as a gzipped tarball.
+func Pack(dir string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path) //nolint:gosec // packing the bundle is intended
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck // read only
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, errPack)
+	}
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, errPack)
+	}
+	return errors.Wrap(gw.Close(), errPack)
+}
+
+// Unpack extracts the gzipped bundle tarball read from the supplied reader
+// to the supplied directory.
+func Unpack(r io.Reader, dir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrap(err, errUnpack)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, errUnpack)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(hdr.Name)) //nolint:gosec // checked below
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return errors.Errorf(errFmtInvalidPath, hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return errors.Wrap(err, errUnpack)
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tr); err != nil {
+				return errors.Wrap(err, errUnpack)
+			}
+		default:
+			return errors.Errorf(errFmtInvalidPath, hdr.Name)
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path) //nolint:gosec // the path is checked by the caller
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec // bundles are trusted input
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
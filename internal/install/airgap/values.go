@@ -0,0 +1,115 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airgap
+
+import (
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// registryKey is the name of chart values that hold only the registry of
+// images, such as the registry value of the Spaces chart.
+const registryKey = "registry"
+
+// RewriteValues returns the values that point the images of the supplied
+// chart at the registry they were imported to. It considers the default
+// values of the chart overridden by the supplied values. A value is
+// rewritten if it is the repository of an imported image, optionally with
+// a tag or digest, or if it is a registry value, optionally with a path,
+// naming the registry of an imported image. Only rewritten values are returned, so they can be
+// passed to an install as an additional values file.
+func RewriteValues(ch *chart.Chart, values map[string]any, mappings []Mapping, opts ...name.Option) (map[string]any, error) {
+	merged, err := chartutil.CoalesceValues(ch, values)
+	if err != nil {
+		return nil, err
+	}
+	repos := map[string]string{}
+	registries := map[string]string{}
+	for _, m := range mappings {
+		src, err := name.ParseReference(m.Source, opts...)
+		if err != nil {
+			continue
+		}
+		dst, err := name.ParseReference(m.Destination, opts...)
+		if err != nil {
+			continue
+		}
+		repos[src.Context().Name()] = dst.Context().Name()
+		// Also match the repository as written in the source reference,
+		// which may omit the default registry.
+		if i := strings.LastIndexAny(m.Source, ":@"); i > strings.LastIndex(m.Source, "/") {
+			repos[m.Source[:i]] = dst.Context().Name()
+		}
+		registries[src.Context().RegistryStr()] = strings.TrimSuffix(dst.Context().Name(), "/"+src.Context().RepositoryStr())
+	}
+	out, _ := rewrite(map[string]any(merged), repos, registries).(map[string]any)
+	if out == nil {
+		out = map[string]any{}
+	}
+	return out, nil
+}
+
+// rewrite returns the rewritten parts of the supplied value, or nil if
+// nothing was rewritten.
+func rewrite(v any, repos, registries map[string]string) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := map[string]any{}
+	for k, e := range m {
+		if s, ok := e.(string); ok {
+			if r, ok := rewriteString(k, s, repos, registries); ok {
+				out[k] = r
+			}
+			continue
+		}
+		if r := rewrite(e, repos, registries); r != nil {
+			out[k] = r
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func rewriteString(key, s string, repos, registries map[string]string) (string, bool) {
+	if key == registryKey {
+		host, path, _ := strings.Cut(s, "/")
+		to, ok := registries[host]
+		if !ok {
+			return "", false
+		}
+		if path == "" {
+			return to, true
+		}
+		return to + "/" + path, true
+	}
+	for src, dst := range repos {
+		if s == src {
+			return dst, true
+		}
+		for _, sep := range []string{":", "@"} {
+			if strings.HasPrefix(s, src+sep) {
+				return dst + strings.TrimPrefix(s, src), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,307 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connector connects an app cluster to a control plane by installing
+// the MCP connector, which projects the claim APIs of the control plane into
+// the app cluster.
+package connector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/internal/install"
+)
+
+const (
+	// Name of the connector release, deployment and service.
+	Name = "mcp-connector"
+
+	// DefaultNamespace is the namespace the connector is installed in.
+	DefaultNamespace = "kube-system"
+
+	// kubeconfigKey is the key of the control plane secret holding the
+	// kubeconfig.
+	kubeconfigKey = "kubeconfig"
+
+	// managedByLabel marks secrets written by the connector subsystem, so
+	// they are removed on disconnect.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "up"
+
+	defaultTimeout      = 5 * time.Minute
+	defaultPollInterval = 2 * time.Second
+
+	errWriteSecret       = "failed to write control plane secret"
+	errFmtGetSecret      = "failed to get control plane secret %s"
+	errGetDeployment     = "failed to get connector deployment"
+	errListAPIServices   = "failed to list API services"
+	errDeleteAPIService  = "failed to delete API service"
+	errDeleteSecret      = "failed to delete control plane secret"
+	errDeploymentPending = "connector is not healthy: deployment is not available"
+	errNoAPIServices     = "connector is not healthy: no API services are registered"
+	errFmtAPIsPending    = "connector is not healthy: API services not available: %s"
+)
+
+var apiServiceGVR = schema.GroupVersionResource{
+	Group:    "apiregistration.k8s.io",
+	Version:  "v1",
+	Resource: "apiservices",
+}
+
+// Config configures the connection to a control plane.
+type Config struct {
+	// Account that owns the control plane.
+	Account string
+
+	// ControlPlane to connect to.
+	ControlPlane string
+
+	// Namespace in the control plane where the claims of the app cluster
+	// are stored.
+	Namespace string
+
+	// Host of the control plane proxy.
+	Host string
+
+	// Token used to authenticate to the control plane.
+	Token string
+
+	// Secret holding the kubeconfig of the control plane. If Kubeconfig is
+	// set, it is written to this secret. Otherwise the secret must exist
+	// already. If empty, the chart provisions a secret from Token.
+	Secret string
+
+	// Kubeconfig of the control plane.
+	Kubeconfig []byte
+}
+
+// A Connector connects an app cluster to a control plane.
+type Connector struct {
+	mgr       install.Manager
+	kube      kubernetes.Interface
+	dyn       dynamic.Interface
+	namespace string
+
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// An Option modifies a Connector.
+type Option func(*Connector)
+
+// WithNamespace sets the namespace the connector is installed in.
+func WithNamespace(ns string) Option {
+	return func(c *Connector) {
+		c.namespace = ns
+	}
+}
+
+// WithTimeout sets how long to wait for the connector to become healthy
+// and, when disconnecting, for its APIs to be removed.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Connector) {
+		c.timeout = d
+	}
+}
+
+// WithPollInterval sets how often the connector is checked.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Connector) {
+		c.pollInterval = d
+	}
+}
+
+// New returns a Connector that installs the connector with the supplied
+// Manager.
+func New(mgr install.Manager, kube kubernetes.Interface, dyn dynamic.Interface, opts ...Option) *Connector {
+	c := &Connector{
+		mgr:          mgr,
+		kube:         kube,
+		dyn:          dyn,
+		namespace:    DefaultNamespace,
+		timeout:      defaultTimeout,
+		pollInterval: defaultPollInterval,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Connect wires the control plane secret, installs the connector with the
+// supplied values and waits until it is healthy.
+func (c *Connector) Connect(ctx context.Context, cfg Config, values map[string]any) error {
+	mcp := map[string]any{
+		"account":   cfg.Account,
+		"name":      cfg.ControlPlane,
+		"namespace": cfg.Namespace,
+		"host":      cfg.Host,
+		"token":     cfg.Token,
+	}
+	if cfg.Secret != "" {
+		if err := c.wireSecret(ctx, cfg); err != nil {
+			return err
+		}
+		mcp["secret"] = map[string]any{
+			"name":      cfg.Secret,
+			"provision": false,
+		}
+	}
+	values["mcp"] = mcp
+
+	if err := c.mgr.Install(ctx, "", values); err != nil {
+		return err
+	}
+	var unhealthy error
+	err := wait.PollUntilContextTimeout(ctx, c.pollInterval, c.timeout, true, func(ctx context.Context) (bool, error) {
+		unhealthy = c.Verify(ctx)
+		return unhealthy == nil, nil
+	})
+	if err != nil && unhealthy != nil {
+		return unhealthy
+	}
+	return err
+}
+
+// wireSecret writes the kubeconfig of the control plane to the configured
+// secret, or checks that the secret exists if no kubeconfig is supplied.
+func (c *Connector) wireSecret(ctx context.Context, cfg Config) error {
+	if len(cfg.Kubeconfig) == 0 {
+		_, err := c.kube.CoreV1().Secrets(c.namespace).Get(ctx, cfg.Secret, metav1.GetOptions{})
+		return errors.Wrapf(err, errFmtGetSecret, cfg.Secret)
+	}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.Secret,
+			Namespace: c.namespace,
+			Labels:    map[string]string{managedByLabel: managedBy},
+		},
+		Data: map[string][]byte{kubeconfigKey: cfg.Kubeconfig},
+	}
+	_, err := c.kube.CoreV1().Secrets(c.namespace).Create(ctx, s, metav1.CreateOptions{})
+	if kerrors.IsAlreadyExists(err) {
+		_, err = c.kube.CoreV1().Secrets(c.namespace).Update(ctx, s, metav1.UpdateOptions{})
+	}
+	return errors.Wrap(err, errWriteSecret)
+}
+
+// Verify returns an error if the connector deployment is not available or
+// the API services it serves are not available.
+func (c *Connector) Verify(ctx context.Context) error {
+	d, err := c.kube.AppsV1().Deployments(c.namespace).Get(ctx, Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, errGetDeployment)
+	}
+	if d.Status.AvailableReplicas == 0 {
+		return errors.New(errDeploymentPending)
+	}
+	svcs, err := c.apiServices(ctx)
+	if err != nil {
+		return err
+	}
+	if len(svcs) == 0 {
+		return errors.New(errNoAPIServices)
+	}
+	pending := []string{}
+	for _, s := range svcs {
+		if !available(s) {
+			pending = append(pending, s.GetName())
+		}
+	}
+	if len(pending) > 0 {
+		return errors.Errorf(errFmtAPIsPending, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Disconnect uninstalls the connector, removes any API services it served
+// that were left behind, which would otherwise break discovery in the app
+// cluster, and deletes the control plane secret if it was written by
+// Connect.
+func (c *Connector) Disconnect(ctx context.Context, secret string) error {
+	if err := c.mgr.Uninstall(); err != nil {
+		return err
+	}
+	svcs, err := c.apiServices(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range svcs {
+		err := c.dyn.Resource(apiServiceGVR).Delete(ctx, s.GetName(), metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, errDeleteAPIService)
+		}
+	}
+	if secret == "" {
+		return nil
+	}
+	s, err := c.kube.CoreV1().Secrets(c.namespace).Get(ctx, secret, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errDeleteSecret)
+	}
+	if s.GetLabels()[managedByLabel] != managedBy {
+		return nil
+	}
+	err = c.kube.CoreV1().Secrets(c.namespace).Delete(ctx, secret, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, errDeleteSecret)
+	}
+	return nil
+}
+
+// apiServices returns the API services served by the connector.
+func (c *Connector) apiServices(ctx context.Context) ([]unstructured.Unstructured, error) {
+	l, err := c.dyn.Resource(apiServiceGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, errListAPIServices)
+	}
+	svcs := []unstructured.Unstructured{}
+	for _, s := range l.Items {
+		name, _, _ := unstructured.NestedString(s.Object, "spec", "service", "name")
+		ns, _, _ := unstructured.NestedString(s.Object, "spec", "service", "namespace")
+		if name == Name && ns == c.namespace {
+			svcs = append(svcs, s)
+		}
+	}
+	return svcs, nil
+}
+
+func available(s unstructured.Unstructured) bool {
+	conds, _, _ := unstructured.NestedSlice(s.Object, "status", "conditions")
+	for _, c := range conds {
+		m, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if m["type"] == "Available" {
+			return m["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/upbound/up/internal/install"
+)
+
+type mockManager struct {
+	values      map[string]any
+	uninstalled bool
+}
+
+func (m *mockManager) GetCurrentVersion() (string, error) { return "", nil }
+
+func (m *mockManager) Install(_ context.Context, _ string, values map[string]any, _ ...install.InstallOption) error {
+	m.values = values
+	return nil
+}
+
+func (m *mockManager) Upgrade(context.Context, string, map[string]any, ...install.UpgradeOption) error {
+	return nil
+}
+
+func (m *mockManager) Uninstall() error {
+	m.uninstalled = true
+	return nil
+}
+
+func deployment(available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: Name, Namespace: DefaultNamespace},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+	}
+}
+
+func apiService(name, status string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apiregistration.k8s.io/v1")
+	u.SetKind("APIService")
+	u.SetName(name)
+	_ = unstructured.SetNestedMap(u.Object, map[string]any{"name": Name, "namespace": DefaultNamespace}, "spec", "service")
+	_ = unstructured.SetNestedSlice(u.Object, []any{map[string]any{"type": "Available", "status": status}}, "status", "conditions")
+	return u
+}
+
+func newDynamic(objs ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		apiServiceGVR: "APIServiceList",
+	}, objs...)
+}
+
+func TestConnect(t *testing.T) {
+	type want struct {
+		mcp    map[string]any
+		secret []byte
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		kube   []runtime.Object
+		dyn    []runtime.Object
+		cfg    Config
+		want   want
+	}{
+		"Token": {
+			reason: "Without a secret the chart should provision one from the token.",
+			kube:   []runtime.Object{deployment(1)},
+			dyn:    []runtime.Object{apiService("v1alpha1.acme.io", "True")},
+			cfg:    Config{Account: "acme", ControlPlane: "ctp", Namespace: "app", Host: "https://proxy.upbound.io", Token: "t"},
+			want: want{
+				mcp: map[string]any{"account": "acme", "name": "ctp", "namespace": "app", "host": "https://proxy.upbound.io", "token": "t"},
+			},
+		},
+		"WireKubeconfig": {
+			reason: "A supplied kubeconfig should be written to the secret the chart uses.",
+			kube:   []runtime.Object{deployment(1)},
+			dyn:    []runtime.Object{apiService("v1alpha1.acme.io", "True")},
+			cfg:    Config{ControlPlane: "ctp", Namespace: "app", Secret: "ctp-kubeconfig", Kubeconfig: []byte("kubeconfig")},
+			want: want{
+				mcp: map[string]any{
+					"account": "", "name": "ctp", "namespace": "app", "host": "", "token": "",
+					"secret": map[string]any{"name": "ctp-kubeconfig", "provision": false},
+				},
+				secret: []byte("kubeconfig"),
+			},
+		},
+		"MissingSecret": {
+			reason: "A secret that doesn't exist and isn't supplied should fail before installing.",
+			cfg:    Config{Secret: "missing"},
+			want: want{
+				err: errors.Wrapf(kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "missing"), errFmtGetSecret, "missing"),
+			},
+		},
+		"Unhealthy": {
+			reason: "An API service that doesn't become available should fail the connect.",
+			kube:   []runtime.Object{deployment(1)},
+			dyn:    []runtime.Object{apiService("v1alpha1.acme.io", "False")},
+			cfg:    Config{ControlPlane: "ctp", Namespace: "app"},
+			want: want{
+				mcp: map[string]any{"account": "", "name": "ctp", "namespace": "app", "host": "", "token": ""},
+				err: errors.Errorf(errFmtAPIsPending, "v1alpha1.acme.io"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewSimpleClientset(tc.kube...)
+			mgr := &mockManager{}
+			c := New(mgr, kube, newDynamic(tc.dyn...), WithTimeout(50*time.Millisecond), WithPollInterval(10*time.Millisecond))
+
+			err := c.Connect(context.Background(), tc.cfg, map[string]any{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nConnect(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var mcp map[string]any
+			if mgr.values != nil {
+				mcp, _ = mgr.values["mcp"].(map[string]any)
+			}
+			if diff := cmp.Diff(tc.want.mcp, mcp); diff != "" {
+				t.Errorf("\n%s\nConnect(...): -want mcp values, +got mcp values:\n%s", tc.reason, diff)
+			}
+			if tc.want.secret == nil {
+				return
+			}
+			s, err := kube.CoreV1().Secrets(DefaultNamespace).Get(context.Background(), tc.cfg.Secret, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("\n%s\nConnect(...): secret not written: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.secret, s.Data[kubeconfigKey]); diff != "" {
+				t.Errorf("\n%s\nConnect(...): -want kubeconfig, +got kubeconfig:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	managed := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name: "managed", Namespace: DefaultNamespace, Labels: map[string]string{managedByLabel: managedBy},
+	}}
+	user := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: DefaultNamespace}}
+	other := apiService("v1.other.io", "True")
+	_ = unstructured.SetNestedField(other.Object, "other", "spec", "service", "name")
+
+	cases := map[string]struct {
+		reason     string
+		secret     string
+		wantSecret bool
+	}{
+		"ManagedSecret": {
+			reason: "A secret written by Connect should be deleted.",
+			secret: "managed",
+		},
+		"UserSecret": {
+			reason:     "A secret supplied by the user should be kept.",
+			secret:     "user",
+			wantSecret: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewSimpleClientset(managed.DeepCopy(), user.DeepCopy())
+			dyn := newDynamic(apiService("v1alpha1.acme.io", "True"), other)
+			mgr := &mockManager{}
+
+			if err := New(mgr, kube, dyn).Disconnect(context.Background(), tc.secret); err != nil {
+				t.Fatalf("\n%s\nDisconnect(...): %v", tc.reason, err)
+			}
+			if !mgr.uninstalled {
+				t.Errorf("\n%s\nDisconnect(...): release not uninstalled", tc.reason)
+			}
+			l, err := dyn.Resource(apiServiceGVR).List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, s := range l.Items {
+				names = append(names, s.GetName())
+			}
+			if diff := cmp.Diff([]string{"v1.other.io"}, names); diff != "" {
+				t.Errorf("\n%s\nDisconnect(...): -want API services, +got API services:\n%s", tc.reason, diff)
+			}
+			_, err = kube.CoreV1().Secrets(DefaultNamespace).Get(context.Background(), tc.secret, metav1.GetOptions{})
+			if diff := cmp.Diff(tc.wantSecret, err == nil); diff != "" {
+				t.Errorf("\n%s\nDisconnect(...): -want secret, +got secret:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
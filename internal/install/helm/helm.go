@@ -15,6 +15,7 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -86,11 +87,11 @@ type helmGetter interface {
 }
 
 type helmInstaller interface {
-	Run(*chart.Chart, map[string]any) (*release.Release, error)
+	RunWithContext(context.Context, *chart.Chart, map[string]any) (*release.Release, error)
 }
 
 type helmUpgrader interface {
-	Run(string, *chart.Chart, map[string]any) (*release.Release, error)
+	RunWithContext(context.Context, string, *chart.Chart, map[string]any) (*release.Release, error)
 }
 
 type helmRollbacker interface {
@@ -119,6 +120,7 @@ type Installer struct {
 	namespace       string
 	cacheDir        string
 	rollbackOnError bool
+	atomic          bool
 	force           bool
 	wait            bool
 	noHooks         bool
@@ -204,6 +206,14 @@ func RollbackOnError(r bool) InstallerModifierFn {
 	}
 }
 
+// Atomic sets whether a failed install is uninstalled again, leaving the
+// cluster as it was before the install.
+func Atomic(a bool) InstallerModifierFn {
+	return func(h *Installer) {
+		h.atomic = a
+	}
+}
+
 // Force will force operations when possible.
 func Force(f bool) InstallerModifierFn {
 	return func(h *Installer) {
@@ -295,6 +305,7 @@ func NewManager(config *rest.Config, chartName string, repoURL *url.URL, modifie
 	ic.Namespace = h.namespace
 	ic.ReleaseName = h.chartName
 	ic.Wait = h.wait
+	ic.Atomic = h.atomic
 	ic.Timeout = waitTimeout
 	ic.DisableHooks = h.noHooks
 	h.installClient = ic
@@ -349,7 +360,7 @@ func (h *Installer) GetCurrentVersion() (string, error) {
 }
 
 // Install installs in the cluster.
-func (h *Installer) Install(version string, parameters map[string]any, opts ...install.InstallOption) error {
+func (h *Installer) Install(ctx context.Context, version string, parameters map[string]any, opts ...install.InstallOption) error {
 	// make sure no version is already installed
 	current, err := h.GetCurrentVersion()
 	if err == nil {
@@ -380,12 +391,12 @@ func (h *Installer) Install(version string, parameters map[string]any, opts ...i
 		}
 	}
 
-	_, err = h.installClient.Run(helmChart, parameters)
+	_, err = h.installClient.RunWithContext(ctx, helmChart, parameters)
 	return err
 }
 
 // Upgrade upgrades an existing installation to a new version.
-func (h *Installer) Upgrade(version string, parameters map[string]any, opts ...install.UpgradeOption) error { //nolint:gocyclo // looks still sane
+func (h *Installer) Upgrade(ctx context.Context, version string, parameters map[string]any, opts ...install.UpgradeOption) error { //nolint:gocyclo // looks still sane
 	// check if version exists
 	current, err := h.GetCurrentVersion()
 	if err != nil {
@@ -415,7 +426,7 @@ func (h *Installer) Upgrade(version string, parameters map[string]any, opts ...i
 		}
 	}
 
-	_, upErr := h.upgradeClient.Run(h.releaseName, helmChart, parameters)
+	_, upErr := h.upgradeClient.RunWithContext(ctx, h.releaseName, helmChart, parameters)
 	if upErr != nil && h.rollbackOnError {
 		if rErr := h.rollbackClient.Run(h.releaseName); rErr != nil {
 			return errors.Wrap(rErr, errFailedUpgradeFailedRollback)
@@ -15,6 +15,7 @@
 package helm
 
 import (
+	"context"
 	"testing"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -55,8 +56,8 @@ type mockInstallClient struct {
 	runFn func(*chart.Chart, map[string]any) (*release.Release, error)
 }
 
-// Run calls the underlying run function.
-func (m *mockInstallClient) Run(c *chart.Chart, v map[string]any) (*release.Release, error) {
+// RunWithContext calls the underlying run function.
+func (m *mockInstallClient) RunWithContext(_ context.Context, c *chart.Chart, v map[string]any) (*release.Release, error) {
 	return m.runFn(c, v)
 }
 
@@ -64,8 +65,8 @@ type mockUpgradeClient struct {
 	runFn func(string, *chart.Chart, map[string]any) (*release.Release, error)
 }
 
-// Run calls the underlying run function.
-func (m *mockUpgradeClient) Run(r string, c *chart.Chart, v map[string]any) (*release.Release, error) {
+// RunWithContext calls the underlying run function.
+func (m *mockUpgradeClient) RunWithContext(_ context.Context, r string, c *chart.Chart, v map[string]any) (*release.Release, error) {
 	return m.runFn(r, c, v)
 }
 
@@ -306,7 +307,7 @@ func TestInstall(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			tc.installer.fs = tc.fsSetup()
-			err := tc.installer.Install(tc.version, nil)
+			err := tc.installer.Install(context.Background(), tc.version, nil)
 			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nInstall(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
@@ -667,7 +668,7 @@ func TestUpgrade(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			tc.installer.fs = tc.fsSetup()
-			err := tc.installer.Upgrade(tc.version, nil)
+			err := tc.installer.Upgrade(context.Background(), tc.version, nil)
 			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nUpgrade(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
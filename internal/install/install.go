@@ -14,7 +14,11 @@
 
 package install
 
-import "helm.sh/helm/v3/pkg/chart"
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
 
 // InstallOption customizes the behavior of an install.
 type InstallOption func(*chart.Chart) error
@@ -23,11 +27,13 @@ type InstallOption func(*chart.Chart) error
 type UpgradeOption func(oldVersion string, ch *chart.Chart) error
 
 // Manager can install and manage Upbound software in a Kubernetes cluster.
+// Install and Upgrade stop waiting for the release when the supplied context
+// is done.
 // TODO(hasheddan): support custom error types, such as AlreadyExists.
 type Manager interface {
 	GetCurrentVersion() (string, error)
-	Install(version string, parameters map[string]any, opts ...InstallOption) error
-	Upgrade(version string, parameters map[string]any, opts ...UpgradeOption) error
+	Install(ctx context.Context, version string, parameters map[string]any, opts ...InstallOption) error
+	Upgrade(ctx context.Context, version string, parameters map[string]any, opts ...UpgradeOption) error
 	Uninstall() error
 }
 
@@ -0,0 +1,179 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	corev1 "k8s.io/api/core/v1"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultStorageClassAnnotation marks the default storage class of a
+// cluster.
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// KubernetesVersion checks that the version of the cluster satisfies the
+// supplied semver constraint.
+func KubernetesVersion(d discovery.ServerVersionInterface, constraint string) Check {
+	return NewCheck("kubernetes version", func(_ context.Context) Result {
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return Fail("invalid constraint %q: %s", constraint, err)
+		}
+		info, err := d.ServerVersion()
+		if err != nil {
+			return Fail("failed to get server version: %s", err)
+		}
+		v, err := semver.NewVersion(info.GitVersion)
+		if err != nil {
+			return Fail("failed to parse server version %q: %s", info.GitVersion, err)
+		}
+		// Pre-release suffixes of managed offerings, such as -eks-1234,
+		// would otherwise never satisfy the constraint.
+		if cv, err := v.SetPrerelease(""); err == nil {
+			v = &cv
+		}
+		if !c.Check(v) {
+			return Fail("version %s does not satisfy %s", info.GitVersion, constraint)
+		}
+		return Pass("version %s", info.GitVersion)
+	})
+}
+
+// NodeResources checks that the nodes of the cluster together have at least
+// the supplied allocatable CPU and memory. Insufficient resources produce a
+// warning, since the cluster may scale.
+func NodeResources(kube kubernetes.Interface, cpu, memory resource.Quantity) Check {
+	return NewCheck("node resources", func(ctx context.Context) Result {
+		l, err := kube.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return Warn("failed to list nodes: %s", err)
+		}
+		gotCPU, gotMem := resource.Quantity{}, resource.Quantity{}
+		for _, n := range l.Items {
+			gotCPU.Add(n.Status.Allocatable[corev1.ResourceCPU])
+			gotMem.Add(n.Status.Allocatable[corev1.ResourceMemory])
+		}
+		if gotCPU.Cmp(cpu) < 0 || gotMem.Cmp(memory) < 0 {
+			return Warn("%s CPU and %s memory allocatable, %s CPU and %s memory recommended", gotCPU.String(), gotMem.String(), cpu.String(), memory.String())
+		}
+		return Pass("%s CPU and %s memory allocatable", gotCPU.String(), gotMem.String())
+	})
+}
+
+// CRDs checks that the supplied CustomResourceDefinitions are installed. The
+// name describes what the CRDs belong to, for example cert-manager.
+func CRDs(crds apixv1client.CustomResourceDefinitionsGetter, name string, names ...string) Check {
+	return NewCheck(name, func(ctx context.Context) Result {
+		missing := []string{}
+		for _, n := range names {
+			_, err := crds.CustomResourceDefinitions().Get(ctx, n, metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				missing = append(missing, n)
+				continue
+			}
+			if err != nil {
+				return Fail("failed to get CRD %s: %s", n, err)
+			}
+		}
+		if len(missing) > 0 {
+			return Fail("missing CRDs: %s", strings.Join(missing, ", "))
+		}
+		return Pass("installed")
+	})
+}
+
+// IngressClass checks that at least one ingress class is available.
+func IngressClass(kube kubernetes.Interface) Check {
+	return NewCheck("ingress", func(ctx context.Context) Result {
+		l, err := kube.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return Fail("failed to list ingress classes: %s", err)
+		}
+		if len(l.Items) == 0 {
+			return Fail("no ingress class is available")
+		}
+		return Pass("ingress class %s", l.Items[0].GetName())
+	})
+}
+
+// DefaultStorageClass checks that the cluster has a default storage class.
+func DefaultStorageClass(kube kubernetes.Interface) Check {
+	return NewCheck("storage class", func(ctx context.Context) Result {
+		l, err := kube.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return Fail("failed to list storage classes: %s", err)
+		}
+		for _, sc := range l.Items {
+			if sc.GetAnnotations()[defaultStorageClassAnnotation] == "true" {
+				return Pass("default storage class %s", sc.GetName())
+			}
+		}
+		return Fail("no default storage class is set")
+	})
+}
+
+// Connectivity checks that the supplied URL can be reached. Any HTTP
+// response passes, since the endpoint may require authentication.
+func Connectivity(client *http.Client, name, url string) Check {
+	return NewCheck(name, func(ctx context.Context) Result {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return Fail("invalid URL %q: %s", url, err)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return Fail("cannot reach %s: %s", url, err)
+		}
+		defer res.Body.Close() //nolint:errcheck
+		return Pass("reached %s", url)
+	})
+}
+
+// ConflictingDeployments checks that no deployment with one of the supplied
+// names exists outside of the supplied namespace, which indicates an
+// existing installation that conflicts with the new one.
+func ConflictingDeployments(kube kubernetes.Interface, namespace string, names ...string) Check {
+	return NewCheck("conflicting installs", func(ctx context.Context) Result {
+		l, err := kube.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return Warn("failed to list deployments: %s", err)
+		}
+		found := []string{}
+		for _, d := range l.Items {
+			if d.GetNamespace() == namespace {
+				continue
+			}
+			for _, n := range names {
+				if d.GetName() == n {
+					found = append(found, d.GetNamespace()+"/"+d.GetName())
+				}
+			}
+		}
+		if len(found) > 0 {
+			return Fail("found existing installs: %s", strings.Join(found, ", "))
+		}
+		return Pass("none found")
+	})
+}
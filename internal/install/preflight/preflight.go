@@ -0,0 +1,137 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package preflight checks that a cluster meets the prerequisites of an
+// installation.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errFmtFailed = "pre-flight checks failed: %s"
+)
+
+// A Status is the outcome of a pre-flight check.
+type Status string
+
+// Check statuses.
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// A Result is the outcome of running a check.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Pass returns a passing result with the supplied message.
+func Pass(format string, args ...any) Result {
+	return Result{Status: StatusPass, Message: fmt.Sprintf(format, args...)}
+}
+
+// Warn returns a warning result with the supplied message. Warnings do not
+// fail a report.
+func Warn(format string, args ...any) Result {
+	return Result{Status: StatusWarn, Message: fmt.Sprintf(format, args...)}
+}
+
+// Fail returns a failing result with the supplied message.
+func Fail(format string, args ...any) Result {
+	return Result{Status: StatusFail, Message: fmt.Sprintf(format, args...)}
+}
+
+// A Check verifies a prerequisite of an installation.
+type Check interface {
+	// Name of the check.
+	Name() string
+
+	// Run the check. The name of the returned result is ignored.
+	Run(ctx context.Context) Result
+}
+
+// A CheckFn is a function that satisfies the Run method of a Check.
+type CheckFn func(ctx context.Context) Result
+
+type check struct {
+	name string
+	fn   CheckFn
+}
+
+func (c check) Name() string                   { return c.name }
+func (c check) Run(ctx context.Context) Result { return c.fn(ctx) }
+
+// NewCheck returns a Check with the supplied name that runs the supplied
+// function.
+func NewCheck(name string, fn CheckFn) Check {
+	return check{name: name, fn: fn}
+}
+
+// A Report is the outcome of running a set of checks.
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// A ReportFn is called with the report of a pre-flight run.
+type ReportFn func(r *Report)
+
+// Run runs the supplied checks in order and returns their report.
+func Run(ctx context.Context, checks ...Check) *Report {
+	r := &Report{Results: make([]Result, 0, len(checks))}
+	for _, c := range checks {
+		res := c.Run(ctx)
+		res.Name = c.Name()
+		r.Results = append(r.Results, res)
+	}
+	return r
+}
+
+// Filter returns the results with the supplied status.
+func (r *Report) Filter(s Status) []Result {
+	out := []Result{}
+	for _, res := range r.Results {
+		if res.Status == s {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Passed returns true if no check failed.
+func (r *Report) Passed() bool {
+	return len(r.Filter(StatusFail)) == 0
+}
+
+// Err returns an error describing the failed checks, or nil if no check
+// failed.
+func (r *Report) Err() error {
+	failed := r.Filter(StatusFail)
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(failed))
+	for i, res := range failed {
+		msgs[i] = fmt.Sprintf("%s: %s", res.Name, res.Message)
+	}
+	return errors.Errorf(errFmtFailed, strings.Join(msgs, "; "))
+}
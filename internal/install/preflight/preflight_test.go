@@ -0,0 +1,211 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRun(t *testing.T) {
+	ok := NewCheck("ok", func(context.Context) Result { return Pass("fine") })
+	warn := NewCheck("warn", func(context.Context) Result { return Warn("hmm") })
+	fail := NewCheck("fail", func(context.Context) Result { return Fail("broken") })
+
+	type want struct {
+		results []Result
+		passed  bool
+		err     string
+	}
+	cases := map[string]struct {
+		reason string
+		checks []Check
+		want   want
+	}{
+		"Passed": {
+			reason: "Warnings should not fail a report.",
+			checks: []Check{ok, warn},
+			want: want{
+				results: []Result{
+					{Name: "ok", Status: StatusPass, Message: "fine"},
+					{Name: "warn", Status: StatusWarn, Message: "hmm"},
+				},
+				passed: true,
+			},
+		},
+		"Failed": {
+			reason: "A failed check should fail the report.",
+			checks: []Check{ok, fail},
+			want: want{
+				results: []Result{
+					{Name: "ok", Status: StatusPass, Message: "fine"},
+					{Name: "fail", Status: StatusFail, Message: "broken"},
+				},
+				err: "pre-flight checks failed: fail: broken",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := Run(context.Background(), tc.checks...)
+			if diff := cmp.Diff(tc.want.results, r.Results); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.passed, r.Passed()); diff != "" {
+				t.Errorf("\n%s\nPassed(): -want, +got:\n%s", tc.reason, diff)
+			}
+			got := ""
+			if err := r.Err(); err != nil {
+				got = err.Error()
+			}
+			if diff := cmp.Diff(tc.want.err, got); diff != "" {
+				t.Errorf("\n%s\nErr(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestChecks(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+		}},
+	}
+	ingress := &networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}}
+	sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+		Name:        "standard",
+		Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+	}}
+	crossplane := func(ns string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "crossplane", Namespace: ns}}
+	}
+	crd := &apixv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "certificates.cert-manager.io"}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	disc := func(v string) *fakediscovery.FakeDiscovery {
+		d := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+		d.FakedServerVersion = &version.Info{GitVersion: v}
+		return d
+	}
+	kube := func(objs ...runtime.Object) *fake.Clientset { return fake.NewSimpleClientset(objs...) }
+	crds := apixfake.NewSimpleClientset(crd).ApiextensionsV1()
+
+	cases := map[string]struct {
+		reason string
+		check  Check
+		want   Status
+	}{
+		"KubernetesVersionManaged": {
+			reason: "Pre-release suffixes of managed offerings should be ignored.",
+			check:  KubernetesVersion(disc("v1.27.3-eks-1234"), ">= 1.25"),
+			want:   StatusPass,
+		},
+		"KubernetesVersionTooOld": {
+			reason: "Versions below the constraint should fail.",
+			check:  KubernetesVersion(disc("v1.24.0"), ">= 1.25"),
+			want:   StatusFail,
+		},
+		"NodeResourcesSufficient": {
+			reason: "Enough allocatable resources should pass.",
+			check:  NodeResources(kube(node), resource.MustParse("2"), resource.MustParse("4Gi")),
+			want:   StatusPass,
+		},
+		"NodeResourcesInsufficient": {
+			reason: "Too few allocatable resources should warn.",
+			check:  NodeResources(kube(node), resource.MustParse("4"), resource.MustParse("4Gi")),
+			want:   StatusWarn,
+		},
+		"CRDsInstalled": {
+			reason: "Installed CRDs should pass.",
+			check:  CRDs(crds, "cert-manager", "certificates.cert-manager.io"),
+			want:   StatusPass,
+		},
+		"CRDsMissing": {
+			reason: "Missing CRDs should fail.",
+			check:  CRDs(crds, "cert-manager", "certificates.cert-manager.io", "issuers.cert-manager.io"),
+			want:   StatusFail,
+		},
+		"IngressClass": {
+			reason: "An ingress class should pass.",
+			check:  IngressClass(kube(ingress)),
+			want:   StatusPass,
+		},
+		"NoIngressClass": {
+			reason: "No ingress class should fail.",
+			check:  IngressClass(kube()),
+			want:   StatusFail,
+		},
+		"DefaultStorageClass": {
+			reason: "A default storage class should pass.",
+			check:  DefaultStorageClass(kube(sc)),
+			want:   StatusPass,
+		},
+		"NoDefaultStorageClass": {
+			reason: "No default storage class should fail.",
+			check:  DefaultStorageClass(kube()),
+			want:   StatusFail,
+		},
+		"Reachable": {
+			reason: "Any HTTP response, even unauthorized, should pass.",
+			check:  Connectivity(srv.Client(), "registry", srv.URL),
+			want:   StatusPass,
+		},
+		"Unreachable": {
+			reason: "A connection error should fail.",
+			check:  Connectivity(srv.Client(), "registry", "http://127.0.0.1:0"),
+			want:   StatusFail,
+		},
+		"OwnInstall": {
+			reason: "A deployment in the target namespace is not a conflict.",
+			check:  ConflictingDeployments(kube(crossplane("upbound-system")), "upbound-system", "crossplane"),
+			want:   StatusPass,
+		},
+		"Conflict": {
+			reason: "A deployment in another namespace is a conflict.",
+			check:  ConflictingDeployments(kube(crossplane("crossplane-system")), "upbound-system", "crossplane"),
+			want:   StatusFail,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.check.Run(context.Background())
+			if diff := cmp.Diff(tc.want, got.Status); diff != "" {
+				t.Errorf("\n%s\n%s.Run(...): -want, +got:\n%s\n%s", tc.reason, tc.check.Name(), diff, got.Message)
+			}
+		})
+	}
+}
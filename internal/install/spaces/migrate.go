@@ -0,0 +1,163 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spaces
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errFmtParseVersion = "failed to parse version %q"
+	errFmtMigration    = "failed to migrate values for %s"
+	errFmtNotAMap      = "value %q is not a map"
+)
+
+// A MigrateFn rewrites chart values in place.
+type MigrateFn func(values map[string]any) error
+
+// A Migration rewrites the values of charts older than Version so they
+// remain valid for Version and later.
+type Migration struct {
+	// Version is the chart version that introduced the change.
+	Version string
+
+	// Description explains the change.
+	Description string
+
+	Migrate MigrateFn
+}
+
+// DefaultMigrations are the values migrations applied when upgrading
+// Spaces. Add a Migration here whenever a chart release renames or moves a
+// value.
+var DefaultMigrations = []Migration{}
+
+// Migrate applies each migration whose version is newer than from and no
+// newer than to, in order of their versions. Migrations are skipped when
+// downgrading.
+func Migrate(migrations []Migration, from, to string, values map[string]any) ([]Migration, error) {
+	f, err := semver.NewVersion(from)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtParseVersion, from)
+	}
+	t, err := semver.NewVersion(to)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtParseVersion, to)
+	}
+	type pending struct {
+		m Migration
+		v *semver.Version
+	}
+	todo := []pending{}
+	for _, m := range migrations {
+		v, err := semver.NewVersion(m.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtParseVersion, m.Version)
+		}
+		if v.GreaterThan(f) && !v.GreaterThan(t) {
+			todo = append(todo, pending{m: m, v: v})
+		}
+	}
+	// Migrations are few, so a simple insertion sort keeps registration
+	// order for equal versions.
+	for i := 1; i < len(todo); i++ {
+		for j := i; j > 0 && todo[j].v.LessThan(todo[j-1].v); j-- {
+			todo[j], todo[j-1] = todo[j-1], todo[j]
+		}
+	}
+	applied := make([]Migration, 0, len(todo))
+	for _, p := range todo {
+		if err := p.m.Migrate(values); err != nil {
+			return applied, errors.Wrapf(err, errFmtMigration, p.m.Version)
+		}
+		applied = append(applied, p.m)
+	}
+	return applied, nil
+}
+
+// MoveValue returns a MigrateFn that moves the value at the dot separated
+// path from to the path to. It does nothing if from is not set, and does
+// not overwrite a value already set at to.
+func MoveValue(from, to string) MigrateFn {
+	return func(values map[string]any) error {
+		v, ok, err := lookup(values, from)
+		if err != nil || !ok {
+			return err
+		}
+		if _, ok, err := lookup(values, to); err != nil || ok {
+			return err
+		}
+		if err := set(values, to, v); err != nil {
+			return err
+		}
+		return remove(values, from)
+	}
+}
+
+func lookup(values map[string]any, path string) (any, bool, error) {
+	parts := strings.Split(path, ".")
+	cur := values
+	for i, p := range parts {
+		v, ok := cur[p]
+		if !ok {
+			return nil, false, nil
+		}
+		if i == len(parts)-1 {
+			return v, true, nil
+		}
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false, errors.Errorf(errFmtNotAMap, strings.Join(parts[:i+1], "."))
+		}
+		cur = m
+	}
+	return nil, false, nil
+}
+
+func set(values map[string]any, path string, v any) error {
+	parts := strings.Split(path, ".")
+	cur := values
+	for i, p := range parts[:len(parts)-1] {
+		next, ok := cur[p]
+		if !ok {
+			next = map[string]any{}
+			cur[p] = next
+		}
+		m, ok := next.(map[string]any)
+		if !ok {
+			return errors.Errorf(errFmtNotAMap, strings.Join(parts[:i+1], "."))
+		}
+		cur = m
+	}
+	cur[parts[len(parts)-1]] = v
+	return nil
+}
+
+func remove(values map[string]any, path string) error {
+	parts := strings.Split(path, ".")
+	cur := values
+	for i, p := range parts[:len(parts)-1] {
+		m, ok := cur[p].(map[string]any)
+		if !ok {
+			return errors.Errorf(errFmtNotAMap, strings.Join(parts[:i+1], "."))
+		}
+		cur = m
+	}
+	delete(cur, parts[len(parts)-1])
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spaces
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMoveValue(t *testing.T) {
+	type want struct {
+		values map[string]any
+		err    error
+	}
+	cases := map[string]struct {
+		reason   string
+		from, to string
+		values   map[string]any
+		want     want
+	}{
+		"Nested": {
+			reason: "A nested value should be moved, creating parents as needed.",
+			from:   "features.alpha.gateway",
+			to:     "gateway.enabled",
+			values: map[string]any{"features": map[string]any{"alpha": map[string]any{"gateway": true}}},
+			want: want{
+				values: map[string]any{
+					"features": map[string]any{"alpha": map[string]any{}},
+					"gateway":  map[string]any{"enabled": true},
+				},
+			},
+		},
+		"Unset": {
+			reason: "Nothing should change if the source is not set.",
+			from:   "a",
+			to:     "b",
+			values: map[string]any{"c": 1},
+			want: want{
+				values: map[string]any{"c": 1},
+			},
+		},
+		"KeepExisting": {
+			reason: "A value already set at the destination should not be overwritten.",
+			from:   "a",
+			to:     "b",
+			values: map[string]any{"a": 1, "b": 2},
+			want: want{
+				values: map[string]any{"a": 1, "b": 2},
+			},
+		},
+		"NotAMap": {
+			reason: "Traversing a scalar should return an error.",
+			from:   "a.b",
+			to:     "c",
+			values: map[string]any{"a": 1},
+			want: want{
+				values: map[string]any{"a": 1},
+				err:    errors.Errorf(errFmtNotAMap, "a"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := MoveValue(tc.from, tc.to)(tc.values)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nMoveValue(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.values, tc.values); diff != "" {
+				t.Errorf("\n%s\nMoveValue(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
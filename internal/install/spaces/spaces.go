@@ -0,0 +1,262 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spaces installs and upgrades Upbound Spaces in a cluster.
+package spaces
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/preflight"
+	"github.com/upbound/up/internal/profile"
+)
+
+const (
+	// DefaultNamespace is the namespace Spaces is installed in.
+	DefaultNamespace = "upbound-system"
+
+	// accountValue is the chart value holding the Upbound account of the
+	// Space.
+	accountValue = "account"
+
+	// kubernetesVersion is the constraint on the Kubernetes version Spaces
+	// supports.
+	kubernetesVersion = ">= 1.25"
+
+	defaultReadyTimeout = 10 * time.Minute
+	defaultPollInterval = 5 * time.Second
+
+	errCreateNamespace       = "failed to create namespace"
+	errGetCurrentVersion     = "failed to get the installed version"
+	errListDeployments       = "failed to list deployments"
+	errFmtComponentsNotReady = "components not ready: %s"
+)
+
+// An Installer installs and upgrades Spaces.
+type Installer struct {
+	mgr        install.Manager
+	kube       kubernetes.Interface
+	namespace  string
+	checks     []preflight.Check
+	report     preflight.ReportFn
+	migrations []Migration
+
+	installOpts []install.InstallOption
+	upgradeOpts []install.UpgradeOption
+
+	readyTimeout time.Duration
+	pollInterval time.Duration
+}
+
+// An Option modifies an Installer.
+type Option func(*Installer)
+
+// WithNamespace sets the namespace Spaces is installed in.
+func WithNamespace(ns string) Option {
+	return func(i *Installer) {
+		i.namespace = ns
+	}
+}
+
+// WithChecks sets the pre-flight checks run before installing.
+func WithChecks(c ...preflight.Check) Option {
+	return func(i *Installer) {
+		i.checks = c
+	}
+}
+
+// WithReportFn sets a function that is called with the pre-flight report
+// before installing.
+func WithReportFn(fn preflight.ReportFn) Option {
+	return func(i *Installer) {
+		i.report = fn
+	}
+}
+
+// WithMigrations sets the values migrations applied when upgrading.
+func WithMigrations(m ...Migration) Option {
+	return func(i *Installer) {
+		i.migrations = m
+	}
+}
+
+// WithInstallOptions sets the options passed to the Manager on install.
+func WithInstallOptions(o ...install.InstallOption) Option {
+	return func(i *Installer) {
+		i.installOpts = o
+	}
+}
+
+// WithUpgradeOptions sets the options passed to the Manager on upgrade.
+func WithUpgradeOptions(o ...install.UpgradeOption) Option {
+	return func(i *Installer) {
+		i.upgradeOpts = o
+	}
+}
+
+// WithReadyTimeout sets how long to wait for the Spaces components to
+// become ready. A zero timeout skips waiting.
+func WithReadyTimeout(d time.Duration) Option {
+	return func(i *Installer) {
+		i.readyTimeout = d
+	}
+}
+
+// WithPollInterval sets how often component readiness is checked.
+func WithPollInterval(d time.Duration) Option {
+	return func(i *Installer) {
+		i.pollInterval = d
+	}
+}
+
+// New returns an Installer that installs Spaces with the supplied Manager.
+func New(mgr install.Manager, kube kubernetes.Interface, opts ...Option) *Installer {
+	i := &Installer{
+		mgr:          mgr,
+		kube:         kube,
+		namespace:    DefaultNamespace,
+		migrations:   DefaultMigrations,
+		readyTimeout: defaultReadyTimeout,
+		pollInterval: defaultPollInterval,
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	return i
+}
+
+// DefaultChecks returns the pre-flight checks run before Spaces is
+// installed in the supplied namespace. The supplied HTTP client is used to
+// check that the registry and the license endpoint (DMV) can be reached.
+func DefaultChecks(kube kubernetes.Interface, crds apixv1client.CustomResourceDefinitionsGetter, client *http.Client, namespace string, registry, dmv *url.URL) []preflight.Check {
+	return []preflight.Check{
+		preflight.KubernetesVersion(kube.Discovery(), kubernetesVersion),
+		preflight.NodeResources(kube, resource.MustParse("4"), resource.MustParse("8Gi")),
+		preflight.CRDs(crds, "cert-manager", "certificates.cert-manager.io"),
+		preflight.IngressClass(kube),
+		preflight.DefaultStorageClass(kube),
+		preflight.ConflictingDeployments(kube, namespace, "crossplane", "spaces-controller"),
+		preflight.Connectivity(client, "registry", registry.String()),
+		preflight.Connectivity(client, "license endpoint", dmv.String()),
+	}
+}
+
+// Preflight runs the pre-flight checks of the Installer.
+func (i *Installer) Preflight(ctx context.Context) *preflight.Report {
+	return preflight.Run(ctx, i.checks...)
+}
+
+// Install runs the pre-flight checks, installs the supplied version of
+// Spaces with the supplied values and waits for its components to become
+// ready.
+func (i *Installer) Install(ctx context.Context, version string, values map[string]any) error {
+	r := i.Preflight(ctx)
+	if i.report != nil {
+		i.report(r)
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	_, err := i.kube.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: i.namespace,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil && !kerrors.IsAlreadyExists(err) {
+		return errors.Wrap(err, errCreateNamespace)
+	}
+	if err := i.mgr.Install(ctx, strings.TrimPrefix(version, "v"), values, i.installOpts...); err != nil {
+		return err
+	}
+	return i.WaitReady(ctx)
+}
+
+// Upgrade migrates the supplied values from the installed version to the
+// supplied version, upgrades Spaces and waits for its components to become
+// ready. It returns the migrations that were applied.
+func (i *Installer) Upgrade(ctx context.Context, version string, values map[string]any) ([]Migration, error) {
+	version = strings.TrimPrefix(version, "v")
+	from, err := i.mgr.GetCurrentVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCurrentVersion)
+	}
+	applied, err := Migrate(i.migrations, from, version, values)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.mgr.Upgrade(ctx, version, values, i.upgradeOpts...); err != nil {
+		return applied, err
+	}
+	return applied, i.WaitReady(ctx)
+}
+
+// WaitReady waits until all deployments in the Spaces namespace are
+// available and up to date.
+func (i *Installer) WaitReady(ctx context.Context) error {
+	if i.readyTimeout == 0 {
+		return nil
+	}
+	var notReady []string
+	err := wait.PollUntilContextTimeout(ctx, i.pollInterval, i.readyTimeout, true, func(ctx context.Context) (bool, error) {
+		l, err := i.kube.AppsV1().Deployments(i.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, errListDeployments)
+		}
+		notReady = notReady[:0]
+		for _, d := range l.Items {
+			if !deploymentReady(d) {
+				notReady = append(notReady, d.GetName())
+			}
+		}
+		return len(notReady) == 0, nil
+	})
+	if err != nil && len(notReady) > 0 {
+		return errors.Wrapf(err, errFmtComponentsNotReady, strings.Join(notReady, ", "))
+	}
+	return err
+}
+
+func deploymentReady(d appsv1.Deployment) bool {
+	want := int32(1)
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.GetGeneration() &&
+		d.Status.UpdatedReplicas >= want &&
+		d.Status.AvailableReplicas >= want
+}
+
+// ProfileValues returns the chart values derived from the supplied profile.
+func ProfileValues(p profile.Profile) map[string]any {
+	values := map[string]any{}
+	if p.Account != "" {
+		values[accountValue] = p.Account
+	}
+	return values
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spaces
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	appsv1 "k8s.io/api/apps/v1"
+	apixfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+
+	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/preflight"
+)
+
+type mockManager struct {
+	current string
+	version string
+	values  map[string]any
+	err     error
+}
+
+func (m *mockManager) GetCurrentVersion() (string, error) { return m.current, nil }
+
+func (m *mockManager) Install(_ context.Context, version string, values map[string]any, _ ...install.InstallOption) error {
+	m.version, m.values = version, values
+	return m.err
+}
+
+func (m *mockManager) Upgrade(_ context.Context, version string, values map[string]any, _ ...install.UpgradeOption) error {
+	m.version, m.values = version, values
+	return m.err
+}
+
+func (m *mockManager) Uninstall() error { return nil }
+
+func deployment(name string, ready bool) *appsv1.Deployment {
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: DefaultNamespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(1)},
+	}
+	if ready {
+		d.Status = appsv1.DeploymentStatus{UpdatedReplicas: 1, AvailableReplicas: 1}
+	}
+	return d
+}
+
+func TestInstall(t *testing.T) {
+	failing := preflight.NewCheck("boom", func(context.Context) preflight.Result { return preflight.Fail("boom") })
+
+	type want struct {
+		version string
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		checks []preflight.Check
+		objs   []runtime.Object
+		want   want
+	}{
+		"PreflightFailed": {
+			reason: "A failed pre-flight check should abort the install.",
+			checks: []preflight.Check{failing},
+			want: want{
+				err: errors.New("pre-flight checks failed: boom: boom"),
+			},
+		},
+		"Ready": {
+			reason: "The install should succeed once all components are ready.",
+			objs:   []runtime.Object{deployment("spaces-controller", true)},
+			want: want{
+				version: "1.2.3",
+			},
+		},
+		"NotReady": {
+			reason: "The install should fail if components don't become ready.",
+			objs:   []runtime.Object{deployment("spaces-controller", false)},
+			want: want{
+				version: "1.2.3",
+				err:     errors.Wrapf(context.DeadlineExceeded, errFmtComponentsNotReady, "spaces-controller"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mgr := &mockManager{}
+			i := New(mgr, fake.NewSimpleClientset(tc.objs...),
+				WithChecks(tc.checks...),
+				WithReadyTimeout(50*time.Millisecond),
+				WithPollInterval(10*time.Millisecond))
+			err := i.Install(context.Background(), "v1.2.3", map[string]any{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nInstall(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.version, mgr.version); diff != "" {
+				t.Errorf("\n%s\nInstall(...): -want version, +got version:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultChecks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	crds := apixfake.NewSimpleClientset().ApiextensionsV1()
+	r := preflight.Run(context.Background(), DefaultChecks(fake.NewSimpleClientset(), crds, srv.Client(), DefaultNamespace, u, u)...)
+	got := map[string]preflight.Status{}
+	for _, res := range r.Results {
+		got[res.Name] = res.Status
+	}
+	want := map[string]preflight.Status{"registry": preflight.StatusPass, "license endpoint": preflight.StatusPass}
+	for name, s := range want {
+		if got[name] != s {
+			t.Errorf("DefaultChecks(...): want %s check to %s, got %q", name, s, got[name])
+		}
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	migrations := []Migration{
+		{Version: "1.3.0", Migrate: MoveValue("a", "b")},
+		{Version: "1.1.0", Migrate: MoveValue("old", "new")},
+	}
+
+	type want struct {
+		applied []string
+		values  map[string]any
+	}
+	cases := map[string]struct {
+		reason  string
+		current string
+		version string
+		want    want
+	}{
+		"MigrateAcrossVersions": {
+			reason:  "Migrations newer than the installed version and no newer than the target should be applied.",
+			current: "1.0.0",
+			version: "v1.2.0",
+			want: want{
+				applied: []string{"1.1.0"},
+				values:  map[string]any{"new": "x", "a": "y"},
+			},
+		},
+		"Downgrade": {
+			reason:  "No migrations should be applied when downgrading.",
+			current: "1.4.0",
+			version: "1.0.0",
+			want: want{
+				applied: []string{},
+				values:  map[string]any{"old": "x", "a": "y"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mgr := &mockManager{current: tc.current}
+			i := New(mgr, fake.NewSimpleClientset(), WithMigrations(migrations...), WithReadyTimeout(0))
+			applied, err := i.Upgrade(context.Background(), tc.version, map[string]any{"old": "x", "a": "y"})
+			if err != nil {
+				t.Fatalf("Upgrade(...): %v", err)
+			}
+			got := make([]string, len(applied))
+			for i, m := range applied {
+				got[i] = m.Version
+			}
+			if diff := cmp.Diff(tc.want.applied, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nUpgrade(...): -want applied, +got applied:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.values, mgr.values); diff != "" {
+				t.Errorf("\n%s\nUpgrade(...): -want values, +got values:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uninstall
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	// managedCategory is the CRD category of Crossplane managed resources.
+	managedCategory = "managed"
+
+	errListXRDs = "failed to list composite resource definitions"
+	errListCRDs = "failed to list custom resource definitions"
+)
+
+var (
+	xrdGVR = schema.GroupVersionResource{
+		Group:    "apiextensions.crossplane.io",
+		Version:  "v1",
+		Resource: "compositeresourcedefinitions",
+	}
+
+	// packageGVRs are the kinds of Crossplane packages.
+	packageGVRs = []schema.GroupVersionResource{
+		{Group: "pkg.crossplane.io", Version: "v1", Resource: "configurations"},
+		{Group: "pkg.crossplane.io", Version: "v1", Resource: "providers"},
+		{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "functions"},
+	}
+
+	controlPlaneGVR = resources.ControlPlaneGVK.GroupVersion().WithResource("controlplanes")
+)
+
+// UXPSteps returns the steps that tear down everything UXP manages: claims,
+// then composite resources, then the managed resources they composed, and
+// finally packages.
+func UXPSteps(dyn dynamic.Interface, crds apixv1client.CustomResourceDefinitionsGetter) []Step {
+	return []Step{
+		{Name: "claims", Resources: XRDResources(dyn, true)},
+		{Name: "composite resources", Resources: XRDResources(dyn, false)},
+		{Name: "managed resources", Resources: CRDsWithCategory(crds, managedCategory), WaitOnly: true},
+		{Name: "packages", Resources: Static(packageGVRs...)},
+	}
+}
+
+// SpacesSteps returns the steps that tear down everything Spaces manages.
+func SpacesSteps() []Step {
+	return []Step{
+		{Name: "control planes", Resources: Static(controlPlaneGVR)},
+	}
+}
+
+// Static returns the supplied kinds of resources.
+func Static(gvrs ...schema.GroupVersionResource) ResourcesFn {
+	return func(_ context.Context) ([]schema.GroupVersionResource, error) {
+		return gvrs, nil
+	}
+}
+
+// XRDResources returns the kinds of claims, or of composite resources, that
+// the composite resource definitions in the cluster define.
+func XRDResources(dyn dynamic.Interface, claims bool) ResourcesFn {
+	return func(ctx context.Context) ([]schema.GroupVersionResource, error) {
+		l, err := dyn.Resource(xrdGVR).List(ctx, metav1.ListOptions{})
+		if resourceErr(err) != nil {
+			return nil, errors.Wrap(err, errListXRDs)
+		}
+		if l == nil {
+			return nil, nil
+		}
+		gvrs := []schema.GroupVersionResource{}
+		for _, xrd := range l.Items {
+			group, _, _ := unstructured.NestedString(xrd.Object, "spec", "group")
+			names := "names"
+			if claims {
+				names = "claimNames"
+			}
+			plural, _, _ := unstructured.NestedString(xrd.Object, "spec", names, "plural")
+			version := referenceableVersion(xrd)
+			if plural == "" || version == "" {
+				continue
+			}
+			gvrs = append(gvrs, schema.GroupVersionResource{Group: group, Version: version, Resource: plural})
+		}
+		return gvrs, nil
+	}
+}
+
+// referenceableVersion returns the referenceable version of the supplied
+// composite resource definition.
+func referenceableVersion(xrd unstructured.Unstructured) string {
+	versions, _, _ := unstructured.NestedSlice(xrd.Object, "spec", "versions")
+	for _, v := range versions {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		if ref, _ := m["referenceable"].(bool); ref {
+			name, _ := m["name"].(string)
+			return name
+		}
+	}
+	return ""
+}
+
+// CRDsWithCategory returns the kinds of the custom resource definitions in
+// the supplied category.
+func CRDsWithCategory(crds apixv1client.CustomResourceDefinitionsGetter, category string) ResourcesFn {
+	return func(ctx context.Context) ([]schema.GroupVersionResource, error) {
+		l, err := crds.CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, errListCRDs)
+		}
+		gvrs := []schema.GroupVersionResource{}
+		for _, crd := range l.Items {
+			if !hasCategory(crd, category) {
+				continue
+			}
+			if v := storageVersion(crd); v != "" {
+				gvrs = append(gvrs, schema.GroupVersionResource{Group: crd.Spec.Group, Version: v, Resource: crd.Spec.Names.Plural})
+			}
+		}
+		return gvrs, nil
+	}
+}
+
+func hasCategory(crd apixv1.CustomResourceDefinition, category string) bool {
+	for _, c := range crd.Spec.Names.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func storageVersion(crd apixv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
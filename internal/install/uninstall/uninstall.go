@@ -0,0 +1,240 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package uninstall removes UXP and Spaces from a cluster, tearing down the
+// resources they manage in dependency order first.
+package uninstall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/install"
+)
+
+const (
+	defaultStepTimeout  = 5 * time.Minute
+	defaultPollInterval = 2 * time.Second
+
+	// removeFinalizers is a merge patch that removes all finalizers.
+	removeFinalizers = `{"metadata":{"finalizers":null}}`
+
+	errFmtResources       = "failed to get resources of step %q"
+	errFmtList            = "failed to list %s"
+	errFmtDelete          = "failed to delete %s"
+	errFmtStripFinalizers = "failed to remove finalizers of %s"
+	errFmtStuck           = "step %q timed out with %d resources remaining: %s; use --force to remove their finalizers"
+	errUninstallRelease   = "failed to uninstall release"
+)
+
+// A ResourcesFn returns the kinds of resources a step removes.
+type ResourcesFn func(ctx context.Context) ([]schema.GroupVersionResource, error)
+
+// A Step removes all resources of some kinds before the next step runs.
+type Step struct {
+	Name      string
+	Resources ResourcesFn
+
+	// WaitOnly waits for the resources to be removed by earlier steps,
+	// e.g. managed resources removed by deleting their composite
+	// resources, rather than deleting them.
+	WaitOnly bool
+}
+
+// An Object identifies a resource.
+type Object struct {
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Name      string
+}
+
+func (o Object) String() string {
+	id := o.Name
+	if o.Namespace != "" {
+		id = o.Namespace + "/" + o.Name
+	}
+	return fmt.Sprintf("%s %s", o.Resource.GroupResource(), id)
+}
+
+// A Report records what an uninstall removed.
+type Report struct {
+	// Deleted resources.
+	Deleted []Object
+
+	// Orphaned resources whose finalizers were removed because they were
+	// stuck. The external resources they manage may still exist.
+	Orphaned []Object
+}
+
+// An Uninstaller removes an install.
+type Uninstaller struct {
+	mgr   install.Manager
+	dyn   dynamic.Interface
+	steps []Step
+	force bool
+
+	stepTimeout  time.Duration
+	pollInterval time.Duration
+}
+
+// An Option modifies an Uninstaller.
+type Option func(*Uninstaller)
+
+// WithSteps sets the steps run before the release is uninstalled.
+func WithSteps(s ...Step) Option {
+	return func(u *Uninstaller) {
+		u.steps = s
+	}
+}
+
+// WithForce removes the finalizers of resources that are still present when
+// a step times out, orphaning them, rather than failing.
+func WithForce(f bool) Option {
+	return func(u *Uninstaller) {
+		u.force = f
+	}
+}
+
+// WithStepTimeout sets how long each step waits for its resources to be
+// removed.
+func WithStepTimeout(d time.Duration) Option {
+	return func(u *Uninstaller) {
+		u.stepTimeout = d
+	}
+}
+
+// WithPollInterval sets how often removal of resources is checked.
+func WithPollInterval(d time.Duration) Option {
+	return func(u *Uninstaller) {
+		u.pollInterval = d
+	}
+}
+
+// New returns an Uninstaller that uninstalls the release of the supplied
+// Manager.
+func New(mgr install.Manager, dyn dynamic.Interface, opts ...Option) *Uninstaller {
+	u := &Uninstaller{
+		mgr:          mgr,
+		dyn:          dyn,
+		stepTimeout:  defaultStepTimeout,
+		pollInterval: defaultPollInterval,
+	}
+	for _, o := range opts {
+		o(u)
+	}
+	return u
+}
+
+// Uninstall runs each step in order and then uninstalls the release. The
+// report is returned even if the uninstall fails.
+func (u *Uninstaller) Uninstall(ctx context.Context) (*Report, error) {
+	r := &Report{}
+	for _, s := range u.steps {
+		if err := u.run(ctx, s, r); err != nil {
+			return r, err
+		}
+	}
+	return r, errors.Wrap(u.mgr.Uninstall(), errUninstallRelease)
+}
+
+func (u *Uninstaller) run(ctx context.Context, s Step, r *Report) error { //nolint:gocyclo // a linear sequence of phases
+	gvrs, err := s.Resources(ctx)
+	if err != nil {
+		return errors.Wrapf(err, errFmtResources, s.Name)
+	}
+	if len(gvrs) == 0 {
+		return nil
+	}
+	if !s.WaitOnly {
+		objs, err := u.list(ctx, gvrs)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			err := u.dyn.Resource(o.Resource).Namespace(o.Namespace).Delete(ctx, o.Name, metav1.DeleteOptions{})
+			if resourceErr(err) != nil {
+				return errors.Wrapf(err, errFmtDelete, o)
+			}
+			r.Deleted = append(r.Deleted, o)
+		}
+	}
+
+	var remaining []Object
+	err = wait.PollUntilContextTimeout(ctx, u.pollInterval, u.stepTimeout, true, func(ctx context.Context) (bool, error) {
+		remaining, err = u.list(ctx, gvrs)
+		return len(remaining) == 0, err
+	})
+	if err == nil {
+		return nil
+	}
+	if len(remaining) == 0 || !wait.Interrupted(err) {
+		return err
+	}
+	if !u.force {
+		names := make([]string, len(remaining))
+		for i, o := range remaining {
+			names[i] = o.String()
+		}
+		return errors.Errorf(errFmtStuck, s.Name, len(remaining), strings.Join(names, ", "))
+	}
+	for _, o := range remaining {
+		_, err := u.dyn.Resource(o.Resource).Namespace(o.Namespace).Patch(ctx, o.Name, types.MergePatchType, []byte(removeFinalizers), metav1.PatchOptions{})
+		if resourceErr(err) != nil {
+			return errors.Wrapf(err, errFmtStripFinalizers, o)
+		}
+		r.Orphaned = append(r.Orphaned, o)
+	}
+	return nil
+}
+
+// list returns all resources of the supplied kinds in all namespaces.
+func (u *Uninstaller) list(ctx context.Context, gvrs []schema.GroupVersionResource) ([]Object, error) {
+	objs := []Object{}
+	for _, gvr := range gvrs {
+		l, err := u.dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if resourceErr(err) != nil {
+			return nil, errors.Wrapf(err, errFmtList, gvr.GroupResource())
+		}
+		if l == nil {
+			continue
+		}
+		for _, o := range l.Items {
+			objs = append(objs, object(gvr, o))
+		}
+	}
+	return objs, nil
+}
+
+func object(gvr schema.GroupVersionResource, u unstructured.Unstructured) Object {
+	return Object{Resource: gvr, Namespace: u.GetNamespace(), Name: u.GetName()}
+}
+
+// resourceErr ignores errors of resources or kinds that are already gone.
+func resourceErr(err error) error {
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
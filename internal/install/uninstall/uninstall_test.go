@@ -0,0 +1,186 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uninstall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/upbound/up/internal/install"
+)
+
+var (
+	claimGVR  = schema.GroupVersionResource{Group: "acme.io", Version: "v1", Resource: "databases"}
+	xrGVR     = schema.GroupVersionResource{Group: "acme.io", Version: "v1", Resource: "xdatabases"}
+	mrGVR     = schema.GroupVersionResource{Group: "rds.aws.upbound.io", Version: "v1beta1", Resource: "instances"}
+	listKinds = map[schema.GroupVersionResource]string{
+		xrdGVR:          "CompositeResourceDefinitionList",
+		claimGVR:        "DatabaseList",
+		xrGVR:           "XDatabaseList",
+		mrGVR:           "InstanceList",
+		packageGVRs[0]:  "ConfigurationList",
+		packageGVRs[1]:  "ProviderList",
+		packageGVRs[2]:  "FunctionList",
+		controlPlaneGVR: "ControlPlaneList",
+	}
+)
+
+type mockManager struct {
+	uninstalled bool
+}
+
+func (m *mockManager) GetCurrentVersion() (string, error) { return "", nil }
+
+func (m *mockManager) Install(context.Context, string, map[string]any, ...install.InstallOption) error {
+	return nil
+}
+
+func (m *mockManager) Upgrade(context.Context, string, map[string]any, ...install.UpgradeOption) error {
+	return nil
+}
+
+func (m *mockManager) Uninstall() error {
+	m.uninstalled = true
+	return nil
+}
+
+func obj(apiVersion, kind, namespace, name string, finalizers ...string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetFinalizers(finalizers)
+	return u
+}
+
+func xrd() *unstructured.Unstructured {
+	u := obj("apiextensions.crossplane.io/v1", "CompositeResourceDefinition", "", "xdatabases.acme.io")
+	_ = unstructured.SetNestedField(u.Object, "acme.io", "spec", "group")
+	_ = unstructured.SetNestedField(u.Object, "xdatabases", "spec", "names", "plural")
+	_ = unstructured.SetNestedField(u.Object, "databases", "spec", "claimNames", "plural")
+	_ = unstructured.SetNestedSlice(u.Object, []any{map[string]any{"name": "v1", "referenceable": true}}, "spec", "versions")
+	return u
+}
+
+func managedCRD() *apixv1.CustomResourceDefinition {
+	return &apixv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "instances.rds.aws.upbound.io"},
+		Spec: apixv1.CustomResourceDefinitionSpec{
+			Group: "rds.aws.upbound.io",
+			Names: apixv1.CustomResourceDefinitionNames{Plural: "instances", Categories: []string{"crossplane", "managed"}},
+			Versions: []apixv1.CustomResourceDefinitionVersion{
+				{Name: "v1beta1", Served: true, Storage: true},
+			},
+		},
+	}
+}
+
+func TestUninstall(t *testing.T) {
+	claim := obj("acme.io/v1", "Database", "default", "db")
+	xr := obj("acme.io/v1", "XDatabase", "", "db-x1")
+	provider := obj("pkg.crossplane.io/v1", "Provider", "", "provider-aws-rds")
+	mr := obj("rds.aws.upbound.io/v1beta1", "Instance", "", "db-x1-abc", "finalizer.managedresource.crossplane.io")
+
+	type want struct {
+		report      *Report
+		uninstalled bool
+		err         error
+	}
+	cases := map[string]struct {
+		reason string
+		objs   []runtime.Object
+		force  bool
+		want   want
+	}{
+		"Clean": {
+			reason: "Claims, composite resources and packages should be deleted in order before the release is uninstalled.",
+			objs:   []runtime.Object{xrd(), claim, xr, provider},
+			want: want{
+				report: &Report{Deleted: []Object{
+					{Resource: claimGVR, Namespace: "default", Name: "db"},
+					{Resource: xrGVR, Name: "db-x1"},
+					{Resource: packageGVRs[1], Name: "provider-aws-rds"},
+				}},
+				uninstalled: true,
+			},
+		},
+		"Stuck": {
+			reason: "Without force, a managed resource that isn't removed should fail the uninstall.",
+			objs:   []runtime.Object{xrd(), mr},
+			want: want{
+				report: &Report{},
+				err:    errors.Errorf(errFmtStuck, "managed resources", 1, "instances.rds.aws.upbound.io db-x1-abc"),
+			},
+		},
+		"Forced": {
+			reason: "With force, the finalizers of a stuck managed resource should be removed and it should be reported as orphaned.",
+			objs:   []runtime.Object{xrd(), mr},
+			force:  true,
+			want: want{
+				report: &Report{Orphaned: []Object{
+					{Resource: mrGVR, Name: "db-x1-abc"},
+				}},
+				uninstalled: true,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tc.objs...)
+			crds := apixfake.NewSimpleClientset(managedCRD()).ApiextensionsV1()
+			mgr := &mockManager{}
+			u := New(mgr, dyn,
+				WithSteps(UXPSteps(dyn, crds)...),
+				WithForce(tc.force),
+				WithStepTimeout(50*time.Millisecond),
+				WithPollInterval(10*time.Millisecond))
+
+			got, err := u.Uninstall(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nUninstall(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.report, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nUninstall(...): -want report, +got report:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.uninstalled, mgr.uninstalled); diff != "" {
+				t.Errorf("\n%s\nUninstall(...): -want uninstalled, +got uninstalled:\n%s", tc.reason, diff)
+			}
+			if !tc.force {
+				return
+			}
+			o, err := dyn.Resource(mrGVR).Get(context.Background(), mr.GetName(), metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(o.GetFinalizers()) != 0 {
+				t.Errorf("\n%s\nUninstall(...): finalizers not removed: %v", tc.reason, o.GetFinalizers())
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package uxp installs and upgrades UXP in a cluster.
+package uxp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/helm"
+	"github.com/upbound/up/internal/install/preflight"
+	"github.com/upbound/up/internal/kube"
+	"github.com/upbound/up/internal/license"
+)
+
+const (
+	// DefaultPullSecret is the name of the image pull secret the license
+	// access key is written to.
+	DefaultPullSecret = "uxp-pull-secret"
+
+	// accessKeyUser is the registry user that authenticates with a license
+	// access key.
+	accessKeyUser = "_json_key"
+
+	// pullSecretsValue is the chart value listing the image pull secrets.
+	pullSecretsValue = "imagePullSecrets"
+
+	// kubernetesVersion is the constraint on the Kubernetes version UXP
+	// supports.
+	kubernetesVersion = ">= 1.24"
+
+	errCreateNamespace = "failed to create namespace"
+	errLicenseVersion  = "a version is required to acquire a license access key"
+	errGetAccessKey    = "failed to acquire license access key"
+	errApplyPullSecret = "failed to write image pull secret"
+	errFmtReadValues   = "failed to read values file %s"
+	errParseValues     = "failed to parse values"
+)
+
+// An Installer installs and upgrades UXP.
+type Installer struct {
+	mgr       install.Manager
+	kube      kubernetes.Interface
+	namespace string
+	checks    []preflight.Check
+	report    preflight.ReportFn
+
+	license    license.Provider
+	token      string
+	pullSecret string
+	registry   string
+}
+
+// An Option modifies an Installer.
+type Option func(*Installer)
+
+// WithNamespace sets the namespace UXP is installed in.
+func WithNamespace(ns string) Option {
+	return func(i *Installer) {
+		i.namespace = ns
+	}
+}
+
+// WithChecks sets the pre-flight checks run before installing.
+func WithChecks(c ...preflight.Check) Option {
+	return func(i *Installer) {
+		i.checks = c
+	}
+}
+
+// WithReportFn sets a function that is called with the pre-flight report
+// before installing.
+func WithReportFn(fn preflight.ReportFn) Option {
+	return func(i *Installer) {
+		i.report = fn
+	}
+}
+
+// WithLicense acquires a license access key for the installed version from
+// the supplied provider, authenticating with the supplied token. An empty
+// token uses the provider's token store.
+func WithLicense(p license.Provider, token string) Option {
+	return func(i *Installer) {
+		i.license = p
+		i.token = token
+	}
+}
+
+// WithPullSecret sets the name of the image pull secret the license access
+// key is written to and the registry it authenticates to.
+func WithPullSecret(name, registry string) Option {
+	return func(i *Installer) {
+		i.pullSecret = name
+		i.registry = registry
+	}
+}
+
+// New returns an Installer that installs UXP with the supplied Manager.
+func New(mgr install.Manager, kube kubernetes.Interface, opts ...Option) *Installer {
+	i := &Installer{
+		mgr:        mgr,
+		kube:       kube,
+		namespace:  "upbound-system",
+		pullSecret: DefaultPullSecret,
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	return i
+}
+
+// DefaultChecks returns the pre-flight checks run before UXP is installed
+// in the supplied namespace. The supplied HTTP client is used to check that
+// the registry and the license endpoint (DMV) can be reached.
+func DefaultChecks(kube kubernetes.Interface, client *http.Client, namespace string, registry, dmv *url.URL) []preflight.Check {
+	return []preflight.Check{
+		preflight.KubernetesVersion(kube.Discovery(), kubernetesVersion),
+		preflight.ConflictingDeployments(kube, namespace, "crossplane"),
+		preflight.Connectivity(client, "registry", registry.String()),
+		preflight.Connectivity(client, "license endpoint", dmv.String()),
+	}
+}
+
+// Preflight runs the pre-flight checks of the Installer.
+func (i *Installer) Preflight(ctx context.Context) *preflight.Report {
+	return preflight.Run(ctx, i.checks...)
+}
+
+// Install runs the pre-flight checks, installs the supplied version of UXP
+// with the supplied values and returns the installed version.
+func (i *Installer) Install(ctx context.Context, version string, values map[string]any) (string, error) {
+	r := i.Preflight(ctx)
+	if i.report != nil {
+		i.report(r)
+	}
+	if err := r.Err(); err != nil {
+		return "", err
+	}
+	_, err := i.kube.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: i.namespace,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil && !kerrors.IsAlreadyExists(err) {
+		return "", errors.Wrap(err, errCreateNamespace)
+	}
+	if err := i.applyPullSecret(ctx, version, values); err != nil {
+		return "", err
+	}
+	if err := i.mgr.Install(ctx, version, values); err != nil {
+		return "", err
+	}
+	return i.mgr.GetCurrentVersion()
+}
+
+// Upgrade upgrades UXP to the supplied version with the supplied values and
+// returns the installed version. The license access key is acquired again
+// for the new version.
+func (i *Installer) Upgrade(ctx context.Context, version string, values map[string]any) (string, error) {
+	if err := i.applyPullSecret(ctx, version, values); err != nil {
+		return "", err
+	}
+	if err := i.mgr.Upgrade(ctx, version, values); err != nil {
+		return "", err
+	}
+	return i.mgr.GetCurrentVersion()
+}
+
+// applyPullSecret acquires a license access key for the supplied version,
+// writes it to the image pull secret and adds the secret to the image pull
+// secrets of the chart values. It does nothing without a license provider.
+func (i *Installer) applyPullSecret(ctx context.Context, version string, values map[string]any) error {
+	if i.license == nil {
+		return nil
+	}
+	if version == "" {
+		return errors.New(errLicenseVersion)
+	}
+	res, err := i.license.GetAccessKey(ctx, i.token, version)
+	if err != nil {
+		return errors.Wrap(err, errGetAccessKey)
+	}
+	if err := kube.NewImagePullApplicator(kube.NewSecretApplicator(i.kube)).Apply(ctx, i.pullSecret, i.namespace, accessKeyUser, res.AccessKey, i.registry); err != nil {
+		return errors.Wrap(err, errApplyPullSecret)
+	}
+
+	secrets, _ := values[pullSecretsValue].([]any)
+	for _, s := range secrets {
+		if s == i.pullSecret {
+			return nil
+		}
+	}
+	values[pullSecretsValue] = append(secrets, i.pullSecret)
+	return nil
+}
+
+// Values reads the supplied values files, merging them in order so later
+// files override earlier ones, and applies the supplied --set overrides on
+// top.
+func Values(fs afero.Fs, files []string, set map[string]string) (map[string]any, error) {
+	base := map[string]any{}
+	for _, f := range files {
+		b, err := afero.ReadFile(fs, f)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtReadValues, f)
+		}
+		v := map[string]any{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, errors.Wrapf(err, errFmtReadValues, f)
+		}
+		base = merge(base, v)
+	}
+	values, err := helm.NewParser(base, set).Parse()
+	return values, errors.Wrap(err, errParseValues)
+}
+
+// merge deeply merges src into dst. Values in src take precedence, except
+// that nested maps are merged rather than replaced.
+func merge(dst, src map[string]any) map[string]any {
+	for k, v := range src {
+		sm, ok := v.(map[string]any)
+		dm, dok := dst[k].(map[string]any)
+		if ok && dok {
+			dst[k] = merge(dm, sm)
+			continue
+		}
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,246 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uxp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/upbound/up/internal/install"
+	"github.com/upbound/up/internal/install/preflight"
+	"github.com/upbound/up/internal/license"
+)
+
+type mockManager struct {
+	version string
+	values  map[string]any
+	err     error
+}
+
+func (m *mockManager) GetCurrentVersion() (string, error) { return m.version, nil }
+
+func (m *mockManager) Install(_ context.Context, version string, values map[string]any, _ ...install.InstallOption) error {
+	m.version, m.values = version, values
+	return m.err
+}
+
+func (m *mockManager) Upgrade(_ context.Context, version string, values map[string]any, _ ...install.UpgradeOption) error {
+	m.version, m.values = version, values
+	return m.err
+}
+
+func (m *mockManager) Uninstall() error { return nil }
+
+type mockLicense struct {
+	version string
+	err     error
+}
+
+func (m *mockLicense) GetAccessKey(_ context.Context, _, version string) (*license.Response, error) {
+	m.version = version
+	return &license.Response{AccessKey: "key-" + version}, m.err
+}
+
+func TestInstall(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		version string
+		values  map[string]any
+		key     string
+		err     error
+	}
+	cases := map[string]struct {
+		reason  string
+		license *mockLicense
+		checks  []preflight.Check
+		values  map[string]any
+		mgrErr  error
+		want    want
+	}{
+		"Unlicensed": {
+			reason: "Without a license provider UXP should be installed without a pull secret.",
+			values: map[string]any{"replicas": 2},
+			want: want{
+				version: "v1.14.0-up.1",
+				values:  map[string]any{"replicas": 2},
+			},
+		},
+		"Licensed": {
+			reason:  "The access key for the installed version should be written to the pull secret, which the chart should use.",
+			license: &mockLicense{},
+			values:  map[string]any{"imagePullSecrets": []any{"other"}},
+			want: want{
+				version: "v1.14.0-up.1",
+				values:  map[string]any{"imagePullSecrets": []any{"other", DefaultPullSecret}},
+				key:     "key-v1.14.0-up.1",
+			},
+		},
+		"LicenseError": {
+			reason:  "UXP should not be installed if the access key cannot be acquired.",
+			license: &mockLicense{err: errBoom},
+			values:  map[string]any{},
+			want: want{
+				err: errors.Wrap(errBoom, errGetAccessKey),
+			},
+		},
+		"PreflightFailed": {
+			reason: "UXP should not be installed if a pre-flight check fails.",
+			checks: []preflight.Check{preflight.NewCheck("boom", func(context.Context) preflight.Result { return preflight.Fail("boom") })},
+			values: map[string]any{},
+			want: want{
+				err: errors.New("pre-flight checks failed: boom: boom"),
+			},
+		},
+		"InstallError": {
+			reason: "Install errors should be returned.",
+			values: map[string]any{},
+			mgrErr: errBoom,
+			want: want{
+				err: errBoom,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewSimpleClientset()
+			mgr := &mockManager{err: tc.mgrErr}
+			opts := []Option{WithNamespace("upbound-system"), WithPullSecret(DefaultPullSecret, "xpkg.upbound.io"), WithChecks(tc.checks...)}
+			if tc.license != nil {
+				opts = append(opts, WithLicense(tc.license, "token"))
+			}
+
+			got, err := New(mgr, kube, opts...).Install(context.Background(), "v1.14.0-up.1", tc.values)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nInstall(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.version, got); diff != "" {
+				t.Errorf("\n%s\nInstall(...): -want version, +got version:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.values, mgr.values); diff != "" {
+				t.Errorf("\n%s\nInstall(...): -want values, +got values:\n%s", tc.reason, diff)
+			}
+			if _, err := kube.CoreV1().Namespaces().Get(context.Background(), "upbound-system", metav1.GetOptions{}); err != nil {
+				t.Errorf("\n%s\nInstall(...): namespace not created: %v", tc.reason, err)
+			}
+
+			s, err := kube.CoreV1().Secrets("upbound-system").Get(context.Background(), DefaultPullSecret, metav1.GetOptions{})
+			if tc.want.key == "" {
+				if err == nil {
+					t.Errorf("\n%s\nInstall(...): unexpected pull secret", tc.reason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("\n%s\nInstall(...): pull secret not written: %v", tc.reason, err)
+			}
+			cfg := struct {
+				Auths map[string]struct {
+					Password string `json:"password"`
+				} `json:"auths"`
+			}{}
+			if err := json.Unmarshal(s.Data[corev1.DockerConfigJsonKey], &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want.key, cfg.Auths["xpkg.upbound.io"].Password); diff != "" {
+				t.Errorf("\n%s\nInstall(...): -want access key, +got access key:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultChecks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	r := preflight.Run(context.Background(), DefaultChecks(fake.NewSimpleClientset(), srv.Client(), "upbound-system", u, u)...)
+	got := map[string]preflight.Status{}
+	for _, res := range r.Results {
+		got[res.Name] = res.Status
+	}
+	want := map[string]preflight.Status{"registry": preflight.StatusPass, "license endpoint": preflight.StatusPass}
+	for name, s := range want {
+		if got[name] != s {
+			t.Errorf("DefaultChecks(...): want %s check to %s, got %q", name, s, got[name])
+		}
+	}
+}
+
+func TestUpgradeRequiresVersionWhenLicensed(t *testing.T) {
+	_, err := New(&mockManager{}, fake.NewSimpleClientset(), WithLicense(&mockLicense{}, "")).Upgrade(context.Background(), "", map[string]any{})
+	if diff := cmp.Diff(errors.New(errLicenseVersion), err, test.EquateErrors()); diff != "" {
+		t.Errorf("Upgrade(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestValues(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "base.yaml", []byte("replicas: 1\nresources:\n  limits:\n    cpu: 100m\n    memory: 512Mi\n"), 0o600)
+	_ = afero.WriteFile(fs, "prod.yaml", []byte("replicas: 3\nresources:\n  limits:\n    cpu: 1\n"), 0o600)
+
+	cases := map[string]struct {
+		reason string
+		files  []string
+		set    map[string]string
+		want   map[string]any
+		err    error
+	}{
+		"MergeFilesAndSet": {
+			reason: "Later files should override earlier ones without dropping nested values, and --set should override files.",
+			files:  []string{"base.yaml", "prod.yaml"},
+			set:    map[string]string{"replicas": "5"},
+			want: map[string]any{
+				"replicas": int64(5),
+				"resources": map[string]any{
+					"limits": map[string]any{"cpu": float64(1), "memory": "512Mi"},
+				},
+			},
+		},
+		"MissingFile": {
+			reason: "A missing values file should return an error.",
+			files:  []string{"missing.yaml"},
+			err:    errors.Wrapf(errors.New("open missing.yaml: file does not exist"), errFmtReadValues, "missing.yaml"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Values(fs, tc.files, tc.set)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/transport"
+
+	uphttp "github.com/upbound/up/internal/http"
 )
 
 const (
@@ -48,6 +50,33 @@ func GetKubeConfig(path string) (*rest.Config, error) {
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
 }
 
+// SetClientCertificate configures the supplied REST config to present the
+// supplied client certificate. It does nothing if the certificate is empty.
+func SetClientCertificate(cfg *rest.Config, cc uphttp.ClientCertificate) {
+	if cc.IsZero() {
+		return
+	}
+	cfg.TLSClientConfig.CertFile = cc.CertFile
+	cfg.TLSClientConfig.KeyFile = cc.KeyFile
+	cfg.TLSClientConfig.CertData = cc.CertPEM
+	cfg.TLSClientConfig.KeyData = cc.KeyPEM
+}
+
+// SetKubeconfigClientCertificate configures every user in the supplied
+// kubeconfig to present the supplied client certificate. It does nothing if
+// the certificate is empty.
+func SetKubeconfigClientCertificate(conf *api.Config, cc uphttp.ClientCertificate) {
+	if cc.IsZero() {
+		return
+	}
+	for _, ai := range conf.AuthInfos {
+		ai.ClientCertificate = cc.CertFile
+		ai.ClientKey = cc.KeyFile
+		ai.ClientCertificateData = cc.CertPEM
+		ai.ClientKeyData = cc.KeyPEM
+	}
+}
+
 // BuildControlPlaneKubeconfig builds a kubeconfig entry for a control plane.
 func BuildControlPlaneKubeconfig(proxy *url.URL, id string, token string, includePrefix bool) *api.Config { //nolint:interfacer
 	conf := api.NewConfig()
@@ -15,22 +15,36 @@
 package license
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 
+	"github.com/upbound/up/internal/failure"
 	uphttp "github.com/upbound/up/internal/http"
+	"github.com/upbound/up/internal/telemetry"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 const (
-	path = "/v1/accessKey/%s/%s:%s"
+	path = "%s/accessKey/%s/%s:%s"
+
+	// DefaultAPIVersion is the version of the DMV API requests are sent to.
+	DefaultAPIVersion = "v1"
+	// DefaultUserAgent is the product in the User-Agent of requests.
+	DefaultUserAgent = "up-cli"
 
 	errGetAccessKey = "failed to acquire key"
+	errFmtStatus    = "unexpected status %d"
 )
 
 // Response is the response returned from a successful access key request
@@ -48,7 +62,11 @@ type Provider interface {
 func NewProvider(modifiers ...ProviderModifierFn) *DMV {
 
 	p := &DMV{
-		client: &http.Client{},
+		client:     uphttp.NewClient(),
+		apiVersion: DefaultAPIVersion,
+		userAgent:  DefaultUserAgent,
+		log:        logging.NewNopLogger(),
+		telemetry:  telemetry.Deferred,
 	}
 
 	for _, m := range modifiers {
@@ -60,11 +78,21 @@ func NewProvider(modifiers ...ProviderModifierFn) *DMV {
 
 // DMV represents the DMV specific license provider
 type DMV struct {
-	client   uphttp.Client
-	endpoint *url.URL
+	client     uphttp.Client
+	endpoint   *url.URL
+	apiVersion string
+	userAgent  string
+	timeout    time.Duration
 
 	orgID     string
 	productID string
+
+	tokens     tokenstore.Store
+	tokenKey   string
+	robotToken string
+
+	log       logging.Logger
+	telemetry telemetry.Recorder
 }
 
 // ProviderModifierFn modifies the provider.
@@ -91,31 +119,96 @@ func WithProductID(productID string) ProviderModifierFn {
 	}
 }
 
-// GetAccessKey returns the license access key corresponding to the supplied version if
-// the given token is valid.
-func (d *DMV) GetAccessKey(ctx context.Context, token, version string) (*Response, error) {
+// WithAPIVersion sets the version of the DMV API requests are sent to, e.g.
+// v2. The version is appended to the path of the endpoint.
+func WithAPIVersion(v string) ProviderModifierFn {
+	return func(u *DMV) {
+		u.apiVersion = v
+	}
+}
 
-	d.endpoint.Path = fmt.Sprintf(path, d.orgID, d.productID, version)
+// WithUserAgent sets the User-Agent of requests. See UserAgent.
+func WithUserAgent(ua string) ProviderModifierFn {
+	return func(u *DMV) {
+		u.userAgent = ua
+	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint.String(), nil)
-	if err != nil {
-		return &Response{}, errors.Wrap(err, errGetAccessKey)
+// WithTimeout bounds the time each request may take. Requests are only
+// bounded by their context by default.
+func WithTimeout(d time.Duration) ProviderModifierFn {
+	return func(u *DMV) {
+		u.timeout = d
 	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	// add authorization header to the req
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+// UserAgent returns a User-Agent identifying the version of up and the target
+// it is installing to, e.g. "up-cli/v0.21.0 (uxp; linux/amd64)".
+func UserAgent(version, target string) string {
+	comments := []string{runtime.GOOS + "/" + runtime.GOARCH}
+	if target != "" {
+		comments = append([]string{target}, comments...)
+	}
+	return fmt.Sprintf("%s/%s (%s)", DefaultUserAgent, version, strings.Join(comments, "; "))
+}
 
-	res, err := d.client.Do(req)
-	if err != nil {
-		return nil, errors.Wrap(err, errGetAccessKey)
+// WithTransportOptions sets options for the transport requests are sent
+// with, e.g. to refresh rejected tokens.
+func WithTransportOptions(opts ...uphttp.TransportOption) ProviderModifierFn {
+	return func(u *DMV) {
+		u.client = uphttp.NewClient(opts...)
 	}
-	defer res.Body.Close() // nolint:gosec,errcheck
+}
 
-	b, err := io.ReadAll(res.Body)
+// WithTokenStore sets the store the token is read from when GetAccessKey is
+// called without one.
+func WithTokenStore(s tokenstore.Store, key string) ProviderModifierFn {
+	return func(u *DMV) {
+		u.tokens = s
+		u.tokenKey = key
+	}
+}
+
+// WithLogger sets the logger used by the license provider.
+func WithLogger(l logging.Logger) ProviderModifierFn {
+	return func(u *DMV) {
+		u.log = l
+	}
+}
+
+// WithTelemetry sets the Recorder access key requests are recorded with. They
+// are recorded with the telemetry.DefaultRecorder by default.
+func WithTelemetry(r telemetry.Recorder) ProviderModifierFn {
+	return func(u *DMV) {
+		u.telemetry = r
+	}
+}
+
+// GetAccessKey returns the license access key corresponding to the supplied version if
+// the given token is valid. If a robot token was configured it is exchanged
+// for the access key instead.
+func (d *DMV) GetAccessKey(ctx context.Context, token, version string) (_ *Response, err error) {
+	defer telemetry.Observe(d.telemetry, "license access key", time.Now(), &err)
+	if d.robotToken != "" {
+		return d.ExchangeRobotToken(ctx, d.robotToken, version)
+	}
+	if token == "" && d.tokens != nil {
+		t, err := d.tokens.Get(d.tokenKey)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetAccessKey)
+		}
+		token = t
+	}
+
+	status, b, err := d.do(ctx, http.MethodGet, path, version, token, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, errGetAccessKey)
 	}
+	d.log.Debug("Requested license access key", "product", d.productID, "version", version, "status", status)
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		d.log.Info("License endpoint rejected request", "status", status, "body", string(b))
+		return nil, errors.Wrap(failure.FromStatus(status, errors.Errorf(errFmtStatus, status)), errGetAccessKey)
+	}
 
 	var resp Response
 	if err := json.Unmarshal(b, &resp); err != nil {
@@ -124,3 +217,51 @@ func (d *DMV) GetAccessKey(ctx context.Context, token, version string) (*Respons
 
 	return &resp, err
 }
+
+// do sends a request to the supplied path format of the configured API
+// version, authenticated with the supplied token if it is not empty, and
+// returns the status and body of the response.
+func (d *DMV) do(ctx context.Context, method, pathFmt, version, token string, body []byte) (int, []byte, error) {
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	apiVersion := d.apiVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
+	}
+	u := *d.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + fmt.Sprintf(pathFmt, "/"+apiVersion, d.orgID, d.productID, version)
+
+	var rb io.Reader
+	if body != nil {
+		rb = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), rb)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if d.userAgent != "" {
+		req.Header.Set("User-Agent", d.userAgent)
+	}
+	if token != "" {
+		// add authorization header to the req
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close() // nolint:gosec,errcheck
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, b, nil
+}
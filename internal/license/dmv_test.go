@@ -21,13 +21,18 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
 
 	"github.com/upbound/up/internal/http/mocks"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 func TestGetAccessKey(t *testing.T) {
@@ -62,6 +67,7 @@ func TestGetAccessKey(t *testing.T) {
 					},
 				},
 				endpoint: defaultURL,
+				log:      logging.NewNopLogger(),
 			},
 			want: want{
 				response: &Response{
@@ -80,9 +86,26 @@ func TestGetAccessKey(t *testing.T) {
 					},
 				},
 				endpoint: defaultURL,
+				log:      logging.NewNopLogger(),
 			},
 			err: errors.Wrap(errBoom, errGetAccessKey),
 		},
+		"ErrStatus": {
+			reason: "If dmv rejects the request an error should be returned.",
+			provider: &DMV{
+				client: &mocks.MockClient{
+					DoFn: func(req *http.Request) (*http.Response, error) {
+						return &http.Response{
+							StatusCode: http.StatusUnauthorized,
+							Body:       io.NopCloser(bytes.NewReader([]byte(`{"message": "unauthorized"}`))),
+						}, nil
+					},
+				},
+				endpoint: defaultURL,
+				log:      logging.NewNopLogger(),
+			},
+			err: errors.Wrap(errors.Errorf(errFmtStatus, http.StatusUnauthorized), errGetAccessKey),
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -97,3 +120,152 @@ func TestGetAccessKey(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAccessKeyReplay(t *testing.T) {
+	endpoint, _ := url.Parse("https://dmv.upbound.io")
+	d := &DMV{
+		client:    &http.Client{Transport: mocks.RecordOrReplay(t, "testdata/accesskey.json")},
+		endpoint:  endpoint,
+		orgID:     "org",
+		productID: "product",
+		log:       logging.NewNopLogger(),
+	}
+
+	want := &Response{
+		AccessKey: "UPBOUND-UXP-ACCESS-KEY",
+		Signature: "MEUCIQDxJ4oLw3fYpI0CqQ4xq7u+Z2jYH0e0d5wq6yG7y9Q2RQIgQm1ZKk2mZ8Q0q9oYjD1T6uXlD7k3vQ3S3aWkZl2aS9A=",
+	}
+	got, err := d.GetAccessKey(context.Background(), "bearerToken", "v1.10.0")
+	if err != nil {
+		t.Fatalf("GetAccessKey(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetAccessKey(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetAccessKeyTokenStore(t *testing.T) {
+	store := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+	if err := store.Set("key", "storedToken"); err != nil {
+		t.Fatalf("Set(...): unexpected error: %v", err)
+	}
+	endpoint, _ := url.Parse("https://test.com")
+	var auth string
+	d := NewProvider(
+		WithEndpoint(endpoint),
+		WithTokenStore(store, "key"),
+	)
+	d.client = &mocks.MockClient{
+		DoFn: func(req *http.Request) (*http.Response, error) {
+			auth = req.Header.Get("Authorization")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			}, nil
+		},
+	}
+	if _, err := d.GetAccessKey(context.Background(), "", "version"); err != nil {
+		t.Fatalf("GetAccessKey(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("Bearer storedToken", auth); diff != "" {
+		t.Errorf("GetAccessKey(...): -want Authorization, +got Authorization:\n%s", diff)
+	}
+}
+
+func TestGetAccessKeyOptions(t *testing.T) {
+	type want struct {
+		url       string
+		userAgent string
+		deadline  bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		endpoint string
+		opts     []ProviderModifierFn
+		want     want
+	}{
+		"Defaults": {
+			reason:   "Requests should be sent to the default API version with the default User-Agent.",
+			endpoint: "https://test.com",
+			want: want{
+				url:       "https://test.com/v1/accessKey/org/product:v1.10.0",
+				userAgent: DefaultUserAgent,
+			},
+		},
+		"Configured": {
+			reason:   "Requests should be sent to the configured API version below the endpoint's path, with the configured User-Agent and timeout.",
+			endpoint: "https://test.com/dmv/",
+			opts: []ProviderModifierFn{
+				WithAPIVersion("v2"),
+				WithUserAgent("up-cli/v0.21.0"),
+				WithTimeout(time.Minute),
+			},
+			want: want{
+				url:       "https://test.com/dmv/v2/accessKey/org/product:v1.10.0",
+				userAgent: "up-cli/v0.21.0",
+				deadline:  true,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			endpoint, _ := url.Parse(tc.endpoint)
+			d := NewProvider(append([]ProviderModifierFn{
+				WithEndpoint(endpoint),
+				WithOrgID("org"),
+				WithProductID("product"),
+			}, tc.opts...)...)
+			got := want{}
+			d.client = &mocks.MockClient{
+				DoFn: func(req *http.Request) (*http.Response, error) {
+					_, got.deadline = req.Context().Deadline()
+					got.url = req.URL.String()
+					got.userAgent = req.Header.Get("User-Agent")
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+					}, nil
+				},
+			}
+			if _, err := d.GetAccessKey(context.Background(), "token", "v1.10.0"); err != nil {
+				t.Fatalf("GetAccessKey(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nGetAccessKey(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.endpoint, endpoint.String()); diff != "" {
+				t.Errorf("\n%s\nGetAccessKey(...): -want endpoint, +got endpoint:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	cases := map[string]struct {
+		reason  string
+		version string
+		target  string
+		want    string
+	}{
+		"WithTarget": {
+			reason:  "The install target should be included in the User-Agent.",
+			version: "v0.21.0",
+			target:  "uxp",
+			want:    "up-cli/v0.21.0 (uxp; " + platform + ")",
+		},
+		"WithoutTarget": {
+			reason:  "The User-Agent should only include the platform when there is no target.",
+			version: "v0.21.0",
+			want:    "up-cli/v0.21.0 (" + platform + ")",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, UserAgent(tc.version, tc.target)); diff != "" {
+				t.Errorf("\n%s\nUserAgent(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package license
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	exchangePath = "%s/accessKey/%s/%s:%s:exchange"
+
+	// RobotTokenType identifies robot tokens in token exchange requests.
+	RobotTokenType = "urn:upbound:params:oauth:token-type:robot"
+
+	errExchangeRobotToken = "failed to exchange robot token for access key"
+	errNoRobotToken       = "robot token is empty"
+	errNoOrgID            = "organization is required to exchange a robot token"
+	errInvalidRobotToken  = "invalid robot token"
+	errFmtScope           = "robot token is not permitted to acquire %s access keys for organization %s: %s"
+)
+
+// Error codes returned by the DMV when a token exchange is rejected.
+const (
+	errCodeInvalidToken      = "invalid_token"
+	errCodeInsufficientScope = "insufficient_scope"
+	errCodeInvalidScope      = "invalid_scope"
+)
+
+// WithRobotToken makes GetAccessKey exchange the supplied organization robot
+// token for an access key instead of using a user's bearer token.
+func WithRobotToken(token string) ProviderModifierFn {
+	return func(u *DMV) {
+		u.robotToken = token
+	}
+}
+
+// exchangeRequest is the body of a token exchange request.
+type exchangeRequest struct {
+	SubjectToken     string `json:"subjectToken"`
+	SubjectTokenType string `json:"subjectTokenType"`
+}
+
+// exchangeError is the body of a rejected token exchange request.
+type exchangeError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// A ScopeError is returned when a robot token is valid but is not permitted
+// to acquire access keys for the product of the configured organization.
+type ScopeError struct {
+	OrgID       string
+	ProductID   string
+	Description string
+}
+
+// Error returns a description of the missing scope.
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf(errFmtScope, e.ProductID, e.OrgID, e.Description)
+}
+
+// Kind returns the kind of failure a ScopeError is.
+func (e *ScopeError) Kind() failure.Kind {
+	return failure.KindAuth
+}
+
+// IsScopeError returns true if the error was caused by a robot token lacking
+// the scope required to acquire an access key.
+func IsScopeError(err error) bool {
+	var se *ScopeError
+	return errors.As(err, &se)
+}
+
+// ExchangeRobotToken exchanges an organization robot token for the license
+// access key corresponding to the supplied version.
+func (d *DMV) ExchangeRobotToken(ctx context.Context, token, version string) (*Response, error) {
+	if token == "" {
+		return nil, errors.Wrap(errors.New(errNoRobotToken), errExchangeRobotToken)
+	}
+	if d.orgID == "" {
+		return nil, errors.Wrap(errors.New(errNoOrgID), errExchangeRobotToken)
+	}
+	body, err := json.Marshal(&exchangeRequest{SubjectToken: token, SubjectTokenType: RobotTokenType})
+	if err != nil {
+		return nil, errors.Wrap(err, errExchangeRobotToken)
+	}
+
+	status, b, err := d.do(ctx, http.MethodPost, exchangePath, version, "", body)
+	if err != nil {
+		return nil, errors.Wrap(err, errExchangeRobotToken)
+	}
+	d.log.Debug("Exchanged robot token for license access key", "product", d.productID, "version", version, "status", status)
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		d.log.Info("License endpoint rejected token exchange", "status", status, "body", string(b))
+		return nil, errors.Wrap(d.exchangeError(status, b), errExchangeRobotToken)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, errors.Wrap(err, errExchangeRobotToken)
+	}
+	return &resp, nil
+}
+
+// exchangeError returns the error described by a rejected token exchange.
+func (d *DMV) exchangeError(status int, body []byte) error {
+	e := exchangeError{}
+	_ = json.Unmarshal(body, &e)
+	switch e.Code {
+	case errCodeInsufficientScope, errCodeInvalidScope:
+		return &ScopeError{OrgID: d.orgID, ProductID: d.productID, Description: e.Description}
+	case errCodeInvalidToken:
+		if e.Description != "" {
+			return failure.Auth(errors.Wrap(errors.New(e.Description), errInvalidRobotToken))
+		}
+		return failure.Auth(errors.New(errInvalidRobotToken))
+	}
+	return failure.FromStatus(status, errors.Errorf(errFmtStatus, status))
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package license
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/http/mocks"
+)
+
+func TestExchangeRobotToken(t *testing.T) {
+	endpoint, _ := url.Parse("https://test.com")
+	respond := func(status int, body string) func(*http.Request) (*http.Response, error) {
+		return func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		}
+	}
+
+	type want struct {
+		response *Response
+		err      error
+		scope    bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		orgID  string
+		token  string
+		doFn   func(*http.Request) (*http.Response, error)
+		want   want
+	}{
+		"Success": {
+			reason: "A robot token should be exchanged for an access key.",
+			orgID:  "org",
+			token:  "robot",
+			doFn: func(req *http.Request) (*http.Response, error) {
+				body := exchangeRequest{}
+				_ = json.NewDecoder(req.Body).Decode(&body)
+				if req.Method != http.MethodPost ||
+					req.URL.Path != "/v1/accessKey/org/product:v1.10.0:exchange" ||
+					req.Header.Get("Authorization") != "" ||
+					body.SubjectToken != "robot" ||
+					body.SubjectTokenType != RobotTokenType {
+					return respond(http.StatusBadRequest, "")(req)
+				}
+				return respond(http.StatusOK, `{"key": "KEY", "signature": "SIG"}`)(req)
+			},
+			want: want{
+				response: &Response{AccessKey: "KEY", Signature: "SIG"},
+			},
+		},
+		"NoToken": {
+			reason: "An empty robot token should return an error.",
+			orgID:  "org",
+			want: want{
+				err: errors.Wrap(errors.New(errNoRobotToken), errExchangeRobotToken),
+			},
+		},
+		"NoOrg": {
+			reason: "A robot token can only be exchanged for an organization.",
+			token:  "robot",
+			want: want{
+				err: errors.Wrap(errors.New(errNoOrgID), errExchangeRobotToken),
+			},
+		},
+		"InsufficientScope": {
+			reason: "A robot token without the required scope should return a scope error.",
+			orgID:  "org",
+			token:  "robot",
+			doFn:   respond(http.StatusForbidden, `{"error": "insufficient_scope", "error_description": "robot is not a member of a team with access"}`),
+			want: want{
+				err:   errors.Wrap(&ScopeError{OrgID: "org", ProductID: "product", Description: "robot is not a member of a team with access"}, errExchangeRobotToken),
+				scope: true,
+			},
+		},
+		"InvalidToken": {
+			reason: "An invalid robot token should return an error.",
+			orgID:  "org",
+			token:  "robot",
+			doFn:   respond(http.StatusUnauthorized, `{"error": "invalid_token", "error_description": "token expired"}`),
+			want: want{
+				err: errors.Wrap(errors.Wrap(errors.New("token expired"), errInvalidRobotToken), errExchangeRobotToken),
+			},
+		},
+		"UnexpectedStatus": {
+			reason: "An unexplained rejection should return the status.",
+			orgID:  "org",
+			token:  "robot",
+			doFn:   respond(http.StatusInternalServerError, ""),
+			want: want{
+				err: errors.Wrap(errors.Errorf(errFmtStatus, http.StatusInternalServerError), errExchangeRobotToken),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := NewProvider(
+				WithEndpoint(endpoint),
+				WithOrgID(tc.orgID),
+				WithProductID("product"),
+			)
+			d.client = &mocks.MockClient{DoFn: tc.doFn}
+
+			got, err := d.ExchangeRobotToken(context.Background(), tc.token, "v1.10.0")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nExchangeRobotToken(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.scope, IsScopeError(err)); diff != "" {
+				t.Errorf("\n%s\nIsScopeError(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.response, got); diff != "" {
+				t.Errorf("\n%s\nExchangeRobotToken(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetAccessKeyRobotToken(t *testing.T) {
+	endpoint, _ := url.Parse("https://test.com")
+	var path, auth string
+	d := NewProvider(
+		WithEndpoint(endpoint),
+		WithOrgID("org"),
+		WithProductID("product"),
+		WithRobotToken("robot"),
+	)
+	d.client = &mocks.MockClient{
+		DoFn: func(req *http.Request) (*http.Response, error) {
+			path, auth = req.URL.Path, req.Header.Get("Authorization")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			}, nil
+		},
+	}
+	if _, err := d.GetAccessKey(context.Background(), "user", "v1.10.0"); err != nil {
+		t.Fatalf("GetAccessKey(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("/v1/accessKey/org/product:v1.10.0:exchange", path); diff != "" {
+		t.Errorf("GetAccessKey(...): -want path, +got path:\n%s", diff)
+	}
+	if diff := cmp.Diff("", auth); diff != "" {
+		t.Errorf("GetAccessKey(...): -want Authorization, +got Authorization:\n%s", diff)
+	}
+}
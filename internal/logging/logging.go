@@ -0,0 +1,101 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging constructs the structured loggers threaded through up's
+// API clients.
+package logging
+
+import (
+	"io"
+	"os"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// Format is the output format of a logger.
+type Format string
+
+const (
+	// FormatText writes human readable lines.
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per line, for machine parsing.
+	FormatJSON Format = "json"
+)
+
+// Verbosity levels. Clients log at Info for things users are likely to care
+// about and at Debug for details that help troubleshooting.
+const (
+	// VerbosityInfo logs Info messages only.
+	VerbosityInfo = 0
+	// VerbosityDebug additionally logs Debug messages.
+	VerbosityDebug = 1
+)
+
+type options struct {
+	verbosity int
+	format    Format
+	out       io.Writer
+}
+
+// Option modifies how a logger is constructed.
+type Option func(*options)
+
+// WithVerbosity sets the most verbose level that is logged. See VerbosityInfo
+// and VerbosityDebug.
+func WithVerbosity(v int) Option {
+	return func(o *options) {
+		o.verbosity = v
+	}
+}
+
+// WithFormat sets the output format.
+func WithFormat(f Format) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// WithWriter sets where logs are written. The default is os.Stderr.
+func WithWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.out = w
+	}
+}
+
+// New constructs a logr-backed logging.Logger.
+func New(opts ...Option) logging.Logger {
+	o := &options{
+		verbosity: VerbosityInfo,
+		format:    FormatText,
+		out:       os.Stderr,
+	}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	zopts := []zap.Opts{
+		zap.WriteTo(o.out),
+		// logr verbosity levels map to negative zap levels.
+		zap.Level(zapcore.Level(-o.verbosity)),
+		zap.StacktraceLevel(zapcore.PanicLevel),
+	}
+	if o.format == FormatJSON {
+		zopts = append(zopts, zap.JSONEncoder())
+	} else {
+		zopts = append(zopts, zap.ConsoleEncoder())
+	}
+	return logging.NewLogrLogger(zap.New(zopts...))
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		opts      []Option
+		wantLines int
+		wantJSON  bool
+	}{
+		"Info": {
+			reason:    "Debug messages should not be logged at the default verbosity.",
+			wantLines: 1,
+		},
+		"Debug": {
+			reason:    "Debug messages should be logged at debug verbosity.",
+			opts:      []Option{WithVerbosity(VerbosityDebug)},
+			wantLines: 2,
+		},
+		"JSON": {
+			reason:    "Each line should be a JSON object in JSON format.",
+			opts:      []Option{WithFormat(FormatJSON), WithVerbosity(VerbosityDebug)},
+			wantLines: 2,
+			wantJSON:  true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			log := New(append(tc.opts, WithWriter(buf))...)
+			log.Info("info", "key", "value")
+			log.Debug("debug", "key", "value")
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != tc.wantLines {
+				t.Fatalf("\n%s\nNew(...): want %d lines, got %d:\n%s", tc.reason, tc.wantLines, len(lines), buf.String())
+			}
+			if !tc.wantJSON {
+				return
+			}
+			for _, l := range lines {
+				m := map[string]any{}
+				if err := json.Unmarshal([]byte(l), &m); err != nil {
+					t.Errorf("\n%s\nNew(...): line is not JSON: %s", tc.reason, l)
+				}
+				if m["key"] != "value" {
+					t.Errorf("\n%s\nNew(...): want structured key, got %v", tc.reason, m)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logs streams logs from the pods backing Crossplane packages in a
+// control plane.
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// DefaultNamespace is the namespace Crossplane runs packages in.
+	DefaultNamespace = "crossplane-system"
+
+	labelProvider = "pkg.crossplane.io/provider"
+	labelFunction = "pkg.crossplane.io/function"
+
+	errListPods       = "failed to list pods"
+	errFmtNoPods      = "no pods found for %s %q"
+	errFmtStream      = "failed to stream logs of %s/%s"
+	errFmtUnknownKind = "unknown package kind %q"
+)
+
+// Kind is the kind of package whose pods are streamed.
+type Kind string
+
+const (
+	// KindProvider streams logs of a provider.
+	KindProvider Kind = "provider"
+	// KindFunction streams logs of a composition function.
+	KindFunction Kind = "function"
+)
+
+// Target identifies the pods to stream logs from.
+type Target struct {
+	Kind Kind
+	// Name of the package, i.e. of the Provider or Function object.
+	Name string
+	// Labels further restricts the pods, e.g. to a single revision.
+	Labels map[string]string
+}
+
+// Options control how logs are streamed.
+type Options struct {
+	// Follow keeps streaming until the context is cancelled.
+	Follow bool
+	// TailLines limits the number of lines initially read per container.
+	// Zero reads all lines.
+	TailLines int64
+	// SinceSeconds only reads lines newer than the supplied number of
+	// seconds. Zero reads all lines.
+	SinceSeconds int64
+	// Container restricts streaming to a single container.
+	Container string
+}
+
+// Line is a line logged by a container.
+type Line struct {
+	Pod       string
+	Container string
+	Text      string
+}
+
+// String returns the line prefixed by its source.
+func (l Line) String() string {
+	return fmt.Sprintf("[%s/%s] %s", l.Pod, l.Container, l.Text)
+}
+
+// LineFn is called for every line streamed. It is never called concurrently.
+type LineFn func(Line)
+
+// PrintTo returns a LineFn that prints lines, prefixed by their source, to
+// the supplied writer.
+func PrintTo(w io.Writer) LineFn {
+	return func(l Line) {
+		fmt.Fprintln(w, l)
+	}
+}
+
+// Streamer streams logs of package pods.
+type Streamer struct {
+	kube      kubernetes.Interface
+	namespace string
+}
+
+// Option modifies a Streamer.
+type Option func(*Streamer)
+
+// WithNamespace sets the namespace packages run in. This is the Crossplane
+// namespace inside a control plane, or the control plane's namespace when
+// streaming from its host Space.
+func WithNamespace(ns string) Option {
+	return func(s *Streamer) {
+		s.namespace = ns
+	}
+}
+
+// New constructs a new Streamer.
+func New(kube kubernetes.Interface, opts ...Option) *Streamer {
+	s := &Streamer{
+		kube:      kube,
+		namespace: DefaultNamespace,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Pods returns the pods backing the target package.
+func (s *Streamer) Pods(ctx context.Context, t Target) ([]corev1.Pod, error) {
+	sel := labels.Set{}
+	switch t.Kind {
+	case KindProvider:
+		sel[labelProvider] = t.Name
+	case KindFunction:
+		sel[labelFunction] = t.Name
+	default:
+		return nil, errors.Errorf(errFmtUnknownKind, t.Kind)
+	}
+	for k, v := range t.Labels {
+		sel[k] = v
+	}
+	l, err := s.kube.CoreV1().Pods(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: sel.AsSelector().String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errListPods)
+	}
+	if len(l.Items) == 0 {
+		return nil, errors.Errorf(errFmtNoPods, t.Kind, t.Name)
+	}
+	return l.Items, nil
+}
+
+// Stream the logs of every container of every pod backing the target package,
+// merging them line by line. Stream returns when all streams end or, when
+// following, when the context is cancelled.
+func (s *Streamer) Stream(ctx context.Context, t Target, o Options, fn LineFn) error {
+	pods, err := s.Pods(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	mu := &sync.Mutex{}
+	emit := func(l Line) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn(l)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, p := range pods {
+		for _, c := range p.Spec.Containers {
+			if o.Container != "" && c.Name != o.Container {
+				continue
+			}
+			pod, container := p.Name, c.Name
+			g.Go(func() error {
+				return s.stream(gctx, pod, container, o, emit)
+			})
+		}
+	}
+	err = g.Wait()
+	if o.Follow && ctx.Err() != nil {
+		// Cancelling a followed stream is the expected way to stop it.
+		return nil
+	}
+	return err
+}
+
+func (s *Streamer) stream(ctx context.Context, pod, container string, o Options, emit LineFn) error {
+	opts := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    o.Follow,
+	}
+	if o.TailLines > 0 {
+		opts.TailLines = &o.TailLines
+	}
+	if o.SinceSeconds > 0 {
+		opts.SinceSeconds = &o.SinceSeconds
+	}
+	rc, err := s.kube.CoreV1().Pods(s.namespace).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return errors.Wrapf(err, errFmtStream, pod, container)
+	}
+	defer rc.Close() // nolint:errcheck
+
+	sc := bufio.NewScanner(rc)
+	for sc.Scan() {
+		emit(Line{Pod: pod, Container: container, Text: sc.Text()})
+	}
+	return errors.Wrapf(sc.Err(), errFmtStream, pod, container)
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func pod(name string, lbls map[string]string, containers ...string) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: DefaultNamespace, Labels: lbls},
+	}
+	for _, c := range containers {
+		p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+	}
+	return p
+}
+
+func TestStream(t *testing.T) {
+	objs := []runtime.Object{
+		pod("provider-aws-1", map[string]string{labelProvider: "provider-aws", "pkg.crossplane.io/revision": "a"}, "package-runtime"),
+		pod("provider-aws-2", map[string]string{labelProvider: "provider-aws", "pkg.crossplane.io/revision": "b"}, "package-runtime", "sidecar"),
+		pod("function-go-1", map[string]string{labelFunction: "function-go"}, "function"),
+	}
+
+	type want struct {
+		lines []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		target Target
+		opts   Options
+		want   want
+	}{
+		"Provider": {
+			reason: "Logs of every container of every provider pod should be merged.",
+			target: Target{Kind: KindProvider, Name: "provider-aws"},
+			want: want{
+				lines: []string{
+					"[provider-aws-1/package-runtime] fake logs",
+					"[provider-aws-2/package-runtime] fake logs",
+					"[provider-aws-2/sidecar] fake logs",
+				},
+			},
+		},
+		"FunctionContainer": {
+			reason: "Streams should be restricted to the requested container.",
+			target: Target{Kind: KindFunction, Name: "function-go"},
+			opts:   Options{Container: "function"},
+			want: want{
+				lines: []string{"[function-go-1/function] fake logs"},
+			},
+		},
+		"Labels": {
+			reason: "Additional labels should restrict the pods streamed.",
+			target: Target{Kind: KindProvider, Name: "provider-aws", Labels: map[string]string{"pkg.crossplane.io/revision": "a"}},
+			want: want{
+				lines: []string{"[provider-aws-1/package-runtime] fake logs"},
+			},
+		},
+		"NoPods": {
+			reason: "Targets without pods should return an error.",
+			target: Target{Kind: KindFunction, Name: "function-missing"},
+			want: want{
+				err: errors.Errorf(errFmtNoPods, KindFunction, "function-missing"),
+			},
+		},
+		"UnknownKind": {
+			reason: "Unknown package kinds should return an error.",
+			target: Target{Kind: "configuration", Name: "c"},
+			want: want{
+				err: errors.Errorf(errFmtUnknownKind, "configuration"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var lines []string
+			err := New(kfake.NewSimpleClientset(objs...)).Stream(context.Background(), tc.target, tc.opts, func(l Line) {
+				lines = append(lines, l.String())
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nStream(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			sort.Strings(lines)
+			if diff := cmp.Diff(tc.want.lines, lines); diff != "" {
+				t.Errorf("\n%s\nStream(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
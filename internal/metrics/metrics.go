@@ -0,0 +1,234 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes Prometheus metrics for long-running operations such
+// as usage collection.
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	namespace = "up"
+
+	errListen      = "cannot listen for metrics requests"
+	errGather      = "cannot gather metrics"
+	errWriteMetric = "cannot write metrics"
+
+	shutdownTimeout = 5 * time.Second
+)
+
+// A Registry holds the metrics recorded by an operation. All methods are safe
+// to call on a nil *Registry, in which case nothing is recorded, so callers
+// need not check whether metrics were requested.
+type Registry struct {
+	reg *prometheus.Registry
+
+	windows  *prometheus.CounterVec
+	events   *prometheus.CounterVec
+	bytes    *prometheus.CounterVec
+	retries  *prometheus.CounterVec
+	requests *prometheus.HistogramVec
+}
+
+// New returns a Registry with all metrics registered.
+func New() *Registry {
+	r := &Registry{
+		reg: prometheus.NewRegistry(),
+		windows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "windows_processed_total",
+			Help:      "Number of time windows processed.",
+		}, []string{"source"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_read_total",
+			Help:      "Number of events read.",
+		}, []string{"source"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downloaded_bytes_total",
+			Help:      "Number of bytes downloaded.",
+		}, []string{"source"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Number of retried operations.",
+		}, []string{"operation"}),
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "Latency of API requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"source", "method", "code"}),
+	}
+	r.reg.MustRegister(r.windows, r.events, r.bytes, r.retries, r.requests)
+	return r
+}
+
+// WindowProcessed records that a time window was processed.
+func (r *Registry) WindowProcessed(source string) {
+	if r == nil {
+		return
+	}
+	r.windows.WithLabelValues(source).Inc()
+}
+
+// EventsRead records that n events were read.
+func (r *Registry) EventsRead(source string, n int) {
+	if r == nil {
+		return
+	}
+	r.events.WithLabelValues(source).Add(float64(n))
+}
+
+// BytesDownloaded records that n bytes were downloaded.
+func (r *Registry) BytesDownloaded(source string, n int64) {
+	if r == nil {
+		return
+	}
+	r.bytes.WithLabelValues(source).Add(float64(n))
+}
+
+// Retry records that an operation was retried.
+func (r *Registry) Retry(operation string) {
+	if r == nil {
+		return
+	}
+	r.retries.WithLabelValues(operation).Inc()
+}
+
+// OnRetry returns a function that records a retry of the supplied operation
+// each time it is called, e.g. by a transport configured WithOnRetry.
+func (r *Registry) OnRetry(operation string) func(*http.Request, int) {
+	return func(*http.Request, int) {
+		r.Retry(operation)
+	}
+}
+
+// ObserveRequest records the latency of an API request. A code of zero
+// indicates the request failed without a response.
+func (r *Registry) ObserveRequest(source, method string, code int, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.requests.WithLabelValues(source, method, strconv.Itoa(code)).Observe(d.Seconds())
+}
+
+// Handler returns an http.Handler that serves the registry's metrics.
+func (r *Registry) Handler() http.Handler {
+	if r == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{})
+}
+
+// Serve serves metrics at /metrics on the supplied address until the context
+// is done.
+func (r *Registry) Serve(ctx context.Context, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrap(err, errListen)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: shutdownTimeout}
+
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(sctx)
+	}()
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Dump writes the registry's metrics to w in the Prometheus text format, e.g.
+// so that they can be inspected after a command exits.
+func (r *Registry) Dump(w io.Writer) error {
+	if r == nil {
+		return nil
+	}
+	mfs, err := r.reg.Gather()
+	if err != nil {
+		return errors.Wrap(err, errGather)
+	}
+	for _, mf := range mfs {
+		if _, err := expfmt.MetricFamilyToText(w, mf); err != nil {
+			return errors.Wrap(err, errWriteMetric)
+		}
+	}
+	return nil
+}
+
+// Transport returns an http.RoundTripper that records the latency and
+// downloaded bytes of requests sent with the supplied RoundTripper. The
+// supplied RoundTripper is returned unchanged for a nil *Registry.
+func (r *Registry) Transport(rt http.RoundTripper, source string) http.RoundTripper {
+	if r == nil {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{base: rt, reg: r, source: source}
+}
+
+type transport struct {
+	base   http.RoundTripper
+	reg    *Registry
+	source string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	code := 0
+	if err == nil {
+		code = resp.StatusCode
+		resp.Body = &countingReader{ReadCloser: resp.Body, fn: func(n int) {
+			t.reg.BytesDownloaded(t.source, int64(n))
+		}}
+	}
+	t.reg.ObserveRequest(t.source, req.Method, code, time.Since(start))
+	return resp, err
+}
+
+type countingReader struct {
+	io.ReadCloser
+	fn func(n int)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		c.fn(n)
+	}
+	return n, err
+}
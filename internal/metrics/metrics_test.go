@@ -0,0 +1,93 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	uphttp "github.com/upbound/up/internal/http"
+)
+
+func TestRegistry(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first request so that it is retried.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer srv.Close()
+
+	r := New()
+	r.WindowProcessed("aws")
+	r.WindowProcessed("aws")
+	r.EventsRead("aws", 5)
+	r.Retry("http")
+	r.ObserveRequest("aws", http.MethodGet, http.StatusOK, time.Second)
+
+	h := uphttp.NewClient(
+		uphttp.WithBaseTransport(r.Transport(nil, "test")),
+		uphttp.WithOnRetry(r.OnRetry("test")),
+		uphttp.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	resp, err := h.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	buf := &bytes.Buffer{}
+	if err := r.Dump(buf); err != nil {
+		t.Fatalf("Dump(...): %v", err)
+	}
+	for _, want := range []string{
+		`up_windows_processed_total{source="aws"} 2`,
+		`up_events_read_total{source="aws"} 5`,
+		`up_retries_total{operation="http"} 1`,
+		`up_retries_total{operation="test"} 1`,
+		`up_downloaded_bytes_total{source="test"} 10`,
+		`up_api_request_duration_seconds_count{code="200",method="GET",source="test"} 1`,
+		`up_api_request_duration_seconds_count{code="503",method="GET",source="test"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Dump(...): missing %q in:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	r.WindowProcessed("aws")
+	r.EventsRead("aws", 1)
+	r.BytesDownloaded("aws", 1)
+	r.Retry("http")
+	r.ObserveRequest("aws", http.MethodGet, http.StatusOK, time.Second)
+	if err := r.Dump(io.Discard); err != nil {
+		t.Errorf("Dump(...): %v", err)
+	}
+	if rt := r.Transport(http.DefaultTransport, "aws"); rt != http.DefaultTransport {
+		t.Errorf("Transport(...): want supplied RoundTripper unchanged")
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package org manages Upbound organizations, their members and their teams.
+package org
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up-sdk-go"
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+	"github.com/upbound/up-sdk-go/service/organizations"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	orgsPath    = "v1/organizations"
+	membersPath = "members"
+
+	errFmtOrgNotFound    = "no organization %q"
+	errFmtMemberNotFound = "no member %q in organization %q"
+)
+
+// Role is the role of a member in an organization.
+type Role string
+
+const (
+	// RoleMember grants basic permissions on an organization.
+	RoleMember Role = Role(organizations.OrganizationMember)
+	// RoleOwner grants full permissions on an organization.
+	RoleOwner Role = Role(organizations.OrganizationOwner)
+)
+
+// Organization is a normalized organization.
+type Organization struct {
+	ID          uint
+	Name        string
+	DisplayName string
+	Role        Role
+}
+
+// Member is a normalized organization member.
+type Member struct {
+	ID       uint
+	Username string
+	Name     string
+	Email    string
+	Role     Role
+}
+
+// Invite is a pending invitation to join an organization.
+type Invite struct {
+	ID    uint
+	Email string
+	Role  Role
+}
+
+type orgClient interface {
+	Get(ctx context.Context, id uint) (*organizations.Organization, error)
+	List(ctx context.Context) ([]organizations.Organization, error)
+	ListMembers(ctx context.Context, orgID uint) ([]organizations.Member, error)
+	RemoveMember(ctx context.Context, orgID uint, userID uint) error
+	ListInvites(ctx context.Context, orgID uint) ([]organizations.Invite, error)
+	CreateInvite(ctx context.Context, orgID uint, params *organizations.OrganizationInviteCreateParameters) error
+	DeleteInvite(ctx context.Context, orgID uint, inviteID uint) error
+}
+
+type roleUpdate struct {
+	Permission Role `json:"permission"`
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithID sets the ID of the organization, avoiding a lookup by name.
+func WithID(id uint) Option {
+	return func(c *Client) {
+		c.id = id
+	}
+}
+
+// Client is the client used for managing an organization on Upbound.
+type Client struct {
+	orgs orgClient
+	api  up.Client
+
+	// Upbound organization name.
+	name string
+	// Upbound organization ID, looked up by name if not set.
+	id uint
+}
+
+// New instantiates a new Client for the named organization.
+func New(orgs orgClient, api up.Client, name string, opts ...Option) *Client {
+	c := &Client{
+		orgs: orgs,
+		api:  api,
+		name: name,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// NewFromConfig instantiates a new Client for the named organization from an
+// Upbound SDK config.
+func NewFromConfig(cfg *up.Config, name string, opts ...Option) *Client {
+	return New(organizations.NewClient(cfg), cfg.Client, name, opts...)
+}
+
+// List all organizations the user is a member of.
+func (c *Client) List(ctx context.Context) ([]*Organization, error) {
+	l, err := c.orgs.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resps := make([]*Organization, len(l))
+	for i := range l {
+		resps[i] = convertOrg(&l[i])
+	}
+	return resps, nil
+}
+
+// Get the organization.
+func (c *Client) Get(ctx context.Context) (*Organization, error) {
+	id, err := c.orgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.orgs.Get(ctx, id)
+	if sdkerrs.IsNotFound(err) {
+		return nil, failure.NotFound(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convertOrg(o), nil
+}
+
+// ListMembers lists the members of the organization.
+func (c *Client) ListMembers(ctx context.Context) ([]*Member, error) {
+	id, err := c.orgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l, err := c.orgs.ListMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resps := make([]*Member, len(l))
+	for i, m := range l {
+		resps[i] = &Member{
+			ID:       m.User.ID,
+			Username: m.User.Username,
+			Name:     m.User.Name,
+			Email:    m.User.Email,
+			Role:     Role(m.Permission),
+		}
+	}
+	return resps, nil
+}
+
+// GetMember gets the member of the organization with the supplied username.
+func (c *Client) GetMember(ctx context.Context, username string) (*Member, error) {
+	l, err := c.ListMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range l {
+		if m.Username == username {
+			return m, nil
+		}
+	}
+	return nil, failure.NotFound(errors.Errorf(errFmtMemberNotFound, username, c.name))
+}
+
+// Invite the supplied email address to join the organization with the
+// supplied role.
+func (c *Client) Invite(ctx context.Context, email string, role Role) error {
+	id, err := c.orgID(ctx)
+	if err != nil {
+		return err
+	}
+	return c.orgs.CreateInvite(ctx, id, &organizations.OrganizationInviteCreateParameters{
+		Email:      email,
+		Permission: organizations.OrganizationPermissionGroup(role),
+	})
+}
+
+// ListInvites lists pending invitations to join the organization.
+func (c *Client) ListInvites(ctx context.Context) ([]*Invite, error) {
+	id, err := c.orgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l, err := c.orgs.ListInvites(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resps := make([]*Invite, len(l))
+	for i, inv := range l {
+		resps[i] = &Invite{
+			ID:    inv.ID,
+			Email: inv.Email,
+			Role:  Role(inv.Permission),
+		}
+	}
+	return resps, nil
+}
+
+// CancelInvite cancels a pending invitation to join the organization.
+func (c *Client) CancelInvite(ctx context.Context, inviteID uint) error {
+	id, err := c.orgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.orgs.DeleteInvite(ctx, id, inviteID)
+	if sdkerrs.IsNotFound(err) {
+		return failure.NotFound(err)
+	}
+	return err
+}
+
+// SetRole changes the role of the member with the supplied username.
+func (c *Client) SetRole(ctx context.Context, username string, role Role) error {
+	m, err := c.GetMember(ctx, username)
+	if err != nil {
+		return err
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodPut, orgsPath, path.Join(strconv.FormatUint(uint64(c.id), 10), membersPath, strconv.FormatUint(uint64(m.ID), 10)), &roleUpdate{Permission: role})
+	if err != nil {
+		return err
+	}
+	return c.do(req)
+}
+
+// RemoveMember removes the member with the supplied username from the
+// organization.
+func (c *Client) RemoveMember(ctx context.Context, username string) error {
+	m, err := c.GetMember(ctx, username)
+	if err != nil {
+		return err
+	}
+	err = c.orgs.RemoveMember(ctx, c.id, m.ID)
+	if sdkerrs.IsNotFound(err) {
+		return failure.NotFound(err)
+	}
+	return err
+}
+
+// orgID returns the ID of the organization, looking it up by name the first
+// time it is needed.
+func (c *Client) orgID(ctx context.Context) (uint, error) {
+	if c.id != 0 {
+		return c.id, nil
+	}
+	l, err := c.orgs.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, o := range l {
+		if o.Name == c.name {
+			c.id = o.ID
+			return o.ID, nil
+		}
+	}
+	return 0, failure.NotFound(errors.Errorf(errFmtOrgNotFound, c.name))
+}
+
+func convertOrg(o *organizations.Organization) *Organization {
+	return &Organization{
+		ID:          o.ID,
+		Name:        o.Name,
+		DisplayName: o.DisplayName,
+		Role:        Role(o.Role),
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package org
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/upbound/up-sdk-go/service/organizations"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+var (
+	errBoom = errors.New("boom")
+	teamID  = uuid.MustParse("5d7b4a38-7d3c-4d2a-9b0e-6b0f3b1a2c4d")
+)
+
+type mockOrgs struct {
+	listErr error
+	removed []uint
+}
+
+func (m *mockOrgs) Get(_ context.Context, id uint) (*organizations.Organization, error) {
+	return &organizations.Organization{ID: id, Name: "acme"}, nil
+}
+
+func (m *mockOrgs) List(_ context.Context) ([]organizations.Organization, error) {
+	return []organizations.Organization{{ID: 7, Name: "acme", Role: organizations.OrganizationOwner}}, m.listErr
+}
+
+func (m *mockOrgs) ListMembers(_ context.Context, _ uint) ([]organizations.Member, error) {
+	return []organizations.Member{
+		{Permission: organizations.OrganizationOwner, User: organizations.User{ID: 1, Username: "alice"}},
+		{Permission: organizations.OrganizationMember, User: organizations.User{ID: 2, Username: "bob"}},
+	}, nil
+}
+
+func (m *mockOrgs) RemoveMember(_ context.Context, _ uint, userID uint) error {
+	m.removed = append(m.removed, userID)
+	return nil
+}
+
+func (m *mockOrgs) ListInvites(_ context.Context, _ uint) ([]organizations.Invite, error) {
+	return nil, nil
+}
+
+func (m *mockOrgs) CreateInvite(_ context.Context, _ uint, _ *organizations.OrganizationInviteCreateParameters) error {
+	return nil
+}
+
+func (m *mockOrgs) DeleteInvite(_ context.Context, _ uint, _ uint) error {
+	return nil
+}
+
+// request is a request sent through mockAPI.
+type request struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+type mockAPI struct {
+	reqs []request
+	resp string
+}
+
+func (m *mockAPI) NewRequest(ctx context.Context, method, prefix, urlPath string, body interface{}) (*http.Request, error) {
+	r := request{Method: method, Path: prefix + "/" + urlPath}
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = string(b)
+	}
+	m.reqs = append(m.reqs, r)
+	return http.NewRequestWithContext(ctx, method, "https://api.upbound.io/"+r.Path, nil)
+}
+
+func (m *mockAPI) Do(_ *http.Request, obj interface{}) error {
+	if obj == nil || m.resp == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(m.resp), obj)
+}
+
+func TestMembers(t *testing.T) {
+	type want struct {
+		err     error
+		reqs    []request
+		removed []uint
+	}
+	cases := map[string]struct {
+		reason string
+		orgs   *mockOrgs
+		name   string
+		fn     func(c *Client) error
+		want   want
+	}{
+		"SetRole": {
+			reason: "Changing the role of a member should update the member by user ID.",
+			orgs:   &mockOrgs{},
+			name:   "acme",
+			fn: func(c *Client) error {
+				return c.SetRole(context.Background(), "bob", RoleOwner)
+			},
+			want: want{
+				reqs: []request{{Method: http.MethodPut, Path: "v1/organizations/7/members/2", Body: `{"permission":"owner"}`}},
+			},
+		},
+		"RemoveMember": {
+			reason: "Removing a member should remove them by user ID.",
+			orgs:   &mockOrgs{},
+			name:   "acme",
+			fn: func(c *Client) error {
+				return c.RemoveMember(context.Background(), "alice")
+			},
+			want: want{
+				removed: []uint{1},
+			},
+		},
+		"MemberNotFound": {
+			reason: "An unknown username should result in a not found error.",
+			orgs:   &mockOrgs{},
+			name:   "acme",
+			fn: func(c *Client) error {
+				return c.RemoveMember(context.Background(), "carol")
+			},
+			want: want{
+				err: failure.NotFound(errors.Errorf(errFmtMemberNotFound, "carol", "acme")),
+			},
+		},
+		"OrgNotFound": {
+			reason: "An unknown organization should result in a not found error.",
+			orgs:   &mockOrgs{},
+			name:   "initech",
+			fn: func(c *Client) error {
+				_, err := c.ListMembers(context.Background())
+				return err
+			},
+			want: want{
+				err: failure.NotFound(errors.Errorf(errFmtOrgNotFound, "initech")),
+			},
+		},
+		"ErrListOrgs": {
+			reason: "An error listing organizations should not be reported as not found.",
+			orgs:   &mockOrgs{listErr: errBoom},
+			name:   "acme",
+			fn: func(c *Client) error {
+				_, err := c.ListMembers(context.Background())
+				return err
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"AddTeamMember": {
+			reason: "Adding a member to a team should add them by user ID.",
+			orgs:   &mockOrgs{},
+			name:   "acme",
+			fn: func(c *Client) error {
+				return c.AddTeamMember(context.Background(), teamID, "bob")
+			},
+			want: want{
+				reqs: []request{{Method: http.MethodPost, Path: "v1/teams/" + teamID.String() + "/members", Body: `{"userId":2}`}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			api := &mockAPI{}
+			err := tc.fn(New(tc.orgs, api, tc.name))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\n-want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reqs, api.reqs); diff != "" {
+				t.Errorf("\n%s\n-want requests, +got requests:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.removed, tc.orgs.removed); diff != "" {
+				t.Errorf("\n%s\n-want removed, +got removed:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateTeam(t *testing.T) {
+	api := &mockAPI{resp: `{"data": {"type": "teams", "id": "` + teamID.String() + `", "attributes": {"name": "platform"}}}`}
+	got, err := New(&mockOrgs{}, api, "acme", WithID(7)).CreateTeam(context.Background(), "platform")
+	if err != nil {
+		t.Fatalf("CreateTeam(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&Team{ID: teamID, Name: "platform"}, got); diff != "" {
+		t.Errorf("CreateTeam(...): -want, +got:\n%s", diff)
+	}
+	want := []request{{Method: http.MethodPost, Path: "v1/organizations/7/teams", Body: `{"data":{"type":"teams","attributes":{"name":"platform"}}}`}}
+	if diff := cmp.Diff(want, api.reqs); diff != "" {
+		t.Errorf("CreateTeam(...): -want requests, +got requests:\n%s", diff)
+	}
+}
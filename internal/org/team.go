@@ -0,0 +1,158 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package org
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	teamsPath = "v1/teams"
+	teamBody  = "teams"
+)
+
+// Team is a group of organization members.
+type Team struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type teamAttributes struct {
+	Name string `json:"name"`
+}
+
+type teamData struct {
+	ID         uuid.UUID      `json:"id"`
+	Attributes teamAttributes `json:"attributes"`
+}
+
+type teamCreateData struct {
+	Type       string         `json:"type"`
+	Attributes teamAttributes `json:"attributes"`
+}
+
+type teamRequest struct {
+	Data teamCreateData `json:"data"`
+}
+
+type teamResponse struct {
+	Data teamData `json:"data"`
+}
+
+type teamsResponse struct {
+	Data []teamData `json:"data"`
+}
+
+type teamMember struct {
+	UserID uint `json:"userId"`
+}
+
+// ListTeams lists the teams of the organization.
+func (c *Client) ListTeams(ctx context.Context) ([]*Team, error) {
+	id, err := c.orgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodGet, orgsPath, path.Join(strconv.FormatUint(uint64(id), 10), teamBody), nil)
+	if err != nil {
+		return nil, err
+	}
+	res := &teamsResponse{}
+	if err := c.api.Do(req, res); err != nil {
+		return nil, err
+	}
+	teams := make([]*Team, len(res.Data))
+	for i, t := range res.Data {
+		teams[i] = &Team{ID: t.ID, Name: t.Attributes.Name}
+	}
+	return teams, nil
+}
+
+// CreateTeam creates a team in the organization.
+func (c *Client) CreateTeam(ctx context.Context, name string) (*Team, error) {
+	id, err := c.orgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodPost, orgsPath, path.Join(strconv.FormatUint(uint64(id), 10), teamBody), &teamRequest{
+		Data: teamCreateData{
+			Type:       teamBody,
+			Attributes: teamAttributes{Name: name},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &teamResponse{}
+	if err := c.api.Do(req, res); err != nil {
+		return nil, err
+	}
+	return &Team{ID: res.Data.ID, Name: res.Data.Attributes.Name}, nil
+}
+
+// DeleteTeam deletes a team from the organization.
+func (c *Client) DeleteTeam(ctx context.Context, teamID uuid.UUID) error {
+	req, err := c.api.NewRequest(ctx, http.MethodDelete, teamsPath, teamID.String(), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req)
+}
+
+// AddTeamMember adds the organization member with the supplied username to
+// a team.
+func (c *Client) AddTeamMember(ctx context.Context, teamID uuid.UUID, username string) error {
+	m, err := c.GetMember(ctx, username)
+	if err != nil {
+		return err
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodPost, teamsPath, path.Join(teamID.String(), membersPath), &teamMember{UserID: m.ID})
+	if err != nil {
+		return err
+	}
+	return c.do(req)
+}
+
+// RemoveTeamMember removes the organization member with the supplied
+// username from a team.
+func (c *Client) RemoveTeamMember(ctx context.Context, teamID uuid.UUID, username string) error {
+	m, err := c.GetMember(ctx, username)
+	if err != nil {
+		return err
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodDelete, teamsPath, path.Join(teamID.String(), membersPath, strconv.FormatUint(uint64(m.ID), 10)), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req)
+}
+
+// do sends the supplied request, translating not found errors.
+func (c *Client) do(req *http.Request) error {
+	err := c.api.Do(req, nil)
+	if sdkerrs.IsNotFound(err) {
+		return failure.NotFound(err)
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"fmt"
+
+	"github.com/upbound/up/internal/controlplane"
+)
+
+// CloudControlPlaneColumns are the columns of control planes in Upbound.
+var CloudControlPlaneColumns = []Column{
+	{Name: "NAME", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Name })},
+	{Name: "ID", Value: controlPlaneField(func(r *controlplane.Response) string { return r.ID })},
+	{Name: "STATUS", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Status })},
+	{Name: "CONFIGURATION", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Cfg })},
+	{Name: "CONFIGURATION STATUS", Value: controlPlaneField(func(r *controlplane.Response) string { return r.CfgStatus })},
+	{Name: "CONFIGURATION VERSION", Wide: true, Value: controlPlaneField(cfgVersion)},
+}
+
+// SpaceControlPlaneColumns are the columns of control planes in a Space.
+var SpaceControlPlaneColumns = []Column{
+	{Name: "NAME", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Name })},
+	{Name: "ID", Value: controlPlaneField(func(r *controlplane.Response) string { return r.ID })},
+	{Name: "STATUS", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Status })},
+	{Name: "MESSAGE", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Message })},
+	{Name: "CLASS", Wide: true, Value: controlPlaneField(func(r *controlplane.Response) string { return r.Class })},
+	{Name: "CONNECTION NAME", Value: controlPlaneField(func(r *controlplane.Response) string { return r.ConnName })},
+	{Name: "CONNECTION NAMESPACE", Value: controlPlaneField(func(r *controlplane.Response) string { return r.ConnNamespace })},
+	{Name: "HEALTH", Wide: true, Value: controlPlaneField(healthLevel)},
+}
+
+func healthLevel(r *controlplane.Response) string {
+	if r.Health == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%d)", r.Health.Level, r.Health.Score)
+}
+
+// cfgVersion describes the configuration version of a control plane, noting
+// pending upgrades and newer versions that are available.
+func cfgVersion(r *controlplane.Response) string {
+	v, target := r.CfgCurrentVersion, r.CfgCurrentVersion
+	if r.CfgDesiredVersion != "" && r.CfgDesiredVersion != r.CfgCurrentVersion {
+		v, target = fmt.Sprintf("%s -> %s", v, r.CfgDesiredVersion), r.CfgDesiredVersion
+	}
+	if r.CfgLatestVersion != "" && r.CfgLatestVersion != target {
+		v = fmt.Sprintf("%s (latest %s)", v, r.CfgLatestVersion)
+	}
+	return v
+}
+
+func controlPlaneField(fn func(r *controlplane.Response) string) func(obj any) string {
+	return func(obj any) string {
+		r, ok := obj.(*controlplane.Response)
+		if !ok || r == nil {
+			return ""
+		}
+		return fn(r)
+	}
+}
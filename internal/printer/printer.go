@@ -0,0 +1,268 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package printer renders objects and lists of objects for get and list
+// commands, either as human-readable tables or as JSON or YAML.
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/upbound/up/internal/config"
+)
+
+const (
+	errFmtUnknownColumn = "unknown column %q, must be one of: %s"
+	errFmtUnknownFormat = "unknown format %q"
+)
+
+// A Column of a table.
+type Column struct {
+	// Name of the column, used as its header and to select it.
+	Name string
+	// Wide columns are only shown in wide format, unless selected.
+	Wide bool
+	// Value returns the value of the column for the supplied object.
+	Value func(obj any) string
+}
+
+// An Option modifies a Printer.
+type Option func(*Printer)
+
+// WithWriter sets where the Printer writes. The default is os.Stdout.
+func WithWriter(w io.Writer) Option {
+	return func(p *Printer) {
+		p.out = w
+	}
+}
+
+// WithFormat sets the format the Printer renders objects in. An empty format
+// is ignored.
+func WithFormat(f config.Format) Option {
+	return func(p *Printer) {
+		if f == "" {
+			return
+		}
+		p.format = f
+		p.formatSet = true
+	}
+}
+
+// WithColumns selects the columns, and their order, of tables. All columns,
+// including wide columns, may be selected.
+func WithColumns(names ...string) Option {
+	return func(p *Printer) {
+		p.columns = names
+	}
+}
+
+// WithSortBy sorts lists by the value of the named column.
+func WithSortBy(name string) Option {
+	return func(p *Printer) {
+		p.sortBy = name
+	}
+}
+
+// WithNoHeaders omits the header row of tables.
+func WithNoHeaders() Option {
+	return func(p *Printer) {
+		p.noHeaders = true
+	}
+}
+
+// A Printer renders objects and lists of objects.
+type Printer struct {
+	out       io.Writer
+	format    config.Format
+	formatSet bool
+	columns   []string
+	sortBy    string
+	noHeaders bool
+}
+
+// New returns a Printer that renders tables by default.
+func New(opts ...Option) *Printer {
+	p := &Printer{
+		out:    os.Stdout,
+		format: config.Default,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// DefaultFormat sets the format the Printer renders objects in, unless one
+// was explicitly supplied using WithFormat. It is used to honor the default
+// format of the current profile.
+func (p *Printer) DefaultFormat(f config.Format) {
+	if p.formatSet || f == "" {
+		return
+	}
+	p.format = f
+}
+
+// Structured returns true if the Printer renders JSON, YAML, or templates,
+// rather than tables intended for humans.
+func (p *Printer) Structured() bool {
+	return p.format == config.JSON || p.format == config.YAML || IsTemplate(p.format)
+}
+
+// Print renders the supplied object, or array or slice of objects. Tables
+// contain the supplied columns, while JSON and YAML contain every field of
+// the objects. JSONPath and Go template formats extract values from the
+// objects as they would be rendered in JSON.
+func (p *Printer) Print(obj any, cols []Column) error {
+	if p.sortBy != "" && isList(obj) {
+		col, err := find(cols, p.sortBy)
+		if err != nil {
+			return err
+		}
+		obj = sorted(obj, col)
+	}
+
+	switch {
+	case IsTemplate(p.format):
+		return PrintTemplate(p.out, p.format, obj)
+	case p.format == config.JSON:
+		b, err := json.MarshalIndent(obj, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.out, string(b))
+		return err
+	case p.format == config.YAML:
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(p.out, string(b))
+		return err
+	case p.format == config.Default, p.format == config.Wide, p.format == "":
+		return p.printTable(obj, cols)
+	default:
+		return errors.Errorf(errFmtUnknownFormat, p.format)
+	}
+}
+
+func (p *Printer) printTable(obj any, cols []Column) error {
+	cols, err := p.selected(cols)
+	if err != nil {
+		return err
+	}
+
+	objs := []any{obj}
+	if isList(obj) {
+		v := reflect.ValueOf(obj)
+		objs = make([]any, v.Len())
+		for i := range objs {
+			objs[i] = v.Index(i).Interface()
+		}
+	}
+
+	data := make([][]string, 0, len(objs)+1)
+	if !p.noHeaders {
+		header := make([]string, len(cols))
+		for i, c := range cols {
+			header[i] = c.Name
+		}
+		data = append(data, header)
+	}
+	for _, o := range objs {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = c.Value(o)
+		}
+		data = append(data, row)
+	}
+
+	// NOTE: tables are written with a tabwriter rather than pterm so that
+	// output is never styled and can be reliably processed by other tools.
+	w := tabwriter.NewWriter(p.out, 0, 0, 3, ' ', 0)
+	for _, row := range data {
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// selected returns the columns to render.
+func (p *Printer) selected(cols []Column) ([]Column, error) {
+	if len(p.columns) > 0 {
+		out := make([]Column, len(p.columns))
+		for i, name := range p.columns {
+			c, err := find(cols, name)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = c
+		}
+		return out, nil
+	}
+	out := make([]Column, 0, len(cols))
+	for _, c := range cols {
+		if c.Wide && p.format != config.Wide {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// find the column with the supplied name. Names are matched ignoring case,
+// and spaces, hyphens, and underscores are treated alike.
+func find(cols []Column, name string) (Column, error) {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		if normalize(c.Name) == normalize(name) {
+			return c, nil
+		}
+		names[i] = strings.ToLower(c.Name)
+	}
+	return Column{}, errors.Errorf(errFmtUnknownColumn, name, strings.Join(names, ", "))
+}
+
+func normalize(name string) string {
+	return strings.NewReplacer(" ", "-", "_", "-").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+func isList(obj any) bool {
+	if obj == nil {
+		return false
+	}
+	k := reflect.TypeOf(obj).Kind()
+	return k == reflect.Array || k == reflect.Slice
+}
+
+// sorted returns a copy of the supplied list, sorted by the supplied column.
+func sorted(obj any, col Column) any {
+	v := reflect.ValueOf(obj)
+	cp := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), v.Len(), v.Len())
+	reflect.Copy(cp, v)
+	sort.SliceStable(cp.Interface(), func(i, j int) bool {
+		return col.Value(cp.Index(i).Interface()) < col.Value(cp.Index(j).Interface())
+	})
+	return cp.Interface()
+}
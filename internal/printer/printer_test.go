@@ -0,0 +1,291 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/controlplane"
+	"github.com/upbound/up/internal/failure"
+)
+
+func TestPrint(t *testing.T) {
+	ctps := []*controlplane.Response{
+		{Name: "ctp2", ID: "2", Status: "ready", Cfg: "cfg", CfgStatus: "ready"},
+		{Name: "ctp1", ID: "1", Status: "provisioning", Cfg: "cfg", CfgStatus: "installing"},
+	}
+	cols := []Column{
+		{Name: "NAME", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Name })},
+		{Name: "STATUS", Value: controlPlaneField(func(r *controlplane.Response) string { return r.Status })},
+		{Name: "CONFIGURATION STATUS", Wide: true, Value: controlPlaneField(func(r *controlplane.Response) string { return r.CfgStatus })},
+	}
+
+	type args struct {
+		obj  any
+		opts []Option
+	}
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Table": {
+			reason: "A list should be rendered as a table of the columns that are not wide.",
+			args: args{
+				obj: ctps,
+			},
+			want: want{
+				out: "NAME   STATUS\nctp2   ready\nctp1   provisioning\n",
+			},
+		},
+		"Object": {
+			reason: "A single object should be rendered as a table with one row.",
+			args: args{
+				obj: ctps[0],
+			},
+			want: want{
+				out: "NAME   STATUS\nctp2   ready\n",
+			},
+		},
+		"Wide": {
+			reason: "Wide columns should be rendered in wide format.",
+			args: args{
+				obj:  ctps,
+				opts: []Option{WithFormat(config.Wide)},
+			},
+			want: want{
+				out: "NAME   STATUS         CONFIGURATION STATUS\nctp2   ready          ready\nctp1   provisioning   installing\n",
+			},
+		},
+		"SortedNoHeaders": {
+			reason: "A list should be sorted by the named column and rendered without headers.",
+			args: args{
+				obj:  ctps,
+				opts: []Option{WithSortBy("name"), WithNoHeaders()},
+			},
+			want: want{
+				out: "ctp1   provisioning\nctp2   ready\n",
+			},
+		},
+		"SelectedColumns": {
+			reason: "Selected columns, including wide columns, should be rendered in the order they were selected.",
+			args: args{
+				obj:  ctps,
+				opts: []Option{WithColumns("configuration-status", "Name")},
+			},
+			want: want{
+				out: "CONFIGURATION STATUS   NAME\nready                  ctp2\ninstalling             ctp1\n",
+			},
+		},
+		"UnknownColumn": {
+			reason: "Selecting an unknown column should return an error.",
+			args: args{
+				obj:  ctps,
+				opts: []Option{WithColumns("id")},
+			},
+			want: want{
+				err: errors.Errorf(errFmtUnknownColumn, "id", "name, status, configuration status"),
+			},
+		},
+		"JSON": {
+			reason: "JSON should contain every field, sorted if requested.",
+			args: args{
+				obj:  []*controlplane.Response{ctps[0]},
+				opts: []Option{WithFormat(config.JSON)},
+			},
+			want: want{
+				out: `[
+    {
+        "ID": "2",
+        "Name": "ctp2",
+        "Message": "",
+        "Status": "ready",
+        "Class": "",
+        "Cfg": "cfg",
+        "CfgStatus": "ready",
+        "ConnName": "",
+        "ConnNamespace": ""
+    }
+]
+`,
+			},
+		},
+		"YAML": {
+			reason: "YAML should contain every field of a list, sorted if requested.",
+			args: args{
+				obj:  ctps,
+				opts: []Option{WithFormat(config.YAML), WithSortBy("NAME")},
+			},
+			want: want{
+				out: `- id: "1"
+  name: ctp1
+  message: ""
+  status: provisioning
+  class: ""
+  cfg: cfg
+  cfgstatus: installing
+  connname: ""
+  connnamespace: ""
+- id: "2"
+  name: ctp2
+  message: ""
+  status: ready
+  class: ""
+  cfg: cfg
+  cfgstatus: ready
+  connname: ""
+  connnamespace: ""
+`,
+			},
+		},
+		"JSONPath": {
+			reason: "A JSONPath expression should extract values from an object by their JSON names.",
+			args: args{
+				obj:  ctps[0],
+				opts: []Option{WithFormat(config.Format(JSONPathPrefix + "{.Name}/{.Status}"))},
+			},
+			want: want{
+				out: "ctp2/ready",
+			},
+		},
+		"JSONPathBareList": {
+			reason: "A bare JSONPath expression should extract values from every object of a sorted list.",
+			args: args{
+				obj:  ctps,
+				opts: []Option{WithFormat(config.Format(JSONPathPrefix + "[*].Name")), WithSortBy("name")},
+			},
+			want: want{
+				out: "ctp1 ctp2",
+			},
+		},
+		"GoTemplate": {
+			reason: "A Go template should be rendered against every object of a list.",
+			args: args{
+				obj:  ctps,
+				opts: []Option{WithFormat(config.Format(GoTemplatePrefix + "{{range .}}{{.Name}} {{.CfgStatus}}\n{{end}}"))},
+			},
+			want: want{
+				out: "ctp2 ready\nctp1 installing\n",
+			},
+		},
+		"GoTemplateMissingKey": {
+			reason: "A Go template referring to an unknown field should return an error.",
+			args: args{
+				obj:  ctps[0],
+				opts: []Option{WithFormat(config.Format(GoTemplatePrefix + "{{.Nope}}"))},
+			},
+			want: want{
+				err: errors.Wrap(errors.New(`template: format:1:2: executing "format" at <.Nope>: map has no entry for key "Nope"`), errExecTemplate),
+			},
+		},
+		"JSONPathEmpty": {
+			reason: "An empty JSONPath expression should return an error.",
+			args: args{
+				obj:  ctps[0],
+				opts: []Option{WithFormat(config.Format(JSONPathPrefix))},
+			},
+			want: want{
+				err: errors.New(errEmptyTemplate),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			p := New(append([]Option{WithWriter(out)}, tc.args.opts...)...)
+			err := p.Print(tc.args.obj, cols)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPrint(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, out.String()); diff != "" {
+				t.Errorf("\n%s\nPrint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCfgVersion(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		r      *controlplane.Response
+		want   string
+	}{
+		"UpToDate": {
+			reason: "A control plane running the latest version should only show its version.",
+			r:      &controlplane.Response{CfgCurrentVersion: "v2", CfgDesiredVersion: "v2", CfgLatestVersion: "v2"},
+			want:   "v2",
+		},
+		"Upgrading": {
+			reason: "A control plane moving to another version should show both versions.",
+			r:      &controlplane.Response{CfgCurrentVersion: "v1", CfgDesiredVersion: "v2", CfgLatestVersion: "v2"},
+			want:   "v1 -> v2",
+		},
+		"Outdated": {
+			reason: "A control plane not running the latest version should show the latest version.",
+			r:      &controlplane.Response{CfgCurrentVersion: "v1", CfgDesiredVersion: "v1", CfgLatestVersion: "v3"},
+			want:   "v1 (latest v3)",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, cfgVersion(tc.r)); diff != "" {
+				t.Errorf("\n%s\ncfgVersion(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		f      config.Format
+		want   error
+	}{
+		"Empty": {
+			reason: "An empty format should be valid.",
+		},
+		"Wide": {
+			reason: "A known format should be valid.",
+			f:      config.Wide,
+		},
+		"GoTemplate": {
+			reason: "A Go template format should be valid.",
+			f:      config.Format(GoTemplatePrefix + "{{.Name}}"),
+		},
+		"Unknown": {
+			reason: "An unknown format should be invalid.",
+			f:      "xml",
+			want:   failure.Usage(errors.Errorf(errFmtUnknownFormat, "xml")),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateFormat(tc.f)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateFormat(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
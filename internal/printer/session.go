@@ -0,0 +1,56 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/upbound/up/internal/auth"
+)
+
+// SessionColumns are the columns of sessions and personal access tokens.
+var SessionColumns = []Column{
+	{Name: "ID", Value: sessionField(func(s auth.Session) string { return s.ID.String() })},
+	{Name: "KIND", Value: sessionField(func(s auth.Session) string { return string(s.Kind) })},
+	{Name: "NAME", Value: sessionField(func(s auth.Session) string { return s.Name })},
+	{Name: "AGE", Value: sessionField(func(s auth.Session) string { return age(s.CreatedAt) })},
+	{Name: "LAST USED", Value: sessionField(func(s auth.Session) string { return age(s.LastUsedAt) })},
+	{Name: "CURRENT", Value: sessionField(func(s auth.Session) string {
+		if s.Current {
+			return "*"
+		}
+		return ""
+	})},
+}
+
+// age returns how long ago the supplied time was, or n/a if it is unknown.
+func age(t *time.Time) string {
+	if t == nil {
+		return "n/a"
+	}
+	return duration.HumanDuration(time.Since(*t))
+}
+
+func sessionField(fn func(s auth.Session) string) func(obj any) string {
+	return func(obj any) string {
+		s, ok := obj.(auth.Session)
+		if !ok {
+			return ""
+		}
+		return fn(s)
+	}
+}
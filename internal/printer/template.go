@@ -0,0 +1,122 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	// JSONPathPrefix prefixes formats that extract values using a JSONPath
+	// expression, e.g. jsonpath={.ConnName}.
+	JSONPathPrefix = "jsonpath="
+	// GoTemplatePrefix prefixes formats that render a Go template, e.g.
+	// go-template={{.ConnName}}.
+	GoTemplatePrefix = "go-template="
+
+	errEmptyTemplate  = "template must not be empty"
+	errConvertObject  = "cannot convert object for template"
+	errParseJSONPath  = "cannot parse JSONPath expression"
+	errExecJSONPath   = "cannot execute JSONPath expression"
+	errParseTemplate  = "cannot parse Go template"
+	errExecTemplate   = "cannot execute Go template"
+	errFmtUnsupported = "unsupported template format %q"
+)
+
+// ValidateFormat returns an error if the supplied format is not one the
+// Printer can render. An empty format is valid.
+func ValidateFormat(f config.Format) error {
+	switch f {
+	case "", config.Default, config.Wide, config.JSON, config.YAML:
+		return nil
+	}
+	if IsTemplate(f) {
+		return nil
+	}
+	return failure.Usage(errors.Errorf(errFmtUnknownFormat, f))
+}
+
+// IsTemplate returns true if the supplied format extracts values from
+// objects using a JSONPath expression or Go template.
+func IsTemplate(f config.Format) bool {
+	return strings.HasPrefix(string(f), JSONPathPrefix) || strings.HasPrefix(string(f), GoTemplatePrefix)
+}
+
+// PrintTemplate renders the supplied object using the JSONPath expression or
+// Go template of the supplied format. The object is rendered as it would be
+// in JSON, so templates refer to fields by their JSON names. Like kubectl, no
+// trailing newline is written.
+func PrintTemplate(w io.Writer, f config.Format, obj any) error {
+	data, err := generic(obj)
+	if err != nil {
+		return err
+	}
+
+	switch s := string(f); {
+	case strings.HasPrefix(s, JSONPathPrefix):
+		return printJSONPath(w, strings.TrimPrefix(s, JSONPathPrefix), data)
+	case strings.HasPrefix(s, GoTemplatePrefix):
+		return printGoTemplate(w, strings.TrimPrefix(s, GoTemplatePrefix), data)
+	default:
+		return errors.Errorf(errFmtUnsupported, f)
+	}
+}
+
+func printJSONPath(w io.Writer, expr string, data any) error {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return errors.New(errEmptyTemplate)
+	}
+	// Accept bare expressions like .ConnName, as kubectl does.
+	if !strings.Contains(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	j := jsonpath.New("format")
+	if err := j.Parse(expr); err != nil {
+		return errors.Wrap(err, errParseJSONPath)
+	}
+	return errors.Wrap(j.Execute(w, data), errExecJSONPath)
+}
+
+func printGoTemplate(w io.Writer, text string, data any) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New(errEmptyTemplate)
+	}
+	t, err := template.New("format").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return errors.Wrap(err, errParseTemplate)
+	}
+	return errors.Wrap(t.Execute(w, data), errExecTemplate)
+}
+
+// generic converts the supplied object to the maps, slices, and scalars it
+// would be decoded to from JSON.
+func generic(obj any) (any, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, errConvertObject)
+	}
+	var data any
+	return data, errors.Wrap(json.Unmarshal(b, &data), errConvertObject)
+}
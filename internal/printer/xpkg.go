@@ -0,0 +1,136 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/lint"
+)
+
+// XRDColumns are the columns of CompositeResourceDefinitions in a package.
+var XRDColumns = []Column{
+	{Name: "NAME", Value: xrdField(func(x *xpv1.CompositeResourceDefinition) string { return x.GetName() })},
+	{Name: "KIND", Value: xrdField(func(x *xpv1.CompositeResourceDefinition) string { return x.Spec.Names.Kind })},
+	{Name: "CLAIM KIND", Value: xrdField(func(x *xpv1.CompositeResourceDefinition) string {
+		if x.Spec.ClaimNames == nil {
+			return ""
+		}
+		return x.Spec.ClaimNames.Kind
+	})},
+	{Name: "VERSIONS", Value: xrdField(func(x *xpv1.CompositeResourceDefinition) string {
+		vs := make([]string, len(x.Spec.Versions))
+		for i, v := range x.Spec.Versions {
+			vs[i] = v.Name
+		}
+		return strings.Join(vs, ",")
+	})},
+}
+
+// CompositionColumns are the columns of Compositions in a package.
+var CompositionColumns = []Column{
+	{Name: "NAME", Value: compositionField(func(c *xpv1.Composition) string { return c.GetName() })},
+	{Name: "XR KIND", Value: compositionField(func(c *xpv1.Composition) string { return c.Spec.CompositeTypeRef.Kind })},
+	{Name: "XR APIVERSION", Value: compositionField(func(c *xpv1.Composition) string { return c.Spec.CompositeTypeRef.APIVersion })},
+	{Name: "MODE", Wide: true, Value: compositionField(func(c *xpv1.Composition) string {
+		if c.Spec.Mode == nil {
+			return string(xpv1.CompositionModeResources)
+		}
+		return string(*c.Spec.Mode)
+	})},
+}
+
+// CRDColumns are the columns of CustomResourceDefinitions in a package.
+var CRDColumns = []Column{
+	{Name: "NAME", Value: crdField(func(c *extv1.CustomResourceDefinition) string { return c.GetName() })},
+	{Name: "KIND", Value: crdField(func(c *extv1.CustomResourceDefinition) string { return c.Spec.Names.Kind })},
+	{Name: "SCOPE", Value: crdField(func(c *extv1.CustomResourceDefinition) string { return string(c.Spec.Scope) })},
+	{Name: "VERSIONS", Value: crdField(func(c *extv1.CustomResourceDefinition) string {
+		vs := make([]string, len(c.Spec.Versions))
+		for i, v := range c.Spec.Versions {
+			vs[i] = v.Name
+		}
+		return strings.Join(vs, ",")
+	})},
+}
+
+// DependencyColumns are the columns of package dependencies.
+var DependencyColumns = []Column{
+	{Name: "PACKAGE", Value: dependencyField(func(d v1beta1.Dependency) string { return d.Package })},
+	{Name: "TYPE", Value: dependencyField(func(d v1beta1.Dependency) string { return string(d.Type) })},
+	{Name: "CONSTRAINTS", Value: dependencyField(func(d v1beta1.Dependency) string { return d.Constraints })},
+}
+
+// LintFindingColumns are the columns of package lint findings.
+var LintFindingColumns = []Column{
+	{Name: "SEVERITY", Value: findingField(func(f lint.Finding) string { return string(f.Severity) })},
+	{Name: "RULE", Value: findingField(func(f lint.Finding) string { return f.Rule })},
+	{Name: "OBJECT", Value: findingField(func(f lint.Finding) string { return f.Object })},
+	{Name: "MESSAGE", Value: findingField(func(f lint.Finding) string { return f.Message })},
+}
+
+func xrdField(fn func(*xpv1.CompositeResourceDefinition) string) func(obj any) string {
+	return func(obj any) string {
+		x, ok := obj.(*xpv1.CompositeResourceDefinition)
+		if !ok || x == nil {
+			return ""
+		}
+		return fn(x)
+	}
+}
+
+func compositionField(fn func(*xpv1.Composition) string) func(obj any) string {
+	return func(obj any) string {
+		c, ok := obj.(*xpv1.Composition)
+		if !ok || c == nil {
+			return ""
+		}
+		return fn(c)
+	}
+}
+
+func crdField(fn func(*extv1.CustomResourceDefinition) string) func(obj any) string {
+	return func(obj any) string {
+		c, ok := obj.(*extv1.CustomResourceDefinition)
+		if !ok || c == nil {
+			return ""
+		}
+		return fn(c)
+	}
+}
+
+func dependencyField(fn func(v1beta1.Dependency) string) func(obj any) string {
+	return func(obj any) string {
+		d, ok := obj.(v1beta1.Dependency)
+		if !ok {
+			return ""
+		}
+		return fn(d)
+	}
+}
+
+func findingField(fn func(lint.Finding) string) func(obj any) string {
+	return func(obj any) string {
+		f, ok := obj.(lint.Finding)
+		if !ok {
+			return ""
+		}
+		return fn(f)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errPersist = "cannot persist profiles"
+)
+
+// A Store holds named profiles, one of which is the default.
+type Store interface {
+	GetUpboundProfiles() (map[string]Profile, error)
+	GetUpboundProfile(name string) (Profile, error)
+	GetDefaultUpboundProfile() (string, Profile, error)
+	SetDefaultUpboundProfile(name string) error
+	AddOrUpdateUpboundProfile(name string, p Profile) error
+}
+
+// A Named profile.
+type Named struct {
+	Name    string
+	Profile Profile
+	Current bool
+}
+
+// A ManagerOption modifies a Manager.
+type ManagerOption func(*Manager)
+
+// WithPersist sets the function called to persist the Store after it is
+// changed. Changes are not persisted by default.
+func WithPersist(fn func() error) ManagerOption {
+	return func(m *Manager) {
+		m.persist = fn
+	}
+}
+
+// A Manager resolves, lists, and switches between named profiles.
+type Manager struct {
+	store   Store
+	persist func() error
+}
+
+// NewManager returns a Manager of the profiles in the supplied Store.
+func NewManager(s Store, opts ...ManagerOption) *Manager {
+	m := &Manager{
+		store:   s,
+		persist: func() error { return nil },
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	return m
+}
+
+// Current returns the name of the current profile and the profile.
+func (m *Manager) Current() (string, Profile, error) {
+	return m.store.GetDefaultUpboundProfile()
+}
+
+// Get returns the named profile.
+func (m *Manager) Get(name string) (Profile, error) {
+	return m.store.GetUpboundProfile(name)
+}
+
+// List returns all profiles, sorted by name.
+func (m *Manager) List() ([]Named, error) {
+	profiles, err := m.store.GetUpboundProfiles()
+	if err != nil {
+		return nil, err
+	}
+	current, _, _ := m.store.GetDefaultUpboundProfile()
+
+	out := make([]Named, 0, len(profiles))
+	for name, p := range profiles {
+		out = append(out, Named{Name: name, Profile: p, Current: name == current})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Switch makes the named profile current and persists the change.
+func (m *Manager) Switch(name string) error {
+	if err := m.store.SetDefaultUpboundProfile(name); err != nil {
+		return err
+	}
+	return errors.Wrap(m.persist(), errPersist)
+}
+
+// Put adds or updates the named profile and persists the change.
+func (m *Manager) Put(name string, p Profile) error {
+	if err := m.store.AddOrUpdateUpboundProfile(name, p); err != nil {
+		return err
+	}
+	return errors.Wrap(m.persist(), errPersist)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile_test
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/profile"
+)
+
+func newConfig() *config.Config {
+	return &config.Config{
+		Upbound: config.Upbound{
+			Default: "cloud",
+			Profiles: map[string]profile.Profile{
+				"cloud": {ID: "someone@upbound.io", Type: profile.User, Account: "demo"},
+				"space": {Type: profile.Space, Kubeconfig: "/kubeconfig", KubeContext: "kind-space", Format: "yaml"},
+			},
+		},
+	}
+}
+
+func TestManagerList(t *testing.T) {
+	m := profile.NewManager(newConfig())
+	got, err := m.List()
+	if err != nil {
+		t.Fatalf("List(): unexpected error: %v", err)
+	}
+	want := []profile.Named{
+		{Name: "cloud", Profile: profile.Profile{ID: "someone@upbound.io", Type: profile.User, Account: "demo"}, Current: true},
+		{Name: "space", Profile: profile.Profile{Type: profile.Space, Kubeconfig: "/kubeconfig", KubeContext: "kind-space", Format: "yaml"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestManagerSwitch(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		current string
+		err     error
+		saved   bool
+	}
+	cases := map[string]struct {
+		reason  string
+		name    string
+		persist error
+		want    want
+	}{
+		"Switched": {
+			reason: "Switching to an existing profile should make it current and persist the change.",
+			name:   "space",
+			want:   want{current: "space", saved: true},
+		},
+		"NotFound": {
+			reason: "Switching to a profile that does not exist should return an error without persisting.",
+			name:   "missing",
+			want:   want{current: "cloud", err: errors.New("profile not found with identifier: missing")},
+		},
+		"PersistError": {
+			reason:  "Errors persisting the change should be returned.",
+			name:    "space",
+			persist: errBoom,
+			want:    want{current: "space", err: errors.Wrap(errBoom, "cannot persist profiles"), saved: true},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			saved := false
+			m := profile.NewManager(newConfig(), profile.WithPersist(func() error {
+				saved = true
+				return tc.persist
+			}))
+			err := m.Switch(tc.name)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSwitch(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			current, _, _ := m.Current()
+			if diff := cmp.Diff(tc.want.current, current); diff != "" {
+				t.Errorf("\n%s\nCurrent(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.saved, saved); diff != "" {
+				t.Errorf("\n%s\nSwitch(...): -want persisted, +got persisted:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
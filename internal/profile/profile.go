@@ -59,6 +59,18 @@ type Profile struct {
 	// will read. If empty, it refers to the default context.
 	KubeContext string `json:"kube_context,omitempty"`
 
+	// APIEndpoint overrides the Upbound API endpoint derived from the
+	// domain when this profile is selected.
+	APIEndpoint string `json:"api_endpoint,omitempty"`
+
+	// ProxyEndpoint overrides the Upbound control plane proxy endpoint
+	// derived from the domain when this profile is selected.
+	ProxyEndpoint string `json:"proxy_endpoint,omitempty"`
+
+	// Format is the default output format of get and list commands when
+	// this profile is selected.
+	Format string `json:"format,omitempty"`
+
 	// BaseConfig represent persisted settings for this profile.
 	// For example:
 	// * flags
@@ -0,0 +1,215 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package query contains a client for the Spaces query API, which searches
+// for resources across the control planes in a Space.
+package query
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/capability"
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	// DefaultPageSize is the number of objects requested per query page.
+	DefaultPageSize = 500
+
+	errQuery          = "failed to query Space"
+	errFmtNamePattern = "invalid name pattern %q"
+)
+
+var (
+	spaceQueries = resources.SpaceQueryGVK.GroupVersion().WithResource("spacequeries")
+	groupQueries = resources.GroupQueryGVK.GroupVersion().WithResource("groupqueries")
+)
+
+// Filter selects the resources returned by a query. Empty fields match
+// everything.
+type Filter struct {
+	// Group restricts the query to control planes in a single Space group.
+	Group string
+	// ControlPlane restricts the query to a single control plane.
+	ControlPlane string
+	// Kind restricts results to a kind, optionally qualified by its API
+	// group, e.g. "Bucket" or "bucket.s3.aws.upbound.io".
+	Kind string
+	// Namespace restricts results to namespaced resources in a namespace.
+	Namespace string
+	// Name is either an exact name or a shell pattern as understood by
+	// path.Match, e.g. "prod-*".
+	Name string
+	// Labels restricts results to resources with all of these labels.
+	Labels map[string]string
+}
+
+// Result is a resource found by a query along with where it lives.
+type Result struct {
+	Group        string
+	ControlPlane string
+	Object       *unstructured.Unstructured
+}
+
+// Client queries resources across the control planes of a Space.
+type Client struct {
+	c        dynamic.Interface
+	pageSize int64
+	caps     *capability.Capabilities
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithPageSize sets the number of objects requested per page.
+func WithPageSize(n int64) Option {
+	return func(c *Client) {
+		c.pageSize = n
+	}
+}
+
+// WithCapabilities makes the Client fail early if the Space does not support
+// queries.
+func WithCapabilities(caps *capability.Capabilities) Option {
+	return func(c *Client) {
+		c.caps = caps
+	}
+}
+
+// New constructs a new Client.
+func New(c dynamic.Interface, opts ...Option) *Client {
+	cl := &Client{
+		c:        c,
+		pageSize: DefaultPageSize,
+	}
+	for _, o := range opts {
+		o(cl)
+	}
+	return cl
+}
+
+// Query returns every resource matching the supplied filter, following
+// pagination until the result set is exhausted. Warnings reported by the
+// Space, e.g. for unreachable control planes, are returned alongside the
+// results.
+func (c *Client) Query(ctx context.Context, f Filter) ([]Result, []string, error) {
+	if err := c.caps.Require(capability.FeatureQuery); err != nil {
+		return nil, nil, err
+	}
+	if f.Group != "" {
+		if err := c.caps.Require(capability.FeatureGroups); err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, err := path.Match(f.Name, ""); err != nil {
+		return nil, nil, errors.Wrapf(err, errFmtNamePattern, f.Name)
+	}
+
+	res := []Result{}
+	warnings := []string{}
+	cursor := ""
+	for {
+		q := c.query(f, cursor)
+		u, err := c.resource(f).Create(ctx, q.GetUnstructured(), metav1.CreateOptions{})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errQuery)
+		}
+		resp := &resources.Query{Unstructured: *u}
+		for _, r := range resp.GetResults() {
+			if r.Object == nil {
+				continue
+			}
+			obj := &unstructured.Unstructured{Object: r.Object}
+			if !matchName(f.Name, obj.GetName()) {
+				continue
+			}
+			res = append(res, Result{
+				Group:        r.ControlPlane.Namespace,
+				ControlPlane: r.ControlPlane.Name,
+				Object:       obj,
+			})
+		}
+		warnings = append(warnings, resp.GetWarnings()...)
+
+		next := resp.GetNextCursor()
+		if next == "" || next == cursor {
+			return res, warnings, nil
+		}
+		cursor = next
+	}
+}
+
+func (c *Client) resource(f Filter) dynamic.ResourceInterface {
+	if f.Group != "" {
+		return c.c.Resource(groupQueries).Namespace(f.Group)
+	}
+	return c.c.Resource(spaceQueries)
+}
+
+func (c *Client) query(f Filter, cursor string) *resources.Query {
+	q := &resources.Query{}
+	q.Object = map[string]interface{}{}
+	if f.Group != "" {
+		q.SetGroupVersionKind(resources.GroupQueryGVK)
+		q.SetNamespace(f.Group)
+	} else {
+		q.SetGroupVersionKind(resources.SpaceQueryGVK)
+	}
+
+	kind, group := splitKind(f.Kind)
+	filter := resources.QueryFilter{
+		ControlPlane: resources.QueryControlPlane{Name: f.ControlPlane, Namespace: f.Group},
+		Kind:         kind,
+		Group:        group,
+		Namespace:    f.Namespace,
+		Labels:       f.Labels,
+	}
+	// Only exact names can be filtered server side; patterns are matched
+	// once results are returned.
+	if f.Name != "" && !isPattern(f.Name) {
+		filter.Names = []string{f.Name}
+	}
+	q.SetFilter(filter)
+	q.SetObjectSelection()
+	q.SetPage(c.pageSize, cursor)
+	return q
+}
+
+// splitKind splits a kind of the form kind.group into its parts. Kinds are
+// matched case-insensitively by the Space, so they are passed through as
+// supplied.
+func splitKind(k string) (string, string) {
+	kind, group, _ := strings.Cut(k, ".")
+	return kind, group
+}
+
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, `*?[\`)
+}
+
+func matchName(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
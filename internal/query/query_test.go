@@ -0,0 +1,220 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/upbound/up/internal/capability"
+	"github.com/upbound/up/internal/resources"
+)
+
+func object(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "s3.aws.upbound.io/v1beta1",
+		"kind":       "Bucket",
+		"metadata":   map[string]interface{}{"name": name},
+	}
+}
+
+func result(group, ctp, name string) interface{} {
+	return map[string]interface{}{
+		"controlPlane": map[string]interface{}{"name": ctp, "namespace": group},
+		"object":       object(name),
+	}
+}
+
+// pages answers successive queries with the supplied status objects and
+// records the queries received.
+func pages(got *[]*resources.Query, statuses ...map[string]interface{}) ktesting.ReactionFunc {
+	i := 0
+	return func(action ktesting.Action) (bool, runtime.Object, error) {
+		u := action.(ktesting.CreateAction).GetObject().(*unstructured.Unstructured).DeepCopy()
+		*got = append(*got, &resources.Query{Unstructured: *u})
+		u.Object["status"] = statuses[i]
+		i++
+		return true, u, nil
+	}
+}
+
+func TestQuery(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		results  []Result
+		warnings []string
+		queries  int
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		filter   Filter
+		caps     *capability.Capabilities
+		resource string
+		statuses []map[string]interface{}
+		err      error
+		want     want
+	}{
+		"SpaceWide": {
+			reason:   "A filter without a group should issue a SpaceQuery and follow cursors across pages.",
+			filter:   Filter{Kind: "Bucket"},
+			resource: "spacequeries",
+			statuses: []map[string]interface{}{
+				{
+					"objects": []interface{}{result("default", "ctp1", "a")},
+					"cursor":  map[string]interface{}{"next": "1"},
+				},
+				{
+					"objects":  []interface{}{result("team", "ctp2", "b")},
+					"warnings": []interface{}{"control plane team/ctp3 unreachable"},
+				},
+			},
+			want: want{
+				results: []Result{
+					{Group: "default", ControlPlane: "ctp1", Object: &unstructured.Unstructured{Object: object("a")}},
+					{Group: "team", ControlPlane: "ctp2", Object: &unstructured.Unstructured{Object: object("b")}},
+				},
+				warnings: []string{"control plane team/ctp3 unreachable"},
+				queries:  2,
+			},
+		},
+		"GroupNamePattern": {
+			reason:   "A filter with a group should issue a GroupQuery and match name patterns client side.",
+			filter:   Filter{Group: "team", Name: "prod-*"},
+			resource: "groupqueries",
+			statuses: []map[string]interface{}{
+				{
+					"objects": []interface{}{
+						result("team", "ctp1", "prod-a"),
+						result("team", "ctp1", "dev-a"),
+					},
+				},
+			},
+			want: want{
+				results: []Result{
+					{Group: "team", ControlPlane: "ctp1", Object: &unstructured.Unstructured{Object: object("prod-a")}},
+				},
+				warnings: []string{},
+				queries:  1,
+			},
+		},
+		"InvalidPattern": {
+			reason: "An invalid name pattern should be rejected before querying.",
+			filter: Filter{Name: "["},
+			want: want{
+				err: errors.Wrapf(errors.New("syntax error in pattern"), errFmtNamePattern, "["),
+			},
+		},
+		"Unsupported": {
+			reason: "Querying a Space that does not serve the query API should fail before querying.",
+			filter: Filter{Kind: "Bucket"},
+			caps:   &capability.Capabilities{Target: capability.TargetSpace, Version: "v1.1.0"},
+			want: want{
+				err: &capability.UnsupportedError{Feature: capability.FeatureQuery, Target: capability.TargetSpace, Required: "v1.2", Found: "v1.1.0"},
+			},
+		},
+		"QueryError": {
+			reason:   "Errors answering the query should be returned.",
+			resource: "spacequeries",
+			err:      errBoom,
+			want: want{
+				err:     errors.Wrap(errBoom, errQuery),
+				queries: 0,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			got := []*resources.Query{}
+			if tc.err != nil {
+				dyn.PrependReactor("create", tc.resource, func(ktesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.err
+				})
+			} else if tc.resource != "" {
+				dyn.PrependReactor("create", tc.resource, pages(&got, tc.statuses...))
+			}
+
+			res, warnings, err := New(dyn, WithCapabilities(tc.caps)).Query(context.Background(), tc.filter)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nQuery(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.results, res); diff != "" {
+				t.Errorf("\n%s\nQuery(...): -want results, +got results:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, warnings); diff != "" {
+				t.Errorf("\n%s\nQuery(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.queries, len(got)); diff != "" {
+				t.Errorf("\n%s\nQuery(...): -want queries, +got queries:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		filter Filter
+		cursor string
+		want   map[string]interface{}
+	}{
+		"Qualified": {
+			reason: "Qualified kinds, exact names and the group should be passed to the Space.",
+			filter: Filter{Group: "team", ControlPlane: "ctp1", Kind: "bucket.s3.aws.upbound.io", Name: "a", Labels: map[string]string{"env": "prod"}},
+			cursor: "5",
+			want: map[string]interface{}{
+				"filter": map[string]interface{}{
+					"controlPlane": map[string]interface{}{"name": "ctp1", "namespace": "team"},
+					"kind":         "bucket",
+					"group":        "s3.aws.upbound.io",
+					"names":        []interface{}{"a"},
+					"labels":       map[string]interface{}{"env": "prod"},
+				},
+				"objects": map[string]interface{}{"id": true, "controlPlane": true, "object": true},
+				"page":    map[string]interface{}{"first": int64(10), "cursor": "5"},
+			},
+		},
+		"Pattern": {
+			reason: "Name patterns should not be passed to the Space.",
+			filter: Filter{Name: "prod-*"},
+			want: map[string]interface{}{
+				"filter": map[string]interface{}{
+					"controlPlane": map[string]interface{}{},
+				},
+				"objects": map[string]interface{}{"id": true, "controlPlane": true, "object": true},
+				"page":    map[string]interface{}{"first": int64(10)},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			q := New(nil, WithPageSize(10)).query(tc.filter, tc.cursor)
+			if diff := cmp.Diff(tc.want, q.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nquery(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
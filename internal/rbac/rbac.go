@@ -0,0 +1,263 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rbac grants teams least-privilege access to a control plane, e.g.
+// to manage claims of a few API groups in a single namespace.
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	authv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// DefaultTokenTTL is how long tokens in generated kubeconfigs are valid.
+	DefaultTokenTTL = 24 * time.Hour
+
+	// LabelTeam is set on all objects created for a team.
+	LabelTeam = "up.upbound.io/team"
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "up"
+
+	errFmtInvalidTeam   = "invalid team name %q: %s"
+	errNoNamespace      = "a namespace is required"
+	errNoGrants         = "at least one API group must be granted"
+	errCreateNamespace  = "cannot create namespace"
+	errApplySA          = "cannot apply service account"
+	errApplyRole        = "cannot apply role"
+	errApplyRoleBinding = "cannot apply role binding"
+	errCreateToken      = "cannot create token"
+	errFmtRevoke        = "cannot delete %s"
+)
+
+var (
+	// readVerbs grant read-only access.
+	readVerbs = []string{"get", "list", "watch"}
+	// writeVerbs grant full access.
+	writeVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+)
+
+// A Grant of access to resources of an API group, e.g. the claims of a
+// Configuration.
+type Grant struct {
+	APIGroup string
+	// Resources are the plural names of resources, e.g. postgresqlinstances.
+	// Defaults to all resources of the API group.
+	Resources []string
+}
+
+// A Request for access to a control plane.
+type Request struct {
+	// Team is used to name the ServiceAccount, Role and RoleBinding.
+	Team string
+	// Namespace the team is granted access to. Created if it does not
+	// exist.
+	Namespace string
+	Grants    []Grant
+	// ReadOnly grants only get, list and watch.
+	ReadOnly bool
+}
+
+// Generator generates least-privilege kubeconfigs for a control plane.
+type Generator struct {
+	kube    kubernetes.Interface
+	cluster api.Cluster
+	ttl     time.Duration
+	log     logging.Logger
+}
+
+// Option modifies a Generator.
+type Option func(*Generator)
+
+// WithTokenTTL sets how long tokens in generated kubeconfigs are valid.
+func WithTokenTTL(d time.Duration) Option {
+	return func(g *Generator) {
+		g.ttl = d
+	}
+}
+
+// WithLogger sets the logger used by the Generator.
+func WithLogger(l logging.Logger) Option {
+	return func(g *Generator) {
+		g.log = l
+	}
+}
+
+// New returns a Generator that creates RBAC objects in a control plane using
+// the supplied client. Generated kubeconfigs reach the control plane through
+// the supplied cluster, e.g. the cluster of an admin kubeconfig.
+func New(kube kubernetes.Interface, cluster api.Cluster, opts ...Option) *Generator {
+	g := &Generator{
+		kube:    kube,
+		cluster: cluster,
+		ttl:     DefaultTokenTTL,
+		log:     logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+// Generate a kubeconfig for the requested access. A ServiceAccount, Role and
+// RoleBinding are created in the namespace, or updated if they exist, and a
+// token is requested for the ServiceAccount.
+func (g *Generator) Generate(ctx context.Context, r Request) (*api.Config, error) {
+	if err := validate(r); err != nil {
+		return nil, err
+	}
+	labels := map[string]string{managedByLabel: managedBy, LabelTeam: r.Team}
+	meta := metav1.ObjectMeta{Name: r.Team, Namespace: r.Namespace, Labels: labels}
+
+	if err := g.ensureNamespace(ctx, r.Namespace); err != nil {
+		return nil, err
+	}
+	sa := &corev1.ServiceAccount{ObjectMeta: meta}
+	if _, err := g.kube.CoreV1().ServiceAccounts(r.Namespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil && !kerrors.IsAlreadyExists(err) {
+		return nil, errors.Wrap(err, errApplySA)
+	}
+	if err := g.applyRole(ctx, &rbacv1.Role{ObjectMeta: meta, Rules: rules(r)}); err != nil {
+		return nil, errors.Wrap(err, errApplyRole)
+	}
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: meta,
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: r.Team},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: r.Team, Namespace: r.Namespace}},
+	}
+	if err := g.applyRoleBinding(ctx, rb); err != nil {
+		return nil, errors.Wrap(err, errApplyRoleBinding)
+	}
+
+	g.log.Debug("Requesting service account token", "namespace", r.Namespace, "team", r.Team, "ttl", g.ttl)
+	tr, err := g.kube.CoreV1().ServiceAccounts(r.Namespace).CreateToken(ctx, r.Team, &authv1.TokenRequest{
+		Spec: authv1.TokenRequestSpec{ExpirationSeconds: pointer.Int64(int64(g.ttl.Seconds()))},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateToken)
+	}
+	return g.kubeconfig(r, tr.Status.Token), nil
+}
+
+// Revoke the access of a team by deleting its RoleBinding, Role and
+// ServiceAccount. Tokens of the ServiceAccount are invalidated with it.
+func (g *Generator) Revoke(ctx context.Context, namespace, team string) error {
+	for _, d := range []struct {
+		kind string
+		fn   func(context.Context, string, metav1.DeleteOptions) error
+	}{
+		{kind: "role binding", fn: g.kube.RbacV1().RoleBindings(namespace).Delete},
+		{kind: "role", fn: g.kube.RbacV1().Roles(namespace).Delete},
+		{kind: "service account", fn: g.kube.CoreV1().ServiceAccounts(namespace).Delete},
+	} {
+		if err := d.fn(ctx, team, metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, errFmtRevoke, d.kind)
+		}
+	}
+	return nil
+}
+
+func validate(r Request) error {
+	if errs := validation.IsDNS1123Subdomain(r.Team); len(errs) > 0 {
+		return errors.Errorf(errFmtInvalidTeam, r.Team, strings.Join(errs, ", "))
+	}
+	if r.Namespace == "" {
+		return errors.New(errNoNamespace)
+	}
+	if len(r.Grants) == 0 {
+		return errors.New(errNoGrants)
+	}
+	return nil
+}
+
+func rules(r Request) []rbacv1.PolicyRule {
+	verbs := writeVerbs
+	if r.ReadOnly {
+		verbs = readVerbs
+	}
+	out := make([]rbacv1.PolicyRule, 0, len(r.Grants))
+	for _, gr := range r.Grants {
+		res := gr.Resources
+		if len(res) == 0 {
+			res = []string{"*"}
+		}
+		out = append(out, rbacv1.PolicyRule{APIGroups: []string{gr.APIGroup}, Resources: res, Verbs: verbs})
+	}
+	return out
+}
+
+func (g *Generator) ensureNamespace(ctx context.Context, name string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if _, err := g.kube.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil && !kerrors.IsAlreadyExists(err) {
+		return errors.Wrap(err, errCreateNamespace)
+	}
+	return nil
+}
+
+func (g *Generator) applyRole(ctx context.Context, role *rbacv1.Role) error {
+	roles := g.kube.RbacV1().Roles(role.GetNamespace())
+	existing, err := roles.Get(ctx, role.GetName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = roles.Create(ctx, role, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing.Rules = role.Rules
+	_, err = roles.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func (g *Generator) applyRoleBinding(ctx context.Context, rb *rbacv1.RoleBinding) error {
+	rbs := g.kube.RbacV1().RoleBindings(rb.GetNamespace())
+	existing, err := rbs.Get(ctx, rb.GetName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = rbs.Create(ctx, rb, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	// The role of a binding is immutable, but always matches since both are
+	// named after the team.
+	existing.Subjects = rb.Subjects
+	_, err = rbs.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func (g *Generator) kubeconfig(r Request, token string) *api.Config {
+	key := fmt.Sprintf("%s-%s", r.Namespace, r.Team)
+	cluster := g.cluster.DeepCopy()
+	conf := api.NewConfig()
+	conf.Clusters[key] = cluster
+	conf.AuthInfos[key] = &api.AuthInfo{Token: token}
+	conf.Contexts[key] = &api.Context{Cluster: key, AuthInfo: key, Namespace: r.Namespace}
+	conf.CurrentContext = key
+	return conf
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	authv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var cluster = api.Cluster{Server: "https://ctp.example.com", CertificateAuthorityData: []byte("ca")}
+
+func newFake() *fake.Clientset {
+	kube := fake.NewSimpleClientset()
+	kube.PrependReactor("create", "serviceaccounts", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		if a.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		return true, &authv1.TokenRequest{Status: authv1.TokenRequestStatus{Token: "t0k3n"}}, nil
+	})
+	return kube
+}
+
+func TestGenerate(t *testing.T) {
+	claims := Grant{APIGroup: "platform.example.org", Resources: []string{"postgresqlinstances"}}
+
+	type want struct {
+		rules []rbacv1.PolicyRule
+		conf  *api.Config
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		req    Request
+		want   want
+	}{
+		"InvalidTeam": {
+			reason: "The team must be a valid object name.",
+			req:    Request{Team: "Team A", Namespace: "team-a", Grants: []Grant{claims}},
+			want: want{
+				err: errors.Errorf(errFmtInvalidTeam, "Team A", strings.Join(validation.IsDNS1123Subdomain("Team A"), ", ")),
+			},
+		},
+		"NoGrants": {
+			reason: "At least one API group must be granted.",
+			req:    Request{Team: "team-a", Namespace: "team-a"},
+			want: want{
+				err: errors.New(errNoGrants),
+			},
+		},
+		"ReadOnly": {
+			reason: "A read only request should only grant read verbs, to all resources of API groups without resources.",
+			req:    Request{Team: "team-a", Namespace: "apps", Grants: []Grant{{APIGroup: "platform.example.org"}}, ReadOnly: true},
+			want: want{
+				rules: []rbacv1.PolicyRule{{APIGroups: []string{"platform.example.org"}, Resources: []string{"*"}, Verbs: readVerbs}},
+				conf: &api.Config{
+					Preferences:    api.Preferences{Extensions: map[string]runtime.Object{}},
+					Clusters:       map[string]*api.Cluster{"apps-team-a": cluster.DeepCopy()},
+					AuthInfos:      map[string]*api.AuthInfo{"apps-team-a": {Token: "t0k3n"}},
+					Contexts:       map[string]*api.Context{"apps-team-a": {Cluster: "apps-team-a", AuthInfo: "apps-team-a", Namespace: "apps"}},
+					CurrentContext: "apps-team-a",
+					Extensions:     map[string]runtime.Object{},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := newFake()
+			conf, err := New(kube, cluster).Generate(context.Background(), tc.req)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGenerate(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.conf, conf); diff != "" {
+				t.Errorf("\n%s\nGenerate(...): -want kubeconfig, +got kubeconfig:\n%s", tc.reason, diff)
+			}
+			role, err := kube.RbacV1().Roles(tc.req.Namespace).Get(context.Background(), tc.req.Team, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("\n%s\nGet role: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.rules, role.Rules); diff != "" {
+				t.Errorf("\n%s\nGenerate(...): -want rules, +got rules:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRegenerateAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	kube := newFake()
+	g := New(kube, cluster)
+
+	req := Request{Team: "team-a", Namespace: "apps", Grants: []Grant{{APIGroup: "a.example.org"}}}
+	if _, err := g.Generate(ctx, req); err != nil {
+		t.Fatalf("Generate(...): %v", err)
+	}
+	req.Grants = []Grant{{APIGroup: "b.example.org", Resources: []string{"buckets"}}}
+	if _, err := g.Generate(ctx, req); err != nil {
+		t.Fatalf("Generate(...): second call: %v", err)
+	}
+	role, _ := kube.RbacV1().Roles("apps").Get(ctx, "team-a", metav1.GetOptions{})
+	want := []rbacv1.PolicyRule{{APIGroups: []string{"b.example.org"}, Resources: []string{"buckets"}, Verbs: writeVerbs}}
+	if diff := cmp.Diff(want, role.Rules); diff != "" {
+		t.Errorf("Generate(...): -want rules, +got rules:\n%s", diff)
+	}
+
+	if err := g.Revoke(ctx, "apps", "team-a"); err != nil {
+		t.Fatalf("Revoke(...): %v", err)
+	}
+	if _, err := kube.CoreV1().ServiceAccounts("apps").Get(ctx, "team-a", metav1.GetOptions{}); !kerrors.IsNotFound(err) {
+		t.Errorf("Revoke(...): want service account deleted, got %v", err)
+	}
+	// Revoking again should be a no-op.
+	if err := g.Revoke(ctx, "apps", "team-a"); err != nil {
+		t.Errorf("Revoke(...): second call: %v", err)
+	}
+}
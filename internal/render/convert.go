@@ -0,0 +1,248 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+const (
+	// DefaultFunctionName is the name function-patch-and-transform is
+	// usually installed as.
+	DefaultFunctionName = "function-patch-and-transform"
+	// DefaultStepName is the name of the pipeline step that runs
+	// function-patch-and-transform.
+	DefaultStepName = "patch-and-transform"
+
+	// PatchAndTransformAPIVersion is the API version of the input of
+	// function-patch-and-transform.
+	PatchAndTransformAPIVersion = "pt.fn.crossplane.io/v1beta1"
+	// PatchAndTransformKind is the kind of the input of
+	// function-patch-and-transform.
+	PatchAndTransformKind = "Resources"
+)
+
+const (
+	errFmtAlreadyPipeline = "Composition %q is already in Pipeline mode"
+	errFmtNoResources     = "Composition %q has no resources to convert"
+	errMarshalInput       = "cannot marshal function-patch-and-transform input"
+)
+
+// PatchAndTransformInput is the input of function-patch-and-transform. It
+// mirrors the patch and transform fields of a Composition in Resources mode.
+type PatchAndTransformInput struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// PatchSets define named sets of patches that may be included by any
+	// resource.
+	PatchSets []xpextv1.PatchSet `json:"patchSets,omitempty"`
+
+	// Environment patches the environment of the composite resource.
+	Environment *PatchAndTransformEnvironment `json:"environment,omitempty"`
+
+	// Resources is the list of composed resource templates. Every template
+	// must be named.
+	Resources []xpextv1.ComposedTemplate `json:"resources"`
+}
+
+// PatchAndTransformEnvironment patches the environment of a composite
+// resource. Selecting EnvironmentConfigs remains the job of the Composition.
+type PatchAndTransformEnvironment struct {
+	Patches []xpextv1.EnvironmentPatch `json:"patches,omitempty"`
+}
+
+// A ConversionWarning describes a construct of a Composition that could not
+// be converted to Pipeline mode without changing its behaviour.
+type ConversionWarning struct {
+	// Resource is the name of the composed resource template the warning
+	// concerns. It is empty for warnings about the Composition as a whole.
+	Resource string
+
+	// Message describes the construct and what was done with it.
+	Message string
+}
+
+func (w ConversionWarning) String() string {
+	if w.Resource == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("resource %q: %s", w.Resource, w.Message)
+}
+
+// A ConvertOption modifies how a Composition is converted.
+type ConvertOption func(*convertOpts)
+
+type convertOpts struct {
+	function string
+	step     string
+}
+
+// WithFunctionName references the supplied Function rather than
+// DefaultFunctionName from the converted pipeline.
+func WithFunctionName(name string) ConvertOption {
+	return func(o *convertOpts) {
+		o.function = name
+	}
+}
+
+// WithStepName names the converted pipeline step rather than using
+// DefaultStepName.
+func WithStepName(name string) ConvertOption {
+	return func(o *convertOpts) {
+		o.step = name
+	}
+}
+
+// ConvertToPipeline converts a Composition in Resources mode, commonly known
+// as patch and transform (P&T) composition, to a Composition in Pipeline mode
+// with a single step that runs function-patch-and-transform. The supplied
+// Composition is not modified.
+//
+// Patch sets, environment patches, composed resource templates and their
+// patches, connection details and readiness checks are moved to the input of
+// the step unchanged, except that defaults Crossplane used to infer are made
+// explicit. EnvironmentConfig selection and connection secret settings stay
+// on the Composition. Constructs that can't be converted exactly are
+// converted as closely as possible and reported as warnings.
+func ConvertToPipeline(comp *xpextv1.Composition, opts ...ConvertOption) (*xpextv1.Composition, []ConversionWarning, error) {
+	o := &convertOpts{function: DefaultFunctionName, step: DefaultStepName}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	if comp.Spec.Mode != nil && *comp.Spec.Mode == xpextv1.CompositionModePipeline {
+		return nil, nil, errors.Errorf(errFmtAlreadyPipeline, comp.GetName())
+	}
+	if len(comp.Spec.Resources) == 0 {
+		return nil, nil, errors.Errorf(errFmtNoResources, comp.GetName())
+	}
+
+	out := comp.DeepCopy()
+	in := &PatchAndTransformInput{
+		TypeMeta:  metav1.TypeMeta{APIVersion: PatchAndTransformAPIVersion, Kind: PatchAndTransformKind},
+		PatchSets: out.Spec.PatchSets,
+		Resources: out.Spec.Resources,
+	}
+	var warnings []ConversionWarning
+
+	if env := out.Spec.Environment; env != nil && len(env.Patches) > 0 {
+		in.Environment = &PatchAndTransformEnvironment{Patches: env.Patches}
+		env.Patches = nil
+		if env.DefaultData == nil && env.EnvironmentConfigs == nil && env.Policy == nil {
+			out.Spec.Environment = nil
+		}
+	}
+
+	sets := make(map[string]bool, len(in.PatchSets))
+	for i := range in.PatchSets {
+		sets[in.PatchSets[i].Name] = true
+		defaultPatchTypes(in.PatchSets[i].Patches)
+	}
+
+	names := make(map[string]bool, len(in.Resources))
+	for _, t := range in.Resources {
+		if t.Name != nil {
+			names[*t.Name] = true
+		}
+	}
+	for i := range in.Resources {
+		t := &in.Resources[i]
+		if t.Name == nil || *t.Name == "" {
+			name := uniqueName(names, fmt.Sprintf("resource-%d", i))
+			t.Name = &name
+			warnings = append(warnings, ConversionWarning{
+				Resource: name,
+				Message:  fmt.Sprintf("template at index %d had no name and was named %q; existing composed resources are matched by name in Pipeline mode and will be replaced", i, name),
+			})
+		}
+		defaultPatchTypes(t.Patches)
+		for _, p := range t.Patches {
+			if p.Type == xpextv1.PatchTypePatchSet && p.PatchSetName != nil && !sets[*p.PatchSetName] {
+				warnings = append(warnings, ConversionWarning{
+					Resource: *t.Name,
+					Message:  fmt.Sprintf("patch references undefined patch set %q", *p.PatchSetName),
+				})
+			}
+		}
+		warnings = append(warnings, defaultConnectionDetails(t)...)
+	}
+
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errMarshalInput)
+	}
+
+	mode := xpextv1.CompositionModePipeline
+	out.Spec.Mode = &mode
+	out.Spec.PatchSets = nil
+	out.Spec.Resources = nil
+	out.Spec.Pipeline = []xpextv1.PipelineStep{{
+		Step:        o.step,
+		FunctionRef: xpextv1.FunctionReference{Name: o.function},
+		Input:       &runtime.RawExtension{Raw: raw},
+	}}
+	return out, warnings, nil
+}
+
+// defaultPatchTypes makes the default type of the supplied patches explicit.
+func defaultPatchTypes(ps []xpextv1.Patch) {
+	for i := range ps {
+		if ps[i].Type == "" {
+			ps[i].Type = xpextv1.PatchTypeFromCompositeFieldPath
+		}
+	}
+}
+
+// defaultConnectionDetails makes the type and name of the connection details
+// of the supplied template explicit where Crossplane would have inferred them,
+// and warns about those whose name can't be inferred.
+func defaultConnectionDetails(t *xpextv1.ComposedTemplate) []ConversionWarning {
+	var warnings []ConversionWarning
+	for i, cfg := range icomposite.ExtractConfigsFromComposedTemplate(t) {
+		cd := &t.ConnectionDetails[i]
+		tp := xpextv1.ConnectionDetailType(cfg.Type)
+		cd.Type = &tp
+		if cfg.Name == "" {
+			warnings = append(warnings, ConversionWarning{
+				Resource: *t.Name,
+				Message:  fmt.Sprintf("connection detail at index %d has no name; function-patch-and-transform requires one", i),
+			})
+			continue
+		}
+		name := cfg.Name
+		cd.Name = &name
+	}
+	return warnings
+}
+
+// uniqueName returns the supplied name, suffixed if necessary to make it
+// unique among the supplied names, and records it.
+func uniqueName(names map[string]bool, name string) string {
+	n := name
+	for i := 1; names[n]; i++ {
+		n = fmt.Sprintf("%s-%d", name, i)
+	}
+	names[n] = true
+	return n
+}
@@ -0,0 +1,275 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestConvertToPipeline(t *testing.T) {
+	type args struct {
+		comp string
+		opts []ConvertOption
+	}
+	type want struct {
+		comp     string
+		warnings []ConversionWarning
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AlreadyPipeline": {
+			reason: "A Composition already in Pipeline mode should not be converted.",
+			args: args{comp: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec:
+  mode: Pipeline
+  pipeline:
+  - step: one
+    functionRef:
+      name: function-one
+`},
+			want: want{err: errors.Errorf(errFmtAlreadyPipeline, "example")},
+		},
+		"NoResources": {
+			reason: "A Composition without resources should not be converted.",
+			args: args{comp: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec: {}
+`},
+			want: want{err: errors.Errorf(errFmtNoResources, "example")},
+		},
+		"Resources": {
+			reason: "Resources, patch sets and environment patches should move to the input of a function-patch-and-transform step, with defaults made explicit.",
+			args: args{comp: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XBucket
+  writeConnectionSecretsToNamespace: crossplane-system
+  environment:
+    environmentConfigs:
+    - type: Reference
+      ref:
+        name: example
+    patches:
+    - type: FromCompositeFieldPath
+      fromFieldPath: spec.region
+      toFieldPath: region
+  patchSets:
+  - name: common
+    patches:
+    - fromFieldPath: metadata.labels
+      toFieldPath: metadata.labels
+  resources:
+  - name: bucket
+    base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: Bucket
+    patches:
+    - type: PatchSet
+      patchSetName: common
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    connectionDetails:
+    - fromConnectionSecretKey: endpoint
+    - name: region
+      fromFieldPath: spec.forProvider.region
+`},
+			want: want{comp: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+  creationTimestamp: null
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XBucket
+  writeConnectionSecretsToNamespace: crossplane-system
+  environment:
+    environmentConfigs:
+    - type: Reference
+      ref:
+        name: example
+  mode: Pipeline
+  pipeline:
+  - step: patch-and-transform
+    functionRef:
+      name: function-patch-and-transform
+    input:
+      apiVersion: pt.fn.crossplane.io/v1beta1
+      kind: Resources
+      environment:
+        patches:
+        - type: FromCompositeFieldPath
+          fromFieldPath: spec.region
+          toFieldPath: region
+      patchSets:
+      - name: common
+        patches:
+        - type: FromCompositeFieldPath
+          fromFieldPath: metadata.labels
+          toFieldPath: metadata.labels
+      resources:
+      - name: bucket
+        base:
+          apiVersion: s3.aws.upbound.io/v1beta1
+          kind: Bucket
+        patches:
+        - type: PatchSet
+          patchSetName: common
+        - type: FromCompositeFieldPath
+          fromFieldPath: spec.region
+          toFieldPath: spec.forProvider.region
+        connectionDetails:
+        - type: FromConnectionSecretKey
+          name: endpoint
+          fromConnectionSecretKey: endpoint
+        - type: FromFieldPath
+          name: region
+          fromFieldPath: spec.forProvider.region
+`},
+		},
+		"Untranslatable": {
+			reason: "Unnamed templates should be named, and constructs that can't be converted exactly should be reported.",
+			args: args{
+				comp: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XBucket
+  resources:
+  - base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: Bucket
+    patches:
+    - type: PatchSet
+      patchSetName: missing
+    connectionDetails:
+    - fromFieldPath: status.atProvider.arn
+  - name: resource-0
+    base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: BucketVersioning
+`,
+				opts: []ConvertOption{WithFunctionName("upbound-function-patch-and-transform"), WithStepName("pt")},
+			},
+			want: want{
+				comp: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+  creationTimestamp: null
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XBucket
+  mode: Pipeline
+  pipeline:
+  - step: pt
+    functionRef:
+      name: upbound-function-patch-and-transform
+    input:
+      apiVersion: pt.fn.crossplane.io/v1beta1
+      kind: Resources
+      resources:
+      - name: resource-0-1
+        base:
+          apiVersion: s3.aws.upbound.io/v1beta1
+          kind: Bucket
+        patches:
+        - type: PatchSet
+          patchSetName: missing
+        connectionDetails:
+        - type: FromFieldPath
+          fromFieldPath: status.atProvider.arn
+      - name: resource-0
+        base:
+          apiVersion: s3.aws.upbound.io/v1beta1
+          kind: BucketVersioning
+`,
+				warnings: []ConversionWarning{
+					{Resource: "resource-0-1", Message: `template at index 0 had no name and was named "resource-0-1"; existing composed resources are matched by name in Pipeline mode and will be replaced`},
+					{Resource: "resource-0-1", Message: `patch references undefined patch set "missing"`},
+					{Resource: "resource-0-1", Message: "connection detail at index 0 has no name; function-patch-and-transform requires one"},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			comp := &xpextv1.Composition{}
+			if err := yaml.Unmarshal([]byte(tc.args.comp), comp); err != nil {
+				t.Fatalf("Unmarshal(...): %s", err)
+			}
+			before := comp.DeepCopy()
+			got, warnings, err := ConvertToPipeline(comp, tc.args.opts...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nConvertToPipeline(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, warnings); diff != "" {
+				t.Errorf("\n%s\nConvertToPipeline(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(before, comp); diff != "" {
+				t.Errorf("\n%s\nConvertToPipeline(...): supplied Composition was modified: -before, +after:\n%s", tc.reason, diff)
+			}
+			if tc.want.comp == "" {
+				return
+			}
+			want := map[string]any{}
+			if err := yaml.Unmarshal([]byte(tc.want.comp), &want); err != nil {
+				t.Fatalf("Unmarshal(...): %s", err)
+			}
+			b, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal(...): %s", err)
+			}
+			gotm := map[string]any{}
+			if err := json.Unmarshal(b, &gotm); err != nil {
+				t.Fatalf("Unmarshal(...): %s", err)
+			}
+			if diff := cmp.Diff(want, gotm); diff != "" {
+				t.Errorf("\n%s\nConvertToPipeline(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+const (
+	// BranchFallback is the branch of a match transform taken when no
+	// pattern matches.
+	BranchFallback = "fallback"
+
+	errFmtCoverXR = "cannot render composite resource at index %d"
+)
+
+// A PatchCoverage reports how often a patch of a Composition was exercised
+// by a set of rendered composite resources.
+type PatchCoverage struct {
+	// Resource is the name of the Composition template the patch belongs to.
+	Resource string `json:"resource"`
+	// Index of the patch in the template, after PatchSets were resolved.
+	Index int `json:"index"`
+	// Type of the patch.
+	Type xpextv1.PatchType `json:"type"`
+	// ToFieldPath is the destination field.
+	ToFieldPath string `json:"toFieldPath,omitempty"`
+	// Applied, Skipped and Failed count the renders with each outcome.
+	Applied int `json:"applied"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+	// Transforms of the patch, in order.
+	Transforms []TransformCoverage `json:"transforms,omitempty"`
+}
+
+// Exercised returns true if the patch was applied by at least one render.
+func (p PatchCoverage) Exercised() bool {
+	return p.Applied > 0
+}
+
+// Covered returns true if the patch and each of its transforms and their
+// branches were exercised.
+func (p PatchCoverage) Covered() bool {
+	if !p.Exercised() {
+		return false
+	}
+	for _, t := range p.Transforms {
+		if !t.Covered() {
+			return false
+		}
+	}
+	return true
+}
+
+// A TransformCoverage reports how often a transform of a patch was
+// exercised.
+type TransformCoverage struct {
+	// Type of the transform.
+	Type xpextv1.TransformType `json:"type"`
+	// Exercised counts the renders in which the transform succeeded.
+	Exercised int `json:"exercised"`
+	// Branches of a map or match transform, i.e. its keys or its patterns
+	// and fallback, and how often each was taken.
+	Branches []BranchCoverage `json:"branches,omitempty"`
+}
+
+// Covered returns true if the transform and each of its branches were
+// exercised.
+func (t TransformCoverage) Covered() bool {
+	if t.Exercised == 0 {
+		return false
+	}
+	for _, b := range t.Branches {
+		if b.Exercised == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// A BranchCoverage reports how often a branch of a transform was taken.
+type BranchCoverage struct {
+	// Name of the branch: a map key, a match pattern such as regexp:^a.*,
+	// or fallback.
+	Name      string `json:"name"`
+	Exercised int    `json:"exercised"`
+}
+
+// Coverage reports which patches and transforms of a Composition were
+// exercised by a set of rendered composite resources.
+type Coverage struct {
+	// Renders is the number of composite resources rendered.
+	Renders int `json:"renders"`
+	// Patches of each Composition template, in order.
+	Patches []PatchCoverage `json:"patches"`
+}
+
+// Uncovered returns the patches that were never applied, or that have a
+// transform or branch of a transform that was never exercised.
+func (c *Coverage) Uncovered() []PatchCoverage {
+	out := []PatchCoverage{}
+	for _, p := range c.Patches {
+		if !p.Covered() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Coverage renders each of the supplied composite resources with the
+// supplied Composition and XRD, and reports which of the Composition's
+// patches and transforms they exercised. Compositions in Pipeline mode have
+// no patches, so their coverage is empty.
+func (r *Renderer) Coverage(ctx context.Context, comp *xpextv1.Composition, xrd *xpextv1.CompositeResourceDefinition, xrs ...*unstructured.Unstructured) (*Coverage, error) {
+	c := &Coverage{Patches: []PatchCoverage{}}
+	if comp.Spec.Mode != nil && *comp.Spec.Mode == xpextv1.CompositionModePipeline {
+		return c, nil
+	}
+	ts, err := icomposite.ComposedTemplates(comp.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, errTemplates)
+	}
+
+	// Index patches by resource and index, as they are traced.
+	patches := map[string]xpextv1.Patch{}
+	index := map[string]int{}
+	for i, t := range ts {
+		name := strconv.Itoa(i)
+		if t.Name != nil && *t.Name != "" {
+			name = *t.Name
+		}
+		for j, p := range t.Patches {
+			key := patchKey(name, j)
+			patches[key] = p
+			index[key] = len(c.Patches)
+			c.Patches = append(c.Patches, newPatchCoverage(name, j, p))
+		}
+	}
+
+	for i, xr := range xrs {
+		traces, err := r.Trace(ctx, &Inputs{Composition: comp, XRD: xrd, XR: xr})
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtCoverXR, i)
+		}
+		c.Renders++
+		for _, pt := range traces {
+			key := patchKey(pt.Resource, pt.Index)
+			j, ok := index[key]
+			if !ok {
+				continue
+			}
+			cover(&c.Patches[j], patches[key], pt)
+		}
+	}
+	return c, nil
+}
+
+func patchKey(resource string, index int) string {
+	return fmt.Sprintf("%s/%d", resource, index)
+}
+
+func newPatchCoverage(resource string, index int, p xpextv1.Patch) PatchCoverage {
+	pc := PatchCoverage{Resource: resource, Index: index, Type: p.Type}
+	if pc.Type == "" {
+		pc.Type = xpextv1.PatchTypeFromCompositeFieldPath
+	}
+	switch {
+	case p.ToFieldPath != nil:
+		pc.ToFieldPath = *p.ToFieldPath
+	case p.FromFieldPath != nil:
+		pc.ToFieldPath = *p.FromFieldPath
+	}
+	for _, t := range p.Transforms {
+		pc.Transforms = append(pc.Transforms, TransformCoverage{Type: t.Type, Branches: branches(t)})
+	}
+	return pc
+}
+
+// branches returns the branches of a map or match transform.
+func branches(t xpextv1.Transform) []BranchCoverage {
+	var names []string
+	switch {
+	case t.Type == xpextv1.TransformTypeMap && t.Map != nil:
+		for k := range t.Map.Pairs {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+	case t.Type == xpextv1.TransformTypeMatch && t.Match != nil:
+		for _, p := range t.Match.Patterns {
+			names = append(names, patternName(p))
+		}
+		names = append(names, BranchFallback)
+	}
+	out := make([]BranchCoverage, len(names))
+	for i, n := range names {
+		out[i] = BranchCoverage{Name: n}
+	}
+	return out
+}
+
+// cover records the supplied trace of the supplied patch.
+func cover(pc *PatchCoverage, p xpextv1.Patch, pt PatchTrace) {
+	switch pt.Outcome {
+	case PatchOutcomeApplied:
+		pc.Applied++
+	case PatchOutcomeSkipped:
+		pc.Skipped++
+	case PatchOutcomeFailed:
+		pc.Failed++
+	}
+	for i, tt := range pt.Transforms {
+		if i >= len(pc.Transforms) || tt.Error != "" {
+			continue
+		}
+		tc := &pc.Transforms[i]
+		tc.Exercised++
+		if b := takenBranch(p.Transforms[i], tt.Input); b != "" {
+			for j := range tc.Branches {
+				if tc.Branches[j].Name == b {
+					tc.Branches[j].Exercised++
+				}
+			}
+		}
+	}
+}
+
+// takenBranch returns the branch of a map or match transform taken for the
+// supplied input, if any.
+func takenBranch(t xpextv1.Transform, in any) string {
+	s, ok := in.(string)
+	switch {
+	case t.Type == xpextv1.TransformTypeMap && t.Map != nil:
+		if _, found := t.Map.Pairs[s]; ok && found {
+			return s
+		}
+	case t.Type == xpextv1.TransformTypeMatch && t.Match != nil:
+		if !ok {
+			return BranchFallback
+		}
+		for _, p := range t.Match.Patterns {
+			if matches(p, s) {
+				return patternName(p)
+			}
+		}
+		return BranchFallback
+	}
+	return ""
+}
+
+func matches(p xpextv1.MatchTransformPattern, s string) bool {
+	switch p.Type {
+	case xpextv1.MatchTransformPatternTypeLiteral:
+		return p.Literal != nil && *p.Literal == s
+	case xpextv1.MatchTransformPatternTypeRegexp:
+		if p.Regexp == nil {
+			return false
+		}
+		re, err := regexp.Compile(*p.Regexp)
+		return err == nil && re.MatchString(s)
+	}
+	return false
+}
+
+func patternName(p xpextv1.MatchTransformPattern) string {
+	switch {
+	case p.Type == xpextv1.MatchTransformPatternTypeLiteral && p.Literal != nil:
+		return fmt.Sprintf("%s:%s", p.Type, *p.Literal)
+	case p.Type == xpextv1.MatchTransformPatternTypeRegexp && p.Regexp != nil:
+		return fmt.Sprintf("%s:%s", p.Type, *p.Regexp)
+	}
+	return string(p.Type)
+}
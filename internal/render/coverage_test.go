@@ -0,0 +1,136 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCoverage(t *testing.T) {
+	in, err := ParseInputs(readFile(t, "composition-coverage.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+	if err != nil {
+		t.Fatalf("ParseInputs(...): %s", err)
+	}
+	eu := in.XR.DeepCopy()
+	_ = unstructured.SetNestedField(eu.Object, "eu-west-1", "spec", "region")
+
+	match := PatchCoverage{
+		Resource:    "bucket",
+		Index:       0,
+		Type:        "FromCompositeFieldPath",
+		ToFieldPath: "spec.forProvider.tags.geo",
+		Applied:     2,
+		Transforms: []TransformCoverage{{
+			Type:      "match",
+			Exercised: 2,
+			Branches: []BranchCoverage{
+				{Name: "literal:us-east-1", Exercised: 1},
+				{Name: "regexp:^eu-", Exercised: 1},
+				{Name: BranchFallback},
+			},
+		}},
+	}
+	optional := PatchCoverage{
+		Resource:    "bucket",
+		Index:       1,
+		Type:        "FromCompositeFieldPath",
+		ToFieldPath: "spec.forProvider.tags.tier",
+		Skipped:     2,
+	}
+	mapped := PatchCoverage{
+		Resource:    "bucket",
+		Index:       2,
+		Type:        "FromCompositeFieldPath",
+		ToFieldPath: "spec.forProvider.tags.zone",
+		Applied:     1,
+		Failed:      1,
+		Transforms: []TransformCoverage{{
+			Type:      "map",
+			Exercised: 1,
+			Branches: []BranchCoverage{
+				{Name: "us-east-1", Exercised: 1},
+				{Name: "us-west-2"},
+			},
+		}},
+	}
+	name := PatchCoverage{
+		Resource:    "bucket",
+		Index:       3,
+		Type:        "FromCompositeFieldPath",
+		ToFieldPath: "spec.forProvider.tags.name",
+		Applied:     2,
+	}
+
+	type want struct {
+		coverage  *Coverage
+		uncovered []PatchCoverage
+	}
+	cases := map[string]struct {
+		reason string
+		xrs    []*unstructured.Unstructured
+		want   want
+	}{
+		"NoRenders": {
+			reason: "Without composite resources no patch should be exercised.",
+			want: want{
+				coverage: &Coverage{Patches: []PatchCoverage{
+					func() PatchCoverage {
+						p := match
+						p.Applied = 0
+						p.Transforms = []TransformCoverage{{Type: "match", Branches: []BranchCoverage{{Name: "literal:us-east-1"}, {Name: "regexp:^eu-"}, {Name: BranchFallback}}}}
+						return p
+					}(),
+					func() PatchCoverage { p := optional; p.Skipped = 0; return p }(),
+					func() PatchCoverage {
+						p := mapped
+						p.Applied, p.Failed = 0, 0
+						p.Transforms = []TransformCoverage{{Type: "map", Branches: []BranchCoverage{{Name: "us-east-1"}, {Name: "us-west-2"}}}}
+						return p
+					}(),
+					func() PatchCoverage { p := name; p.Applied = 0; return p }(),
+				}},
+			},
+		},
+		"Renders": {
+			reason: "Patches that were never applied, and transforms with branches that were never taken, should be uncovered.",
+			xrs:    []*unstructured.Unstructured{in.XR, eu},
+			want: want{
+				coverage:  &Coverage{Renders: 2, Patches: []PatchCoverage{match, optional, mapped, name}},
+				uncovered: []PatchCoverage{match, optional, mapped},
+			},
+		},
+	}
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			got, err := NewRenderer().Coverage(context.Background(), in.Composition, in.XRD, tc.xrs...)
+			if err != nil {
+				t.Fatalf("Coverage(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.coverage, got); diff != "" {
+				t.Errorf("\n%s\nCoverage(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.want.uncovered == nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.uncovered, got.Uncovered()); diff != "" {
+				t.Errorf("\n%s\nUncovered(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+const (
+	// DefaultFieldOwner is the field manager composed resources are applied
+	// with when diffing.
+	DefaultFieldOwner = "apiextensions.crossplane.io/composed"
+
+	errGetLiveXR     = "cannot get live composite resource"
+	errPlanDeletions = "cannot determine composed resources that would be garbage collected"
+	errAssociate     = "cannot match rendered composed resources to live composed resources"
+
+	errFmtGetLive  = "cannot get live composed resource %q"
+	errFmtDryRun   = "cannot dry-run apply composed resource %q"
+	errFmtDiff     = "cannot diff composed resource %q"
+	errMarshalDiff = "cannot marshal composed resource"
+)
+
+// DiffType is the type of change applying a rendered composed resource would
+// make to a control plane.
+type DiffType string
+
+// Types of change.
+const (
+	// DiffTypeAdded indicates the composed resource does not exist yet.
+	DiffTypeAdded DiffType = "Added"
+	// DiffTypeModified indicates the composed resource exists and would be
+	// changed.
+	DiffTypeModified DiffType = "Modified"
+	// DiffTypeUnchanged indicates the composed resource exists and would not
+	// be changed.
+	DiffTypeUnchanged DiffType = "Unchanged"
+	// DiffTypeRemoved indicates the composed resource exists but would no
+	// longer be composed.
+	DiffTypeRemoved DiffType = "Removed"
+)
+
+// ResourceDiff is the difference between a rendered composed resource and
+// the composed resource in a control plane.
+type ResourceDiff struct {
+	// Name of the Composition template or Function pipeline resource the
+	// composed resource was rendered from.
+	Name string
+	// Type of change.
+	Type DiffType
+	// Live is the composed resource in the control plane, or as rendered by
+	// the Composition compared against. It is nil if the composed resource
+	// does not exist yet.
+	Live *unstructured.Unstructured
+	// Desired is the composed resource as the control plane would store it
+	// once the rendered composed resource was applied. It is nil if the
+	// composed resource would be removed.
+	Desired *unstructured.Unstructured
+	// Diff is a unified diff of the YAML of Live and Desired. Changes to
+	// spec.forProvider and spec.initProvider of observe-only composed
+	// resources are omitted.
+	Diff string
+	// ObserveOnly indicates Crossplane would neither create nor update the
+	// external resource of the composed resource, per its management
+	// policies.
+	ObserveOnly bool
+	// Orphan indicates the external resource of a removed composed resource
+	// would be kept, per its management or deletion policy.
+	Orphan bool
+}
+
+// DiffOption modifies a Differ.
+type DiffOption func(*Differ)
+
+// WithFieldOwner sets the field manager rendered composed resources are
+// applied with. Fields owned by other managers are only reported as changed
+// if the rendered composed resource sets them.
+func WithFieldOwner(owner string) DiffOption {
+	return func(d *Differ) {
+		d.owner = owner
+	}
+}
+
+// Differ compares rendered composed resources against the composed resources
+// in a control plane.
+type Differ struct {
+	kube  client.Client
+	owner string
+}
+
+// NewDiffer returns a Differ that reads from and dry-run applies to the
+// control plane c is connected to.
+func NewDiffer(c client.Client, opts ...DiffOption) *Differ {
+	d := &Differ{
+		kube:  c,
+		owner: DefaultFieldOwner,
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// Diff compares each composed resource in the supplied Output to the one in
+// the control plane. Rendered composed resources are matched to the composed
+// resources of the composite resource in the control plane the way Crossplane
+// matches them, by the crossplane.io/composition-resource-name annotation of
+// the resources in its spec.resourceRefs, so that composed resources with
+// generated names are found. Those that can't be matched are looked up by
+// name, if they have one. Existing composed resources are server-side applied
+// in dry-run mode so that the diff only includes the changes the control plane
+// would actually make, accounting for defaulting, admission and fields owned
+// by other managers. Composed resources of the composite resource in the
+// control plane that would be garbage collected because they were composed
+// from templates that no longer exist are returned as removed. Nothing is
+// persisted.
+func (d *Differ) Diff(ctx context.Context, out *Output) ([]ResourceDiff, error) {
+	xr, err := d.liveXR(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+	ct := make([]xpextv1.ComposedTemplate, len(out.ComposedResources))
+	for i := range out.ComposedResources {
+		ct[i] = xpextv1.ComposedTemplate{Name: &out.ComposedResources[i].Name}
+	}
+	a := icomposite.NewGarbageCollectingAssociator(icomposite.WithComposedResourceReader(d.kube))
+
+	refs := map[string]corev1.ObjectReference{}
+	if xr != nil {
+		tas, err := a.AssociateTemplates(ctx, xr, ct)
+		if err != nil {
+			return nil, errors.Wrap(err, errAssociate)
+		}
+		for _, ta := range tas {
+			if ta.Template.Name != nil && ta.Reference.Name != "" {
+				refs[*ta.Template.Name] = ta.Reference
+			}
+		}
+	}
+
+	diffs := make([]ResourceDiff, 0, len(out.ComposedResources))
+	for _, cd := range out.ComposedResources {
+		rd, err := d.diff(ctx, cd, refs[cd.Name])
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, rd)
+	}
+
+	if xr == nil {
+		return diffs, nil
+	}
+	pds, err := a.PlannedDeletions(ctx, xr, ct)
+	if err != nil {
+		return nil, errors.Wrap(err, errPlanDeletions)
+	}
+	for _, pd := range pds {
+		rd := ResourceDiff{Name: string(pd.ResourceName), Type: DiffTypeRemoved, Live: &pd.Resource.Unstructured}
+		rd.ObserveOnly = observeOnly(rd.Live)
+		rd.Orphan = orphans(rd.Live)
+		if rd.Diff, err = unifiedDiff("live", "desired", rd.Live, nil); err != nil {
+			return nil, errors.Wrapf(err, errFmtDiff, rd.Name)
+		}
+		diffs = append(diffs, rd)
+	}
+	return diffs, nil
+}
+
+// liveXR returns the composite resource of the supplied Output as it exists
+// in the control plane, or nil if it doesn't exist.
+func (d *Differ) liveXR(ctx context.Context, out *Output) (*composite.Unstructured, error) {
+	if out.CompositeResource == nil || out.CompositeResource.GetName() == "" {
+		return nil, nil
+	}
+	xr := composite.New(composite.WithGroupVersionKind(out.CompositeResource.GroupVersionKind()))
+	err := d.kube.Get(ctx, client.ObjectKeyFromObject(out.CompositeResource), xr)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errGetLiveXR)
+	}
+	return xr, nil
+}
+
+// diff compares the supplied rendered composed resource to the live composed
+// resource ref refers to or, if ref is empty, to the live composed resource
+// with the same name.
+func (d *Differ) diff(ctx context.Context, cd ComposedResource, ref corev1.ObjectReference) (ResourceDiff, error) {
+	rd := ResourceDiff{Name: cd.Name, Type: DiffTypeAdded, Desired: cd.Resource.DeepCopy(), ObserveOnly: observeOnly(cd.Resource)}
+
+	key := client.ObjectKeyFromObject(cd.Resource)
+	if ref.Name != "" {
+		key = client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	}
+	// A composed resource that has neither a name nor a live counterpart
+	// would be named by the API server when created, so it can't exist yet.
+	if key.Name != "" {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(cd.Resource.GroupVersionKind())
+		err := d.kube.Get(ctx, key, live)
+		if client.IgnoreNotFound(err) != nil {
+			return ResourceDiff{}, errors.Wrapf(err, errFmtGetLive, cd.Name)
+		}
+		if err == nil {
+			rd.Live = live
+		}
+	}
+
+	if rd.Live != nil {
+		// Crossplane updates the composed resource it already created rather
+		// than the one the rendered name or generateName would produce.
+		desired := cd.Resource.DeepCopy()
+		desired.SetName(rd.Live.GetName())
+		desired.SetGenerateName(rd.Live.GetGenerateName())
+		desired.SetResourceVersion("")
+		desired.SetManagedFields(nil)
+		if err := d.kube.Patch(ctx, desired, client.Apply, client.DryRunAll, client.FieldOwner(d.owner), client.ForceOwnership); err != nil {
+			return ResourceDiff{}, errors.Wrapf(err, errFmtDryRun, cd.Name)
+		}
+		rd.Desired = desired
+	}
+
+	// The provider never applies spec.forProvider or spec.initProvider of an
+	// observe-only composed resource that already exists, so changes to them
+	// are not proposed.
+	live, desired := rd.Live, rd.Desired
+	if rd.ObserveOnly && rd.Live != nil {
+		live, desired = withoutProviderSpec(live), withoutProviderSpec(desired)
+	}
+	diff, err := unifiedDiff("live", "desired", live, desired)
+	if err != nil {
+		return ResourceDiff{}, errors.Wrapf(err, errFmtDiff, cd.Name)
+	}
+	rd.Diff = diff
+	if rd.Live != nil {
+		rd.Type = DiffTypeModified
+		if diff == "" {
+			rd.Type = DiffTypeUnchanged
+		}
+	}
+	return rd, nil
+}
+
+// unifiedDiff returns a unified diff of the YAML of the supplied objects,
+// ignoring metadata the API server changes on every write.
+func unifiedDiff(fromName, toName string, from, to *unstructured.Unstructured) (string, error) {
+	a, err := diffableYAML(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := diffableYAML(to)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+func diffableYAML(u *unstructured.Unstructured) (string, error) {
+	if u == nil {
+		return "", nil
+	}
+	u = u.DeepCopy()
+	u.SetManagedFields(nil)
+	u.SetResourceVersion("")
+	u.SetGeneration(0)
+	b, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return "", errors.Wrap(err, errMarshalDiff)
+	}
+	return string(b), nil
+}
+
+// splitLines splits s into lines, keeping their line endings. Unlike
+// difflib.SplitLines it doesn't add an empty last line.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
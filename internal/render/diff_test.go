@@ -0,0 +1,383 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestDiff(t *testing.T) {
+	errBoom := errors.New("boom")
+	bucket := func(name, region string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "s3.aws.upbound.io/v1beta1",
+			"kind":       "Bucket",
+			"spec":       map[string]any{"forProvider": map[string]any{"region": region}},
+		}}
+		if name == "" {
+			u.SetGenerateName("example-")
+			return u
+		}
+		u.SetName(name)
+		return u
+	}
+	live := func(region string) *unstructured.Unstructured {
+		u := bucket("example", region)
+		u.SetResourceVersion("42")
+		u.SetGeneration(3)
+		return u
+	}
+	// rendered is a composed resource rendered from the "bucket" template,
+	// whose name would be generated by the API server.
+	rendered := func(region string) *unstructured.Unstructured {
+		u := bucket("", region)
+		u.SetAnnotations(map[string]string{"crossplane.io/composition-resource-name": "bucket"})
+		return u
+	}
+	// generated is the live composed resource rendered from the "bucket"
+	// template, named by the API server.
+	generated := func(region string) *unstructured.Unstructured {
+		u := rendered(region)
+		u.SetName("example-x7k2p")
+		u.SetResourceVersion("42")
+		return u
+	}
+	// observeOnly makes the supplied composed resource observe its external
+	// resource without creating or updating it.
+	observeOnly := func(u *unstructured.Unstructured) *unstructured.Unstructured {
+		_ = unstructured.SetNestedStringSlice(u.Object, []string{"Observe", "LateInitialize"}, "spec", "managementPolicies")
+		return u
+	}
+	getLive := func(region string) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			live(region).DeepCopyInto(obj.(*unstructured.Unstructured))
+			return nil
+		}
+	}
+	xr := func() *unstructured.Unstructured {
+		xr := composite.New(composite.WithGroupVersionKind(schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XBucket"}))
+		xr.SetName("xr")
+		xr.SetUID("xr-uid")
+		xr.SetResourceReferences([]corev1.ObjectReference{
+			{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "BucketPolicy", Name: "policy"},
+			{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "BucketPolicy", Name: "acl"},
+		})
+		return &xr.Unstructured
+	}
+	// policy is a composed resource whose template no longer exists.
+	policy := func(controller types.UID) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("s3.aws.upbound.io/v1beta1")
+		u.SetKind("BucketPolicy")
+		u.SetAnnotations(map[string]string{"crossplane.io/composition-resource-name": "policy"})
+		u.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "example.org/v1alpha1", Kind: "XBucket", Name: "xr", UID: controller, Controller: pointer.Bool(true)}})
+		return u
+	}
+	// dryRun echoes the applied object back, as if the API server stored it.
+	dryRun := func(_ context.Context, obj client.Object, p client.Patch, opts ...client.PatchOption) error {
+		if p != client.Apply {
+			return errors.New("not an apply patch")
+		}
+		po := &client.PatchOptions{}
+		po.ApplyOptions(opts)
+		if len(po.DryRun) == 0 {
+			return errors.New("not a dry run")
+		}
+		obj.SetResourceVersion("43")
+		return nil
+	}
+
+	type want struct {
+		diffs []ResourceDiff
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		kube   client.Client
+		out    *Output
+		want   want
+	}{
+		"GenerateNameOnly": {
+			reason: "A composed resource without a name that the live composite resource doesn't compose should be added.",
+			kube:   &test.MockClient{},
+			out:    &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: bucket("", "us-east-1")}}},
+			want: want{diffs: []ResourceDiff{{
+				Name:    "bucket",
+				Type:    DiffTypeAdded,
+				Desired: bucket("", "us-east-1"),
+				Diff: `--- live
++++ desired
+@@ -0,0 +1,7 @@
++apiVersion: s3.aws.upbound.io/v1beta1
++kind: Bucket
++metadata:
++  generateName: example-
++spec:
++  forProvider:
++    region: us-east-1
+`,
+			}}},
+		},
+		"GeneratedName": {
+			reason: "A composed resource without a name should be matched to the live composed resource through the live composite resource's references.",
+			kube: &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					xr := composite.New(composite.WithGroupVersionKind(schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XBucket"}))
+					xr.SetName("xr")
+					xr.SetUID("xr-uid")
+					xr.SetResourceReferences([]corev1.ObjectReference{{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "example-x7k2p"}})
+					objs := map[string]*unstructured.Unstructured{
+						"xr":            &xr.Unstructured,
+						"example-x7k2p": generated("us-west-2"),
+					}
+					o, ok := objs[key.Name]
+					if !ok {
+						return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+					}
+					obj.(runtime.Unstructured).SetUnstructuredContent(o.DeepCopy().Object)
+					return nil
+				},
+				MockPatch: dryRun,
+			},
+			out: &Output{
+				CompositeResource: xr(),
+				ComposedResources: []ComposedResource{{Name: "bucket", Resource: rendered("us-east-1")}},
+			},
+			want: want{diffs: []ResourceDiff{{
+				Name: "bucket",
+				Type: DiffTypeModified,
+				Live: generated("us-west-2"),
+				Desired: func() *unstructured.Unstructured {
+					u := rendered("us-east-1")
+					u.SetName("example-x7k2p")
+					u.SetResourceVersion("43")
+					return u
+				}(),
+				Diff: `--- live
++++ desired
+@@ -7,4 +7,4 @@
+   name: example-x7k2p
+ spec:
+   forProvider:
+-    region: us-west-2
++    region: us-east-1
+`,
+			}}},
+		},
+		"NotFound": {
+			reason: "A composed resource that does not exist should be added.",
+			kube: &test.MockClient{
+				MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "example")),
+			},
+			out: &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: bucket("example", "us-east-1")}}},
+			want: want{diffs: []ResourceDiff{{
+				Name:    "bucket",
+				Type:    DiffTypeAdded,
+				Desired: bucket("example", "us-east-1"),
+				Diff: `--- live
++++ desired
+@@ -0,0 +1,7 @@
++apiVersion: s3.aws.upbound.io/v1beta1
++kind: Bucket
++metadata:
++  name: example
++spec:
++  forProvider:
++    region: us-east-1
+`,
+			}}},
+		},
+		"Modified": {
+			reason: "A composed resource that would be changed should be diffed against the result of a dry-run apply.",
+			kube: &test.MockClient{
+				MockGet:   getLive("us-west-2"),
+				MockPatch: dryRun,
+			},
+			out: &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: bucket("example", "us-east-1")}}},
+			want: want{diffs: []ResourceDiff{{
+				Name: "bucket",
+				Type: DiffTypeModified,
+				Live: live("us-west-2"),
+				Desired: func() *unstructured.Unstructured {
+					u := bucket("example", "us-east-1")
+					u.SetResourceVersion("43")
+					return u
+				}(),
+				Diff: `--- live
++++ desired
+@@ -4,4 +4,4 @@
+   name: example
+ spec:
+   forProvider:
+-    region: us-west-2
++    region: us-east-1
+`,
+			}}},
+		},
+		"Unchanged": {
+			reason: "A composed resource that would not be changed should have an empty diff.",
+			kube: &test.MockClient{
+				MockGet:   getLive("us-east-1"),
+				MockPatch: dryRun,
+			},
+			out: &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: bucket("example", "us-east-1")}}},
+			want: want{diffs: []ResourceDiff{{
+				Name: "bucket",
+				Type: DiffTypeUnchanged,
+				Live: live("us-east-1"),
+				Desired: func() *unstructured.Unstructured {
+					u := bucket("example", "us-east-1")
+					u.SetResourceVersion("43")
+					return u
+				}(),
+			}}},
+		},
+		"ObserveOnly": {
+			reason: "Changes to spec.forProvider of an observe-only composed resource should not be proposed.",
+			kube: &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					observeOnly(live("us-west-2")).DeepCopyInto(obj.(*unstructured.Unstructured))
+					return nil
+				},
+				MockPatch: dryRun,
+			},
+			out: &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: observeOnly(bucket("example", "us-east-1"))}}},
+			want: want{diffs: []ResourceDiff{{
+				Name: "bucket",
+				Type: DiffTypeUnchanged,
+				Live: observeOnly(live("us-west-2")),
+				Desired: func() *unstructured.Unstructured {
+					u := observeOnly(bucket("example", "us-east-1"))
+					u.SetResourceVersion("43")
+					return u
+				}(),
+				ObserveOnly: true,
+			}}},
+		},
+		"BecomesObserveOnly": {
+			reason: "Changes to the management policies of a composed resource should be proposed.",
+			kube: &test.MockClient{
+				MockGet:   getLive("us-west-2"),
+				MockPatch: dryRun,
+			},
+			out: &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: observeOnly(bucket("example", "us-east-1"))}}},
+			want: want{diffs: []ResourceDiff{{
+				Name: "bucket",
+				Type: DiffTypeModified,
+				Live: live("us-west-2"),
+				Desired: func() *unstructured.Unstructured {
+					u := observeOnly(bucket("example", "us-east-1"))
+					u.SetResourceVersion("43")
+					return u
+				}(),
+				Diff: `--- live
++++ desired
+@@ -2,3 +2,7 @@
+ kind: Bucket
+ metadata:
+   name: example
++spec:
++  managementPolicies:
++  - Observe
++  - LateInitialize
+`,
+				ObserveOnly: true,
+			}}},
+		},
+		"GarbageCollected": {
+			reason: "Composed resources of the live composite resource whose templates no longer exist should be removed.",
+			kube: &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					objs := map[string]*unstructured.Unstructured{
+						"xr":      xr(),
+						"example": live("us-east-1"),
+						"policy":  policy("xr-uid"),
+						"acl":     policy("other-uid"),
+					}
+					obj.(runtime.Unstructured).SetUnstructuredContent(objs[key.Name].DeepCopy().Object)
+					return nil
+				},
+				MockPatch: dryRun,
+			},
+			out: &Output{
+				CompositeResource: xr(),
+				ComposedResources: []ComposedResource{{Name: "bucket", Resource: bucket("example", "us-east-1")}},
+			},
+			want: want{diffs: []ResourceDiff{
+				{
+					Name: "bucket",
+					Type: DiffTypeUnchanged,
+					Live: live("us-east-1"),
+					Desired: func() *unstructured.Unstructured {
+						u := bucket("example", "us-east-1")
+						u.SetResourceVersion("43")
+						return u
+					}(),
+				},
+				{
+					Name: "policy",
+					Type: DiffTypeRemoved,
+					Live: policy("xr-uid"),
+					Diff: "--- live\n+++ desired\n@@ -1,11 +0,0 @@\n-apiVersion: s3.aws.upbound.io/v1beta1\n-kind: BucketPolicy\n-metadata:\n-  annotations:\n-    crossplane.io/composition-resource-name: policy\n-  ownerReferences:\n-  - apiVersion: example.org/v1alpha1\n-    controller: true\n-    kind: XBucket\n-    name: xr\n-    uid: xr-uid\n",
+				},
+			}},
+		},
+		"GetError": {
+			reason: "Errors getting a live composed resource should be returned.",
+			kube: &test.MockClient{
+				MockGet: test.NewMockGetFn(errBoom),
+			},
+			out:  &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: bucket("example", "us-east-1")}}},
+			want: want{err: errors.Wrapf(errBoom, errFmtGetLive, "bucket")},
+		},
+		"DryRunError": {
+			reason: "Errors dry-run applying a composed resource should be returned.",
+			kube: &test.MockClient{
+				MockGet:   getLive("us-west-2"),
+				MockPatch: test.NewMockPatchFn(errBoom),
+			},
+			out:  &Output{ComposedResources: []ComposedResource{{Name: "bucket", Resource: bucket("example", "us-east-1")}}},
+			want: want{err: errors.Wrapf(errBoom, errFmtDryRun, "bucket")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diffs, err := NewDiffer(tc.kube).Diff(context.Background(), tc.out)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.diffs, diffs); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
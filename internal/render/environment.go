@@ -0,0 +1,78 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/spf13/afero"
+	apimachyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+const (
+	errFmtReadEnvironmentConfigs  = "cannot read EnvironmentConfigs from %q"
+	errFmtParseEnvironmentConfigs = "cannot parse EnvironmentConfigs from %q"
+	errFmtNotEnvironmentConfig    = "%s %q in %q is not an EnvironmentConfig"
+)
+
+// LoadEnvironmentConfigs reads EnvironmentConfigs from the supplied YAML
+// files. Each file may contain multiple YAML documents. EnvironmentConfigs are
+// returned in the order they appear.
+func LoadEnvironmentConfigs(fs afero.Fs, paths ...string) ([]*v1alpha1.EnvironmentConfig, error) {
+	cfgs := []*v1alpha1.EnvironmentConfig{}
+	for _, p := range paths {
+		b, err := afero.ReadFile(fs, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtReadEnvironmentConfigs, p)
+		}
+		c, err := parseEnvironmentConfigs(p, b)
+		if err != nil {
+			return nil, err
+		}
+		cfgs = append(cfgs, c...)
+	}
+	return cfgs, nil
+}
+
+func parseEnvironmentConfigs(path string, b []byte) ([]*v1alpha1.EnvironmentConfig, error) {
+	cfgs := []*v1alpha1.EnvironmentConfig{}
+	yr := apimachyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+	for {
+		doc, err := yr.Read()
+		if errors.Is(err, io.EOF) {
+			return cfgs, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtParseEnvironmentConfigs, path)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		cfg := &v1alpha1.EnvironmentConfig{}
+		if err := yaml.Unmarshal(doc, cfg); err != nil {
+			return nil, errors.Wrapf(err, errFmtParseEnvironmentConfigs, path)
+		}
+		if cfg.GroupVersionKind() != v1alpha1.EnvironmentConfigGroupVersionKind {
+			return nil, errors.Errorf(errFmtNotEnvironmentConfig, cfg.GroupVersionKind().Kind, cfg.GetName(), path)
+		}
+		cfgs = append(cfgs, cfg)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestLoadEnvironmentConfigs(t *testing.T) {
+	type want struct {
+		names []string
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		files  map[string]string
+		paths  []string
+		want   want
+	}{
+		"MultipleDocuments": {
+			reason: "EnvironmentConfigs should be read from every document of every file, in order.",
+			files: map[string]string{
+				"/a.yaml": string(readFile(t, "environment-configs.yaml")),
+				"/b.yaml": "---\napiVersion: apiextensions.crossplane.io/v1alpha1\nkind: EnvironmentConfig\nmetadata:\n  name: other\n",
+			},
+			paths: []string{"/b.yaml", "/a.yaml"},
+			want:  want{names: []string{"other", "region", "tags"}},
+		},
+		"NotEnvironmentConfig": {
+			reason: "A document that is not an EnvironmentConfig should return an error.",
+			files: map[string]string{
+				"/a.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n",
+			},
+			paths: []string{"/a.yaml"},
+			want:  want{err: errors.Errorf(errFmtNotEnvironmentConfig, "ConfigMap", "cm", "/a.yaml")},
+		},
+		"MissingFile": {
+			reason: "A file that does not exist should return an error.",
+			paths:  []string{"/missing.yaml"},
+			want:   want{err: errors.Wrapf(errors.New("open /missing.yaml: file does not exist"), errFmtReadEnvironmentConfigs, "/missing.yaml")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for p, c := range tc.files {
+				if err := afero.WriteFile(fs, p, []byte(c), 0o600); err != nil {
+					t.Fatalf("WriteFile(...): %s", err)
+				}
+			}
+			cfgs, err := LoadEnvironmentConfigs(fs, tc.paths...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoadEnvironmentConfigs(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			var names []string
+			for _, c := range cfgs {
+				names = append(names, c.GetName())
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("\n%s\nLoadEnvironmentConfigs(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRenderEnvironment(t *testing.T) {
+	type want struct {
+		region string
+		refs   []corev1.ObjectReference
+		bucket map[string]any
+	}
+	cases := map[string]struct {
+		reason string
+		refs   []string
+		want   want
+	}{
+		"AllEnvironmentConfigs": {
+			reason: "A composite resource without references should be patched from all EnvironmentConfigs merged over the Composition's default data.",
+			want: want{
+				region: "eu-central-1",
+				refs:   []corev1.ObjectReference{envConfigRef("region"), envConfigRef("tags")},
+				bucket: map[string]any{
+					"region": "eu-central-1",
+					"tags":   map[string]any{"team": "platform", "env": "prod"},
+				},
+			},
+		},
+		"ReferencedEnvironmentConfigs": {
+			reason: "A composite resource with references should be patched only from the referenced EnvironmentConfigs.",
+			refs:   []string{"tags"},
+			want: want{
+				region: "us-east-1",
+				refs:   []corev1.ObjectReference{envConfigRef("tags")},
+				bucket: map[string]any{
+					"region": "us-east-1",
+					"tags":   map[string]any{"team": "platform", "env": "prod"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in, err := ParseInputs(readFile(t, "composition-environment.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+			if err != nil {
+				t.Fatalf("ParseInputs(...): %s", err)
+			}
+			if len(tc.refs) > 0 {
+				refs := make([]any, len(tc.refs))
+				for i, n := range tc.refs {
+					refs[i] = map[string]any{"apiVersion": "apiextensions.crossplane.io/v1alpha1", "kind": "EnvironmentConfig", "name": n}
+				}
+				_ = unstructured.SetNestedSlice(in.XR.Object, refs, "spec", "environmentConfigRefs")
+			}
+			cfgs, err := LoadEnvironmentConfigs(afero.NewOsFs(), "testdata/environment-configs.yaml")
+			if err != nil {
+				t.Fatalf("LoadEnvironmentConfigs(...): %s", err)
+			}
+
+			out, err := NewRenderer(WithEnvironmentConfigs(cfgs...)).Render(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Render(...): %s", err)
+			}
+
+			region, _, _ := unstructured.NestedString(out.CompositeResource.Object, "spec", "region")
+			if diff := cmp.Diff(tc.want.region, region); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want XR region, +got XR region:\n%s", tc.reason, diff)
+			}
+			refs, _, _ := unstructured.NestedSlice(out.CompositeResource.Object, "spec", "environmentConfigRefs")
+			got := make([]corev1.ObjectReference, len(refs))
+			for i, r := range refs {
+				m := r.(map[string]any)
+				got[i] = corev1.ObjectReference{APIVersion: m["apiVersion"].(string), Kind: m["kind"].(string), Name: m["name"].(string)}
+			}
+			if diff := cmp.Diff(tc.want.refs, got); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want XR environment refs, +got XR environment refs:\n%s", tc.reason, diff)
+			}
+			bucket, _, _ := unstructured.NestedMap(out.ComposedResources[0].Resource.Object, "spec", "forProvider")
+			if diff := cmp.Diff(tc.want.bucket, bucket); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want bucket, +got bucket:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff("platform", out.ComposedResources[0].Resource.GetLabels()["example.org/owner"]); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want owner label, +got owner label:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func envConfigRef(name string) corev1.ObjectReference {
+	return corev1.ObjectReference{APIVersion: "apiextensions.crossplane.io/v1alpha1", Kind: "EnvironmentConfig", Name: name}
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/xcrd"
+)
+
+const (
+	// ExampleName is the name of generated examples.
+	ExampleName = "example"
+	// ExampleNamespace is the namespace of generated example claims.
+	ExampleNamespace = "default"
+
+	errNoClaimNames     = "CompositeResourceDefinition does not offer a claim"
+	errDeriveClaimCRD   = "cannot derive CustomResourceDefinition from CompositeResourceDefinition claim"
+	errFmtNoVersion     = "CompositeResourceDefinition %q does not serve a version"
+	errFmtVersionSchema = "version %q of CompositeResourceDefinition %q has no schema"
+	errFmtParseDefault  = "cannot parse default of %q"
+)
+
+// An ExampleOption modifies how an example is generated.
+type ExampleOption func(*exampleOpts)
+
+type exampleOpts struct {
+	claim   bool
+	version string
+}
+
+// WithClaim generates an example claim rather than a composite resource.
+func WithClaim() ExampleOption {
+	return func(o *exampleOpts) {
+		o.claim = true
+	}
+}
+
+// WithVersion generates an example of the supplied version. The referenceable
+// version is used by default.
+func WithVersion(v string) ExampleOption {
+	return func(o *exampleOpts) {
+		o.version = v
+	}
+}
+
+// GenerateExample generates an example composite resource or claim of the
+// kind defined by the supplied XRD. The example sets every required field of
+// the XRD's schema, recursively, using the field's default, its first enum
+// value or otherwise a plausible value of its type and format. Optional
+// fields are omitted.
+func GenerateExample(xrd *xpextv1.CompositeResourceDefinition, opts ...ExampleOption) (*unstructured.Unstructured, error) {
+	o := &exampleOpts{}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	kind := xrd.Spec.Names.Kind
+	derive := xcrd.ForCompositeResource
+	if o.claim {
+		if xrd.Spec.ClaimNames == nil {
+			return nil, errors.New(errNoClaimNames)
+		}
+		kind = xrd.Spec.ClaimNames.Kind
+		derive = xcrd.ForCompositeResourceClaim
+	}
+	crd, err := derive(xrd)
+	if err != nil {
+		if o.claim {
+			return nil, errors.Wrap(err, errDeriveClaimCRD)
+		}
+		return nil, errors.Wrap(err, errDeriveCRD)
+	}
+
+	v := exampleVersion(xrd, crd, o.version)
+	if v == nil {
+		return nil, errors.Errorf(errFmtNoVersion, xrd.GetName())
+	}
+	if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+		return nil, errors.Errorf(errFmtVersionSchema, v.Name, xrd.GetName())
+	}
+
+	ex := &unstructured.Unstructured{Object: map[string]any{}}
+	if s, ok := v.Schema.OpenAPIV3Schema.Properties["spec"]; ok {
+		spec, err := exampleValue("spec", &s)
+		if err != nil {
+			return nil, err
+		}
+		ex.Object["spec"] = spec
+	}
+	ex.SetAPIVersion(xrd.Spec.Group + "/" + v.Name)
+	ex.SetKind(kind)
+	ex.SetName(ExampleName)
+	if o.claim {
+		ex.SetNamespace(ExampleNamespace)
+	}
+	return ex, nil
+}
+
+// exampleVersion returns the supplied served version, or the referenceable
+// version if none is supplied.
+func exampleVersion(xrd *xpextv1.CompositeResourceDefinition, crd *extv1.CustomResourceDefinition, version string) *extv1.CustomResourceDefinitionVersion {
+	if version == "" {
+		for _, v := range xrd.Spec.Versions {
+			if v.Referenceable {
+				version = v.Name
+			}
+		}
+	}
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == version && crd.Spec.Versions[i].Served {
+			return &crd.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+// exampleValue returns a plausible value of the field at the supplied path
+// with the supplied schema.
+func exampleValue(path string, s *extv1.JSONSchemaProps) (any, error) { //nolint:gocyclo // Only a switch over types.
+	if s.Default != nil {
+		var v any
+		if err := json.Unmarshal(s.Default.Raw, &v); err != nil {
+			return nil, errors.Wrapf(err, errFmtParseDefault, path)
+		}
+		return v, nil
+	}
+	if len(s.Enum) > 0 {
+		var v any
+		if err := json.Unmarshal(s.Enum[0].Raw, &v); err != nil {
+			return nil, errors.Wrapf(err, errFmtParseDefault, path)
+		}
+		return v, nil
+	}
+
+	switch s.Type {
+	case "object":
+		obj := map[string]any{}
+		required := append([]string{}, s.Required...)
+		sort.Strings(required)
+		for _, name := range required {
+			p, ok := s.Properties[name]
+			if !ok {
+				continue
+			}
+			v, err := exampleValue(path+"."+name, &p)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+		}
+		return obj, nil
+	case "array":
+		arr := []any{}
+		if s.Items == nil || s.Items.Schema == nil {
+			return arr, nil
+		}
+		n := int64(1)
+		if s.MinItems != nil && *s.MinItems > n {
+			n = *s.MinItems
+		}
+		for i := int64(0); i < n; i++ {
+			v, err := exampleValue(path+"[]", s.Items.Schema)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case "integer":
+		if s.Minimum != nil {
+			return int64(*s.Minimum), nil
+		}
+		return int64(1), nil
+	case "number":
+		if s.Minimum != nil {
+			return *s.Minimum, nil
+		}
+		return float64(1), nil
+	case "boolean":
+		return false, nil
+	case "string":
+		return exampleString(path, s), nil
+	}
+
+	// Fields without a type, such as those that preserve unknown fields, are
+	// set to an empty object.
+	return map[string]any{}, nil
+}
+
+// exampleString returns a plausible string for the supplied schema.
+func exampleString(path string, s *extv1.JSONSchemaProps) string {
+	var v string
+	switch s.Format {
+	case "date-time":
+		v = "2006-01-02T15:04:05Z"
+	case "date":
+		v = "2006-01-02"
+	case "email":
+		v = "example@example.org"
+	case "uri", "url":
+		v = "https://example.org"
+	case "hostname":
+		v = "example.org"
+	case "ipv4":
+		v = "192.0.2.1"
+	case "ipv6":
+		v = "2001:db8::1"
+	case "uuid":
+		v = "00000000-0000-0000-0000-000000000000"
+	default:
+		v = path[strings.LastIndex(path, ".")+1:]
+	}
+	if s.MinLength != nil && int64(len(v)) < *s.MinLength {
+		v += strings.Repeat("x", int(*s.MinLength)-len(v))
+	}
+	if s.MaxLength != nil && int64(len(v)) > *s.MaxLength {
+		v = v[:*s.MaxLength]
+	}
+	return v
+}
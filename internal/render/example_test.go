@@ -0,0 +1,122 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestGenerateExample(t *testing.T) {
+	spec := map[string]any{
+		"engine":    "postgres",
+		"storageGB": int64(20),
+		"admin":     "example@example.org",
+		"replicas": []any{
+			map[string]any{"region": "us-east-1"},
+			map[string]any{"region": "us-east-1"},
+		},
+		"network": map[string]any{
+			"name":   "namexx",
+			"public": false,
+		},
+	}
+
+	type want struct {
+		ex  *unstructured.Unstructured
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		file   string
+		opts   []ExampleOption
+		want   want
+	}{
+		"CompositeResource": {
+			reason: "An example composite resource of the referenceable version should set every required field.",
+			file:   "xrd-required.yaml",
+			want: want{ex: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1beta1",
+				"kind":       "XDatabase",
+				"metadata":   map[string]any{"name": "example"},
+				"spec":       spec,
+			}}},
+		},
+		"Claim": {
+			reason: "An example claim should be namespaced.",
+			file:   "xrd-required.yaml",
+			opts:   []ExampleOption{WithClaim()},
+			want: want{ex: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1beta1",
+				"kind":       "Database",
+				"metadata":   map[string]any{"name": "example", "namespace": "default"},
+				"spec":       spec,
+			}}},
+		},
+		"Version": {
+			reason: "An example of the supplied version should be generated.",
+			file:   "xrd-required.yaml",
+			opts:   []ExampleOption{WithVersion("v1alpha1")},
+			want: want{ex: &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "example.org/v1alpha1",
+				"kind":       "XDatabase",
+				"metadata":   map[string]any{"name": "example"},
+				"spec":       map[string]any{},
+			}}},
+		},
+		"VersionNotServed": {
+			reason: "An error should be returned if the supplied version is not served.",
+			file:   "xrd-required.yaml",
+			opts:   []ExampleOption{WithVersion("v1")},
+			want:   want{err: errors.Errorf(errFmtNoVersion, "xdatabases.example.org")},
+		},
+		"NoClaim": {
+			reason: "An error should be returned if a claim is requested of an XRD that does not offer one.",
+			file:   "xrd.yaml",
+			opts:   []ExampleOption{WithClaim()},
+			want:   want{err: errors.New(errNoClaimNames)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			xrd := &xpextv1.CompositeResourceDefinition{}
+			if err := yaml.Unmarshal(readFile(t, tc.file), xrd); err != nil {
+				t.Fatalf("Unmarshal(...): %s", err)
+			}
+
+			ex, err := GenerateExample(xrd, tc.opts...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGenerateExample(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ex, ex); diff != "" {
+				t.Errorf("\n%s\nGenerateExample(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if ex == nil || ex.GetNamespace() != "" {
+				return
+			}
+			if err := defaultAndValidate(xrd, ex.GroupVersionKind(), ex.DeepCopy().Object); err != nil {
+				t.Errorf("\n%s\nGenerateExample(...): generated example is invalid: %s", tc.reason, err)
+			}
+		})
+	}
+}
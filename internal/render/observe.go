@@ -0,0 +1,127 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+const (
+	errParseObserved   = "cannot parse observed composed resources"
+	errFmtObservedName = "observed composed resource at index %d has no name"
+	errFmtExtractConn  = "cannot extract connection details of composed resource %q"
+)
+
+// An ObservedResource is the fake observed state of a composed resource. It
+// stands in for the state a provider would report once the composed resource
+// was created.
+type ObservedResource struct {
+	// Name of the Composition template the composed resource was rendered
+	// from.
+	Name string `json:"name"`
+	// Status of the composed resource.
+	Status map[string]any `json:"status,omitempty"`
+	// ConnectionDetails the composed resource wrote to its connection
+	// secret.
+	ConnectionDetails map[string]string `json:"connectionDetails,omitempty"`
+}
+
+// ParseObservedResources parses ObservedResources from a YAML list.
+func ParseObservedResources(b []byte) ([]ObservedResource, error) {
+	obs := []ObservedResource{}
+	if err := yaml.Unmarshal(b, &obs); err != nil {
+		return nil, errors.Wrap(err, errParseObserved)
+	}
+	for i, o := range obs {
+		if o.Name == "" {
+			return nil, errors.Errorf(errFmtObservedName, i)
+		}
+	}
+	return obs, nil
+}
+
+// WithObservedResources sets the fake observed state of composed resources.
+// Connection details and readiness of the composite resource are evaluated
+// as if its composed resources had this state.
+func WithObservedResources(obs ...ObservedResource) Option {
+	return func(r *Renderer) {
+		r.observed = make(map[string]ObservedResource, len(obs))
+		for _, o := range obs {
+			r.observed[o.Name] = o
+		}
+	}
+}
+
+// observe evaluates the connection details and readiness checks of each
+// template of the supplied Composition against the observed state of the
+// composed resource rendered from it. Compositions in Pipeline mode are
+// skipped, because functions return connection details and readiness.
+func (r *Renderer) observe(comp *xpextv1.Composition, out *Output) error {
+	out.ConnectionDetails = managed.ConnectionDetails{}
+	if comp.Spec.Mode != nil && *comp.Spec.Mode == xpextv1.CompositionModePipeline {
+		return nil
+	}
+
+	ts, err := icomposite.ComposedTemplates(comp.Spec)
+	if err != nil {
+		return errors.Wrap(err, errTemplates)
+	}
+	out.Ready = true
+	for i := range ts {
+		// Templates are rendered in order, so the ith composed resource was
+		// rendered from the ith template.
+		if i >= len(out.ComposedResources) {
+			break
+		}
+		cd := &out.ComposedResources[i]
+		u, data := r.observedState(cd)
+
+		conn, err := icomposite.ExtractConnectionDetails(u, data, icomposite.ExtractConfigsFromComposedTemplate(&ts[i])...)
+		if err != nil {
+			return errors.Wrapf(err, errFmtExtractConn, cd.Name)
+		}
+		cd.ConnectionDetails = conn
+		for k, v := range conn {
+			out.ConnectionDetails[k] = v
+		}
+
+		cd.Ready, cd.ReadinessChecks = evaluateReadiness(u, &ts[i], cd.ObserveOnly)
+		out.Ready = out.Ready && cd.Ready
+	}
+	return nil
+}
+
+// observedState returns the supplied composed resource with its observed
+// status, and its observed connection details.
+func (r *Renderer) observedState(cd *ComposedResource) (*composed.Unstructured, managed.ConnectionDetails) {
+	u := cd.Resource.DeepCopy()
+	obs := r.observed[cd.Name]
+	if obs.Status != nil {
+		u.Object["status"] = obs.Status
+	}
+	data := managed.ConnectionDetails{}
+	for k, v := range obs.ConnectionDetails {
+		data[k] = []byte(v)
+	}
+	return &composed.Unstructured{Unstructured: unstructured.Unstructured{Object: u.Object}}, data
+}
@@ -0,0 +1,230 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestParseObservedResources(t *testing.T) {
+	type want struct {
+		obs []ObservedResource
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		yaml   string
+		want   want
+	}{
+		"Success": {
+			reason: "Observed resources should be parsed from a YAML list.",
+			yaml:   "- name: bucket\n  status:\n    ready: true\n  connectionDetails:\n    token: t0k3n\n",
+			want: want{obs: []ObservedResource{{
+				Name:              "bucket",
+				Status:            map[string]any{"ready": true},
+				ConnectionDetails: map[string]string{"token": "t0k3n"},
+			}}},
+		},
+		"MissingName": {
+			reason: "An observed resource without a name should return an error.",
+			yaml:   "- status: {}\n",
+			want:   want{err: errors.Errorf(errFmtObservedName, 0)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			obs, err := ParseObservedResources([]byte(tc.yaml))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseObservedResources(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, obs); diff != "" {
+				t.Errorf("\n%s\nParseObservedResources(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRenderConnectionDetails(t *testing.T) {
+	type want struct {
+		conn     managed.ConnectionDetails
+		composed []managed.ConnectionDetails
+	}
+	cases := map[string]struct {
+		reason   string
+		observed bool
+		want     want
+	}{
+		"Observed": {
+			reason:   "Connection details should be extracted from the observed state of each composed resource.",
+			observed: true,
+			want: want{
+				conn: managed.ConnectionDetails{
+					"endpoint": []byte("example.s3.amazonaws.com"),
+					"port":     []byte("443"),
+					"token":    []byte("t0k3n"),
+					"secret":   []byte("s3cr3t"),
+				},
+				composed: []managed.ConnectionDetails{
+					{
+						"endpoint": []byte("example.s3.amazonaws.com"),
+						"port":     []byte("443"),
+						"token":    []byte("t0k3n"),
+					},
+					{
+						"secret": []byte("s3cr3t"),
+					},
+				},
+			},
+		},
+		"NotObserved": {
+			reason: "Only fixed values should be extracted from composed resources that have no observed state.",
+			want: want{
+				conn:     managed.ConnectionDetails{"port": []byte("443")},
+				composed: []managed.ConnectionDetails{{"port": []byte("443")}, {}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in, err := ParseInputs(readFile(t, "composition-connection.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+			if err != nil {
+				t.Fatalf("ParseInputs(...): %s", err)
+			}
+			opts := []Option{}
+			if tc.observed {
+				obs, err := ParseObservedResources(readFile(t, "observed.yaml"))
+				if err != nil {
+					t.Fatalf("ParseObservedResources(...): %s", err)
+				}
+				opts = append(opts, WithObservedResources(obs...))
+			}
+
+			out, err := NewRenderer(opts...).Render(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Render(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.conn, out.ConnectionDetails); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want connection details, +got connection details:\n%s", tc.reason, diff)
+			}
+			composed := make([]managed.ConnectionDetails, len(out.ComposedResources))
+			for i, cd := range out.ComposedResources {
+				composed[i] = cd.ConnectionDetails
+			}
+			if diff := cmp.Diff(tc.want.composed, composed); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want composed connection details, +got composed connection details:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRenderReadiness(t *testing.T) {
+	type want struct {
+		ready    bool
+		composed []bool
+		checks   [][]ReadinessCheckResult
+	}
+	cases := map[string]struct {
+		reason   string
+		observed []ObservedResource
+		want     want
+	}{
+		"Ready": {
+			reason: "The composite resource should be ready if all readiness checks of all composed resources pass.",
+			observed: []ObservedResource{
+				{Name: "bucket", Status: map[string]any{"conditions": []any{map[string]any{"type": "Ready", "status": "True"}}}},
+				{Name: "policy", Status: map[string]any{"atProvider": map[string]any{"state": "Active", "id": "p"}}},
+			},
+			want: want{
+				ready:    true,
+				composed: []bool{true, true},
+				checks: [][]ReadinessCheckResult{
+					{{Type: "MatchCondition", Ready: true}},
+					{
+						{Type: "MatchString", FieldPath: "status.atProvider.state", Ready: true},
+						{Type: "NonEmpty", FieldPath: "status.atProvider.id", Ready: true},
+					},
+				},
+			},
+		},
+		"CheckFailed": {
+			reason: "The composite resource should not be ready if any readiness check fails, and the failed check should be reported.",
+			observed: []ObservedResource{
+				{Name: "bucket", Status: map[string]any{"conditions": []any{map[string]any{"type": "Ready", "status": "True"}}}},
+				{Name: "policy", Status: map[string]any{"atProvider": map[string]any{"state": "Pending", "id": "p"}}},
+			},
+			want: want{
+				composed: []bool{true, false},
+				checks: [][]ReadinessCheckResult{
+					{{Type: "MatchCondition", Ready: true}},
+					{
+						{Type: "MatchString", FieldPath: "status.atProvider.state"},
+						{Type: "NonEmpty", FieldPath: "status.atProvider.id", Ready: true},
+					},
+				},
+			},
+		},
+		"NotObserved": {
+			reason: "Composed resources without observed state should not be ready.",
+			want: want{
+				composed: []bool{false, false},
+				checks: [][]ReadinessCheckResult{
+					{{Type: "MatchCondition"}},
+					{
+						{Type: "MatchString", FieldPath: "status.atProvider.state"},
+						{Type: "NonEmpty", FieldPath: "status.atProvider.id"},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in, err := ParseInputs(readFile(t, "composition-readiness.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+			if err != nil {
+				t.Fatalf("ParseInputs(...): %s", err)
+			}
+
+			out, err := NewRenderer(WithObservedResources(tc.observed...)).Render(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Render(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.ready, out.Ready); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want ready, +got ready:\n%s", tc.reason, diff)
+			}
+			composed := make([]bool, len(out.ComposedResources))
+			checks := make([][]ReadinessCheckResult, len(out.ComposedResources))
+			for i, cd := range out.ComposedResources {
+				composed[i] = cd.Ready
+				checks[i] = cd.ReadinessChecks
+			}
+			if diff := cmp.Diff(tc.want.composed, composed); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want composed ready, +got composed ready:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.checks, checks); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want readiness checks, +got readiness checks:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
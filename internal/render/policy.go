@@ -0,0 +1,102 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+const (
+	fieldForProvider  = "spec.forProvider"
+	fieldInitProvider = "spec.initProvider"
+	fieldAtProvider   = "status.atProvider"
+)
+
+// managementPolicies returns the actions Crossplane may take on the external
+// resource of the supplied composed resource. Composed resources that don't
+// set management policies, including those that aren't managed resources,
+// are fully managed.
+func managementPolicies(u *unstructured.Unstructured) map[xpv1.ManagementAction]bool {
+	if u == nil {
+		return nil
+	}
+	mp := xpv1.ManagementPolicies{}
+	if err := fieldpath.Pave(u.Object).GetValueInto("spec.managementPolicies", &mp); err != nil || len(mp) == 0 {
+		mp = xpv1.ManagementPolicies{xpv1.ManagementActionAll}
+	}
+	actions := make(map[xpv1.ManagementAction]bool, len(mp))
+	for _, a := range mp {
+		actions[a] = true
+	}
+	return actions
+}
+
+// observeOnly returns true if Crossplane neither creates nor updates the
+// external resource of the supplied composed resource, so its
+// spec.forProvider and spec.initProvider are never acted upon.
+func observeOnly(u *unstructured.Unstructured) bool {
+	mp := managementPolicies(u)
+	if mp == nil || mp[xpv1.ManagementActionAll] {
+		return false
+	}
+	return !mp[xpv1.ManagementActionCreate] && !mp[xpv1.ManagementActionUpdate]
+}
+
+// orphans returns true if the external resource of the supplied composed
+// resource is kept when the composed resource is deleted.
+func orphans(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	if p, _ := fieldpath.Pave(u.Object).GetString("spec.deletionPolicy"); p == string(xpv1.DeletionOrphan) {
+		return true
+	}
+	mp := managementPolicies(u)
+	return !mp[xpv1.ManagementActionAll] && !mp[xpv1.ManagementActionDelete]
+}
+
+// withoutProviderSpec returns a copy of the supplied composed resource
+// without the fields Crossplane only acts on when creating or updating its
+// external resource.
+func withoutProviderSpec(u *unstructured.Unstructured) *unstructured.Unstructured {
+	if u == nil {
+		return nil
+	}
+	u = u.DeepCopy()
+	unstructured.RemoveNestedField(u.Object, strings.Split(fieldForProvider, ".")...)
+	unstructured.RemoveNestedField(u.Object, strings.Split(fieldInitProvider, ".")...)
+	if spec, ok := u.Object["spec"].(map[string]any); ok && len(spec) == 0 {
+		delete(u.Object, "spec")
+	}
+	return u
+}
+
+// observedFieldPath returns the field path an observe-only composed resource
+// reports the supplied field path at. The desired spec.forProvider of an
+// observe-only composed resource is never applied, so its observed state in
+// status.atProvider is what matters.
+func observedFieldPath(p string) string {
+	for _, prefix := range []string{fieldForProvider, fieldInitProvider} {
+		if p == prefix || strings.HasPrefix(p, prefix+".") || strings.HasPrefix(p, prefix+"[") {
+			return fieldAtProvider + strings.TrimPrefix(p, prefix)
+		}
+	}
+	return p
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestManagementPolicies(t *testing.T) {
+	type want struct {
+		observeOnly bool
+		orphans     bool
+	}
+	cases := map[string]struct {
+		reason string
+		spec   map[string]any
+		want   want
+	}{
+		"Default": {
+			reason: "A composed resource without management policies should be fully managed.",
+			spec:   map[string]any{},
+		},
+		"All": {
+			reason: "A composed resource with the * policy should be fully managed.",
+			spec:   map[string]any{"managementPolicies": []any{"*"}},
+		},
+		"ObserveOnly": {
+			reason: "A composed resource that may only be observed should be observe-only and orphan its external resource.",
+			spec:   map[string]any{"managementPolicies": []any{"Observe"}},
+			want:   want{observeOnly: true, orphans: true},
+		},
+		"ObserveAndDelete": {
+			reason: "A composed resource that may be observed and deleted should be observe-only.",
+			spec:   map[string]any{"managementPolicies": []any{"Observe", "Delete"}},
+			want:   want{observeOnly: true},
+		},
+		"NoDelete": {
+			reason: "A composed resource that may not be deleted should orphan its external resource.",
+			spec:   map[string]any{"managementPolicies": []any{"Observe", "Create", "Update", "LateInitialize"}},
+			want:   want{orphans: true},
+		},
+		"OrphanDeletionPolicy": {
+			reason: "A composed resource with the Orphan deletion policy should orphan its external resource.",
+			spec:   map[string]any{"deletionPolicy": "Orphan"},
+			want:   want{orphans: true},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: map[string]any{"spec": tc.spec}}
+			got := want{observeOnly: observeOnly(u), orphans: orphans(u)}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nobserveOnly(...), orphans(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEvaluateReadinessObserveOnly(t *testing.T) {
+	tmpl := &xpextv1.ComposedTemplate{ReadinessChecks: []xpextv1.ReadinessCheck{{
+		Type:        xpextv1.ReadinessCheckTypeMatchString,
+		FieldPath:   "spec.forProvider.region",
+		MatchString: "us-east-1",
+	}}}
+	// The desired region differs from the observed region of the external
+	// resource, which an observe-only composed resource never changes.
+	cd := &composed.Unstructured{Unstructured: unstructured.Unstructured{Object: map[string]any{
+		"spec":   map[string]any{"forProvider": map[string]any{"region": "us-east-1"}},
+		"status": map[string]any{"atProvider": map[string]any{"region": "us-west-2"}},
+	}}}
+
+	type want struct {
+		ready  bool
+		checks []ReadinessCheckResult
+	}
+	cases := map[string]struct {
+		reason      string
+		observeOnly bool
+		want        want
+	}{
+		"Managed": {
+			reason: "Readiness checks of a managed composed resource should read the supplied field path.",
+			want: want{
+				ready:  true,
+				checks: []ReadinessCheckResult{{Type: "MatchString", FieldPath: "spec.forProvider.region", Ready: true}},
+			},
+		},
+		"ObserveOnly": {
+			reason:      "Readiness checks of an observe-only composed resource should read its observed state.",
+			observeOnly: true,
+			want: want{
+				checks: []ReadinessCheckResult{{Type: "MatchString", FieldPath: "status.atProvider.region"}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ready, checks := evaluateReadiness(cd, tmpl, tc.observeOnly)
+			if diff := cmp.Diff(tc.want.ready, ready); diff != "" {
+				t.Errorf("\n%s\nevaluateReadiness(...): -want ready, +got ready:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.checks, checks); diff != "" {
+				t.Errorf("\n%s\nevaluateReadiness(...): -want checks, +got checks:\n%s", tc.reason, diff)
+			}
+		})
+	}
+	if tmpl.ReadinessChecks[0].FieldPath != "spec.forProvider.region" {
+		t.Errorf("evaluateReadiness(...): template was modified")
+	}
+}
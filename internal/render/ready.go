@@ -0,0 +1,85 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+// A ReadinessCheckResult is the result of a readiness check of a composed
+// resource.
+type ReadinessCheckResult struct {
+	// Type of the readiness check.
+	Type string `json:"type"`
+	// FieldPath the readiness check reads, if any.
+	FieldPath string `json:"fieldPath,omitempty"`
+	// Ready indicates whether the readiness check passed.
+	Ready bool `json:"ready"`
+	// Error explains why the readiness check could not be run, if it
+	// couldn't.
+	Error string `json:"error,omitempty"`
+}
+
+// defaultReadinessChecks are used for templates without readiness checks,
+// matching the default of the Composition API.
+var defaultReadinessChecks = []xpextv1.ReadinessCheck{{
+	Type: xpextv1.ReadinessCheckTypeMatchCondition,
+	MatchCondition: &xpextv1.MatchConditionReadinessCheck{
+		Type:   xpv1.TypeReady,
+		Status: corev1.ConditionTrue,
+	},
+}}
+
+// evaluateReadiness runs each readiness check of the supplied template
+// against the supplied composed resource. The composed resource is ready if
+// all of its readiness checks pass. Readiness checks of observe-only
+// composed resources read their observed state in status.atProvider instead
+// of spec.forProvider or spec.initProvider, which are never applied.
+func evaluateReadiness(cd *composed.Unstructured, t *xpextv1.ComposedTemplate, observeOnly bool) (bool, []ReadinessCheckResult) {
+	if len(t.ReadinessChecks) == 0 {
+		t = t.DeepCopy()
+		t.ReadinessChecks = defaultReadinessChecks
+	}
+	if observeOnly {
+		t = t.DeepCopy()
+		for i := range t.ReadinessChecks {
+			t.ReadinessChecks[i].FieldPath = observedFieldPath(t.ReadinessChecks[i].FieldPath)
+		}
+	}
+	paved := fieldpath.Pave(cd.Object)
+
+	ready := true
+	results := make([]ReadinessCheckResult, len(t.ReadinessChecks))
+	for i, rc := range icomposite.ReadinessChecksFromComposedTemplate(t) {
+		results[i] = ReadinessCheckResult{Type: string(rc.Type)}
+		if rc.FieldPath != nil {
+			results[i].FieldPath = *rc.FieldPath
+		}
+		ok, err := rc.IsReady(paved, cd)
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+		results[i].Ready = ok
+		ready = ready && ok
+	}
+	return ready, results
+}
@@ -0,0 +1,324 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render renders the resources a Composition composes for an example
+// composite resource without an API server.
+package render
+
+import (
+	"context"
+	"strconv"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+	"github.com/crossplane/crossplane/xcrd"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+	icompositions "github.com/crossplane/crossplane/controller/apiextensions/compositions"
+)
+
+const (
+	errParseComposition = "cannot parse Composition"
+	errParseXRD         = "cannot parse CompositeResourceDefinition"
+	errParseXR          = "cannot parse composite resource"
+	errDeriveCRD        = "cannot derive CustomResourceDefinition from CompositeResourceDefinition"
+	errConvertSchema    = "cannot convert composite resource schema"
+	errStructuralSchema = "cannot build structural schema of composite resource"
+	errSchemaValidator  = "cannot build schema validator of composite resource"
+	errInvalidXR        = "invalid composite resource"
+	errRender           = "cannot render composed resources"
+	errConvertComposed  = "cannot convert composed resource to unstructured"
+
+	errFmtKindNotDefined   = "composite resource kind %s is not defined by CompositeResourceDefinition %q"
+	errFmtVersionNotServed = "composite resource version %q is not served by CompositeResourceDefinition %q"
+)
+
+// Inputs are the inputs to a render.
+type Inputs struct {
+	// Composition that composes resources.
+	Composition *xpextv1.Composition
+	// XRD that defines the kind of the composite resource.
+	XRD *xpextv1.CompositeResourceDefinition
+	// XR is the example composite resource for which resources are composed.
+	XR *unstructured.Unstructured
+}
+
+// ParseInputs parses Inputs from the YAML of a Composition, a
+// CompositeResourceDefinition and an example composite resource. A
+// CompositionRevision may be supplied instead of a Composition, in which case
+// the Composition is rendered as it was at that revision.
+func ParseInputs(comp, xrd, xr []byte) (*Inputs, error) {
+	in := &Inputs{
+		Composition: &xpextv1.Composition{},
+		XRD:         &xpextv1.CompositeResourceDefinition{},
+		XR:          &unstructured.Unstructured{},
+	}
+	tm := &metav1.TypeMeta{}
+	if err := yaml.Unmarshal(comp, tm); err != nil {
+		return nil, errors.Wrap(err, errParseComposition)
+	}
+	if tm.Kind == xpextv1.CompositionRevisionKind {
+		rev := &xpextv1.CompositionRevision{}
+		if err := yaml.Unmarshal(comp, rev); err != nil {
+			return nil, errors.Wrap(err, errParseComposition)
+		}
+		in.Composition = CompositionFromRevision(rev)
+	} else if err := yaml.Unmarshal(comp, in.Composition); err != nil {
+		return nil, errors.Wrap(err, errParseComposition)
+	}
+	if err := yaml.Unmarshal(xrd, in.XRD); err != nil {
+		return nil, errors.Wrap(err, errParseXRD)
+	}
+	if err := yaml.Unmarshal(xr, &in.XR.Object); err != nil {
+		return nil, errors.Wrap(err, errParseXR)
+	}
+	return in, nil
+}
+
+// Output is the result of a render.
+type Output struct {
+	// CompositeResource is the composite resource after defaults from its XRD
+	// and any patches to it were applied.
+	CompositeResource *unstructured.Unstructured
+	// Environment of the composite resource after any environment patches
+	// were applied to it, or nil if the Composition does not use one.
+	Environment *unstructured.Unstructured
+	// ConnectionDetails of the composite resource, extracted from the
+	// observed state of its composed resources.
+	ConnectionDetails managed.ConnectionDetails
+	// Ready indicates whether the composite resource would be ready, which
+	// it is once all of its composed resources are.
+	Ready bool
+	// ComposedResources are the rendered composed resources, in the order
+	// they were composed.
+	ComposedResources []ComposedResource
+}
+
+// ComposedResource is a rendered composed resource.
+type ComposedResource struct {
+	// Name of the Composition template or Function pipeline resource the
+	// composed resource was rendered from.
+	Name string
+	// Resource is the rendered composed resource.
+	Resource *unstructured.Unstructured
+	// ConnectionDetails the composed resource contributes to the connection
+	// secret of the composite resource, given its observed state.
+	ConnectionDetails managed.ConnectionDetails
+	// ObserveOnly indicates Crossplane would neither create nor update the
+	// external resource of the composed resource, per its management
+	// policies.
+	ObserveOnly bool
+	// Ready indicates whether the composed resource passes all of its
+	// readiness checks, given its observed state.
+	Ready bool
+	// ReadinessChecks are the results of each readiness check of the
+	// composed resource.
+	ReadinessChecks []ReadinessCheckResult
+	// Err is the error rendering the composed resource, if any. A composed
+	// resource that could not be fully rendered, for example because one of
+	// its patches could not be applied, is still returned.
+	Err error
+}
+
+// Option modifies a Renderer.
+type Option func(*Renderer)
+
+// WithLogger overrides the default logger with the provided Logger.
+func WithLogger(l logging.Logger) Option {
+	return func(r *Renderer) {
+		r.log = l
+	}
+}
+
+// WithFunctionRunner sets the FunctionRunner used to render Compositions in
+// Pipeline mode. Without a runner the functions pipeline is ignored.
+func WithFunctionRunner(fr icomposite.FunctionRunner) Option {
+	return func(r *Renderer) {
+		r.fn = fr
+	}
+}
+
+// WithEnvironmentConfigs sets the EnvironmentConfigs the environment of the
+// composite resource is built from. Unless the composite resource references
+// specific EnvironmentConfigs, all of them are merged in the order supplied,
+// after any default data of the Composition.
+func WithEnvironmentConfigs(cfgs ...*v1alpha1.EnvironmentConfig) Option {
+	return func(r *Renderer) {
+		r.envConfigs = cfgs
+	}
+}
+
+// WithDeterministicNames names composed resources that would otherwise only
+// have a generate name, using a hash of the names of the composite and
+// composed resources. This allows rendered resources to be compared across
+// renders without an API server to name them.
+func WithDeterministicNames() Option {
+	return func(r *Renderer) {
+		r.namer = icomposite.NewHashNamer()
+	}
+}
+
+// Renderer renders the resources a Composition composes for a composite
+// resource.
+type Renderer struct {
+	log        logging.Logger
+	fn         icomposite.FunctionRunner
+	envConfigs []*v1alpha1.EnvironmentConfig
+	namer      icomposite.ComposedResourceNamer
+	observed   map[string]ObservedResource
+}
+
+// NewRenderer returns a new Renderer.
+func NewRenderer(opts ...Option) *Renderer {
+	r := &Renderer{
+		log: logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Render the resources composed by the supplied Composition for the supplied
+// composite resource. The composite resource is defaulted and validated using
+// the schema of the supplied XRD before resources are composed.
+func (r *Renderer) Render(ctx context.Context, in *Inputs) (*Output, error) {
+	gvk := in.XR.GroupVersionKind()
+	xr := composite.New(composite.WithGroupVersionKind(gvk))
+	xr.SetUnstructuredContent(in.XR.DeepCopy().UnstructuredContent())
+	if err := defaultAndValidate(in.XRD, gvk, xr.Object); err != nil {
+		return nil, err
+	}
+
+	// The composite resource was never created, so fill in the fields the API
+	// server would have set that composed resources may be patched from.
+	if xr.GetName() == "" {
+		xr.SetName(icomposite.PlaceholderName)
+	}
+	if xr.GetUID() == "" {
+		xr.SetUID(types.UID(icomposite.PlaceholderUID))
+	}
+
+	// Round trip through a CompositionRevision to set the defaults of the
+	// Composition's fields.
+	comp := icomposite.AsComposition(icompositions.NewCompositionRevision(in.Composition, 1))
+
+	opts := []icomposite.ReconcilerOption{
+		icomposite.WithLogger(r.log),
+		icomposite.WithEnvironmentFetcher(icomposite.NewStaticEnvironmentFetcher(r.envConfigs...)),
+	}
+	if r.fn != nil {
+		opts = append(opts, icomposite.WithFunctionRunner(r.fn))
+	}
+	if r.namer != nil {
+		opts = append(opts, icomposite.WithComposedResourceNamer(r.namer))
+	}
+	cds, e, err := icomposite.NewReconciler(resource.CompositeKind(gvk), opts...).Render(ctx, xr, comp)
+	if err != nil {
+		return nil, errors.Wrap(err, errRender)
+	}
+
+	out := &Output{
+		CompositeResource: &xr.Unstructured,
+		Environment:       e,
+		ComposedResources: make([]ComposedResource, len(cds)),
+	}
+	for i, cd := range cds {
+		u, err := asUnstructured(cd.Resource)
+		if err != nil {
+			return nil, errors.Wrap(err, errConvertComposed)
+		}
+		name := string(cd.ResourceName)
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		out.ComposedResources[i] = ComposedResource{Name: name, Resource: u, ObserveOnly: observeOnly(u), Err: cd.TemplateRenderErr}
+	}
+	if err := r.observe(comp, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// defaultAndValidate applies the defaults of the schema the supplied XRD
+// defines for the supplied kind of composite resource to obj, then validates
+// obj against it.
+func defaultAndValidate(xrd *xpextv1.CompositeResourceDefinition, gvk schema.GroupVersionKind, obj map[string]any) error {
+	if gvk.Group != xrd.Spec.Group || gvk.Kind != xrd.Spec.Names.Kind {
+		return errors.Errorf(errFmtKindNotDefined, gvk.GroupKind(), xrd.GetName())
+	}
+
+	crd, err := xcrd.ForCompositeResource(xrd)
+	if err != nil {
+		return errors.Wrap(err, errDeriveCRD)
+	}
+
+	var v *extv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == gvk.Version && crd.Spec.Versions[i].Served {
+			v = &crd.Spec.Versions[i]
+		}
+	}
+	if v == nil || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+		return errors.Errorf(errFmtVersionNotServed, gvk.Version, xrd.GetName())
+	}
+
+	props := &apiextensions.JSONSchemaProps{}
+	if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(v.Schema.OpenAPIV3Schema, props, nil); err != nil {
+		return errors.Wrap(err, errConvertSchema)
+	}
+	ss, err := structuralschema.NewStructural(props)
+	if err != nil {
+		return errors.Wrap(err, errStructuralSchema)
+	}
+	defaulting.Default(obj, ss)
+
+	sv, _, err := validation.NewSchemaValidator(props)
+	if err != nil {
+		return errors.Wrap(err, errSchemaValidator)
+	}
+	if errs := validation.ValidateCustomResource(nil, obj, sv); len(errs) > 0 {
+		return errors.Wrap(errs.ToAggregate(), errInvalidXR)
+	}
+	return nil
+}
+
+func asUnstructured(cd resource.Composed) (*unstructured.Unstructured, error) {
+	if u, ok := cd.(*composed.Unstructured); ok {
+		return &u.Unstructured, nil
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cd)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
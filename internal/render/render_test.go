@@ -0,0 +1,214 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRender(t *testing.T) {
+	type want struct {
+		out *Output
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		xr     func(xr *unstructured.Unstructured)
+		want   want
+	}{
+		"Success": {
+			reason: "Resources should be composed for a defaulted composite resource, returning any error rendering an individual resource.",
+			xr:     func(_ *unstructured.Unstructured) {},
+			want: want{out: &Output{
+				CompositeResource: &unstructured.Unstructured{Object: map[string]any{
+					"apiVersion": "example.org/v1alpha1",
+					"kind":       "XBucket",
+					"metadata": map[string]any{
+						"name":   "example",
+						"uid":    "placeholder-uid",
+						"labels": map[string]any{"crossplane.io/composite": "example"},
+					},
+					"spec": map[string]any{
+						"region":     "us-east-1",
+						"versioning": false,
+						"resourceRefs": []any{
+							map[string]any{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "Bucket"},
+							map[string]any{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "BucketVersioning"},
+						},
+						"writeConnectionSecretToRef": map[string]any{
+							"name":      "placeholder-uid",
+							"namespace": "placeholder-namespace",
+						},
+					},
+				}},
+				Environment: &unstructured.Unstructured{Object: map[string]any{
+					"apiVersion": "internal.crossplane.io/v1alpha1",
+					"kind":       "Environment",
+				}},
+				ConnectionDetails: managed.ConnectionDetails{},
+				ComposedResources: []ComposedResource{
+					{
+						Name:              "bucket",
+						ConnectionDetails: managed.ConnectionDetails{},
+						ReadinessChecks:   []ReadinessCheckResult{{Type: "MatchCondition"}},
+						Resource: &unstructured.Unstructured{Object: map[string]any{
+							"apiVersion": "s3.aws.upbound.io/v1beta1",
+							"kind":       "Bucket",
+							"metadata": map[string]any{
+								"generateName": "example-",
+								"annotations":  map[string]any{"crossplane.io/composition-resource-name": "bucket"},
+								"labels": map[string]any{
+									"crossplane.io/claim-name":      "",
+									"crossplane.io/claim-namespace": "",
+									"crossplane.io/composite":       "example",
+								},
+								"ownerReferences": []any{map[string]any{
+									"apiVersion":         "example.org/v1alpha1",
+									"kind":               "XBucket",
+									"name":               "example",
+									"uid":                "placeholder-uid",
+									"controller":         true,
+									"blockOwnerDeletion": true,
+								}},
+							},
+							"spec": map[string]any{
+								"forProvider": map[string]any{"region": "us-east-1"},
+							},
+						}},
+					},
+					{
+						Name:              "versioning",
+						ConnectionDetails: managed.ConnectionDetails{},
+						ReadinessChecks:   []ReadinessCheckResult{{Type: "MatchCondition"}},
+						Resource: &unstructured.Unstructured{Object: map[string]any{
+							"apiVersion": "s3.aws.upbound.io/v1beta1",
+							"kind":       "BucketVersioning",
+							"metadata":   map[string]any{"generateName": "example-"},
+							"spec": map[string]any{
+								"forProvider": map[string]any{
+									"region": "us-east-1",
+									"versioningConfiguration": []any{
+										map[string]any{"status": "Enabled"},
+									},
+								},
+							},
+						}},
+						Err: cmpopts.AnyError,
+					},
+				},
+			}},
+		},
+		"KindNotDefined": {
+			reason: "A composite resource of a kind the XRD does not define should return an error.",
+			xr: func(xr *unstructured.Unstructured) {
+				xr.SetKind("XTable")
+			},
+			want: want{err: errors.Errorf(errFmtKindNotDefined, "XTable.example.org", "xbuckets.example.org")},
+		},
+		"VersionNotServed": {
+			reason: "A composite resource of a version the XRD does not serve should return an error.",
+			xr: func(xr *unstructured.Unstructured) {
+				xr.SetAPIVersion("example.org/v1")
+			},
+			want: want{err: errors.Errorf(errFmtVersionNotServed, "v1", "xbuckets.example.org")},
+		},
+		"InvalidXR": {
+			reason: "A composite resource that does not match the XRD's schema should return an error.",
+			xr: func(xr *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(xr.Object, int64(5), "spec", "region")
+			},
+			want: want{err: errors.Wrap(errors.New(`spec.region: Invalid value: "integer": spec.region in body must be of type string: "integer"`), errInvalidXR)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in, err := ParseInputs(readFile(t, "composition.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+			if err != nil {
+				t.Fatalf("ParseInputs(...): %s", err)
+			}
+			tc.xr(in.XR)
+
+			out, err := NewRenderer().Render(context.Background(), in)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, out, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadFile(...): %s", err)
+	}
+	return b
+}
+
+func TestRenderDeterministicNames(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		xr     string
+		want   []string
+	}{
+		"Named": {
+			reason: "Composed resources should be named by hashing the composite and composed resource names.",
+			xr:     "example",
+			want:   []string{"example-a8817", "example-fe194"},
+		},
+		"OtherXR": {
+			reason: "Composed resources of a differently named composite resource should be named differently.",
+			xr:     "other",
+			want:   []string{"other-2cf1b", "other-cf093"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in, err := ParseInputs(readFile(t, "composition.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+			if err != nil {
+				t.Fatalf("ParseInputs(...): %s", err)
+			}
+			in.XR.SetName(tc.xr)
+
+			out, err := NewRenderer(WithDeterministicNames()).Render(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Render(...): %s", err)
+			}
+			got := make([]string, len(out.ComposedResources))
+			for i, cd := range out.ComposedResources {
+				got[i] = cd.Resource.GetName()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
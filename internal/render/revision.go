@@ -0,0 +1,115 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+const (
+	errListRevisions      = "cannot list CompositionRevisions"
+	errFmtNoRevisions     = "Composition %q has no revisions"
+	errFmtRevisionMissing = "Composition %q has no revision %d"
+	errFmtDiffRendered    = "cannot diff rendered composed resource %q"
+)
+
+// CompositionFromRevision returns the Composition the supplied
+// CompositionRevision is a revision of, as it was at that revision.
+func CompositionFromRevision(rev *xpextv1.CompositionRevision) *xpextv1.Composition {
+	comp := icomposite.AsComposition(rev)
+	comp.SetGroupVersionKind(xpextv1.CompositionGroupVersionKind)
+	comp.SetName(rev.GetLabels()[xpextv1.LabelCompositionName])
+	if comp.GetName() == "" {
+		comp.SetName(rev.GetName())
+	}
+	return comp
+}
+
+// FetchCompositionRevision fetches the supplied revision of the named
+// Composition from a control plane. The latest revision is fetched if the
+// supplied revision is zero.
+func FetchCompositionRevision(ctx context.Context, c client.Reader, comp string, revision int64) (*xpextv1.CompositionRevision, error) {
+	l := &xpextv1.CompositionRevisionList{}
+	if err := c.List(ctx, l, client.MatchingLabels{xpextv1.LabelCompositionName: comp}); err != nil {
+		return nil, errors.Wrap(err, errListRevisions)
+	}
+	if len(l.Items) == 0 {
+		return nil, errors.Errorf(errFmtNoRevisions, comp)
+	}
+	sort.Slice(l.Items, func(i, j int) bool { return l.Items[i].Spec.Revision < l.Items[j].Spec.Revision })
+	if revision == 0 {
+		return &l.Items[len(l.Items)-1], nil
+	}
+	for i := range l.Items {
+		if l.Items[i].Spec.Revision == revision {
+			return &l.Items[i], nil
+		}
+	}
+	return nil, errors.Errorf(errFmtRevisionMissing, comp, revision)
+}
+
+// DiffRendered compares the composed resources rendered by two renders, for
+// example of two revisions of the same Composition. Composed resources are
+// matched by the name of the template they were rendered from. The composed
+// resources of from are returned as Live, and those of to as Desired.
+func DiffRendered(from, to *Output) ([]ResourceDiff, error) {
+	prev := make(map[string]*ComposedResource, len(from.ComposedResources))
+	for i := range from.ComposedResources {
+		prev[from.ComposedResources[i].Name] = &from.ComposedResources[i]
+	}
+
+	diffs := make([]ResourceDiff, 0, len(to.ComposedResources))
+	for _, cd := range to.ComposedResources {
+		rd := ResourceDiff{Name: cd.Name, Type: DiffTypeAdded, Desired: cd.Resource}
+		if p, ok := prev[cd.Name]; ok {
+			rd.Live = p.Resource
+			delete(prev, cd.Name)
+		}
+		d, err := unifiedDiff("from", "to", rd.Live, rd.Desired)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtDiffRendered, cd.Name)
+		}
+		rd.Diff = d
+		if rd.Live != nil {
+			rd.Type = DiffTypeModified
+			if d == "" {
+				rd.Type = DiffTypeUnchanged
+			}
+		}
+		diffs = append(diffs, rd)
+	}
+
+	// Composed resources that are no longer rendered are removed, in the
+	// order they were originally rendered.
+	for _, cd := range from.ComposedResources {
+		if _, ok := prev[cd.Name]; !ok {
+			continue
+		}
+		d, err := unifiedDiff("from", "to", cd.Resource, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtDiffRendered, cd.Name)
+		}
+		diffs = append(diffs, ResourceDiff{Name: cd.Name, Type: DiffTypeRemoved, Live: cd.Resource, Diff: d})
+	}
+	return diffs, nil
+}
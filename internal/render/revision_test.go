@@ -0,0 +1,173 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestParseInputsRevision(t *testing.T) {
+	in, err := ParseInputs(readFile(t, "composition-revision.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+	if err != nil {
+		t.Fatalf("ParseInputs(...): %s", err)
+	}
+	if diff := cmp.Diff("xbuckets.example.org", in.Composition.GetName()); diff != "" {
+		t.Errorf("ParseInputs(...): -want name, +got name:\n%s", diff)
+	}
+
+	out, err := NewRenderer().Render(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Render(...): %s", err)
+	}
+	names := []string{}
+	for _, cd := range out.ComposedResources {
+		names = append(names, cd.Name)
+	}
+	if diff := cmp.Diff([]string{"bucket"}, names); diff != "" {
+		t.Errorf("Render(...): -want composed resources, +got composed resources:\n%s", diff)
+	}
+}
+
+func TestFetchCompositionRevision(t *testing.T) {
+	errBoom := errors.New("boom")
+	rev := func(n int64) xpextv1.CompositionRevision {
+		r := xpextv1.CompositionRevision{Spec: xpextv1.CompositionRevisionSpec{Revision: n}}
+		r.SetName(fmt.Sprintf("example-%d", n))
+		return r
+	}
+	list := func(revs ...xpextv1.CompositionRevision) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			if lo.LabelSelector.String() != xpextv1.LabelCompositionName+"=example" {
+				return errors.Errorf("unexpected selector %q", lo.LabelSelector)
+			}
+			obj.(*xpextv1.CompositionRevisionList).Items = revs
+			return nil
+		}
+	}
+
+	type want struct {
+		rev *xpextv1.CompositionRevision
+		err error
+	}
+	cases := map[string]struct {
+		reason   string
+		list     test.MockListFn
+		revision int64
+		want     want
+	}{
+		"Latest": {
+			reason: "The revision with the highest number should be returned if no revision is supplied.",
+			list:   list(rev(2), rev(3), rev(1)),
+			want:   want{rev: func() *xpextv1.CompositionRevision { r := rev(3); return &r }()},
+		},
+		"Revision": {
+			reason:   "The supplied revision should be returned.",
+			list:     list(rev(2), rev(3), rev(1)),
+			revision: 2,
+			want:     want{rev: func() *xpextv1.CompositionRevision { r := rev(2); return &r }()},
+		},
+		"RevisionMissing": {
+			reason:   "An error should be returned if the supplied revision does not exist.",
+			list:     list(rev(1)),
+			revision: 2,
+			want:     want{err: errors.Errorf(errFmtRevisionMissing, "example", 2)},
+		},
+		"NoRevisions": {
+			reason: "An error should be returned if the Composition has no revisions.",
+			list:   list(),
+			want:   want{err: errors.Errorf(errFmtNoRevisions, "example")},
+		},
+		"ListError": {
+			reason: "Errors listing revisions should be returned.",
+			list:   test.NewMockListFn(errBoom),
+			want:   want{err: errors.Wrap(errBoom, errListRevisions)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := FetchCompositionRevision(context.Background(), &test.MockClient{MockList: tc.list}, "example", tc.revision)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nFetchCompositionRevision(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rev, got); diff != "" {
+				t.Errorf("\n%s\nFetchCompositionRevision(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiffRendered(t *testing.T) {
+	cm := func(data string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"data":       map[string]any{"key": data},
+		}}
+	}
+	from := &Output{ComposedResources: []ComposedResource{
+		{Name: "same", Resource: cm("a")},
+		{Name: "changed", Resource: cm("a")},
+		{Name: "removed", Resource: cm("a")},
+	}}
+	to := &Output{ComposedResources: []ComposedResource{
+		{Name: "same", Resource: cm("a")},
+		{Name: "changed", Resource: cm("b")},
+		{Name: "added", Resource: cm("b")},
+	}}
+
+	want := []ResourceDiff{
+		{Name: "same", Type: DiffTypeUnchanged, Live: cm("a"), Desired: cm("a")},
+		{
+			Name:    "changed",
+			Type:    DiffTypeModified,
+			Live:    cm("a"),
+			Desired: cm("b"),
+			Diff:    "--- from\n+++ to\n@@ -1,4 +1,4 @@\n apiVersion: v1\n data:\n-  key: a\n+  key: b\n kind: ConfigMap\n",
+		},
+		{
+			Name:    "added",
+			Type:    DiffTypeAdded,
+			Desired: cm("b"),
+			Diff:    "--- from\n+++ to\n@@ -0,0 +1,4 @@\n+apiVersion: v1\n+data:\n+  key: b\n+kind: ConfigMap\n",
+		},
+		{
+			Name: "removed",
+			Type: DiffTypeRemoved,
+			Live: cm("a"),
+			Diff: "--- from\n+++ to\n@@ -1,4 +0,0 @@\n-apiVersion: v1\n-data:\n-  key: a\n-kind: ConfigMap\n",
+		},
+	}
+
+	got, err := DiffRendered(from, to)
+	if err != nil {
+		t.Fatalf("DiffRendered(...): %s", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DiffRendered(...): -want, +got:\n%s", diff)
+	}
+}
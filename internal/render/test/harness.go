@@ -0,0 +1,398 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package test runs Composition tests that compare the resources rendered for
+// example composite resources against golden files.
+package test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/render"
+)
+
+// Files of a test case directory.
+const (
+	// CompositeFile contains the example composite resource. A directory is
+	// a test case if and only if it contains this file.
+	CompositeFile = "xr.yaml"
+	// CompositionFile contains the Composition under test. If a test case
+	// directory does not contain one, the nearest one in a parent directory
+	// is used.
+	CompositionFile = "composition.yaml"
+	// DefinitionFile contains the CompositeResourceDefinition of the
+	// composite resource. It is found the same way as the CompositionFile.
+	DefinitionFile = "definition.yaml"
+	// EnvironmentFile optionally contains EnvironmentConfigs.
+	EnvironmentFile = "environment.yaml"
+	// ObservedFile optionally contains the observed state of composed
+	// resources.
+	ObservedFile = "observed.yaml"
+	// ExpectedFile is the golden file the rendered output is compared to.
+	ExpectedFile = "expected.yaml"
+)
+
+const (
+	errFmtWalk         = "cannot discover test cases in %q"
+	errFmtRead         = "cannot read %q"
+	errFmtNotFound     = "cannot find %s for test case %q"
+	errFmtMarshal      = "cannot marshal rendered resource %q"
+	errFmtWriteGolden  = "cannot write golden file %q"
+	errFmtNoGolden     = "golden file %q does not exist; run with update enabled to create it"
+	errFmtScaffold     = "cannot scaffold test case in %q"
+	errFmtCaseExists   = "test case %q already exists"
+	errParseInputs     = "cannot parse inputs"
+	errLoadEnvironment = "cannot load EnvironmentConfigs"
+	errLoadObserved    = "cannot load observed resources"
+	errRender          = "cannot render"
+	errDiff            = "cannot diff rendered output against golden file"
+)
+
+// A Case is a test case.
+type Case struct {
+	// Name of the test case, which is the path of its directory relative to
+	// the root the test cases were discovered in.
+	Name string
+	// Dir is the directory of the test case.
+	Dir string
+
+	root string
+}
+
+// A Result is the result of running a test case.
+type Result struct {
+	// Case that was run.
+	Case Case
+	// Diff between the golden file and the rendered output. It is empty if
+	// they are the same.
+	Diff string
+	// Updated indicates whether the golden file was updated.
+	Updated bool
+	// Err is any error running the test case.
+	Err error
+}
+
+// Passed returns true if the test case ran without error and its rendered
+// output matched its golden file.
+func (r Result) Passed() bool {
+	return r.Err == nil && r.Diff == ""
+}
+
+// Option modifies a Harness.
+type Option func(*Harness)
+
+// WithUpdate sets whether golden files are updated with the rendered output
+// instead of compared against it.
+func WithUpdate(u bool) Option {
+	return func(h *Harness) {
+		h.update = u
+	}
+}
+
+// WithRenderOptions sets options for the renderer test cases are rendered
+// with.
+func WithRenderOptions(opts ...render.Option) Option {
+	return func(h *Harness) {
+		h.opts = opts
+	}
+}
+
+// Harness discovers and runs test cases.
+type Harness struct {
+	fs     afero.Fs
+	update bool
+	opts   []render.Option
+}
+
+// New returns a Harness that reads test cases from the supplied filesystem.
+func New(fs afero.Fs, opts ...Option) *Harness {
+	h := &Harness{fs: fs}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// Discover returns the test cases under the supplied root directory, in
+// lexical order.
+func (h *Harness) Discover(root string) ([]Case, error) {
+	cases := []Case{}
+	err := afero.Walk(h.fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != CompositeFile {
+			return nil
+		}
+		dir := filepath.Dir(path)
+		name, err := filepath.Rel(root, dir)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, Case{Name: filepath.ToSlash(name), Dir: dir, root: root})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtWalk, root)
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// Run the supplied test case.
+func (h *Harness) Run(ctx context.Context, c Case) Result {
+	res := Result{Case: c}
+
+	got, err := h.render(ctx, c)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	golden := filepath.Join(c.Dir, ExpectedFile)
+	if h.update {
+		if err := afero.WriteFile(h.fs, golden, got, 0o644); err != nil {
+			res.Err = errors.Wrapf(err, errFmtWriteGolden, golden)
+			return res
+		}
+		res.Updated = true
+		return res
+	}
+
+	want, err := afero.ReadFile(h.fs, golden)
+	if os.IsNotExist(err) {
+		res.Err = errors.Errorf(errFmtNoGolden, golden)
+		return res
+	}
+	if err != nil {
+		res.Err = errors.Wrapf(err, errFmtRead, golden)
+		return res
+	}
+
+	res.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(want)),
+		B:        difflib.SplitLines(string(got)),
+		FromFile: golden,
+		ToFile:   "rendered",
+		Context:  3,
+	})
+	if err != nil {
+		res.Err = errors.Wrap(err, errDiff)
+	}
+	return res
+}
+
+// RunAll discovers and runs the test cases under the supplied root directory.
+func (h *Harness) RunAll(ctx context.Context, root string) ([]Result, error) {
+	cases, err := h.Discover(root)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(cases))
+	for i, c := range cases {
+		results[i] = h.Run(ctx, c)
+	}
+	return results, nil
+}
+
+// update is registered as a test flag of packages that use RunTests.
+var update = flag.Bool("update", false, "Update golden files with the rendered output.")
+
+// RunTests runs the test cases under the supplied root directory of the OS
+// filesystem as subtests of t. Golden files are updated if the test binary is
+// run with the -update flag, e.g. go test ./internal/render/test -update.
+func RunTests(t *testing.T, root string, opts ...Option) {
+	t.Helper()
+	opts = append([]Option{WithUpdate(*update)}, opts...)
+	h := New(afero.NewOsFs(), opts...)
+	cases, err := h.Discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			res := h.Run(context.Background(), c)
+			if res.Err != nil {
+				t.Fatal(res.Err)
+			}
+			if res.Diff != "" {
+				t.Errorf("rendered output does not match golden file:\n%s", res.Diff)
+			}
+		})
+	}
+}
+
+// Scaffold creates a test case in the supplied directory, under the supplied
+// root directory, whose composite resource is an example generated from the
+// nearest CompositeResourceDefinition. Existing test cases are not modified.
+func (h *Harness) Scaffold(root, dir string) (Case, error) {
+	name, err := filepath.Rel(root, dir)
+	if err != nil {
+		return Case{}, errors.Wrapf(err, errFmtScaffold, dir)
+	}
+	c := Case{Name: filepath.ToSlash(name), Dir: dir, root: root}
+
+	xrPath := filepath.Join(dir, CompositeFile)
+	if h.exists(xrPath) {
+		return Case{}, errors.Errorf(errFmtCaseExists, c.Name)
+	}
+	xrdPath, err := h.find(c, DefinitionFile)
+	if err != nil {
+		return Case{}, err
+	}
+	b, err := afero.ReadFile(h.fs, xrdPath)
+	if err != nil {
+		return Case{}, errors.Wrapf(err, errFmtRead, xrdPath)
+	}
+	xrd := &xpextv1.CompositeResourceDefinition{}
+	if err := yaml.Unmarshal(b, xrd); err != nil {
+		return Case{}, errors.Wrapf(err, errFmtScaffold, dir)
+	}
+	ex, err := render.GenerateExample(xrd)
+	if err != nil {
+		return Case{}, errors.Wrapf(err, errFmtScaffold, dir)
+	}
+	if b, err = yaml.Marshal(ex.Object); err != nil {
+		return Case{}, errors.Wrapf(err, errFmtScaffold, dir)
+	}
+	if err := h.fs.MkdirAll(dir, 0o755); err != nil {
+		return Case{}, errors.Wrapf(err, errFmtScaffold, dir)
+	}
+	if err := afero.WriteFile(h.fs, xrPath, b, 0o644); err != nil {
+		return Case{}, errors.Wrapf(err, errFmtScaffold, dir)
+	}
+	return c, nil
+}
+
+// render the supplied test case, returning the rendered output in the form
+// of its golden file.
+func (h *Harness) render(ctx context.Context, c Case) ([]byte, error) {
+	compPath, err := h.find(c, CompositionFile)
+	if err != nil {
+		return nil, err
+	}
+	xrdPath, err := h.find(c, DefinitionFile)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, p := range []string{compPath, xrdPath, filepath.Join(c.Dir, CompositeFile)} {
+		b, err := afero.ReadFile(h.fs, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtRead, p)
+		}
+		files[p] = b
+	}
+	in, err := render.ParseInputs(files[compPath], files[xrdPath], files[filepath.Join(c.Dir, CompositeFile)])
+	if err != nil {
+		return nil, errors.Wrap(err, errParseInputs)
+	}
+
+	opts := append([]render.Option{render.WithDeterministicNames()}, h.opts...)
+	if p := filepath.Join(c.Dir, EnvironmentFile); h.exists(p) {
+		cfgs, err := render.LoadEnvironmentConfigs(h.fs, p)
+		if err != nil {
+			return nil, errors.Wrap(err, errLoadEnvironment)
+		}
+		opts = append(opts, render.WithEnvironmentConfigs(cfgs...))
+	}
+	if p := filepath.Join(c.Dir, ObservedFile); h.exists(p) {
+		b, err := afero.ReadFile(h.fs, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtRead, p)
+		}
+		obs, err := render.ParseObservedResources(b)
+		if err != nil {
+			return nil, errors.Wrap(err, errLoadObserved)
+		}
+		opts = append(opts, render.WithObservedResources(obs...))
+	}
+
+	out, err := render.NewRenderer(opts...).Render(ctx, in)
+	if err != nil {
+		return nil, errors.Wrap(err, errRender)
+	}
+	return golden(out)
+}
+
+// find returns the path of the supplied file in the directory of the supplied
+// test case or the nearest of its parents, up to the root it was discovered
+// in.
+func (h *Harness) find(c Case, file string) (string, error) {
+	root := filepath.Clean(c.root)
+	for dir := filepath.Clean(c.Dir); ; dir = filepath.Dir(dir) {
+		if p := filepath.Join(dir, file); h.exists(p) {
+			return p, nil
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return "", errors.Errorf(errFmtNotFound, file, c.Name)
+		}
+	}
+}
+
+func (h *Harness) exists(path string) bool {
+	ok, err := afero.Exists(h.fs, path)
+	return ok && err == nil
+}
+
+// golden returns the rendered composite resource followed by each rendered
+// composed resource as a multi-document YAML file. Errors rendering a composed
+// resource are recorded as a comment above it, so that they are part of the
+// golden file.
+func golden(out *render.Output) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	write := func(name string, u *unstructured.Unstructured, comments ...string) error {
+		b, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return errors.Wrapf(err, errFmtMarshal, name)
+		}
+		buf.WriteString("---\n")
+		for _, c := range comments {
+			fmt.Fprintf(buf, "# %s\n", c)
+		}
+		buf.Write(b)
+		return nil
+	}
+
+	if err := write("composite", out.CompositeResource); err != nil {
+		return nil, err
+	}
+	for _, cd := range out.ComposedResources {
+		comments := []string{fmt.Sprintf("resource: %s", cd.Name)}
+		if cd.Err != nil {
+			comments = append(comments, fmt.Sprintf("error: %s", strings.ReplaceAll(cd.Err.Error(), "\n", " ")))
+		}
+		if err := write(cd.Name, cd.Resource, comments...); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestGolden(t *testing.T) {
+	RunTests(t, "testdata/suite")
+}
+
+func TestDiscover(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for _, p := range []string{"/suite/b/xr.yaml", "/suite/a/xr.yaml", "/suite/a/nested/xr.yaml", "/suite/c/other.yaml"} {
+		_ = afero.WriteFile(fs, p, []byte{}, 0o644)
+	}
+
+	cases, err := New(fs).Discover("/suite")
+	if err != nil {
+		t.Fatalf("Discover(...): %s", err)
+	}
+	want := []Case{
+		{Name: "a", Dir: "/suite/a", root: "/suite"},
+		{Name: "a/nested", Dir: "/suite/a/nested", root: "/suite"},
+		{Name: "b", Dir: "/suite/b", root: "/suite"},
+	}
+	if diff := cmp.Diff(want, cases, cmp.AllowUnexported(Case{})); diff != "" {
+		t.Errorf("Discover(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestRun(t *testing.T) {
+	suite := afero.NewBasePathFs(afero.NewOsFs(), "testdata/suite")
+	read := func(path string) []byte {
+		b, _ := afero.ReadFile(suite, path)
+		return b
+	}
+
+	type want struct {
+		res     Result
+		updated []byte
+	}
+	cases := map[string]struct {
+		reason string
+		files  map[string][]byte
+		update bool
+		want   want
+	}{
+		"Match": {
+			reason: "A test case whose rendered output matches its golden file should pass.",
+			files: map[string][]byte{
+				"/suite/composition.yaml":   read("composition.yaml"),
+				"/suite/definition.yaml":    read("definition.yaml"),
+				"/suite/case/xr.yaml":       read("default/xr.yaml"),
+				"/suite/case/expected.yaml": read("default/expected.yaml"),
+			},
+			want: want{res: Result{}},
+		},
+		"Mismatch": {
+			reason: "A test case whose rendered output differs from its golden file should return a diff.",
+			files: map[string][]byte{
+				"/suite/composition.yaml":   read("composition.yaml"),
+				"/suite/definition.yaml":    read("definition.yaml"),
+				"/suite/case/xr.yaml":       read("versioned/xr.yaml"),
+				"/suite/case/expected.yaml": read("default/expected.yaml"),
+			},
+			want: want{res: Result{Diff: "any"}},
+		},
+		"MissingGolden": {
+			reason: "A test case without a golden file should return an error.",
+			files: map[string][]byte{
+				"/suite/composition.yaml": read("composition.yaml"),
+				"/suite/definition.yaml":  read("definition.yaml"),
+				"/suite/case/xr.yaml":     read("default/xr.yaml"),
+			},
+			want: want{res: Result{Err: errors.Errorf(errFmtNoGolden, "/suite/case/expected.yaml")}},
+		},
+		"MissingComposition": {
+			reason: "A test case without a Composition in its directory or any parent should return an error.",
+			files: map[string][]byte{
+				"/suite/definition.yaml": read("definition.yaml"),
+				"/suite/case/xr.yaml":    read("default/xr.yaml"),
+			},
+			want: want{res: Result{Err: errors.Errorf(errFmtNotFound, CompositionFile, "case")}},
+		},
+		"Update": {
+			reason: "The golden file of a test case should be written in update mode.",
+			files: map[string][]byte{
+				"/suite/composition.yaml": read("composition.yaml"),
+				"/suite/definition.yaml":  read("definition.yaml"),
+				"/suite/case/xr.yaml":     read("default/xr.yaml"),
+			},
+			update: true,
+			want:   want{res: Result{Updated: true}, updated: read("default/expected.yaml")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for p, b := range tc.files {
+				_ = afero.WriteFile(fs, p, b, 0o644)
+			}
+			c := Case{Name: "case", Dir: "/suite/case", root: "/suite"}
+
+			res := New(fs, WithUpdate(tc.update)).Run(context.Background(), c)
+			if diff := cmp.Diff(tc.want.res.Err, res.Err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res.Diff != "", res.Diff != ""); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want diff, +got diff:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res.Updated, res.Updated); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want updated, +got updated:\n%s", tc.reason, diff)
+			}
+			if tc.want.updated != nil {
+				got, _ := afero.ReadFile(fs, "/suite/case/expected.yaml")
+				if diff := cmp.Diff(string(tc.want.updated), string(got), cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("\n%s\nRun(...): -want golden file, +got golden file:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	suite := afero.NewBasePathFs(afero.NewOsFs(), "testdata/suite")
+	read := func(path string) []byte {
+		b, _ := afero.ReadFile(suite, path)
+		return b
+	}
+
+	type want struct {
+		c   Case
+		xr  string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		files  map[string][]byte
+		want   want
+	}{
+		"Success": {
+			reason: "A test case should be created with an example composite resource of the nearest XRD.",
+			files: map[string][]byte{
+				"/suite/definition.yaml": read("definition.yaml"),
+			},
+			want: want{
+				c:  Case{Name: "new", Dir: "/suite/new", root: "/suite"},
+				xr: "apiVersion: example.org/v1alpha1\nkind: XBucket\nmetadata:\n  name: example\nspec: {}\n",
+			},
+		},
+		"Exists": {
+			reason: "An existing test case should not be overwritten.",
+			files: map[string][]byte{
+				"/suite/definition.yaml": read("definition.yaml"),
+				"/suite/new/xr.yaml":     []byte("existing"),
+			},
+			want: want{
+				xr:  "existing",
+				err: errors.Errorf(errFmtCaseExists, "new"),
+			},
+		},
+		"NoDefinition": {
+			reason: "An error should be returned if there is no XRD to generate an example of.",
+			want:   want{err: errors.Errorf(errFmtNotFound, DefinitionFile, "new")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for p, b := range tc.files {
+				_ = afero.WriteFile(fs, p, b, 0o644)
+			}
+
+			c, err := New(fs).Scaffold("/suite", "/suite/new")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nScaffold(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, c, cmp.AllowUnexported(Case{})); diff != "" {
+				t.Errorf("\n%s\nScaffold(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			xr, _ := afero.ReadFile(fs, "/suite/new/xr.yaml")
+			if diff := cmp.Diff(tc.want.xr, string(xr)); diff != "" {
+				t.Errorf("\n%s\nScaffold(...): -want xr.yaml, +got xr.yaml:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
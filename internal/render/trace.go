@@ -0,0 +1,233 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+)
+
+const (
+	errTemplates             = "cannot resolve composed resource templates"
+	errPatchSetUnresolved    = "PatchSet was not resolved"
+	errCombineRequired       = "combine is required"
+	errFromFieldPathRequired = "fromFieldPath is required"
+
+	errFmtTransform = "transform at index %d returned error"
+)
+
+// PatchOutcome is the outcome of a traced patch.
+type PatchOutcome string
+
+// Patch outcomes.
+const (
+	// PatchOutcomeApplied indicates the patch set its destination field.
+	PatchOutcomeApplied PatchOutcome = "Applied"
+	// PatchOutcomeSkipped indicates the patch was not applied because an
+	// optional source field was not found.
+	PatchOutcomeSkipped PatchOutcome = "Skipped"
+	// PatchOutcomeFailed indicates the patch could not be applied.
+	PatchOutcomeFailed PatchOutcome = "Failed"
+)
+
+// A PatchTrace traces a patch of a Composition from its source fields,
+// through its transforms, to its destination field.
+type PatchTrace struct {
+	// Resource is the name of the Composition template the patch belongs to.
+	Resource string `json:"resource"`
+	// Index of the patch in the template, after PatchSets were resolved.
+	Index int `json:"index"`
+	// Type of the patch.
+	Type xpextv1.PatchType `json:"type"`
+	// Sources of the patch. Combine patches have one source per variable.
+	Sources []PatchSource `json:"sources"`
+	// Combined is the value the sources were combined into, if the patch is
+	// a combine patch.
+	Combined any `json:"combined,omitempty"`
+	// Transforms applied to the source value, in order. Transforms after one
+	// that failed are not traced.
+	Transforms []TransformTrace `json:"transforms,omitempty"`
+	// ToFieldPath is the destination field.
+	ToFieldPath string `json:"toFieldPath"`
+	// Value written to the destination field.
+	Value any `json:"value,omitempty"`
+	// Outcome of the patch.
+	Outcome PatchOutcome `json:"outcome"`
+	// Error explains why the patch was skipped or failed.
+	Error string `json:"error,omitempty"`
+}
+
+// A PatchSource is a field a patch reads from.
+type PatchSource struct {
+	// FieldPath of the source field.
+	FieldPath string `json:"fieldPath"`
+	// Value of the source field. It is nil if the field was not found.
+	Value any `json:"value,omitempty"`
+	// Found indicates whether the source field was found.
+	Found bool `json:"found"`
+}
+
+// A TransformTrace traces a single transform of a patch.
+type TransformTrace struct {
+	// Type of the transform.
+	Type xpextv1.TransformType `json:"type"`
+	// Input to the transform.
+	Input any `json:"input,omitempty"`
+	// Output of the transform. It is nil if the transform failed.
+	Output any `json:"output,omitempty"`
+	// Error returned by the transform, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Trace renders the supplied Inputs and traces each patch of each Composition
+// template. Patches from the composite resource and environment read from
+// them as they were when resources were composed. Patches to the composite
+// resource or environment read from the rendered composed resource.
+func (r *Renderer) Trace(ctx context.Context, in *Inputs) ([]PatchTrace, error) {
+	out, err := r.Render(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := icomposite.ComposedTemplates(in.Composition.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, errTemplates)
+	}
+
+	traces := []PatchTrace{}
+	for i, t := range ts {
+		// Templates are rendered in order, so the ith composed resource was
+		// rendered from the ith template.
+		if i >= len(out.ComposedResources) {
+			break
+		}
+		cd := out.ComposedResources[i]
+		for j, p := range t.Patches {
+			pt := tracePatch(p, out.CompositeResource, out.Environment, cd.Resource)
+			pt.Resource = cd.Name
+			pt.Index = j
+			traces = append(traces, pt)
+		}
+	}
+	return traces, nil
+}
+
+// tracePatch traces the supplied patch between the supplied composite
+// resource, environment and composed resource.
+func tracePatch(p xpextv1.Patch, xr, env, cd *unstructured.Unstructured) PatchTrace { //nolint:gocyclo // Only slightly over, and easier to follow as one function.
+	if p.Type == "" {
+		p.Type = xpextv1.PatchTypeFromCompositeFieldPath
+	}
+	pt := PatchTrace{Type: p.Type, Sources: []PatchSource{}}
+
+	var from *unstructured.Unstructured
+	switch p.Type {
+	case xpextv1.PatchTypeFromCompositeFieldPath, xpextv1.PatchTypeCombineFromComposite:
+		from = xr
+	case xpextv1.PatchTypeFromEnvironmentFieldPath, xpextv1.PatchTypeCombineFromEnvironment:
+		from = env
+	case xpextv1.PatchTypeToCompositeFieldPath, xpextv1.PatchTypeCombineToComposite,
+		xpextv1.PatchTypeToEnvironmentFieldPath, xpextv1.PatchTypeCombineToEnvironment:
+		from = cd
+	case xpextv1.PatchTypePatchSet:
+		return failed(pt, errors.New(errPatchSetUnresolved))
+	}
+	if from == nil {
+		from = &unstructured.Unstructured{Object: map[string]any{}}
+	}
+	paved := fieldpath.Pave(from.Object)
+
+	var in any
+	switch p.Type {
+	case xpextv1.PatchTypeCombineFromComposite, xpextv1.PatchTypeCombineFromEnvironment,
+		xpextv1.PatchTypeCombineToComposite, xpextv1.PatchTypeCombineToEnvironment:
+		if p.ToFieldPath != nil {
+			pt.ToFieldPath = *p.ToFieldPath
+		}
+		if p.Combine == nil {
+			return failed(pt, errors.New(errCombineRequired))
+		}
+		vars := make([]any, len(p.Combine.Variables))
+		for i, v := range p.Combine.Variables {
+			val, err := paved.GetValue(v.FromFieldPath)
+			pt.Sources = append(pt.Sources, PatchSource{FieldPath: v.FromFieldPath, Value: val, Found: err == nil})
+			if err != nil {
+				return notFound(pt, p, err)
+			}
+			vars[i] = val
+		}
+		cb, err := icomposite.Combine(*p.Combine, vars)
+		if err != nil {
+			return failed(pt, err)
+		}
+		pt.Combined = cb
+		in = cb
+	default:
+		if p.FromFieldPath == nil {
+			return failed(pt, errors.New(errFromFieldPathRequired))
+		}
+		pt.ToFieldPath = *p.FromFieldPath
+		if p.ToFieldPath != nil {
+			pt.ToFieldPath = *p.ToFieldPath
+		}
+		val, err := paved.GetValue(*p.FromFieldPath)
+		pt.Sources = append(pt.Sources, PatchSource{FieldPath: *p.FromFieldPath, Value: val, Found: err == nil})
+		if err != nil {
+			return notFound(pt, p, err)
+		}
+		in = val
+	}
+
+	for _, t := range p.Transforms {
+		tt := TransformTrace{Type: t.Type, Input: in}
+		out, err := icomposite.Resolve(t, in)
+		if err != nil {
+			tt.Error = err.Error()
+			pt.Transforms = append(pt.Transforms, tt)
+			return failed(pt, errors.Wrapf(err, errFmtTransform, len(pt.Transforms)-1))
+		}
+		tt.Output = out
+		pt.Transforms = append(pt.Transforms, tt)
+		in = out
+	}
+
+	pt.Value = in
+	pt.Outcome = PatchOutcomeApplied
+	return pt
+}
+
+// notFound records a source field that was not found, which skips the patch
+// if the source is optional and otherwise fails it.
+func notFound(pt PatchTrace, p xpextv1.Patch, err error) PatchTrace {
+	if icomposite.IsOptionalFieldPathNotFound(err, p.Policy) {
+		pt.Outcome = PatchOutcomeSkipped
+		pt.Error = err.Error()
+		return pt
+	}
+	return failed(pt, err)
+}
+
+func failed(pt PatchTrace, err error) PatchTrace {
+	pt.Outcome = PatchOutcomeFailed
+	pt.Error = err.Error()
+	return pt
+}
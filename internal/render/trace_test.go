@@ -0,0 +1,159 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestTrace(t *testing.T) {
+	region := func(resource string) PatchTrace {
+		return PatchTrace{
+			Resource:    resource,
+			Type:        xpextv1.PatchTypeFromCompositeFieldPath,
+			Sources:     []PatchSource{{FieldPath: "spec.region", Value: "us-east-1", Found: true}},
+			ToFieldPath: "spec.forProvider.region",
+			Value:       "us-east-1",
+			Outcome:     PatchOutcomeApplied,
+		}
+	}
+
+	cases := map[string]struct {
+		reason  string
+		patches []xpextv1.Patch
+		want    []PatchTrace
+	}{
+		"Composition": {
+			reason: "Each patch should be traced, including the transform that failed.",
+			want: []PatchTrace{
+				region("bucket"),
+				region("versioning"),
+				{
+					Resource:    "versioning",
+					Index:       1,
+					Type:        xpextv1.PatchTypeFromCompositeFieldPath,
+					Sources:     []PatchSource{{FieldPath: "spec.versioning", Value: false, Found: true}},
+					Transforms:  []TransformTrace{{Type: xpextv1.TransformTypeMap, Input: false, Error: "map transform could not resolve: type bool is not supported for map transform"}},
+					ToFieldPath: "spec.forProvider.versioningConfiguration[0].status",
+					Outcome:     PatchOutcomeFailed,
+					Error:       "transform at index 0 returned error: map transform could not resolve: type bool is not supported for map transform",
+				},
+			},
+		},
+		"Transforms": {
+			reason: "The intermediate value of each transform should be traced.",
+			patches: []xpextv1.Patch{{
+				FromFieldPath: pointer.String("spec.region"),
+				ToFieldPath:   pointer.String("metadata.name"),
+				Transforms: []xpextv1.Transform{
+					{Type: xpextv1.TransformTypeString, String: &xpextv1.StringTransform{Type: xpextv1.StringTransformTypeFormat, Format: pointer.String("bucket-%s")}},
+					{Type: xpextv1.TransformTypeString, String: &xpextv1.StringTransform{Type: xpextv1.StringTransformTypeConvert, Convert: func() *xpextv1.StringConversionType { c := xpextv1.StringConversionTypeToUpper; return &c }()}},
+				},
+			}},
+			want: []PatchTrace{{
+				Resource: "bucket",
+				Type:     xpextv1.PatchTypeFromCompositeFieldPath,
+				Sources:  []PatchSource{{FieldPath: "spec.region", Value: "us-east-1", Found: true}},
+				Transforms: []TransformTrace{
+					{Type: xpextv1.TransformTypeString, Input: "us-east-1", Output: "bucket-us-east-1"},
+					{Type: xpextv1.TransformTypeString, Input: "bucket-us-east-1", Output: "BUCKET-US-EAST-1"},
+				},
+				ToFieldPath: "metadata.name",
+				Value:       "BUCKET-US-EAST-1",
+				Outcome:     PatchOutcomeApplied,
+			}},
+		},
+		"OptionalSourceNotFound": {
+			reason: "A patch from an optional field that was not found should be skipped.",
+			patches: []xpextv1.Patch{{
+				FromFieldPath: pointer.String("spec.missing"),
+			}},
+			want: []PatchTrace{{
+				Resource:    "bucket",
+				Type:        xpextv1.PatchTypeFromCompositeFieldPath,
+				Sources:     []PatchSource{{FieldPath: "spec.missing"}},
+				ToFieldPath: "spec.missing",
+				Outcome:     PatchOutcomeSkipped,
+				Error:       "spec.missing: no such field",
+			}},
+		},
+		"RequiredSourceNotFound": {
+			reason: "A patch from a required field that was not found should fail.",
+			patches: []xpextv1.Patch{{
+				FromFieldPath: pointer.String("spec.missing"),
+				Policy:        &xpextv1.PatchPolicy{FromFieldPath: func() *xpextv1.FromFieldPathPolicy { p := xpextv1.FromFieldPathPolicyRequired; return &p }()},
+			}},
+			want: []PatchTrace{{
+				Resource:    "bucket",
+				Type:        xpextv1.PatchTypeFromCompositeFieldPath,
+				Sources:     []PatchSource{{FieldPath: "spec.missing"}},
+				ToFieldPath: "spec.missing",
+				Outcome:     PatchOutcomeFailed,
+				Error:       "spec.missing: no such field",
+			}},
+		},
+		"Combine": {
+			reason: "The values of each variable of a combine patch and the value they were combined into should be traced.",
+			patches: []xpextv1.Patch{{
+				Type: xpextv1.PatchTypeCombineFromComposite,
+				Combine: &xpextv1.Combine{
+					Variables: []xpextv1.CombineVariable{{FromFieldPath: "metadata.name"}, {FromFieldPath: "spec.region"}},
+					Strategy:  xpextv1.CombineStrategyString,
+					String:    &xpextv1.StringCombine{Format: "%s-%s"},
+				},
+				ToFieldPath: pointer.String("metadata.name"),
+			}},
+			want: []PatchTrace{{
+				Resource: "bucket",
+				Type:     xpextv1.PatchTypeCombineFromComposite,
+				Sources: []PatchSource{
+					{FieldPath: "metadata.name", Value: "example", Found: true},
+					{FieldPath: "spec.region", Value: "us-east-1", Found: true},
+				},
+				Combined:    "example-us-east-1",
+				ToFieldPath: "metadata.name",
+				Value:       "example-us-east-1",
+				Outcome:     PatchOutcomeApplied,
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in, err := ParseInputs(readFile(t, "composition.yaml"), readFile(t, "xrd.yaml"), readFile(t, "xr.yaml"))
+			if err != nil {
+				t.Fatalf("ParseInputs(...): %s", err)
+			}
+			if tc.patches != nil {
+				in.Composition.Spec.Resources = in.Composition.Spec.Resources[:1]
+				in.Composition.Spec.Resources[0].Patches = tc.patches
+			}
+
+			got, err := NewRenderer().Trace(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Trace(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nTrace(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
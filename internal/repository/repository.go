@@ -0,0 +1,223 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package repository manages Upbound package repositories.
+package repository
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"github.com/google/uuid"
+
+	"github.com/upbound/up-sdk-go"
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+	"github.com/upbound/up-sdk-go/service/common"
+	"github.com/upbound/up-sdk-go/service/repositories"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	maxItems = 100
+
+	reposPath       = "v1/repositories"
+	permissionsPath = "permissions/teams"
+)
+
+// Permission is the permission a team has on a repository.
+type Permission string
+
+const (
+	// PermissionRead allows pulling packages from a repository.
+	PermissionRead Permission = "read"
+	// PermissionWrite allows pushing packages to a repository.
+	PermissionWrite Permission = "write"
+	// PermissionAdmin allows managing a repository.
+	PermissionAdmin Permission = "admin"
+)
+
+// Response is a normalized repository response.
+type Response struct {
+	Name     string
+	Type     string
+	Public   bool
+	Official bool
+	Version  string
+}
+
+// Tag is a published version of a package in a repository.
+type Tag struct {
+	Version string
+	Digest  string
+	Status  string
+}
+
+// Options configure a repository.
+type Options struct {
+	// Public repositories may be pulled without credentials.
+	Public bool
+}
+
+type repoClient interface {
+	Get(ctx context.Context, account, name string) (*repositories.RepositoryResponse, error)
+	List(ctx context.Context, account string, opts ...common.ListOption) (*repositories.RepositoryListResponse, error)
+	Delete(ctx context.Context, account, name string) error
+}
+
+type repoParameters struct {
+	Public bool `json:"public"`
+}
+
+type permissionParameters struct {
+	Permission Permission `json:"permission"`
+}
+
+// Client is the client used for managing repositories in an Upbound account.
+type Client struct {
+	repos repoClient
+	api   up.Client
+
+	// Upbound account
+	account string
+}
+
+// New instantiates a new Client.
+func New(repos repoClient, api up.Client, account string) *Client {
+	return &Client{
+		repos:   repos,
+		api:     api,
+		account: account,
+	}
+}
+
+// NewFromConfig instantiates a new Client from an Upbound SDK config.
+func NewFromConfig(cfg *up.Config, account string) *Client {
+	return New(repositories.NewClient(cfg), cfg.Client, account)
+}
+
+// Get the repository with the given name.
+func (c *Client) Get(ctx context.Context, name string) (*Response, error) {
+	r, err := c.repos.Get(ctx, c.account, name)
+	if sdkerrs.IsNotFound(err) {
+		return nil, failure.NotFound(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convert(&r.Repository), nil
+}
+
+// List all repositories in the account.
+func (c *Client) List(ctx context.Context) ([]*Response, error) {
+	l, err := c.repos.List(ctx, c.account, common.WithSize(maxItems))
+	if err != nil {
+		return nil, err
+	}
+	resps := make([]*Response, len(l.Repositories))
+	for i := range l.Repositories {
+		resps[i] = convert(&l.Repositories[i])
+	}
+	return resps, nil
+}
+
+// Create a repository with the given name, or update the configuration of an
+// existing one.
+func (c *Client) Create(ctx context.Context, name string, opts Options) error {
+	req, err := c.api.NewRequest(ctx, http.MethodPut, reposPath, path.Join(c.account, name), &repoParameters{Public: opts.Public})
+	if err != nil {
+		return err
+	}
+	return c.api.Do(req, nil)
+}
+
+// SetVisibility makes the repository with the given name public or private.
+func (c *Client) SetVisibility(ctx context.Context, name string, public bool) error {
+	return c.Create(ctx, name, Options{Public: public})
+}
+
+// Delete the repository with the given name.
+func (c *Client) Delete(ctx context.Context, name string) error {
+	err := c.repos.Delete(ctx, c.account, name)
+	if sdkerrs.IsNotFound(err) {
+		return failure.NotFound(err)
+	}
+	return err
+}
+
+// GrantTeam grants a team the supplied permission on the repository with
+// the given name, replacing any permission it already has.
+func (c *Client) GrantTeam(ctx context.Context, name string, teamID uuid.UUID, p Permission) error {
+	req, err := c.api.NewRequest(ctx, http.MethodPut, reposPath, path.Join(c.account, name, permissionsPath, teamID.String()), &permissionParameters{Permission: p})
+	if err != nil {
+		return err
+	}
+	return c.do(req)
+}
+
+// RevokeTeam revokes the permission of a team on the repository with the
+// given name.
+func (c *Client) RevokeTeam(ctx context.Context, name string, teamID uuid.UUID) error {
+	req, err := c.api.NewRequest(ctx, http.MethodDelete, reposPath, path.Join(c.account, name, permissionsPath, teamID.String()), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req)
+}
+
+// ListTags lists the package versions pushed to the repository with the given
+// name.
+func (c *Client) ListTags(ctx context.Context, name string) ([]*Tag, error) {
+	r, err := c.repos.Get(ctx, c.account, name)
+	if sdkerrs.IsNotFound(err) {
+		return nil, failure.NotFound(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*Tag, len(r.Versions))
+	for i, v := range r.Versions {
+		tags[i] = &Tag{
+			Version: v.Version,
+			Digest:  v.Digest,
+			Status:  string(v.Status),
+		}
+	}
+	return tags, nil
+}
+
+// do sends the supplied request, translating not found errors.
+func (c *Client) do(req *http.Request) error {
+	err := c.api.Do(req, nil)
+	if sdkerrs.IsNotFound(err) {
+		return failure.NotFound(err)
+	}
+	return err
+}
+
+func convert(r *repositories.Repository) *Response {
+	resp := &Response{
+		Name:     r.Name,
+		Public:   r.Public,
+		Official: r.Official,
+	}
+	if r.Type != nil {
+		resp.Type = string(*r.Type)
+	}
+	if r.CurrentVersion != nil {
+		resp.Version = *r.CurrentVersion
+	}
+	return resp
+}
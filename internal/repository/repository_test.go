@@ -0,0 +1,204 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"k8s.io/utils/pointer"
+
+	sdkerrs "github.com/upbound/up-sdk-go/errors"
+	"github.com/upbound/up-sdk-go/service/common"
+	"github.com/upbound/up-sdk-go/service/repositories"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+var (
+	acct = "demo"
+
+	sdkNotFound = &sdkerrs.Error{
+		Status: http.StatusNotFound,
+		Detail: pointer.String(`repository "missing" not found`),
+		Title:  http.StatusText(http.StatusNotFound),
+	}
+)
+
+type mockRepos struct {
+	err error
+}
+
+func (m *mockRepos) Get(_ context.Context, _, name string) (*repositories.RepositoryResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	typ := repositories.RepositoryTypeProvider
+	return &repositories.RepositoryResponse{
+		Repository: repositories.Repository{
+			Name:           name,
+			Type:           &typ,
+			Public:         true,
+			CurrentVersion: pointer.String("v1.1.0"),
+		},
+		Versions: []repositories.Package{
+			{Version: "v1.0.0", Digest: "sha256:a", Status: repositories.PackageStatusPublished},
+			{Version: "v1.1.0", Digest: "sha256:b", Status: repositories.PackageStatusAccepted},
+		},
+	}, nil
+}
+
+func (m *mockRepos) List(_ context.Context, _ string, _ ...common.ListOption) (*repositories.RepositoryListResponse, error) {
+	return nil, m.err
+}
+
+func (m *mockRepos) Delete(_ context.Context, _, _ string) error {
+	return m.err
+}
+
+// request is a request sent through mockAPI.
+type request struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+type mockAPI struct {
+	reqs []request
+	err  error
+}
+
+func (m *mockAPI) NewRequest(ctx context.Context, method, prefix, urlPath string, body interface{}) (*http.Request, error) {
+	r := request{Method: method, Path: prefix + "/" + urlPath}
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = string(b)
+	}
+	m.reqs = append(m.reqs, r)
+	return http.NewRequestWithContext(ctx, method, "https://api.upbound.io/"+r.Path, nil)
+}
+
+func (m *mockAPI) Do(_ *http.Request, _ interface{}) error {
+	return m.err
+}
+
+func TestGet(t *testing.T) {
+	type want struct {
+		resp *Response
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		repos  *mockRepos
+		want   want
+	}{
+		"Success": {
+			reason: "We should return a normalized repository.",
+			repos:  &mockRepos{},
+			want: want{
+				resp: &Response{Name: "provider-aws", Type: "provider", Public: true, Version: "v1.1.0"},
+			},
+		},
+		"NotFound": {
+			reason: "A missing repository should result in a not found error.",
+			repos:  &mockRepos{err: sdkNotFound},
+			want: want{
+				err: failure.NotFound(sdkNotFound),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(tc.repos, &mockAPI{}, acct).Get(context.Background(), "provider-aws")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resp, got); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestListTags(t *testing.T) {
+	got, err := New(&mockRepos{}, &mockAPI{}, acct).ListTags(context.Background(), "provider-aws")
+	if err != nil {
+		t.Fatalf("ListTags(...): unexpected error: %v", err)
+	}
+	want := []*Tag{
+		{Version: "v1.0.0", Digest: "sha256:a", Status: "published"},
+		{Version: "v1.1.0", Digest: "sha256:b", Status: "accepted"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListTags(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	team := uuid.MustParse("5d7b4a38-7d3c-4d2a-9b0e-6b0f3b1a2c4d")
+	cases := map[string]struct {
+		reason string
+		fn     func(c *Client) error
+		want   []request
+	}{
+		"CreatePrivate": {
+			reason: "Repositories should be created private unless requested otherwise.",
+			fn: func(c *Client) error {
+				return c.Create(context.Background(), "provider-aws", Options{})
+			},
+			want: []request{{Method: http.MethodPut, Path: "v1/repositories/demo/provider-aws", Body: `{"public":false}`}},
+		},
+		"SetVisibility": {
+			reason: "Making a repository public should update it in place.",
+			fn: func(c *Client) error {
+				return c.SetVisibility(context.Background(), "provider-aws", true)
+			},
+			want: []request{{Method: http.MethodPut, Path: "v1/repositories/demo/provider-aws", Body: `{"public":true}`}},
+		},
+		"GrantTeam": {
+			reason: "Granting a team a permission should set the permission of that team.",
+			fn: func(c *Client) error {
+				return c.GrantTeam(context.Background(), "provider-aws", team, PermissionWrite)
+			},
+			want: []request{{Method: http.MethodPut, Path: "v1/repositories/demo/provider-aws/permissions/teams/" + team.String(), Body: `{"permission":"write"}`}},
+		},
+		"RevokeTeam": {
+			reason: "Revoking a team's permission should delete the permission of that team.",
+			fn: func(c *Client) error {
+				return c.RevokeTeam(context.Background(), "provider-aws", team)
+			},
+			want: []request{{Method: http.MethodDelete, Path: "v1/repositories/demo/provider-aws/permissions/teams/" + team.String()}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			api := &mockAPI{}
+			if err := tc.fn(New(&mockRepos{}, api, acct)); err != nil {
+				t.Fatalf("\n%s\nunexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, api.reqs); diff != "" {
+				t.Errorf("\n%s\n-want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// BackupGVK is the GroupVersionKind used for Space Backups.
+	BackupGVK = schema.GroupVersionKind{
+		Group:   "spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "Backup",
+	}
+)
+
+// BackupConfigReference references the SharedBackupConfig used by a Backup.
+type BackupConfigReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Backup represents the Backup CustomResource and extends an
+// unstructured.Unstructured.
+type Backup struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (b *Backup) GetUnstructured() *unstructured.Unstructured {
+	b.SetGroupVersionKind(BackupGVK)
+	return &b.Unstructured
+}
+
+// GetCondition returns the condition for the given xpv1.ConditionType if it
+// exists, otherwise returns nil.
+func (b *Backup) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return GetCondition(b.Object, ct)
+}
+
+// GetControlPlane returns the name of the control plane being backed up.
+func (b *Backup) GetControlPlane() string {
+	ctp, err := fieldpath.Pave(b.Object).GetString("spec.controlPlane")
+	if err != nil {
+		return ""
+	}
+	return ctp
+}
+
+// SetControlPlane sets the name of the control plane to back up.
+func (b *Backup) SetControlPlane(name string) {
+	_ = fieldpath.Pave(b.Object).SetString("spec.controlPlane", name)
+}
+
+// GetConfigReference returns the reference to the backup's configuration.
+func (b *Backup) GetConfigReference() BackupConfigReference {
+	ref := BackupConfigReference{}
+	_ = fieldpath.Pave(b.Object).GetValueInto("spec.configRef", &ref)
+	return ref
+}
+
+// SetConfigReference sets the reference to the backup's configuration.
+func (b *Backup) SetConfigReference(ref BackupConfigReference) {
+	_ = fieldpath.Pave(b.Object).SetValue("spec.configRef", ref)
+}
+
+// GetTTL returns how long the backup is retained, e.g. 168h.
+func (b *Backup) GetTTL() string {
+	ttl, err := fieldpath.Pave(b.Object).GetString("spec.ttl")
+	if err != nil {
+		return ""
+	}
+	return ttl
+}
+
+// SetTTL sets how long the backup is retained.
+func (b *Backup) SetTTL(ttl string) {
+	_ = fieldpath.Pave(b.Object).SetString("spec.ttl", ttl)
+}
+
+// GetDeletionPolicy returns whether the backup's data is deleted along with
+// the Backup.
+func (b *Backup) GetDeletionPolicy() xpv1.DeletionPolicy {
+	p, err := fieldpath.Pave(b.Object).GetString("spec.deletionPolicy")
+	if err != nil {
+		return ""
+	}
+	return xpv1.DeletionPolicy(p)
+}
+
+// SetDeletionPolicy sets whether the backup's data is deleted along with the
+// Backup.
+func (b *Backup) SetDeletionPolicy(p xpv1.DeletionPolicy) {
+	_ = fieldpath.Pave(b.Object).SetString("spec.deletionPolicy", string(p))
+}
+
+// GetPhase returns the phase of the backup, e.g. Pending, InProgress,
+// Failed, or Completed.
+func (b *Backup) GetPhase() string {
+	phase, err := fieldpath.Pave(b.Object).GetString("status.phase")
+	if err != nil {
+		return ""
+	}
+	return phase
+}
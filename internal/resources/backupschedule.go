@@ -0,0 +1,131 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// BackupScheduleGVK is the GroupVersionKind used for Space
+	// BackupSchedules.
+	BackupScheduleGVK = schema.GroupVersionKind{
+		Group:   "spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "BackupSchedule",
+	}
+)
+
+// BackupSchedule represents the BackupSchedule CustomResource and extends an
+// unstructured.Unstructured.
+type BackupSchedule struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (b *BackupSchedule) GetUnstructured() *unstructured.Unstructured {
+	b.SetGroupVersionKind(BackupScheduleGVK)
+	return &b.Unstructured
+}
+
+// GetCondition returns the condition for the given xpv1.ConditionType if it
+// exists, otherwise returns nil.
+func (b *BackupSchedule) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return GetCondition(b.Object, ct)
+}
+
+// GetControlPlane returns the name of the control plane backed up.
+func (b *BackupSchedule) GetControlPlane() string {
+	ctp, err := fieldpath.Pave(b.Object).GetString("spec.controlPlane")
+	if err != nil {
+		return ""
+	}
+	return ctp
+}
+
+// SetControlPlane sets the name of the control plane to back up.
+func (b *BackupSchedule) SetControlPlane(name string) {
+	_ = fieldpath.Pave(b.Object).SetString("spec.controlPlane", name)
+}
+
+// GetSchedule returns the cron schedule backups are taken on.
+func (b *BackupSchedule) GetSchedule() string {
+	s, err := fieldpath.Pave(b.Object).GetString("spec.schedule")
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// SetSchedule sets the cron schedule backups are taken on, e.g. "@daily".
+func (b *BackupSchedule) SetSchedule(s string) {
+	_ = fieldpath.Pave(b.Object).SetString("spec.schedule", s)
+}
+
+// GetConfigReference returns the reference to the configuration of the
+// scheduled backups.
+func (b *BackupSchedule) GetConfigReference() BackupConfigReference {
+	ref := BackupConfigReference{}
+	_ = fieldpath.Pave(b.Object).GetValueInto("spec.configRef", &ref)
+	return ref
+}
+
+// SetConfigReference sets the reference to the configuration of the
+// scheduled backups.
+func (b *BackupSchedule) SetConfigReference(ref BackupConfigReference) {
+	_ = fieldpath.Pave(b.Object).SetValue("spec.configRef", ref)
+}
+
+// GetTTL returns how long scheduled backups are retained.
+func (b *BackupSchedule) GetTTL() string {
+	ttl, err := fieldpath.Pave(b.Object).GetString("spec.ttl")
+	if err != nil {
+		return ""
+	}
+	return ttl
+}
+
+// SetTTL sets how long scheduled backups are retained.
+func (b *BackupSchedule) SetTTL(ttl string) {
+	_ = fieldpath.Pave(b.Object).SetString("spec.ttl", ttl)
+}
+
+// IsSuspended returns true if no backups are taken.
+func (b *BackupSchedule) IsSuspended() bool {
+	s, err := fieldpath.Pave(b.Object).GetBool("spec.suspend")
+	if err != nil {
+		return false
+	}
+	return s
+}
+
+// SetSuspended sets whether backups are taken.
+func (b *BackupSchedule) SetSuspended(s bool) {
+	_ = fieldpath.Pave(b.Object).SetBool("spec.suspend", s)
+}
+
+// GetLastBackup returns the name of the most recent Backup taken on the
+// schedule.
+func (b *BackupSchedule) GetLastBackup() string {
+	n, err := fieldpath.Pave(b.Object).GetString("status.lastBackup")
+	if err != nil {
+		return ""
+	}
+	return n
+}
@@ -15,13 +15,46 @@
 package resources
 
 import (
+	"strings"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 )
 
+const errFmtInvalidChannel = "invalid auto-upgrade channel %q: must be one of None, Patch, Stable, or Rapid"
+
+// CrossplaneChannel is an auto-upgrade channel for the Crossplane version of a
+// ControlPlane.
+type CrossplaneChannel string
+
+const (
+	// CrossplaneChannelNone disables auto-upgrades.
+	CrossplaneChannelNone CrossplaneChannel = "None"
+	// CrossplaneChannelPatch upgrades to the latest patch release of the
+	// current minor version.
+	CrossplaneChannelPatch CrossplaneChannel = "Patch"
+	// CrossplaneChannelStable upgrades to the latest patch release of the
+	// previous minor version.
+	CrossplaneChannelStable CrossplaneChannel = "Stable"
+	// CrossplaneChannelRapid upgrades to the latest release.
+	CrossplaneChannelRapid CrossplaneChannel = "Rapid"
+)
+
+// ParseCrossplaneChannel returns the auto-upgrade channel with the supplied
+// name, ignoring case.
+func ParseCrossplaneChannel(s string) (CrossplaneChannel, error) {
+	for _, ch := range []CrossplaneChannel{CrossplaneChannelNone, CrossplaneChannelPatch, CrossplaneChannelStable, CrossplaneChannelRapid} {
+		if strings.EqualFold(s, string(ch)) {
+			return ch, nil
+		}
+	}
+	return "", errors.Errorf(errFmtInvalidChannel, s)
+}
+
 var (
 	// ControlPlaneGVK is the GroupVersionKind used for
 	// provider-kubernetes ProviderConfig.
@@ -47,21 +80,12 @@ func (c *ControlPlane) GetUnstructured() *unstructured.Unstructured {
 // GetCondition returns the condition for the given xpv1.ConditionType if it
 // exists, otherwise returns nil.
 func (c *ControlPlane) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
-	conditioned := xpv1.ConditionedStatus{}
-	// The path is directly `status` because conditions are inline.
-	if err := fieldpath.Pave(c.Object).GetValueInto("status", &conditioned); err != nil {
-		return xpv1.Condition{}
-	}
-	return conditioned.GetCondition(ct)
+	return GetCondition(c.Object, ct)
 }
 
 // SetConditions of this composite resource claim.
 func (c *ControlPlane) SetConditions(conditions ...xpv1.Condition) {
-	conditioned := xpv1.ConditionedStatus{}
-	// The path is directly `status` because conditions are inline.
-	_ = fieldpath.Pave(c.Object).GetValueInto("status", &conditioned)
-	conditioned.SetConditions(conditions...)
-	_ = fieldpath.Pave(c.Object).SetValue("status.conditions", conditioned.Conditions)
+	SetConditions(c.Object, conditions...)
 }
 
 // GetControlPlaneID returns the MXP ID associated with the ControlPlane.
@@ -89,3 +113,46 @@ func (c *ControlPlane) GetConnectionSecretToReference() *xpv1.SecretReference {
 func (c *ControlPlane) SetWriteConnectionSecretToReference(ref *xpv1.SecretReference) {
 	_ = fieldpath.Pave(c.Object).SetValue("spec.writeConnectionSecretToRef", ref)
 }
+
+// GetCrossplaneVersion returns the Crossplane version of the control plane.
+func (c *ControlPlane) GetCrossplaneVersion() string {
+	v, err := GetString(c.Object, "spec.crossplane.version")
+	if err != nil {
+		return ""
+	}
+	return v
+}
+
+// SetCrossplaneVersion sets the Crossplane version of the control plane.
+func (c *ControlPlane) SetCrossplaneVersion(v string) {
+	_ = SetValue(c.Object, "spec.crossplane.version", v)
+}
+
+// GetCrossplaneChannel returns the auto-upgrade channel of the control plane.
+func (c *ControlPlane) GetCrossplaneChannel() CrossplaneChannel {
+	ch, err := GetString(c.Object, "spec.crossplane.autoUpgrade.channel")
+	if err != nil {
+		return ""
+	}
+	return CrossplaneChannel(ch)
+}
+
+// SetCrossplaneChannel sets the auto-upgrade channel of the control plane.
+func (c *ControlPlane) SetCrossplaneChannel(ch CrossplaneChannel) {
+	_ = SetValue(c.Object, "spec.crossplane.autoUpgrade.channel", string(ch))
+}
+
+// GetClass returns the class of the control plane, which determines the
+// resources allocated to it.
+func (c *ControlPlane) GetClass() string {
+	class, err := GetString(c.Object, "spec.class")
+	if err != nil {
+		return ""
+	}
+	return class
+}
+
+// SetClass sets the class of the control plane.
+func (c *ControlPlane) SetClass(class string) {
+	_ = SetValue(c.Object, "spec.class", class)
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestParseCrossplaneChannel(t *testing.T) {
+	type want struct {
+		ch  CrossplaneChannel
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      string
+		want   want
+	}{
+		"Exact": {
+			reason: "Channels should be parsed by name.",
+			s:      "Stable",
+			want:   want{ch: CrossplaneChannelStable},
+		},
+		"Lowercase": {
+			reason: "Channel names should be matched ignoring case.",
+			s:      "none",
+			want:   want{ch: CrossplaneChannelNone},
+		},
+		"Invalid": {
+			reason: "Unknown channels should be rejected.",
+			s:      "fast",
+			want:   want{err: errors.Errorf(errFmtInvalidChannel, "fast")},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ch, err := ParseCrossplaneChannel(tc.s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseCrossplaneChannel(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ch, ch); diff != "" {
+				t.Errorf("\n%s\nParseCrossplaneChannel(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
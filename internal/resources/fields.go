@@ -0,0 +1,168 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+// A MissingFieldError is returned when a field path does not exist in an
+// object.
+type MissingFieldError struct {
+	Path string
+}
+
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("%s: no such field", e.Path)
+}
+
+// A WrongTypeError is returned when the value at a field path is not of the
+// expected type.
+type WrongTypeError struct {
+	Path string
+	Want string
+	Got  any
+}
+
+func (e *WrongTypeError) Error() string {
+	return fmt.Sprintf("%s: value is of type %T, not %s", e.Path, e.Got, e.Want)
+}
+
+// IsMissingField returns true if the supplied error indicates that a field
+// path does not exist.
+func IsMissingField(err error) bool {
+	var e *MissingFieldError
+	return errors.As(err, &e)
+}
+
+// IsWrongType returns true if the supplied error indicates that the value at a
+// field path is not of the expected type.
+func IsWrongType(err error) bool {
+	var e *WrongTypeError
+	return errors.As(err, &e)
+}
+
+// GetValue returns the value at the supplied field path of the supplied
+// object, e.g. an unstructured.Unstructured's Object.
+func GetValue(obj map[string]any, path string) (any, error) {
+	v, err := fieldpath.Pave(obj).GetValue(path)
+	if fieldpath.IsNotFound(err) {
+		return nil, &MissingFieldError{Path: path}
+	}
+	return v, err
+}
+
+// GetString returns the string at the supplied field path.
+func GetString(obj map[string]any, path string) (string, error) {
+	v, err := GetValue(obj, path)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", &WrongTypeError{Path: path, Want: "string", Got: v}
+	}
+	return s, nil
+}
+
+// GetBool returns the bool at the supplied field path.
+func GetBool(obj map[string]any, path string) (bool, error) {
+	v, err := GetValue(obj, path)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, &WrongTypeError{Path: path, Want: "bool", Got: v}
+	}
+	return b, nil
+}
+
+// GetInt64 returns the integer at the supplied field path. Integral floats,
+// as produced by decoding JSON, are accepted.
+func GetInt64(obj map[string]any, path string) (int64, error) {
+	v, err := GetValue(obj, path)
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case float64:
+		if n == float64(int64(n)) {
+			return int64(n), nil
+		}
+	}
+	return 0, &WrongTypeError{Path: path, Want: "int64", Got: v}
+}
+
+// GetBytes returns the base64 decoded string at the supplied field path, e.g.
+// a key of a Secret's data.
+func GetBytes(obj map[string]any, path string) ([]byte, error) {
+	s, err := GetString(obj, path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, &WrongTypeError{Path: path, Want: "base64 encoded string", Got: s}
+	}
+	return b, nil
+}
+
+// GetValueInto decodes the value at the supplied field path into the supplied
+// value, which must be a pointer.
+func GetValueInto(obj map[string]any, path string, into any) error {
+	v, err := GetValue(obj, path)
+	if err != nil {
+		return err
+	}
+	if err := fieldpath.Pave(map[string]any{"v": v}).GetValueInto("v", into); err != nil {
+		return &WrongTypeError{Path: path, Want: fmt.Sprintf("%T", into), Got: v}
+	}
+	return nil
+}
+
+// SetValue sets the value at the supplied field path, creating any
+// intermediate objects and arrays.
+func SetValue(obj map[string]any, path string, v any) error {
+	return fieldpath.Pave(obj).SetValue(path, v)
+}
+
+// GetCondition returns the condition of the supplied type from the status of
+// the supplied object. A condition of Unknown status is returned if the
+// object has no such condition.
+func GetCondition(obj map[string]any, ct xpv1.ConditionType) xpv1.Condition {
+	conditioned := xpv1.ConditionedStatus{}
+	// The path is directly `status` because conditions are inline.
+	_ = GetValueInto(obj, "status", &conditioned)
+	return conditioned.GetCondition(ct)
+}
+
+// SetConditions sets the supplied conditions in the status of the supplied
+// object, replacing any existing conditions of the same type.
+func SetConditions(obj map[string]any, c ...xpv1.Condition) {
+	conditioned := xpv1.ConditionedStatus{}
+	_ = GetValueInto(obj, "status", &conditioned)
+	conditioned.SetConditions(c...)
+	_ = SetValue(obj, "status.conditions", conditioned.Conditions)
+}
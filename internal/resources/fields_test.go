@@ -0,0 +1,130 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+func TestGetters(t *testing.T) {
+	obj := map[string]any{
+		"spec": map[string]any{
+			"name":     "cool",
+			"enabled":  true,
+			"replicas": float64(3),
+			"ratio":    0.5,
+		},
+		"data": map[string]any{
+			"kubeconfig": "aGVsbG8=",
+			"invalid":    "!!",
+		},
+	}
+
+	type want struct {
+		v   any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		get    func() (any, error)
+		want   want
+	}{
+		"String": {
+			reason: "A string field should be returned.",
+			get:    func() (any, error) { return GetString(obj, "spec.name") },
+			want:   want{v: "cool"},
+		},
+		"StringMissing": {
+			reason: "A missing field should return a MissingFieldError.",
+			get:    func() (any, error) { return GetString(obj, "spec.missing") },
+			want:   want{v: "", err: &MissingFieldError{Path: "spec.missing"}},
+		},
+		"StringWrongType": {
+			reason: "A field of another type should return a WrongTypeError.",
+			get:    func() (any, error) { return GetString(obj, "spec.enabled") },
+			want:   want{v: "", err: &WrongTypeError{Path: "spec.enabled", Want: "string", Got: true}},
+		},
+		"Bool": {
+			reason: "A bool field should be returned.",
+			get:    func() (any, error) { return GetBool(obj, "spec.enabled") },
+			want:   want{v: true},
+		},
+		"Int64": {
+			reason: "An integral float field should be returned as an int64.",
+			get:    func() (any, error) { return GetInt64(obj, "spec.replicas") },
+			want:   want{v: int64(3)},
+		},
+		"Int64Fractional": {
+			reason: "A fractional float field should return a WrongTypeError.",
+			get:    func() (any, error) { return GetInt64(obj, "spec.ratio") },
+			want:   want{v: int64(0), err: &WrongTypeError{Path: "spec.ratio", Want: "int64", Got: 0.5}},
+		},
+		"Bytes": {
+			reason: "A base64 encoded field should be decoded.",
+			get:    func() (any, error) { return GetBytes(obj, "data.kubeconfig") },
+			want:   want{v: []byte("hello")},
+		},
+		"BytesInvalid": {
+			reason: "A field that is not base64 encoded should return a WrongTypeError.",
+			get:    func() (any, error) { return GetBytes(obj, "data.invalid") },
+			want:   want{v: []byte(nil), err: &WrongTypeError{Path: "data.invalid", Want: "base64 encoded string", Got: "!!"}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := tc.get()
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.v, v); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	_, missing := GetString(map[string]any{}, "spec.name")
+	_, wrong := GetBool(map[string]any{"spec": map[string]any{"name": "cool"}}, "spec.name")
+
+	if !IsMissingField(missing) || IsWrongType(missing) {
+		t.Errorf("IsMissingField(%v): want true, IsWrongType: want false", missing)
+	}
+	if !IsWrongType(wrong) || IsMissingField(wrong) {
+		t.Errorf("IsWrongType(%v): want true, IsMissingField: want false", wrong)
+	}
+}
+
+func TestConditions(t *testing.T) {
+	obj := map[string]any{}
+
+	got := GetCondition(obj, xpv1.TypeReady)
+	if diff := cmp.Diff(xpv1.Condition{Type: xpv1.TypeReady, Status: corev1.ConditionUnknown}, got); diff != "" {
+		t.Errorf("GetCondition(...): an object without conditions: -want, +got:\n%s", diff)
+	}
+
+	SetConditions(obj, xpv1.Available())
+	got = GetCondition(obj, xpv1.TypeReady)
+	if diff := cmp.Diff(xpv1.Available(), got, cmpopts.IgnoreFields(xpv1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("GetCondition(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// GroupLabel is the label that marks a namespace as a Space group.
+	GroupLabel = "spaces.upbound.io/group"
+	// GroupProtectedLabel is the label that prevents a Space group from
+	// being deleted.
+	GroupProtectedLabel = "spaces.upbound.io/group-protected"
+)
+
+var (
+	// GroupGVK is the GroupVersionKind used for Space groups, which are
+	// labelled namespaces.
+	GroupGVK = schema.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Namespace",
+	}
+)
+
+// Group represents a Space group, the namespace that contains control planes
+// and the resources shared between them, and extends an
+// unstructured.Unstructured.
+type Group struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured, labelled
+// as a Space group.
+func (g *Group) GetUnstructured() *unstructured.Unstructured {
+	g.SetGroupVersionKind(GroupGVK)
+	g.setLabel(GroupLabel, "true")
+	return &g.Unstructured
+}
+
+// IsGroup returns true if the namespace is labelled as a Space group.
+func (g *Group) IsGroup() bool {
+	return g.GetLabels()[GroupLabel] == "true"
+}
+
+// IsProtected returns true if the group is protected from deletion.
+func (g *Group) IsProtected() bool {
+	return g.GetLabels()[GroupProtectedLabel] == "true"
+}
+
+// SetProtected sets whether the group is protected from deletion.
+func (g *Group) SetProtected(p bool) {
+	if p {
+		g.setLabel(GroupProtectedLabel, "true")
+		return
+	}
+	l := g.GetLabels()
+	delete(l, GroupProtectedLabel)
+	g.SetLabels(l)
+}
+
+func (g *Group) setLabel(k, v string) {
+	l := g.GetLabels()
+	if l == nil {
+		l = map[string]string{}
+	}
+	l[k] = v
+	g.SetLabels(l)
+}
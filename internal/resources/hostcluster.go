@@ -36,12 +36,7 @@ func (h *HostCluster) GetUnstructured() *unstructured.Unstructured {
 // GetCondition returns the condition for the given xpv1.ConditionType if it
 // exists, otherwise returns nil.
 func (h *HostCluster) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
-	conditioned := xpv1.ConditionedStatus{}
-	// The path is directly `status` because conditions are inline.
-	if err := fieldpath.Pave(h.Object).GetValueInto("status", &conditioned); err != nil {
-		return xpv1.Condition{}
-	}
-	return conditioned.GetCondition(ct)
+	return GetCondition(h.Object, ct)
 }
 
 // SetCompositionSelector of this composite resource claim.
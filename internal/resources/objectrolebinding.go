@@ -0,0 +1,84 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// ObjectRoleBindingGVK is the GroupVersionKind used for Space
+	// ObjectRoleBindings.
+	ObjectRoleBindingGVK = schema.GroupVersionKind{
+		Group:   "authorization.spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "ObjectRoleBinding",
+	}
+)
+
+// ObjectRoleBindingObject references the object roles are bound on.
+type ObjectRoleBindingObject struct {
+	APIGroup string `json:"apiGroup"`
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+}
+
+// ObjectRoleBindingSubject is a subject bound to a role on an object.
+type ObjectRoleBindingSubject struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// ObjectRoleBinding represents the ObjectRoleBinding CustomResource and
+// extends an unstructured.Unstructured.
+type ObjectRoleBinding struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (o *ObjectRoleBinding) GetUnstructured() *unstructured.Unstructured {
+	o.SetGroupVersionKind(ObjectRoleBindingGVK)
+	return &o.Unstructured
+}
+
+// GetObject returns the object roles are bound on.
+func (o *ObjectRoleBinding) GetObject() ObjectRoleBindingObject {
+	obj := ObjectRoleBindingObject{}
+	_ = fieldpath.Pave(o.Object).GetValueInto("spec.object", &obj)
+	return obj
+}
+
+// SetObject sets the object roles are bound on.
+func (o *ObjectRoleBinding) SetObject(obj ObjectRoleBindingObject) {
+	_ = fieldpath.Pave(o.Object).SetValue("spec.object", obj)
+}
+
+// GetSubjects returns the subjects bound to roles on the object.
+func (o *ObjectRoleBinding) GetSubjects() []ObjectRoleBindingSubject {
+	out := []ObjectRoleBindingSubject{}
+	if err := fieldpath.Pave(o.Object).GetValueInto("spec.subjects", &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetSubjects sets the subjects bound to roles on the object.
+func (o *ObjectRoleBinding) SetSubjects(s []ObjectRoleBindingSubject) {
+	_ = fieldpath.Pave(o.Object).SetValue("spec.subjects", s)
+}
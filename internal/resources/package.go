@@ -54,6 +54,18 @@ func (p *Package) GetHealthy() bool {
 	return resource.IsConditionTrue(conditioned.GetCondition("Healthy"))
 }
 
+// GetCondition returns the condition for the given ConditionType if it
+// exists, otherwise returns nil.
+func (p *Package) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return GetCondition(p.Object, ct)
+}
+
+// GetPackage returns the package reference.
+func (p *Package) GetPackage() string {
+	pkg, _ := fieldpath.Pave(p.Object).GetString("spec.package")
+	return pkg
+}
+
 // SetPackage sets the package reference.
 func (p *Package) SetPackage(pkg string) {
 	_ = fieldpath.Pave(p.Object).SetValue("spec.package", pkg)
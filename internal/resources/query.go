@@ -0,0 +1,128 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// SpaceQueryGVK is the GroupVersionKind used for queries spanning every
+	// control plane in a Space.
+	SpaceQueryGVK = schema.GroupVersionKind{
+		Group:   "query.spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "SpaceQuery",
+	}
+	// GroupQueryGVK is the GroupVersionKind used for queries spanning the
+	// control planes in a single Space group.
+	GroupQueryGVK = schema.GroupVersionKind{
+		Group:   "query.spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "GroupQuery",
+	}
+)
+
+// QueryFilter selects the objects returned by a Query.
+type QueryFilter struct {
+	ControlPlane QueryControlPlane `json:"controlPlane,omitempty"`
+	Group        string            `json:"group,omitempty"`
+	Kind         string            `json:"kind,omitempty"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Names        []string          `json:"names,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// QueryControlPlane identifies the control plane an object lives in.
+type QueryControlPlane struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// QueryResult is a single object returned by a Query.
+type QueryResult struct {
+	ID           string                 `json:"id,omitempty"`
+	ControlPlane QueryControlPlane      `json:"controlPlane,omitempty"`
+	Object       map[string]interface{} `json:"object,omitempty"`
+}
+
+// Query represents a SpaceQuery or GroupQuery and extends an
+// unstructured.Unstructured. Queries are never persisted; the API server
+// answers them in the status of the created object.
+type Query struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (q *Query) GetUnstructured() *unstructured.Unstructured {
+	return &q.Unstructured
+}
+
+// SetFilter sets the filter used to select objects.
+func (q *Query) SetFilter(f QueryFilter) {
+	_ = fieldpath.Pave(q.Object).SetValue("spec.filter", f)
+}
+
+// SetPage requests a page of at most limit objects, starting at the supplied
+// cursor. An empty cursor requests the first page.
+func (q *Query) SetPage(limit int64, cursor string) {
+	p := fieldpath.Pave(q.Object)
+	if limit > 0 {
+		_ = p.SetNumber("spec.page.first", float64(limit))
+	}
+	if cursor != "" {
+		_ = p.SetString("spec.page.cursor", cursor)
+	}
+}
+
+// SetObjectSelection requests the full object and the control plane it
+// belongs to for every result.
+func (q *Query) SetObjectSelection() {
+	_ = fieldpath.Pave(q.Object).SetValue("spec.objects", map[string]interface{}{
+		"id":           true,
+		"controlPlane": true,
+		"object":       true,
+	})
+}
+
+// GetResults returns the objects answered by the query.
+func (q *Query) GetResults() []QueryResult {
+	res := []QueryResult{}
+	_ = fieldpath.Pave(q.Object).GetValueInto("status.objects", &res)
+	return res
+}
+
+// GetNextCursor returns the cursor of the next page, or an empty string if
+// this was the last page.
+func (q *Query) GetNextCursor() string {
+	c, err := fieldpath.Pave(q.Object).GetString("status.cursor.next")
+	if err != nil {
+		return ""
+	}
+	return c
+}
+
+// GetWarnings returns any warnings produced while answering the query, for
+// example when a control plane could not be reached.
+func (q *Query) GetWarnings() []string {
+	w, err := fieldpath.Pave(q.Object).GetStringArray("status.warnings")
+	if err != nil {
+		return nil
+	}
+	return w
+}
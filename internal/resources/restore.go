@@ -0,0 +1,92 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// RestoreGVK is the GroupVersionKind used for Space Restores.
+	RestoreGVK = schema.GroupVersionKind{
+		Group:   "spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "Restore",
+	}
+)
+
+// RestoreSource references the Backup a control plane is restored from.
+type RestoreSource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Restore represents the Restore CustomResource and extends an
+// unstructured.Unstructured.
+type Restore struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (r *Restore) GetUnstructured() *unstructured.Unstructured {
+	r.SetGroupVersionKind(RestoreGVK)
+	return &r.Unstructured
+}
+
+// GetCondition returns the condition for the given xpv1.ConditionType if it
+// exists, otherwise returns nil.
+func (r *Restore) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return GetCondition(r.Object, ct)
+}
+
+// GetControlPlane returns the name of the control plane being restored.
+func (r *Restore) GetControlPlane() string {
+	ctp, err := fieldpath.Pave(r.Object).GetString("spec.controlPlane")
+	if err != nil {
+		return ""
+	}
+	return ctp
+}
+
+// SetControlPlane sets the name of the control plane to restore.
+func (r *Restore) SetControlPlane(name string) {
+	_ = fieldpath.Pave(r.Object).SetString("spec.controlPlane", name)
+}
+
+// GetSource returns the reference to the Backup being restored.
+func (r *Restore) GetSource() RestoreSource {
+	src := RestoreSource{}
+	_ = fieldpath.Pave(r.Object).GetValueInto("spec.source", &src)
+	return src
+}
+
+// SetSource sets the reference to the Backup to restore.
+func (r *Restore) SetSource(src RestoreSource) {
+	_ = fieldpath.Pave(r.Object).SetValue("spec.source", src)
+}
+
+// GetPhase returns the phase of the restore, e.g. Pending, InProgress,
+// Failed, or Completed.
+func (r *Restore) GetPhase() string {
+	phase, err := fieldpath.Pave(r.Object).GetString("status.phase")
+	if err != nil {
+		return ""
+	}
+	return phase
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// SharedBackupConfigGVK is the GroupVersionKind used for Space
+	// SharedBackupConfigs.
+	SharedBackupConfigGVK = schema.GroupVersionKind{
+		Group:   "spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "SharedBackupConfig",
+	}
+)
+
+// SharedBackupConfig represents the SharedBackupConfig CustomResource and
+// extends an unstructured.Unstructured.
+type SharedBackupConfig struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (s *SharedBackupConfig) GetUnstructured() *unstructured.Unstructured {
+	s.SetGroupVersionKind(SharedBackupConfigGVK)
+	return &s.Unstructured
+}
+
+// GetCondition returns the condition for the given xpv1.ConditionType if it
+// exists, otherwise returns nil.
+func (s *SharedBackupConfig) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return GetCondition(s.Object, ct)
+}
+
+// GetProvider returns the object storage provider backups are written to,
+// e.g. AWS, Azure, or GCP.
+func (s *SharedBackupConfig) GetProvider() string {
+	p, err := fieldpath.Pave(s.Object).GetString("spec.objectStorage.provider")
+	if err != nil {
+		return ""
+	}
+	return p
+}
+
+// SetProvider sets the object storage provider backups are written to.
+func (s *SharedBackupConfig) SetProvider(p string) {
+	_ = fieldpath.Pave(s.Object).SetString("spec.objectStorage.provider", p)
+}
+
+// GetBucket returns the name of the bucket backups are written to.
+func (s *SharedBackupConfig) GetBucket() string {
+	b, err := fieldpath.Pave(s.Object).GetString("spec.objectStorage.bucket")
+	if err != nil {
+		return ""
+	}
+	return b
+}
+
+// SetBucket sets the name of the bucket backups are written to.
+func (s *SharedBackupConfig) SetBucket(b string) {
+	_ = fieldpath.Pave(s.Object).SetString("spec.objectStorage.bucket", b)
+}
+
+// GetCredentialsSecretReference returns the reference to the secret
+// containing object storage credentials, if any.
+func (s *SharedBackupConfig) GetCredentialsSecretReference() *xpv1.SecretKeySelector {
+	out := &xpv1.SecretKeySelector{}
+	if err := fieldpath.Pave(s.Object).GetValueInto("spec.objectStorage.credentials.secretRef", out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetCredentialsSecretReference sets the secret containing object storage
+// credentials.
+func (s *SharedBackupConfig) SetCredentialsSecretReference(ref *xpv1.SecretKeySelector) {
+	_ = fieldpath.Pave(s.Object).SetString("spec.objectStorage.credentials.source", string(xpv1.CredentialsSourceSecret))
+	_ = fieldpath.Pave(s.Object).SetValue("spec.objectStorage.credentials.secretRef", ref)
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// SharedSecretStoreGVK is the GroupVersionKind used for Space
+	// SharedSecretStores.
+	SharedSecretStoreGVK = schema.GroupVersionKind{
+		Group:   "spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "SharedSecretStore",
+	}
+)
+
+// SharedSecretStore represents the SharedSecretStore CustomResource and
+// extends an unstructured.Unstructured.
+type SharedSecretStore struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (s *SharedSecretStore) GetUnstructured() *unstructured.Unstructured {
+	s.SetGroupVersionKind(SharedSecretStoreGVK)
+	return &s.Unstructured
+}
+
+// GetCondition returns the condition for the given xpv1.ConditionType if it
+// exists, otherwise returns nil.
+func (s *SharedSecretStore) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return GetCondition(s.Object, ct)
+}
+
+// GetProvider returns the External Secrets Operator provider configuration
+// of the store.
+func (s *SharedSecretStore) GetProvider() map[string]any {
+	p := map[string]any{}
+	if err := fieldpath.Pave(s.Object).GetValueInto("spec.provider", &p); err != nil {
+		return nil
+	}
+	return p
+}
+
+// SetProvider sets the External Secrets Operator provider configuration of
+// the store.
+func (s *SharedSecretStore) SetProvider(p map[string]any) {
+	_ = fieldpath.Pave(s.Object).SetValue("spec.provider", p)
+}
+
+// GetControlPlaneSelector returns the selector for control planes the store
+// is provisioned to.
+func (s *SharedSecretStore) GetControlPlaneSelector() *metav1.LabelSelector {
+	out := &metav1.LabelSelector{}
+	if err := fieldpath.Pave(s.Object).GetValueInto("spec.controlPlaneSelector.labelSelectors[0]", out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetControlPlaneSelector sets the selector for control planes the store is
+// provisioned to.
+func (s *SharedSecretStore) SetControlPlaneSelector(sel *metav1.LabelSelector) {
+	_ = fieldpath.Pave(s.Object).SetValue("spec.controlPlaneSelector.labelSelectors", []*metav1.LabelSelector{sel})
+}
+
+// GetControlPlaneNames returns the names of control planes the store is
+// provisioned to.
+func (s *SharedSecretStore) GetControlPlaneNames() []string {
+	out := []string{}
+	if err := fieldpath.Pave(s.Object).GetValueInto("spec.controlPlaneSelector.names", &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetControlPlaneNames sets the names of control planes the store is
+// provisioned to.
+func (s *SharedSecretStore) SetControlPlaneNames(names []string) {
+	_ = fieldpath.Pave(s.Object).SetValue("spec.controlPlaneSelector.names", names)
+}
+
+// ClearControlPlaneSelector removes both names and labels from the selector
+// for control planes the store is provisioned to.
+func (s *SharedSecretStore) ClearControlPlaneSelector() {
+	_ = fieldpath.Pave(s.Object).DeleteField("spec.controlPlaneSelector")
+}
+
+// GetNamespaceNames returns the namespaces of each control plane the store
+// is provisioned to.
+func (s *SharedSecretStore) GetNamespaceNames() []string {
+	out := []string{}
+	if err := fieldpath.Pave(s.Object).GetValueInto("spec.namespaceSelector.names", &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetNamespaceNames sets the namespaces of each control plane the store is
+// provisioned to.
+func (s *SharedSecretStore) SetNamespaceNames(names []string) {
+	_ = fieldpath.Pave(s.Object).SetValue("spec.namespaceSelector.names", names)
+}
+
+// GetSecretStoreName returns the name of the SecretStore provisioned in each
+// control plane. It defaults to the name of the SharedSecretStore.
+func (s *SharedSecretStore) GetSecretStoreName() string {
+	n, err := fieldpath.Pave(s.Object).GetString("spec.secretStoreName")
+	if err != nil {
+		return ""
+	}
+	return n
+}
+
+// SetSecretStoreName sets the name of the SecretStore provisioned in each
+// control plane.
+func (s *SharedSecretStore) SetSecretStoreName(name string) {
+	_ = fieldpath.Pave(s.Object).SetString("spec.secretStoreName", name)
+}
+
+// GetProvisionedControlPlanes returns the names of the control planes the
+// store has been provisioned to.
+func (s *SharedSecretStore) GetProvisionedControlPlanes() []string {
+	out := []string{}
+	if err := fieldpath.Pave(s.Object).GetValueInto("status.provisioned.controlPlanes", &out); err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+var (
+	// SimulationGVK is the GroupVersionKind used for Space Simulations.
+	SimulationGVK = schema.GroupVersionKind{
+		Group:   "spaces.upbound.io",
+		Version: "v1alpha1",
+		Kind:    "Simulation",
+	}
+)
+
+// Simulation condition types.
+const (
+	// TypeAcceptingChanges is true once the simulated control plane is ready
+	// for changes to be applied to it.
+	TypeAcceptingChanges xpv1.ConditionType = "AcceptingChanges"
+	// TypeSimulationComplete is true once the changes to managed resources
+	// have been computed.
+	TypeSimulationComplete xpv1.ConditionType = "SimulationComplete"
+)
+
+// SimulationCompletionCriterion determines when a simulation is complete.
+type SimulationCompletionCriterion struct {
+	// Type of the criterion, e.g. Duration.
+	Type string `json:"type"`
+	// Duration the simulation runs for once changes stop being accepted,
+	// e.g. 90s.
+	Duration string `json:"duration,omitempty"`
+}
+
+// SimulationObjectReference identifies an object changed in a simulation.
+type SimulationObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// SimulationChange is a change to a managed resource computed by a
+// simulation.
+type SimulationChange struct {
+	// Change is one of Create, Update, Delete or Unknown.
+	Change          string                    `json:"change"`
+	ObjectReference SimulationObjectReference `json:"objectReference"`
+}
+
+// Simulation represents the Simulation CustomResource and extends an
+// unstructured.Unstructured.
+type Simulation struct {
+	unstructured.Unstructured
+}
+
+// GetUnstructured returns the underlying *unstructured.Unstructured.
+func (s *Simulation) GetUnstructured() *unstructured.Unstructured {
+	s.SetGroupVersionKind(SimulationGVK)
+	return &s.Unstructured
+}
+
+// GetCondition returns the condition for the given xpv1.ConditionType if it
+// exists, otherwise returns nil.
+func (s *Simulation) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return GetCondition(s.Object, ct)
+}
+
+// GetControlPlaneName returns the name of the control plane being simulated.
+func (s *Simulation) GetControlPlaneName() string {
+	ctp, err := fieldpath.Pave(s.Object).GetString("spec.controlPlaneName")
+	if err != nil {
+		return ""
+	}
+	return ctp
+}
+
+// SetControlPlaneName sets the name of the control plane to simulate.
+func (s *Simulation) SetControlPlaneName(name string) {
+	_ = fieldpath.Pave(s.Object).SetString("spec.controlPlaneName", name)
+}
+
+// GetDesiredState returns the desired state of the simulation, e.g.
+// AcceptingChanges, Complete, or Terminated.
+func (s *Simulation) GetDesiredState() string {
+	st, err := fieldpath.Pave(s.Object).GetString("spec.desiredState")
+	if err != nil {
+		return ""
+	}
+	return st
+}
+
+// SetDesiredState sets the desired state of the simulation.
+func (s *Simulation) SetDesiredState(st string) {
+	_ = fieldpath.Pave(s.Object).SetString("spec.desiredState", st)
+}
+
+// GetCompletionCriteria returns the criteria that determine when the
+// simulation is complete.
+func (s *Simulation) GetCompletionCriteria() []SimulationCompletionCriterion {
+	cc := []SimulationCompletionCriterion{}
+	_ = fieldpath.Pave(s.Object).GetValueInto("spec.completionCriteria", &cc)
+	return cc
+}
+
+// SetCompletionCriteria sets the criteria that determine when the simulation
+// is complete.
+func (s *Simulation) SetCompletionCriteria(cc []SimulationCompletionCriterion) {
+	_ = fieldpath.Pave(s.Object).SetValue("spec.completionCriteria", cc)
+}
+
+// GetSimulatedControlPlaneName returns the name of the control plane the
+// simulation runs in, once it has been created.
+func (s *Simulation) GetSimulatedControlPlaneName() string {
+	ctp, err := fieldpath.Pave(s.Object).GetString("status.simulatedControlPlaneName")
+	if err != nil {
+		return ""
+	}
+	return ctp
+}
+
+// GetChanges returns the changes to managed resources computed by the
+// simulation.
+func (s *Simulation) GetChanges() []SimulationChange {
+	changes := []SimulationChange{}
+	_ = fieldpath.Pave(s.Object).GetValueInto("status.changes", &changes)
+	return changes
+}
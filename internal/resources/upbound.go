@@ -37,12 +37,7 @@ type Upbound struct {
 // GetCondition returns the condition for the given xpv1.ConditionType if it
 // exists, otherwise returns nil.
 func (s *Upbound) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
-	conditioned := xpv1.ConditionedStatus{}
-	// The path is directly `status` because conditions are inline.
-	if err := fieldpath.Pave(s.Object).GetValueInto("status", &conditioned); err != nil {
-		return xpv1.Condition{}
-	}
-	return conditioned.GetCondition(ct)
+	return GetCondition(s.Object, ct)
 }
 
 // GetDomain returns the domain field from the Upbound CustomResource.
@@ -0,0 +1,233 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package robot provisions robot accounts and tokens with scoped permissions
+// so that automation does not need to reuse user credentials.
+package robot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/uuid"
+
+	"github.com/upbound/up-sdk-go"
+	"github.com/upbound/up-sdk-go/service/robots"
+	"github.com/upbound/up-sdk-go/service/tokens"
+)
+
+const (
+	robotsPath      = "v1/robots"
+	permissionsPath = "permissions"
+	permissionsBody = "permissions"
+
+	jwtMetaKey = "jwt"
+
+	errCreateRobot = "cannot create robot"
+	errCreateToken = "cannot create robot token"
+	errSetScope    = "cannot set robot permissions"
+	errFmtRollback = "%s; additionally cannot delete robot %s"
+)
+
+// AccessLevel is the level of access a robot has to control planes.
+type AccessLevel string
+
+const (
+	// AccessRead allows a robot to read control plane resources.
+	AccessRead AccessLevel = "read"
+	// AccessWrite allows a robot to read and write control plane resources.
+	AccessWrite AccessLevel = "write"
+)
+
+// Scope limits the permissions of a robot.
+type Scope struct {
+	// ControlPlanes the robot may access. An empty list grants access to all
+	// control planes in the organization.
+	ControlPlanes []string
+	// ReadOnly limits access to reading resources.
+	ReadOnly bool
+}
+
+// IsZero returns true if the scope places no limits on the robot.
+func (s Scope) IsZero() bool {
+	return len(s.ControlPlanes) == 0 && !s.ReadOnly
+}
+
+// AccessLevel returns the access level granted by the scope.
+func (s Scope) AccessLevel() AccessLevel {
+	if s.ReadOnly {
+		return AccessRead
+	}
+	return AccessWrite
+}
+
+// Credentials identify a robot token.
+type Credentials struct {
+	RobotID  uuid.UUID
+	AccessID string
+	Token    string
+}
+
+// Options configure a robot and token provisioned by Provision.
+type Options struct {
+	OrganizationID uint
+	Name           string
+	Description    string
+	TokenName      string
+	Scope          Scope
+}
+
+type robotClient interface {
+	Create(ctx context.Context, params *robots.RobotCreateParameters) (*robots.RobotResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type tokenClient interface {
+	Create(ctx context.Context, params *tokens.TokenCreateParameters) (*tokens.TokenResponse, error)
+}
+
+type permissionAttributes struct {
+	ControlPlanes []string    `json:"controlPlanes,omitempty"`
+	AccessLevel   AccessLevel `json:"accessLevel"`
+}
+
+type permissionData struct {
+	Type       string               `json:"type"`
+	Attributes permissionAttributes `json:"attributes"`
+}
+
+type permissionRequest struct {
+	Data permissionData `json:"data"`
+}
+
+// Client provisions robots and robot tokens on Upbound.
+type Client struct {
+	robots robotClient
+	tokens tokenClient
+	api    up.Client
+}
+
+// New constructs a new Client.
+func New(rc robotClient, tc tokenClient, api up.Client) *Client {
+	return &Client{
+		robots: rc,
+		tokens: tc,
+		api:    api,
+	}
+}
+
+// NewFromConfig constructs a new Client from an Upbound SDK config.
+func NewFromConfig(cfg *up.Config) *Client {
+	return New(robots.NewClient(cfg), tokens.NewClient(cfg), cfg.Client)
+}
+
+// Provision creates a robot limited to the supplied scope and a token for
+// it. The robot is deleted if it cannot be scoped or its token cannot be
+// created, so that no unscoped or unused robots are left behind.
+func (c *Client) Provision(ctx context.Context, opts Options) (*Credentials, error) {
+	id, err := c.CreateRobot(ctx, opts.OrganizationID, opts.Name, opts.Description, opts.Scope)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := c.CreateToken(ctx, id, opts.TokenName)
+	if err != nil {
+		return nil, c.rollback(ctx, id, err)
+	}
+	return creds, nil
+}
+
+// CreateRobot creates a robot in the supplied organization and limits it to
+// the supplied scope. The robot is deleted if it cannot be scoped.
+func (c *Client) CreateRobot(ctx context.Context, orgID uint, name, description string, scope Scope) (uuid.UUID, error) {
+	res, err := c.robots.Create(ctx, &robots.RobotCreateParameters{
+		Attributes: robots.RobotAttributes{
+			Name:        name,
+			Description: description,
+		},
+		Relationships: robots.RobotRelationships{
+			Owner: robots.RobotOwner{
+				Data: robots.RobotOwnerData{
+					Type: robots.RobotOwnerOrganization,
+					ID:   fmt.Sprint(orgID),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, errCreateRobot)
+	}
+	if err := c.SetScope(ctx, res.ID, scope); err != nil {
+		return uuid.Nil, c.rollback(ctx, res.ID, err)
+	}
+	return res.ID, nil
+}
+
+// CreateToken creates a token for the supplied robot. The token has the
+// permissions of the robot.
+func (c *Client) CreateToken(ctx context.Context, robotID uuid.UUID, name string) (*Credentials, error) {
+	res, err := c.tokens.Create(ctx, &tokens.TokenCreateParameters{
+		Attributes: tokens.TokenAttributes{
+			Name: name,
+		},
+		Relationships: tokens.TokenRelationships{
+			Owner: tokens.TokenOwner{
+				Data: tokens.TokenOwnerData{
+					Type: tokens.TokenOwnerRobot,
+					ID:   robotID.String(),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateToken)
+	}
+	return &Credentials{
+		RobotID:  robotID,
+		AccessID: res.ID.String(),
+		Token:    fmt.Sprint(res.DataSet.Meta[jwtMetaKey]),
+	}, nil
+}
+
+// SetScope limits the supplied robot to the supplied scope. A zero scope
+// leaves the permissions of the robot unchanged.
+func (c *Client) SetScope(ctx context.Context, robotID uuid.UUID, scope Scope) error {
+	if scope.IsZero() {
+		return nil
+	}
+	req, err := c.api.NewRequest(ctx, http.MethodPut, robotsPath, path.Join(robotID.String(), permissionsPath), &permissionRequest{
+		Data: permissionData{
+			Type: permissionsBody,
+			Attributes: permissionAttributes{
+				ControlPlanes: scope.ControlPlanes,
+				AccessLevel:   scope.AccessLevel(),
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, errSetScope)
+	}
+	return errors.Wrap(c.api.Do(req, nil), errSetScope)
+}
+
+// rollback deletes the supplied robot after provisioning failed with the
+// supplied error.
+func (c *Client) rollback(ctx context.Context, id uuid.UUID, cause error) error {
+	if err := c.robots.Delete(ctx, id); err != nil {
+		return errors.Errorf(errFmtRollback, cause, id)
+	}
+	return cause
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/upbound/up-sdk-go/service/common"
+	"github.com/upbound/up-sdk-go/service/robots"
+	"github.com/upbound/up-sdk-go/service/tokens"
+)
+
+var (
+	robotID = uuid.MustParse("5d7b4a38-7d3c-4d2a-9b0e-6b0f3b1a2c4d")
+	tokenID = uuid.MustParse("9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d")
+	errBoom = errors.New("boom")
+)
+
+type mockRobots struct {
+	createErr error
+	deleted   []uuid.UUID
+}
+
+func (m *mockRobots) Create(_ context.Context, _ *robots.RobotCreateParameters) (*robots.RobotResponse, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	return &robots.RobotResponse{DataSet: common.DataSet{ID: robotID}}, nil
+}
+
+func (m *mockRobots) Delete(_ context.Context, id uuid.UUID) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+type mockTokens struct {
+	err error
+}
+
+func (m *mockTokens) Create(_ context.Context, p *tokens.TokenCreateParameters) (*tokens.TokenResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &tokens.TokenResponse{DataSet: common.DataSet{ID: tokenID, Meta: common.Meta{"jwt": "a-jwt"}}}, nil
+}
+
+type mockAPI struct {
+	err  error
+	body []byte
+}
+
+func (m *mockAPI) NewRequest(ctx context.Context, method, prefix, urlPath string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	m.body = b
+	return http.NewRequestWithContext(ctx, method, "https://api.upbound.io/"+prefix+"/"+urlPath, io.NopCloser(bytes.NewReader(b)))
+}
+
+func (m *mockAPI) Do(_ *http.Request, _ interface{}) error {
+	return m.err
+}
+
+func TestProvision(t *testing.T) {
+	type want struct {
+		creds       *Credentials
+		err         error
+		deleted     []uuid.UUID
+		permissions string
+	}
+	cases := map[string]struct {
+		reason string
+		opts   Options
+		robots *mockRobots
+		tokens *mockTokens
+		api    *mockAPI
+		want   want
+	}{
+		"Unscoped": {
+			reason: "A robot without a scope should not have its permissions changed.",
+			opts:   Options{OrganizationID: 1, Name: "ci", TokenName: "ci-token"},
+			robots: &mockRobots{},
+			tokens: &mockTokens{},
+			api:    &mockAPI{},
+			want: want{
+				creds: &Credentials{RobotID: robotID, AccessID: tokenID.String(), Token: "a-jwt"},
+			},
+		},
+		"ScopedReadOnly": {
+			reason: "A scoped robot should be limited to read access on the supplied control planes.",
+			opts: Options{
+				OrganizationID: 1,
+				Name:           "ci",
+				TokenName:      "ci-token",
+				Scope:          Scope{ControlPlanes: []string{"ctp1", "ctp2"}, ReadOnly: true},
+			},
+			robots: &mockRobots{},
+			tokens: &mockTokens{},
+			api:    &mockAPI{},
+			want: want{
+				creds:       &Credentials{RobotID: robotID, AccessID: tokenID.String(), Token: "a-jwt"},
+				permissions: `{"data":{"type":"permissions","attributes":{"controlPlanes":["ctp1","ctp2"],"accessLevel":"read"}}}`,
+			},
+		},
+		"ErrCreateRobot": {
+			reason: "An error creating the robot should be returned.",
+			opts:   Options{Name: "ci"},
+			robots: &mockRobots{createErr: errBoom},
+			tokens: &mockTokens{},
+			api:    &mockAPI{},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateRobot),
+			},
+		},
+		"ErrSetScopeDeletesRobot": {
+			reason: "A robot that cannot be scoped should be deleted rather than left with broader permissions.",
+			opts:   Options{Name: "ci", Scope: Scope{ReadOnly: true}},
+			robots: &mockRobots{},
+			tokens: &mockTokens{},
+			api:    &mockAPI{err: errBoom},
+			want: want{
+				err:         errors.Wrap(errBoom, errSetScope),
+				deleted:     []uuid.UUID{robotID},
+				permissions: `{"data":{"type":"permissions","attributes":{"accessLevel":"read"}}}`,
+			},
+		},
+		"ErrCreateTokenDeletesRobot": {
+			reason: "A robot whose token cannot be created should be deleted.",
+			opts:   Options{Name: "ci"},
+			robots: &mockRobots{},
+			tokens: &mockTokens{err: errBoom},
+			api:    &mockAPI{},
+			want: want{
+				err:     errors.Wrap(errBoom, errCreateToken),
+				deleted: []uuid.UUID{robotID},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			creds, err := New(tc.robots, tc.tokens, tc.api).Provision(context.Background(), tc.opts)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nProvision(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.creds, creds); diff != "" {
+				t.Errorf("\n%s\nProvision(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, tc.robots.deleted); diff != "" {
+				t.Errorf("\n%s\nProvision(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.permissions, string(tc.api.body)); diff != "" {
+				t.Errorf("\n%s\nProvision(...): -want permissions, +got permissions:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretstore manages the SharedSecretStores of a Space, which
+// provision External Secrets Operator stores to the control planes of a
+// group.
+package secretstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	errNoProvider  = "a provider is required"
+	errNoGroup     = "a group is required"
+	errFmtGetGroup = "cannot get group %q"
+	errFmtNotGroup = "namespace %q is not a Space group"
+	errFmtCreate   = "cannot create shared secret store %q"
+	errFmtGet      = "cannot get shared secret store %q"
+	errFmtUpdate   = "cannot update shared secret store %q"
+	errFmtDelete   = "cannot delete shared secret store %q"
+	errListStores  = "cannot list shared secret stores"
+	errBadSelector = "invalid control plane label selector"
+)
+
+var (
+	resource = resources.SharedSecretStoreGVK.GroupVersion().WithResource("sharedsecretstores")
+
+	namespaces = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+)
+
+// A Selector selects the control planes of a group a store is provisioned
+// to. Control planes matching either names or labels are selected; an empty
+// Selector selects no control planes.
+type Selector struct {
+	Names  []string
+	Labels *metav1.LabelSelector
+}
+
+// Options for creating a SharedSecretStore.
+type Options struct {
+	// Provider is the External Secrets Operator provider configuration,
+	// e.g. {"aws": {"service": "SecretsManager", "region": "us-east-1"}}.
+	Provider map[string]any
+	// ControlPlanes the store is provisioned to.
+	ControlPlanes Selector
+	// Namespaces of each control plane the store is provisioned to.
+	Namespaces []string
+	// SecretStoreName is the name of the store in each control plane.
+	// Defaults to the name of the SharedSecretStore.
+	SecretStoreName string
+}
+
+// Client manages the SharedSecretStores of a Space.
+type Client struct {
+	c   dynamic.Interface
+	log logging.Logger
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithLogger sets the logger used by the Client.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// New instantiates a new Client.
+func New(c dynamic.Interface, opts ...Option) *Client {
+	cl := &Client{
+		c:   c,
+		log: logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(cl)
+	}
+	return cl
+}
+
+// Create a SharedSecretStore in the supplied group.
+func (c *Client) Create(ctx context.Context, group, name string, opts Options) (*resources.SharedSecretStore, error) {
+	if len(opts.Provider) == 0 {
+		return nil, errors.New(errNoProvider)
+	}
+	if err := validate(opts.ControlPlanes); err != nil {
+		return nil, err
+	}
+	if err := c.checkGroup(ctx, group); err != nil {
+		return nil, err
+	}
+
+	s := &resources.SharedSecretStore{}
+	s.SetNamespace(group)
+	s.SetName(name)
+	s.SetProvider(opts.Provider)
+	setSelector(s, opts.ControlPlanes)
+	if len(opts.Namespaces) > 0 {
+		s.SetNamespaceNames(opts.Namespaces)
+	}
+	if opts.SecretStoreName != "" {
+		s.SetSecretStoreName(opts.SecretStoreName)
+	}
+
+	c.log.Debug("Creating shared secret store", "group", group, "name", name)
+	u, err := c.c.Resource(resource).Namespace(group).Create(ctx, s.GetUnstructured(), metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtCreate, name)
+	}
+	return &resources.SharedSecretStore{Unstructured: *u}, nil
+}
+
+// Get the SharedSecretStore of the supplied name in the supplied group.
+func (c *Client) Get(ctx context.Context, group, name string) (*resources.SharedSecretStore, error) {
+	u, err := c.c.Resource(resource).Namespace(group).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtGet, name)
+	}
+	return &resources.SharedSecretStore{Unstructured: *u}, nil
+}
+
+// List the SharedSecretStores of the supplied group, or of all groups if the
+// group is empty, sorted by group and name.
+func (c *Client) List(ctx context.Context, group string) ([]*resources.SharedSecretStore, error) {
+	l, err := c.c.Resource(resource).Namespace(group).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, errListStores)
+	}
+	out := make([]*resources.SharedSecretStore, len(l.Items))
+	for i := range l.Items {
+		out[i] = &resources.SharedSecretStore{Unstructured: l.Items[i]}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].GetNamespace() != out[j].GetNamespace() {
+			return out[i].GetNamespace() < out[j].GetNamespace()
+		}
+		return out[i].GetName() < out[j].GetName()
+	})
+	return out, nil
+}
+
+// Bind replaces the selector of control planes the SharedSecretStore is
+// provisioned to. Control planes no longer selected are deprovisioned by the
+// Space.
+func (c *Client) Bind(ctx context.Context, group, name string, sel Selector) (*resources.SharedSecretStore, error) {
+	if err := validate(sel); err != nil {
+		return nil, err
+	}
+	s, err := c.Get(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+	s.ClearControlPlaneSelector()
+	setSelector(s, sel)
+
+	c.log.Debug("Binding shared secret store", "group", group, "name", name, "names", sel.Names)
+	u, err := c.c.Resource(resource).Namespace(group).Update(ctx, s.GetUnstructured(), metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtUpdate, name)
+	}
+	return &resources.SharedSecretStore{Unstructured: *u}, nil
+}
+
+// Delete the SharedSecretStore of the supplied name in the supplied group.
+func (c *Client) Delete(ctx context.Context, group, name string) error {
+	err := c.c.Resource(resource).Namespace(group).Delete(ctx, name, metav1.DeleteOptions{})
+	return errors.Wrapf(err, errFmtDelete, name)
+}
+
+func (c *Client) checkGroup(ctx context.Context, group string) error {
+	if group == "" {
+		return errors.New(errNoGroup)
+	}
+	u, err := c.c.Resource(namespaces).Get(ctx, group, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, errFmtGetGroup, group)
+	}
+	if g := (&resources.Group{Unstructured: *u}); !g.IsGroup() {
+		return errors.Errorf(errFmtNotGroup, group)
+	}
+	return nil
+}
+
+func validate(sel Selector) error {
+	if sel.Labels == nil {
+		return nil
+	}
+	_, err := metav1.LabelSelectorAsSelector(sel.Labels)
+	return errors.Wrap(err, errBadSelector)
+}
+
+func setSelector(s *resources.SharedSecretStore, sel Selector) {
+	if len(sel.Names) > 0 {
+		s.SetControlPlaneNames(sel.Names)
+	}
+	if sel.Labels != nil {
+		s.SetControlPlaneSelector(sel.Labels)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+var provider = map[string]any{"aws": map[string]any{"service": "SecretsManager", "region": "us-east-1"}}
+
+func newFake(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resource:   "SharedSecretStoreList",
+		namespaces: "NamespaceList",
+	}, objs...)
+}
+
+func namespace(name string, group bool) *unstructured.Unstructured {
+	g := &resources.Group{}
+	g.SetName(name)
+	if group {
+		return g.GetUnstructured()
+	}
+	g.SetGroupVersionKind(resources.GroupGVK)
+	return &g.Unstructured
+}
+
+func store(group, name string) *unstructured.Unstructured {
+	s := &resources.SharedSecretStore{}
+	s.SetNamespace(group)
+	s.SetName(name)
+	s.SetProvider(provider)
+	return s.GetUnstructured()
+}
+
+func TestCreate(t *testing.T) {
+	type args struct {
+		objs  []runtime.Object
+		group string
+		opts  Options
+	}
+	type want struct {
+		spec map[string]any
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoProvider": {
+			reason: "A provider is required.",
+			args: args{
+				group: "default",
+			},
+			want: want{
+				err: errors.New(errNoProvider),
+			},
+		},
+		"NotGroup": {
+			reason: "Stores can only be created in Space groups.",
+			args: args{
+				objs:  []runtime.Object{namespace("kube-system", false)},
+				group: "kube-system",
+				opts:  Options{Provider: provider},
+			},
+			want: want{
+				err: errors.Errorf(errFmtNotGroup, "kube-system"),
+			},
+		},
+		"Success": {
+			reason: "The store should be created with the supplied selectors.",
+			args: args{
+				objs:  []runtime.Object{namespace("default", true)},
+				group: "default",
+				opts: Options{
+					Provider: provider,
+					ControlPlanes: Selector{
+						Names:  []string{"ctp1"},
+						Labels: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+					},
+					Namespaces:      []string{"app"},
+					SecretStoreName: "aws",
+				},
+			},
+			want: want{
+				spec: map[string]any{
+					"provider": provider,
+					"controlPlaneSelector": map[string]any{
+						"names":          []any{"ctp1"},
+						"labelSelectors": []any{map[string]any{"matchLabels": map[string]any{"env": "prod"}}},
+					},
+					"namespaceSelector": map[string]any{"names": []any{"app"}},
+					"secretStoreName":   "aws",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(newFake(tc.args.objs...)).Create(context.Background(), tc.args.group, "store", tc.args.opts)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.spec, got.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	c := New(newFake(store("b", "s1"), store("a", "s2"), store("a", "s1")))
+
+	type want struct {
+		names []string
+	}
+	cases := map[string]struct {
+		reason string
+		group  string
+		want   want
+	}{
+		"AllGroups": {
+			reason: "Stores of all groups should be listed, sorted by group and name.",
+			want:   want{names: []string{"a/s1", "a/s2", "b/s1"}},
+		},
+		"Group": {
+			reason: "Only stores of the supplied group should be listed.",
+			group:  "b",
+			want:   want{names: []string{"b/s1"}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l, err := c.List(context.Background(), tc.group)
+			if err != nil {
+				t.Fatalf("\n%s\nList(...): %v", tc.reason, err)
+			}
+			got := []string{}
+			for _, s := range l {
+				got = append(got, s.GetNamespace()+"/"+s.GetName())
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nList(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	s := &resources.SharedSecretStore{Unstructured: *store("default", "store")}
+	s.SetControlPlaneNames([]string{"old"})
+	c := New(newFake(s.GetUnstructured()))
+
+	got, err := c.Bind(context.Background(), "default", "store", Selector{Labels: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}})
+	if err != nil {
+		t.Fatalf("Bind(...): %v", err)
+	}
+	if diff := cmp.Diff([]string(nil), got.GetControlPlaneNames()); diff != "" {
+		t.Errorf("Bind(...): -want names, +got names:\n%s", diff)
+	}
+	want := &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	if diff := cmp.Diff(want, got.GetControlPlaneSelector()); diff != "" {
+		t.Errorf("Bind(...): -want selector, +got selector:\n%s", diff)
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulation runs control plane simulations in a Space. A simulation
+// clones the desired state of a control plane, accepts a set of changes, and
+// reports how the managed resources of the control plane would change.
+package simulation
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	// DefaultPollInterval is how often the status of a simulation is checked
+	// while waiting for it.
+	DefaultPollInterval = 5 * time.Second
+	// DefaultTimeout is how long to wait for a simulation to accept changes
+	// or to complete.
+	DefaultTimeout = 15 * time.Minute
+
+	desiredAcceptingChanges = "AcceptingChanges"
+	desiredComplete         = "Complete"
+	desiredTerminated       = "Terminated"
+
+	completionDuration = "Duration"
+
+	// discardTimeout is how long discarding a simulation may take once it
+	// has run.
+	discardTimeout = 30 * time.Second
+
+	errFmtCreate  = "cannot create simulation %q"
+	errFmtGet     = "cannot get simulation %q"
+	errFmtUpdate  = "cannot update simulation %q"
+	errFmtDelete  = "cannot delete simulation %q"
+	errList       = "cannot list simulations"
+	errFmtFailed  = "simulation %q failed: %s"
+	errFmtApply   = "cannot apply changes to simulation %q"
+	errFmtDiscard = "cannot discard simulation %q"
+	errNoCtp      = "a control plane is required"
+)
+
+var simulations = resources.SimulationGVK.GroupVersion().WithResource("simulations")
+
+// A State of a simulation.
+type State string
+
+// States of a simulation.
+const (
+	// StatePending simulations are creating their simulated control plane.
+	StatePending State = "Pending"
+	// StateAcceptingChanges simulations accept changes to their simulated
+	// control plane.
+	StateAcceptingChanges State = "AcceptingChanges"
+	// StateComplete simulations have computed their changes.
+	StateComplete State = "Complete"
+	// StateTerminated simulations have discarded their simulated control
+	// plane.
+	StateTerminated State = "Terminated"
+	// StateFailed simulations cannot make progress.
+	StateFailed State = "Failed"
+)
+
+// Status of a simulation.
+type Status struct {
+	State State
+	// SimulatedControlPlane is the control plane to apply changes to.
+	SimulatedControlPlane string
+	// Message explains the state, e.g. why a simulation failed.
+	Message string
+}
+
+// SimulationStatus returns the status of the supplied simulation.
+func SimulationStatus(s *resources.Simulation) Status {
+	st := Status{SimulatedControlPlane: s.GetSimulatedControlPlaneName()}
+	ready := s.GetCondition(xpv1.TypeReady)
+	switch {
+	case ready.Status == "False" && ready.Reason == xpv1.ReasonReconcileError:
+		st.State, st.Message = StateFailed, ready.Message
+	case s.GetDesiredState() == desiredTerminated:
+		st.State = StateTerminated
+	case s.GetCondition(resources.TypeSimulationComplete).Status == "True":
+		st.State = StateComplete
+	case s.GetCondition(resources.TypeAcceptingChanges).Status == "True":
+		st.State = StateAcceptingChanges
+	default:
+		st.State = StatePending
+	}
+	return st
+}
+
+// A ChangeType is how a managed resource changes.
+type ChangeType string
+
+// Types of change.
+const (
+	ChangeCreate  ChangeType = "Create"
+	ChangeUpdate  ChangeType = "Update"
+	ChangeDelete  ChangeType = "Delete"
+	ChangeUnknown ChangeType = "Unknown"
+)
+
+// A Change to a managed resource.
+type Change struct {
+	Type       ChangeType
+	APIVersion string
+	Kind       string
+	Namespace  string `json:",omitempty" yaml:",omitempty"`
+	Name       string
+}
+
+// Result of a completed simulation.
+type Result struct {
+	Simulation string
+	Changes    []Change
+}
+
+// Count returns how many changes of the supplied type the simulation found.
+func (r *Result) Count(t ChangeType) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Type == t {
+			n++
+		}
+	}
+	return n
+}
+
+// HasChanges returns true if any managed resource would change.
+func (r *Result) HasChanges() bool {
+	return len(r.Changes) > 0
+}
+
+// SimulationResult returns the result of the supplied simulation. Changes are
+// sorted by kind, namespace and name.
+func SimulationResult(s *resources.Simulation) *Result {
+	r := &Result{Simulation: s.GetName(), Changes: []Change{}}
+	for _, c := range s.GetChanges() {
+		t := ChangeType(c.Change)
+		switch t {
+		case ChangeCreate, ChangeUpdate, ChangeDelete:
+		default:
+			t = ChangeUnknown
+		}
+		r.Changes = append(r.Changes, Change{
+			Type:       t,
+			APIVersion: c.ObjectReference.APIVersion,
+			Kind:       c.ObjectReference.Kind,
+			Namespace:  c.ObjectReference.Namespace,
+			Name:       c.ObjectReference.Name,
+		})
+	}
+	sort.SliceStable(r.Changes, func(i, j int) bool {
+		a, b := r.Changes[i], r.Changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return r
+}
+
+// Client runs simulations of control planes in a Space. Simulations are
+// created in the group of the control plane they simulate.
+type Client struct {
+	c            dynamic.Interface
+	log          logging.Logger
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithLogger sets the logger used by the Client.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// WithPollInterval sets how often the status of a simulation is checked
+// while waiting for it.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = d
+	}
+}
+
+// WithTimeout sets how long to wait for a simulation to accept changes or to
+// complete.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// New instantiates a new Client.
+func New(c dynamic.Interface, opts ...Option) *Client {
+	cl := &Client{
+		c:            c,
+		log:          logging.NewNopLogger(),
+		pollInterval: DefaultPollInterval,
+		timeout:      DefaultTimeout,
+	}
+	for _, o := range opts {
+		o(cl)
+	}
+	return cl
+}
+
+// Options for creating a Simulation.
+type Options struct {
+	ControlPlane string
+	// Duration the simulation keeps running once it stops accepting
+	// changes, giving controllers time to react to them. The Space default
+	// is used if it is zero.
+	Duration time.Duration
+}
+
+// Create a Simulation of a control plane in the supplied group.
+func (c *Client) Create(ctx context.Context, group, name string, opts Options) (*resources.Simulation, error) {
+	if opts.ControlPlane == "" {
+		return nil, errors.New(errNoCtp)
+	}
+	s := &resources.Simulation{}
+	s.SetNamespace(group)
+	s.SetName(name)
+	s.SetControlPlaneName(opts.ControlPlane)
+	s.SetDesiredState(desiredAcceptingChanges)
+	if opts.Duration > 0 {
+		s.SetCompletionCriteria([]resources.SimulationCompletionCriterion{{Type: completionDuration, Duration: opts.Duration.String()}})
+	}
+	c.log.Debug("Creating simulation", "group", group, "name", name, "controlplane", opts.ControlPlane)
+	u, err := c.c.Resource(simulations).Namespace(group).Create(ctx, s.GetUnstructured(), metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtCreate, name)
+	}
+	return &resources.Simulation{Unstructured: *u}, nil
+}
+
+// Get a Simulation.
+func (c *Client) Get(ctx context.Context, group, name string) (*resources.Simulation, error) {
+	u, err := c.c.Resource(simulations).Namespace(group).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtGet, name)
+	}
+	return &resources.Simulation{Unstructured: *u}, nil
+}
+
+// List the Simulations of the supplied group, or of all groups if the group
+// is empty. If a control plane is supplied, only its simulations are listed.
+func (c *Client) List(ctx context.Context, group, controlPlane string) ([]*resources.Simulation, error) {
+	l, err := c.c.Resource(simulations).Namespace(group).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, errList)
+	}
+	sort.Slice(l.Items, func(i, j int) bool {
+		if l.Items[i].GetNamespace() != l.Items[j].GetNamespace() {
+			return l.Items[i].GetNamespace() < l.Items[j].GetNamespace()
+		}
+		return l.Items[i].GetName() < l.Items[j].GetName()
+	})
+	out := []*resources.Simulation{}
+	for i := range l.Items {
+		s := &resources.Simulation{Unstructured: l.Items[i]}
+		if controlPlane != "" && s.GetControlPlaneName() != controlPlane {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Complete stops a Simulation accepting changes so that it computes the
+// changes to managed resources.
+func (c *Client) Complete(ctx context.Context, group, name string) error {
+	return c.setDesiredState(ctx, group, name, desiredComplete)
+}
+
+// Terminate discards the simulated control plane of a Simulation. Its
+// results remain available until it is deleted.
+func (c *Client) Terminate(ctx context.Context, group, name string) error {
+	return c.setDesiredState(ctx, group, name, desiredTerminated)
+}
+
+// Delete a Simulation. Deleting a simulation that does not exist is not an
+// error.
+func (c *Client) Delete(ctx context.Context, group, name string) error {
+	c.log.Debug("Deleting simulation", "group", group, "name", name)
+	err := c.c.Resource(simulations).Namespace(group).Delete(ctx, name, metav1.DeleteOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrapf(err, errFmtDelete, name)
+}
+
+// WaitForAcceptingChanges waits until the simulated control plane of a
+// Simulation accepts changes.
+func (c *Client) WaitForAcceptingChanges(ctx context.Context, group, name string) (*resources.Simulation, error) {
+	return c.wait(ctx, group, name, func(st State) bool {
+		return st == StateAcceptingChanges || st == StateComplete
+	})
+}
+
+// WaitForResult waits for a Simulation to complete and returns its result.
+func (c *Client) WaitForResult(ctx context.Context, group, name string) (*Result, error) {
+	s, err := c.wait(ctx, group, name, func(st State) bool {
+		return st == StateComplete
+	})
+	if err != nil {
+		return nil, err
+	}
+	return SimulationResult(s), nil
+}
+
+// An ApplyFn applies changes to the named simulated control plane.
+type ApplyFn func(ctx context.Context, simulatedControlPlane string) error
+
+// Run a simulation of a control plane: create a Simulation, apply changes to
+// its simulated control plane, wait for the resulting changes to managed
+// resources, then discard the Simulation.
+func (c *Client) Run(ctx context.Context, group, name string, opts Options, apply ApplyFn) (res *Result, err error) {
+	if _, err := c.Create(ctx, group, name, opts); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Discard the simulation even if the context was cancelled.
+		dctx, cancel := context.WithTimeout(context.Background(), discardTimeout)
+		defer cancel()
+		derr := c.Delete(dctx, group, name)
+		if err == nil && derr != nil {
+			err = errors.Wrapf(derr, errFmtDiscard, name)
+		}
+	}()
+
+	s, err := c.WaitForAcceptingChanges(ctx, group, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(ctx, s.GetSimulatedControlPlaneName()); err != nil {
+		return nil, errors.Wrapf(err, errFmtApply, name)
+	}
+	if err := c.Complete(ctx, group, name); err != nil {
+		return nil, err
+	}
+	return c.WaitForResult(ctx, group, name)
+}
+
+func (c *Client) setDesiredState(ctx context.Context, group, name, state string) error {
+	s, err := c.Get(ctx, group, name)
+	if err != nil {
+		return err
+	}
+	s.SetDesiredState(state)
+	c.log.Debug("Setting desired state of simulation", "group", group, "name", name, "state", state)
+	_, err = c.c.Resource(simulations).Namespace(group).Update(ctx, s.GetUnstructured(), metav1.UpdateOptions{})
+	return errors.Wrapf(err, errFmtUpdate, name)
+}
+
+// wait polls the status of a Simulation until done returns true. An error
+// is returned if the simulation fails or is terminated.
+func (c *Client) wait(ctx context.Context, group, name string, done func(State) bool) (*resources.Simulation, error) {
+	var s *resources.Simulation
+	var st Status
+	err := wait.PollUntilContextTimeout(ctx, c.pollInterval, c.timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		s, err = c.Get(ctx, group, name)
+		if err != nil {
+			return false, err
+		}
+		st = SimulationStatus(s)
+		c.log.Debug("Waiting for simulation", "name", name, "state", st.State)
+		return done(st.State) || st.State == StateFailed || st.State == StateTerminated, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch st.State {
+	case StateFailed:
+		return nil, errors.Errorf(errFmtFailed, name, st.Message)
+	case StateTerminated:
+		return nil, errors.Errorf(errFmtFailed, name, "terminated")
+	}
+	return s, nil
+}
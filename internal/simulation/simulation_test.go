@@ -0,0 +1,289 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+func newFake(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		simulations: "SimulationList",
+	}, objs...)
+}
+
+func condition(t, status, reason, msg string) any {
+	return map[string]any{"type": t, "status": status, "reason": reason, "message": msg}
+}
+
+func simulation(group, name, ctp string, status map[string]any) *unstructured.Unstructured {
+	s := &resources.Simulation{}
+	s.SetNamespace(group)
+	s.SetName(name)
+	s.SetControlPlaneName(ctp)
+	u := s.GetUnstructured()
+	if status != nil {
+		u.Object["status"] = status
+	}
+	return u
+}
+
+func TestSimulationStatus(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		status map[string]any
+		state  string
+		want   Status
+	}{
+		"Pending": {
+			reason: "A simulation without conditions should be pending.",
+			want:   Status{State: StatePending},
+		},
+		"AcceptingChanges": {
+			reason: "A simulation whose simulated control plane is ready should accept changes.",
+			status: map[string]any{
+				"simulatedControlPlaneName": "sim-ctp",
+				"conditions":                []any{condition("AcceptingChanges", "True", "", "")},
+			},
+			want: Status{State: StateAcceptingChanges, SimulatedControlPlane: "sim-ctp"},
+		},
+		"Complete": {
+			reason: "A simulation whose changes were computed should be complete.",
+			status: map[string]any{
+				"conditions": []any{condition("AcceptingChanges", "False", "", ""), condition("SimulationComplete", "True", "", "")},
+			},
+			state: desiredComplete,
+			want:  Status{State: StateComplete},
+		},
+		"Terminated": {
+			reason: "A simulation that should be terminated should be reported as terminated.",
+			status: map[string]any{
+				"conditions": []any{condition("SimulationComplete", "True", "", "")},
+			},
+			state: desiredTerminated,
+			want:  Status{State: StateTerminated},
+		},
+		"Failed": {
+			reason: "A simulation that cannot be reconciled should be failed.",
+			status: map[string]any{
+				"conditions": []any{condition("Ready", "False", "ReconcileError", "control plane not found")},
+			},
+			want: Status{State: StateFailed, Message: "control plane not found"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &resources.Simulation{Unstructured: *simulation("default", "sim", "ctp1", tc.status)}
+			if tc.state != "" {
+				s.SetDesiredState(tc.state)
+			}
+			if diff := cmp.Diff(tc.want, SimulationStatus(s)); diff != "" {
+				t.Errorf("\n%s\nSimulationStatus(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSimulationResult(t *testing.T) {
+	s := &resources.Simulation{Unstructured: *simulation("default", "sim", "ctp1", map[string]any{
+		"changes": []any{
+			map[string]any{"change": "Update", "objectReference": map[string]any{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "Bucket", "name": "b"}},
+			map[string]any{"change": "Create", "objectReference": map[string]any{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "Bucket", "name": "a"}},
+			map[string]any{"change": "Replace", "objectReference": map[string]any{"apiVersion": "ec2.aws.upbound.io/v1beta1", "kind": "VPC", "name": "vpc"}},
+		},
+	})}
+	want := &Result{
+		Simulation: "sim",
+		Changes: []Change{
+			{Type: ChangeCreate, APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "a"},
+			{Type: ChangeUpdate, APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "b"},
+			{Type: ChangeUnknown, APIVersion: "ec2.aws.upbound.io/v1beta1", Kind: "VPC", Name: "vpc"},
+		},
+	}
+	got := SimulationResult(s)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SimulationResult(...): -want, +got:\n%s", diff)
+	}
+	if got.Count(ChangeCreate) != 1 || got.Count(ChangeDelete) != 0 || !got.HasChanges() {
+		t.Errorf("SimulationResult(...): unexpected counts: %+v", got)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	type want struct {
+		spec map[string]any
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		opts   Options
+		want   want
+	}{
+		"NoControlPlane": {
+			reason: "A control plane is required.",
+			want: want{
+				err: errors.New(errNoCtp),
+			},
+		},
+		"Success": {
+			reason: "The simulation should accept changes to a clone of the control plane.",
+			opts:   Options{ControlPlane: "ctp1", Duration: 90 * time.Second},
+			want: want{
+				spec: map[string]any{
+					"controlPlaneName":   "ctp1",
+					"desiredState":       "AcceptingChanges",
+					"completionCriteria": []any{map[string]any{"type": "Duration", "duration": "1m30s"}},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(newFake()).Create(context.Background(), "default", "sim", tc.opts)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.spec, got.Object["spec"]); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	c := New(newFake(simulation("b", "s1", "ctp1", nil), simulation("a", "s2", "ctp2", nil), simulation("a", "s1", "ctp1", nil)))
+	l, err := c.List(context.Background(), "", "ctp1")
+	if err != nil {
+		t.Fatalf("List(...): %v", err)
+	}
+	got := []string{}
+	for _, s := range l {
+		got = append(got, s.GetNamespace()+"/"+s.GetName())
+	}
+	if diff := cmp.Diff([]string{"a/s1", "b/s1"}, got); diff != "" {
+		t.Errorf("List(...): -want, +got:\n%s", diff)
+	}
+}
+
+// controller reacts to simulations like a Space would: they accept changes
+// once created and report changes once completed.
+func controller(dyn *fake.FakeDynamicClient, fail string) {
+	dyn.PrependReactor("create", "simulations", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		u := a.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		u.Object["status"] = map[string]any{
+			"simulatedControlPlaneName": u.GetName() + "-ctp",
+			"conditions":                []any{condition("AcceptingChanges", "True", "", "")},
+		}
+		return false, nil, nil
+	})
+	dyn.PrependReactor("update", "simulations", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		u := a.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		if fail != "" {
+			u.Object["status"] = map[string]any{"conditions": []any{condition("Ready", "False", "ReconcileError", fail)}}
+			return false, nil, nil
+		}
+		u.Object["status"] = map[string]any{
+			"conditions": []any{condition("SimulationComplete", "True", "", "")},
+			"changes": []any{
+				map[string]any{"change": "Delete", "objectReference": map[string]any{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "Bucket", "name": "old"}},
+			},
+		}
+		return false, nil, nil
+	})
+}
+
+func TestRun(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		applied string
+		res     *Result
+		err     error
+	}
+	cases := map[string]struct {
+		reason   string
+		fail     string
+		applyErr error
+		want     want
+	}{
+		"Success": {
+			reason: "Changes should be applied to the simulated control plane and the result returned.",
+			want: want{
+				applied: "sim-ctp",
+				res: &Result{
+					Simulation: "sim",
+					Changes:    []Change{{Type: ChangeDelete, APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "old"}},
+				},
+			},
+		},
+		"ApplyError": {
+			reason:   "An error applying changes should be returned.",
+			applyErr: errBoom,
+			want: want{
+				applied: "sim-ctp",
+				err:     errors.Wrapf(errBoom, errFmtApply, "sim"),
+			},
+		},
+		"Failed": {
+			reason: "A failed simulation should return an error.",
+			fail:   "out of capacity",
+			want: want{
+				applied: "sim-ctp",
+				err:     errors.Errorf(errFmtFailed, "sim", "out of capacity"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := newFake()
+			controller(dyn, tc.fail)
+			c := New(dyn, WithPollInterval(time.Millisecond), WithTimeout(5*time.Second))
+
+			applied := ""
+			res, err := c.Run(context.Background(), "default", "sim", Options{ControlPlane: "ctp1"}, func(_ context.Context, ctp string) error {
+				applied = ctp
+				return tc.applyErr
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res, res); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.applied, applied); diff != "" {
+				t.Errorf("\n%s\nRun(...): -want applied to, +got applied to:\n%s", tc.reason, diff)
+			}
+			if l, _ := c.List(context.Background(), "default", ""); len(l) != 0 {
+				t.Errorf("\n%s\nRun(...): simulation was not discarded", tc.reason)
+			}
+		})
+	}
+}
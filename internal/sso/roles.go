@@ -0,0 +1,156 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sso
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+// A Role on a control plane.
+type Role string
+
+// Roles on control planes.
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+const (
+	subjectKindGroup = "Group"
+
+	errFmtInvalidRole = "invalid role %q, must be one of admin, editor, or viewer"
+	errFmtGetBinding  = "cannot get role binding of control plane %q"
+	errFmtSetBinding  = "cannot write role binding of control plane %q"
+	errListBindings   = "cannot list role bindings"
+)
+
+var objectRoleBindings = resources.ObjectRoleBindingGVK.GroupVersion().WithResource("objectrolebindings")
+
+// ParseRole parses a role, ignoring case.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(s)); r {
+	case RoleAdmin, RoleEditor, RoleViewer:
+		return r, nil
+	}
+	return "", errors.Errorf(errFmtInvalidRole, s)
+}
+
+// A Binding grants a group of the identity provider a role on a control
+// plane.
+type Binding struct {
+	// Group of the Space the control plane is in.
+	Group        string
+	ControlPlane string
+	// Subject is the group of the identity provider, including any groups
+	// prefix configured for the OIDC identity provider.
+	Subject string
+	Role    Role
+}
+
+// BindGroup grants the subject, a group of the identity provider, the
+// supplied role on a control plane. Any role the subject already has on the
+// control plane is replaced.
+func (c *Client) BindGroup(ctx context.Context, b Binding) error {
+	if _, err := ParseRole(string(b.Role)); err != nil {
+		return err
+	}
+	return c.updateSubjects(ctx, b.Group, b.ControlPlane, func(s []resources.ObjectRoleBindingSubject) []resources.ObjectRoleBindingSubject {
+		out := without(s, b.Subject)
+		return append(out, resources.ObjectRoleBindingSubject{Kind: subjectKindGroup, Name: b.Subject, Role: string(b.Role)})
+	})
+}
+
+// UnbindGroup revokes any role the subject, a group of the identity provider,
+// has on a control plane.
+func (c *Client) UnbindGroup(ctx context.Context, group, controlPlane, subject string) error {
+	return c.updateSubjects(ctx, group, controlPlane, func(s []resources.ObjectRoleBindingSubject) []resources.ObjectRoleBindingSubject {
+		return without(s, subject)
+	})
+}
+
+// ListBindings lists the roles groups of the identity provider have on the
+// control planes of the supplied Space group, or of all Space groups if the
+// group is empty.
+func (c *Client) ListBindings(ctx context.Context, group string) ([]Binding, error) {
+	l, err := c.dyn.Resource(objectRoleBindings).Namespace(group).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, errListBindings)
+	}
+	out := []Binding{}
+	for i := range l.Items {
+		orb := &resources.ObjectRoleBinding{Unstructured: l.Items[i]}
+		obj := orb.GetObject()
+		if obj.Resource != "controlplanes" {
+			continue
+		}
+		for _, s := range orb.GetSubjects() {
+			if s.Kind != subjectKindGroup {
+				continue
+			}
+			out = append(out, Binding{Group: orb.GetNamespace(), ControlPlane: obj.Name, Subject: s.Name, Role: Role(s.Role)})
+		}
+	}
+	return out, nil
+}
+
+// updateSubjects updates the subjects of the ObjectRoleBinding of a control
+// plane, creating it if necessary. The ObjectRoleBinding is named after the
+// control plane.
+func (c *Client) updateSubjects(ctx context.Context, group, controlPlane string, fn func([]resources.ObjectRoleBindingSubject) []resources.ObjectRoleBindingSubject) error {
+	ri := c.dyn.Resource(objectRoleBindings).Namespace(group)
+	u, err := ri.Get(ctx, controlPlane, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		orb := &resources.ObjectRoleBinding{}
+		orb.SetNamespace(group)
+		orb.SetName(controlPlane)
+		orb.SetLabels(map[string]string{managedByLabel: managedBy})
+		orb.SetObject(resources.ObjectRoleBindingObject{
+			APIGroup: resources.ControlPlaneGVK.Group,
+			Resource: "controlplanes",
+			Name:     controlPlane,
+		})
+		orb.SetSubjects(fn(nil))
+		c.log.Debug("Creating role binding", "group", group, "controlplane", controlPlane)
+		_, err = ri.Create(ctx, orb.GetUnstructured(), metav1.CreateOptions{})
+		return errors.Wrapf(err, errFmtSetBinding, controlPlane)
+	}
+	if err != nil {
+		return errors.Wrapf(err, errFmtGetBinding, controlPlane)
+	}
+	orb := &resources.ObjectRoleBinding{Unstructured: *u}
+	orb.SetSubjects(fn(orb.GetSubjects()))
+	c.log.Debug("Updating role binding", "group", group, "controlplane", controlPlane)
+	_, err = ri.Update(ctx, orb.GetUnstructured(), metav1.UpdateOptions{})
+	return errors.Wrapf(err, errFmtSetBinding, controlPlane)
+}
+
+func without(s []resources.ObjectRoleBindingSubject, group string) []resources.ObjectRoleBindingSubject {
+	out := make([]resources.ObjectRoleBindingSubject, 0, len(s))
+	for _, sub := range s {
+		if sub.Kind == subjectKindGroup && sub.Name == group {
+			continue
+		}
+		out = append(out, sub)
+	}
+	return out
+}
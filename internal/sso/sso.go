@@ -0,0 +1,276 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sso configures single sign-on for a Space: the OIDC identity
+// provider the Space trusts, and the roles groups of the identity provider
+// have on control planes.
+package sso
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultNamespace is the namespace Spaces is installed in.
+	DefaultNamespace = "upbound-system"
+	// DefaultConfigMap is the ConfigMap the authentication configuration of
+	// the Space is written to.
+	DefaultConfigMap = "space-authentication"
+
+	// DefaultUsernameClaim is the claim of the ID token used as user name.
+	DefaultUsernameClaim = "email"
+	// DefaultGroupsClaim is the claim of the ID token used as groups.
+	DefaultGroupsClaim = "groups"
+
+	keyConfig = "config.yaml"
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "up"
+
+	errNoIssuer       = "an issuer URL is required"
+	errIssuerScheme   = "issuer URL must use https"
+	errNoClientID     = "a client ID is required"
+	errParseIssuer    = "cannot parse issuer URL"
+	errGetConfigMap   = "cannot get authentication configuration"
+	errWriteConfigMap = "cannot write authentication configuration"
+	errParseConfig    = "cannot parse authentication configuration"
+	errMarshalConfig  = "cannot marshal authentication configuration"
+	errNoOIDC         = "no OIDC identity provider is configured"
+)
+
+// OIDC configures the OIDC identity provider trusted by a Space.
+type OIDC struct {
+	// IssuerURL of the identity provider, e.g.
+	// https://example.okta.com/oauth2/default.
+	IssuerURL string
+	// ClientID the identity provider issues ID tokens for. Tokens must
+	// have it as audience.
+	ClientID string
+	// CertificateAuthority is the PEM encoded CA of the issuer, if it is
+	// not trusted by the Space.
+	CertificateAuthority string
+
+	// UsernameClaim defaults to email.
+	UsernameClaim  string
+	UsernamePrefix string
+	// GroupsClaim defaults to groups.
+	GroupsClaim  string
+	GroupsPrefix string
+}
+
+// Validate the OIDC configuration.
+func (o OIDC) Validate() error {
+	if o.IssuerURL == "" {
+		return errors.New(errNoIssuer)
+	}
+	u, err := url.Parse(o.IssuerURL)
+	if err != nil {
+		return errors.Wrap(err, errParseIssuer)
+	}
+	if u.Scheme != "https" {
+		return errors.New(errIssuerScheme)
+	}
+	if o.ClientID == "" {
+		return errors.New(errNoClientID)
+	}
+	return nil
+}
+
+// The authentication configuration read by the Spaces API server, in the
+// format of the Kubernetes structured authentication configuration.
+type authenticationConfiguration struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	JWT        []jwtAuthenticator `json:"jwt"`
+}
+
+type jwtAuthenticator struct {
+	Issuer        issuer        `json:"issuer"`
+	ClaimMappings claimMappings `json:"claimMappings"`
+}
+
+type issuer struct {
+	URL                  string   `json:"url"`
+	Audiences            []string `json:"audiences"`
+	CertificateAuthority string   `json:"certificateAuthority,omitempty"`
+}
+
+type claimMappings struct {
+	Username prefixedClaim `json:"username"`
+	Groups   prefixedClaim `json:"groups"`
+}
+
+type prefixedClaim struct {
+	Claim  string `json:"claim"`
+	Prefix string `json:"prefix"`
+}
+
+// Client configures single sign-on for a Space.
+type Client struct {
+	kube      kubernetes.Interface
+	dyn       dynamic.Interface
+	namespace string
+	configMap string
+	log       logging.Logger
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithNamespace sets the namespace Spaces is installed in.
+func WithNamespace(ns string) Option {
+	return func(c *Client) {
+		c.namespace = ns
+	}
+}
+
+// WithConfigMap sets the ConfigMap the authentication configuration is
+// written to.
+func WithConfigMap(name string) Option {
+	return func(c *Client) {
+		c.configMap = name
+	}
+}
+
+// WithLogger sets the logger used by the Client.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// New instantiates a new Client for the Space served by the supplied
+// clients.
+func New(kube kubernetes.Interface, dyn dynamic.Interface, opts ...Option) *Client {
+	c := &Client{
+		kube:      kube,
+		dyn:       dyn,
+		namespace: DefaultNamespace,
+		configMap: DefaultConfigMap,
+		log:       logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// HelmValues returns the values the Spaces Helm chart must be installed or
+// upgraded with for the Space to read the authentication configuration
+// written by SetOIDC.
+func (c *Client) HelmValues() map[string]any {
+	return map[string]any{
+		"authentication": map[string]any{
+			"structuredConfig": c.configMap,
+		},
+	}
+}
+
+// SetOIDC configures the OIDC identity provider trusted by the Space,
+// replacing any previously configured provider.
+func (c *Client) SetOIDC(ctx context.Context, o OIDC) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if o.UsernameClaim == "" {
+		o.UsernameClaim = DefaultUsernameClaim
+	}
+	if o.GroupsClaim == "" {
+		o.GroupsClaim = DefaultGroupsClaim
+	}
+	b, err := yaml.Marshal(authenticationConfiguration{
+		APIVersion: "apiserver.config.k8s.io/v1beta1",
+		Kind:       "AuthenticationConfiguration",
+		JWT: []jwtAuthenticator{{
+			Issuer: issuer{
+				URL:                  o.IssuerURL,
+				Audiences:            []string{o.ClientID},
+				CertificateAuthority: o.CertificateAuthority,
+			},
+			ClaimMappings: claimMappings{
+				Username: prefixedClaim{Claim: o.UsernameClaim, Prefix: o.UsernamePrefix},
+				Groups:   prefixedClaim{Claim: o.GroupsClaim, Prefix: o.GroupsPrefix},
+			},
+		}},
+	})
+	if err != nil {
+		return errors.Wrap(err, errMarshalConfig)
+	}
+
+	cms := c.kube.CoreV1().ConfigMaps(c.namespace)
+	cm, err := cms.Get(ctx, c.configMap, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		c.log.Debug("Creating authentication configuration", "namespace", c.namespace, "name", c.configMap)
+		_, err = cms.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.configMap,
+				Namespace: c.namespace,
+				Labels:    map[string]string{managedByLabel: managedBy},
+			},
+			Data: map[string]string{keyConfig: string(b)},
+		}, metav1.CreateOptions{})
+		return errors.Wrap(err, errWriteConfigMap)
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetConfigMap)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[keyConfig] = string(b)
+	c.log.Debug("Updating authentication configuration", "namespace", c.namespace, "name", c.configMap)
+	_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+	return errors.Wrap(err, errWriteConfigMap)
+}
+
+// GetOIDC returns the OIDC identity provider trusted by the Space.
+func (c *Client) GetOIDC(ctx context.Context) (*OIDC, error) {
+	cm, err := c.kube.CoreV1().ConfigMaps(c.namespace).Get(ctx, c.configMap, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, errors.New(errNoOIDC)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfigMap)
+	}
+	cfg := authenticationConfiguration{}
+	if err := yaml.Unmarshal([]byte(cm.Data[keyConfig]), &cfg); err != nil {
+		return nil, errors.Wrap(err, errParseConfig)
+	}
+	if len(cfg.JWT) == 0 {
+		return nil, errors.New(errNoOIDC)
+	}
+	j := cfg.JWT[0]
+	o := &OIDC{
+		IssuerURL:            j.Issuer.URL,
+		CertificateAuthority: j.Issuer.CertificateAuthority,
+		UsernameClaim:        j.ClaimMappings.Username.Claim,
+		UsernamePrefix:       j.ClaimMappings.Username.Prefix,
+		GroupsClaim:          j.ClaimMappings.Groups.Claim,
+		GroupsPrefix:         j.ClaimMappings.Groups.Prefix,
+	}
+	if len(j.Issuer.Audiences) > 0 {
+		o.ClientID = j.Issuer.Audiences[0]
+	}
+	return o, nil
+}
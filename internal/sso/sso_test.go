@@ -0,0 +1,127 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sso
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newDynamic(objs ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		objectRoleBindings: "ObjectRoleBindingList",
+	}, objs...)
+}
+
+func TestOIDC(t *testing.T) {
+	type want struct {
+		oidc *OIDC
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		oidc   OIDC
+		want   want
+	}{
+		"NoIssuer": {
+			reason: "An issuer is required.",
+			oidc:   OIDC{ClientID: "up"},
+			want:   want{err: errors.New(errNoIssuer)},
+		},
+		"InsecureIssuer": {
+			reason: "The issuer must use https.",
+			oidc:   OIDC{IssuerURL: "http://example.com", ClientID: "up"},
+			want:   want{err: errors.New(errIssuerScheme)},
+		},
+		"NoClientID": {
+			reason: "A client ID is required.",
+			oidc:   OIDC{IssuerURL: "https://example.com"},
+			want:   want{err: errors.New(errNoClientID)},
+		},
+		"Defaults": {
+			reason: "Claims should be defaulted, and the configuration should round trip.",
+			oidc:   OIDC{IssuerURL: "https://example.com", ClientID: "up", GroupsPrefix: "oidc:"},
+			want: want{
+				oidc: &OIDC{IssuerURL: "https://example.com", ClientID: "up", UsernameClaim: "email", GroupsClaim: "groups", GroupsPrefix: "oidc:"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := New(fake.NewSimpleClientset(), newDynamic())
+			err := c.SetOIDC(context.Background(), tc.oidc)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSetOIDC(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			// Setting the configuration again should update it.
+			if err := c.SetOIDC(context.Background(), tc.oidc); err != nil {
+				t.Fatalf("\n%s\nSetOIDC(...): %v", tc.reason, err)
+			}
+			got, err := c.GetOIDC(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\nGetOIDC(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.oidc, got); diff != "" {
+				t.Errorf("\n%s\nGetOIDC(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBindings(t *testing.T) {
+	c := New(fake.NewSimpleClientset(), newDynamic())
+	ctx := context.Background()
+
+	if err := c.BindGroup(ctx, Binding{Group: "default", ControlPlane: "ctp1", Subject: "oidc:platform", Role: "owner"}); err == nil {
+		t.Errorf("BindGroup(...): want error for invalid role")
+	}
+	for _, b := range []Binding{
+		{Group: "default", ControlPlane: "ctp1", Subject: "oidc:platform", Role: RoleViewer},
+		{Group: "default", ControlPlane: "ctp1", Subject: "oidc:platform", Role: RoleAdmin},
+		{Group: "default", ControlPlane: "ctp1", Subject: "oidc:dev", Role: RoleEditor},
+		{Group: "prod", ControlPlane: "ctp2", Subject: "oidc:dev", Role: RoleViewer},
+	} {
+		if err := c.BindGroup(ctx, b); err != nil {
+			t.Fatalf("BindGroup(%v): %v", b, err)
+		}
+	}
+	if err := c.UnbindGroup(ctx, "prod", "ctp2", "oidc:dev"); err != nil {
+		t.Fatalf("UnbindGroup(...): %v", err)
+	}
+
+	got, err := c.ListBindings(ctx, "")
+	if err != nil {
+		t.Fatalf("ListBindings(...): %v", err)
+	}
+	want := []Binding{
+		{Group: "default", ControlPlane: "ctp1", Subject: "oidc:platform", Role: RoleAdmin},
+		{Group: "default", ControlPlane: "ctp1", Subject: "oidc:dev", Role: RoleEditor},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListBindings(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// EndpointPath is the path of the telemetry endpoint of the Upbound API.
+	EndpointPath = "/v1/telemetry"
+	// DefaultBatchSize is the number of events buffered in memory before
+	// they are spooled.
+	DefaultBatchSize = 20
+	// DefaultMaxSpooled is the maximum number of events kept while offline.
+	// The oldest events are dropped first.
+	DefaultMaxSpooled = 1000
+
+	idFile     = "id"
+	spoolFile  = "spool.jsonl"
+	noticeFile = "notice"
+
+	errFmtSendStatus = "telemetry endpoint returned status %d"
+)
+
+// Notice is shown the first time telemetry is collected.
+const Notice = `up collects anonymized usage data: command names, error categories and
+durations. Arguments, flag values, names and credentials are never collected.
+Run "up telemetry disable" or set %s=true to opt out.
+`
+
+// Endpoint returns the telemetry endpoint of the supplied Upbound API
+// endpoint.
+func Endpoint(api *url.URL) string {
+	u := *api
+	u.Path = strings.TrimSuffix(u.Path, "/") + EndpointPath
+	return u.String()
+}
+
+// A Batch is the payload sent to the telemetry endpoint.
+type Batch struct {
+	// InstallID is a random identifier generated on first use. It is not
+	// derived from any user, machine or account information.
+	InstallID string  `json:"installId"`
+	Version   string  `json:"version"`
+	OS        string  `json:"os"`
+	Arch      string  `json:"arch"`
+	Events    []Event `json:"events"`
+}
+
+// Client is a Recorder that spools events to disk. Spooled events are sent by
+// Flush, typically in the background of a later invocation, so that recording
+// never blocks on the network.
+type Client struct {
+	endpoint   string
+	http       *http.Client
+	fs         afero.Fs
+	dir        string
+	version    string
+	batchSize  int
+	maxSpooled int
+
+	mu      sync.Mutex
+	pending []Event
+	// spoolMu serializes access to the spool. It is never held while
+	// sending.
+	spoolMu sync.Mutex
+}
+
+// Option modifies a Client.
+type Option func(*Client)
+
+// WithEndpoint sets where spooled events are sent.
+func WithEndpoint(url string) Option {
+	return func(c *Client) {
+		c.endpoint = url
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send batches.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// WithSpool sets the filesystem and directory the install ID and spooled
+// events are stored in.
+func WithSpool(fs afero.Fs, dir string) Option {
+	return func(c *Client) {
+		c.fs = fs
+		c.dir = dir
+	}
+}
+
+// WithVersion sets the version of up reported with each batch.
+func WithVersion(v string) Option {
+	return func(c *Client) {
+		c.version = v
+	}
+}
+
+// WithBatchSize sets the number of events buffered in memory before they are
+// spooled.
+func WithBatchSize(n int) Option {
+	return func(c *Client) {
+		c.batchSize = n
+	}
+}
+
+// WithMaxSpooled sets the maximum number of events kept while offline.
+func WithMaxSpooled(n int) Option {
+	return func(c *Client) {
+		c.maxSpooled = n
+	}
+}
+
+// New constructs a new Client. Callers must check Disabled before
+// constructing a Client; a Client always records.
+func New(opts ...Option) *Client {
+	c := &Client{
+		http:       http.DefaultClient,
+		fs:         afero.NewOsFs(),
+		batchSize:  DefaultBatchSize,
+		maxSpooled: DefaultMaxSpooled,
+	}
+	if h, err := os.UserHomeDir(); err == nil {
+		c.dir = filepath.Join(h, ".up", "telemetry")
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// NoticeShown returns true if the telemetry notice was shown before.
+func (c *Client) NoticeShown() bool {
+	_, err := c.fs.Stat(filepath.Join(c.dir, noticeFile))
+	return err == nil
+}
+
+// ShowNotice writes the telemetry notice to w unless it was shown before. It
+// returns true if the notice was shown.
+func (c *Client) ShowNotice(w io.Writer) bool {
+	if c.NoticeShown() {
+		return false
+	}
+	fmt.Fprintf(w, Notice, EnvDisable)
+	if err := c.fs.MkdirAll(c.dir, 0o755); err == nil {
+		_ = afero.WriteFile(c.fs, filepath.Join(c.dir, noticeFile), nil, 0o600)
+	}
+	return true
+}
+
+// Record buffers the supplied event, spooling buffered events once enough
+// are buffered. Record never blocks on the network.
+func (c *Client) Record(e Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, e)
+	if len(c.pending) < c.batchSize {
+		return
+	}
+	c.appendSpool(c.pending)
+	c.pending = nil
+}
+
+// Close spools any buffered events. It never blocks on the network, so
+// events are never lost to an exit racing an in-flight send.
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.appendSpool(c.pending)
+	c.pending = nil
+}
+
+// Flush sends spooled events, removing them from the spool once the
+// endpoint accepts them. Events stay spooled if they cannot be sent or the
+// process exits first. Errors are never surfaced; telemetry must not affect
+// the command being run.
+func (c *Client) Flush(ctx context.Context) {
+	if c.endpoint == "" {
+		return
+	}
+	c.spoolMu.Lock()
+	events := c.readSpool()
+	c.spoolMu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+	if err := c.post(ctx, events); err != nil {
+		return
+	}
+
+	// Events spooled while sending were appended after the sent ones.
+	c.spoolMu.Lock()
+	defer c.spoolMu.Unlock()
+	remaining := c.readSpool()
+	if len(remaining) > len(events) {
+		c.writeSpool(remaining[len(events):])
+		return
+	}
+	c.writeSpool(nil)
+}
+
+func (c *Client) post(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(Batch{
+		InstallID: c.installID(),
+		Version:   c.version,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Events:    events,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf(errFmtSendStatus, resp.StatusCode)
+	}
+	return nil
+}
+
+// installID returns the random install ID, generating and storing it on
+// first use. An ephemeral ID is used if it cannot be stored.
+func (c *Client) installID() string {
+	p := filepath.Join(c.dir, idFile)
+	if b, err := afero.ReadFile(c.fs, p); err == nil && len(b) > 0 {
+		return strings.TrimSpace(string(b))
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	id := hex.EncodeToString(b)
+	if err := c.fs.MkdirAll(c.dir, 0o755); err == nil {
+		_ = afero.WriteFile(c.fs, p, []byte(id), 0o600)
+	}
+	return id
+}
+
+// appendSpool appends the supplied events to the spool.
+func (c *Client) appendSpool(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	c.spoolMu.Lock()
+	defer c.spoolMu.Unlock()
+	c.writeSpool(append(c.readSpool(), events...))
+}
+
+func (c *Client) readSpool() []Event {
+	f, err := c.fs.Open(filepath.Join(c.dir, spoolFile))
+	if err != nil {
+		return nil
+	}
+	defer f.Close() // nolint:errcheck
+
+	events := []Event{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		e := Event{}
+		// Skip corrupt lines rather than discarding the whole spool.
+		if err := json.Unmarshal(sc.Bytes(), &e); err == nil {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// writeSpool replaces the spool with the supplied events, keeping only the
+// most recent. A nil slice clears the spool.
+func (c *Client) writeSpool(events []Event) {
+	p := filepath.Join(c.dir, spoolFile)
+	if len(events) == 0 {
+		_ = c.fs.Remove(p)
+		return
+	}
+	if len(events) > c.maxSpooled {
+		events = events[len(events)-c.maxSpooled:]
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	for _, e := range events {
+		_ = enc.Encode(e)
+	}
+	if err := c.fs.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	_ = afero.WriteFile(c.fs, p, buf.Bytes(), 0o600)
+}
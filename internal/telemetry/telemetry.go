@@ -0,0 +1,226 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telemetry records anonymized usage of up so that maintainers can
+// prioritize work. Only command paths, error categories and durations are
+// recorded; arguments, flag values, names and credentials never are.
+package telemetry
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+const (
+	// EnvDisable disables telemetry when set to a true value.
+	EnvDisable = "UP_TELEMETRY_DISABLED"
+	// EnvDoNotTrack is the cross-tool convention for disabling telemetry.
+	EnvDoNotTrack = "DO_NOT_TRACK"
+	// EnvEndpoint overrides the URL events are sent to, which is otherwise
+	// derived from the Upbound API endpoint.
+	EnvEndpoint = "UP_TELEMETRY_ENDPOINT"
+)
+
+// Type is the type of an Event.
+type Type string
+
+const (
+	// TypeCommand is recorded once per command invocation.
+	TypeCommand Type = "command"
+	// TypeAPI is recorded for every API request made by a command.
+	TypeAPI Type = "api"
+	// TypeOperation is recorded for every operation of an API client, e.g.
+	// getting a control plane. An operation may make several API requests.
+	TypeOperation Type = "operation"
+)
+
+// Category is a coarse classification of an error.
+type Category string
+
+// Error categories.
+const (
+	CategoryNone         Category = "none"
+	CategoryCanceled     Category = "canceled"
+	CategoryTimeout      Category = "timeout"
+	CategoryNetwork      Category = "network"
+	CategoryNotFound     Category = "not_found"
+	CategoryUnauthorized Category = "unauthorized"
+	CategoryForbidden    Category = "forbidden"
+	CategoryConflict     Category = "conflict"
+	CategoryInvalid      Category = "invalid"
+	CategoryServer       Category = "server"
+	CategoryUnknown      Category = "unknown"
+)
+
+// An Event is a single anonymized occurrence.
+type Event struct {
+	Type Type `json:"type"`
+	// Name is the command path, e.g. "controlplane create <name>", for
+	// commands, the HTTP method for API requests, and a fixed name, e.g.
+	// "controlplane get", for operations.
+	Name      string    `json:"name"`
+	Category  Category  `json:"category"`
+	Duration  int64     `json:"durationMs"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// A Recorder records events.
+type Recorder interface {
+	Record(e Event)
+}
+
+// Nop is a Recorder that discards all events.
+type Nop struct{}
+
+// Record does nothing.
+func (Nop) Record(Event) {}
+
+// DefaultRecorder is the Recorder used by clients that are not explicitly
+// supplied one. It discards events unless replaced.
+var DefaultRecorder Recorder = Nop{}
+
+// Deferred is a Recorder that records events with the DefaultRecorder at the
+// time they are recorded. Clients that are supplied Deferred while flags are
+// parsed, before the DefaultRecorder is replaced, still record events.
+var Deferred Recorder = deferred{}
+
+type deferred struct{}
+
+func (deferred) Record(e Event) {
+	DefaultRecorder.Record(e)
+}
+
+// Command returns the event recorded for a command invocation.
+func Command(path string, err error, d time.Duration) Event {
+	return Event{
+		Type:      TypeCommand,
+		Name:      path,
+		Category:  Categorize(err),
+		Duration:  d.Milliseconds(),
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// Operation returns the event recorded for an operation of an API client
+// that started at the supplied time.
+func Operation(name string, err error, start time.Time) Event {
+	return Event{
+		Type:      TypeOperation,
+		Name:      name,
+		Category:  Categorize(err),
+		Duration:  time.Since(start).Milliseconds(),
+		Timestamp: start.UTC(),
+	}
+}
+
+// Observe records the operation of an API client that started at the
+// supplied time with the supplied Recorder. It is meant to be deferred by
+// operations with a named error result, e.g.
+//
+//	defer telemetry.Observe(c.telemetry, "controlplane get", time.Now(), &err)
+func Observe(r Recorder, name string, start time.Time, err *error) {
+	if r == nil {
+		return
+	}
+	r.Record(Operation(name, *err, start))
+}
+
+// Disabled returns true if the user opted out of telemetry through the
+// environment, as read by the supplied function, e.g. os.Getenv.
+func Disabled(getenv func(string) string) bool {
+	return truthy(getenv(EnvDisable)) || truthy(getenv(EnvDoNotTrack))
+}
+
+func truthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+// Categorize returns the category of the supplied error.
+func Categorize(err error) Category { //nolint:gocyclo
+	if err == nil {
+		return CategoryNone
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CategoryCanceled
+	case errors.Is(err, context.DeadlineExceeded), kerrors.IsTimeout(err), kerrors.IsServerTimeout(err):
+		return CategoryTimeout
+	case kerrors.IsNotFound(err):
+		return CategoryNotFound
+	case kerrors.IsUnauthorized(err):
+		return CategoryUnauthorized
+	case kerrors.IsForbidden(err):
+		return CategoryForbidden
+	case kerrors.IsConflict(err), kerrors.IsAlreadyExists(err):
+		return CategoryConflict
+	case kerrors.IsInvalid(err), kerrors.IsBadRequest(err):
+		return CategoryInvalid
+	case kerrors.IsInternalError(err), kerrors.IsServiceUnavailable(err):
+		return CategoryServer
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return CategoryTimeout
+		}
+		return CategoryNetwork
+	}
+	// Errors of the internal clients, e.g. control plane not found errors,
+	// are only classified by their failure kind.
+	switch failure.KindOf(err) {
+	case failure.KindNotFound:
+		return CategoryNotFound
+	case failure.KindAuth:
+		return CategoryUnauthorized
+	case failure.KindConflict:
+		return CategoryConflict
+	case failure.KindUsage:
+		return CategoryInvalid
+	case failure.KindTransient:
+		return CategoryServer
+	}
+	return CategoryUnknown
+}
+
+// StatusCategory returns the category of an HTTP response status code.
+func StatusCategory(code int) Category {
+	switch {
+	case code < 400:
+		return CategoryNone
+	case code == 401:
+		return CategoryUnauthorized
+	case code == 403:
+		return CategoryForbidden
+	case code == 404:
+		return CategoryNotFound
+	case code == 408:
+		return CategoryTimeout
+	case code == 409:
+		return CategoryConflict
+	case code < 500:
+		return CategoryInvalid
+	}
+	return CategoryServer
+}
@@ -0,0 +1,265 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/failure"
+)
+
+func TestCategorize(t *testing.T) {
+	gr := schema.GroupResource{Resource: "controlplanes"}
+	cases := map[string]struct {
+		err  error
+		want Category
+	}{
+		"Nil":       {err: nil, want: CategoryNone},
+		"Canceled":  {err: errors.Wrap(context.Canceled, "boom"), want: CategoryCanceled},
+		"Deadline":  {err: context.DeadlineExceeded, want: CategoryTimeout},
+		"NotFound":  {err: errors.Wrap(kerrors.NewNotFound(gr, "a"), "boom"), want: CategoryNotFound},
+		"Forbidden": {err: kerrors.NewForbidden(gr, "a", errors.New("no")), want: CategoryForbidden},
+		"Conflict":  {err: kerrors.NewAlreadyExists(gr, "a"), want: CategoryConflict},
+		"Failure":   {err: errors.Wrap(failure.NotFound(errors.New("no")), "boom"), want: CategoryNotFound},
+		"Unknown":   {err: errors.New("boom"), want: CategoryUnknown},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := Categorize(tc.err); got != tc.want {
+				t.Errorf("Categorize(%v): want %s, got %s", tc.err, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	cases := map[string]struct {
+		env  map[string]string
+		want bool
+	}{
+		"Unset":           {env: map[string]string{}, want: false},
+		"ExplicitlyOn":    {env: map[string]string{EnvDisable: "false"}, want: false},
+		"Disabled":        {env: map[string]string{EnvDisable: "true"}, want: true},
+		"DoNotTrack":      {env: map[string]string{EnvDoNotTrack: "1"}, want: true},
+		"DoNotTrackFalse": {env: map[string]string{EnvDoNotTrack: "0"}, want: false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Disabled(func(k string) string { return tc.env[k] })
+			if got != tc.want {
+				t.Errorf("Disabled(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+// endpoint is a fake telemetry endpoint that records received batches and
+// can be toggled offline.
+type endpoint struct {
+	mu      sync.Mutex
+	offline bool
+	batches []Batch
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.offline {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	b := Batch{}
+	_ = json.NewDecoder(r.Body).Decode(&b)
+	e.batches = append(e.batches, b)
+}
+
+func (e *endpoint) names() [][]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := [][]string{}
+	for _, b := range e.batches {
+		n := []string{}
+		for _, ev := range b.Events {
+			n = append(n, ev.Name)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func TestClient(t *testing.T) {
+	ep := &endpoint{}
+	srv := httptest.NewServer(ep)
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	newClient := func() *Client {
+		return New(WithEndpoint(srv.URL), WithSpool(fs, "/telemetry"), WithBatchSize(2), WithMaxSpooled(3))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Events are spooled on close without being sent.
+	c := newClient()
+	for _, n := range []string{"a", "b", "c"} {
+		c.Record(Event{Name: n})
+	}
+	c.Close()
+	if got := ep.names(); len(got) != 0 {
+		t.Errorf("Close(): want no batches sent, got %v", got)
+	}
+
+	// Spooled events are sent by a later flush, keeping only the most recent
+	// while offline.
+	ep.offline = true
+	c = newClient()
+	c.Flush(ctx)
+	c.Record(Event{Name: "d"})
+	c.Close()
+	if got := c.readSpool(); len(got) != 3 || got[0].Name != "b" {
+		t.Errorf("Flush(...): want spool [b c d], got %v", got)
+	}
+
+	ep.offline = false
+	c = newClient()
+	c.Flush(ctx)
+	c.Record(Event{Name: "e"})
+	c.Close()
+	if diff := cmp.Diff([][]string{{"b", "c", "d"}}, ep.names()); diff != "" {
+		t.Errorf("Flush(...): -want batches, +got batches:\n%s", diff)
+	}
+	if got := c.readSpool(); len(got) != 1 || got[0].Name != "e" {
+		t.Errorf("Flush(...): want spool [e], got %v", got)
+	}
+
+	// Nothing is sent without an endpoint.
+	c = New(WithSpool(fs, "/telemetry"))
+	c.Flush(ctx)
+	if got := len(ep.names()); got != 1 {
+		t.Errorf("Flush(...): want 1 batch, got %d", got)
+	}
+
+	// The install ID is stable across clients.
+	c = newClient()
+	c.Flush(ctx)
+	ids := map[string]bool{}
+	for _, b := range ep.batches {
+		ids[b.InstallID] = true
+	}
+	if len(ep.batches) != 2 || len(ids) != 1 {
+		t.Errorf("Flush(...): want a single install ID across 2 batches, got %v", ids)
+	}
+}
+
+func TestShowNotice(t *testing.T) {
+	c := New(WithSpool(afero.NewMemMapFs(), "/telemetry"))
+	buf := &bytes.Buffer{}
+	if !c.ShowNotice(buf) || !strings.Contains(buf.String(), EnvDisable) {
+		t.Errorf("ShowNotice(...): want notice shown on first use, got %q", buf.String())
+	}
+	buf.Reset()
+	if c.ShowNotice(buf) || buf.Len() != 0 {
+		t.Errorf("ShowNotice(...): want notice shown only once, got %q", buf.String())
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	api, _ := url.Parse("https://api.acme.example/")
+	if got, want := Endpoint(api), "https://api.acme.example/v1/telemetry"; got != want {
+		t.Errorf("Endpoint(%s): want %s, got %s", api, want, got)
+	}
+}
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Record(e Event) {
+	r.events = append(r.events, e)
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	r := &recorder{}
+	h := &http.Client{Transport: WrapTransport(r)(http.DefaultTransport)}
+	resp, err := h.Get(srv.URL + "/v1/controlPlanes/secret-name")
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(r.events) != 1 {
+		t.Fatalf("RoundTrip(...): want 1 event, got %d", len(r.events))
+	}
+	got := r.events[0]
+	want := Event{Type: TypeAPI, Name: http.MethodGet, Category: CategoryNotFound}
+	if diff := cmp.Diff(want, Event{Type: got.Type, Name: got.Name, Category: got.Category}); diff != "" {
+		t.Errorf("RoundTrip(...): -want, +got:\n%s", diff)
+	}
+
+	if WrapTransport(Nop{}) != nil {
+		t.Errorf("WrapTransport(Nop{}): want nil")
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := kerrors.NewNotFound(schema.GroupResource{Resource: "controlplanes"}, "ctp")
+
+	// Deferred must use the DefaultRecorder at the time events are recorded,
+	// not the one set when it was supplied.
+	rec := Deferred
+	r := &recorder{}
+	DefaultRecorder = r
+	defer func() { DefaultRecorder = Nop{} }()
+
+	op := func(err error) (rerr error) {
+		defer Observe(rec, "controlplane get", time.Now(), &rerr)
+		return err
+	}
+	_ = op(nil)
+	_ = op(errBoom)
+
+	got := make([]Event, 0, len(r.events))
+	for _, e := range r.events {
+		got = append(got, Event{Type: e.Type, Name: e.Name, Category: e.Category})
+	}
+	want := []Event{
+		{Type: TypeOperation, Name: "controlplane get", Category: CategoryNone},
+		{Type: TypeOperation, Name: "controlplane get", Category: CategoryNotFound},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Observe(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"net/http"
+	"time"
+)
+
+// Transport is an http.RoundTripper that records an event for every request
+// it sends. Only the method, outcome and latency of requests are recorded.
+type Transport struct {
+	base     http.RoundTripper
+	recorder Recorder
+	now      func() time.Time
+}
+
+// NewTransport returns a Transport that sends requests using the supplied
+// RoundTripper and records them with the supplied Recorder.
+func NewTransport(rt http.RoundTripper, r Recorder) *Transport {
+	return &Transport{base: rt, recorder: r, now: time.Now}
+}
+
+// RoundTrip sends the supplied request, recording its outcome.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.now()
+	resp, err := t.base.RoundTrip(req)
+	e := Event{
+		Type:      TypeAPI,
+		Name:      req.Method,
+		Category:  Categorize(err),
+		Duration:  t.now().Sub(start).Milliseconds(),
+		Timestamp: start.UTC(),
+	}
+	if err == nil {
+		e.Category = StatusCategory(resp.StatusCode)
+	}
+	t.recorder.Record(e)
+	return resp, err
+}
+
+// WrapTransport returns a function that wraps RoundTrippers in a Transport,
+// suitable for rest.Config's WrapTransport. It returns nil for the Nop
+// recorder so that transports are left untouched when telemetry is off.
+func WrapTransport(r Recorder) func(http.RoundTripper) http.RoundTripper {
+	if _, ok := r.(Nop); ok || r == nil {
+		return nil
+	}
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return NewTransport(rt, r)
+	}
+}
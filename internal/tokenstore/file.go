@@ -0,0 +1,132 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenstore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	errReadFile  = "cannot read token file"
+	errWriteFile = "cannot write token file"
+)
+
+// File is a Store that keeps tokens in a file readable only by the current
+// user. It is used when no operating system keychain is available.
+type File struct {
+	fs   afero.Fs
+	path string
+
+	mu sync.Mutex
+}
+
+// FileOption modifies a File.
+type FileOption func(*File)
+
+// WithFS sets the filesystem the token file is stored on.
+func WithFS(fs afero.Fs) FileOption {
+	return func(f *File) {
+		f.fs = fs
+	}
+}
+
+// NewFile constructs a File store that keeps tokens at path.
+func NewFile(path string, opts ...FileOption) *File {
+	f := &File{
+		fs:   afero.NewOsFs(),
+		path: path,
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	return f
+}
+
+// Get returns the token stored in the file for key.
+func (f *File) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokens, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	t, ok := tokens[key]
+	if !ok {
+		return "", NewNotFound(key)
+	}
+	return t, nil
+}
+
+// Set stores token in the file for key.
+func (f *File) Set(key, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokens, err := f.read()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+	return f.write(tokens)
+}
+
+// Delete removes the token stored in the file for key.
+func (f *File) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokens, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key]; !ok {
+		return NewNotFound(key)
+	}
+	delete(tokens, key)
+	return f.write(tokens)
+}
+
+func (f *File) read() (map[string]string, error) {
+	tokens := map[string]string{}
+	b, err := afero.ReadFile(f.fs, f.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errReadFile)
+	}
+	if len(b) == 0 {
+		return tokens, nil
+	}
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return nil, errors.Wrap(err, errReadFile)
+	}
+	return tokens, nil
+}
+
+func (f *File) write(tokens map[string]string) error {
+	b, err := json.Marshal(tokens)
+	if err != nil {
+		return errors.Wrap(err, errWriteFile)
+	}
+	if err := f.fs.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return errors.Wrap(err, errWriteFile)
+	}
+	return errors.Wrap(afero.WriteFile(f.fs, f.path, b, 0o600), errWriteFile)
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenstore
+
+import (
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+const (
+	helperPrefix = "docker-credential-"
+
+	// keychainUser is the username tokens are stored under. Credential
+	// helpers require one, but it carries no meaning for tokens.
+	keychainUser = "up"
+
+	errGetKeychain    = "cannot get token from keychain"
+	errSetKeychain    = "cannot store token in keychain"
+	errDeleteKeychain = "cannot delete token from keychain"
+)
+
+// Keychain is a Store backed by the operating system keychain. It talks to
+// the keychain through a docker credential helper binary, e.g.
+// docker-credential-osxkeychain, so that up does not need cgo.
+type Keychain struct {
+	program client.ProgramFunc
+}
+
+// KeychainOption modifies a Keychain.
+type KeychainOption func(*Keychain)
+
+// WithProgram sets the function used to invoke the credential helper.
+func WithProgram(p client.ProgramFunc) KeychainOption {
+	return func(k *Keychain) {
+		k.program = p
+	}
+}
+
+// NewKeychain constructs a Keychain that uses the named credential helper,
+// e.g. "osxkeychain", "wincred" or "secretservice".
+func NewKeychain(helper string, opts ...KeychainOption) *Keychain {
+	k := &Keychain{
+		program: client.NewShellProgramFunc(helperPrefix + helper),
+	}
+	for _, o := range opts {
+		o(k)
+	}
+	return k
+}
+
+// Get returns the token stored in the keychain for key.
+func (k *Keychain) Get(key string) (string, error) {
+	c, err := client.Get(k.program, key)
+	if credentials.IsErrCredentialsNotFound(err) {
+		return "", NewNotFound(key)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errGetKeychain)
+	}
+	return c.Secret, nil
+}
+
+// Set stores token in the keychain for key.
+func (k *Keychain) Set(key, token string) error {
+	return errors.Wrap(client.Store(k.program, &credentials.Credentials{
+		ServerURL: key,
+		Username:  keychainUser,
+		Secret:    token,
+	}), errSetKeychain)
+}
+
+// Delete removes the token stored in the keychain for key.
+func (k *Keychain) Delete(key string) error {
+	err := client.Erase(k.program, key)
+	if err != nil && strings.Contains(err.Error(), credentials.NewErrCredentialsNotFound().Error()) {
+		return NewNotFound(key)
+	}
+	return errors.Wrap(err, errDeleteKeychain)
+}
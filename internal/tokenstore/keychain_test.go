@@ -0,0 +1,97 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenstore
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeHelper emulates the docker credential helper protocol over an in-memory
+// map.
+type fakeHelper struct {
+	secrets map[string]string
+}
+
+func (h *fakeHelper) program(args ...string) client.Program {
+	return &fakeProgram{helper: h, action: args[0]}
+}
+
+type fakeProgram struct {
+	helper *fakeHelper
+	action string
+	in     []byte
+}
+
+func (p *fakeProgram) Input(in io.Reader) {
+	p.in, _ = io.ReadAll(in)
+}
+
+func (p *fakeProgram) Output() ([]byte, error) {
+	notFound := credentials.NewErrCredentialsNotFound()
+	switch p.action {
+	case credentials.ActionStore:
+		c := &credentials.Credentials{}
+		if err := json.Unmarshal(p.in, c); err != nil {
+			return nil, err
+		}
+		p.helper.secrets[c.ServerURL] = c.Secret
+		return nil, nil
+	case credentials.ActionGet:
+		s, ok := p.helper.secrets[string(p.in)]
+		if !ok {
+			return []byte(notFound.Error()), notFound
+		}
+		return json.Marshal(&credentials.Credentials{Username: keychainUser, Secret: s})
+	case credentials.ActionErase:
+		if _, ok := p.helper.secrets[string(p.in)]; !ok {
+			return []byte(notFound.Error()), notFound
+		}
+		delete(p.helper.secrets, string(p.in))
+		return nil, nil
+	}
+	return nil, nil
+}
+
+func TestKeychain(t *testing.T) {
+	h := &fakeHelper{secrets: map[string]string{}}
+	k := NewKeychain("fake", WithProgram(h.program))
+	key := TokenKey("default")
+
+	if _, err := k.Get(key); !IsNotFound(err) {
+		t.Fatalf("Get(...): want not found error, got %v", err)
+	}
+	if err := k.Set(key, "cool-token"); err != nil {
+		t.Fatalf("Set(...): unexpected error: %v", err)
+	}
+	got, err := k.Get(key)
+	if err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("cool-token", got); diff != "" {
+		t.Errorf("Get(...): -want, +got:\n%s", diff)
+	}
+	if err := k.Delete(key); err != nil {
+		t.Fatalf("Delete(...): unexpected error: %v", err)
+	}
+	if err := k.Delete(key); !IsNotFound(err) {
+		t.Errorf("Delete(...): want not found error, got %v", err)
+	}
+}
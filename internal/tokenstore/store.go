@@ -0,0 +1,157 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tokenstore stores Upbound session tokens and personal access tokens
+// outside of the plaintext up config.
+package tokenstore
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// DefaultFile is the name of the file tokens are stored in when no
+	// operating system keychain is available.
+	DefaultFile = "tokens.json"
+
+	keyFmt = "up.upbound.io/profiles/%s/%s"
+
+	errFmtNotFound = "no token found for %q"
+	errDeleteStale = "cannot remove stale token from fallback store"
+)
+
+// helpers maps operating systems to the docker credential helper used to
+// access their native keychain.
+var helpers = map[string]string{
+	"darwin":  "osxkeychain",
+	"windows": "wincred",
+	"linux":   "secretservice",
+}
+
+// A Store stores secret tokens by key.
+type Store interface {
+	// Get returns the token stored for key. It returns an error satisfying
+	// IsNotFound if no token is stored.
+	Get(key string) (string, error)
+	// Set stores token for key, replacing any existing token.
+	Set(key, token string) error
+	// Delete removes the token stored for key.
+	Delete(key string) error
+}
+
+// SessionKey returns the key of the session token for the named profile.
+func SessionKey(profile string) string {
+	return fmt.Sprintf(keyFmt, profile, "session")
+}
+
+// TokenKey returns the key of the personal access token for the named
+// profile.
+func TokenKey(profile string) string {
+	return fmt.Sprintf(keyFmt, profile, "token")
+}
+
+// RefreshKey returns the key of the OAuth2 refresh token for the named
+// profile.
+func RefreshKey(profile string) string {
+	return fmt.Sprintf(keyFmt, profile, "refresh")
+}
+
+type notFoundError struct {
+	key string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf(errFmtNotFound, e.key)
+}
+
+// NewNotFound returns an error indicating that no token is stored for key.
+func NewNotFound(key string) error {
+	return &notFoundError{key: key}
+}
+
+// IsNotFound returns true if the error indicates that no token is stored.
+func IsNotFound(err error) bool {
+	var nf *notFoundError
+	return errors.As(err, &nf)
+}
+
+// Default returns a Store backed by the operating system keychain, falling
+// back to a file at path when no keychain credential helper is installed or
+// the keychain cannot be reached.
+func Default(path string, opts ...FileOption) Store {
+	f := NewFile(path, opts...)
+	h, ok := helpers[runtime.GOOS]
+	if !ok {
+		return f
+	}
+	if _, err := exec.LookPath(helperPrefix + h); err != nil {
+		return f
+	}
+	return NewFallback(NewKeychain(h), f)
+}
+
+// Fallback is a Store that prefers a primary Store and uses a secondary Store
+// when the primary is unavailable.
+type Fallback struct {
+	primary   Store
+	secondary Store
+}
+
+// NewFallback constructs a new Fallback store.
+func NewFallback(primary, secondary Store) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary}
+}
+
+// Get returns the token from the primary store, or from the secondary store
+// if the primary does not have it.
+func (f *Fallback) Get(key string) (string, error) {
+	t, err := f.primary.Get(key)
+	if err == nil {
+		return t, nil
+	}
+	return f.secondary.Get(key)
+}
+
+// Set stores the token in the primary store, or in the secondary store if the
+// primary cannot be written. A token stored in the primary store is removed
+// from the secondary store, so that a stale token is not returned by Get if
+// the primary later becomes unavailable.
+func (f *Fallback) Set(key, token string) error {
+	if err := f.primary.Set(key, token); err != nil {
+		return f.secondary.Set(key, token)
+	}
+	if err := f.secondary.Delete(key); err != nil && !IsNotFound(err) {
+		return errors.Wrap(err, errDeleteStale)
+	}
+	return nil
+}
+
+// Delete removes the token from both stores. It only returns a not found
+// error if neither store had the token.
+func (f *Fallback) Delete(key string) error {
+	perr := f.primary.Delete(key)
+	serr := f.secondary.Delete(key)
+	switch {
+	case perr == nil || serr == nil:
+		return nil
+	case !IsNotFound(serr):
+		return serr
+	default:
+		return perr
+	}
+}
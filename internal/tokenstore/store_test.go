@@ -0,0 +1,204 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenstore
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+var errBoom = errors.New("boom")
+
+type mockStore struct {
+	MockGet    func(key string) (string, error)
+	MockSet    func(key, token string) error
+	MockDelete func(key string) error
+}
+
+func (m *mockStore) Get(key string) (string, error) { return m.MockGet(key) }
+func (m *mockStore) Set(key, token string) error    { return m.MockSet(key, token) }
+func (m *mockStore) Delete(key string) error        { return m.MockDelete(key) }
+
+func TestFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	f := NewFile("/.up/tokens.json", WithFS(fs))
+	key := SessionKey("default")
+
+	if _, err := f.Get(key); !IsNotFound(err) {
+		t.Fatalf("Get(...): want not found error, got %v", err)
+	}
+	if err := f.Set(key, "cool-token"); err != nil {
+		t.Fatalf("Set(...): unexpected error: %v", err)
+	}
+	got, err := f.Get(key)
+	if err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("cool-token", got); diff != "" {
+		t.Errorf("Get(...): -want, +got:\n%s", diff)
+	}
+	info, err := fs.Stat("/.up/tokens.json")
+	if err != nil {
+		t.Fatalf("Stat(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("-rw-------", info.Mode().Perm().String()); diff != "" {
+		t.Errorf("Stat(...): -want mode, +got mode:\n%s", diff)
+	}
+	if err := f.Delete(key); err != nil {
+		t.Fatalf("Delete(...): unexpected error: %v", err)
+	}
+	if err := f.Delete(key); !IsNotFound(err) {
+		t.Errorf("Delete(...): want not found error, got %v", err)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	type want struct {
+		token string
+		err   error
+	}
+	cases := map[string]struct {
+		reason    string
+		primary   Store
+		secondary Store
+		want      want
+	}{
+		"Primary": {
+			reason: "A token in the primary store should be returned.",
+			primary: &mockStore{
+				MockGet: func(string) (string, error) { return "primary", nil },
+			},
+			want: want{
+				token: "primary",
+			},
+		},
+		"PrimaryUnavailable": {
+			reason: "The secondary store should be used if the primary cannot be reached.",
+			primary: &mockStore{
+				MockGet: func(string) (string, error) { return "", errBoom },
+			},
+			secondary: &mockStore{
+				MockGet: func(string) (string, error) { return "secondary", nil },
+			},
+			want: want{
+				token: "secondary",
+			},
+		},
+		"NotFound": {
+			reason: "A not found error should be returned if neither store has the token.",
+			primary: &mockStore{
+				MockGet: func(key string) (string, error) { return "", NewNotFound(key) },
+			},
+			secondary: &mockStore{
+				MockGet: func(key string) (string, error) { return "", NewNotFound(key) },
+			},
+			want: want{
+				err: NewNotFound("key"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewFallback(tc.primary, tc.secondary).Get("key")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.token, got); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFallbackSet(t *testing.T) {
+	type want struct {
+		primary   map[string]string
+		secondary map[string]string
+		err       error
+	}
+	cases := map[string]struct {
+		reason     string
+		primaryErr error
+		deleteErr  error
+		want       want
+	}{
+		"Primary": {
+			reason: "A token should be stored in the primary store and removed from the secondary store.",
+			want: want{
+				primary:   map[string]string{"key": "new"},
+				secondary: map[string]string{},
+			},
+		},
+		"PrimaryUnavailable": {
+			reason:     "A token should be stored in the secondary store if the primary cannot be written.",
+			primaryErr: errBoom,
+			want: want{
+				primary:   map[string]string{},
+				secondary: map[string]string{"key": "new"},
+			},
+		},
+		"DeleteStaleError": {
+			reason:    "An error removing the stale token from the secondary store should be returned.",
+			deleteErr: errBoom,
+			want: want{
+				primary:   map[string]string{"key": "new"},
+				secondary: map[string]string{"key": "old"},
+				err:       errors.Wrap(errBoom, errDeleteStale),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			primary := map[string]string{}
+			secondary := map[string]string{"key": "old"}
+			p := &mockStore{
+				MockSet: func(key, token string) error {
+					if tc.primaryErr != nil {
+						return tc.primaryErr
+					}
+					primary[key] = token
+					return nil
+				},
+			}
+			s := &mockStore{
+				MockSet: func(key, token string) error {
+					secondary[key] = token
+					return nil
+				},
+				MockDelete: func(key string) error {
+					if tc.deleteErr != nil {
+						return tc.deleteErr
+					}
+					delete(secondary, key)
+					return nil
+				},
+			}
+			err := NewFallback(p, s).Set("key", "new")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.primary, primary); diff != "" {
+				t.Errorf("\n%s\nSet(...): -want primary, +got primary:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.secondary, secondary); diff != "" {
+				t.Errorf("\n%s\nSet(...): -want secondary, +got secondary:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
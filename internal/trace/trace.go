@@ -0,0 +1,287 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trace builds the graph of objects behind a Crossplane claim or
+// composite resource.
+package trace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/resources"
+)
+
+const (
+	// DefaultMaxDepth is the default number of levels walked below the root.
+	DefaultMaxDepth = 10
+	// DefaultSettle is the default time a watch waits for events to settle
+	// before rebuilding the graph.
+	DefaultSettle = 250 * time.Millisecond
+
+	errGetRoot      = "failed to get root object"
+	errFmtMapKind   = "failed to map kind %s"
+	errFmtGetObject = "failed to get %s"
+	errListEvents   = "failed to list events"
+	errMaxDepth     = "maximum depth reached"
+)
+
+// Ref references an object in the graph.
+type Ref struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// String returns the reference in kind.group/name form.
+func (r Ref) String() string {
+	gk := schema.FromAPIVersionAndKind(r.APIVersion, r.Kind).GroupKind()
+	if r.Namespace != "" {
+		return fmt.Sprintf("%s/%s/%s", gk, r.Namespace, r.Name)
+	}
+	return fmt.Sprintf("%s/%s", gk, r.Name)
+}
+
+// A Node is an object in the graph along with its status and the objects it
+// references. Nodes whose object could not be read carry an Error instead of
+// an Object.
+type Node struct {
+	Ref        Ref                        `json:"ref"`
+	Object     *unstructured.Unstructured `json:"object,omitempty"`
+	Conditions []xpv1.Condition           `json:"conditions,omitempty"`
+	Events     []corev1.Event             `json:"events,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+	Children   []*Node                    `json:"children,omitempty"`
+}
+
+// Ready returns true if the node's object reports it is ready.
+func (n *Node) Ready() bool {
+	return n.condition(xpv1.TypeReady)
+}
+
+// Synced returns true if the node's object reports it is synced.
+func (n *Node) Synced() bool {
+	return n.condition(xpv1.TypeSynced)
+}
+
+func (n *Node) condition(ct xpv1.ConditionType) bool {
+	for _, c := range n.Conditions {
+		if c.Type == ct {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Walk calls fn for the node and each of its descendants, depth first, along
+// with their depth below the node.
+func (n *Node) Walk(fn func(n *Node, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *Node) walk(fn func(n *Node, depth int), depth int) {
+	fn(n, depth)
+	for _, c := range n.Children {
+		c.walk(fn, depth+1)
+	}
+}
+
+// Builder builds object graphs.
+type Builder struct {
+	dyn      dynamic.Interface
+	mapper   meta.RESTMapper
+	events   kubernetes.Interface
+	maxDepth int
+	settle   time.Duration
+	resync   time.Duration
+}
+
+// Option modifies a Builder.
+type Option func(*Builder)
+
+// WithEvents attaches the events of each object to its node using the
+// supplied client.
+func WithEvents(kube kubernetes.Interface) Option {
+	return func(b *Builder) {
+		b.events = kube
+	}
+}
+
+// WithMaxDepth sets the number of levels walked below the root.
+func WithMaxDepth(d int) Option {
+	return func(b *Builder) {
+		b.maxDepth = d
+	}
+}
+
+// WithSettle sets how long a watch waits for events to settle before
+// rebuilding the graph.
+func WithSettle(d time.Duration) Option {
+	return func(b *Builder) {
+		b.settle = d
+	}
+}
+
+// WithResync sets how often a watch's informers resync. Zero disables
+// resyncing.
+func WithResync(d time.Duration) Option {
+	return func(b *Builder) {
+		b.resync = d
+	}
+}
+
+// New constructs a new Builder.
+func New(dyn dynamic.Interface, mapper meta.RESTMapper, opts ...Option) *Builder {
+	b := &Builder{
+		dyn:      dyn,
+		mapper:   mapper,
+		maxDepth: DefaultMaxDepth,
+		settle:   DefaultSettle,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Build returns the graph rooted at the referenced claim or composite
+// resource. Claims are followed to their composite resource through
+// spec.resourceRef, and composite resources to their composed resources
+// through spec.resourceRefs. Objects that cannot be read are recorded as
+// nodes with an error rather than failing the whole graph; only failing to
+// read the root is an error.
+func (b *Builder) Build(ctx context.Context, root Ref) (*Node, error) {
+	n, err := b.node(ctx, root)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetRoot)
+	}
+	visited := map[types.UID]bool{n.Object.GetUID(): true}
+	b.children(ctx, n, visited, 1)
+	return n, nil
+}
+
+func (b *Builder) children(ctx context.Context, n *Node, visited map[types.UID]bool, depth int) {
+	for _, ref := range refs(n.Object) {
+		if depth > b.maxDepth {
+			n.Children = append(n.Children, &Node{Ref: ref, Error: errMaxDepth})
+			continue
+		}
+		c, err := b.node(ctx, ref)
+		if err != nil {
+			n.Children = append(n.Children, &Node{Ref: ref, Error: err.Error()})
+			continue
+		}
+		n.Children = append(n.Children, c)
+		// Guard against reference cycles.
+		if visited[c.Object.GetUID()] {
+			continue
+		}
+		visited[c.Object.GetUID()] = true
+		b.children(ctx, c, visited, depth+1)
+	}
+}
+
+func (b *Builder) node(ctx context.Context, ref Ref) (*Node, error) {
+	m, err := b.mapping(ref)
+	if err != nil {
+		return nil, err
+	}
+	var ri dynamic.ResourceInterface = b.dyn.Resource(m.Resource)
+	if m.Scope.Name() == meta.RESTScopeNameNamespace {
+		ri = b.dyn.Resource(m.Resource).Namespace(ref.Namespace)
+	}
+	u, err := ri.Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtGetObject, ref)
+	}
+
+	n := &Node{Ref: ref, Object: u, Conditions: conditions(u)}
+	if b.events != nil {
+		ev, err := b.eventsFor(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		n.Events = ev
+	}
+	return n, nil
+}
+
+func (b *Builder) mapping(ref Ref) (*meta.RESTMapping, error) {
+	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+	m, err := b.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	return m, errors.Wrapf(err, errFmtMapKind, gvk)
+}
+
+func (b *Builder) eventsFor(ctx context.Context, u *unstructured.Unstructured) ([]corev1.Event, error) {
+	// Cluster scoped objects have their events recorded in the default
+	// namespace.
+	ns := u.GetNamespace()
+	if ns == "" {
+		ns = metav1.NamespaceDefault
+	}
+	l, err := b.events.CoreV1().Events(ns).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.uid", string(u.GetUID())).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errListEvents)
+	}
+	ev := []corev1.Event{}
+	for _, e := range l.Items {
+		if e.InvolvedObject.UID == u.GetUID() {
+			ev = append(ev, e)
+		}
+	}
+	return ev, nil
+}
+
+func conditions(u *unstructured.Unstructured) []xpv1.Condition {
+	c := xpv1.ConditionedStatus{}
+	_ = resources.GetValueInto(u.Object, "status", &c)
+	return c.Conditions
+}
+
+// refs returns the objects referenced by a claim or composite resource.
+func refs(u *unstructured.Unstructured) []Ref {
+	out := []Ref{}
+	r := Ref{}
+	if err := resources.GetValueInto(u.Object, "spec.resourceRef", &r); err == nil && r.Name != "" {
+		// A claim's composite resource is cluster scoped.
+		out = append(out, r)
+	}
+	rs := []Ref{}
+	if err := resources.GetValueInto(u.Object, "spec.resourceRefs", &rs); err == nil {
+		for _, r := range rs {
+			if r.Name == "" {
+				continue
+			}
+			out = append(out, r)
+		}
+	}
+	return out
+}
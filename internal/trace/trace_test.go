@@ -0,0 +1,171 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kfake "k8s.io/client-go/kubernetes/fake"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var (
+	claimRef  = Ref{APIVersion: "example.org/v1", Kind: "Database", Name: "db", Namespace: "default"}
+	xrRef     = Ref{APIVersion: "example.org/v1", Kind: "XDatabase", Name: "db-abc"}
+	bucketRef = Ref{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "db-abc-bucket"}
+	missRef   = Ref{APIVersion: "s3.aws.upbound.io/v1beta1", Kind: "Bucket", Name: "db-abc-missing"}
+)
+
+func mapper() meta.RESTMapper {
+	m := meta.NewDefaultRESTMapper(nil)
+	m.Add(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Database"}, meta.RESTScopeNamespace)
+	m.Add(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XDatabase"}, meta.RESTScopeRoot)
+	m.Add(schema.GroupVersionKind{Group: "s3.aws.upbound.io", Version: "v1beta1", Kind: "Bucket"}, meta.RESTScopeRoot)
+	return m
+}
+
+func obj(r Ref, uid string, spec map[string]any, conds ...xpv1.Condition) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	u.SetAPIVersion(r.APIVersion)
+	u.SetKind(r.Kind)
+	u.SetName(r.Name)
+	u.SetNamespace(r.Namespace)
+	u.SetUID(types.UID(uid))
+	if len(conds) > 0 {
+		cs := []any{}
+		for _, c := range conds {
+			cs = append(cs, map[string]any{"type": string(c.Type), "status": string(c.Status)})
+		}
+		u.Object["status"] = map[string]any{"conditions": cs}
+	}
+	return u
+}
+
+func ref(r Ref) map[string]any {
+	return map[string]any{"apiVersion": r.APIVersion, "kind": r.Kind, "name": r.Name}
+}
+
+func TestBuild(t *testing.T) {
+	claim := obj(claimRef, "1", map[string]any{"resourceRef": ref(xrRef)}, xpv1.Available())
+	xr := obj(xrRef, "2", map[string]any{"resourceRefs": []any{ref(bucketRef), ref(missRef)}}, xpv1.Available(), xpv1.ReconcileSuccess())
+	bucket := obj(bucketRef, "3", map[string]any{}, xpv1.Creating())
+	cyclic := obj(xrRef, "2", map[string]any{"resourceRefs": []any{ref(xrRef)}})
+
+	event := corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "e", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{UID: "3"},
+		Reason:         "CannotCreateExternalResource",
+	}
+
+	type want struct {
+		tree []string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		objs   []runtime.Object
+		root   Ref
+		opts   []Option
+		want   want
+	}{
+		"Claim": {
+			reason: "A claim should be followed to its composite resource and composed resources, recording missing objects as errored nodes.",
+			objs:   []runtime.Object{claim, xr, bucket},
+			root:   claimRef,
+			opts:   []Option{WithEvents(kfake.NewSimpleClientset(&event))},
+			want: want{
+				tree: []string{
+					"0 Database.example.org/default/db ready=true synced=false events=0",
+					"1 XDatabase.example.org/db-abc ready=true synced=true events=0",
+					"2 Bucket.s3.aws.upbound.io/db-abc-bucket ready=false synced=false events=1",
+					"2 Bucket.s3.aws.upbound.io/db-abc-missing error",
+				},
+			},
+		},
+		"MaxDepth": {
+			reason: "References below the maximum depth should not be followed.",
+			objs:   []runtime.Object{claim, xr, bucket},
+			root:   claimRef,
+			opts:   []Option{WithMaxDepth(1)},
+			want: want{
+				tree: []string{
+					"0 Database.example.org/default/db ready=true synced=false events=0",
+					"1 XDatabase.example.org/db-abc ready=true synced=true events=0",
+					"2 Bucket.s3.aws.upbound.io/db-abc-bucket error",
+					"2 Bucket.s3.aws.upbound.io/db-abc-missing error",
+				},
+			},
+		},
+		"Cycle": {
+			reason: "Reference cycles should not be followed more than once.",
+			objs:   []runtime.Object{cyclic},
+			root:   xrRef,
+			want: want{
+				tree: []string{
+					"0 XDatabase.example.org/db-abc ready=false synced=false events=0",
+					"1 XDatabase.example.org/db-abc ready=false synced=false events=0",
+				},
+			},
+		},
+		"MissingRoot": {
+			reason: "Failing to get the root object should return an error.",
+			root:   claimRef,
+			want: want{
+				err: errors.Wrap(errors.Wrapf(errors.New(`databases.example.org "db" not found`), errFmtGetObject, claimRef), errGetRoot),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), tc.objs...)
+			n, err := New(dyn, mapper(), tc.opts...).Build(context.Background(), tc.root)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nBuild(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var tree []string
+			if n != nil {
+				n.Walk(func(n *Node, depth int) {
+					tree = append(tree, describe(n, depth))
+				})
+			}
+			if diff := cmp.Diff(tc.want.tree, tree, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nBuild(...): -want tree, +got tree:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func describe(n *Node, depth int) string {
+	if n.Error != "" {
+		return fmt.Sprintf("%d %s error", depth, n.Ref)
+	}
+	return fmt.Sprintf("%d %s ready=%t synced=%t events=%d", depth, n.Ref, n.Ready(), n.Synced(), len(n.Events))
+}
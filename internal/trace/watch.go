@@ -0,0 +1,205 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ChangeType is the type of a change between two snapshots of a graph.
+type ChangeType string
+
+const (
+	// ChangeCondition indicates a condition of a node changed status.
+	ChangeCondition ChangeType = "ConditionChanged"
+	// ChangeChildAdded indicates a node was added to the graph.
+	ChangeChildAdded ChangeType = "ChildAdded"
+	// ChangeChildRemoved indicates a node was removed from the graph.
+	ChangeChildRemoved ChangeType = "ChildRemoved"
+	// ChangeError indicates a node's error changed, e.g. because its object
+	// was deleted or became readable again.
+	ChangeError ChangeType = "ErrorChanged"
+)
+
+// A Change between two snapshots of a graph.
+type Change struct {
+	Type ChangeType `json:"type"`
+	Ref  Ref        `json:"ref"`
+
+	// Condition, From and To are set for condition changes.
+	Condition xpv1.ConditionType     `json:"condition,omitempty"`
+	From      corev1.ConditionStatus `json:"from,omitempty"`
+	To        corev1.ConditionStatus `json:"to,omitempty"`
+
+	// Message is the new condition message or node error, if any.
+	Message string `json:"message,omitempty"`
+}
+
+// An Update is a snapshot of a watched graph and the changes since the
+// previous snapshot. Err is set if the graph could not be rebuilt, in which
+// case Root is the last known graph.
+type Update struct {
+	Root    *Node
+	Changes []Change
+	Err     error
+}
+
+// Watch builds the graph rooted at the referenced object and keeps it up to
+// date using informers for every kind in the graph. An update is sent when
+// the graph is first built and whenever it changes. The returned channel is
+// closed when the context is cancelled.
+func (b *Builder) Watch(ctx context.Context, root Ref) (<-chan Update, error) {
+	n, err := b.Build(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	// Any event on a watched kind triggers a rebuild. Events arriving during
+	// a rebuild are coalesced into a single subsequent rebuild.
+	trigger := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { notify() },
+		UpdateFunc: func(any, any) { notify() },
+		DeleteFunc: func(any) { notify() },
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(b.dyn, b.resync)
+	watched := map[schema.GroupVersionResource]bool{}
+	inform := func(n *Node) {
+		n.Walk(func(n *Node, _ int) {
+			m, err := b.mapping(n.Ref)
+			if err != nil || watched[m.Resource] {
+				return
+			}
+			watched[m.Resource] = true
+			_, _ = factory.ForResource(m.Resource).Informer().AddEventHandler(handler)
+		})
+		// Start only starts informers that are not already running.
+		factory.Start(ctx.Done())
+	}
+	inform(n)
+
+	updates := make(chan Update)
+	go func() {
+		defer close(updates)
+		defer factory.Shutdown()
+
+		if !send(ctx, updates, Update{Root: n, Changes: []Change{}}) {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-trigger:
+			}
+
+			// Let closely spaced events settle before rebuilding.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(b.settle):
+			}
+
+			next, err := b.Build(ctx, root)
+			if err != nil {
+				if !send(ctx, updates, Update{Root: n, Err: err}) {
+					return
+				}
+				continue
+			}
+			inform(next)
+			changes := Diff(n, next)
+			n = next
+			if len(changes) == 0 {
+				continue
+			}
+			if !send(ctx, updates, Update{Root: n, Changes: changes}) {
+				return
+			}
+		}
+	}()
+	return updates, nil
+}
+
+func send(ctx context.Context, ch chan<- Update, u Update) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- u:
+		return true
+	}
+}
+
+// Diff returns the changes between two snapshots of a graph.
+func Diff(from, to *Node) []Change {
+	before, after := index(from), index(to)
+	changes := []Change{}
+
+	// Walk the new graph so changes are reported in tree order.
+	to.Walk(func(n *Node, _ int) {
+		o, ok := before[n.Ref]
+		if !ok {
+			changes = append(changes, Change{Type: ChangeChildAdded, Ref: n.Ref, Message: n.Error})
+			before[n.Ref] = n
+			return
+		}
+		if o.Error != n.Error {
+			changes = append(changes, Change{Type: ChangeError, Ref: n.Ref, Message: n.Error})
+		}
+		for _, c := range n.Conditions {
+			prev := corev1.ConditionUnknown
+			for _, oc := range o.Conditions {
+				if oc.Type == c.Type {
+					prev = oc.Status
+				}
+			}
+			if prev != c.Status {
+				changes = append(changes, Change{Type: ChangeCondition, Ref: n.Ref, Condition: c.Type, From: prev, To: c.Status, Message: c.Message})
+			}
+		}
+	})
+	from.Walk(func(n *Node, _ int) {
+		if _, ok := after[n.Ref]; !ok {
+			changes = append(changes, Change{Type: ChangeChildRemoved, Ref: n.Ref})
+			after[n.Ref] = n
+		}
+	})
+	return changes
+}
+
+func index(root *Node) map[Ref]*Node {
+	idx := map[Ref]*Node{}
+	root.Walk(func(n *Node, _ int) {
+		if _, ok := idx[n.Ref]; !ok {
+			idx[n.Ref] = n
+		}
+	})
+	return idx
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+func TestDiff(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		from   *Node
+		to     *Node
+		want   []Change
+	}{
+		"NoChange": {
+			reason: "Identical graphs should have no changes.",
+			from:   &Node{Ref: xrRef, Conditions: []xpv1.Condition{xpv1.Available()}},
+			to:     &Node{Ref: xrRef, Conditions: []xpv1.Condition{xpv1.Available()}},
+			want:   []Change{},
+		},
+		"Changes": {
+			reason: "Condition transitions, errors and added and removed children should be reported in tree order.",
+			from: &Node{Ref: xrRef, Conditions: []xpv1.Condition{xpv1.Creating()}, Children: []*Node{
+				{Ref: bucketRef},
+				{Ref: missRef, Error: "not found"},
+			}},
+			to: &Node{Ref: xrRef, Conditions: []xpv1.Condition{xpv1.Available(), xpv1.ReconcileSuccess()}, Children: []*Node{
+				{Ref: missRef},
+				{Ref: claimRef},
+			}},
+			want: []Change{
+				{Type: ChangeCondition, Ref: xrRef, Condition: xpv1.TypeReady, From: corev1.ConditionFalse, To: corev1.ConditionTrue},
+				{Type: ChangeCondition, Ref: xrRef, Condition: xpv1.TypeSynced, From: corev1.ConditionUnknown, To: corev1.ConditionTrue},
+				{Type: ChangeError, Ref: missRef},
+				{Type: ChangeChildAdded, Ref: claimRef},
+				{Type: ChangeChildRemoved, Ref: bucketRef},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Diff(tc.from, tc.to)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	xr := obj(xrRef, "2", map[string]any{"resourceRefs": []any{ref(bucketRef)}})
+	bucket := obj(bucketRef, "3", map[string]any{}, xpv1.Creating())
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), xr, bucket)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updates, err := New(dyn, mapper(), WithSettle(10*time.Millisecond)).Watch(ctx, xrRef)
+	if err != nil {
+		t.Fatalf("Watch(...): %v", err)
+	}
+	initial := <-updates
+	if diff := cmp.Diff(1, len(initial.Root.Children)); diff != "" {
+		t.Errorf("Watch(...): -want children, +got children:\n%s", diff)
+	}
+
+	ready := obj(bucketRef, "3", map[string]any{}, xpv1.Available())
+	bgvr := schema.GroupVersionResource{Group: "s3.aws.upbound.io", Version: "v1beta1", Resource: "buckets"}
+	if _, err := dyn.Resource(bgvr).Update(ctx, ready, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update(...): %v", err)
+	}
+
+	want := []Change{{Type: ChangeCondition, Ref: bucketRef, Condition: xpv1.TypeReady, From: corev1.ConditionFalse, To: corev1.ConditionTrue}}
+	select {
+	case u := <-updates:
+		if diff := cmp.Diff(want, u.Changes); diff != "" {
+			t.Errorf("Watch(...): -want changes, +got changes:\n%s", diff)
+		}
+	case <-ctx.Done():
+		t.Fatal("Watch(...): timed out waiting for update")
+	}
+
+	cancel()
+	for range updates {
+	}
+}
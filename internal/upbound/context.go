@@ -16,21 +16,33 @@ package upbound
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"path/filepath"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/spf13/afero"
-	"k8s.io/client-go/transport"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	"github.com/upbound/up-sdk-go"
 
+	"github.com/upbound/up/internal/auth"
+	"github.com/upbound/up/internal/capability"
 	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/credhelper"
+	uphttp "github.com/upbound/up/internal/http"
+	"github.com/upbound/up/internal/kube"
 	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/telemetry"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 const (
@@ -54,6 +66,8 @@ const (
 
 const (
 	errProfileNotFoundFmt = "profile not found with identifier: %s"
+	errFmtProfileEndpoint = "cannot parse %s endpoint of profile %q"
+	errGetSession         = "cannot get session token"
 )
 
 // Context includes common data that Upbound consumers may utilize.
@@ -65,16 +79,22 @@ type Context struct {
 	Domain      *url.URL
 
 	InsecureSkipTLSVerify bool
+	HTTP                  uphttp.Config
 
 	APIEndpoint      *url.URL
 	ProxyEndpoint    *url.URL
 	RegistryEndpoint *url.URL
 	Cfg              *config.Config
 	CfgSrc           config.Source
+	Profiles         *profile.Manager
+	Tokens           tokenstore.Store
 
 	DebugLevel    int
 	WrapTransport func(rt http.RoundTripper) http.RoundTripper
 
+	// Telemetry records anonymized usage of the Upbound API and Spaces.
+	Telemetry telemetry.Recorder
+
 	allowMissingProfile bool
 	cfgPath             string
 	fs                  afero.Fs
@@ -91,6 +111,22 @@ func AllowMissingProfile() Option {
 	}
 }
 
+// WithTokenStore sets the store session tokens and personal access tokens are
+// read from and written to.
+func WithTokenStore(s tokenstore.Store) Option {
+	return func(ctx *Context) {
+		ctx.Tokens = s
+	}
+}
+
+// WithTelemetry sets the Recorder used to record API usage. Events are
+// recorded with the telemetry.DefaultRecorder if none is supplied.
+func WithTelemetry(r telemetry.Recorder) Option {
+	return func(ctx *Context) {
+		ctx.Telemetry = r
+	}
+}
+
 // NewFromFlags constructs a new context from flags.
 func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 	p, err := config.GetDefaultPath()
@@ -101,6 +137,9 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 	c := &Context{
 		fs:      afero.NewOsFs(),
 		cfgPath: p,
+		// The DefaultRecorder is only replaced once flags are parsed, so
+		// it must be looked up when events are recorded.
+		Telemetry: telemetry.Deferred,
 	}
 
 	for _, o := range opts {
@@ -121,6 +160,9 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 
 	c.Cfg = conf
 	c.CfgSrc = src
+	c.Profiles = profile.NewManager(conf, profile.WithPersist(func() error {
+		return src.UpdateConfig(conf)
+	}))
 
 	// If profile identifier is not provided, use the default, or empty if the
 	// default cannot be obtained.
@@ -139,12 +181,35 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 		c.ProfileName = f.Profile
 	}
 
+	if c.Tokens == nil {
+		c.Tokens = tokenstore.Default(
+			filepath.Join(filepath.Dir(c.cfgPath), tokenstore.DefaultFile),
+			tokenstore.WithFS(c.fs),
+		)
+	}
+	// Sessions are kept out of the config file, but profiles written by
+	// older versions of up may still carry one.
+	if c.Profile.Session == "" && c.ProfileName != "" && !c.Profile.IsSpace() {
+		s, err := c.Tokens.Get(tokenstore.SessionKey(c.ProfileName))
+		if err != nil && !tokenstore.IsNotFound(err) {
+			return nil, errors.Wrap(err, errGetSession)
+		}
+		c.Profile.Session = s
+	}
+
 	of, err := c.applyOverrides(f, c.ProfileName)
 	if err != nil {
 		return nil, err
 	}
 
 	c.APIEndpoint = of.APIEndpoint
+	if c.APIEndpoint == nil && c.Profile.APIEndpoint != "" {
+		u, err := url.Parse(c.Profile.APIEndpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtProfileEndpoint, "API", c.ProfileName)
+		}
+		c.APIEndpoint = u
+	}
 	if c.APIEndpoint == nil {
 		u := *of.Domain
 		u.Host = apiSubdomain + u.Host
@@ -152,6 +217,13 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 	}
 
 	c.ProxyEndpoint = of.ProxyEndpoint
+	if c.ProxyEndpoint == nil && c.Profile.ProxyEndpoint != "" {
+		u, err := url.Parse(c.Profile.ProxyEndpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtProfileEndpoint, "proxy", c.ProfileName)
+		}
+		c.ProxyEndpoint = u
+	}
 	if c.ProxyEndpoint == nil {
 		u := *of.Domain
 		u.Host = proxySubdomain + u.Host
@@ -176,26 +248,87 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 
 	c.InsecureSkipTLSVerify = of.InsecureSkipTLSVerify
 
-	c.DebugLevel = of.Debug
-	switch {
-	case of.Debug >= 3:
-		c.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
-			return transport.NewDebuggingRoundTripper(rt, transport.DebugCurlCommand, transport.DebugURLTiming, transport.DebugDetailedTiming, transport.DebugResponseHeaders)
-		}
-	case of.Debug >= 2:
-		c.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
-			return transport.NewDebuggingRoundTripper(rt, transport.DebugJustURL, transport.DebugRequestHeaders, transport.DebugResponseStatus, transport.DebugResponseHeaders)
+	c.HTTP = uphttp.DefaultConfig()
+	c.HTTP.DialTimeout = of.HTTPDialTimeout
+	c.HTTP.TLSHandshakeTimeout = of.HTTPTLSHandshakeTimeout
+	c.HTTP.ResponseHeaderTimeout = of.HTTPResponseHeaderTimeout
+	c.HTTP.MaxIdleConns = of.HTTPMaxIdleConns
+	c.HTTP.ClientCertificate = uphttp.ClientCertificate{
+		CertFile: of.HTTPClientCert,
+		KeyFile:  of.HTTPClientKey,
+	}
+	if !c.HTTP.ClientCertificate.IsZero() {
+		if _, err := c.HTTP.ClientCertificate.Load(); err != nil {
+			return nil, err
 		}
-	case of.Debug >= 1:
+	}
+
+	c.DebugLevel = of.Debug
+	if of.Debug > 0 {
 		c.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
-			return transport.NewDebuggingRoundTripper(rt, transport.DebugURLTiming)
+			return uphttp.NewDebugTransport(rt, uphttp.WithVerbosity(of.Debug))
 		}
-	default:
 	}
 
 	return c, nil
 }
 
+// GetKubeClientConfig returns a *rest.Config for the Space of the current
+// profile, presenting any configured client certificate.
+func (c *Context) GetKubeClientConfig() (*rest.Config, error) {
+	cfg, err := c.Profile.GetKubeClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	kube.SetClientCertificate(cfg, c.HTTP.ClientCertificate)
+	c.WrapKubeConfig(cfg)
+	return cfg, nil
+}
+
+// WrapKubeConfig wraps the transport of the supplied config of a kube client
+// that talks to the Space of the current profile or to one of its control
+// planes. Requests are logged when debugging, recorded by telemetry, and
+// retried once with a refreshed session if they are rejected.
+func (c *Context) WrapKubeConfig(cfg *rest.Config) {
+	if c.WrapTransport != nil {
+		cfg.Wrap(c.WrapTransport)
+	}
+	if w := telemetry.WrapTransport(c.Telemetry); w != nil {
+		cfg.Wrap(w)
+	}
+	if opts := c.TransportOptions(uphttp.BearerToken); len(opts) > 0 {
+		cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			// Kube clients retry requests themselves.
+			return uphttp.NewTransport(append(opts, uphttp.WithBaseTransport(rt), uphttp.WithMaxRetries(0))...)
+		})
+	}
+}
+
+// Capabilities negotiates the capabilities of the Space or Upbound Cloud
+// targeted by the current profile. Results are cached per profile.
+func (c *Context) Capabilities(ctx context.Context) (*capability.Capabilities, error) {
+	var d capability.Discoverer = capability.NewCloud()
+	if c.Profile.IsSpace() {
+		cfg, err := c.GetKubeClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		disc, err := discovery.NewDiscoveryClientForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		kube, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		d = capability.NewSpace(disc, kube)
+	}
+	if c.ProfileName == "" {
+		return d.Discover(ctx)
+	}
+	return capability.NewCache(capability.WithFS(c.fs)).Get(ctx, c.ProfileName, d)
+}
+
 // BuildSDKConfig builds an Upbound SDK config suitable for usage with any
 // service client.
 func (c *Context) BuildSDKConfig() (*up.Config, error) {
@@ -210,19 +343,22 @@ func (c *Context) BuildSDKConfig() (*up.Config, error) {
 		},
 		})
 	}
-	var tr http.RoundTripper = &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: c.InsecureSkipTLSVerify, //nolint:gosec
-		},
-	}
+	var tr http.RoundTripper = c.HTTP.Transport(&tls.Config{
+		InsecureSkipVerify: c.InsecureSkipTLSVerify, //nolint:gosec
+	})
 	if c.WrapTransport != nil {
 		tr = c.WrapTransport(tr)
 	}
+	if w := telemetry.WrapTransport(c.Telemetry); w != nil {
+		tr = w(tr)
+	}
+	tr = uphttp.NewTransport(append(c.TransportOptions(uphttp.CookieToken(CookieName)), uphttp.WithBaseTransport(tr))...)
 	client := up.NewClient(func(u *up.HTTPClient) {
 		u.BaseURL = c.APIEndpoint
 		u.HTTP = &http.Client{
 			Jar:       cj,
 			Transport: tr,
+			Timeout:   c.HTTP.Timeout,
 		}
 		u.UserAgent = UserAgent
 	})
@@ -231,6 +367,62 @@ func (c *Context) BuildSDKConfig() (*up.Config, error) {
 	}), nil
 }
 
+// CredentialHelper returns a Docker credential helper that resolves registry
+// credentials of the named profile, or of the default profile if no name is
+// supplied. Sessions are read from the token store of the Context, since they
+// are no longer written to the config file.
+func (c *Context) CredentialHelper(name string) *credhelper.Helper {
+	opts := []credhelper.Opt{
+		credhelper.WithDomain(c.Domain.Hostname()),
+		credhelper.WithProfile(name),
+	}
+	if c.CfgSrc != nil {
+		opts = append(opts, credhelper.WithSource(c.CfgSrc))
+	}
+	if c.Tokens != nil {
+		opts = append(opts, credhelper.WithTokenStore(c.Tokens))
+	}
+	return credhelper.New(opts...)
+}
+
+// TransportOptions returns options that configure a transport to refresh the
+// session of the current profile when a request is rejected, applying the new
+// session with the supplied ApplyFunc. They must be passed to every client of
+// the Upbound API, Spaces, and control planes. The session can only be
+// refreshed if a refresh token was stored by up login --device; otherwise the
+// rejection is returned unchanged.
+func (c *Context) TransportOptions(apply uphttp.ApplyFunc) []uphttp.TransportOption {
+	if c.Tokens == nil || c.ProfileName == "" {
+		return nil
+	}
+	return []uphttp.TransportOption{
+		uphttp.WithTokenRefresh(c.refreshSession, apply),
+		uphttp.WithTokenStore(c.Tokens, tokenstore.SessionKey(c.ProfileName)),
+	}
+}
+
+// refreshSession exchanges the stored refresh token of the current profile
+// for a new session token.
+func (c *Context) refreshSession(ctx context.Context) (string, error) {
+	rt, err := c.Tokens.Get(tokenstore.RefreshKey(c.ProfileName))
+	if err != nil {
+		return "", err
+	}
+	t, err := auth.NewDeviceFlow(
+		auth.WithDeviceEndpoint(c.APIEndpoint),
+		auth.WithDeviceClient(&http.Client{Transport: c.HTTP.Transport(&tls.Config{
+			InsecureSkipVerify: c.InsecureSkipTLSVerify, //nolint:gosec
+		})}),
+	).Refresh(ctx, rt)
+	if err != nil {
+		return "", err
+	}
+	if err := c.Tokens.Set(tokenstore.RefreshKey(c.ProfileName), t.RefreshToken); err != nil {
+		return "", err
+	}
+	return t.AccessToken, nil
+}
+
 // applyOverrides applies applicable overrides to the given Flags based on the
 // pre-existing configs, if there are any.
 func (c *Context) applyOverrides(f Flags, profileName string) (Flags, error) {
@@ -275,6 +467,12 @@ func (f Flags) MarshalJSON() ([]byte, error) {
 		Account               string `json:"account,omitempty"`
 		InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify,omitempty"`
 		Debug                 int    `json:"debug,omitempty"`
+		DialTimeout           string `json:"http_dial_timeout,omitempty"`
+		TLSHandshakeTimeout   string `json:"http_tls_handshake_timeout,omitempty"`
+		ResponseHeaderTimeout string `json:"http_response_header_timeout,omitempty"`
+		MaxIdleConns          int    `json:"http_max_idle_conns,omitempty"`
+		ClientCert            string `json:"http_client_cert,omitempty"`
+		ClientKey             string `json:"http_client_key,omitempty"`
 		APIEndpoint           string `json:"override_api_endpoint,omitempty"`
 		ProxyEndpoint         string `json:"override_proxy_endpoint,omitempty"`
 		RegistryEndpoint      string `json:"override_registry_endpoint,omitempty"`
@@ -284,6 +482,12 @@ func (f Flags) MarshalJSON() ([]byte, error) {
 		Account:               f.Account,
 		InsecureSkipTLSVerify: f.InsecureSkipTLSVerify,
 		Debug:                 f.Debug,
+		DialTimeout:           nullableDuration(f.HTTPDialTimeout),
+		TLSHandshakeTimeout:   nullableDuration(f.HTTPTLSHandshakeTimeout),
+		ResponseHeaderTimeout: nullableDuration(f.HTTPResponseHeaderTimeout),
+		MaxIdleConns:          f.HTTPMaxIdleConns,
+		ClientCert:            f.HTTPClientCert,
+		ClientKey:             f.HTTPClientKey,
 		APIEndpoint:           nullableURL(f.APIEndpoint),
 		ProxyEndpoint:         nullableURL(f.ProxyEndpoint),
 		RegistryEndpoint:      nullableURL(f.RegistryEndpoint),
@@ -297,3 +501,10 @@ func nullableURL(u *url.URL) string {
 	}
 	return u.String()
 }
+
+func nullableDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
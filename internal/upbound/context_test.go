@@ -15,10 +15,14 @@
 package upbound
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -26,9 +30,13 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/spf13/afero"
+	"k8s.io/client-go/rest"
 
 	"github.com/upbound/up/internal/config"
+	uphttp "github.com/upbound/up/internal/http"
 	"github.com/upbound/up/internal/profile"
+	"github.com/upbound/up/internal/telemetry"
+	"github.com/upbound/up/internal/tokenstore"
 )
 
 var (
@@ -73,6 +81,33 @@ var (
 		}
 	  }
 	`
+	endpointsConfigJSON = `{
+		"upbound": {
+		  "default": "default",
+		  "profiles": {
+			"default": {
+			  "id": "someone@upbound.io",
+			  "type": "user",
+			  "session": "a token",
+			  "api_endpoint": "https://api.internal.example.com",
+			  "proxy_endpoint": "https://proxy.internal.example.com/v1/controlPlanes"
+			}
+		  }
+		}
+	  }
+	`
+	sessionlessConfigJSON = `{
+		"upbound": {
+		  "default": "default",
+		  "profiles": {
+			"default": {
+			  "id": "someone@upbound.io",
+			  "type": "user"
+			}
+		  }
+		}
+	  }
+	`
 )
 
 func withConfig(config string) Option {
@@ -100,6 +135,14 @@ func withPath(p string) Option {
 	}
 }
 
+func withStoredSession(profile, session string) Option {
+	return func(ctx *Context) {
+		s := tokenstore.NewFile("/.up/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+		_ = s.Set(tokenstore.SessionKey(profile), session)
+		ctx.Tokens = s
+	}
+}
+
 func withURL(uri string) *url.URL {
 	u, _ := url.Parse(uri)
 	return u
@@ -138,6 +181,7 @@ func TestNewFromFlags(t *testing.T) {
 					Profile:          profile.Profile{},
 					ProxyEndpoint:    withURL("https://proxy.upbound.io/v1/controlPlanes"),
 					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					HTTP:             uphttp.DefaultConfig(),
 				},
 			},
 		},
@@ -173,6 +217,7 @@ func TestNewFromFlags(t *testing.T) {
 					Profile:          profile.Profile{},
 					ProxyEndpoint:    withURL("https://proxy.upbound.io/v1/controlPlanes"),
 					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					HTTP:             uphttp.DefaultConfig(),
 				},
 			},
 		},
@@ -200,10 +245,64 @@ func TestNewFromFlags(t *testing.T) {
 					},
 					ProxyEndpoint:    withURL("https://proxy.upbound.io/v1/controlPlanes"),
 					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					HTTP:             uphttp.DefaultConfig(),
 					Token:            "",
 				},
 			},
 		},
+		"PreExistingProfileEndpoints": {
+			reason: "We should use the API and proxy endpoints of the selected profile.",
+			args: args{
+				flags: []string{},
+				opts: []Option{
+					withConfig(endpointsConfigJSON),
+					withPath("/.up/config.json"),
+				},
+			},
+			want: want{
+				c: &Context{
+					ProfileName: "default",
+					APIEndpoint: withURL("https://api.internal.example.com"),
+					Domain:      withURL("https://upbound.io"),
+					Profile: profile.Profile{
+						ID:            "someone@upbound.io",
+						Type:          profile.User,
+						Session:       "a token",
+						APIEndpoint:   "https://api.internal.example.com",
+						ProxyEndpoint: "https://proxy.internal.example.com/v1/controlPlanes",
+					},
+					ProxyEndpoint:    withURL("https://proxy.internal.example.com/v1/controlPlanes"),
+					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					HTTP:             uphttp.DefaultConfig(),
+				},
+			},
+		},
+		"SessionFromTokenStore": {
+			reason: "We should read the session of the selected profile from the token store.",
+			args: args{
+				flags: []string{},
+				opts: []Option{
+					withConfig(sessionlessConfigJSON),
+					withPath("/.up/config.json"),
+					withStoredSession("default", "a stored token"),
+				},
+			},
+			want: want{
+				c: &Context{
+					ProfileName: "default",
+					APIEndpoint: withURL("https://api.upbound.io"),
+					Domain:      withURL("https://upbound.io"),
+					Profile: profile.Profile{
+						ID:      "someone@upbound.io",
+						Type:    profile.User,
+						Session: "a stored token",
+					},
+					ProxyEndpoint:    withURL("https://proxy.upbound.io/v1/controlPlanes"),
+					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					HTTP:             uphttp.DefaultConfig(),
+				},
+			},
+		},
 		"PreExistingProfileBaseConfigSetProfile": {
 			reason: "We should return a Context that includes the persisted Profile from base config",
 			args: args{
@@ -233,6 +332,7 @@ func TestNewFromFlags(t *testing.T) {
 					},
 					ProxyEndpoint:    withURL("https://proxy.local.upbound.io/v1/controlPlanes"),
 					RegistryEndpoint: withURL("https://xpkg.local.upbound.io"),
+					HTTP:             uphttp.DefaultConfig(),
 					Token:            "",
 				},
 			},
@@ -271,6 +371,7 @@ func TestNewFromFlags(t *testing.T) {
 					},
 					ProxyEndpoint:    withURL("http://proxy.a.domain.org/v1/controlPlanes"),
 					RegistryEndpoint: withURL("http://xpkg.a.domain.org"),
+					HTTP:             uphttp.DefaultConfig(),
 					Token:            "",
 				},
 			},
@@ -292,11 +393,40 @@ func TestNewFromFlags(t *testing.T) {
 					Profile:          profile.Profile{},
 					ProxyEndpoint:    withURL("https://proxy.upbound.io/v1/controlPlanes"),
 					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					HTTP:             uphttp.DefaultConfig(),
 					DebugLevel:       3,
 				},
 				wrapTransport: true,
 			},
 		},
+		"HTTPFlags": {
+			reason: "We should tune HTTP timeouts and connection pooling using the supplied flags.",
+			args: args{
+				flags: []string{"--http-dial-timeout=5s", "--http-tls-handshake-timeout=3s", "--http-response-header-timeout=0s", "--http-max-idle-conns=5"},
+				opts: []Option{
+					withFS(afero.NewMemMapFs()),
+				},
+			},
+			want: want{
+				c: &Context{
+					Account:          "",
+					APIEndpoint:      withURL("https://api.upbound.io"),
+					Cfg:              &config.Config{},
+					Domain:           withURL("https://upbound.io"),
+					Profile:          profile.Profile{},
+					ProxyEndpoint:    withURL("https://proxy.upbound.io/v1/controlPlanes"),
+					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					HTTP: uphttp.Config{
+						DialTimeout:         5 * time.Second,
+						KeepAlive:           30 * time.Second,
+						TLSHandshakeTimeout: 3 * time.Second,
+						IdleConnTimeout:     90 * time.Second,
+						MaxIdleConns:        5,
+						MaxIdleConnsPerHost: 10,
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -327,12 +457,173 @@ func TestNewFromFlags(t *testing.T) {
 				// NOTE(tnthornton) we're not concerned about the Cfg's
 				// internal components.
 				cmpopts.IgnoreFields(Context{}, "Cfg"),
+				// The profile manager wraps Cfg.
+				cmpopts.IgnoreFields(Context{}, "Profiles"),
+				// Token stores may be backed by the OS keychain.
+				cmpopts.IgnoreFields(Context{}, "Tokens"),
 				// NOTE(sttts) we compare check it before
 				// a function pointer we cannot compare
 				cmpopts.IgnoreFields(Context{}, "WrapTransport"),
+				// Telemetry defaults to the process-wide recorder.
+				cmpopts.IgnoreFields(Context{}, "Telemetry"),
 			); diff != "" {
 				t.Errorf("\n%s\nNewFromFlags(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
 		})
 	}
 }
+
+func TestSessionRefresh(t *testing.T) {
+	// The API rejects the stale session until it is refreshed using the
+	// stored refresh token.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/oauth/token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("refresh_token") != "cool-refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"fresh-session","refresh_token":"next-refresh"}`))
+		case "/v1/self":
+			if c, err := r.Cookie(CookieName); err != nil || c.Value != "fresh-session" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		case "/version":
+			if r.Header.Get("Authorization") != "Bearer fresh-session" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	newContext := func(t *testing.T) *Context {
+		t.Helper()
+		tokens := tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))
+		if err := tokens.Set(tokenstore.RefreshKey("default"), "cool-refresh"); err != nil {
+			t.Fatal(err)
+		}
+		return &Context{
+			ProfileName: "default",
+			Profile:     profile.Profile{Type: profile.User, Session: "stale-session"},
+			APIEndpoint: u,
+			Tokens:      tokens,
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		do     func(c *Context) error
+	}{
+		"UpboundAPI": {
+			reason: "A request to the Upbound API rejected with 401 should be retried with a refreshed session.",
+			do: func(c *Context) error {
+				conf, err := c.BuildSDKConfig()
+				if err != nil {
+					return err
+				}
+				req, err := conf.Client.NewRequest(context.Background(), http.MethodGet, "v1/self", "", nil)
+				if err != nil {
+					return err
+				}
+				return conf.Client.Do(req, &struct{}{})
+			},
+		},
+		"KubeAPI": {
+			reason: "A request of a kube client rejected with 401 should be retried with a refreshed session.",
+			do: func(c *Context) error {
+				cfg := &rest.Config{Host: srv.URL, BearerToken: "stale-session"}
+				c.WrapKubeConfig(cfg)
+				hc, err := rest.HTTPClientFor(cfg)
+				if err != nil {
+					return err
+				}
+				rsp, err := hc.Get(srv.URL + "/version")
+				if err != nil {
+					return err
+				}
+				defer rsp.Body.Close() //nolint:errcheck
+				if rsp.StatusCode != http.StatusOK {
+					return errors.Errorf("unexpected status %d", rsp.StatusCode)
+				}
+				return nil
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newContext(t)
+			if err := tc.do(c); err != nil {
+				t.Fatalf("\n%s\nrequest: %s", tc.reason, err)
+			}
+			for key, want := range map[string]string{
+				tokenstore.SessionKey("default"): "fresh-session",
+				tokenstore.RefreshKey("default"): "next-refresh",
+			} {
+				got, err := c.Tokens.Get(key)
+				if err != nil {
+					t.Fatalf("\n%s\nGet(%q): %s", tc.reason, key, err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("\n%s\nGet(%q): -want, +got:\n%s", tc.reason, key, diff)
+				}
+			}
+		})
+	}
+}
+
+type recorder struct {
+	events []telemetry.Event
+}
+
+func (r *recorder) Record(e telemetry.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestTelemetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	flags := Flags{}
+	parser, _ := kong.New(&flags)
+	if _, err := parser.Parse([]string{"--override-api-endpoint=" + srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewFromFlags(flags,
+		withFS(afero.NewMemMapFs()),
+		WithTokenStore(tokenstore.NewFile("/tokens.json", tokenstore.WithFS(afero.NewMemMapFs()))),
+	)
+	if err != nil {
+		t.Fatalf("NewFromFlags(...): %s", err)
+	}
+
+	// The recorder is only set up once flags are parsed, i.e. after the
+	// Context was constructed.
+	r := &recorder{}
+	telemetry.DefaultRecorder = r
+	defer func() { telemetry.DefaultRecorder = telemetry.Nop{} }()
+
+	conf, err := c.BuildSDKConfig()
+	if err != nil {
+		t.Fatalf("BuildSDKConfig(): %s", err)
+	}
+	req, err := conf.Client.NewRequest(context.Background(), http.MethodGet, "v1/self", "", nil)
+	if err != nil {
+		t.Fatalf("NewRequest(...): %s", err)
+	}
+	_ = conf.Client.Do(req, nil)
+
+	want := []telemetry.Event{{Type: telemetry.TypeAPI, Name: http.MethodGet, Category: telemetry.CategoryNotFound}}
+	if diff := cmp.Diff(want, r.events, cmpopts.IgnoreFields(telemetry.Event{}, "Duration", "Timestamp")); diff != "" {
+		t.Errorf("Record(...): -want, +got:\n%s", diff)
+	}
+}
@@ -16,6 +16,7 @@ package upbound
 
 import (
 	"net/url"
+	"time"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -32,6 +33,14 @@ type Flags struct {
 	InsecureSkipTLSVerify bool `env:"UP_INSECURE_SKIP_TLS_VERIFY" help:"[INSECURE] Skip verifying TLS certificates." json:"insecureSkipTLSVerify,omitempty"`
 	Debug                 int  `short:"d" env:"UP_DEBUG" name:"debug" type:"counter" help:"[INSECURE] Run with debug logging. Repeat to increase verbosity. Output might contain confidential data like tokens." json:"debug,omitempty"`
 
+	// HTTP
+	HTTPDialTimeout           time.Duration `env:"UP_HTTP_DIAL_TIMEOUT" name:"http-dial-timeout" default:"30s" help:"Maximum time to wait to establish a connection to an API." json:"httpDialTimeout,omitempty"`
+	HTTPTLSHandshakeTimeout   time.Duration `env:"UP_HTTP_TLS_HANDSHAKE_TIMEOUT" name:"http-tls-handshake-timeout" default:"10s" help:"Maximum time to wait for a TLS handshake with an API." json:"httpTLSHandshakeTimeout,omitempty"`
+	HTTPResponseHeaderTimeout time.Duration `env:"UP_HTTP_RESPONSE_HEADER_TIMEOUT" name:"http-response-header-timeout" default:"60s" help:"Maximum time to wait for an API to respond to a request. Zero means no timeout." json:"httpResponseHeaderTimeout,omitempty"`
+	HTTPClientCert            string        `env:"UP_HTTP_CLIENT_CERT" name:"http-client-cert" type:"path" help:"Path to a PEM encoded client certificate presented to APIs that require mutual TLS." json:"httpClientCert,omitempty"`
+	HTTPClientKey             string        `env:"UP_HTTP_CLIENT_KEY" name:"http-client-key" type:"path" help:"Path to the PEM encoded private key of the client certificate." json:"httpClientKey,omitempty"`
+	HTTPMaxIdleConns          int           `env:"UP_HTTP_MAX_IDLE_CONNS" name:"http-max-idle-conns" default:"100" help:"Maximum number of idle connections to keep open to APIs." json:"httpMaxIdleConns,omitempty"`
+
 	// Hidden
 	APIEndpoint      *url.URL `env:"OVERRIDE_API_ENDPOINT" hidden:"" name:"override-api-endpoint" help:"Overrides the default API endpoint." json:"apiEndpoint,omitempty"`
 	ProxyEndpoint    *url.URL `env:"OVERRIDE_PROXY_ENDPOINT" hidden:"" name:"override-proxy-endpoint" help:"Overrides the default proxy endpoint." json:"proxyEndpoint,omitempty"`
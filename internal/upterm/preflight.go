@@ -0,0 +1,37 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upterm
+
+import (
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/install/preflight"
+)
+
+// PrintPreflightReport prints each result of the supplied pre-flight report
+// styled by its status.
+func PrintPreflightReport(r *preflight.Report) {
+	for _, res := range r.Results {
+		p := pterm.Success
+		switch res.Status {
+		case preflight.StatusWarn:
+			p = pterm.Warning
+		case preflight.StatusFail:
+			p = pterm.Error
+		case preflight.StatusPass:
+		}
+		p.Printfln("%s: %s", res.Name, res.Message)
+	}
+}
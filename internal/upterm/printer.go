@@ -17,11 +17,13 @@ package upterm
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
 
 	"github.com/pterm/pterm"
 
 	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/printer"
 
 	"gopkg.in/yaml.v3"
 )
@@ -76,6 +78,9 @@ func (p *ObjectPrinter) Print(obj any, fieldNames []string, extractFields func(a
 	case config.YAML:
 		return printYAML(obj)
 	default:
+		if printer.IsTemplate(p.Format) {
+			return printer.PrintTemplate(os.Stdout, p.Format, obj)
+		}
 		return p.printDefault(obj, fieldNames, extractFields)
 	}
 }
@@ -42,7 +42,7 @@ type MaxResourceCountPerGVKPerMXP struct {
 
 // Add adds a usage event to the aggregate.
 func (ag *MaxResourceCountPerGVKPerMXP) Add(e model.MXPGVKEvent) error {
-	if err := ag.validateEvent(e); err != nil {
+	if err := validateEvent(e); err != nil {
 		return err
 	}
 
@@ -83,7 +83,9 @@ func (ag *MaxResourceCountPerGVKPerMXP) UpboundEvents() []model.MXPGVKEvent {
 	return events
 }
 
-func (ag *MaxResourceCountPerGVKPerMXP) validateEvent(e model.MXPGVKEvent) error {
+// validateEvent returns an error if e is not a managed resource count event
+// with a complete set of MXP and GVK tags.
+func validateEvent(e model.MXPGVKEvent) error {
 	if e.Name != mrCountUpboundEventName {
 		return fmt.Errorf("expected event name %s, got %s", mrCountUpboundEventName, e.Name)
 	}
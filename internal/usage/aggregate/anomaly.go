@@ -0,0 +1,169 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultWindow is the number of trailing hours an hour is compared to.
+	DefaultWindow = 24
+	// DefaultJump is the relative increase over the trailing average above
+	// which an hour is flagged.
+	DefaultJump = 0.5
+	// DefaultDrop is the relative decrease below the trailing average above
+	// which an hour is flagged.
+	DefaultDrop = 0.5
+	// DefaultMinBaseline is the trailing average below which hours are not
+	// flagged, since small counts fluctuate by large ratios.
+	DefaultMinBaseline = 10
+)
+
+// AnomalyType is the type of an anomaly.
+type AnomalyType string
+
+// Types of anomalies.
+const (
+	// AnomalyJump indicates managed resource counts rose sharply, e.g.
+	// because of a runaway composition.
+	AnomalyJump AnomalyType = "Jump"
+	// AnomalyDrop indicates managed resource counts fell sharply, e.g.
+	// because an exporter stopped reporting.
+	AnomalyDrop AnomalyType = "Drop"
+)
+
+// A Finding is an hour in which the managed resource count of a control
+// plane deviated from its trailing average beyond a threshold.
+type Finding struct {
+	MXPID string      `json:"mxp_id"`
+	Hour  time.Time   `json:"hour"`
+	Type  AnomalyType `json:"type"`
+	// Resources is the number of managed resources during the hour.
+	Resources int `json:"resources"`
+	// TrailingAverage is the mean number of managed resources during the
+	// preceding hours of the window.
+	TrailingAverage float64 `json:"trailing_average"`
+	// Change is the change relative to the trailing average, e.g. 1.5 for an
+	// increase of 150% or -1 if no resources were observed.
+	Change float64 `json:"change"`
+}
+
+// String returns a one line description of the finding.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s: %s to %d managed resources (%+.0f%% versus trailing average of %.1f)",
+		f.MXPID, f.Hour.Format(time.RFC3339), f.Type, f.Resources, f.Change*100, f.TrailingAverage)
+}
+
+// A Detector flags hours in which managed resource counts jump or drop
+// beyond thresholds relative to their trailing average. Zero fields use
+// their defaults.
+type Detector struct {
+	// Window is the number of trailing hours each hour is compared to.
+	Window int
+	// Jump is the relative increase, e.g. 0.5 for 50%, above which an hour
+	// is flagged.
+	Jump float64
+	// Drop is the relative decrease, e.g. 0.5 for 50%, above which an hour
+	// is flagged.
+	Drop float64
+	// MinBaseline is the trailing average below which hours are not
+	// flagged.
+	MinBaseline float64
+}
+
+// Detect returns the anomalies in the supplied report, ordered by hour and
+// MXP ID. Hours missing between the first and last observed hour of a
+// control plane count as zero managed resources, since they usually indicate
+// a broken exporter.
+func (d Detector) Detect(r Report) []Finding {
+	d = d.withDefaults()
+	findings := []Finding{}
+	for _, cp := range r.ControlPlanes {
+		findings = append(findings, d.detect(cp)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if !findings[i].Hour.Equal(findings[j].Hour) {
+			return findings[i].Hour.Before(findings[j].Hour)
+		}
+		return findings[i].MXPID < findings[j].MXPID
+	})
+	return findings
+}
+
+func (d Detector) withDefaults() Detector {
+	if d.Window <= 0 {
+		d.Window = DefaultWindow
+	}
+	if d.Jump <= 0 {
+		d.Jump = DefaultJump
+	}
+	if d.Drop <= 0 {
+		d.Drop = DefaultDrop
+	}
+	if d.MinBaseline <= 0 {
+		d.MinBaseline = DefaultMinBaseline
+	}
+	return d
+}
+
+func (d Detector) detect(cp ControlPlaneTotals) []Finding {
+	hours := contiguous(cp.Hours)
+	findings := []Finding{}
+	for i := 1; i < len(hours); i++ {
+		start := i - d.Window
+		if start < 0 {
+			start = 0
+		}
+		sum := 0
+		for _, h := range hours[start:i] {
+			sum += h.Resources
+		}
+		avg := float64(sum) / float64(i-start)
+		if avg < d.MinBaseline {
+			continue
+		}
+		change := (float64(hours[i].Resources) - avg) / avg
+		f := Finding{MXPID: cp.MXPID, Hour: hours[i].Hour, Resources: hours[i].Resources, TrailingAverage: avg, Change: change}
+		switch {
+		case change > d.Jump:
+			f.Type = AnomalyJump
+		case -change > d.Drop:
+			f.Type = AnomalyDrop
+		default:
+			continue
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// contiguous returns the supplied hours, which must be ordered, with any
+// missing hours filled in with zero resources.
+func contiguous(hours []HourTotals) []HourTotals {
+	if len(hours) == 0 {
+		return nil
+	}
+	out := []HourTotals{hours[0]}
+	for _, h := range hours[1:] {
+		for next := out[len(out)-1].Hour.Add(time.Hour); next.Before(h.Hour); next = next.Add(time.Hour) {
+			out = append(out, HourTotals{Hour: next})
+		}
+		out = append(out, h)
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetect(t *testing.T) {
+	h := func(i int) time.Time { return time.Date(2006, 5, 4, i, 0, 0, 0, time.UTC) }
+	series := func(mxp string, counts map[int]int) ControlPlaneTotals {
+		cp := ControlPlaneTotals{MXPID: mxp}
+		for i := 0; i < 24; i++ {
+			if c, ok := counts[i]; ok {
+				cp.Hours = append(cp.Hours, HourTotals{Hour: h(i), Resources: c})
+			}
+		}
+		return cp
+	}
+
+	cases := map[string]struct {
+		reason   string
+		detector Detector
+		report   Report
+		want     []Finding
+	}{
+		"Empty": {
+			reason: "A report without control planes has no findings.",
+			want:   []Finding{},
+		},
+		"JumpAndGap": {
+			reason: "Jumps and missing hours beyond the default thresholds should be flagged.",
+			report: Report{ControlPlanes: []ControlPlaneTotals{
+				series("mxp1", map[int]int{0: 20, 1: 20, 2: 20, 3: 50, 4: 20, 6: 20}),
+			}},
+			want: []Finding{
+				{MXPID: "mxp1", Hour: h(3), Type: AnomalyJump, Resources: 50, TrailingAverage: 20, Change: 1.5},
+				{MXPID: "mxp1", Hour: h(5), Type: AnomalyDrop, Resources: 0, TrailingAverage: 26, Change: -1},
+			},
+		},
+		"SmallBaseline": {
+			reason: "Hours with a trailing average below the minimum baseline should not be flagged.",
+			report: Report{ControlPlanes: []ControlPlaneTotals{
+				series("mxp1", map[int]int{0: 1, 1: 5, 2: 1}),
+			}},
+			want: []Finding{},
+		},
+		"Thresholds": {
+			reason:   "Custom thresholds and windows should be respected, and findings ordered by hour and MXP ID.",
+			detector: Detector{Window: 1, Jump: 0.1, Drop: 0.1, MinBaseline: 1},
+			report: Report{ControlPlanes: []ControlPlaneTotals{
+				series("mxp2", map[int]int{0: 10, 1: 12}),
+				series("mxp1", map[int]int{0: 10, 1: 8, 2: 8}),
+			}},
+			want: []Finding{
+				{MXPID: "mxp1", Hour: h(1), Type: AnomalyDrop, Resources: 8, TrailingAverage: 10, Change: -0.2},
+				{MXPID: "mxp2", Hour: h(1), Type: AnomalyJump, Resources: 12, TrailingAverage: 10, Change: 0.2},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.detector.Detect(tc.report)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDetect(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
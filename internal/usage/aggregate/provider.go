@@ -0,0 +1,42 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"strings"
+)
+
+// ProviderForGroup returns the name of the provider that most likely serves
+// the supplied API group of managed resources, following the naming
+// conventions of Upbound and Crossplane community providers:
+//
+//   - s3.aws.upbound.io is served by provider-aws-s3.
+//   - aws.upbound.io is served by provider-family-aws.
+//   - ec2.aws.crossplane.io is served by provider-aws.
+//
+// Other API groups are returned unchanged.
+func ProviderForGroup(group string) string {
+	labels := strings.Split(group, ".")
+	n := len(labels)
+	switch {
+	case n >= 4 && strings.HasSuffix(group, ".upbound.io"):
+		return "provider-" + labels[n-3] + "-" + labels[n-4]
+	case n == 3 && strings.HasSuffix(group, ".upbound.io"):
+		return "provider-family-" + labels[0]
+	case n >= 3 && strings.HasSuffix(group, ".crossplane.io"):
+		return "provider-" + labels[n-3]
+	}
+	return group
+}
@@ -0,0 +1,310 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+// Report summarizes managed resource usage per control plane.
+type Report struct {
+	ControlPlanes []ControlPlaneTotals `json:"control_planes"`
+	// Providers summarizes managed resources per provider across all control
+	// planes, ordered by provider.
+	Providers []ProviderTotals `json:"providers"`
+}
+
+// ControlPlaneTotals summarizes managed resource usage for a control plane.
+type ControlPlaneTotals struct {
+	MXPID string `json:"mxp_id"`
+	// MaxResources is the largest number of managed resources observed on the
+	// control plane in a single hour.
+	MaxResources int `json:"max_resources"`
+	// AverageResources is the mean number of managed resources across the
+	// hours in which the control plane was observed.
+	AverageResources float64 `json:"average_resources"`
+	// PeakHour is the first hour in which MaxResources was observed.
+	PeakHour time.Time `json:"peak_hour"`
+	// Hours is the number of managed resources per hour, ordered by hour.
+	Hours []HourTotals `json:"hours"`
+	// Kinds summarizes managed resources per kind, ordered by GVK.
+	Kinds []KindTotals `json:"kinds"`
+	// Providers summarizes managed resources per provider, ordered by
+	// provider.
+	Providers []ProviderTotals `json:"providers"`
+}
+
+// HourTotals records the number of managed resources on a control plane
+// during an hour.
+type HourTotals struct {
+	Hour      time.Time `json:"hour"`
+	Resources int       `json:"resources"`
+}
+
+// KindTotals summarizes managed resource usage for a GVK on a control plane.
+type KindTotals struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// MaxResources is the largest number of managed resources of the kind
+	// observed in a single hour.
+	MaxResources int `json:"max_resources"`
+	// AverageResources is the mean number of managed resources of the kind
+	// across the hours in which the control plane was observed. Hours in which
+	// the kind was not observed count as zero.
+	AverageResources float64 `json:"average_resources"`
+	// PeakHour is the first hour in which MaxResources was observed.
+	PeakHour time.Time `json:"peak_hour"`
+}
+
+// ProviderTotals summarizes managed resource usage for a provider.
+type ProviderTotals struct {
+	Provider string `json:"provider"`
+	// MaxResources is the largest number of managed resources of the
+	// provider observed in a single hour.
+	MaxResources int `json:"max_resources"`
+	// AverageResources is the mean number of managed resources of the
+	// provider across the hours in which any control plane was observed.
+	AverageResources float64 `json:"average_resources"`
+	// PeakHour is the first hour in which MaxResources was observed.
+	PeakHour time.Time `json:"peak_hour"`
+	// Groups summarizes managed resources per API group of the provider,
+	// ordered by group.
+	Groups []GroupTotals `json:"groups"`
+}
+
+// GroupTotals summarizes managed resource usage for an API group.
+type GroupTotals struct {
+	Group            string    `json:"group"`
+	MaxResources     int       `json:"max_resources"`
+	AverageResources float64   `json:"average_resources"`
+	PeakHour         time.Time `json:"peak_hour"`
+}
+
+type gvk struct {
+	Group   string
+	Version string
+	Kind    string
+}
+
+// Rollup aggregates raw managed resource count events into per-control-plane,
+// per-hour and per-kind totals. Events are bucketed by the UTC hour of their
+// timestamp and the largest count of a GVK within an hour is used as the
+// count for that hour.
+type Rollup struct {
+	// Provider returns the provider serving an API group. Defaults to
+	// ProviderForGroup.
+	Provider func(group string) string
+
+	// counts maps MXP ID to hour to GVK to the largest observed count.
+	counts map[string]map[time.Time]map[gvk]int
+}
+
+// Add adds a usage event to the rollup.
+func (r *Rollup) Add(e model.MXPGVKEvent) error {
+	if err := validateEvent(e); err != nil {
+		return err
+	}
+	if r.counts == nil {
+		r.counts = make(map[string]map[time.Time]map[gvk]int)
+	}
+	hours, ok := r.counts[e.Tags.MXPID]
+	if !ok {
+		hours = make(map[time.Time]map[gvk]int)
+		r.counts[e.Tags.MXPID] = hours
+	}
+	hour := e.Timestamp.UTC().Truncate(time.Hour)
+	kinds, ok := hours[hour]
+	if !ok {
+		kinds = make(map[gvk]int)
+		hours[hour] = kinds
+	}
+	key := gvk{Group: e.Tags.Group, Version: e.Tags.Version, Kind: e.Tags.Kind}
+	if value := int(e.Value); value > kinds[key] {
+		kinds[key] = value
+	}
+	return nil
+}
+
+// ReadFrom adds every event read from er to the rollup. Reads until er returns
+// EOF. The caller is responsible for closing er.
+func (r *Rollup) ReadFrom(ctx context.Context, er event.Reader) error {
+	for {
+		e, err := er.Read(ctx)
+		if errors.Is(err, event.ErrEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.Add(e); err != nil {
+			return err
+		}
+	}
+}
+
+// Report returns the totals for every control plane in the rollup, ordered by
+// MXP ID.
+func (r *Rollup) Report() Report {
+	provider := r.Provider
+	if provider == nil {
+		provider = ProviderForGroup
+	}
+	report := Report{ControlPlanes: []ControlPlaneTotals{}}
+	all := map[time.Time]map[gvk]int{}
+	for mxpID, hours := range r.counts {
+		report.ControlPlanes = append(report.ControlPlanes, controlPlaneTotals(mxpID, hours, provider))
+		for hour, kinds := range hours {
+			if all[hour] == nil {
+				all[hour] = map[gvk]int{}
+			}
+			for key, count := range kinds {
+				all[hour][key] += count
+			}
+		}
+	}
+	sort.Slice(report.ControlPlanes, func(i, j int) bool {
+		return report.ControlPlanes[i].MXPID < report.ControlPlanes[j].MXPID
+	})
+	report.Providers = providerTotals(sortedHours(all), all, provider)
+	return report
+}
+
+func sortedHours(hours map[time.Time]map[gvk]int) []time.Time {
+	ordered := make([]time.Time, 0, len(hours))
+	for hour := range hours {
+		ordered = append(ordered, hour)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Before(ordered[j]) })
+	return ordered
+}
+
+// providerTotals sums the counts of kinds per provider and API group within
+// each hour, and summarizes them across the supplied hours.
+func providerTotals(ordered []time.Time, hours map[time.Time]map[gvk]int, provider func(string) string) []ProviderTotals {
+	byProvider := map[string]map[time.Time]int{}
+	byGroup := map[string]map[time.Time]int{}
+	groups := map[string]map[string]bool{}
+	for hour, kinds := range hours {
+		for key, count := range kinds {
+			p := provider(key.Group)
+			if byProvider[p] == nil {
+				byProvider[p] = map[time.Time]int{}
+				groups[p] = map[string]bool{}
+			}
+			if byGroup[key.Group] == nil {
+				byGroup[key.Group] = map[time.Time]int{}
+			}
+			byProvider[p][hour] += count
+			byGroup[key.Group][hour] += count
+			groups[p][key.Group] = true
+		}
+	}
+
+	out := []ProviderTotals{}
+	for p, counts := range byProvider {
+		pt := ProviderTotals{Provider: p, Groups: []GroupTotals{}}
+		pt.MaxResources, pt.AverageResources, pt.PeakHour = summarize(ordered, counts)
+		for g := range groups[p] {
+			gt := GroupTotals{Group: g}
+			gt.MaxResources, gt.AverageResources, gt.PeakHour = summarize(ordered, byGroup[g])
+			pt.Groups = append(pt.Groups, gt)
+		}
+		sort.Slice(pt.Groups, func(i, j int) bool { return pt.Groups[i].Group < pt.Groups[j].Group })
+		out = append(out, pt)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// summarize returns the largest count, the first hour it was observed in,
+// and the mean count across the supplied hours. Hours without a count count
+// as zero.
+func summarize(ordered []time.Time, counts map[time.Time]int) (max int, avg float64, peak time.Time) {
+	sum := 0
+	for _, hour := range ordered {
+		count, ok := counts[hour]
+		if !ok {
+			continue
+		}
+		sum += count
+		if count > max || peak.IsZero() {
+			max, peak = count, hour
+		}
+	}
+	if len(ordered) > 0 {
+		avg = float64(sum) / float64(len(ordered))
+	}
+	return max, avg, peak
+}
+
+func controlPlaneTotals(mxpID string, hours map[time.Time]map[gvk]int, provider func(string) string) ControlPlaneTotals {
+	cp := ControlPlaneTotals{MXPID: mxpID, Hours: []HourTotals{}, Kinds: []KindTotals{}}
+
+	ordered := sortedHours(hours)
+
+	kinds := map[gvk]*KindTotals{}
+	sums := map[gvk]int{}
+	total := 0
+	for _, hour := range ordered {
+		resources := 0
+		for key, count := range hours[hour] {
+			resources += count
+			sums[key] += count
+			kt, ok := kinds[key]
+			if !ok {
+				kt = &KindTotals{Group: key.Group, Version: key.Version, Kind: key.Kind}
+				kinds[key] = kt
+			}
+			if count > kt.MaxResources || kt.PeakHour.IsZero() {
+				kt.MaxResources = count
+				kt.PeakHour = hour
+			}
+		}
+		cp.Hours = append(cp.Hours, HourTotals{Hour: hour, Resources: resources})
+		if resources > cp.MaxResources || cp.PeakHour.IsZero() {
+			cp.MaxResources = resources
+			cp.PeakHour = hour
+		}
+		total += resources
+	}
+	if len(ordered) > 0 {
+		cp.AverageResources = float64(total) / float64(len(ordered))
+	}
+
+	for key, kt := range kinds {
+		kt.AverageResources = float64(sums[key]) / float64(len(ordered))
+		cp.Kinds = append(cp.Kinds, *kt)
+	}
+	sort.Slice(cp.Kinds, func(i, j int) bool {
+		a, b := cp.Kinds[i], cp.Kinds[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Kind < b.Kind
+	})
+	cp.Providers = providerTotals(ordered, hours, provider)
+	return cp
+}
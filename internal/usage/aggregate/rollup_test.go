@@ -0,0 +1,246 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
+)
+
+func TestRollup(t *testing.T) {
+	h1 := time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC)
+	h2 := time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC)
+	gev := func(mxp, group, kind string, value float64, ts time.Time) usagetesting.ReadResult {
+		return usagetesting.ReadResult{Event: model.MXPGVKEvent{
+			Name:      "kube_managedresource_uid",
+			Value:     value,
+			Timestamp: ts,
+			Tags: model.MXPGVKEventTags{
+				MXPID:   mxp,
+				Group:   group,
+				Version: "v1",
+				Kind:    kind,
+			},
+		}}
+	}
+	ev := func(mxp, kind string, value float64, ts time.Time) usagetesting.ReadResult {
+		return gev(mxp, "example.com", kind, value, ts)
+	}
+
+	type want struct {
+		report Report
+		err    error
+	}
+	cases := map[string]struct {
+		reason   string
+		reader   *usagetesting.MockReader
+		provider func(string) string
+		want     want
+	}{
+		"Empty": {
+			reason: "A rollup with no events has no control planes.",
+			reader: &usagetesting.MockReader{},
+			want:   want{report: Report{ControlPlanes: []ControlPlaneTotals{}, Providers: []ProviderTotals{}}},
+		},
+		"InvalidEvent": {
+			reason: "Invalid events return an error.",
+			reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{
+				{Event: model.MXPGVKEvent{Name: "unexpected_name"}},
+			}},
+			want: want{
+				report: Report{ControlPlanes: []ControlPlaneTotals{}, Providers: []ProviderTotals{}},
+				err:    errors.New("expected event name kube_managedresource_uid, got unexpected_name"),
+			},
+		},
+		"Populated": {
+			reason: "Events are rolled up per control plane, hour and kind.",
+			reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{
+				ev("mxp1", "Thing", 2, h1),
+				ev("mxp1", "Thing", 4, h1.Add(30*time.Minute)),
+				ev("mxp1", "Other", 1, h1),
+				ev("mxp1", "Thing", 3, h2),
+				ev("mxp2", "Thing", 7, h2),
+			}},
+			want: want{report: Report{ControlPlanes: []ControlPlaneTotals{
+				{
+					MXPID:            "mxp1",
+					MaxResources:     5,
+					AverageResources: 4,
+					PeakHour:         h1,
+					Hours: []HourTotals{
+						{Hour: h1, Resources: 5},
+						{Hour: h2, Resources: 3},
+					},
+					Kinds: []KindTotals{
+						{Group: "example.com", Version: "v1", Kind: "Other", MaxResources: 1, AverageResources: 0.5, PeakHour: h1},
+						{Group: "example.com", Version: "v1", Kind: "Thing", MaxResources: 4, AverageResources: 3.5, PeakHour: h1},
+					},
+					Providers: []ProviderTotals{
+						{Provider: "example.com", MaxResources: 5, AverageResources: 4, PeakHour: h1, Groups: []GroupTotals{
+							{Group: "example.com", MaxResources: 5, AverageResources: 4, PeakHour: h1},
+						}},
+					},
+				},
+				{
+					MXPID:            "mxp2",
+					MaxResources:     7,
+					AverageResources: 7,
+					PeakHour:         h2,
+					Hours:            []HourTotals{{Hour: h2, Resources: 7}},
+					Kinds: []KindTotals{
+						{Group: "example.com", Version: "v1", Kind: "Thing", MaxResources: 7, AverageResources: 7, PeakHour: h2},
+					},
+					Providers: []ProviderTotals{
+						{Provider: "example.com", MaxResources: 7, AverageResources: 7, PeakHour: h2, Groups: []GroupTotals{
+							{Group: "example.com", MaxResources: 7, AverageResources: 7, PeakHour: h2},
+						}},
+					},
+				},
+			}, Providers: []ProviderTotals{
+				{Provider: "example.com", MaxResources: 10, AverageResources: 7.5, PeakHour: h2, Groups: []GroupTotals{
+					{Group: "example.com", MaxResources: 10, AverageResources: 7.5, PeakHour: h2},
+				}},
+			}}},
+		},
+		"Providers": {
+			reason: "Kinds are grouped by the provider serving their API group.",
+			reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{
+				gev("mxp1", "s3.aws.upbound.io", "Bucket", 2, h1),
+				gev("mxp1", "ec2.aws.upbound.io", "Instance", 3, h1),
+				gev("mxp1", "s3.aws.upbound.io", "Bucket", 4, h2),
+			}},
+			want: want{report: Report{ControlPlanes: []ControlPlaneTotals{
+				{
+					MXPID:            "mxp1",
+					MaxResources:     5,
+					AverageResources: 4.5,
+					PeakHour:         h1,
+					Hours: []HourTotals{
+						{Hour: h1, Resources: 5},
+						{Hour: h2, Resources: 4},
+					},
+					Kinds: []KindTotals{
+						{Group: "ec2.aws.upbound.io", Version: "v1", Kind: "Instance", MaxResources: 3, AverageResources: 1.5, PeakHour: h1},
+						{Group: "s3.aws.upbound.io", Version: "v1", Kind: "Bucket", MaxResources: 4, AverageResources: 3, PeakHour: h2},
+					},
+					Providers: []ProviderTotals{
+						{Provider: "provider-aws-ec2", MaxResources: 3, AverageResources: 1.5, PeakHour: h1, Groups: []GroupTotals{
+							{Group: "ec2.aws.upbound.io", MaxResources: 3, AverageResources: 1.5, PeakHour: h1},
+						}},
+						{Provider: "provider-aws-s3", MaxResources: 4, AverageResources: 3, PeakHour: h2, Groups: []GroupTotals{
+							{Group: "s3.aws.upbound.io", MaxResources: 4, AverageResources: 3, PeakHour: h2},
+						}},
+					},
+				},
+			}, Providers: []ProviderTotals{
+				{Provider: "provider-aws-ec2", MaxResources: 3, AverageResources: 1.5, PeakHour: h1, Groups: []GroupTotals{
+					{Group: "ec2.aws.upbound.io", MaxResources: 3, AverageResources: 1.5, PeakHour: h1},
+				}},
+				{Provider: "provider-aws-s3", MaxResources: 4, AverageResources: 3, PeakHour: h2, Groups: []GroupTotals{
+					{Group: "s3.aws.upbound.io", MaxResources: 4, AverageResources: 3, PeakHour: h2},
+				}},
+			}}},
+		},
+		"CustomProvider": {
+			reason: "A custom function can map API groups to providers.",
+			reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{
+				gev("mxp1", "s3.aws.upbound.io", "Bucket", 2, h1),
+				gev("mxp1", "ec2.aws.upbound.io", "Instance", 3, h1),
+			}},
+			provider: func(string) string { return "provider-aws" },
+			want: want{report: Report{ControlPlanes: []ControlPlaneTotals{
+				{
+					MXPID:            "mxp1",
+					MaxResources:     5,
+					AverageResources: 5,
+					PeakHour:         h1,
+					Hours:            []HourTotals{{Hour: h1, Resources: 5}},
+					Kinds: []KindTotals{
+						{Group: "ec2.aws.upbound.io", Version: "v1", Kind: "Instance", MaxResources: 3, AverageResources: 3, PeakHour: h1},
+						{Group: "s3.aws.upbound.io", Version: "v1", Kind: "Bucket", MaxResources: 2, AverageResources: 2, PeakHour: h1},
+					},
+					Providers: []ProviderTotals{
+						{Provider: "provider-aws", MaxResources: 5, AverageResources: 5, PeakHour: h1, Groups: []GroupTotals{
+							{Group: "ec2.aws.upbound.io", MaxResources: 3, AverageResources: 3, PeakHour: h1},
+							{Group: "s3.aws.upbound.io", MaxResources: 2, AverageResources: 2, PeakHour: h1},
+						}},
+					},
+				},
+			}, Providers: []ProviderTotals{
+				{Provider: "provider-aws", MaxResources: 5, AverageResources: 5, PeakHour: h1, Groups: []GroupTotals{
+					{Group: "ec2.aws.upbound.io", MaxResources: 3, AverageResources: 3, PeakHour: h1},
+					{Group: "s3.aws.upbound.io", MaxResources: 2, AverageResources: 2, PeakHour: h1},
+				}},
+			}}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &Rollup{Provider: tc.provider}
+			err := r.ReadFrom(context.Background(), tc.reader)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadFrom(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.report, r.Report()); diff != "" {
+				t.Errorf("\n%s\nReport(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestProviderForGroup(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		group  string
+		want   string
+	}{
+		"UpboundService": {
+			reason: "Upbound API groups of a service are served by the provider of the service.",
+			group:  "s3.aws.upbound.io",
+			want:   "provider-aws-s3",
+		},
+		"UpboundFamily": {
+			reason: "Upbound API groups of a family are served by the family provider.",
+			group:  "azure.upbound.io",
+			want:   "provider-family-azure",
+		},
+		"Community": {
+			reason: "Crossplane community API groups are served by a single provider.",
+			group:  "ec2.aws.crossplane.io",
+			want:   "provider-aws",
+		},
+		"Other": {
+			reason: "Other API groups are returned unchanged.",
+			group:  "example.com",
+			want:   "example.com",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ProviderForGroup(tc.group)); diff != "" {
+				t.Errorf("\n%s\nProviderForGroup(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const errCreateSession = "error creating aws session"
+
+type clientConfig struct {
+	profile    string
+	region     string
+	endpoint   string
+	roleARN    string
+	externalID string
+	httpClient *http.Client
+	maxRetries *int
+}
+
+// ClientOption modifies the configuration of an S3 client.
+type ClientOption func(*clientConfig)
+
+// WithProfile loads credentials and configuration from a named profile in the
+// shared AWS config and credentials files.
+func WithProfile(profile string) ClientOption {
+	return func(c *clientConfig) {
+		c.profile = profile
+	}
+}
+
+// WithRegion sets the region of the bucket.
+func WithRegion(region string) ClientOption {
+	return func(c *clientConfig) {
+		c.region = region
+	}
+}
+
+// WithEndpoint sets a custom S3 endpoint.
+func WithEndpoint(endpoint string) ClientOption {
+	return func(c *clientConfig) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithRole assumes a role before accessing the bucket. The external ID is
+// optional.
+func WithRole(arn, externalID string) ClientOption {
+	return func(c *clientConfig) {
+		c.roleARN = arn
+		c.externalID = externalID
+	}
+}
+
+// WithHTTPClient sets the HTTP client requests are sent with, e.g. to record
+// metrics.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *clientConfig) {
+		c.httpClient = hc
+	}
+}
+
+// WithMaxRetries sets the number of times the SDK retries a failed request,
+// e.g. to disable retries when the HTTP client already retries them.
+func WithMaxRetries(n int) ClientOption {
+	return func(c *clientConfig) {
+		c.maxRetries = aws.Int(n)
+	}
+}
+
+// NewClient returns an S3 client for reading usage data. Credentials are read
+// from the default credential chain unless a profile is set. If a role is set,
+// the credentials are used to assume it.
+func NewClient(opts ...ClientOption) (*s3.S3, error) {
+	c := &clientConfig{}
+	for _, o := range opts {
+		o(c)
+	}
+
+	so := session.Options{Profile: c.profile}
+	if c.profile != "" {
+		so.SharedConfigState = session.SharedConfigEnable
+	}
+	if c.region != "" {
+		so.Config.Region = aws.String(c.region)
+	}
+	if c.httpClient != nil {
+		so.Config.HTTPClient = c.httpClient
+	}
+	so.Config.MaxRetries = c.maxRetries
+	sess, err := session.NewSessionWithOptions(so)
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateSession)
+	}
+
+	// The endpoint is only set on the S3 client so that role assumption
+	// continues to use the default STS endpoint.
+	config := &aws.Config{}
+	if c.endpoint != "" {
+		config.Endpoint = aws.String(c.endpoint)
+	}
+	if c.roleARN != "" {
+		config.Credentials = stscreds.NewCredentials(sess, c.roleARN, func(p *stscreds.AssumeRoleProvider) {
+			if c.externalID != "" {
+				p.ExternalID = aws.String(c.externalID)
+			}
+		})
+	}
+	return s3.New(sess, config), nil
+}
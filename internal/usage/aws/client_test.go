@@ -0,0 +1,82 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestNewClient(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile billing]\nregion = eu-west-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", config)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	type want struct {
+		region   string
+		endpoint string
+	}
+	cases := map[string]struct {
+		reason string
+		opts   []ClientOption
+		want   want
+	}{
+		"Region": {
+			reason: "The region should be set on the client.",
+			opts:   []ClientOption{WithRegion("us-west-2")},
+			want:   want{region: "us-west-2", endpoint: "https://s3.us-west-2.amazonaws.com"},
+		},
+		"Profile": {
+			reason: "The region should be loaded from the profile.",
+			opts:   []ClientOption{WithProfile("billing")},
+			want:   want{region: "eu-west-1", endpoint: "https://s3.eu-west-1.amazonaws.com"},
+		},
+		"RegionOverridesProfile": {
+			reason: "An explicit region should override the region of the profile.",
+			opts:   []ClientOption{WithProfile("billing"), WithRegion("us-west-2")},
+			want:   want{region: "us-west-2", endpoint: "https://s3.us-west-2.amazonaws.com"},
+		},
+		"Endpoint": {
+			reason: "A custom endpoint should be set on the client.",
+			opts:   []ClientOption{WithRegion("us-west-2"), WithEndpoint("https://minio.example.com"), WithRole("arn:aws:iam::123456789012:role/billing", "external")},
+			want:   want{region: "us-west-2", endpoint: "https://minio.example.com"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cli, err := NewClient(tc.opts...)
+			if err != nil {
+				t.Fatalf("\n%s\nNewClient(...): %s", tc.reason, err)
+			}
+			if got := aws.StringValue(cli.Config.Region); got != tc.want.region {
+				t.Errorf("\n%s\nNewClient(...): want region %q, got %q", tc.reason, tc.want.region, got)
+			}
+			if got := cli.Endpoint; got != tc.want.endpoint {
+				t.Errorf("\n%s\nNewClient(...): want endpoint %q, got %q", tc.reason, tc.want.endpoint, got)
+			}
+		})
+	}
+}
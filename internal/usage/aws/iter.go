@@ -15,15 +15,16 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	clock "k8s.io/utils/clock/testing"
 
 	"github.com/upbound/up/internal/usage/event"
-	"github.com/upbound/up/internal/usage/event/reader"
 	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
@@ -32,14 +33,14 @@ var _ event.WindowIterator = &WindowIterator{}
 // WindowIterator iterates through readers for windows of usage events from an
 // S3 bucket. Must be initialized with NewWindowIterator().
 type WindowIterator struct {
-	Client *s3.S3
+	Client s3iface.S3API
 	Bucket string
 	Iter   *ListObjectsV2InputIterator
 }
 
 // NewWindowIterator returns an initialized *WindowIterator.
-func NewWindowIterator(cli *s3.S3, bucket, account string, tr usagetime.Range, window time.Duration) (*WindowIterator, error) {
-	iter, err := NewListObjectsV2InputIterator(bucket, account, tr, window)
+func NewWindowIterator(cli s3iface.S3API, bucket, account string, tr usagetime.Range, window time.Duration, opts ...usagetime.IteratorOption) (*WindowIterator, error) {
+	iter, err := NewListObjectsV2InputIterator(bucket, account, tr, window, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -59,17 +60,57 @@ func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
 	if err != nil {
 		return nil, usagetime.Range{}, err
 	}
+	return &ObjectIteratorEventReader{
+		Client:   i.Client,
+		Bucket:   i.Bucket,
+		Iterator: NewObjectIterator(i.Client, inputs...),
+	}, window, nil
+}
+
+// ObjectIterator iterates through the objects listed by one or more
+// *s3.ListObjectsV2Input, following continuation tokens until each listing is
+// complete. Pages are requested as they are needed. Must be initialized with
+// NewObjectIterator().
+type ObjectIterator struct {
+	Client s3iface.S3API
+	Inputs []*s3.ListObjectsV2Input
+
+	page  []*s3.Object
+	token *string
+	// listing is true if the current input has pages left to request.
+	listing bool
+}
 
-	readers := make([]event.Reader, len(inputs))
-	for j, loi := range inputs {
-		readers[j] = &ListObjectsV2InputEventReader{
-			Bucket:             i.Bucket,
-			Client:             i.Client,
-			ListObjectsV2Input: loi,
+// NewObjectIterator returns an initialized *ObjectIterator.
+func NewObjectIterator(cli s3iface.S3API, inputs ...*s3.ListObjectsV2Input) *ObjectIterator {
+	return &ObjectIterator{Client: cli, Inputs: inputs, listing: len(inputs) > 0}
+}
+
+// Next returns the next object. Returns ErrEOF when there are no more objects.
+func (i *ObjectIterator) Next(ctx context.Context) (*s3.Object, error) {
+	for len(i.page) == 0 {
+		if !i.listing {
+			if len(i.Inputs) <= 1 {
+				i.Inputs = nil
+				return nil, ErrEOF
+			}
+			i.Inputs = i.Inputs[1:]
+			i.token = nil
+			i.listing = true
+		}
+		in := *i.Inputs[0]
+		in.ContinuationToken = i.token
+		out, err := i.Client.ListObjectsV2WithContext(ctx, &in)
+		if err != nil {
+			return nil, err
 		}
+		i.page = out.Contents
+		i.token = out.NextContinuationToken
+		i.listing = aws.BoolValue(out.IsTruncated) && i.token != nil
 	}
-
-	return &reader.MultiReader{Readers: readers}, window, nil
+	obj := i.page[0]
+	i.page = i.page[1:]
+	return obj, nil
 }
 
 // ListObjectsV2InputIterator iterates through a []*s3.ListObjectsV2Input for
@@ -78,12 +119,12 @@ func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
 type ListObjectsV2InputIterator struct {
 	Bucket  string
 	Account string
-	Iter    *usagetime.WindowIterator
+	Iter    usagetime.Iterator
 }
 
 // NewListObjectsV2InputIterator returns an initialized *ListObjectsV2InputIterator.
-func NewListObjectsV2InputIterator(bucket string, account string, tr usagetime.Range, window time.Duration) (*ListObjectsV2InputIterator, error) {
-	iter, err := usagetime.NewWindowIterator(tr, window)
+func NewListObjectsV2InputIterator(bucket string, account string, tr usagetime.Range, window time.Duration, opts ...usagetime.IteratorOption) (*ListObjectsV2InputIterator, error) {
+	iter, err := usagetime.NewIterator(tr, window, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +162,7 @@ func (i *ListObjectsV2InputIterator) Next() ([]*s3.ListObjectsV2Input, usagetime
 				"account=%s/date=%s/hour=%02d/",
 				i.Account,
 				usagetime.FormatDateUTC(now),
-				now.Hour(),
+				now.UTC().Hour(),
 			)),
 		})
 		now = c.Now()
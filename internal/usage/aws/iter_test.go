@@ -15,11 +15,19 @@
 package aws
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 
@@ -517,3 +525,173 @@ func TestListObjectsV2InputIterator(t *testing.T) {
 		})
 	}
 }
+
+// fakeS3 serves pages of object keys for each listed prefix and object bodies
+// for each key.
+type fakeS3 struct {
+	s3iface.S3API
+
+	pages   map[string][][]string
+	objects map[string]string
+	err     error
+}
+
+func (f *fakeS3) ListObjectsV2WithContext(_ aws.Context, in *s3.ListObjectsV2Input, _ ...request.Option) (*s3.ListObjectsV2Output, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	pages := f.pages[aws.StringValue(in.Prefix)]
+	out := &s3.ListObjectsV2Output{Contents: []*s3.Object{}}
+	if len(pages) == 0 {
+		return out, nil
+	}
+	page := 0
+	if in.ContinuationToken != nil {
+		page, _ = strconv.Atoi(aws.StringValue(in.ContinuationToken))
+	}
+	for _, key := range pages[page] {
+		out.Contents = append(out.Contents, &s3.Object{Key: aws.String(key), Size: aws.Int64(int64(len(f.objects[key])))})
+	}
+	if page+1 < len(pages) {
+		out.IsTruncated = aws.Bool(true)
+		out.NextContinuationToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return out, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(f.objects[aws.StringValue(in.Key)]))}, nil
+}
+
+func TestObjectIterator(t *testing.T) {
+	input := func(prefix string) *s3.ListObjectsV2Input {
+		return &s3.ListObjectsV2Input{Bucket: aws.String("test-bucket"), Prefix: aws.String(prefix)}
+	}
+	errBoom := errors.New("boom")
+
+	type want struct {
+		keys []string
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		client *fakeS3
+		inputs []*s3.ListObjectsV2Input
+		want   want
+	}{
+		"NoInputs": {
+			reason: "An iterator without inputs returns no objects.",
+			client: &fakeS3{},
+			want:   want{keys: []string{}},
+		},
+		"Pages": {
+			reason: "All pages of each input are listed in order.",
+			client: &fakeS3{pages: map[string][][]string{
+				"hour=03/": {{"hour=03/a", "hour=03/b"}, {"hour=03/c"}, {"hour=03/d"}},
+				"hour=05/": {{"hour=05/a"}},
+			}},
+			inputs: []*s3.ListObjectsV2Input{input("hour=03/"), input("hour=04/"), input("hour=05/")},
+			want: want{keys: []string{
+				"hour=03/a", "hour=03/b", "hour=03/c", "hour=03/d", "hour=05/a",
+			}},
+		},
+		"EmptyPages": {
+			reason: "Empty pages in a truncated listing are skipped.",
+			client: &fakeS3{pages: map[string][][]string{
+				"hour=03/": {{}, {"hour=03/a"}},
+			}},
+			inputs: []*s3.ListObjectsV2Input{input("hour=03/")},
+			want:   want{keys: []string{"hour=03/a"}},
+		},
+		"ListError": {
+			reason: "Errors listing objects are returned.",
+			client: &fakeS3{err: errBoom},
+			inputs: []*s3.ListObjectsV2Input{input("hour=03/")},
+			want:   want{keys: []string{}, err: errBoom},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			iter := NewObjectIterator(tc.client, tc.inputs...)
+			keys := []string{}
+			var err error
+			for {
+				var obj *s3.Object
+				obj, err = iter.Next(context.Background())
+				if err != nil {
+					break
+				}
+				keys = append(keys, aws.StringValue(obj.Key))
+			}
+			if errors.Is(err, ErrEOF) {
+				err = nil
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNext(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.keys, keys); diff != "" {
+				t.Errorf("\n%s\nNext(...): -want keys, +got keys:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWindowIterator(t *testing.T) {
+	object := func(names ...string) string {
+		events := []string{}
+		for _, n := range names {
+			events = append(events, fmt.Sprintf(`{"name":%q}`, n))
+		}
+		return "[" + strings.Join(events, ",") + "]"
+	}
+	client := &fakeS3{
+		pages: map[string][][]string{
+			"account=test-account/date=2006-05-04/hour=03/": {{"03/a"}, {"03/b"}},
+			"account=test-account/date=2006-05-04/hour=04/": {{"04/a"}},
+		},
+		objects: map[string]string{
+			"03/a": object("a1", "a2"),
+			"03/b": object("b1"),
+			"04/a": object("c1"),
+		},
+	}
+	tr := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+	}
+
+	iter, err := NewWindowIterator(client, "test-bucket", "test-account", tr, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("NewWindowIterator(...): %s", err)
+	}
+	r, window, err := iter.Next()
+	if err != nil {
+		t.Fatalf("Next(): %s", err)
+	}
+	if diff := cmp.Diff(tr, window); diff != "" {
+		t.Errorf("Next(): -want window, +got window:\n%s", diff)
+	}
+
+	got := []string{}
+	for {
+		e, err := r.Read(context.Background())
+		if errors.Is(err, ErrEOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read(...): %s", err)
+		}
+		got = append(got, e.Name)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close(): %s", err)
+	}
+	want := []string{"a1", "a2", "b1", "c1"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Read(...): -want, +got:\n%s", diff)
+	}
+	if iter.More() {
+		t.Errorf("More(): want false, got true")
+	}
+}
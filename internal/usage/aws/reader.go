@@ -21,52 +21,42 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 
 	"github.com/upbound/up/internal/usage/encoding/json"
 	"github.com/upbound/up/internal/usage/event"
-	"github.com/upbound/up/internal/usage/event/reader"
 	"github.com/upbound/up/internal/usage/model"
 )
 
 var ErrEOF = event.ErrEOF
 
-var _ event.Reader = &ListObjectsV2InputEventReader{}
+var (
+	_ event.Reader = &ListObjectsV2InputEventReader{}
+	_ event.Sizer  = &ListObjectsV2InputEventReader{}
+)
 
-// ListBlobsResponseEventReader reads usage events from a
+// ListObjectsV2InputEventReader reads usage events from a
 // *s3.ListObjectsV2Input.
 type ListObjectsV2InputEventReader struct {
-	Client             *s3.S3
+	Client             s3iface.S3API
 	Bucket             string
 	ListObjectsV2Input *s3.ListObjectsV2Input
-	reader             *reader.MultiReader
+	reader             *ObjectIteratorEventReader
 }
 
 func (r *ListObjectsV2InputEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
 	if r.reader == nil {
-		readers := []event.Reader{}
-		if err := r.Client.ListObjectsV2PagesWithContext(
-			ctx,
-			r.ListObjectsV2Input,
-			func(page *s3.ListObjectsV2Output, _ bool) bool {
-				for _, obj := range page.Contents {
-					readers = append(readers, &GetObjectInputEventReader{
-						Client: r.Client,
-						GetObjectInput: &s3.GetObjectInput{
-							Bucket: aws.String(r.Bucket),
-							Key:    obj.Key,
-						},
-					})
-				}
-				return true
-			},
-		); err != nil {
-			return model.MXPGVKEvent{}, err
-		}
-		r.reader = &reader.MultiReader{Readers: readers}
+		r.reader = r.newReader()
 	}
 	return r.reader.Read(ctx)
 }
 
+// Size lists the objects of the input and returns their number and total size.
+func (r *ListObjectsV2InputEventReader) Size(ctx context.Context) (int, int64, error) {
+	return r.newReader().Size(ctx)
+}
+
 func (r *ListObjectsV2InputEventReader) Close() error {
 	if r.reader == nil {
 		return nil
@@ -74,11 +64,82 @@ func (r *ListObjectsV2InputEventReader) Close() error {
 	return r.reader.Close()
 }
 
+func (r *ListObjectsV2InputEventReader) newReader() *ObjectIteratorEventReader {
+	return &ObjectIteratorEventReader{
+		Client:   r.Client,
+		Bucket:   r.Bucket,
+		Iterator: NewObjectIterator(r.Client, r.ListObjectsV2Input),
+	}
+}
+
+var (
+	_ event.Reader = &ObjectIteratorEventReader{}
+	_ event.Sizer  = &ObjectIteratorEventReader{}
+)
+
+// ObjectIteratorEventReader reads usage events from the objects returned by an
+// *ObjectIterator.
+type ObjectIteratorEventReader struct {
+	Client     s3iface.S3API
+	Bucket     string
+	Iterator   *ObjectIterator
+	currReader *GetObjectInputEventReader
+}
+
+func (r *ObjectIteratorEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	for {
+		if r.currReader == nil {
+			obj, err := r.Iterator.Next(ctx)
+			if err != nil {
+				return model.MXPGVKEvent{}, err
+			}
+			r.currReader = &GetObjectInputEventReader{
+				Client: r.Client,
+				GetObjectInput: &s3.GetObjectInput{
+					Bucket: aws.String(r.Bucket),
+					Key:    obj.Key,
+				},
+			}
+		}
+		if e, err := r.currReader.Read(ctx); !errors.Is(err, ErrEOF) {
+			return e, err
+		}
+		if err := r.currReader.Close(); err != nil {
+			return model.MXPGVKEvent{}, err
+		}
+		r.currReader = nil
+	}
+}
+
+// Size consumes the iterator and returns the number and total size of its
+// objects.
+func (r *ObjectIteratorEventReader) Size(ctx context.Context) (int, int64, error) {
+	objects, bytes := 0, int64(0)
+	for {
+		obj, err := r.Iterator.Next(ctx)
+		if errors.Is(err, ErrEOF) {
+			return objects, bytes, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		objects++
+		bytes += aws.Int64Value(obj.Size)
+	}
+}
+
+func (r *ObjectIteratorEventReader) Close() error {
+	if r.currReader == nil {
+		return nil
+	}
+	return r.currReader.Close()
+}
+
 var _ event.Reader = &GetObjectInputEventReader{}
 
 // GetObjectInputEventReader reads usage events from a *s3.GetObjectInput.
 type GetObjectInputEventReader struct {
-	Client         *s3.S3
+	Client         s3iface.S3API
 	GetObjectInput *s3.GetObjectInput
 	decoder        *json.MXPGVKEventDecoder
 	closers        []io.Closer
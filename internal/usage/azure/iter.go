@@ -37,8 +37,8 @@ type WindowIterator struct {
 }
 
 // NewWindowIterator returns an initialized *WindowIterator.
-func NewWindowIterator(cli *container.Client, account string, tr usagetime.Range, window time.Duration) (*WindowIterator, error) {
-	iter, err := NewListBlobsOptionsIterator(account, tr, window)
+func NewWindowIterator(cli *container.Client, account string, tr usagetime.Range, window time.Duration, opts ...usagetime.IteratorOption) (*WindowIterator, error) {
+	iter, err := NewListBlobsOptionsIterator(account, tr, window, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,12 +72,12 @@ func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
 // NewListBlobOptions().
 type ListBlobsOptionsIterator struct {
 	Account string
-	Iter    *usagetime.WindowIterator
+	Iter    usagetime.Iterator
 }
 
 // NewListBlobsOptionsIterator returns an initialized *ListBlobOptionsIterator.
-func NewListBlobsOptionsIterator(account string, tr usagetime.Range, window time.Duration) (*ListBlobsOptionsIterator, error) {
-	iter, err := usagetime.NewWindowIterator(tr, window)
+func NewListBlobsOptionsIterator(account string, tr usagetime.Range, window time.Duration, opts ...usagetime.IteratorOption) (*ListBlobsOptionsIterator, error) {
+	iter, err := usagetime.NewIterator(tr, window, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +113,7 @@ func (i *ListBlobsOptionsIterator) Next() ([]container.ListBlobsFlatOptions, usa
 				"account=%s/date=%s/hour=%02d/",
 				i.Account,
 				usagetime.FormatDateUTC(now),
-				now.Hour(),
+				now.UTC().Hour(),
 			)),
 		})
 		now = c.Now()
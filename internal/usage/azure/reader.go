@@ -32,7 +32,10 @@ import (
 
 var ErrEOF = event.ErrEOF
 
-var _ event.Reader = &PagerEventReader{}
+var (
+	_ event.Reader = &PagerEventReader{}
+	_ event.Sizer  = &PagerEventReader{}
+)
 
 // PagerEventReader reads usage events from a pager for blob list responses.
 type PagerEventReader struct {
@@ -61,6 +64,25 @@ func (r *PagerEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error)
 	}
 }
 
+// Size lists the remaining pages of blobs and returns the number and total size
+// of the blobs.
+func (r *PagerEventReader) Size(ctx context.Context) (int, int64, error) {
+	objects, bytes := 0, int64(0)
+	for r.Pager.More() {
+		resp, err := r.Pager.NextPage(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, blob := range resp.Segment.BlobItems {
+			objects++
+			if blob.Properties != nil && blob.Properties.ContentLength != nil {
+				bytes += *blob.Properties.ContentLength
+			}
+		}
+	}
+	return objects, bytes, nil
+}
+
 func (r *PagerEventReader) Close() error {
 	if r.currReader == nil {
 		return nil
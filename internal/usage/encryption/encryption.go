@@ -0,0 +1,216 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encryption encrypts and decrypts usage archives with OpenPGP.
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	armorHeader      = "-----BEGIN PGP"
+	armorMessageType = "PGP MESSAGE"
+
+	errNoRecipients     = "at least one recipient is required"
+	errReadKeys         = "cannot read OpenPGP keys"
+	errNoKeys           = "no OpenPGP keys found"
+	errEncrypt          = "cannot encrypt archive"
+	errDecrypt          = "cannot decrypt archive"
+	errNoDecryptionKey  = "archive is encrypted but no decryption key was supplied"
+	errNoPassphrase     = "decryption key is protected by a passphrase but none was supplied"
+	errWrongPassphrase  = "cannot unlock decryption key with the supplied passphrase"
+	errPeek             = "cannot read archive"
+	errNotAuthenticated = "archive failed integrity check"
+)
+
+// ReadKeys reads OpenPGP keys from r, which may be armored or binary.
+func ReadKeys(r io.Reader) (openpgp.EntityList, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(armorHeader))
+	var (
+		keys openpgp.EntityList
+		err  error
+	)
+	if bytes.Equal(head, []byte(armorHeader)) {
+		keys, err = openpgp.ReadArmoredKeyRing(br)
+	} else {
+		keys, err = openpgp.ReadKeyRing(br)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errReadKeys)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New(errNoKeys)
+	}
+	return keys, nil
+}
+
+// An Encrypter encrypts usage archives for a set of recipients.
+type Encrypter struct {
+	recipients openpgp.EntityList
+	armor      bool
+}
+
+// Option modifies an Encrypter.
+type Option func(*Encrypter)
+
+// WithRecipients adds recipients that can decrypt archives.
+func WithRecipients(keys ...*openpgp.Entity) Option {
+	return func(e *Encrypter) {
+		e.recipients = append(e.recipients, keys...)
+	}
+}
+
+// WithArmor makes the Encrypter write ASCII armored archives.
+func WithArmor() Option {
+	return func(e *Encrypter) {
+		e.armor = true
+	}
+}
+
+// NewEncrypter returns an Encrypter. At least one recipient must be supplied.
+func NewEncrypter(opts ...Option) (*Encrypter, error) {
+	e := &Encrypter{}
+	for _, o := range opts {
+		o(e)
+	}
+	if len(e.recipients) == 0 {
+		return nil, errors.New(errNoRecipients)
+	}
+	return e, nil
+}
+
+// Encrypt returns a writer that encrypts what is written to it and writes the
+// result to w. The returned writer must be closed to flush the archive; it
+// does not close w.
+func (e *Encrypter) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	closers := []io.Closer{}
+	if e.armor {
+		aw, err := armor.Encode(w, armorMessageType, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, errEncrypt)
+		}
+		w = aw
+		closers = append(closers, aw)
+	}
+	pw, err := openpgp.Encrypt(w, e.recipients, nil, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, errEncrypt)
+	}
+	return &writer{WriteCloser: pw, closers: closers}, nil
+}
+
+// writer closes the encrypting writer followed by any armor writer.
+type writer struct {
+	io.WriteCloser
+	closers []io.Closer
+}
+
+func (w *writer) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	for _, c := range w.closers {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsEncrypted returns true if the archive read by r is an OpenPGP message. It
+// does not consume r.
+func IsEncrypted(r *bufio.Reader) (bool, error) {
+	head, err := r.Peek(len(armorHeader))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, errors.Wrap(err, errPeek)
+	}
+	if bytes.Equal(head, []byte(armorHeader)) {
+		return true, nil
+	}
+	// Unencrypted archives are gzip, tar, CSV or parquet files. None of them
+	// start with a byte that has the high bit set, which OpenPGP packets do.
+	return len(head) > 0 && head[0]&0x80 != 0, nil
+}
+
+// NewReader returns a reader of the plaintext of the archive read by r. If the
+// archive is not encrypted it is returned as is. Encrypted archives are
+// decrypted with the supplied keys, which are unlocked with the passphrase if
+// necessary. The integrity of the archive is only verified once the returned
+// reader has been read to EOF.
+func NewReader(r io.Reader, keys openpgp.EntityList, passphrase []byte) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	enc, err := IsEncrypted(br)
+	if err != nil {
+		return nil, err
+	}
+	if !enc {
+		return br, nil
+	}
+	if len(keys) == 0 {
+		return nil, errors.New(errNoDecryptionKey)
+	}
+
+	var in io.Reader = br
+	if head, _ := br.Peek(len(armorHeader)); bytes.Equal(head, []byte(armorHeader)) {
+		b, err := armor.Decode(br)
+		if err != nil {
+			return nil, errors.Wrap(err, errDecrypt)
+		}
+		in = b.Body
+	}
+
+	prompted := false
+	prompt := func(ks []openpgp.Key, _ bool) ([]byte, error) {
+		if len(passphrase) == 0 {
+			return nil, errors.New(errNoPassphrase)
+		}
+		if prompted {
+			return nil, errors.New(errWrongPassphrase)
+		}
+		prompted = true
+		for _, k := range ks {
+			if k.PrivateKey != nil && k.PrivateKey.Encrypted {
+				_ = k.PrivateKey.Decrypt(passphrase)
+			}
+		}
+		return nil, nil
+	}
+	md, err := openpgp.ReadMessage(in, keys, prompt, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, errDecrypt)
+	}
+	return &reader{md: md}, nil
+}
+
+// reader reads the body of a decrypted message and reports integrity failures
+// once it has been read.
+type reader struct {
+	md *openpgp.MessageDetails
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.md.UnverifiedBody.Read(p)
+	if errors.Is(err, io.EOF) && r.md.SignatureError != nil {
+		return n, errors.Wrap(r.md.SignatureError, errNotAuthenticated)
+	}
+	return n, err
+}
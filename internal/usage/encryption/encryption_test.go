@@ -0,0 +1,206 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newEntity(t *testing.T, passphrase []byte) *openpgp.Entity {
+	t.Helper()
+	e, err := openpgp.NewEntity("billing", "", "billing@example.org", &packet.Config{RSABits: 1024})
+	if err != nil {
+		t.Fatalf("NewEntity(...): %v", err)
+	}
+	if len(passphrase) == 0 {
+		return e
+	}
+	if err := e.PrivateKey.Encrypt(passphrase); err != nil {
+		t.Fatalf("Encrypt(...): %v", err)
+	}
+	for _, s := range e.Subkeys {
+		if err := s.PrivateKey.Encrypt(passphrase); err != nil {
+			t.Fatalf("Encrypt(...): %v", err)
+		}
+	}
+	return e
+}
+
+func TestReadKeys(t *testing.T) {
+	e := newEntity(t, nil)
+	bin := &bytes.Buffer{}
+	if err := e.Serialize(bin); err != nil {
+		t.Fatalf("Serialize(...): %v", err)
+	}
+	armored := &bytes.Buffer{}
+	aw, _ := armor.Encode(armored, openpgp.PublicKeyType, nil)
+	_, _ = aw.Write(bin.Bytes())
+	_ = aw.Close()
+
+	type want struct {
+		fingerprint []byte
+		err         error
+	}
+	cases := map[string]struct {
+		reason string
+		in     []byte
+		want   want
+	}{
+		"Binary": {
+			reason: "Binary keys should be read.",
+			in:     bin.Bytes(),
+			want:   want{fingerprint: e.PrimaryKey.Fingerprint},
+		},
+		"Armored": {
+			reason: "ASCII armored keys should be read.",
+			in:     armored.Bytes(),
+			want:   want{fingerprint: e.PrimaryKey.Fingerprint},
+		},
+		"Empty": {
+			reason: "An empty key file should return an error.",
+			want:   want{err: errors.New(errNoKeys)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			keys, err := ReadKeys(bytes.NewReader(tc.in))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nReadKeys(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var got []byte
+			if len(keys) > 0 {
+				got = keys[0].PrimaryKey.Fingerprint
+			}
+			if diff := cmp.Diff(tc.want.fingerprint, got); diff != "" {
+				t.Errorf("\n%s\nReadKeys(...): -want fingerprint, +got fingerprint:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewEncrypter(t *testing.T) {
+	_, err := NewEncrypter()
+	if diff := cmp.Diff(errors.New(errNoRecipients), err, test.EquateErrors()); diff != "" {
+		t.Errorf("NewEncrypter(): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	plaintext := []byte("\x1f\x8b usage archive")
+	key := newEntity(t, nil)
+	other := newEntity(t, nil)
+	// Unlocking a key decrypts it in place, so each case needs its own.
+	locked := newEntity(t, []byte("secret"))
+	stillLocked := newEntity(t, []byte("secret"))
+
+	type args struct {
+		recipient  *openpgp.Entity
+		armor      bool
+		encrypt    bool
+		keys       openpgp.EntityList
+		passphrase []byte
+	}
+	type want struct {
+		out []byte
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unencrypted": {
+			reason: "An unencrypted archive should be read as is.",
+			args:   args{},
+			want:   want{out: plaintext},
+		},
+		"Binary": {
+			reason: "A binary encrypted archive should be decrypted.",
+			args:   args{recipient: key, encrypt: true, keys: openpgp.EntityList{key}},
+			want:   want{out: plaintext},
+		},
+		"Armored": {
+			reason: "An armored encrypted archive should be decrypted.",
+			args:   args{recipient: key, encrypt: true, armor: true, keys: openpgp.EntityList{key}},
+			want:   want{out: plaintext},
+		},
+		"NoKey": {
+			reason: "An encrypted archive should not be read without a key.",
+			args:   args{recipient: key, encrypt: true},
+			want:   want{err: errors.New(errNoDecryptionKey)},
+		},
+		"WrongKey": {
+			reason: "An encrypted archive should not be decrypted with another key.",
+			args:   args{recipient: key, encrypt: true, keys: openpgp.EntityList{other}},
+			want:   want{err: errors.Wrap(errors.New("openpgp: incorrect key"), errDecrypt)},
+		},
+		"Passphrase": {
+			reason: "A passphrase protected key should be unlocked with the passphrase.",
+			args:   args{recipient: locked, encrypt: true, keys: openpgp.EntityList{locked}, passphrase: []byte("secret")},
+			want:   want{out: plaintext},
+		},
+		"NoPassphrase": {
+			reason: "A passphrase protected key should not be used without the passphrase.",
+			args:   args{recipient: stillLocked, encrypt: true, keys: openpgp.EntityList{stillLocked}},
+			want:   want{err: errors.Wrap(errors.New(errNoPassphrase), errDecrypt)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			archive := &bytes.Buffer{}
+			if tc.args.encrypt {
+				opts := []Option{WithRecipients(tc.args.recipient)}
+				if tc.args.armor {
+					opts = append(opts, WithArmor())
+				}
+				e, err := NewEncrypter(opts...)
+				if err != nil {
+					t.Fatalf("NewEncrypter(...): %v", err)
+				}
+				w, err := e.Encrypt(archive)
+				if err != nil {
+					t.Fatalf("Encrypt(...): %v", err)
+				}
+				_, _ = w.Write(plaintext)
+				if err := w.Close(); err != nil {
+					t.Fatalf("Close(): %v", err)
+				}
+			} else {
+				archive.Write(plaintext)
+			}
+
+			var got []byte
+			r, err := NewReader(archive, tc.args.keys, tc.args.passphrase)
+			if err == nil {
+				got, err = io.ReadAll(r)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nNewReader(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, got); diff != "" {
+				t.Errorf("\n%s\nNewReader(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
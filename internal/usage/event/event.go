@@ -34,6 +34,14 @@ type Reader interface {
 	Close() error
 }
 
+// Sizer is implemented by readers that can report the number and total size of
+// the objects they would read events from without downloading them. A reader
+// must not be read after it has been sized.
+type Sizer interface {
+	// Size returns the number of objects and their total size in bytes.
+	Size(context.Context) (objects int, bytes int64, err error)
+}
+
 // WindowIterator is the interface for iterating through usage event readers for
 // windows of time within a time range.
 type WindowIterator interface {
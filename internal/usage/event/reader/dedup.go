@@ -0,0 +1,122 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"context"
+	"time"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+// dedupKey identifies an event. Usage events do not carry an ID, so an event
+// is identified by its name, tags and timestamps.
+type dedupKey struct {
+	Name         string
+	Tags         model.MXPGVKEventTags
+	Timestamp    int64
+	TimestampEnd int64
+}
+
+func keyOf(e model.MXPGVKEvent) dedupKey {
+	return dedupKey{
+		Name:         e.Name,
+		Tags:         e.Tags,
+		Timestamp:    e.Timestamp.UnixNano(),
+		TimestampEnd: e.TimestampEnd.UnixNano(),
+	}
+}
+
+// DedupStats records how many events were read and dropped by a
+// Deduplicator.
+type DedupStats struct {
+	// Read is the number of events read from the underlying readers.
+	Read int `json:"read"`
+	// Dropped is the number of events dropped as duplicates.
+	Dropped int `json:"dropped"`
+}
+
+// Deduplicator drops events that were already read by any reader it wraps. It
+// can be shared across windows so that events which appear in more than one
+// window, such as when windows overlap or a window is re-read, are only
+// counted once. Must be initialized with NewDeduplicator().
+type Deduplicator struct {
+	seen  map[dedupKey]time.Time
+	stats DedupStats
+}
+
+// NewDeduplicator returns an initialized *Deduplicator.
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{seen: map[dedupKey]time.Time{}}
+}
+
+// Reader returns a reader that reads events from r, dropping duplicates.
+func (d *Deduplicator) Reader(r event.Reader) event.Reader {
+	return &DedupReader{Reader: r, Dedup: d}
+}
+
+// Forget forgets events with timestamps before t. Callers iterating through
+// windows in order should forget events before the start of each window to
+// bound memory use.
+func (d *Deduplicator) Forget(t time.Time) {
+	for k, ts := range d.seen {
+		if ts.Before(t) {
+			delete(d.seen, k)
+		}
+	}
+}
+
+// Stats returns the number of events read and dropped so far.
+func (d *Deduplicator) Stats() DedupStats {
+	return d.stats
+}
+
+// add records e and returns true if it was not seen before.
+func (d *Deduplicator) add(e model.MXPGVKEvent) bool {
+	d.stats.Read++
+	k := keyOf(e)
+	if _, ok := d.seen[k]; ok {
+		d.stats.Dropped++
+		return false
+	}
+	d.seen[k] = e.Timestamp
+	return true
+}
+
+var _ event.Reader = &DedupReader{}
+
+// DedupReader reads events from a reader, dropping events already seen by its
+// Deduplicator.
+type DedupReader struct {
+	Reader event.Reader
+	Dedup  *Deduplicator
+}
+
+func (r *DedupReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	for {
+		e, err := r.Reader.Read(ctx)
+		if err != nil {
+			return e, err
+		}
+		if r.Dedup.add(e) {
+			return e, nil
+		}
+	}
+}
+
+func (r *DedupReader) Close() error {
+	return r.Reader.Close()
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
+)
+
+func TestDeduplicator(t *testing.T) {
+	t1 := time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC)
+	t2 := time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC)
+	ev := func(name string, ts time.Time) usagetesting.ReadResult {
+		return usagetesting.ReadResult{Event: model.MXPGVKEvent{Name: name, Timestamp: ts}}
+	}
+
+	type want struct {
+		reads [][]usagetesting.ReadResult
+		stats DedupStats
+	}
+	cases := map[string]struct {
+		reason  string
+		readers []*usagetesting.MockReader
+		forget  time.Time
+		want    want
+	}{
+		"NoDuplicates": {
+			reason: "Distinct events are all returned.",
+			readers: []*usagetesting.MockReader{
+				{Reads: []usagetesting.ReadResult{ev("a", t1), ev("b", t1), ev("a", t2)}},
+			},
+			want: want{
+				reads: [][]usagetesting.ReadResult{{ev("a", t1), ev("b", t1), ev("a", t2)}},
+				stats: DedupStats{Read: 3},
+			},
+		},
+		"DuplicatesAcrossReaders": {
+			reason: "Events already read by another reader sharing the deduplicator are dropped.",
+			readers: []*usagetesting.MockReader{
+				{Reads: []usagetesting.ReadResult{ev("a", t1), ev("a", t2)}},
+				{Reads: []usagetesting.ReadResult{ev("a", t2), ev("b", t2)}},
+			},
+			want: want{
+				reads: [][]usagetesting.ReadResult{
+					{ev("a", t1), ev("a", t2)},
+					{ev("b", t2)},
+				},
+				stats: DedupStats{Read: 4, Dropped: 1},
+			},
+		},
+		"Forgotten": {
+			reason: "Events forgotten between readers are not considered duplicates.",
+			readers: []*usagetesting.MockReader{
+				{Reads: []usagetesting.ReadResult{ev("a", t1), ev("a", t2)}},
+				{Reads: []usagetesting.ReadResult{ev("a", t1), ev("a", t2)}},
+			},
+			forget: t2,
+			want: want{
+				reads: [][]usagetesting.ReadResult{
+					{ev("a", t1), ev("a", t2)},
+					{ev("a", t1)},
+				},
+				stats: DedupStats{Read: 4, Dropped: 1},
+			},
+		},
+		"Error": {
+			reason: "Errors from the underlying reader are returned.",
+			readers: []*usagetesting.MockReader{
+				{Reads: []usagetesting.ReadResult{ev("a", t1), {Err: errors.New("boom")}}},
+			},
+			want: want{
+				reads: [][]usagetesting.ReadResult{{ev("a", t1), {Err: errors.New("boom")}}},
+				stats: DedupStats{Read: 1},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			d := NewDeduplicator()
+			got := [][]usagetesting.ReadResult{}
+			for _, mr := range tc.readers {
+				r := d.Reader(mr)
+				reads := []usagetesting.ReadResult{}
+				for {
+					e, err := r.Read(ctx)
+					if errors.Is(err, ErrEOF) {
+						break
+					}
+					reads = append(reads, usagetesting.ReadResult{Event: e, Err: err})
+					if err != nil {
+						break
+					}
+				}
+				got = append(got, reads)
+				if !tc.forget.IsZero() {
+					d.Forget(tc.forget)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.reads, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDedupReader: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.stats, d.Stats()); diff != "" {
+				t.Errorf("\n%s\nStats(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -24,7 +24,10 @@ import (
 
 var ErrEOF = event.ErrEOF
 
-var _ event.Reader = &MultiReader{}
+var (
+	_ event.Reader = &MultiReader{}
+	_ event.Sizer  = &MultiReader{}
+)
 
 // MultiReader is the logical concatenation of its readers. They're read
 // sequentially. Once all readers have returned EOF, Read will return EOF. If
@@ -51,6 +54,25 @@ func (r *MultiReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
 	}
 }
 
+// Size returns the total number and size of the objects of the readers. Returns
+// an error if any of the readers is not an event.Sizer.
+func (r *MultiReader) Size(ctx context.Context) (int, int64, error) {
+	objects, bytes := 0, int64(0)
+	for _, er := range r.Readers {
+		s, ok := er.(event.Sizer)
+		if !ok {
+			return 0, 0, errors.New("reader does not support sizing")
+		}
+		o, b, err := s.Size(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		objects += o
+		bytes += b
+	}
+	return objects, bytes, nil
+}
+
 func (r *MultiReader) Close() error {
 	for _, er := range r.Readers {
 		if err := er.Close(); err != nil {
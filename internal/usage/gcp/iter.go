@@ -34,8 +34,8 @@ type WindowIterator struct {
 }
 
 // NewWindowIterator returns an initialized *WindowIterator.
-func NewWindowIterator(bkt *storage.BucketHandle, account string, tr usagetime.Range, window time.Duration) (*WindowIterator, error) {
-	iter, err := NewQueryIterator(account, tr, window)
+func NewWindowIterator(bkt *storage.BucketHandle, account string, tr usagetime.Range, window time.Duration, opts ...usagetime.IteratorOption) (*WindowIterator, error) {
+	iter, err := NewQueryIterator(account, tr, window, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -62,14 +62,14 @@ func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
 // time range. Must be initialized with NewUsageQueryIterator().
 type QueryIterator struct {
 	Account string
-	Iter    *usagetime.WindowIterator
+	Iter    usagetime.Iterator
 }
 
 // NewQueryIterator() returns an initialized *UsageQueryIterator. The
 // start of the time range is inclusive and the end is exclusive to the hour.
 // The time range and window are truncated to the hour.
-func NewQueryIterator(account string, tr usagetime.Range, window time.Duration) (*QueryIterator, error) {
-	iter, err := usagetime.NewWindowIterator(tr, window)
+func NewQueryIterator(account string, tr usagetime.Range, window time.Duration, opts ...usagetime.IteratorOption) (*QueryIterator, error) {
+	iter, err := usagetime.NewIterator(tr, window, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -110,13 +110,13 @@ func query(account string, tr usagetime.Range) *storage.Query {
 			"account=%s/date=%s/hour=%02d/",
 			account,
 			usagetime.FormatDateUTC(tr.Start),
-			tr.Start.Hour(),
+			tr.Start.UTC().Hour(),
 		),
 		EndOffset: fmt.Sprintf(
 			"account=%s/date=%s/hour=%02d/",
 			account,
 			usagetime.FormatDateUTC(tr.End),
-			tr.End.Hour(),
+			tr.End.UTC().Hour(),
 		),
 	}
 }
@@ -30,7 +30,10 @@ import (
 
 var ErrEOF = event.ErrEOF
 
-var _ event.Reader = &QueryEventReader{}
+var (
+	_ event.Reader = &QueryEventReader{}
+	_ event.Sizer  = &QueryEventReader{}
+)
 
 type QueryEventReader struct {
 	Bucket *storage.BucketHandle
@@ -45,6 +48,24 @@ func (r *QueryEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error)
 	return r.reader.Read(ctx)
 }
 
+// Size lists the objects matching the query and returns their number and total
+// size.
+func (r *QueryEventReader) Size(ctx context.Context) (int, int64, error) {
+	objects, bytes := 0, int64(0)
+	it := r.Bucket.Objects(ctx, r.Query)
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return objects, bytes, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		objects++
+		bytes += attrs.Size
+	}
+}
+
 func (r *QueryEventReader) Close() error {
 	if r.reader == nil {
 		return nil
@@ -0,0 +1,303 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package live collects managed resource usage directly from the API servers
+// of control planes, for Spaces that do not export usage to object storage.
+package live
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/upbound/up/internal/fleet"
+	"github.com/upbound/up/internal/resources"
+	"github.com/upbound/up/internal/usage/aggregate"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+const (
+	// mrCountEventName is the name of managed resource count events.
+	mrCountEventName = "kube_managedresource_uid"
+	// categoryManaged is the CRD category of managed resources.
+	categoryManaged = "managed"
+	// pageSize is the number of objects listed per request.
+	pageSize = 500
+
+	errGetControlPlane  = "cannot get control plane"
+	errConnect          = "cannot connect to control plane"
+	errListCRDs         = "cannot list custom resource definitions"
+	errFmtCountResource = "cannot count %s"
+	errFmtAddEvent      = "cannot add event for %s"
+)
+
+var crdResource = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// A Snapshot is the managed resource usage of a set of control planes at a
+// point in time.
+type Snapshot struct {
+	// Time at which the snapshot was taken.
+	Time time.Time `json:"time"`
+	// Report summarizes the counted managed resources. Each control plane
+	// has a single hour of data.
+	Report aggregate.Report `json:"report"`
+	// Events are the counts the report was built from, one per control plane
+	// and GVK.
+	Events []model.MXPGVKEvent `json:"-"`
+	// Failures are the control planes that could not be counted.
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// A Failure records a control plane that could not be counted.
+type Failure struct {
+	ControlPlane string `json:"control_plane"`
+	Error        string `json:"error"`
+}
+
+// Collector counts the managed resources of control planes in a Space.
+type Collector struct {
+	dyn         dynamic.Interface
+	connect     fleet.ConnectFn
+	concurrency int
+	progress    fleet.ResultFn
+	now         func() time.Time
+	log         logging.Logger
+}
+
+// Option modifies a Collector.
+type Option func(*Collector)
+
+// WithConcurrency sets the number of control planes counted at once.
+func WithConcurrency(n int) Option {
+	return func(c *Collector) {
+		c.concurrency = n
+	}
+}
+
+// WithProgress sets a function called as each control plane is counted.
+func WithProgress(fn fleet.ResultFn) Option {
+	return func(c *Collector) {
+		c.progress = fn
+	}
+}
+
+// WithClock sets the function used to timestamp snapshots.
+func WithClock(now func() time.Time) Option {
+	return func(c *Collector) {
+		c.now = now
+	}
+}
+
+// WithLogger sets the logger of the Collector.
+func WithLogger(l logging.Logger) Option {
+	return func(c *Collector) {
+		c.log = l
+	}
+}
+
+// New returns a Collector that selects control planes using the supplied
+// Space client and connects to them using the supplied ConnectFn.
+func New(dyn dynamic.Interface, connect fleet.ConnectFn, opts ...Option) *Collector {
+	c := &Collector{
+		dyn:         dyn,
+		connect:     connect,
+		concurrency: fleet.DefaultConcurrency,
+		progress:    func(fleet.Result) {},
+		now:         time.Now,
+		log:         logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Snapshot counts the managed resources of every control plane matching the
+// supplied selector. Control planes that cannot be counted are recorded as
+// failures of the snapshot rather than returned as an error.
+func (c *Collector) Snapshot(ctx context.Context, s fleet.Selector) (*Snapshot, error) {
+	r := fleet.New(c.dyn,
+		fleet.WithConcurrency(c.concurrency),
+		fleet.WithProgress(c.progress),
+		fleet.WithLogger(c.log),
+	)
+	now := c.now().UTC()
+	op := &count{dyn: c.dyn, connect: c.connect, now: now}
+	rep, err := r.Run(ctx, s, op)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(op.events, func(i, j int) bool {
+		a, b := op.events[i].Tags, op.events[j].Tags
+		if a.MXPID != b.MXPID {
+			return a.MXPID < b.MXPID
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Kind < b.Kind
+	})
+	roll := &aggregate.Rollup{}
+	for _, e := range op.events {
+		if err := roll.Add(e); err != nil {
+			return nil, errors.Wrapf(err, errFmtAddEvent, e.Tags.MXPID)
+		}
+	}
+	snap := &Snapshot{Time: now, Report: roll.Report(), Events: op.events}
+	for _, res := range rep.Failed() {
+		snap.Failures = append(snap.Failures, Failure{ControlPlane: res.Target.String(), Error: res.Err.Error()})
+	}
+	return snap, nil
+}
+
+// count is a fleet operation that counts the managed resources of a control
+// plane.
+type count struct {
+	dyn     dynamic.Interface
+	connect fleet.ConnectFn
+	now     time.Time
+
+	mu     sync.Mutex
+	events []model.MXPGVKEvent
+}
+
+func (o *count) Name() string {
+	return "count managed resources"
+}
+
+func (o *count) Apply(ctx context.Context, t fleet.Target) error {
+	u, err := o.dyn.Resource(fleet.Resource).Namespace(t.Group).Get(ctx, t.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, errGetControlPlane)
+	}
+	id := (&resources.ControlPlane{Unstructured: *u}).GetControlPlaneID()
+	if id == "" {
+		id = t.String()
+	}
+
+	cp, err := o.connect(ctx, t)
+	if err != nil {
+		return errors.Wrap(err, errConnect)
+	}
+	crds, err := listAll(ctx, cp.Resource(crdResource))
+	if err != nil {
+		return errors.Wrap(err, errListCRDs)
+	}
+
+	var events []model.MXPGVKEvent
+	for i := range crds {
+		gvr, kind, ok := managedResource(&crds[i])
+		if !ok {
+			continue
+		}
+		items, err := listAll(ctx, cp.Resource(gvr))
+		if err != nil {
+			return errors.Wrapf(err, errFmtCountResource, gvr.GroupResource())
+		}
+		if len(items) == 0 {
+			continue
+		}
+		events = append(events, model.MXPGVKEvent{
+			Name: mrCountEventName,
+			Tags: model.MXPGVKEventTags{
+				Group:   gvr.Group,
+				Version: gvr.Version,
+				Kind:    kind,
+				MXPID:   id,
+			},
+			Timestamp:    o.now,
+			TimestampEnd: o.now,
+			Value:        float64(len(items)),
+		})
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, events...)
+	return nil
+}
+
+// managedResource returns the resource and kind defined by a CRD if it is in
+// the managed category. Resources are counted at their storage version so
+// that each object is counted once.
+func managedResource(crd *unstructured.Unstructured) (schema.GroupVersionResource, string, bool) {
+	p := fieldpath.Pave(crd.Object)
+	categories, _ := p.GetStringArray("spec.names.categories")
+	managed := false
+	for _, c := range categories {
+		if c == categoryManaged {
+			managed = true
+			break
+		}
+	}
+	if !managed {
+		return schema.GroupVersionResource{}, "", false
+	}
+	group, _ := p.GetString("spec.group")
+	plural, _ := p.GetString("spec.names.plural")
+	kind, _ := p.GetString("spec.names.kind")
+	versions, _ := p.GetValue("spec.versions")
+	vs, _ := versions.([]any)
+	version := ""
+	for _, v := range vs {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		served, _ := m["served"].(bool)
+		storage, _ := m["storage"].(bool)
+		if served && (storage || version == "") {
+			version = name
+		}
+	}
+	if group == "" || plural == "" || kind == "" || version == "" {
+		return schema.GroupVersionResource{}, "", false
+	}
+	return schema.GroupVersionResource{Group: group, Version: version, Resource: plural}, kind, true
+}
+
+// listAll lists every object of a resource, a page at a time.
+func listAll(ctx context.Context, ri dynamic.ResourceInterface) ([]unstructured.Unstructured, error) {
+	var items []unstructured.Unstructured
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		l, err := ri.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, l.Items...)
+		if l.GetContinue() == "" {
+			return items, nil
+		}
+		opts.Continue = l.GetContinue()
+	}
+}
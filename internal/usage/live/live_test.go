@@ -0,0 +1,187 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package live
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/upbound/up/internal/fleet"
+	"github.com/upbound/up/internal/resources"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+var bucketGVR = schema.GroupVersionResource{Group: "s3.aws.upbound.io", Version: "v1beta1", Resource: "buckets"}
+
+func ctp(group, name, id string) *unstructured.Unstructured {
+	c := &resources.ControlPlane{}
+	c.SetGroupVersionKind(resources.ControlPlaneGVK)
+	c.SetNamespace(group)
+	c.SetName(name)
+	if id != "" {
+		c.SetControlPlaneID(id)
+	}
+	return c.GetUnstructured()
+}
+
+func crd(group, kind, plural string, categories []any, versions ...any) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"group": group,
+			"names": map[string]any{
+				"kind":       kind,
+				"plural":     plural,
+				"categories": categories,
+			},
+			"versions": versions,
+		},
+	}}
+	u.SetAPIVersion("apiextensions.k8s.io/v1")
+	u.SetKind("CustomResourceDefinition")
+	u.SetName(plural + "." + group)
+	return u
+}
+
+func version(name string, served, storage bool) map[string]any {
+	return map[string]any{"name": name, "served": served, "storage": storage}
+}
+
+func bucket(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(bucketGVR.GroupVersion().String())
+	u.SetKind("Bucket")
+	u.SetName(name)
+	return u
+}
+
+func newSpace(objs ...runtime.Object) dynamic.Interface {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		fleet.Resource: "ControlPlaneList",
+	}, objs...)
+}
+
+func newControlPlane(objs ...runtime.Object) dynamic.Interface {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdResource: "CustomResourceDefinitionList",
+		bucketGVR:   "BucketList",
+	}, objs...)
+}
+
+func TestSnapshot(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	bucketCRD := crd("s3.aws.upbound.io", "Bucket", "buckets", []any{"crossplane", "managed", "aws"},
+		version("v1beta1", true, true),
+		version("v1beta2", true, false),
+	)
+	claimCRD := crd("acme.io", "Database", "databases", []any{"claim"}, version("v1", true, true))
+	event := func(id string, n int) model.MXPGVKEvent {
+		return model.MXPGVKEvent{
+			Name: mrCountEventName,
+			Tags: model.MXPGVKEventTags{
+				Group:   "s3.aws.upbound.io",
+				Version: "v1beta1",
+				Kind:    "Bucket",
+				MXPID:   id,
+			},
+			Timestamp:    now,
+			TimestampEnd: now,
+			Value:        float64(n),
+		}
+	}
+
+	type want struct {
+		events   []model.MXPGVKEvent
+		failures []Failure
+		mxps     []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		space   dynamic.Interface
+		planes  map[string]dynamic.Interface
+		connect error
+		want    want
+	}{
+		"Counted": {
+			reason: "Managed resources of each control plane should be counted at their storage version, ignoring other CRDs.",
+			space:  newSpace(ctp("prod", "a", "mxp-a"), ctp("prod", "b", "")),
+			planes: map[string]dynamic.Interface{
+				"prod/a": newControlPlane(bucketCRD, claimCRD, bucket("one"), bucket("two")),
+				"prod/b": newControlPlane(bucketCRD, bucket("three")),
+			},
+			want: want{
+				events:   []model.MXPGVKEvent{event("mxp-a", 2), event("prod/b", 1)},
+				failures: nil,
+				mxps:     []string{"mxp-a", "prod/b"},
+			},
+		},
+		"NoManagedResources": {
+			reason: "Control planes without managed resources should have no events.",
+			space:  newSpace(ctp("prod", "a", "mxp-a")),
+			planes: map[string]dynamic.Interface{
+				"prod/a": newControlPlane(bucketCRD, claimCRD),
+			},
+			want: want{},
+		},
+		"ConnectError": {
+			reason:  "Control planes that cannot be connected to should be recorded as failures.",
+			space:   newSpace(ctp("prod", "a", "mxp-a")),
+			connect: errBoom,
+			want: want{
+				failures: []Failure{{ControlPlane: "prod/a", Error: errors.Wrap(errBoom, errConnect).Error()}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			connect := func(_ context.Context, tgt fleet.Target) (dynamic.Interface, error) {
+				if tc.connect != nil {
+					return nil, tc.connect
+				}
+				return tc.planes[tgt.String()], nil
+			}
+			c := New(tc.space, connect, WithClock(func() time.Time { return now }))
+			snap, err := c.Snapshot(context.Background(), fleet.Selector{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nSnapshot(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, snap.Events); diff != "" {
+				t.Errorf("\n%s\nSnapshot(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.failures, snap.Failures); diff != "" {
+				t.Errorf("\n%s\nSnapshot(...): -want failures, +got failures:\n%s", tc.reason, diff)
+			}
+			var mxps []string
+			for _, cp := range snap.Report.ControlPlanes {
+				mxps = append(mxps, cp.MXPID)
+			}
+			if diff := cmp.Diff(tc.want.mxps, mxps); diff != "" {
+				t.Errorf("\n%s\nSnapshot(...): -want MXP IDs, +got MXP IDs:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/upbound/up/internal/usage/event"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	// DefaultQuery counts managed resources per GVK per MXP.
+	DefaultQuery = "count by (mxp_id, customresource_group, customresource_version, customresource_kind) (kube_managedresource_uid)"
+	// DefaultStep is the default resolution of range queries.
+	DefaultStep = time.Minute
+)
+
+var _ event.WindowIterator = &WindowIterator{}
+
+// WindowIterator iterates through readers for windows of usage events from
+// the Prometheus HTTP API. Must be initialized with NewWindowIterator().
+type WindowIterator struct {
+	API   v1.API
+	Query string
+	Step  time.Duration
+	Iter  usagetime.Iterator
+
+	windowOpts []usagetime.IteratorOption
+}
+
+// Option modifies a *WindowIterator.
+type Option func(*WindowIterator)
+
+// WithQuery sets the query used to count managed resources. Each series
+// returned by the query must be labeled with the MXP ID and GVK of the counted
+// resources.
+func WithQuery(q string) Option {
+	return func(i *WindowIterator) {
+		i.Query = q
+	}
+}
+
+// WithStep sets the resolution of range queries.
+func WithStep(step time.Duration) Option {
+	return func(i *WindowIterator) {
+		i.Step = step
+	}
+}
+
+// WithCalendarWindows aligns windows to the calendar boundaries described by
+// spec instead of sizing them by a fixed duration.
+func WithCalendarWindows(spec usagetime.WindowSpec) Option {
+	return func(i *WindowIterator) {
+		i.windowOpts = append(i.windowOpts, usagetime.WithCalendarWindows(spec))
+	}
+}
+
+// NewWindowIterator returns an initialized *WindowIterator.
+func NewWindowIterator(api v1.API, tr usagetime.Range, window time.Duration, opts ...Option) (*WindowIterator, error) {
+	i := &WindowIterator{
+		API:   api,
+		Query: DefaultQuery,
+		Step:  DefaultStep,
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	iter, err := usagetime.NewIterator(tr, window, i.windowOpts...)
+	if err != nil {
+		return nil, err
+	}
+	i.Iter = iter
+	return i, nil
+}
+
+func (i *WindowIterator) More() bool {
+	return i.Iter.More()
+}
+
+func (i *WindowIterator) Next() (event.Reader, usagetime.Range, error) {
+	window, err := i.Iter.Next()
+	if err != nil {
+		return nil, usagetime.Range{}, err
+	}
+	return &QueryRangeEventReader{
+		API:   i.API,
+		Query: i.Query,
+		// Prometheus range queries include their end time. End a step early
+		// so that samples at the start of the next window aren't read twice.
+		Range: v1.Range{Start: window.Start, End: window.End.Add(-i.Step), Step: i.Step},
+	}, window, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prommodel "github.com/prometheus/common/model"
+
+	"github.com/upbound/up/internal/usage/model"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+// fakeAPI returns a fixed result for range queries and records the ranges
+// that were queried.
+type fakeAPI struct {
+	v1.API
+
+	value  prommodel.Value
+	err    error
+	ranges []v1.Range
+}
+
+func (f *fakeAPI) QueryRange(_ context.Context, _ string, r v1.Range, _ ...v1.Option) (prommodel.Value, v1.Warnings, error) {
+	f.ranges = append(f.ranges, r)
+	return f.value, nil, f.err
+}
+
+func TestWindowIterator(t *testing.T) {
+	start := time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC)
+	tags := model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"}
+	metric := prommodel.Metric{
+		labelMXPID:   "mxp1",
+		labelGroup:   "example.com",
+		labelVersion: "v1",
+		labelKind:    "Thing",
+	}
+	errBoom := errors.New("boom")
+
+	type want struct {
+		events []model.MXPGVKEvent
+		ranges []v1.Range
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		api    *fakeAPI
+		want   want
+	}{
+		"Samples": {
+			reason: "Each sample is read as an event covering one step.",
+			api: &fakeAPI{value: prommodel.Matrix{
+				{Metric: metric, Values: []prommodel.SamplePair{
+					{Timestamp: prommodel.TimeFromUnixNano(start.UnixNano()), Value: 2},
+					{Timestamp: prommodel.TimeFromUnixNano(start.Add(time.Minute).UnixNano()), Value: 3},
+				}},
+			}},
+			want: want{
+				events: []model.MXPGVKEvent{
+					{Name: mrCountEventName, Tags: tags, Timestamp: start, TimestampEnd: start.Add(time.Minute), Value: 2},
+					{Name: mrCountEventName, Tags: tags, Timestamp: start.Add(time.Minute), TimestampEnd: start.Add(2 * time.Minute), Value: 3},
+				},
+				ranges: []v1.Range{{Start: start, End: start.Add(59 * time.Minute), Step: time.Minute}},
+			},
+		},
+		"UnexpectedResult": {
+			reason: "A query that does not return a matrix should return an error.",
+			api:    &fakeAPI{value: prommodel.Vector{}},
+			want: want{
+				events: []model.MXPGVKEvent{},
+				ranges: []v1.Range{{Start: start, End: start.Add(59 * time.Minute), Step: time.Minute}},
+				err:    errors.New("expected query result of type matrix, got vector"),
+			},
+		},
+		"QueryError": {
+			reason: "Errors querying Prometheus should be returned.",
+			api:    &fakeAPI{err: errBoom},
+			want: want{
+				events: []model.MXPGVKEvent{},
+				ranges: []v1.Range{{Start: start, End: start.Add(59 * time.Minute), Step: time.Minute}},
+				err:    errBoom,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			iter, err := NewWindowIterator(tc.api, usagetime.Range{Start: start, End: start.Add(time.Hour)}, time.Hour)
+			if err != nil {
+				t.Fatalf("NewWindowIterator(...): %s", err)
+			}
+			r, window, err := iter.Next()
+			if err != nil {
+				t.Fatalf("Next(): %s", err)
+			}
+			if diff := cmp.Diff(usagetime.Range{Start: start, End: start.Add(time.Hour)}, window); diff != "" {
+				t.Errorf("\n%s\nNext(): -want window, +got window:\n%s", tc.reason, diff)
+			}
+
+			events := []model.MXPGVKEvent{}
+			for {
+				var e model.MXPGVKEvent
+				e, err = r.Read(context.Background())
+				if err != nil {
+					break
+				}
+				events = append(events, e)
+			}
+			if errors.Is(err, ErrEOF) {
+				err = nil
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRead(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, events); diff != "" {
+				t.Errorf("\n%s\nRead(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ranges, tc.api.ranges); diff != "" {
+				t.Errorf("\n%s\nQueryRange(...): -want ranges, +got ranges:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWindowIteratorMonthWindow(t *testing.T) {
+	start := time.Date(2006, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2006, 6, 1, 0, 0, 0, 0, time.UTC)
+	api := &fakeAPI{value: prommodel.Matrix{}}
+
+	iter, err := NewWindowIterator(api, usagetime.Range{Start: start, End: end}, time.Hour, WithCalendarWindows(usagetime.WindowSpec{Unit: usagetime.WindowUnitMonth}))
+	if err != nil {
+		t.Fatalf("NewWindowIterator(...): %s", err)
+	}
+	r, _, err := iter.Next()
+	if err != nil {
+		t.Fatalf("Next(): %s", err)
+	}
+	if _, err := r.Read(context.Background()); !errors.Is(err, ErrEOF) {
+		t.Fatalf("Read(...): want ErrEOF, got %v", err)
+	}
+
+	// A 31 day window holds 44,640 one minute steps, so it must be split into
+	// queries of at most MaxPointsPerQuery points.
+	want := []v1.Range{
+		{Start: start, End: start.Add(10999 * time.Minute), Step: time.Minute},
+		{Start: start.Add(11000 * time.Minute), End: start.Add(21999 * time.Minute), Step: time.Minute},
+		{Start: start.Add(22000 * time.Minute), End: start.Add(32999 * time.Minute), Step: time.Minute},
+		{Start: start.Add(33000 * time.Minute), End: start.Add(43999 * time.Minute), Step: time.Minute},
+		{Start: start.Add(44000 * time.Minute), End: end.Add(-time.Minute), Step: time.Minute},
+	}
+	if diff := cmp.Diff(want, api.ranges); diff != "" {
+		t.Errorf("QueryRange(...): -want ranges, +got ranges:\n%s", diff)
+	}
+}
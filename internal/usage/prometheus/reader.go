@@ -0,0 +1,140 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prommodel "github.com/prometheus/common/model"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+)
+
+var ErrEOF = event.ErrEOF
+
+const (
+	// mrCountEventName is the name of managed resource count events read
+	// from object storage.
+	mrCountEventName = "kube_managedresource_uid"
+
+	labelMXPID   = "mxp_id"
+	labelGroup   = "customresource_group"
+	labelVersion = "customresource_version"
+	labelKind    = "customresource_kind"
+
+	errFmtUnexpectedResult = "expected query result of type matrix, got %s"
+)
+
+// MaxPointsPerQuery is the maximum number of points per series requested by
+// a single range query. Prometheus refuses queries for more than 11,000.
+const MaxPointsPerQuery = 11000
+
+var _ event.Reader = &QueryRangeEventReader{}
+
+// QueryRangeEventReader reads managed resource count events from the result
+// of a Prometheus range query. Each sample is read as an event covering one
+// step. Ranges with more than MaxPointsPerQuery steps are split into several
+// queries.
+type QueryRangeEventReader struct {
+	API   v1.API
+	Query string
+	Range v1.Range
+
+	events []model.MXPGVKEvent
+	done   bool
+}
+
+func (r *QueryRangeEventReader) Read(ctx context.Context) (model.MXPGVKEvent, error) {
+	if !r.done {
+		events, err := r.query(ctx)
+		if err != nil {
+			return model.MXPGVKEvent{}, err
+		}
+		r.events = events
+		r.done = true
+	}
+	if len(r.events) == 0 {
+		return model.MXPGVKEvent{}, ErrEOF
+	}
+	e := r.events[0]
+	r.events = r.events[1:]
+	return e, nil
+}
+
+func (r *QueryRangeEventReader) Close() error {
+	return nil
+}
+
+func (r *QueryRangeEventReader) query(ctx context.Context) ([]model.MXPGVKEvent, error) {
+	events := []model.MXPGVKEvent{}
+	for _, rng := range splitRange(r.Range) {
+		v, _, err := r.API.QueryRange(ctx, r.Query, rng)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := v.(prommodel.Matrix)
+		if !ok {
+			return nil, fmt.Errorf(errFmtUnexpectedResult, v.Type())
+		}
+		events = append(events, r.matrixEvents(m)...)
+	}
+	return events, nil
+}
+
+// splitRange splits r into consecutive ranges of at most MaxPointsPerQuery
+// points. Range queries include their end time, so each range starts a step
+// after the previous one ends.
+func splitRange(r v1.Range) []v1.Range {
+	if r.Step <= 0 {
+		return []v1.Range{r}
+	}
+	span := r.Step * (MaxPointsPerQuery - 1)
+	out := []v1.Range{}
+	for start := r.Start; !start.After(r.End); {
+		end := start.Add(span)
+		if end.After(r.End) {
+			end = r.End
+		}
+		out = append(out, v1.Range{Start: start, End: end, Step: r.Step})
+		start = end.Add(r.Step)
+	}
+	return out
+}
+
+func (r *QueryRangeEventReader) matrixEvents(m prommodel.Matrix) []model.MXPGVKEvent {
+	events := []model.MXPGVKEvent{}
+	for _, ss := range m {
+		tags := model.MXPGVKEventTags{
+			MXPID:   string(ss.Metric[labelMXPID]),
+			Group:   string(ss.Metric[labelGroup]),
+			Version: string(ss.Metric[labelVersion]),
+			Kind:    string(ss.Metric[labelKind]),
+		}
+		for _, s := range ss.Values {
+			ts := s.Timestamp.Time().UTC()
+			events = append(events, model.MXPGVKEvent{
+				Name:         mrCountEventName,
+				Tags:         tags,
+				Timestamp:    ts,
+				TimestampEnd: ts.Add(r.Range.Step),
+				Value:        float64(s.Value),
+			})
+		}
+	}
+	return events
+}
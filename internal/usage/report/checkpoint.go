@@ -0,0 +1,119 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/json"
+	"io/fs"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	errReadCheckpoint      = "error reading checkpoint"
+	errWriteCheckpoint     = "error writing checkpoint"
+	errCheckpointTimeRange = "checkpoint time range does not match collection time range"
+
+	checkpointFileMode = 0600
+)
+
+// Checkpoint records the progress of a usage collection. A collection can be
+// resumed from a checkpoint by skipping every window that ends at or before
+// the end of the checkpoint's window.
+type Checkpoint struct {
+	// TimeRange is the time range of the collection.
+	TimeRange usagetime.Range `json:"time_range"`
+	// Window is the last window that was fully processed.
+	Window usagetime.Range `json:"window"`
+}
+
+// IsZero returns true if the checkpoint does not record any progress.
+func (c Checkpoint) IsZero() bool {
+	return c.Window.End.IsZero()
+}
+
+// Done returns true if window was fully processed before the checkpoint was
+// recorded.
+func (c Checkpoint) Done(window usagetime.Range) bool {
+	if c.IsZero() {
+		return false
+	}
+	return !window.End.After(c.Window.End)
+}
+
+// CheckpointWriter writes checkpoints.
+type CheckpointWriter interface {
+	// WriteCheckpoint records a checkpoint.
+	WriteCheckpoint(Checkpoint) error
+}
+
+var _ CheckpointWriter = &CheckpointFile{}
+
+// CheckpointFile reads and writes a checkpoint from a JSON file. Writes
+// replace the file atomically so an interrupted write never leaves a corrupt
+// checkpoint behind.
+type CheckpointFile struct {
+	FS   afero.Fs
+	Path string
+}
+
+// NewCheckpointFile returns a *CheckpointFile for a file at path on the OS
+// filesystem.
+func NewCheckpointFile(path string) *CheckpointFile {
+	return &CheckpointFile{FS: afero.NewOsFs(), Path: path}
+}
+
+// ReadCheckpoint returns the checkpoint recorded in the file. Returns a zero
+// checkpoint if the file does not exist.
+func (f *CheckpointFile) ReadCheckpoint() (Checkpoint, error) {
+	b, err := afero.ReadFile(f.FS, f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Checkpoint{}, nil
+	}
+	if err != nil {
+		return Checkpoint{}, errors.Wrap(err, errReadCheckpoint)
+	}
+	cp := Checkpoint{}
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return Checkpoint{}, errors.Wrap(err, errReadCheckpoint)
+	}
+	return cp, nil
+}
+
+// WriteCheckpoint writes a checkpoint to the file.
+func (f *CheckpointFile) WriteCheckpoint(cp Checkpoint) error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errWriteCheckpoint)
+	}
+	tmp := f.Path + ".tmp"
+	if err := afero.WriteFile(f.FS, tmp, b, checkpointFileMode); err != nil {
+		return errors.Wrap(err, errWriteCheckpoint)
+	}
+	return errors.Wrap(f.FS.Rename(tmp, f.Path), errWriteCheckpoint)
+}
+
+// Remove removes the checkpoint file. It is not an error if the file does not
+// exist.
+func (f *CheckpointFile) Remove() error {
+	err := f.FS.Remove(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestCheckpointFile(t *testing.T) {
+	cp := Checkpoint{
+		TimeRange: usagetime.Range{
+			Start: time.Date(2006, 05, 01, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2006, 06, 01, 0, 0, 0, 0, time.UTC),
+		},
+		Window: usagetime.Range{
+			Start: time.Date(2006, 05, 21, 19, 0, 0, 0, time.UTC),
+			End:   time.Date(2006, 05, 21, 20, 0, 0, 0, time.UTC),
+		},
+	}
+
+	type want struct {
+		cp  Checkpoint
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		write  []Checkpoint
+		want   want
+	}{
+		"NotExist": {
+			reason: "Reading a file that does not exist returns a zero checkpoint.",
+			want:   want{cp: Checkpoint{}},
+		},
+		"RoundTrip": {
+			reason: "The last written checkpoint is read back.",
+			write:  []Checkpoint{{TimeRange: cp.TimeRange}, cp},
+			want:   want{cp: cp},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := &CheckpointFile{FS: afero.NewMemMapFs(), Path: "/checkpoint.json"}
+			for _, w := range tc.write {
+				if err := f.WriteCheckpoint(w); err != nil {
+					t.Fatalf("WriteCheckpoint(...): %s", err)
+				}
+			}
+			got, err := f.ReadCheckpoint()
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadCheckpoint(): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cp, got); diff != "" {
+				t.Errorf("\n%s\nReadCheckpoint(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCheckpointDone(t *testing.T) {
+	cp := Checkpoint{Window: usagetime.Range{
+		Start: time.Date(2006, 05, 04, 03, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 05, 04, 04, 0, 0, 0, time.UTC),
+	}}
+	cases := map[string]struct {
+		reason string
+		cp     Checkpoint
+		window usagetime.Range
+		want   bool
+	}{
+		"ZeroCheckpoint": {
+			reason: "No window is done for a zero checkpoint.",
+			window: cp.Window,
+			want:   false,
+		},
+		"EarlierWindow": {
+			reason: "A window ending before the checkpoint is done.",
+			cp:     cp,
+			window: usagetime.Range{Start: cp.Window.Start.Add(-time.Hour), End: cp.Window.Start},
+			want:   true,
+		},
+		"SameWindow": {
+			reason: "The checkpoint's window is done.",
+			cp:     cp,
+			window: cp.Window,
+			want:   true,
+		},
+		"LaterWindow": {
+			reason: "A window ending after the checkpoint is not done.",
+			cp:     cp,
+			window: usagetime.Range{Start: cp.Window.End, End: cp.Window.End.Add(time.Hour)},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.cp.Done(tc.window)); diff != "" {
+				t.Errorf("\n%s\nDone(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
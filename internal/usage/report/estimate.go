@@ -0,0 +1,84 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/upbound/up/internal/usage/event"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	errEstimate          = "error estimating usage"
+	errReaderNotSizeable = "reader does not support sizing"
+)
+
+// Estimate records the number and total size of the objects that a usage
+// collection would read.
+type Estimate struct {
+	Windows []WindowEstimate `json:"windows"`
+	Objects int              `json:"objects"`
+	Bytes   int64            `json:"bytes"`
+}
+
+// WindowEstimate records the number and total size of the objects in a window.
+type WindowEstimate struct {
+	Window  usagetime.Range `json:"window"`
+	Objects int             `json:"objects"`
+	Bytes   int64           `json:"bytes"`
+}
+
+// Estimate lists the objects in each window of Iter that overlaps tr and
+// returns their number and total size without downloading them. Iter is
+// consumed, so a new collector must be used to collect usage afterwards.
+func (c *Collector) Estimate(ctx context.Context, tr usagetime.Range) (*Estimate, error) {
+	est := &Estimate{Windows: []WindowEstimate{}}
+	for c.Iter.More() {
+		r, window, err := c.Iter.Next()
+		if err != nil {
+			return nil, errors.Wrap(err, errEstimate)
+		}
+		if !window.Start.Before(tr.End) || !window.End.After(tr.Start) {
+			if err := r.Close(); err != nil {
+				return nil, errors.Wrap(err, errEstimate)
+			}
+			continue
+		}
+		we, err := estimateWindow(ctx, r, window)
+		if err != nil {
+			return nil, errors.Wrap(err, errEstimate)
+		}
+		est.Windows = append(est.Windows, we)
+		est.Objects += we.Objects
+		est.Bytes += we.Bytes
+	}
+	return est, nil
+}
+
+func estimateWindow(ctx context.Context, r event.Reader, window usagetime.Range) (WindowEstimate, error) {
+	defer r.Close() // nolint:errcheck
+	s, ok := r.(event.Sizer)
+	if !ok {
+		return WindowEstimate{}, errors.New(errReaderNotSizeable)
+	}
+	objects, bytes, err := s.Size(ctx)
+	if err != nil {
+		return WindowEstimate{}, err
+	}
+	return WindowEstimate{Window: window, Objects: objects, Bytes: bytes}, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/event/reader"
+	usagetesting "github.com/upbound/up/internal/usage/testing"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestCollectorEstimate(t *testing.T) {
+	w1 := usagetime.Range{
+		Start: time.Date(2006, 05, 04, 03, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 05, 04, 04, 0, 0, 0, time.UTC),
+	}
+	w2 := usagetime.Range{
+		Start: time.Date(2006, 05, 04, 04, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 05, 04, 05, 0, 0, 0, time.UTC),
+	}
+	errBoom := errors.New("boom")
+
+	type want struct {
+		est *Estimate
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		iter   *usagetesting.MockWindowIterator
+		tr     usagetime.Range
+		want   want
+	}{
+		"AllWindows": {
+			reason: "Objects are counted and sized for every window in the range.",
+			iter: &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+				{Reader: &reader.MultiReader{Readers: []event.Reader{
+					&usagetesting.MockReader{Objects: 2, Bytes: 100},
+					&usagetesting.MockReader{Objects: 1, Bytes: 50},
+				}}, Window: w1},
+				{Reader: &usagetesting.MockReader{Objects: 4, Bytes: 400}, Window: w2},
+			}},
+			tr: usagetime.Range{Start: w1.Start, End: w2.End},
+			want: want{est: &Estimate{
+				Windows: []WindowEstimate{
+					{Window: w1, Objects: 3, Bytes: 150},
+					{Window: w2, Objects: 4, Bytes: 400},
+				},
+				Objects: 7,
+				Bytes:   550,
+			}},
+		},
+		"PartialRange": {
+			reason: "Windows outside the range are skipped.",
+			iter: &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+				{Reader: &usagetesting.MockReader{Objects: 2, Bytes: 100}, Window: w1},
+				{Reader: &usagetesting.MockReader{Objects: 4, Bytes: 400}, Window: w2},
+			}},
+			tr: w2,
+			want: want{est: &Estimate{
+				Windows: []WindowEstimate{{Window: w2, Objects: 4, Bytes: 400}},
+				Objects: 4,
+				Bytes:   400,
+			}},
+		},
+		"SizeError": {
+			reason: "Errors listing objects are returned.",
+			iter: &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+				{Reader: &usagetesting.MockReader{SizeErr: errBoom}, Window: w1},
+			}},
+			tr:   w1,
+			want: want{err: errors.Wrap(errBoom, errEstimate)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &Collector{Iter: tc.iter}
+			est, err := c.Estimate(context.Background(), tc.tr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nEstimate(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.est, est); diff != "" {
+				t.Errorf("\n%s\nEstimate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+)
+
+// MetaFileSuffix is appended to the name of a CSV usage report to name the
+// file its metadata is written to.
+const MetaFileSuffix = ".meta.json"
+
+// Header is the header row of a CSV usage report.
+var Header = []string{
+	"schema_version",
+	"account",
+	"name",
+	"mxp_id",
+	"group",
+	"version",
+	"kind",
+	"timestamp",
+	"timestamp_end",
+	"value",
+}
+
+// An Option modifies a Writer.
+type Option func(*Writer)
+
+// WithMetaWriter writes the report metadata to the supplied writer when the
+// Writer is closed, in the same JSON format as the metadata of a tar archive
+// report. CSV has no place for metadata, so it is usually written to a file
+// named after the report with MetaFileSuffix.
+func WithMetaWriter(w io.Writer) Option {
+	return func(cw *Writer) {
+		cw.mw = w
+	}
+}
+
+var _ event.Writer = &Writer{}
+
+// Writer writes Upbound usage events for a single account to a usage report in
+// CSV format. Each row records the schema version of the report in its first
+// column. Must be initialized with NewWriter(). Callers must call Close() on
+// the writer when finished writing to it.
+type Writer struct {
+	cw   *csv.Writer
+	mw   io.Writer
+	meta report.Meta
+}
+
+// NewWriter returns an initialized *Writer. The header row is written
+// immediately.
+func NewWriter(w io.Writer, meta report.Meta, opts ...Option) (*Writer, error) {
+	cw := NewAppendWriter(w, meta, opts...)
+	if err := cw.cw.Write(Header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// NewAppendWriter returns an initialized *Writer that appends rows to an
+// existing report. The header row is not written.
+func NewAppendWriter(w io.Writer, meta report.Meta, opts ...Option) *Writer {
+	cw := &Writer{cw: csv.NewWriter(w), meta: meta}
+	for _, o := range opts {
+		o(cw)
+	}
+	return cw
+}
+
+// Write writes an Upbound usage event as a CSV row.
+func (w *Writer) Write(e model.MXPGVKEvent) error {
+	return w.cw.Write([]string{
+		strconv.Itoa(report.SchemaVersion),
+		w.meta.UpboundAccount,
+		e.Name,
+		e.Tags.MXPID,
+		e.Tags.Group,
+		e.Tags.Version,
+		e.Tags.Kind,
+		formatTimestamp(e.Timestamp),
+		formatTimestamp(e.TimestampEnd),
+		strconv.FormatFloat(e.Value, 'f', -1, 64),
+	})
+}
+
+// Flush writes buffered rows to the underlying writer.
+func (w *Writer) Flush() error {
+	w.cw.Flush()
+	return w.cw.Error()
+}
+
+// Close flushes buffered rows to the underlying writer and writes the report
+// metadata, if a metadata writer was supplied.
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if w.mw == nil {
+		return nil
+	}
+	b, err := json.MarshalIndent(w.meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.mw.Write(b)
+	return err
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/event/reader"
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestWriter(t *testing.T) {
+	meta := report.Meta{UpboundAccount: "test-account"}
+
+	cases := map[string]struct {
+		reason string
+		append bool
+		events []model.MXPGVKEvent
+		want   string
+	}{
+		"Empty": {
+			reason: "A report without events only has a header.",
+			want:   "schema_version,account,name,mxp_id,group,version,kind,timestamp,timestamp_end,value\n",
+		},
+		"Populated": {
+			reason: "Each event is written as a row with the account from the report metadata.",
+			events: []model.MXPGVKEvent{
+				{
+					Name:         "max_resource_count_per_gvk_per_mxp",
+					Value:        float64(7),
+					Timestamp:    time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					TimestampEnd: time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+					Tags: model.MXPGVKEventTags{
+						MXPID:   "mxp1",
+						Group:   "example.com",
+						Version: "v1",
+						Kind:    "Thing",
+					},
+				},
+				{
+					Name:  "max_resource_count_per_gvk_per_mxp",
+					Value: float64(1.5),
+					Tags: model.MXPGVKEventTags{
+						MXPID:   "mxp2",
+						Group:   "example.com",
+						Version: "v1",
+						Kind:    "Thing,WithComma",
+					},
+				},
+			},
+			want: "schema_version,account,name,mxp_id,group,version,kind,timestamp,timestamp_end,value\n" +
+				"1,test-account,max_resource_count_per_gvk_per_mxp,mxp1,example.com,v1,Thing,2006-05-04T03:00:00Z,2006-05-04T04:00:00Z,7\n" +
+				"1,test-account,max_resource_count_per_gvk_per_mxp,mxp2,example.com,v1,\"Thing,WithComma\",,,1.5\n",
+		},
+		"Append": {
+			reason: "An append writer writes rows without a header.",
+			append: true,
+			events: []model.MXPGVKEvent{
+				{
+					Name:  "max_resource_count_per_gvk_per_mxp",
+					Value: float64(3),
+					Tags: model.MXPGVKEventTags{
+						MXPID:   "mxp1",
+						Group:   "example.com",
+						Version: "v1",
+						Kind:    "Thing",
+					},
+				},
+			},
+			want: "1,test-account,max_resource_count_per_gvk_per_mxp,mxp1,example.com,v1,Thing,,,3\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := NewAppendWriter(buf, meta)
+			if !tc.append {
+				var err error
+				w, err = NewWriter(buf, meta)
+				if err != nil {
+					t.Fatalf("NewWriter(...): %s", err)
+				}
+			}
+			for _, e := range tc.events {
+				if err := w.Write(e); err != nil {
+					t.Fatalf("Write(...): %s", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close(): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("\n%s\nWriter output: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriterMeta(t *testing.T) {
+	meta := report.Meta{
+		UpboundAccount: "test-account",
+		TimeRange: usagetime.Range{
+			Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2006, 6, 4, 0, 0, 0, 0, time.UTC),
+		},
+		CollectedAt: time.Date(2006, 6, 4, 9, 0, 0, 0, time.UTC),
+		Windows:     &report.Windows{Unit: usagetime.WindowUnitDay, Count: 1, OffsetHours: 6, Timezone: "Asia/Kolkata"},
+	}
+	// The metadata is written like in a tar archive report.
+	want := `{
+  "account": "test-account",
+  "time_range": {
+    "start": "2006-05-04T00:00:00Z",
+    "end": "2006-06-04T00:00:00Z"
+  },
+  "collected_at": "2006-06-04T09:00:00Z",
+  "windows": {
+    "unit": "day",
+    "count": 1,
+    "offset_hours": 6,
+    "timezone": "Asia/Kolkata"
+  },
+  "dedup": {
+    "read": 10,
+    "dropped": 3
+  }
+}`
+
+	cases := map[string]struct {
+		reason string
+		append bool
+	}{
+		"New": {
+			reason: "A writer should write the metadata with dedup stats recorded before it is closed.",
+		},
+		"Append": {
+			reason: "An append writer should also write the metadata.",
+			append: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := meta
+			m.Dedup = &reader.DedupStats{}
+			mbuf := &bytes.Buffer{}
+			w := NewAppendWriter(&bytes.Buffer{}, m, WithMetaWriter(mbuf))
+			if !tc.append {
+				var err error
+				w, err = NewWriter(&bytes.Buffer{}, m, WithMetaWriter(mbuf))
+				if err != nil {
+					t.Fatalf("NewWriter(...): %s", err)
+				}
+			}
+			if mbuf.Len() != 0 {
+				t.Errorf("\n%s\nmetadata written before Close()", tc.reason)
+			}
+			*m.Dedup = reader.DedupStats{Read: 10, Dropped: 3}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close(): %s", err)
+			}
+			if diff := cmp.Diff(want, mbuf.String()); diff != "" {
+				t.Errorf("\n%s\nmetadata: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+)
+
+const (
+	magic     = "PAR1"
+	createdBy = "up"
+
+	// MetaKeySchemaVersion is the key of the file metadata entry recording the
+	// schema version of the report.
+	MetaKeySchemaVersion = "upbound.usage.schema_version"
+	// MetaKeyAccount is the key of the file metadata entry recording the
+	// Upbound account of the report.
+	MetaKeyAccount = "upbound.usage.account"
+	// MetaKeyTimeRangeStart is the key of the file metadata entry recording
+	// the start of the time range of the report.
+	MetaKeyTimeRangeStart = "upbound.usage.time_range.start"
+	// MetaKeyTimeRangeEnd is the key of the file metadata entry recording the
+	// end of the time range of the report.
+	MetaKeyTimeRangeEnd = "upbound.usage.time_range.end"
+	// MetaKeyCollectedAt is the key of the file metadata entry recording when
+	// the report was collected.
+	MetaKeyCollectedAt = "upbound.usage.collected_at"
+	// MetaKeyWindowsUnit is the key of the file metadata entry recording the
+	// unit of the calendar windows usage is aggregated over.
+	MetaKeyWindowsUnit = "upbound.usage.windows.unit"
+	// MetaKeyWindowsCount is the key of the file metadata entry recording the
+	// number of units in each calendar window.
+	MetaKeyWindowsCount = "upbound.usage.windows.count"
+	// MetaKeyWindowsOffsetHours is the key of the file metadata entry
+	// recording the offset of the calendar windows in hours.
+	MetaKeyWindowsOffsetHours = "upbound.usage.windows.offset_hours"
+	// MetaKeyWindowsTimezone is the key of the file metadata entry recording
+	// the timezone of the calendar windows.
+	MetaKeyWindowsTimezone = "upbound.usage.windows.timezone"
+	// MetaKeyDedupRead is the key of the file metadata entry recording the
+	// number of events read while dropping duplicates.
+	MetaKeyDedupRead = "upbound.usage.dedup.read"
+	// MetaKeyDedupDropped is the key of the file metadata entry recording the
+	// number of events dropped as duplicates.
+	MetaKeyDedupDropped = "upbound.usage.dedup.dropped"
+)
+
+// Parquet physical types.
+const (
+	typeInt64     int32 = 2
+	typeDouble    int32 = 5
+	typeByteArray int32 = 6
+)
+
+// Parquet converted types. convertedNone marks a column without a converted
+// type.
+const (
+	convertedNone            int32 = -1
+	convertedUTF8            int32 = 0
+	convertedTimestampMillis int32 = 9
+)
+
+const (
+	repetitionRequired int32 = 0
+	encodingPlain      int32 = 0
+	encodingRLE        int32 = 3
+	codecUncompressed  int32 = 0
+	pageTypeData       int32 = 0
+)
+
+// column is a leaf column of the report schema.
+type column struct {
+	name      string
+	typ       int32
+	converted int32
+	value     func(e model.MXPGVKEvent, w *bytes.Buffer)
+}
+
+func byteArray(s string, w *bytes.Buffer) {
+	_ = binary.Write(w, binary.LittleEndian, uint32(len(s)))
+	w.WriteString(s)
+}
+
+func int64Millis(t time.Time, w *bytes.Buffer) {
+	_ = binary.Write(w, binary.LittleEndian, t.UnixMilli())
+}
+
+// columns is the schema of a Parquet usage report. Changes to the columns
+// must be accompanied by a change to report.SchemaVersion.
+var columns = []column{
+	{name: "account", typ: typeByteArray, converted: convertedUTF8, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { byteArray(e.Tags.UpboundAccount, w) }},
+	{name: "name", typ: typeByteArray, converted: convertedUTF8, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { byteArray(e.Name, w) }},
+	{name: "mxp_id", typ: typeByteArray, converted: convertedUTF8, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { byteArray(e.Tags.MXPID, w) }},
+	{name: "group", typ: typeByteArray, converted: convertedUTF8, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { byteArray(e.Tags.Group, w) }},
+	{name: "version", typ: typeByteArray, converted: convertedUTF8, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { byteArray(e.Tags.Version, w) }},
+	{name: "kind", typ: typeByteArray, converted: convertedUTF8, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { byteArray(e.Tags.Kind, w) }},
+	{name: "timestamp", typ: typeInt64, converted: convertedTimestampMillis, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { int64Millis(e.Timestamp, w) }},
+	{name: "timestamp_end", typ: typeInt64, converted: convertedTimestampMillis, value: func(e model.MXPGVKEvent, w *bytes.Buffer) { int64Millis(e.TimestampEnd, w) }},
+	{name: "value", typ: typeDouble, converted: convertedNone, value: func(e model.MXPGVKEvent, w *bytes.Buffer) {
+		_ = binary.Write(w, binary.LittleEndian, math.Float64bits(e.Value))
+	}},
+}
+
+// DefaultRowGroupSize is the default number of events written to each row
+// group.
+const DefaultRowGroupSize = 10000
+
+// An Option modifies a Writer.
+type Option func(*Writer)
+
+// WithRowGroupSize sets the number of events buffered before they are written
+// as a row group.
+func WithRowGroupSize(n int) Option {
+	return func(w *Writer) {
+		w.rowGroupSize = n
+	}
+}
+
+var _ event.Writer = &Writer{}
+
+// Writer writes Upbound usage events for a single account to a usage report in
+// Parquet format. Events are buffered in memory and written as a row group
+// once enough events are buffered, so only one row group is held in memory at
+// a time. The schema version and report metadata, including windows and dedup
+// stats set before Close(), are recorded in the file's key-value metadata. Must be initialized with NewWriter(). Callers must call
+// Close() on the writer when finished writing to it.
+type Writer struct {
+	w            io.Writer
+	meta         report.Meta
+	rowGroupSize int
+
+	offset    int64
+	events    []model.MXPGVKEvent
+	rowGroups []rowGroup
+}
+
+// NewWriter returns an initialized *Writer.
+func NewWriter(w io.Writer, meta report.Meta, opts ...Option) (*Writer, error) {
+	pw := &Writer{w: w, meta: meta, rowGroupSize: DefaultRowGroupSize}
+	for _, o := range opts {
+		o(pw)
+	}
+	return pw, nil
+}
+
+// Write buffers an Upbound usage event, writing the buffered events as a row
+// group if enough events are buffered.
+func (w *Writer) Write(e model.MXPGVKEvent) error {
+	e.Tags.UpboundAccount = w.meta.UpboundAccount
+	w.events = append(w.events, e)
+	if len(w.events) < w.rowGroupSize {
+		return nil
+	}
+	return w.flush()
+}
+
+// chunk records the location of a column chunk in the file.
+type chunk struct {
+	offset int64
+	size   int64
+}
+
+// rowGroup records the location of the column chunks of a row group.
+type rowGroup struct {
+	rows   int
+	chunks []chunk
+}
+
+// Close writes any buffered events and the file metadata to the underlying
+// writer.
+func (w *Writer) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	footer := w.fileMetaData()
+	buf := &bytes.Buffer{}
+	buf.Write(footer)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(footer)))
+	buf.WriteString(magic)
+	return w.write(buf.Bytes())
+}
+
+// flush writes the buffered events as a row group.
+func (w *Writer) flush() error {
+	if len(w.events) == 0 {
+		return nil
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	rg := rowGroup{rows: len(w.events), chunks: make([]chunk, len(columns))}
+	for i, col := range columns {
+		data := &bytes.Buffer{}
+		for _, e := range w.events {
+			col.value(e, data)
+		}
+		header := pageHeader(len(w.events), data.Len())
+		rg.chunks[i] = chunk{offset: w.offset, size: int64(len(header) + data.Len())}
+		if err := w.write(header); err != nil {
+			return err
+		}
+		if err := w.write(data.Bytes()); err != nil {
+			return err
+		}
+	}
+	w.rowGroups = append(w.rowGroups, rg)
+	w.events = w.events[:0]
+	return nil
+}
+
+// writeMagic writes the magic number that starts a Parquet file, if nothing
+// was written yet.
+func (w *Writer) writeMagic() error {
+	if w.offset > 0 {
+		return nil
+	}
+	return w.write([]byte(magic))
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+func pageHeader(numValues, size int) []byte {
+	e := newCompactEncoder()
+	e.I32(1, pageTypeData)
+	e.I32(2, int32(size))
+	e.I32(3, int32(size))
+	e.BeginStruct(5)
+	e.I32(1, int32(numValues))
+	e.I32(2, encodingPlain)
+	e.I32(3, encodingRLE)
+	e.I32(4, encodingRLE)
+	e.EndStruct()
+	e.Stop()
+	return e.Bytes()
+}
+
+func (w *Writer) fileMetaData() []byte {
+	e := newCompactEncoder()
+	e.I32(1, 1)
+	e.StructList(2, len(columns)+1, func(i int) {
+		if i == 0 {
+			e.String(4, "schema")
+			e.I32(5, int32(len(columns)))
+			return
+		}
+		col := columns[i-1]
+		e.I32(1, col.typ)
+		e.I32(3, repetitionRequired)
+		e.String(4, col.name)
+		if col.converted != convertedNone {
+			e.I32(6, col.converted)
+		}
+	})
+	rows := 0
+	for _, rg := range w.rowGroups {
+		rows += rg.rows
+	}
+	e.I64(3, int64(rows))
+
+	e.StructList(4, len(w.rowGroups), func(g int) {
+		rg := w.rowGroups[g]
+		var total int64
+		e.StructList(1, len(columns), func(i int) {
+			col, c := columns[i], rg.chunks[i]
+			total += c.size
+			e.I64(2, c.offset)
+			e.BeginStruct(3)
+			e.I32(1, col.typ)
+			e.I32List(2, []int32{encodingPlain, encodingRLE})
+			e.StringList(3, []string{col.name})
+			e.I32(4, codecUncompressed)
+			e.I64(5, int64(rg.rows))
+			e.I64(6, c.size)
+			e.I64(7, c.size)
+			e.I64(9, c.offset)
+			e.EndStruct()
+		})
+		e.I64(2, total)
+		e.I64(3, int64(rg.rows))
+	})
+
+	kv := [][2]string{
+		{MetaKeySchemaVersion, strconv.Itoa(report.SchemaVersion)},
+		{MetaKeyAccount, w.meta.UpboundAccount},
+		{MetaKeyTimeRangeStart, formatTimestamp(w.meta.TimeRange.Start)},
+		{MetaKeyTimeRangeEnd, formatTimestamp(w.meta.TimeRange.End)},
+		{MetaKeyCollectedAt, formatTimestamp(w.meta.CollectedAt)},
+	}
+	// Windows and dedup stats are only recorded if set, like in the metadata
+	// of a tar archive report.
+	if win := w.meta.Windows; win != nil {
+		kv = append(kv,
+			[2]string{MetaKeyWindowsUnit, string(win.Unit)},
+			[2]string{MetaKeyWindowsCount, strconv.Itoa(win.Count)},
+			[2]string{MetaKeyWindowsOffsetHours, strconv.Itoa(win.OffsetHours)},
+			[2]string{MetaKeyWindowsTimezone, win.Timezone},
+		)
+	}
+	if d := w.meta.Dedup; d != nil {
+		kv = append(kv,
+			[2]string{MetaKeyDedupRead, strconv.Itoa(d.Read)},
+			[2]string{MetaKeyDedupDropped, strconv.Itoa(d.Dropped)},
+		)
+	}
+	e.StructList(5, len(kv), func(i int) {
+		e.String(1, kv[i][0])
+		e.String(2, kv[i][1])
+	})
+	e.String(6, createdBy)
+	e.Stop()
+	return e.Bytes()
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,542 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/event/reader"
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestWriter(t *testing.T) {
+	meta := report.Meta{
+		UpboundAccount: "test-account",
+		TimeRange: usagetime.Range{
+			Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+			End:   time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+		},
+		CollectedAt: time.Date(2006, 5, 4, 6, 0, 0, 0, time.UTC),
+	}
+
+	cases := map[string]struct {
+		reason string
+		events []model.MXPGVKEvent
+	}{
+		"Empty": {
+			reason: "A report without events is a valid file without row groups.",
+		},
+		"Populated": {
+			reason: "A report with events is a valid file containing the event values.",
+			events: []model.MXPGVKEvent{
+				{
+					Name:         "max_resource_count_per_gvk_per_mxp",
+					Value:        float64(7),
+					Timestamp:    time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					TimestampEnd: time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+					Tags: model.MXPGVKEventTags{
+						MXPID:   "mxp1",
+						Group:   "example.com",
+						Version: "v1",
+						Kind:    "Thing",
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := NewWriter(buf, meta)
+			if err != nil {
+				t.Fatalf("NewWriter(...): %s", err)
+			}
+			for _, e := range tc.events {
+				if err := w.Write(e); err != nil {
+					t.Fatalf("Write(...): %s", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close(): %s", err)
+			}
+
+			b := buf.Bytes()
+			if diff := cmp.Diff(magic, string(b[:4])); diff != "" {
+				t.Errorf("\n%s\nLeading magic: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(magic, string(b[len(b)-4:])); diff != "" {
+				t.Errorf("\n%s\nTrailing magic: -want, +got:\n%s", tc.reason, diff)
+			}
+			footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8 : len(b)-4]))
+			footer := b[len(b)-8-footerLen : len(b)-8]
+			for _, want := range []string{MetaKeySchemaVersion, "test-account", "mxp_id", "2006-05-04T03:00:00Z"} {
+				if !bytes.Contains(footer, []byte(want)) {
+					t.Errorf("\n%s\nFooter does not contain %q", tc.reason, want)
+				}
+			}
+			for _, e := range tc.events {
+				data := b[4 : len(b)-8-footerLen]
+				for _, want := range []string{e.Tags.MXPID, e.Tags.Kind, e.Name} {
+					if !bytes.Contains(data, []byte(want)) {
+						t.Errorf("\n%s\nColumn data does not contain %q", tc.reason, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	meta := report.Meta{
+		UpboundAccount: "test-account",
+		TimeRange: usagetime.Range{
+			Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+			End:   time.Date(2006, 5, 4, 8, 0, 0, 0, time.UTC),
+		},
+		CollectedAt: time.Date(2006, 5, 4, 9, 0, 0, 0, time.UTC),
+	}
+	event := func(hour int, kind string, v float64) model.MXPGVKEvent {
+		return model.MXPGVKEvent{
+			Name:         "max_resource_count_per_gvk_per_mxp",
+			Value:        v,
+			Timestamp:    time.Date(2006, 5, 4, hour, 0, 0, 0, time.UTC),
+			TimestampEnd: time.Date(2006, 5, 4, hour+1, 0, 0, 0, time.UTC),
+			Tags: model.MXPGVKEventTags{
+				UpboundAccount: "test-account",
+				MXPID:          "mxp1",
+				Group:          "example.com",
+				Version:        "v1",
+				Kind:           kind,
+			},
+		}
+	}
+	wantMeta := map[string]string{
+		MetaKeySchemaVersion:  "1",
+		MetaKeyAccount:        "test-account",
+		MetaKeyTimeRangeStart: "2006-05-04T03:00:00Z",
+		MetaKeyTimeRangeEnd:   "2006-05-04T08:00:00Z",
+		MetaKeyCollectedAt:    "2006-05-04T09:00:00Z",
+	}
+
+	type want struct {
+		rowGroups int
+		events    []model.MXPGVKEvent
+	}
+	cases := map[string]struct {
+		reason string
+		events []model.MXPGVKEvent
+		want   want
+	}{
+		"Empty": {
+			reason: "A report without events should have no row groups.",
+			want:   want{events: []model.MXPGVKEvent{}},
+		},
+		"SingleRowGroup": {
+			reason: "Events that fit in one row group should be read back.",
+			events: []model.MXPGVKEvent{event(3, "Thing", 7)},
+			want: want{
+				rowGroups: 1,
+				events:    []model.MXPGVKEvent{event(3, "Thing", 7)},
+			},
+		},
+		"MultipleRowGroups": {
+			reason: "Events should be streamed as several row groups and read back in order.",
+			events: []model.MXPGVKEvent{
+				event(3, "Thing", 7),
+				event(4, "Thing", 2.5),
+				event(5, "Other", 0),
+				event(6, "Thing", 1e6),
+				event(7, "Other", 3),
+			},
+			want: want{
+				rowGroups: 3,
+				events: []model.MXPGVKEvent{
+					event(3, "Thing", 7),
+					event(4, "Thing", 2.5),
+					event(5, "Other", 0),
+					event(6, "Thing", 1e6),
+					event(7, "Other", 3),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := NewWriter(buf, meta, WithRowGroupSize(2))
+			if err != nil {
+				t.Fatalf("NewWriter(...): %s", err)
+			}
+			for _, e := range tc.events {
+				// Account tags are set from the report metadata.
+				e.Tags.UpboundAccount = ""
+				if err := w.Write(e); err != nil {
+					t.Fatalf("Write(...): %s", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close(): %s", err)
+			}
+
+			f, err := readFile(buf.Bytes())
+			if err != nil {
+				t.Fatalf("\n%s\nreadFile(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.rowGroups, f.rowGroups); diff != "" {
+				t.Errorf("\n%s\nrow groups: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(int64(len(tc.want.events)), f.numRows); diff != "" {
+				t.Errorf("\n%s\nnum_rows: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(wantMeta, f.meta); diff != "" {
+				t.Errorf("\n%s\nkey-value metadata: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, f.events); diff != "" {
+				t.Errorf("\n%s\nevents: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriterMeta(t *testing.T) {
+	base := report.Meta{
+		UpboundAccount: "test-account",
+		TimeRange: usagetime.Range{
+			Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2006, 6, 4, 0, 0, 0, 0, time.UTC),
+		},
+		CollectedAt: time.Date(2006, 6, 4, 9, 0, 0, 0, time.UTC),
+	}
+	baseMeta := map[string]string{
+		MetaKeySchemaVersion:  "1",
+		MetaKeyAccount:        "test-account",
+		MetaKeyTimeRangeStart: "2006-05-04T00:00:00Z",
+		MetaKeyTimeRangeEnd:   "2006-06-04T00:00:00Z",
+		MetaKeyCollectedAt:    "2006-06-04T09:00:00Z",
+	}
+	withMeta := func(kv map[string]string) map[string]string {
+		m := map[string]string{}
+		for k, v := range baseMeta {
+			m[k] = v
+		}
+		for k, v := range kv {
+			m[k] = v
+		}
+		return m
+	}
+
+	cases := map[string]struct {
+		reason string
+		meta   func(m report.Meta) report.Meta
+		want   map[string]string
+	}{
+		"NoWindowsOrDedup": {
+			reason: "Windows and dedup stats should not be recorded if unset.",
+			meta:   func(m report.Meta) report.Meta { return m },
+			want:   baseMeta,
+		},
+		"WindowsAndDedup": {
+			reason: "Windows and dedup stats should be recorded like in the metadata of a tar archive report.",
+			meta: func(m report.Meta) report.Meta {
+				m.Windows = &report.Windows{Unit: usagetime.WindowUnitDay, Count: 1, OffsetHours: 6, Timezone: "Asia/Kolkata"}
+				m.Dedup = &reader.DedupStats{Read: 10, Dropped: 3}
+				return m
+			},
+			want: withMeta(map[string]string{
+				MetaKeyWindowsUnit:        "day",
+				MetaKeyWindowsCount:       "1",
+				MetaKeyWindowsOffsetHours: "6",
+				MetaKeyWindowsTimezone:    "Asia/Kolkata",
+				MetaKeyDedupRead:          "10",
+				MetaKeyDedupDropped:       "3",
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := NewWriter(buf, tc.meta(base))
+			if err != nil {
+				t.Fatalf("NewWriter(...): %s", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close(): %s", err)
+			}
+			f, err := readFile(buf.Bytes())
+			if err != nil {
+				t.Fatalf("\n%s\nreadFile(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, f.meta); diff != "" {
+				t.Errorf("\n%s\nkey-value metadata: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// parquetFile is a Parquet usage report read by readFile.
+type parquetFile struct {
+	numRows   int64
+	rowGroups int
+	meta      map[string]string
+	events    []model.MXPGVKEvent
+}
+
+// readFile reads a Parquet usage report. It is written against the Parquet
+// and Thrift compact protocol specifications rather than the writer's
+// encoder, so that it can catch files only the writer understands. Only
+// uncompressed, PLAIN encoded, required columns are supported.
+func readFile(b []byte) (*parquetFile, error) { //nolint:gocyclo // Walking the file metadata is long but simple.
+	if len(b) < 12 || string(b[:4]) != "PAR1" || string(b[len(b)-4:]) != "PAR1" {
+		return nil, fmt.Errorf("missing magic number")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	d := &thriftDecoder{b: b[len(b)-8-footerLen : len(b)-8]}
+	fmd, err := d.Struct()
+	if err != nil {
+		return nil, fmt.Errorf("file metadata: %w", err)
+	}
+
+	// The first schema element is the root, followed by the leaf columns.
+	schema := fmd[2].([]any)
+	types := map[string]int32{}
+	for _, el := range schema[1:] {
+		el := el.(map[int16]any)
+		if el[3].(int32) != 0 {
+			return nil, fmt.Errorf("column %s is not required", el[4])
+		}
+		types[string(el[4].([]byte))] = el[1].(int32)
+	}
+
+	f := &parquetFile{numRows: fmd[3].(int64), meta: map[string]string{}, events: []model.MXPGVKEvent{}}
+	for _, kv := range fmd[5].([]any) {
+		kv := kv.(map[int16]any)
+		f.meta[string(kv[1].([]byte))] = string(kv[2].([]byte))
+	}
+	rgs, _ := fmd[4].([]any)
+	f.rowGroups = len(rgs)
+	for _, rg := range rgs {
+		rg := rg.(map[int16]any)
+		rows := int(rg[3].(int64))
+		values := map[string][]any{}
+		for _, cc := range rg[1].([]any) {
+			cmd := cc.(map[int16]any)[3].(map[int16]any)
+			if cmd[4].(int32) != 0 {
+				return nil, fmt.Errorf("column chunk is compressed")
+			}
+			name := string(cmd[3].([]any)[0].([]byte))
+			vs, err := readPage(b, cmd[9].(int64), types[name], rows)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", name, err)
+			}
+			values[name] = vs
+		}
+		for i := 0; i < rows; i++ {
+			f.events = append(f.events, model.MXPGVKEvent{
+				Name:         values["name"][i].(string),
+				Value:        values["value"][i].(float64),
+				Timestamp:    time.UnixMilli(values["timestamp"][i].(int64)).UTC(),
+				TimestampEnd: time.UnixMilli(values["timestamp_end"][i].(int64)).UTC(),
+				Tags: model.MXPGVKEventTags{
+					UpboundAccount: values["account"][i].(string),
+					MXPID:          values["mxp_id"][i].(string),
+					Group:          values["group"][i].(string),
+					Version:        values["version"][i].(string),
+					Kind:           values["kind"][i].(string),
+				},
+			})
+		}
+	}
+	return f, nil
+}
+
+// readPage reads the PLAIN encoded values of the data page at offset.
+func readPage(b []byte, offset int64, typ int32, rows int) ([]any, error) {
+	d := &thriftDecoder{b: b[offset:]}
+	ph, err := d.Struct()
+	if err != nil {
+		return nil, fmt.Errorf("page header: %w", err)
+	}
+	if ph[1].(int32) != 0 {
+		return nil, fmt.Errorf("page is not a data page")
+	}
+	dph := ph[5].(map[int16]any)
+	if n := int(dph[1].(int32)); n != rows {
+		return nil, fmt.Errorf("page has %d values, want %d", n, rows)
+	}
+	if dph[2].(int32) != 0 {
+		return nil, fmt.Errorf("page is not PLAIN encoded")
+	}
+	data := d.b[d.pos : d.pos+int(ph[3].(int32))]
+
+	vs := make([]any, 0, rows)
+	for i := 0; i < rows; i++ {
+		switch typ {
+		case 2: // INT64
+			vs = append(vs, int64(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		case 5: // DOUBLE
+			vs = append(vs, math.Float64frombits(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		case 6: // BYTE_ARRAY
+			n := binary.LittleEndian.Uint32(data)
+			vs = append(vs, string(data[4:4+n]))
+			data = data[4+n:]
+		default:
+			return nil, fmt.Errorf("unsupported type %d", typ)
+		}
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%d unread bytes in page", len(data))
+	}
+	return vs, nil
+}
+
+// thriftDecoder decodes Thrift compact protocol structs into maps of field ID
+// to value.
+type thriftDecoder struct {
+	b   []byte
+	pos int
+}
+
+func (d *thriftDecoder) byte() (byte, error) {
+	if d.pos >= len(d.b) {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	c := d.b[d.pos]
+	d.pos++
+	return c, nil
+}
+
+func (d *thriftDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.b[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varint")
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *thriftDecoder) zigzag() (int64, error) {
+	v, err := d.uvarint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+// Struct decodes a struct.
+func (d *thriftDecoder) Struct() (map[int16]any, error) {
+	s := map[int16]any{}
+	var id int16
+	for {
+		h, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		if h == 0 {
+			return s, nil
+		}
+		if delta := int16(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			v, err := d.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		typ := h & 0x0f
+		switch typ {
+		case 1, 2: // Boolean fields encode their value in the type.
+			s[id] = typ == 1
+		default:
+			v, err := d.value(typ)
+			if err != nil {
+				return nil, err
+			}
+			s[id] = v
+		}
+	}
+}
+
+func (d *thriftDecoder) value(typ byte) (any, error) { //nolint:gocyclo // A switch over every type.
+	switch typ {
+	case 1, 2: // BOOL in a list
+		c, err := d.byte()
+		return c == 1, err
+	case 3: // BYTE
+		c, err := d.byte()
+		return int8(c), err
+	case 4: // I16
+		v, err := d.zigzag()
+		return int16(v), err
+	case 5: // I32
+		v, err := d.zigzag()
+		return int32(v), err
+	case 6: // I64
+		return d.zigzag()
+	case 7: // DOUBLE
+		if d.pos+8 > len(d.b) {
+			return nil, fmt.Errorf("unexpected end of data")
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.b[d.pos:]))
+		d.pos += 8
+		return v, nil
+	case 8: // BINARY
+		n, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if d.pos+int(n) > len(d.b) {
+			return nil, fmt.Errorf("unexpected end of data")
+		}
+		v := d.b[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return v, nil
+	case 9, 10: // LIST, SET
+		h, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(h >> 4)
+		if n == 15 {
+			if n, err = d.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		vs := make([]any, 0, n)
+		for i := uint64(0); i < n; i++ {
+			v, err := d.value(h & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+		return vs, nil
+	case 12: // STRUCT
+		return d.Struct()
+	default:
+		return nil, fmt.Errorf("unsupported thrift type %d", typ)
+	}
+}
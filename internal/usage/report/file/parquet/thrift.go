@@ -0,0 +1,151 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type identifiers.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// compactEncoder encodes Thrift structs with the compact protocol, which is
+// the protocol used for Parquet page headers and file metadata. Only the
+// types needed to write Parquet metadata are supported.
+type compactEncoder struct {
+	buf bytes.Buffer
+	// lastField is a stack of the last field ID written in each open struct.
+	lastField []int16
+}
+
+func newCompactEncoder() *compactEncoder {
+	return &compactEncoder{lastField: []int16{0}}
+}
+
+// Bytes returns the encoded bytes.
+func (e *compactEncoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+func (e *compactEncoder) fieldHeader(id int16, typ byte) {
+	last := e.lastField[len(e.lastField)-1]
+	if delta := id - last; delta > 0 && delta <= 15 {
+		e.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.buf.WriteByte(typ)
+		e.varint(int64(id))
+	}
+	e.lastField[len(e.lastField)-1] = id
+}
+
+func (e *compactEncoder) uvarint(v uint64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(b, v)
+	e.buf.Write(b[:n])
+}
+
+// varint writes a zigzag encoded varint.
+func (e *compactEncoder) varint(v int64) {
+	e.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (e *compactEncoder) binary(b []byte) {
+	e.uvarint(uint64(len(b)))
+	e.buf.Write(b)
+}
+
+// I32 writes an i32 field.
+func (e *compactEncoder) I32(id int16, v int32) {
+	e.fieldHeader(id, thriftI32)
+	e.varint(int64(v))
+}
+
+// I64 writes an i64 field.
+func (e *compactEncoder) I64(id int16, v int64) {
+	e.fieldHeader(id, thriftI64)
+	e.varint(v)
+}
+
+// String writes a string field.
+func (e *compactEncoder) String(id int16, v string) {
+	e.fieldHeader(id, thriftBinary)
+	e.binary([]byte(v))
+}
+
+// BeginStruct writes the header of a struct field. Must be followed by a call
+// to EndStruct.
+func (e *compactEncoder) BeginStruct(id int16) {
+	e.fieldHeader(id, thriftStruct)
+	e.beginStruct()
+}
+
+// EndStruct ends the innermost open struct.
+func (e *compactEncoder) EndStruct() {
+	e.buf.WriteByte(0)
+	e.lastField = e.lastField[:len(e.lastField)-1]
+}
+
+func (e *compactEncoder) beginStruct() {
+	e.lastField = append(e.lastField, 0)
+}
+
+func (e *compactEncoder) listHeader(id int16, typ byte, size int) {
+	e.fieldHeader(id, thriftList)
+	if size < 15 {
+		e.buf.WriteByte(byte(size)<<4 | typ)
+		return
+	}
+	e.buf.WriteByte(0xf0 | typ)
+	e.uvarint(uint64(size))
+}
+
+// I32List writes a list<i32> field.
+func (e *compactEncoder) I32List(id int16, vs []int32) {
+	e.listHeader(id, thriftI32, len(vs))
+	for _, v := range vs {
+		e.varint(int64(v))
+	}
+}
+
+// StringList writes a list<string> field.
+func (e *compactEncoder) StringList(id int16, vs []string) {
+	e.listHeader(id, thriftBinary, len(vs))
+	for _, v := range vs {
+		e.binary([]byte(v))
+	}
+}
+
+// StructList writes a list<struct> field. fn is called to write the fields of
+// each element.
+func (e *compactEncoder) StructList(id int16, size int, fn func(i int)) {
+	e.listHeader(id, thriftStruct, size)
+	for i := 0; i < size; i++ {
+		e.beginStruct()
+		fn(i)
+		e.EndStruct()
+	}
+}
+
+// Stop ends the top-level struct.
+func (e *compactEncoder) Stop() {
+	e.buf.WriteByte(0)
+}
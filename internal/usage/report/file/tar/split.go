@@ -0,0 +1,216 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tar
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	indexSuffix = ".index.json"
+
+	errCreatePart = "error creating archive part"
+	errClosePart  = "error closing archive part"
+	errWriteIndex = "error writing archive index"
+)
+
+// Index lists the parts of a usage report that was split across multiple
+// archives.
+type Index struct {
+	Meta        report.Meta `json:"meta"`
+	Compression Compression `json:"compression"`
+	Parts       []IndexPart `json:"parts"`
+}
+
+// IndexPart describes a single archive of a split usage report.
+type IndexPart struct {
+	// File is the name of the archive, relative to the index.
+	File string `json:"file"`
+	// Windows are the windows of time with events in the archive, in the order
+	// they were written.
+	Windows []usagetime.Range `json:"windows"`
+	// Events is the number of events in the archive.
+	Events int `json:"events"`
+}
+
+// SplitOption modifies a *SplitWriter.
+type SplitOption func(*SplitWriter)
+
+// WithCompression sets the compression of each archive.
+func WithCompression(c Compression) SplitOption {
+	return func(w *SplitWriter) {
+		w.compression = c
+	}
+}
+
+// WithMaxPartSize sets the size in bytes after which a new archive is started.
+// The size is measured on uncompressed usage data, and archives are only split
+// between windows, so an archive may exceed the size by up to one window of
+// events. A size of zero disables splitting.
+func WithMaxPartSize(size int) SplitOption {
+	return func(w *SplitWriter) {
+		w.maxPartSize = size
+	}
+}
+
+// IndexFile returns the name of the index written by a SplitWriter for
+// archives with the supplied name.
+func IndexFile(name string) string {
+	return name + indexSuffix
+}
+
+var _ event.Writer = &SplitWriter{}
+
+// SplitWriter writes Upbound usage events for a single account to one or more
+// tar archives named <name>.part-NNNN<ext> in a directory, along with an index
+// named <name>.index.json listing the windows of time in each archive. Events
+// must be written in window order. Must be initialized with NewSplitWriter().
+// Callers must call Close() on the writer when finished writing to it.
+type SplitWriter struct {
+	fs          afero.Fs
+	dir         string
+	name        string
+	compression Compression
+	maxPartSize int
+
+	index Index
+	part  *part
+}
+
+// part is an open archive.
+type part struct {
+	closers []io.Closer
+	w       *Writer
+}
+
+// NewSplitWriter returns an initialized *SplitWriter.
+func NewSplitWriter(fs afero.Fs, dir, name string, meta report.Meta, opts ...SplitOption) (*SplitWriter, error) {
+	w := &SplitWriter{
+		fs:          fs,
+		dir:         dir,
+		name:        name,
+		compression: CompressionGzip,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	if _, err := NewCompressor(io.Discard, w.compression); err != nil {
+		return nil, err
+	}
+	w.index = Index{Meta: meta, Compression: w.compression, Parts: []IndexPart{}}
+	return w, nil
+}
+
+// Write writes an Upbound usage event to the current archive. A new archive
+// is started if the event starts a new window and the current archive has
+// reached the maximum part size.
+func (w *SplitWriter) Write(e model.MXPGVKEvent) error {
+	window := usagetime.Range{Start: e.Timestamp, End: e.TimestampEnd}
+	newWindow := true
+	if w.part != nil {
+		windows := w.index.Parts[len(w.index.Parts)-1].Windows
+		newWindow = len(windows) == 0 || !windowEqual(windows[len(windows)-1], window)
+	}
+	if w.part != nil && newWindow && w.maxPartSize > 0 && w.part.w.Size() >= w.maxPartSize {
+		if err := w.closePart(); err != nil {
+			return err
+		}
+	}
+	if w.part == nil {
+		if err := w.openPart(); err != nil {
+			return err
+		}
+	}
+
+	ip := &w.index.Parts[len(w.index.Parts)-1]
+	if newWindow {
+		ip.Windows = append(ip.Windows, window)
+	}
+	ip.Events++
+	return w.part.w.Write(e)
+}
+
+// Close closes the current archive and writes the index. An empty archive is
+// written if no events were written.
+func (w *SplitWriter) Close() error {
+	if len(w.index.Parts) == 0 {
+		if err := w.openPart(); err != nil {
+			return err
+		}
+	}
+	if err := w.closePart(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(w.index, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errWriteIndex)
+	}
+	return errors.Wrap(afero.WriteFile(w.fs, filepath.Join(w.dir, IndexFile(w.name)), b, mode), errWriteIndex)
+}
+
+func (w *SplitWriter) openPart() error {
+	file := fmt.Sprintf("%s.part-%04d%s", w.name, len(w.index.Parts)+1, w.compression.Extension())
+	f, err := w.fs.Create(filepath.Join(w.dir, file))
+	if err != nil {
+		return errors.Wrap(err, errCreatePart)
+	}
+	c, err := NewCompressor(f, w.compression)
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, errCreatePart)
+	}
+	tw := tar.NewWriter(c)
+	rw, err := NewWriter(tw, w.index.Meta)
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, errCreatePart)
+	}
+	w.part = &part{w: rw, closers: []io.Closer{tw, c, f}}
+	w.index.Parts = append(w.index.Parts, IndexPart{File: file, Windows: []usagetime.Range{}})
+	return nil
+}
+
+func (w *SplitWriter) closePart() error {
+	if w.part == nil {
+		return nil
+	}
+	p := w.part
+	w.part = nil
+	if err := p.w.Close(); err != nil {
+		return errors.Wrap(err, errClosePart)
+	}
+	for _, c := range p.closers {
+		if err := c.Close(); err != nil {
+			return errors.Wrap(err, errClosePart)
+		}
+	}
+	return nil
+}
+
+func windowEqual(a, b usagetime.Range) bool {
+	return a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
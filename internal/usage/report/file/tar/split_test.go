@@ -0,0 +1,192 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tar
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestSplitWriter(t *testing.T) {
+	meta := report.Meta{UpboundAccount: "test-account"}
+	w1 := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+	}
+	w2 := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+	}
+	events := []model.MXPGVKEvent{
+		{Name: "a", Timestamp: w1.Start, TimestampEnd: w1.End},
+		{Name: "b", Timestamp: w1.Start, TimestampEnd: w1.End},
+		{Name: "c", Timestamp: w2.Start, TimestampEnd: w2.End},
+	}
+
+	type args struct {
+		opts   []SplitOption
+		events []model.MXPGVKEvent
+	}
+	type want struct {
+		index *Index
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnsupportedCompression": {
+			reason: "An unsupported compression should return an error.",
+			args: args{
+				opts: []SplitOption{WithCompression("lz4")},
+			},
+			want: want{err: errors.New(`compression "lz4" is not supported`)},
+		},
+		"NoEvents": {
+			reason: "An empty archive is written if no events are written.",
+			args:   args{},
+			want: want{index: &Index{
+				Meta:        meta,
+				Compression: CompressionGzip,
+				Parts: []IndexPart{
+					{File: "report.part-0001.tar.gz", Windows: []usagetime.Range{}},
+				},
+			}},
+		},
+		"NoSplitting": {
+			reason: "All events are written to a single archive without a maximum part size.",
+			args: args{
+				opts:   []SplitOption{WithCompression(CompressionZstd)},
+				events: events,
+			},
+			want: want{index: &Index{
+				Meta:        meta,
+				Compression: CompressionZstd,
+				Parts: []IndexPart{
+					{File: "report.part-0001.tar.zst", Windows: []usagetime.Range{w1, w2}, Events: 3},
+				},
+			}},
+		},
+		"SplitBetweenWindows": {
+			reason: "A new archive is started at a window boundary once the maximum part size is reached.",
+			args: args{
+				opts:   []SplitOption{WithCompression(CompressionNone), WithMaxPartSize(1)},
+				events: events,
+			},
+			want: want{index: &Index{
+				Meta:        meta,
+				Compression: CompressionNone,
+				Parts: []IndexPart{
+					{File: "report.part-0001.tar", Windows: []usagetime.Range{w1}, Events: 2},
+					{File: "report.part-0002.tar", Windows: []usagetime.Range{w2}, Events: 1},
+				},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			w, err := NewSplitWriter(fs, "/out", "report", meta, tc.args.opts...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nNewSplitWriter(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			for _, e := range tc.args.events {
+				if err := w.Write(e); err != nil {
+					t.Fatalf("Write(...): %s", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close(): %s", err)
+			}
+
+			b, err := afero.ReadFile(fs, "/out/report.index.json")
+			if err != nil {
+				t.Fatalf("ReadFile(...): %s", err)
+			}
+			got := &Index{}
+			if err := json.Unmarshal(b, got); err != nil {
+				t.Fatalf("Unmarshal(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.index, got); diff != "" {
+				t.Errorf("\n%s\nIndex: -want, +got:\n%s", tc.reason, diff)
+			}
+
+			for _, p := range got.Parts {
+				names := readArchiveNames(t, fs, "/out/"+p.File, got.Compression)
+				if diff := cmp.Diff([]string{MetaFilename, UsageFilename}, names); diff != "" {
+					t.Errorf("\n%s\nArchive %s: -want files, +got files:\n%s", tc.reason, p.File, diff)
+				}
+			}
+		})
+	}
+}
+
+func readArchiveNames(t *testing.T, fs afero.Fs, path string, c Compression) []string {
+	t.Helper()
+	f, err := fs.Open(path)
+	if err != nil {
+		t.Fatalf("Open(...): %s", err)
+	}
+	defer f.Close() // nolint:errcheck
+
+	var r io.Reader = f
+	switch c {
+	case CompressionGzip:
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("gzip.NewReader(...): %s", err)
+		}
+		r = gr
+	case CompressionZstd:
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			t.Fatalf("zstd.NewReader(...): %s", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	names := []string{}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return names
+		}
+		if err != nil {
+			t.Fatalf("tar.Reader.Next(): %s", err)
+		}
+		names = append(names, h.Name)
+	}
+}
@@ -25,9 +25,13 @@ import (
 )
 
 const (
-	metaFilename  = "report/meta.json"
-	usageFilename = "report/usage.json"
-	mode          = 0644
+	// MetaFilename is the name of the usage report metadata file in an
+	// archive.
+	MetaFilename = "report/meta.json"
+	// UsageFilename is the name of the usage data file in an archive.
+	UsageFilename = "report/usage.json"
+
+	mode = 0644
 )
 
 // Writer writes Upbound usage events for a single account to a usage report in
@@ -56,6 +60,11 @@ func (w *Writer) Write(e model.MXPGVKEvent) error {
 	return w.ee.Encode(e)
 }
 
+// Size returns the number of bytes of encoded usage data written so far.
+func (w *Writer) Size() int {
+	return w.buf.Len()
+}
+
 // Close closes the writer.
 func (w *Writer) Close() error {
 	if err := w.ee.Close(); err != nil {
@@ -74,7 +83,7 @@ func writeMeta(tw *tar.Writer, meta report.Meta) error {
 		return err
 	}
 	if err := tw.WriteHeader(&tar.Header{
-		Name: metaFilename,
+		Name: MetaFilename,
 		Mode: mode,
 		Size: int64(len(b)),
 	}); err != nil {
@@ -87,7 +96,7 @@ func writeMeta(tw *tar.Writer, meta report.Meta) error {
 // writeUsage writes usage data to a *tar.Writer.
 func writeUsage(tw *tar.Writer, b []byte) error {
 	if err := tw.WriteHeader(&tar.Header{
-		Name: usageFilename,
+		Name: UsageFilename,
 		Mode: mode,
 		Size: int64(len(b)),
 	}); err != nil {
@@ -0,0 +1,43 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"github.com/upbound/up/internal/metrics"
+	"github.com/upbound/up/internal/usage/model"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+var _ Progress = &MetricsProgress{}
+
+// MetricsProgress is a Progress that records processed windows and events in
+// a metrics.Registry, labelled with the storage they were read from.
+type MetricsProgress struct {
+	Registry *metrics.Registry
+	Source   string
+}
+
+// OnWindowStart does nothing.
+func (p *MetricsProgress) OnWindowStart(usagetime.Range) {}
+
+// OnObject records that an event was read.
+func (p *MetricsProgress) OnObject(usagetime.Range, model.MXPGVKEvent) {
+	p.Registry.EventsRead(p.Source, 1)
+}
+
+// OnWindowDone records that a window was processed.
+func (p *MetricsProgress) OnWindowDone(usagetime.Range) {
+	p.Registry.WindowProcessed(p.Source)
+}
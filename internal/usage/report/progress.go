@@ -0,0 +1,45 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"github.com/upbound/up/internal/usage/model"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+// Progress receives progress updates from a Collector.
+type Progress interface {
+	// OnWindowStart is called before events are read from a window.
+	OnWindowStart(window usagetime.Range)
+	// OnObject is called for each event read from a window.
+	OnObject(window usagetime.Range, e model.MXPGVKEvent)
+	// OnWindowDone is called after a window is fully processed or skipped
+	// while resuming.
+	OnWindowDone(window usagetime.Range)
+}
+
+var _ Progress = NopProgress{}
+
+// NopProgress is a Progress that ignores all updates.
+type NopProgress struct{}
+
+// OnWindowStart does nothing.
+func (NopProgress) OnWindowStart(usagetime.Range) {}
+
+// OnObject does nothing.
+func (NopProgress) OnObject(usagetime.Range, model.MXPGVKEvent) {}
+
+// OnWindowDone does nothing.
+func (NopProgress) OnWindowDone(usagetime.Range) {}
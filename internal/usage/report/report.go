@@ -22,19 +22,67 @@ import (
 
 	"github.com/upbound/up/internal/usage/aggregate"
 	"github.com/upbound/up/internal/usage/event"
+	"github.com/upbound/up/internal/usage/event/reader"
 	usagetime "github.com/upbound/up/internal/usage/time"
 )
 
 const (
-	errReadEvents  = "error reading events"
-	errWriteEvents = "error writing events"
+	errReadEvents   = "error reading events"
+	errWriteEvents  = "error writing events"
+	errLoadTimezone = "error loading window timezone"
 )
 
+// SchemaVersion is the version of the schema of tabular usage reports. It must
+// be incremented whenever the columns of a tabular report change.
+const SchemaVersion = 1
+
 // Meta contains metadata for a usage report.
 type Meta struct {
 	UpboundAccount string          `json:"account"`
 	TimeRange      usagetime.Range `json:"time_range"`
 	CollectedAt    time.Time       `json:"collected_at"`
+	// Windows are the windows that usage is aggregated over. Reports without
+	// windows are aggregated over hour windows in UTC.
+	Windows *Windows `json:"windows,omitempty"`
+	// Dedup records the events dropped as duplicates, if duplicates were
+	// dropped while collecting the report.
+	Dedup *reader.DedupStats `json:"dedup,omitempty"`
+}
+
+// Windows describes calendar windows that usage is aggregated over.
+type Windows struct {
+	Unit        usagetime.WindowUnit `json:"unit"`
+	Count       int                  `json:"count,omitempty"`
+	OffsetHours int                  `json:"offset_hours,omitempty"`
+	Timezone    string               `json:"timezone"`
+}
+
+// NewWindows returns the report metadata for the windows described by spec.
+func NewWindows(spec usagetime.WindowSpec) *Windows {
+	w := &Windows{
+		Unit:        spec.Unit,
+		Count:       spec.Count,
+		OffsetHours: int(spec.Offset / time.Hour),
+		Timezone:    time.UTC.String(),
+	}
+	if spec.Location != nil {
+		w.Timezone = spec.Location.String()
+	}
+	return w
+}
+
+// Spec returns the calendar windows described by the metadata.
+func (w *Windows) Spec() (usagetime.WindowSpec, error) {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return usagetime.WindowSpec{}, errors.Wrap(err, errLoadTimezone)
+	}
+	return usagetime.WindowSpec{
+		Unit:     w.Unit,
+		Count:    w.Count,
+		Offset:   time.Duration(w.OffsetHours) * time.Hour,
+		Location: loc,
+	}, nil
 }
 
 // MaxResourceCountPerGVKPerMXP reads events from i and writes aggregated events
@@ -42,35 +90,97 @@ type Meta struct {
 // aggregated event records the largest observed count of instances of a GVK on
 // an MXP during a window. The order of written events is not stable.
 func MaxResourceCountPerGVKPerMXP(ctx context.Context, i event.WindowIterator, w event.Writer) error {
-	for i.More() {
-		r, window, err := i.Next()
+	return (&Collector{Iter: i, Writer: w}).Collect(ctx)
+}
+
+// Collector drives the collection of a usage report. It reads events from
+// Iter, aggregates them with MaxResourceCountPerGVKPerMXP for each window and
+// writes the aggregated events to Writer. If Checkpoints is set, a checkpoint
+// is written after each window is fully processed. If Dedup is set, events
+// that were already read in a previous or overlapping window are dropped. If
+// Progress is set, it is notified as windows and events are processed.
+type Collector struct {
+	Iter        event.WindowIterator
+	Writer      event.Writer
+	Checkpoints CheckpointWriter
+	Dedup       *reader.Deduplicator
+	Progress    Progress
+	// TimeRange is the time range covered by Iter. It is recorded in
+	// checkpoints and used to verify that a checkpoint belongs to the
+	// collection being resumed.
+	TimeRange usagetime.Range
+}
+
+// Collect collects a usage report from the start of the time range.
+func (c *Collector) Collect(ctx context.Context) error {
+	return c.Resume(ctx, Checkpoint{})
+}
+
+// Resume collects a usage report, skipping windows that were fully processed
+// before cp was recorded. Events for skipped windows are not written, so the
+// caller is responsible for keeping the output of the interrupted collection.
+func (c *Collector) Resume(ctx context.Context, cp Checkpoint) error {
+	if !cp.IsZero() && (!cp.TimeRange.Start.Equal(c.TimeRange.Start) || !cp.TimeRange.End.Equal(c.TimeRange.End)) {
+		return errors.New(errCheckpointTimeRange)
+	}
+	progress := c.Progress
+	if progress == nil {
+		progress = NopProgress{}
+	}
+	for c.Iter.More() {
+		r, window, err := c.Iter.Next()
 		if err != nil {
 			return errors.Wrap(err, errReadEvents)
 		}
-
-		ag := &aggregate.MaxResourceCountPerGVKPerMXP{}
-		for {
-			e, err := r.Read(ctx)
-			if errors.Is(err, event.ErrEOF) {
-				break
-			}
-			if err != nil {
-				return err
+		if cp.Done(window) {
+			if err := r.Close(); err != nil {
+				return errors.Wrap(err, errReadEvents)
 			}
-			if err := ag.Add(e); err != nil {
+			progress.OnWindowDone(window)
+			continue
+		}
+		if c.Dedup != nil {
+			c.Dedup.Forget(window.Start)
+			r = c.Dedup.Reader(r)
+		}
+		progress.OnWindowStart(window)
+		if err := c.collectWindow(ctx, r, window, progress); err != nil {
+			return err
+		}
+		if c.Checkpoints != nil {
+			if err := c.Checkpoints.WriteCheckpoint(Checkpoint{TimeRange: c.TimeRange, Window: window}); err != nil {
 				return err
 			}
 		}
-		if err := r.Close(); err != nil {
-			return errors.Wrap(err, errReadEvents)
+		progress.OnWindowDone(window)
+	}
+	return nil
+}
+
+func (c *Collector) collectWindow(ctx context.Context, r event.Reader, window usagetime.Range, progress Progress) error {
+	ag := &aggregate.MaxResourceCountPerGVKPerMXP{}
+	for {
+		e, err := r.Read(ctx)
+		if errors.Is(err, event.ErrEOF) {
+			break
+		}
+		if err != nil {
+			return err
 		}
+		progress.OnObject(window, e)
+		if err := ag.Add(e); err != nil {
+			return err
+		}
+	}
+	if err := r.Close(); err != nil {
+		return errors.Wrap(err, errReadEvents)
+	}
 
-		for _, e := range ag.UpboundEvents() {
-			e.Timestamp = window.Start
-			e.TimestampEnd = window.End
-			if err := w.Write(e); err != nil {
-				return errors.Wrap(err, errWriteEvents)
-			}
+	for _, e := range ag.UpboundEvents() {
+		e.Timestamp = window.Start
+		e.TimestampEnd = window.End
+		if err := c.Writer.Write(e); err != nil {
+			return errors.Wrap(err, errWriteEvents)
 		}
 	}
 	return nil
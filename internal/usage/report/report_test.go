@@ -16,6 +16,7 @@ package report
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -395,3 +396,157 @@ func TestMaxResourceCountPerGVKPerMXP(t *testing.T) {
 		})
 	}
 }
+
+type mockCheckpointWriter struct {
+	Checkpoints []Checkpoint
+}
+
+func (w *mockCheckpointWriter) WriteCheckpoint(cp Checkpoint) error {
+	w.Checkpoints = append(w.Checkpoints, cp)
+	return nil
+}
+
+type mockProgress struct {
+	Calls []string
+}
+
+func (p *mockProgress) OnWindowStart(w usagetime.Range) {
+	p.Calls = append(p.Calls, "start "+w.Start.Format(time.Kitchen))
+}
+
+func (p *mockProgress) OnObject(w usagetime.Range, _ model.MXPGVKEvent) {
+	p.Calls = append(p.Calls, "object "+w.Start.Format(time.Kitchen))
+}
+
+func (p *mockProgress) OnWindowDone(w usagetime.Range) {
+	p.Calls = append(p.Calls, "done "+w.Start.Format(time.Kitchen))
+}
+
+func TestCollectorResume(t *testing.T) {
+	tr := usagetime.Range{
+		Start: time.Date(2006, 05, 04, 03, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 05, 04, 05, 0, 0, 0, time.UTC),
+	}
+	w1 := usagetime.Range{
+		Start: time.Date(2006, 05, 04, 03, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 05, 04, 04, 0, 0, 0, time.UTC),
+	}
+	w2 := usagetime.Range{
+		Start: time.Date(2006, 05, 04, 04, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 05, 04, 05, 0, 0, 0, time.UTC),
+	}
+	newIter := func() *usagetesting.MockWindowIterator {
+		return &usagetesting.MockWindowIterator{Windows: []usagetesting.Window{
+			{
+				Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{
+					{Event: model.MXPGVKEvent{
+						Name:  "kube_managedresource_uid",
+						Value: float64(2),
+						Tags:  model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+					}},
+				}},
+				Window: w1,
+			},
+			{
+				Reader: &usagetesting.MockReader{Reads: []usagetesting.ReadResult{
+					{Event: model.MXPGVKEvent{
+						Name:  "kube_managedresource_uid",
+						Value: float64(5),
+						Tags:  model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+					}},
+				}},
+				Window: w2,
+			},
+		}}
+	}
+
+	type want struct {
+		events      []model.MXPGVKEvent
+		checkpoints []Checkpoint
+		progress    []string
+		err         error
+	}
+	cases := map[string]struct {
+		reason string
+		cp     Checkpoint
+		want   want
+	}{
+		"NoCheckpoint": {
+			reason: "A zero checkpoint collects every window and records a checkpoint after each.",
+			want: want{
+				events: []model.MXPGVKEvent{
+					{
+						Name:         "max_resource_count_per_gvk_per_mxp",
+						Value:        float64(2),
+						Timestamp:    w1.Start,
+						TimestampEnd: w1.End,
+						Tags:         model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+					},
+					{
+						Name:         "max_resource_count_per_gvk_per_mxp",
+						Value:        float64(5),
+						Timestamp:    w2.Start,
+						TimestampEnd: w2.End,
+						Tags:         model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+					},
+				},
+				checkpoints: []Checkpoint{
+					{TimeRange: tr, Window: w1},
+					{TimeRange: tr, Window: w2},
+				},
+				progress: []string{"start 3:00AM", "object 3:00AM", "done 3:00AM", "start 4:00AM", "object 4:00AM", "done 4:00AM"},
+			},
+		},
+		"SkipProcessedWindows": {
+			reason: "Windows covered by the checkpoint are skipped.",
+			cp:     Checkpoint{TimeRange: tr, Window: w1},
+			want: want{
+				events: []model.MXPGVKEvent{
+					{
+						Name:         "max_resource_count_per_gvk_per_mxp",
+						Value:        float64(5),
+						Timestamp:    w2.Start,
+						TimestampEnd: w2.End,
+						Tags:         model.MXPGVKEventTags{Group: "example.com", Version: "v1", Kind: "Thing", MXPID: "mxp1"},
+					},
+				},
+				checkpoints: []Checkpoint{
+					{TimeRange: tr, Window: w2},
+				},
+				progress: []string{"done 3:00AM", "start 4:00AM", "object 4:00AM", "done 4:00AM"},
+			},
+		},
+		"MismatchedTimeRange": {
+			reason: "A checkpoint for a different time range is rejected.",
+			cp: Checkpoint{
+				TimeRange: usagetime.Range{Start: w2.Start, End: w2.End},
+				Window:    w2,
+			},
+			want: want{
+				err: errors.New(errCheckpointTimeRange),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &usagetesting.MockWriter{}
+			cw := &mockCheckpointWriter{}
+			p := &mockProgress{}
+			c := &Collector{Iter: newIter(), Writer: w, Checkpoints: cw, Progress: p, TimeRange: tr}
+			err := c.Resume(context.Background(), tc.cp)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResume(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, w.Events); diff != "" {
+				t.Errorf("\n%s\nResume(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.checkpoints, cw.Checkpoints); diff != "" {
+				t.Errorf("\n%s\nResume(...): -want checkpoints, +got checkpoints:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.progress, p.Calls); diff != "" {
+				t.Errorf("\n%s\nResume(...): -want progress, +got progress:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
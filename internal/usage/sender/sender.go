@@ -0,0 +1,303 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/upbound/up/internal/capability"
+	uphttp "github.com/upbound/up/internal/http"
+	"github.com/upbound/up/internal/telemetry"
+)
+
+const (
+	usagePath   = "v1/usage"
+	uploadsPath = "uploads"
+	completeFmt = "%s:complete"
+
+	// DefaultChunkSize is the default number of bytes sent in each request.
+	DefaultChunkSize = 8 << 20
+
+	errNoEndpoint       = "usage ingestion endpoint is not set"
+	errCreateUpload     = "failed to create upload"
+	errGetUpload        = "failed to get upload status"
+	errUploadChunk      = "failed to upload chunk"
+	errCompleteUpload   = "failed to complete upload"
+	errComputeChecksum  = "failed to compute checksum"
+	errFmtStatus        = "unexpected status %d: %s"
+	errFmtChecksum      = "checksum mismatch: sent %s, received %s"
+	errFmtOffsetInvalid = "server reported offset %d for upload of %d bytes"
+)
+
+// Upload is an upload of a usage archive to the usage ingestion API.
+type Upload struct {
+	// ID identifies the upload. An interrupted upload can be resumed by
+	// passing an Upload with the same ID to Sender.Upload().
+	ID string `json:"id"`
+	// Size is the size of the archive in bytes.
+	Size int64 `json:"size"`
+	// SHA256 is the hex encoded SHA-256 checksum of the archive.
+	SHA256 string `json:"sha256"`
+	// Offset is the number of bytes received by the server.
+	Offset int64 `json:"offset"`
+}
+
+// ProgressFn is called after each chunk is sent with the number of bytes
+// received by the server and the total size of the archive.
+type ProgressFn func(sent, total int64)
+
+// Sender uploads usage archives to the Upbound usage ingestion API, whose
+// endpoint must be supplied WithEndpoint. Archives
+// are sent in chunks. If an upload is interrupted, it is resumed from the last
+// chunk received by the server. The checksum reported by the server when the
+// upload is completed is verified against the checksum of the archive.
+type Sender struct {
+	client    uphttp.Client
+	endpoint  *url.URL
+	token     string
+	account   string
+	chunkSize int64
+	progress  ProgressFn
+	log       logging.Logger
+	telemetry telemetry.Recorder
+	caps      *capability.Capabilities
+}
+
+// SenderModifierFn modifies the sender.
+type SenderModifierFn func(*Sender)
+
+// WithClient sets the HTTP client used by the sender.
+func WithClient(c uphttp.Client) SenderModifierFn {
+	return func(s *Sender) {
+		s.client = c
+	}
+}
+
+// WithEndpoint sets the endpoint of the usage ingestion API.
+func WithEndpoint(endpoint *url.URL) SenderModifierFn {
+	return func(s *Sender) {
+		s.endpoint = endpoint
+	}
+}
+
+// WithToken sets the bearer token used to authenticate requests.
+func WithToken(token string) SenderModifierFn {
+	return func(s *Sender) {
+		s.token = token
+	}
+}
+
+// WithAccount sets the Upbound account that usage is uploaded for.
+func WithAccount(account string) SenderModifierFn {
+	return func(s *Sender) {
+		s.account = account
+	}
+}
+
+// WithChunkSize sets the number of bytes sent in each request.
+func WithChunkSize(size int64) SenderModifierFn {
+	return func(s *Sender) {
+		s.chunkSize = size
+	}
+}
+
+// WithProgress sets a function that is called after each chunk is sent.
+func WithProgress(fn ProgressFn) SenderModifierFn {
+	return func(s *Sender) {
+		s.progress = fn
+	}
+}
+
+// WithLogger sets the logger used by the sender.
+func WithLogger(l logging.Logger) SenderModifierFn {
+	return func(s *Sender) {
+		s.log = l
+	}
+}
+
+// WithTelemetry sets the Recorder uploads are recorded with. They are recorded
+// with the telemetry.DefaultRecorder by default.
+func WithTelemetry(r telemetry.Recorder) SenderModifierFn {
+	return func(s *Sender) {
+		s.telemetry = r
+	}
+}
+
+// WithCapabilities makes the sender fail early if the target does not accept
+// usage uploads.
+func WithCapabilities(caps *capability.Capabilities) SenderModifierFn {
+	return func(s *Sender) {
+		s.caps = caps
+	}
+}
+
+// NewSender constructs a new sender.
+func NewSender(modifiers ...SenderModifierFn) *Sender {
+	s := &Sender{
+		client:    uphttp.NewClient(),
+		chunkSize: DefaultChunkSize,
+		progress:  func(int64, int64) {},
+		log:       logging.NewNopLogger(),
+		telemetry: telemetry.Deferred,
+	}
+	for _, m := range modifiers {
+		m(s)
+	}
+	return s
+}
+
+// Send uploads an archive of size bytes read from r.
+func (s *Sender) Send(ctx context.Context, r io.ReaderAt, size int64) (*Upload, error) {
+	sum, err := Checksum(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, errComputeChecksum)
+	}
+	u, err := s.Create(ctx, size, sum)
+	if err != nil {
+		return nil, err
+	}
+	return u, s.Upload(ctx, u, r)
+}
+
+// Create creates an upload for an archive with the supplied size and hex
+// encoded SHA-256 checksum.
+func (s *Sender) Create(ctx context.Context, size int64, sum string) (_ *Upload, err error) {
+	defer telemetry.Observe(s.telemetry, "usage create", time.Now(), &err)
+	if s.endpoint == nil {
+		return nil, errors.New(errNoEndpoint)
+	}
+	if err := s.caps.Require(capability.FeatureUsageUpload); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&Upload{Size: size, SHA256: sum})
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateUpload)
+	}
+	u := &Upload{}
+	if err := s.do(ctx, http.MethodPost, s.url(url.PathEscape(s.account), uploadsPath), nil, bytes.NewReader(body), u); err != nil {
+		return nil, errors.Wrap(err, errCreateUpload)
+	}
+	u.Size, u.SHA256 = size, sum
+	return u, nil
+}
+
+// Upload sends the remainder of an upload read from r, starting at the offset
+// most recently received by the server, and completes it.
+func (s *Sender) Upload(ctx context.Context, u *Upload, r io.ReaderAt) (err error) {
+	defer telemetry.Observe(s.telemetry, "usage upload", time.Now(), &err)
+	if s.endpoint == nil {
+		return errors.New(errNoEndpoint)
+	}
+	status := &Upload{}
+	if err := s.do(ctx, http.MethodGet, s.url(url.PathEscape(s.account), uploadsPath, url.PathEscape(u.ID)), nil, nil, status); err != nil {
+		return errors.Wrap(err, errGetUpload)
+	}
+	if status.Offset < 0 || status.Offset > u.Size {
+		return errors.Errorf(errFmtOffsetInvalid, status.Offset, u.Size)
+	}
+	u.Offset = status.Offset
+	s.progress(u.Offset, u.Size)
+	if u.Offset > 0 {
+		s.log.Info("Resuming upload", "id", u.ID, "offset", u.Offset, "size", u.Size)
+	}
+
+	for u.Offset < u.Size {
+		n := s.chunkSize
+		if rem := u.Size - u.Offset; rem < n {
+			n = rem
+		}
+		h := http.Header{}
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", u.Offset, u.Offset+n-1, u.Size))
+		status := &Upload{}
+		if err := s.do(ctx, http.MethodPut, s.url(url.PathEscape(s.account), uploadsPath, url.PathEscape(u.ID)), h, io.NewSectionReader(r, u.Offset, n), status); err != nil {
+			return errors.Wrap(err, errUploadChunk)
+		}
+		if status.Offset <= u.Offset || status.Offset > u.Size {
+			return errors.Wrap(errors.Errorf(errFmtOffsetInvalid, status.Offset, u.Size), errUploadChunk)
+		}
+		u.Offset = status.Offset
+		s.progress(u.Offset, u.Size)
+		s.log.Debug("Uploaded chunk", "id", u.ID, "offset", u.Offset, "size", u.Size)
+	}
+
+	status = &Upload{}
+	if err := s.do(ctx, http.MethodPost, s.url(url.PathEscape(s.account), uploadsPath, fmt.Sprintf(completeFmt, url.PathEscape(u.ID))), nil, nil, status); err != nil {
+		return errors.Wrap(err, errCompleteUpload)
+	}
+	if status.SHA256 != u.SHA256 {
+		return errors.Wrap(errors.Errorf(errFmtChecksum, u.SHA256, status.SHA256), errCompleteUpload)
+	}
+	return nil
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of size bytes read from r.
+func Checksum(r io.ReaderAt, size int64) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(r, 0, size)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// url returns the URL of the supplied escaped path elements of the usage API,
+// relative to the path of the endpoint.
+func (s *Sender) url(elem ...string) string {
+	return s.endpoint.JoinPath(append([]string{usagePath}, elem...)...).String()
+}
+
+func (s *Sender) do(ctx context.Context, method, url string, h http.Header, body io.Reader, into any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range h {
+		req.Header[k] = v
+	}
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.token))
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() // nolint:gosec,errcheck
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf(errFmtStatus, res.StatusCode, string(b))
+	}
+	return json.Unmarshal(b, into)
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/http/mocks"
+)
+
+// fakeServer is an in-memory usage ingestion API. It serves uploads of the
+// account "acct" at the root of its host unless a path is supplied.
+type fakeServer struct {
+	path     string
+	received []byte
+	offset   int64
+	checksum string
+	failPut  bool
+}
+
+func (f *fakeServer) Do(req *http.Request) (*http.Response, error) {
+	respond := func(code int, body string) (*http.Response, error) {
+		return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+	uploads := f.path
+	if uploads == "" {
+		uploads = "/v1/usage/acct/uploads"
+	}
+	p := req.URL.EscapedPath()
+	switch {
+	case req.Method == http.MethodPost && p == uploads:
+		return respond(http.StatusCreated, `{"id":"up1"}`)
+	case req.Method == http.MethodGet && p == uploads+"/up1":
+		return respond(http.StatusOK, fmt.Sprintf(`{"id":"up1","offset":%d}`, f.offset))
+	case req.Method == http.MethodPut && p == uploads+"/up1":
+		if f.failPut {
+			return respond(http.StatusInternalServerError, "boom")
+		}
+		b, _ := io.ReadAll(req.Body)
+		f.received = append(f.received, b...)
+		f.offset += int64(len(b))
+		return respond(http.StatusOK, fmt.Sprintf(`{"id":"up1","offset":%d}`, f.offset))
+	case req.Method == http.MethodPost && p == uploads+"/up1:complete":
+		sum := f.checksum
+		if sum == "" {
+			sum, _ = Checksum(bytes.NewReader(f.received), int64(len(f.received)))
+		}
+		return respond(http.StatusOK, fmt.Sprintf(`{"id":"up1","sha256":%q}`, sum))
+	}
+	return respond(http.StatusNotFound, p)
+}
+
+func TestSend(t *testing.T) {
+	endpoint, _ := url.Parse("https://usage.test.com")
+	archive := []byte("0123456789")
+
+	type want struct {
+		received []byte
+		progress [][2]int64
+		err      error
+	}
+	cases := map[string]struct {
+		reason     string
+		server     *fakeServer
+		endpoint   string
+		account    string
+		noEndpoint bool
+		want       want
+	}{
+		"NoEndpoint": {
+			reason:     "Sending without an endpoint should return an error rather than panic.",
+			server:     &fakeServer{},
+			noEndpoint: true,
+			want: want{
+				progress: [][2]int64{},
+				err:      errors.New(errNoEndpoint),
+			},
+		},
+		"Chunked": {
+			reason: "The archive should be sent in chunks and the progress reported after each chunk.",
+			server: &fakeServer{},
+			want: want{
+				received: archive,
+				progress: [][2]int64{{0, 10}, {4, 10}, {8, 10}, {10, 10}},
+			},
+		},
+		"PathPrefix": {
+			reason:   "Uploads should be sent relative to the path of the endpoint, with the account escaped.",
+			server:   &fakeServer{path: "/proxy/usage/v1/usage/acme%2Fteam/uploads"},
+			endpoint: "https://usage.test.com/proxy/usage/",
+			account:  "acme/team",
+			want: want{
+				received: archive,
+				progress: [][2]int64{{0, 10}, {4, 10}, {8, 10}, {10, 10}},
+			},
+		},
+		"Resumed": {
+			reason: "An upload should resume from the offset reported by the server.",
+			server: &fakeServer{offset: 8, received: archive[:8]},
+			want: want{
+				received: archive,
+				progress: [][2]int64{{8, 10}, {10, 10}},
+			},
+		},
+		"ChecksumMismatch": {
+			reason: "A checksum reported by the server that does not match the archive should return an error.",
+			server: &fakeServer{checksum: "bad"},
+			want: want{
+				received: archive,
+				progress: [][2]int64{{0, 10}, {4, 10}, {8, 10}, {10, 10}},
+				err:      errors.Wrap(errors.Errorf(errFmtChecksum, "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882", "bad"), errCompleteUpload),
+			},
+		},
+		"ChunkFailed": {
+			reason: "A failure to upload a chunk should return an error.",
+			server: &fakeServer{failPut: true},
+			want: want{
+				progress: [][2]int64{{0, 10}},
+				err:      errors.Wrap(errors.Errorf(errFmtStatus, http.StatusInternalServerError, "boom"), errUploadChunk),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			progress := [][2]int64{}
+			ep := endpoint
+			if tc.endpoint != "" {
+				ep, _ = url.Parse(tc.endpoint)
+			}
+			if tc.noEndpoint {
+				ep = nil
+			}
+			account := "acct"
+			if tc.account != "" {
+				account = tc.account
+			}
+			s := NewSender(
+				WithClient(&mocks.MockClient{DoFn: tc.server.Do}),
+				WithEndpoint(ep),
+				WithAccount(account),
+				WithChunkSize(4),
+				WithProgress(func(sent, total int64) { progress = append(progress, [2]int64{sent, total}) }),
+			)
+			_, err := s.Send(context.Background(), bytes.NewReader(archive), int64(len(archive)))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSend(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.received, tc.server.received); diff != "" {
+				t.Errorf("\n%s\nSend(...): -want received, +got received:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.progress, progress); diff != "" {
+				t.Errorf("\n%s\nSend(...): -want progress, +got progress:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
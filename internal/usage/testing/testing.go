@@ -32,10 +32,22 @@ type ReadResult struct {
 	Err   error
 }
 
-var _ event.Reader = &MockReader{}
+var (
+	_ event.Reader = &MockReader{}
+	_ event.Sizer  = &MockReader{}
+)
 
 type MockReader struct {
 	Reads []ReadResult
+
+	// Objects, Bytes and SizeErr are returned by Size().
+	Objects int
+	Bytes   int64
+	SizeErr error
+}
+
+func (r *MockReader) Size(context.Context) (int, int64, error) {
+	return r.Objects, r.Bytes, r.SizeErr
 }
 
 func (r *MockReader) Read(context.Context) (model.MXPGVKEvent, error) {
@@ -0,0 +1,184 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package time
+
+import (
+	"fmt"
+	"time"
+)
+
+// Iterator is the interface for iterating through windows of a range of time.
+type Iterator interface {
+	// More returns true if Next() has more to return.
+	More() bool
+	// Next returns a time range covering the next window of time.
+	Next() (Range, error)
+}
+
+var (
+	_ Iterator = &WindowIterator{}
+	_ Iterator = &CalendarWindowIterator{}
+)
+
+// WindowUnit is a calendar unit used to size windows.
+type WindowUnit string
+
+const (
+	WindowUnitHour  WindowUnit = "hour"
+	WindowUnitDay   WindowUnit = "day"
+	WindowUnitMonth WindowUnit = "month"
+)
+
+// WindowSpec describes windows aligned to calendar boundaries.
+type WindowSpec struct {
+	// Unit is the calendar unit of a window.
+	Unit WindowUnit
+	// Count is the number of units in a window. Defaults to 1.
+	Count int
+	// Offset shifts the boundaries of each window from the start of their
+	// unit. For example, day windows with an offset of 6h start at 06:00.
+	Offset time.Duration
+	// Location is the timezone in which boundaries are computed. Defaults to
+	// UTC.
+	Location *time.Location
+}
+
+// IteratorOption modifies the windows of an Iterator returned by NewIterator().
+type IteratorOption func(*iteratorOptions)
+
+type iteratorOptions struct {
+	spec *WindowSpec
+}
+
+// WithCalendarWindows aligns windows to the calendar boundaries described by
+// spec instead of sizing them by a fixed duration.
+func WithCalendarWindows(spec WindowSpec) IteratorOption {
+	return func(o *iteratorOptions) {
+		o.spec = &spec
+	}
+}
+
+// NewIterator returns an Iterator through windows of a range of time. Windows
+// have a fixed duration and start at the start of the range, unless
+// WithCalendarWindows() is supplied, in which case window is ignored.
+func NewIterator(tr Range, window time.Duration, opts ...IteratorOption) (Iterator, error) {
+	o := &iteratorOptions{}
+	for _, fn := range opts {
+		fn(o)
+	}
+	if o.spec != nil {
+		return NewCalendarWindowIterator(tr, *o.spec)
+	}
+	return NewWindowIterator(tr, window)
+}
+
+// CalendarWindowIterator iterates through windows of a range of time that are
+// aligned to calendar boundaries in a timezone. The first and last windows are
+// clipped to the range, so they may be shorter than the other windows. Must be
+// initialized with NewCalendarWindowIterator().
+type CalendarWindowIterator struct {
+	Spec   WindowSpec
+	Anchor time.Time
+	Start  time.Time
+	End    time.Time
+	// index is the number of windows between Anchor and the start of the next
+	// window.
+	index int
+}
+
+// NewCalendarWindowIterator returns an initialized *CalendarWindowIterator. The
+// start and end of the time range are truncated to the hour.
+func NewCalendarWindowIterator(tr Range, spec WindowSpec) (*CalendarWindowIterator, error) {
+	switch spec.Unit {
+	case WindowUnitHour, WindowUnitDay, WindowUnitMonth:
+	default:
+		return nil, fmt.Errorf("window unit %q is not supported", spec.Unit)
+	}
+	if spec.Count < 0 {
+		return nil, fmt.Errorf("window count must not be negative")
+	}
+	if spec.Count == 0 {
+		spec.Count = 1
+	}
+	if spec.Offset%time.Hour != 0 {
+		return nil, fmt.Errorf("window offset must be a whole number of hours")
+	}
+	if spec.Location == nil {
+		spec.Location = time.UTC
+	}
+	if tr.End.Before(tr.Start.Add(time.Hour)) {
+		return nil, fmt.Errorf("time range must be at least 1h")
+	}
+	start := tr.Start.Truncate(time.Hour)
+	return &CalendarWindowIterator{
+		Spec:   spec,
+		Anchor: floorUnit(start.Add(-spec.Offset), spec),
+		Start:  start,
+		End:    tr.End.Truncate(time.Hour),
+	}, nil
+}
+
+// More returns true if Next() has more to return.
+func (i *CalendarWindowIterator) More() bool {
+	return i.boundary(i.index).Before(i.End)
+}
+
+// Next returns a time range covering the next window of time. The start time
+// is inclusive and the end time is exclusive. Returns an error if More()
+// returns false.
+func (i *CalendarWindowIterator) Next() (Range, error) {
+	if !i.More() {
+		return Range{}, fmt.Errorf("iterator is done")
+	}
+	window := Range{Start: i.boundary(i.index), End: i.boundary(i.index + 1)}
+	i.index++
+	if window.Start.Before(i.Start) {
+		window.Start = i.Start
+	}
+	if window.End.After(i.End) {
+		window.End = i.End
+	}
+	return window, nil
+}
+
+// boundary returns the start of the nth window after the anchor. Day and
+// month boundaries are computed in calendar time in the spec's timezone, so
+// windows follow daylight saving transitions and start on the hour of the
+// timezone even if its offset from UTC is not a whole number of hours.
+func (i *CalendarWindowIterator) boundary(n int) time.Time {
+	a, n := i.Anchor, n*i.Spec.Count
+	offset := int(i.Spec.Offset / time.Hour)
+	switch i.Spec.Unit {
+	case WindowUnitDay:
+		return time.Date(a.Year(), a.Month(), a.Day()+n, offset, 0, 0, 0, i.Spec.Location)
+	case WindowUnitMonth:
+		return time.Date(a.Year(), a.Month()+time.Month(n), 1, offset, 0, 0, 0, i.Spec.Location)
+	default:
+		return a.Add(time.Duration(n)*time.Hour + i.Spec.Offset)
+	}
+}
+
+// floorUnit returns the start of the unit containing t in the spec's timezone.
+func floorUnit(t time.Time, spec WindowSpec) time.Time {
+	t = t.In(spec.Location)
+	switch spec.Unit {
+	case WindowUnitDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, spec.Location)
+	case WindowUnitMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, spec.Location)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, spec.Location)
+	}
+}
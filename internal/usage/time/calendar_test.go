@@ -0,0 +1,264 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package time
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCalendarWindowIterator(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("LoadLocation(...): %s", err)
+	}
+
+	type args struct {
+		tr   Range
+		spec WindowSpec
+	}
+	type iteration struct {
+		Window Range
+		Err    error
+	}
+	type want struct {
+		err        error
+		iterations []iteration
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnsupportedUnit": {
+			reason: "An unknown window unit should return an error.",
+			args: args{
+				tr: Range{
+					Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+				},
+				spec: WindowSpec{Unit: "week"},
+			},
+			want: want{err: errors.New(`window unit "week" is not supported`)},
+		},
+		"PartialHourOffset": {
+			reason: "An offset that is not a whole number of hours should return an error.",
+			args: args{
+				tr: Range{
+					Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+				},
+				spec: WindowSpec{Unit: WindowUnitDay, Offset: 90 * time.Minute},
+			},
+			want: want{err: errors.New("window offset must be a whole number of hours")},
+		},
+		"DayWindowsUTC": {
+			reason: "Day windows are aligned to midnight UTC and clipped to the range.",
+			args: args{
+				tr: Range{
+					Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 6, 3, 0, 0, 0, time.UTC),
+				},
+				spec: WindowSpec{Unit: WindowUnitDay},
+			},
+			want: want{iterations: []iteration{
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 6, 0, 0, 0, 0, time.UTC),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 5, 6, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 6, 3, 0, 0, 0, time.UTC),
+				}},
+			}},
+		},
+		"DayWindowsWithOffset": {
+			reason: "Day windows are shifted by the offset.",
+			args: args{
+				tr: Range{
+					Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC),
+				},
+				spec: WindowSpec{Unit: WindowUnitDay, Offset: 6 * time.Hour},
+			},
+			want: want{iterations: []iteration{
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 6, 0, 0, 0, time.UTC),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 6, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC),
+				}},
+			}},
+		},
+		"MonthWindowsInTimezone": {
+			reason: "Month windows are aligned to the start of the month in the billing timezone.",
+			args: args{
+				tr: Range{
+					Start: time.Date(2006, 5, 1, 5, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 7, 1, 5, 0, 0, 0, time.UTC),
+				},
+				spec: WindowSpec{Unit: WindowUnitMonth, Location: est},
+			},
+			want: want{iterations: []iteration{
+				{Window: Range{
+					Start: time.Date(2006, 5, 1, 0, 0, 0, 0, est),
+					End:   time.Date(2006, 6, 1, 0, 0, 0, 0, est),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 6, 1, 0, 0, 0, 0, est),
+					End:   time.Date(2006, 7, 1, 0, 0, 0, 0, est),
+				}},
+			}},
+		},
+		"MultiHourWindows": {
+			reason: "Hour windows with a count are aligned to the hour the range starts in.",
+			args: args{
+				tr: Range{
+					Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 8, 0, 0, 0, time.UTC),
+				},
+				spec: WindowSpec{Unit: WindowUnitHour, Count: 2},
+			},
+			want: want{iterations: []iteration{
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 7, 0, 0, 0, time.UTC),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 7, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 8, 0, 0, 0, time.UTC),
+				}},
+			}},
+		},
+		"HalfHourTimezone": {
+			reason: "Day windows are aligned to midnight in a timezone whose offset from UTC is not a whole number of hours.",
+			args: args{
+				tr: Range{
+					Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 6, 0, 0, 0, 0, time.UTC),
+				},
+				spec: WindowSpec{Unit: WindowUnitDay, Offset: 6 * time.Hour, Location: kolkata},
+			},
+			want: want{iterations: []iteration{
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2006, 5, 4, 6, 0, 0, 0, kolkata),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 5, 4, 6, 0, 0, 0, kolkata),
+					End:   time.Date(2006, 5, 5, 6, 0, 0, 0, kolkata),
+				}},
+				{Window: Range{
+					Start: time.Date(2006, 5, 5, 6, 0, 0, 0, kolkata),
+					End:   time.Date(2006, 5, 6, 0, 0, 0, 0, time.UTC),
+				}},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			iter, err := NewCalendarWindowIterator(tc.args.tr, tc.args.spec)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nNewCalendarWindowIterator(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			got := []iteration{}
+			for iter.More() {
+				window, err := iter.Next()
+				got = append(got, iteration{Window: window, Err: err})
+			}
+
+			if diff := cmp.Diff(tc.want.iterations, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCalendarWindowIterator output: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewIterator(t *testing.T) {
+	tr := Range{
+		Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 5, 3, 0, 0, 0, time.UTC),
+	}
+
+	type args struct {
+		window time.Duration
+		opts   []IteratorOption
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []Range
+	}{
+		"FixedWindows": {
+			reason: "Without options, windows have a fixed duration from the start of the range.",
+			args:   args{window: 12 * time.Hour},
+			want: []Range{
+				{Start: tr.Start, End: time.Date(2006, 5, 4, 15, 0, 0, 0, time.UTC)},
+				{Start: time.Date(2006, 5, 4, 15, 0, 0, 0, time.UTC), End: tr.End},
+			},
+		},
+		"CalendarWindows": {
+			reason: "Calendar windows are aligned to calendar boundaries and the window duration is ignored.",
+			args: args{
+				window: 12 * time.Hour,
+				opts:   []IteratorOption{WithCalendarWindows(WindowSpec{Unit: WindowUnitDay})},
+			},
+			want: []Range{
+				{Start: tr.Start, End: time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC)},
+				{Start: time.Date(2006, 5, 5, 0, 0, 0, 0, time.UTC), End: tr.End},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			iter, err := NewIterator(tr, tc.args.window, tc.args.opts...)
+			if err != nil {
+				t.Fatalf("\n%s\nNewIterator(...): %s", tc.reason, err)
+			}
+			got := []Range{}
+			for iter.More() {
+				window, err := iter.Next()
+				if err != nil {
+					t.Fatalf("\n%s\nNext(): %s", tc.reason, err)
+				}
+				got = append(got, window)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNewIterator(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
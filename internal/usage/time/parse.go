@@ -0,0 +1,149 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package time
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxSpan is the longest time range accepted by a RangeParser with no
+// MaxSpan.
+const DefaultMaxSpan = 366 * 24 * time.Hour
+
+var relativeRE = regexp.MustCompile(`^last\s+(\d+)\s*(h|d|w)$`)
+
+// RangeParser parses human-friendly expressions into time ranges. The zero
+// value parses relative to the current time in UTC.
+//
+// Supported expressions are:
+//
+//   - "today", "yesterday", "this month" and "last month".
+//   - "last <n><unit>", where unit is h, d or w, e.g. "last 7d". The range
+//     ends at the start of the current hour.
+//   - A month, e.g. "2024-03", or a day, e.g. "2024-03-05".
+//   - A pair of dates or RFC3339 timestamps separated by "/", e.g.
+//     "2024-03-01/2024-03-07". A date at the end of a pair is inclusive, a
+//     timestamp is exclusive.
+type RangeParser struct {
+	// Now returns the time relative expressions are resolved against.
+	// Defaults to time.Now.
+	Now func() time.Time
+	// Location is the timezone in which days and months are computed.
+	// Defaults to UTC.
+	Location *time.Location
+	// MaxSpan is the longest range accepted. Defaults to DefaultMaxSpan.
+	MaxSpan time.Duration
+}
+
+// Parse parses a human-friendly expression into a time range. The start of the
+// range is inclusive and the end is exclusive.
+func (p RangeParser) Parse(expr string) (Range, error) {
+	r, err := p.parse(strings.ToLower(strings.TrimSpace(expr)))
+	if err != nil {
+		return Range{}, err
+	}
+	if !r.Start.Before(r.End) {
+		return Range{}, fmt.Errorf("time range %q must start before it ends", expr)
+	}
+	maxSpan := p.MaxSpan
+	if maxSpan == 0 {
+		maxSpan = DefaultMaxSpan
+	}
+	if r.End.Sub(r.Start) > maxSpan {
+		return Range{}, fmt.Errorf("time range %q must not be longer than %s", expr, maxSpan)
+	}
+	return r, nil
+}
+
+func (p RangeParser) parse(expr string) (Range, error) {
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	t := now().In(loc)
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	thisMonth := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+
+	switch expr {
+	case "today":
+		return Range{Start: today, End: today.AddDate(0, 0, 1)}, nil
+	case "yesterday":
+		return Range{Start: today.AddDate(0, 0, -1), End: today}, nil
+	case "this month":
+		return Range{Start: thisMonth, End: thisMonth.AddDate(0, 1, 0)}, nil
+	case "last month":
+		return Range{Start: thisMonth.AddDate(0, -1, 0), End: thisMonth}, nil
+	}
+
+	if m := relativeRE.FindStringSubmatch(expr); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid duration in %q: %w", expr, err)
+		}
+		end := t.Truncate(time.Hour)
+		switch m[2] {
+		case "h":
+			return Range{Start: end.Add(-time.Duration(n) * time.Hour), End: end}, nil
+		case "w":
+			n *= 7
+		}
+		return Range{Start: end.AddDate(0, 0, -n), End: end}, nil
+	}
+
+	if start, end, ok := strings.Cut(expr, "/"); ok {
+		s, _, err := parsePoint(start, loc)
+		if err != nil {
+			return Range{}, err
+		}
+		e, date, err := parsePoint(end, loc)
+		if err != nil {
+			return Range{}, err
+		}
+		if date {
+			e = e.AddDate(0, 0, 1)
+		}
+		return Range{Start: s, End: e}, nil
+	}
+
+	if m, err := time.ParseInLocation("2006-01", expr, loc); err == nil {
+		return Range{Start: m, End: m.AddDate(0, 1, 0)}, nil
+	}
+	if d, err := time.ParseInLocation(time.DateOnly, expr, loc); err == nil {
+		return Range{Start: d, End: d.AddDate(0, 0, 1)}, nil
+	}
+	return Range{}, fmt.Errorf("unsupported time range %q", expr)
+}
+
+// parsePoint parses an RFC3339 timestamp or a date. It returns true if the
+// point is a date.
+func parsePoint(s string, loc *time.Location) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return d, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, strings.ToUpper(s))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is not a date or RFC3339 timestamp", s)
+	}
+	return t, false, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package time
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRangeParserParse(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	est := time.FixedZone("EST", -5*60*60)
+
+	type want struct {
+		r   Range
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		parser RangeParser
+		expr   string
+		want   want
+	}{
+		"Today": {
+			reason: "Today should cover the current day.",
+			expr:   "today",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Yesterday": {
+			reason: "Yesterday should cover the previous day.",
+			expr:   " Yesterday ",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"YesterdayInLocation": {
+			reason: "Days should be computed in the parser's location.",
+			parser: RangeParser{Location: est},
+			expr:   "yesterday",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 14, 0, 0, 0, 0, est),
+				End:   time.Date(2024, 3, 15, 0, 0, 0, 0, est),
+			}},
+		},
+		"ThisMonth": {
+			reason: "This month should cover the current calendar month.",
+			expr:   "this month",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"LastMonth": {
+			reason: "Last month should cover the previous calendar month.",
+			expr:   "last month",
+			want: want{r: Range{
+				Start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Last7Days": {
+			reason: "A relative range should end at the start of the current hour.",
+			expr:   "last 7d",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 8, 10, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Last12Hours": {
+			reason: "Relative ranges may be in hours.",
+			expr:   "last 12h",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 14, 22, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Last2Weeks": {
+			reason: "Relative ranges may be in weeks.",
+			expr:   "last 2w",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Month": {
+			reason: "A month should cover the whole month.",
+			expr:   "2024-02",
+			want: want{r: Range{
+				Start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"Day": {
+			reason: "A date should cover the whole day.",
+			expr:   "2024-02-29",
+			want: want{r: Range{
+				Start: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"DatePair": {
+			reason: "A pair of dates should include the end date.",
+			expr:   "2024-03-01/2024-03-07",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		"TimestampPair": {
+			reason: "A pair of RFC3339 timestamps should exclude the end timestamp.",
+			expr:   "2024-03-01T06:00:00Z/2024-03-02T06:00:00Z",
+			want: want{r: Range{
+				Start: time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC),
+			}},
+		},
+		"EndBeforeStart": {
+			reason: "A range that ends before it starts should return an error.",
+			expr:   "2024-03-07/2024-03-01",
+			want:   want{err: errors.New(`time range "2024-03-07/2024-03-01" must start before it ends`)},
+		},
+		"TooLong": {
+			reason: "A range longer than the maximum span should return an error.",
+			parser: RangeParser{MaxSpan: 7 * 24 * time.Hour},
+			expr:   "last 8d",
+			want:   want{err: errors.New(`time range "last 8d" must not be longer than 168h0m0s`)},
+		},
+		"InvalidPoint": {
+			reason: "A pair with an invalid point should return an error.",
+			expr:   "2024-03-01/tomorrow",
+			want:   want{err: errors.New(`"tomorrow" is not a date or RFC3339 timestamp`)},
+		},
+		"Unsupported": {
+			reason: "An unsupported expression should return an error.",
+			expr:   "next week",
+			want:   want{err: errors.New(`unsupported time range "next week"`)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := tc.parser
+			p.Now = func() time.Time { return now }
+			got, err := p.Parse(tc.expr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usage contains functions for working with collected usage reports.
+package usage
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	usagejson "github.com/upbound/up/internal/usage/encoding/json"
+	"github.com/upbound/up/internal/usage/report"
+	reporttar "github.com/upbound/up/internal/usage/report/file/tar"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+const (
+	errReadArchive  = "error reading archive"
+	errIterWindows  = "error iterating windows"
+	errMissingMeta  = "archive does not contain a meta file"
+	errMissingUsage = "archive does not contain a usage file"
+	errNoAccount    = "meta file does not specify an account"
+
+	errFmtCorruptMeta     = "meta file is corrupt: %s"
+	errFmtCorruptUsage    = "usage file is corrupt: %s"
+	errFmtMetaTimeRange   = "meta file time range %s to %s does not match %s to %s"
+	errFmtEventOutOfRange = "%d events are outside of the time range"
+)
+
+// GapReport describes the completeness of a usage report archive. Windows are
+// those recorded in the archive's meta file, or one hour long if it records
+// none.
+type GapReport struct {
+	// TimeRange is the time range that the archive was validated against.
+	TimeRange usagetime.Range `json:"time_range"`
+	// Meta is the metadata read from the archive, if it could be read.
+	Meta *report.Meta `json:"meta,omitempty"`
+	// Windows is the number of windows in the time range.
+	Windows int `json:"windows"`
+	// MissingWindows are windows for which the archive contains no events.
+	MissingWindows []usagetime.Range `json:"missing_windows"`
+	// EmptyWindows are windows for which every event in the archive records
+	// zero resources.
+	EmptyWindows []usagetime.Range `json:"empty_windows"`
+	// Errors are problems with the files in the archive.
+	Errors []string `json:"errors"`
+}
+
+// Complete returns true if the archive covers every window in the time range
+// and has no errors.
+func (r *GapReport) Complete() bool {
+	return len(r.MissingWindows) == 0 && len(r.EmptyWindows) == 0 && len(r.Errors) == 0
+}
+
+// Validate reads a usage report archive from tr and checks that it is
+// complete for the time range. Problems with the contents of the archive are
+// recorded in the returned report. An error is returned only if the archive
+// cannot be read.
+func Validate(tr *tar.Reader, timeRange usagetime.Range) (*GapReport, error) {
+	gr := &GapReport{
+		TimeRange:      timeRange,
+		MissingWindows: []usagetime.Range{},
+		EmptyWindows:   []usagetime.Range{},
+		Errors:         []string{},
+	}
+
+	var hasMeta, hasUsage bool
+	counts := map[time.Time]int{}
+	nonzero := map[time.Time]bool{}
+	outOfRange := 0
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errReadArchive)
+		}
+		switch h.Name {
+		case reporttar.MetaFilename:
+			hasMeta = true
+			gr.Meta, err = readMeta(tr)
+			if err != nil {
+				gr.Errors = append(gr.Errors, fmt.Sprintf(errFmtCorruptMeta, err))
+			}
+		case reporttar.UsageFilename:
+			hasUsage = true
+			d, err := usagejson.NewMXPGVKEventDecoder(tr)
+			if err != nil {
+				gr.Errors = append(gr.Errors, fmt.Sprintf(errFmtCorruptUsage, err))
+				continue
+			}
+			for d.More() {
+				e, err := d.Decode()
+				if err != nil {
+					gr.Errors = append(gr.Errors, fmt.Sprintf(errFmtCorruptUsage, err))
+					break
+				}
+				if e.Timestamp.Before(timeRange.Start) || !e.Timestamp.Before(timeRange.End) {
+					outOfRange++
+					continue
+				}
+				hour := e.Timestamp.UTC().Truncate(time.Hour)
+				counts[hour]++
+				if e.Value > 0 {
+					nonzero[hour] = true
+				}
+			}
+		}
+	}
+
+	if !hasMeta {
+		gr.Errors = append(gr.Errors, errMissingMeta)
+	}
+	if gr.Meta != nil {
+		gr.Errors = append(gr.Errors, metaErrors(gr.Meta, timeRange)...)
+	}
+	if !hasUsage {
+		gr.Errors = append(gr.Errors, errMissingUsage)
+	}
+	if outOfRange > 0 {
+		gr.Errors = append(gr.Errors, fmt.Sprintf(errFmtEventOutOfRange, outOfRange))
+	}
+
+	opts := []usagetime.IteratorOption{}
+	if gr.Meta != nil && gr.Meta.Windows != nil {
+		spec, err := gr.Meta.Windows.Spec()
+		if err != nil {
+			gr.Errors = append(gr.Errors, fmt.Sprintf(errFmtCorruptMeta, err))
+		} else {
+			opts = append(opts, usagetime.WithCalendarWindows(spec))
+		}
+	}
+	iter, err := usagetime.NewIterator(timeRange, time.Hour, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, errIterWindows)
+	}
+	for iter.More() {
+		window, err := iter.Next()
+		if err != nil {
+			return nil, errors.Wrap(err, errIterWindows)
+		}
+		gr.Windows++
+		count, hasValue := 0, false
+		for hour := window.Start.UTC(); hour.Before(window.End); hour = hour.Add(time.Hour) {
+			count += counts[hour]
+			hasValue = hasValue || nonzero[hour]
+		}
+		switch {
+		case count == 0:
+			gr.MissingWindows = append(gr.MissingWindows, window)
+		case !hasValue:
+			gr.EmptyWindows = append(gr.EmptyWindows, window)
+		}
+	}
+	return gr, nil
+}
+
+func readMeta(r io.Reader) (*report.Meta, error) {
+	meta := &report.Meta{}
+	if err := json.NewDecoder(r).Decode(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// metaErrors returns problems with the metadata of an archive.
+func metaErrors(meta *report.Meta, timeRange usagetime.Range) []string {
+	errs := []string{}
+	if meta.UpboundAccount == "" {
+		errs = append(errs, errNoAccount)
+	}
+	if !meta.TimeRange.Start.Equal(timeRange.Start) || !meta.TimeRange.End.Equal(timeRange.End) {
+		errs = append(errs, fmt.Sprintf(errFmtMetaTimeRange,
+			meta.TimeRange.Start.Format(time.RFC3339),
+			meta.TimeRange.End.Format(time.RFC3339),
+			timeRange.Start.Format(time.RFC3339),
+			timeRange.End.Format(time.RFC3339),
+		))
+	}
+	return errs
+}
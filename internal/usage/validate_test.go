@@ -0,0 +1,251 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/upbound/up/internal/usage/model"
+	"github.com/upbound/up/internal/usage/report"
+	reporttar "github.com/upbound/up/internal/usage/report/file/tar"
+	usagetime "github.com/upbound/up/internal/usage/time"
+)
+
+func TestValidate(t *testing.T) {
+	w1 := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 3, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+	}
+	w2 := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 4, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+	}
+	w3 := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 5, 0, 0, 0, time.UTC),
+		End:   time.Date(2006, 5, 4, 6, 0, 0, 0, time.UTC),
+	}
+	tr := usagetime.Range{Start: w1.Start, End: w3.End}
+	meta := report.Meta{UpboundAccount: "test-account", TimeRange: tr}
+	ev := func(w usagetime.Range, value float64) model.MXPGVKEvent {
+		return model.MXPGVKEvent{Name: "max_resource_count_per_gvk_per_mxp", Timestamp: w.Start, TimestampEnd: w.End, Value: value}
+	}
+
+	type want struct {
+		gr  *GapReport
+		err error
+	}
+	cases := map[string]struct {
+		reason  string
+		archive []byte
+		want    want
+	}{
+		"Complete": {
+			reason:  "An archive with events for every window is complete.",
+			archive: writeArchive(t, meta, ev(w1, 1), ev(w2, 2), ev(w3, 3)),
+			want: want{gr: &GapReport{
+				TimeRange:      tr,
+				Meta:           &meta,
+				Windows:        3,
+				MissingWindows: []usagetime.Range{},
+				EmptyWindows:   []usagetime.Range{},
+				Errors:         []string{},
+			}},
+		},
+		"Gaps": {
+			reason:  "Windows without events or with only zero values are reported.",
+			archive: writeArchive(t, meta, ev(w1, 1), ev(w2, 0)),
+			want: want{gr: &GapReport{
+				TimeRange:      tr,
+				Meta:           &meta,
+				Windows:        3,
+				MissingWindows: []usagetime.Range{w3},
+				EmptyWindows:   []usagetime.Range{w2},
+				Errors:         []string{},
+			}},
+		},
+		"MetaMismatch": {
+			reason:  "A meta file for a different time range or without an account is reported.",
+			archive: writeArchive(t, report.Meta{TimeRange: w1}, ev(w1, 1), ev(w2, 1), ev(w3, 1)),
+			want: want{gr: &GapReport{
+				TimeRange:      tr,
+				Meta:           &report.Meta{TimeRange: w1},
+				Windows:        3,
+				MissingWindows: []usagetime.Range{},
+				EmptyWindows:   []usagetime.Range{},
+				Errors: []string{
+					errNoAccount,
+					"meta file time range 2006-05-04T03:00:00Z to 2006-05-04T04:00:00Z does not match 2006-05-04T03:00:00Z to 2006-05-04T06:00:00Z",
+				},
+			}},
+		},
+		"CorruptFiles": {
+			reason: "Corrupt meta and usage files are reported.",
+			archive: writeFiles(t, map[string]string{
+				reporttar.MetaFilename:  "{",
+				reporttar.UsageFilename: "{}",
+			}),
+			want: want{gr: &GapReport{
+				TimeRange:      tr,
+				Windows:        3,
+				MissingWindows: []usagetime.Range{w1, w2, w3},
+				EmptyWindows:   []usagetime.Range{},
+				Errors: []string{
+					"meta file is corrupt: unexpected EOF",
+					"usage file is corrupt: reader does not contain JSON array. expected [, got {",
+				},
+			}},
+		},
+		"EmptyArchive": {
+			reason:  "Missing files are reported.",
+			archive: writeFiles(t, nil),
+			want: want{gr: &GapReport{
+				TimeRange:      tr,
+				Windows:        3,
+				MissingWindows: []usagetime.Range{w1, w2, w3},
+				EmptyWindows:   []usagetime.Range{},
+				Errors:         []string{errMissingMeta, errMissingUsage},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gr, err := Validate(tar.NewReader(bytes.NewReader(tc.archive)), tr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidate(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.gr, gr); diff != "" {
+				t.Errorf("\n%s\nValidate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateCalendarWindows(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation(...): %s", err)
+	}
+	d1 := usagetime.Range{
+		Start: time.Date(2006, 5, 4, 0, 0, 0, 0, loc),
+		End:   time.Date(2006, 5, 5, 0, 0, 0, 0, loc),
+	}
+	d2 := usagetime.Range{
+		Start: time.Date(2006, 5, 5, 0, 0, 0, 0, loc),
+		End:   time.Date(2006, 5, 6, 0, 0, 0, 0, loc),
+	}
+	tr := usagetime.Range{Start: d1.Start.UTC(), End: d2.End.UTC()}
+	meta := report.Meta{
+		UpboundAccount: "test-account",
+		TimeRange:      tr,
+		Windows:        report.NewWindows(usagetime.WindowSpec{Unit: usagetime.WindowUnitDay, Location: loc}),
+	}
+	ev := func(w usagetime.Range, value float64) model.MXPGVKEvent {
+		return model.MXPGVKEvent{Name: "max_resource_count_per_gvk_per_mxp", Timestamp: w.Start, TimestampEnd: w.End, Value: value}
+	}
+
+	cases := map[string]struct {
+		reason  string
+		archive []byte
+		want    *GapReport
+	}{
+		"Complete": {
+			reason:  "An archive with an event for every day window is complete.",
+			archive: writeArchive(t, meta, ev(d1, 1), ev(d2, 2)),
+			want: &GapReport{
+				TimeRange:      tr,
+				Meta:           &meta,
+				Windows:        2,
+				MissingWindows: []usagetime.Range{},
+				EmptyWindows:   []usagetime.Range{},
+				Errors:         []string{},
+			},
+		},
+		"Gaps": {
+			reason:  "Day windows without events are reported.",
+			archive: writeArchive(t, meta, ev(d1, 1)),
+			want: &GapReport{
+				TimeRange:      tr,
+				Meta:           &meta,
+				Windows:        2,
+				MissingWindows: []usagetime.Range{d2},
+				EmptyWindows:   []usagetime.Range{},
+				Errors:         []string{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gr, err := Validate(tar.NewReader(bytes.NewReader(tc.archive)), tr)
+			if err != nil {
+				t.Fatalf("\n%s\nValidate(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, gr, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+				t.Errorf("\n%s\nValidate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func writeArchive(t *testing.T, meta report.Meta, events ...model.MXPGVKEvent) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	w, err := reporttar.NewWriter(tw, meta)
+	if err != nil {
+		t.Fatalf("NewWriter(...): %s", err)
+	}
+	for _, e := range events {
+		if err := w.Write(e); err != nil {
+			t.Fatalf("Write(...): %s", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(): %s", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close(): %s", err)
+	}
+	return buf.Bytes()
+}
+
+func writeFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, name := range []string{reporttar.MetaFilename, reporttar.UsageFilename} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatalf("WriteHeader(...): %s", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Write(...): %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close(): %s", err)
+	}
+	return buf.Bytes()
+}
@@ -20,6 +20,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
@@ -119,8 +120,13 @@ func New(pkg, ab, ex parser.Backend, pp parser.Parser, ep *examples.Parser) *Bui
 	}
 }
 
+// DefaultTimestamp is the creation time recorded in packages built without
+// WithTimestamp. A fixed time keeps builds of the same sources reproducible.
+var DefaultTimestamp = time.Unix(0, 0).UTC()
+
 type buildOpts struct {
-	base v1.Image
+	base    v1.Image
+	created time.Time
 }
 
 // A BuildOpt modifies how a package is built.
@@ -134,6 +140,15 @@ func WithController(img v1.Image) BuildOpt {
 	}
 }
 
+// WithTimestamp sets the creation time recorded in the package. Builds of the
+// same sources with the same timestamp produce the same digest, e.g. when the
+// timestamp is taken from SOURCE_DATE_EPOCH.
+func WithTimestamp(t time.Time) BuildOpt {
+	return func(o *buildOpts) {
+		o.created = t.UTC()
+	}
+}
+
 type AuthExtension struct {
 	Version      string `yaml:"version"`
 	Discriminant string `yaml:"discriminant"`
@@ -151,7 +166,8 @@ type AuthExtension struct {
 // Build compiles a Crossplane package from an on-disk package.
 func (b *Builder) Build(ctx context.Context, opts ...BuildOpt) (v1.Image, runtime.Object, error) { // nolint:gocyclo
 	bOpts := &buildOpts{
-		base: empty.Image,
+		base:    empty.Image,
+		created: DefaultTimestamp,
 	}
 	for _, o := range opts {
 		o(bOpts)
@@ -272,8 +288,15 @@ func (b *Builder) Build(ctx context.Context, opts ...BuildOpt) (v1.Image, runtim
 		layers = append(layers, exLayer)
 	}
 
+	// Layers are always appended in the same order, package then examples,
+	// and stamped with a fixed time so that the digest only depends on the
+	// package sources.
+	created := v1.Time{Time: bOpts.created}
 	for _, l := range layers {
-		bOpts.base, err = mutate.AppendLayers(bOpts.base, l)
+		bOpts.base, err = mutate.Append(bOpts.base, mutate.Addendum{
+			Layer:   l,
+			History: v1.History{Created: created},
+		})
 		if err != nil {
 			return nil, nil, errors.Wrap(err, errBuildImage)
 		}
@@ -283,6 +306,10 @@ func (b *Builder) Build(ctx context.Context, opts ...BuildOpt) (v1.Image, runtim
 	if err != nil {
 		return nil, nil, errors.Wrap(err, errMutateConfig)
 	}
+	bOpts.base, err = mutate.CreatedAt(bOpts.base, created)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errMutateConfig)
+	}
 
 	return bOpts.base, meta, nil
 }
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/parser"
@@ -432,6 +433,57 @@ func TestBuildAuth(t *testing.T) {
 	}
 }
 
+func TestBuildReproducible(t *testing.T) {
+	pkgp, _ := yaml.New()
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/ws/crossplane.yaml", testMetav1alpha1, os.ModePerm)
+	_ = afero.WriteFile(fs, "/ws/crds/crd.yaml", testCRD, os.ModePerm)
+	_ = afero.WriteFile(fs, "/ws/examples/ec2/instance.yaml", testEx1, os.ModePerm)
+	_ = afero.WriteFile(fs, "/ws/examples/provider.yaml", testEx4, os.ModePerm)
+
+	build := func(opts ...BuildOpt) v1.Image {
+		t.Helper()
+		pkgBe := parser.NewFsBackend(fs, parser.FsDir("/ws"), parser.FsFilters(append(defaultFilters, SkipContains("examples/"))...))
+		pkgEx := parser.NewFsBackend(fs, parser.FsDir("/ws/examples"), parser.FsFilters(defaultFilters...))
+		img, _, err := New(pkgBe, nil, pkgEx, pkgp, examples.New()).Build(context.TODO(), opts...)
+		if err != nil {
+			t.Fatalf("Build(...): unexpected error: %v", err)
+		}
+		return img
+	}
+	digest := func(img v1.Image) string {
+		t.Helper()
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatalf("Digest(): unexpected error: %v", err)
+		}
+		return d.String()
+	}
+
+	first, second := build(), build()
+	if diff := cmp.Diff(digest(first), digest(second)); diff != "" {
+		t.Errorf("Build(...): builds of the same sources should have the same digest: -first, +second:\n%s", diff)
+	}
+
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	stamped := build(WithTimestamp(ts))
+	cfg, err := stamped.ConfigFile()
+	if err != nil {
+		t.Fatalf("ConfigFile(): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(ts, cfg.Created.Time); diff != "" {
+		t.Errorf("Build(...): -want created, +got created:\n%s", diff)
+	}
+	for _, h := range cfg.History {
+		if diff := cmp.Diff(ts, h.Created.Time); diff != "" {
+			t.Errorf("Build(...): -want history created, +got history created:\n%s", diff)
+		}
+	}
+	if digest(stamped) == digest(first) {
+		t.Errorf("Build(...): builds with different timestamps should have different digests")
+	}
+}
+
 type xpkgContents struct {
 	labels       []string
 	pkgBytes     []byte
@@ -0,0 +1,183 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lock resolves package dependencies to pinned versions and digests
+// and records them in a lock file.
+package lock
+
+import (
+	"context"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+const (
+	// DefaultFile is the name of the lock file in a package workspace.
+	DefaultFile = "up.lock"
+	// Version is the current lock file format version.
+	Version = "v1"
+
+	errReadLock       = "failed to read lock file"
+	errWriteLock      = "failed to write lock file"
+	errParseLock      = "failed to parse lock file"
+	errFmtLockVersion = "unsupported lock file version %q"
+	errFmtResolve     = "failed to resolve %s"
+	errFmtVendor      = "failed to vendor %s@%s"
+
+	// fileMode is the mode of written lock files.
+	fileMode = 0o644
+)
+
+// Lock is the set of packages, including transitive dependencies, that a
+// package's dependencies resolved to.
+type Lock struct {
+	Version  string    `json:"version"`
+	Packages []Package `json:"packages"`
+}
+
+// Package is a single resolved package.
+type Package struct {
+	// Package is the package reference without a tag, e.g.
+	// xpkg.upbound.io/crossplane/provider-aws.
+	Package string `json:"package"`
+	// Type is the type of the package.
+	Type v1beta1.PackageType `json:"type"`
+	// Version is the tag the package's constraints resolved to.
+	Version string `json:"version"`
+	// Digest is the digest the version resolved to.
+	Digest string `json:"digest"`
+	// Dependencies are the packages this package depends on.
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// Dependency returns the dependency pinned to the locked version.
+func (p Package) Dependency() v1beta1.Dependency {
+	return v1beta1.Dependency{
+		Package:     p.Package,
+		Type:        p.Type,
+		Constraints: p.Version,
+	}
+}
+
+// Get returns the locked package with the supplied reference.
+func (l *Lock) Get(pkg string) (Package, bool) {
+	for _, p := range l.Packages {
+		if p.Package == pkg {
+			return p, true
+		}
+	}
+	return Package{}, false
+}
+
+// Read reads the lock file at the supplied path.
+func Read(fs afero.Fs, path string) (*Lock, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadLock)
+	}
+	l := &Lock{}
+	if err := yaml.Unmarshal(b, l); err != nil {
+		return nil, errors.Wrap(err, errParseLock)
+	}
+	if l.Version != Version {
+		return nil, errors.Errorf(errFmtLockVersion, l.Version)
+	}
+	return l, nil
+}
+
+// Write writes the lock file to the supplied path.
+func Write(fs afero.Fs, path string, l *Lock) error {
+	b, err := yaml.Marshal(l)
+	if err != nil {
+		return errors.Wrap(err, errWriteLock)
+	}
+	return errors.Wrap(afero.WriteFile(fs, path, b, fileMode), errWriteLock)
+}
+
+// Manager resolves a dependency and its transitive dependencies and stores
+// them in its cache.
+type Manager interface {
+	AddAll(context.Context, v1beta1.Dependency) (v1beta1.Dependency, []*xpkg.ParsedPackage, error)
+	AddDigest(ctx context.Context, d v1beta1.Dependency, digest string) (*xpkg.ParsedPackage, error)
+}
+
+// Resolver produces lock files and vendors the packages they lock.
+type Resolver struct {
+	m Manager
+}
+
+// NewResolver returns a Resolver that resolves packages with the supplied
+// Manager.
+func NewResolver(m Manager) *Resolver {
+	return &Resolver{m: m}
+}
+
+// Lock resolves the supplied dependencies and their transitive dependencies
+// against their registries and returns a Lock pinning each to a version and
+// digest.
+func (r *Resolver) Lock(ctx context.Context, deps []v1beta1.Dependency) (*Lock, error) {
+	pkgs := make(map[string]Package)
+	for _, d := range deps {
+		_, acc, err := r.m.AddAll(ctx, d)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtResolve, d.Package)
+		}
+		for _, p := range acc {
+			pkgs[p.Name()] = newPackage(p)
+		}
+	}
+
+	l := &Lock{Version: Version, Packages: make([]Package, 0, len(pkgs))}
+	for _, p := range pkgs {
+		l.Packages = append(l.Packages, p)
+	}
+	sort.Slice(l.Packages, func(i, j int) bool {
+		return l.Packages[i].Package < l.Packages[j].Package
+	})
+	return l, nil
+}
+
+// Vendor stores every package in the supplied Lock at its locked version in
+// the Manager's cache, so that they can be resolved without access to their
+// registries. Packages are pulled by their locked digest, so tags that were
+// moved since the Lock was written do not change what is vendored. The Lock
+// includes transitive dependencies, so they are not resolved again.
+func (r *Resolver) Vendor(ctx context.Context, l *Lock) error {
+	for _, p := range l.Packages {
+		if _, err := r.m.AddDigest(ctx, p.Dependency(), p.Digest); err != nil {
+			return errors.Wrapf(err, errFmtVendor, p.Package, p.Digest)
+		}
+	}
+	return nil
+}
+
+func newPackage(p *xpkg.ParsedPackage) Package {
+	lp := Package{
+		Package: p.Name(),
+		Type:    p.Type(),
+		Version: p.Version(),
+		Digest:  p.Digest(),
+	}
+	for _, d := range p.Dependencies() {
+		lp.Dependencies = append(lp.Dependencies, d.Package)
+	}
+	sort.Strings(lp.Dependencies)
+	return lp
+}
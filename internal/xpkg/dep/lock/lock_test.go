@@ -0,0 +1,220 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+)
+
+var errBoom = errors.New("boom")
+
+type mockManager struct {
+	pkgs   map[string][]*xpkg.ParsedPackage
+	pulled []string
+}
+
+func (m *mockManager) AddAll(_ context.Context, d v1beta1.Dependency) (v1beta1.Dependency, []*xpkg.ParsedPackage, error) {
+	acc, ok := m.pkgs[d.Package]
+	if !ok {
+		return v1beta1.Dependency{}, nil, errors.New("boom")
+	}
+	return d, acc, nil
+}
+
+func (m *mockManager) AddDigest(_ context.Context, d v1beta1.Dependency, digest string) (*xpkg.ParsedPackage, error) {
+	for _, p := range m.pkgs[d.Package] {
+		if p.Name() == d.Package && p.Digest() == digest {
+			m.pulled = append(m.pulled, d.Package+"@"+digest)
+			return p, nil
+		}
+	}
+	return nil, errBoom
+}
+
+var (
+	aws = &xpkg.ParsedPackage{
+		DepName: "xpkg.upbound.io/upbound/provider-aws",
+		PType:   v1beta1.ProviderPackageType,
+		SHA:     "sha256:aws",
+		Ver:     "v0.40.0",
+	}
+	family = &xpkg.ParsedPackage{
+		DepName: "xpkg.upbound.io/upbound/provider-family-aws",
+		PType:   v1beta1.ProviderPackageType,
+		SHA:     "sha256:family",
+		Ver:     "v0.40.0",
+	}
+	platform = &xpkg.ParsedPackage{
+		DepName: "xpkg.upbound.io/upbound/platform-ref-aws",
+		PType:   v1beta1.ConfigurationPackageType,
+		SHA:     "sha256:platform",
+		Ver:     "v1.0.0",
+		Deps: []v1beta1.Dependency{
+			{Package: "xpkg.upbound.io/upbound/provider-aws", Constraints: ">=v0.30.0"},
+		},
+	}
+)
+
+func TestLock(t *testing.T) {
+	m := &mockManager{pkgs: map[string][]*xpkg.ParsedPackage{
+		platform.Name(): {platform, aws, family},
+		aws.Name():      {aws, family},
+	}}
+
+	type want struct {
+		lock *Lock
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		deps   []v1beta1.Dependency
+		want   want
+	}{
+		"TransitiveDependencies": {
+			reason: "Every resolved package should be locked once, sorted by package.",
+			deps: []v1beta1.Dependency{
+				{Package: platform.Name(), Constraints: "v1.0.0"},
+				{Package: aws.Name(), Constraints: ">=v0.40.0"},
+			},
+			want: want{
+				lock: &Lock{
+					Version: Version,
+					Packages: []Package{
+						{
+							Package:      platform.Name(),
+							Type:         v1beta1.ConfigurationPackageType,
+							Version:      "v1.0.0",
+							Digest:       "sha256:platform",
+							Dependencies: []string{aws.Name()},
+						},
+						{
+							Package: aws.Name(),
+							Type:    v1beta1.ProviderPackageType,
+							Version: "v0.40.0",
+							Digest:  "sha256:aws",
+						},
+						{
+							Package: family.Name(),
+							Type:    v1beta1.ProviderPackageType,
+							Version: "v0.40.0",
+							Digest:  "sha256:family",
+						},
+					},
+				},
+			},
+		},
+		"ResolveError": {
+			reason: "Errors resolving a dependency should be returned.",
+			deps: []v1beta1.Dependency{
+				{Package: "xpkg.upbound.io/upbound/missing"},
+			},
+			want: want{
+				err: errors.Wrapf(errors.New("boom"), errFmtResolve, "xpkg.upbound.io/upbound/missing"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l, err := NewResolver(m).Lock(context.Background(), tc.deps)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLock(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.lock, l); diff != "" {
+				t.Errorf("\n%s\nLock(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestVendor(t *testing.T) {
+	type want struct {
+		pulled []string
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		lock   *Lock
+		want   want
+	}{
+		"PullByDigest": {
+			reason: "Vendoring should pull every locked package, including transitive ones, by its locked digest.",
+			lock: &Lock{Version: Version, Packages: []Package{
+				{Package: aws.Name(), Version: "v0.40.0", Digest: "sha256:aws"},
+				{Package: family.Name(), Version: "v0.40.0", Digest: "sha256:family"},
+			}},
+			want: want{
+				pulled: []string{aws.Name() + "@sha256:aws", family.Name() + "@sha256:family"},
+			},
+		},
+		"DigestNotFound": {
+			reason: "Vendoring should fail if a locked digest cannot be pulled.",
+			lock: &Lock{Version: Version, Packages: []Package{
+				{Package: aws.Name(), Version: "v0.40.0", Digest: "sha256:old"},
+			}},
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtVendor, aws.Name(), "sha256:old"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &mockManager{pkgs: map[string][]*xpkg.ParsedPackage{
+				aws.Name():    {aws},
+				family.Name(): {family},
+			}}
+			err := NewResolver(m).Vendor(context.Background(), tc.lock)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nVendor(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.pulled, m.pulled); diff != "" {
+				t.Errorf("\n%s\nVendor(...): -want pulled, +got pulled:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	want := &Lock{Version: Version, Packages: []Package{
+		{Package: aws.Name(), Type: v1beta1.ProviderPackageType, Version: "v0.40.0", Digest: "sha256:aws"},
+	}}
+	if err := Write(fs, DefaultFile, want); err != nil {
+		t.Fatalf("Write(...): %v", err)
+	}
+	got, err := Read(fs, DefaultFile)
+	if err != nil {
+		t.Fatalf("Read(...): %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Read(Write(...)): -want, +got:\n%s", diff)
+	}
+
+	if err := afero.WriteFile(fs, DefaultFile, []byte("version: v0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Read(fs, DefaultFile)
+	if diff := cmp.Diff(errors.Errorf(errFmtLockVersion, "v0"), err, test.EquateErrors()); diff != "" {
+		t.Errorf("Read(...): -want error, +got error:\n%s", diff)
+	}
+}
@@ -45,6 +45,7 @@ const (
 	defaultWatchInterval = "100ms"
 
 	errInvalidSemVerConstraintFmt = "invalid semver constraint %v: %w"
+	errFmtDigestMismatch          = "fetched image of %s has digest %s, want %s"
 )
 
 // Manager defines a dependency Manager
@@ -72,6 +73,7 @@ type Cache interface {
 type ImageResolver interface {
 	ResolveDigest(context.Context, v1beta1.Dependency) (string, error)
 	ResolveImage(context.Context, v1beta1.Dependency) (string, v1.Image, error)
+	ResolveImageDigest(context.Context, v1beta1.Dependency, string) (v1.Image, error)
 	ResolveTag(context.Context, v1beta1.Dependency) (string, error)
 }
 
@@ -242,6 +244,24 @@ func (m *Manager) AddAll(ctx context.Context, d v1beta1.Dependency) (v1beta1.Dep
 	return ud, m.acc, nil
 }
 
+// AddDigest fetches the image of the given package with the supplied digest
+// and stores it in the cache as the version in the dependency's constraints.
+// Its transitive dependencies are not resolved.
+func (m *Manager) AddDigest(ctx context.Context, d v1beta1.Dependency, digest string) (*xpkg.ParsedPackage, error) {
+	i, err := m.i.ResolveImageDigest(ctx, d, digest)
+	if err != nil {
+		return nil, err
+	}
+	got, err := i.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if got.String() != digest {
+		return nil, fmt.Errorf(errFmtDigestMismatch, d.Package, got, digest)
+	}
+	return m.storeImage(d, d.Constraints, i)
+}
+
 func (m *Manager) retrieveAllDeps(ctx context.Context, p *xpkg.ParsedPackage) error {
 	if len(p.Dependencies()) == 0 {
 		// no remaining dependencies to resolve
@@ -293,7 +313,12 @@ func (m *Manager) addPkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.Parse
 	if err != nil {
 		return nil, err
 	}
+	return m.storeImage(d, t, i)
+}
 
+// storeImage stores the package image i in the cache as the supplied version
+// of the given dependency.
+func (m *Manager) storeImage(d v1beta1.Dependency, version string, i v1.Image) (*xpkg.ParsedPackage, error) {
 	tag, err := name.NewTag(d.Package)
 	if err != nil {
 		return nil, err
@@ -308,7 +333,7 @@ func (m *Manager) addPkg(ctx context.Context, d v1beta1.Dependency) (*xpkg.Parse
 		Meta: ixpkg.ImageMeta{
 			Repo:     deriveRepoName(tag),
 			Registry: tag.RegistryStr(),
-			Version:  t,
+			Version:  version,
 			Digest:   digest.String(),
 		},
 		Image: i,
@@ -18,6 +18,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"testing"
 
@@ -444,6 +445,86 @@ func TestSnapshot(t *testing.T) {
 	}
 }
 
+func TestAddDigest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c, _ := cache.NewLocal("/tmp/cache", cache.WithFS(fs))
+
+	dep := v1beta1.Dependency{
+		Package:     "registry.upbound.io/upbound/provider-aws",
+		Type:        v1beta1.ProviderPackageType,
+		Constraints: "v0.40.0",
+	}
+	meta := &metav1.Provider{
+		TypeMeta: apimetav1.TypeMeta{
+			APIVersion: "meta.pkg.crossplane.io/v1alpha1",
+			Kind:       "Provider",
+		},
+	}
+	h, _ := newPackageImage(meta).Digest()
+	locked := h.String()
+	moved := "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	type want struct {
+		digest string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		digest string
+		want   want
+	}{
+		"Success": {
+			reason: "Should fetch the package by its digest and store it in the cache.",
+			digest: locked,
+			want: want{
+				digest: locked,
+			},
+		},
+		"DigestMismatch": {
+			reason: "Should return an error if the fetched image does not have the requested digest.",
+			digest: moved,
+			want: want{
+				err: fmt.Errorf(errFmtDigestMismatch, dep.Package, locked, moved),
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			ref, _ := name.NewDigest(dep.Package + "@" + tc.digest)
+
+			m, _ := New(
+				WithCache(c),
+				WithResolver(
+					image.NewResolver(
+						image.WithFetcher(
+							NewMockFetcher(
+								WithPackageObjects(ref, meta),
+							),
+						),
+					),
+				),
+			)
+
+			p, err := m.AddDigest(context.Background(), dep, tc.digest)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAddDigest(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.digest, p.Digest()); diff != "" {
+				t.Errorf("\n%s\nAddDigest(...): -want digest, +got digest:\n%s", tc.reason, diff)
+			}
+			if _, err := m.c.Get(dep); err != nil {
+				t.Errorf("\n%s\nAddDigest(...): package not in cache: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
 type MockFetcher struct {
 	pkgMeta map[name.Reference][]runtime.Object
 	tags    []string
@@ -45,3 +45,54 @@ func (r *LocalFetcher) Head(ctx context.Context, ref name.Reference, secrets ...
 func (r *LocalFetcher) Tags(ctx context.Context, ref name.Reference, secrets ...string) ([]string, error) {
 	return remote.List(ref.Context(), remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
 }
+
+// ImageCache stores package images by digest.
+type ImageCache interface {
+	GetImage(v1.Hash) (v1.Image, error)
+	PutImage(v1.Image) (v1.Hash, error)
+}
+
+// CachedFetcher is a Fetcher that serves package images from an ImageCache,
+// only fetching images that are not yet cached.
+type CachedFetcher struct {
+	Fetcher
+	cache ImageCache
+}
+
+// NewCachedFetcher returns a Fetcher that caches images fetched by the
+// supplied Fetcher in the supplied ImageCache.
+func NewCachedFetcher(f Fetcher, c ImageCache) *CachedFetcher {
+	return &CachedFetcher{Fetcher: f, cache: c}
+}
+
+// Fetch fetches a package image, returning the cached image if one exists for
+// the digest the reference resolves to.
+func (f *CachedFetcher) Fetch(ctx context.Context, ref name.Reference, secrets ...string) (v1.Image, error) {
+	var h v1.Hash
+	if d, ok := ref.(name.Digest); ok {
+		dh, err := v1.NewHash(d.DigestStr())
+		if err != nil {
+			return nil, err
+		}
+		h = dh
+	} else {
+		desc, err := f.Fetcher.Head(ctx, ref, secrets...)
+		if err != nil {
+			return nil, err
+		}
+		h = desc.Digest
+	}
+	if img, err := f.cache.GetImage(h); err == nil {
+		return img, nil
+	}
+
+	img, err := f.Fetcher.Fetch(ctx, ref, secrets...)
+	if err != nil {
+		return nil, err
+	}
+	d, err := f.cache.PutImage(img)
+	if err != nil {
+		return nil, err
+	}
+	return f.cache.GetImage(d)
+}
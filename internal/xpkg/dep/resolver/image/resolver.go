@@ -32,7 +32,8 @@ const (
 	// DefaultVer effectively defines latest for the semver constraints
 	DefaultVer = ">=v0.0.0"
 
-	packageTagFmt = "%s:%s"
+	packageTagFmt    = "%s:%s"
+	packageDigestFmt = "%s@%s"
 
 	errInvalidConstraint  = "invalid dependency constraint"
 	errInvalidProviderRef = "invalid package reference"
@@ -96,6 +97,16 @@ func (r *Resolver) ResolveImage(ctx context.Context, dep v1beta1.Dependency) (st
 	return tag, i, err
 }
 
+// ResolveImageDigest fetches the image of the given v1beta1.Dependency with the
+// supplied digest, ignoring its version constraints.
+func (r *Resolver) ResolveImageDigest(ctx context.Context, dep v1beta1.Dependency, digest string) (v1.Image, error) {
+	ref, err := name.NewDigest(fmt.Sprintf(packageDigestFmt, dep.Package, digest))
+	if err != nil {
+		return nil, errors.Wrap(err, errInvalidProviderRef)
+	}
+	return r.f.Fetch(ctx, ref)
+}
+
 // ResolveTag resolves the tag corresponding to the given v1beta1.Dependency.
 // TODO(@tnthornton) add a test that flexes resolving constraint versions to the expected target version
 func (r *Resolver) ResolveTag(ctx context.Context, dep v1beta1.Dependency) (string, error) { // nolint:gocyclo
@@ -0,0 +1,202 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/apiextensions/fn/proto/v1beta1"
+)
+
+const (
+	// functionPort is the port that Functions serve gRPC on.
+	functionPort = "9443/tcp"
+
+	stopTimeout = 10 * time.Second
+
+	errDockerClient       = "cannot create Docker client"
+	errFmtNoImage         = "no image is configured for function %q"
+	errFmtPullImage       = "cannot pull image %q"
+	errFmtCreateContainer = "cannot create container for function %q"
+	errFmtStartContainer  = "cannot start container for function %q"
+	errFmtInspect         = "cannot inspect container for function %q"
+	errFmtNoPort          = "container for function %q does not publish port %s"
+	errStopContainer      = "cannot stop function container"
+)
+
+// dockerClient is the subset of the Docker API used to run Functions.
+type dockerClient interface {
+	ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error)
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error)
+	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+}
+
+// DockerRunner runs Composition Functions by starting their images in Docker
+// containers and calling them over gRPC. Each Function's container is started
+// the first time the Function is run. Must be initialized with
+// NewDockerRunner(). Callers must call Close() to stop the containers.
+type DockerRunner struct {
+	docker dockerClient
+	images map[string]string
+	pull   bool
+	grpc   *GRPCRunner
+
+	mu         sync.Mutex
+	containers map[string]string
+}
+
+// DockerRunnerOption modifies a DockerRunner.
+type DockerRunnerOption func(*DockerRunner)
+
+// WithDockerClient overrides the default Docker client, which is configured
+// from the environment.
+func WithDockerClient(c dockerClient) DockerRunnerOption {
+	return func(r *DockerRunner) {
+		r.docker = c
+	}
+}
+
+// WithPull sets whether images are pulled before their containers are
+// started. Images are pulled by default.
+func WithPull(pull bool) DockerRunnerOption {
+	return func(r *DockerRunner) {
+		r.pull = pull
+	}
+}
+
+// NewDockerRunner returns a runner that runs each Function using the image it
+// is mapped to by name in images.
+func NewDockerRunner(images map[string]string, opts ...DockerRunnerOption) (*DockerRunner, error) {
+	r := &DockerRunner{
+		images:     images,
+		pull:       true,
+		grpc:       NewGRPCRunner(nil),
+		containers: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if r.docker == nil {
+		c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if err != nil {
+			return nil, errors.Wrap(err, errDockerClient)
+		}
+		r.docker = c
+	}
+	return r, nil
+}
+
+// RunFunction runs the named Function with the supplied request, starting its
+// container if necessary.
+func (r *DockerRunner) RunFunction(ctx context.Context, name string, req *v1beta1.RunFunctionRequest) (*v1beta1.RunFunctionResponse, error) {
+	if err := r.start(ctx, name); err != nil {
+		return nil, err
+	}
+	return r.grpc.RunFunction(ctx, name, req)
+}
+
+// Close stops all Function containers. Containers are removed once stopped.
+func (r *DockerRunner) Close() error {
+	if err := r.grpc.Close(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := stopTimeout
+	for name, id := range r.containers {
+		if err := r.docker.ContainerStop(context.Background(), id, &t); err != nil {
+			return errors.Wrap(err, errStopContainer)
+		}
+		delete(r.containers, name)
+	}
+	return nil
+}
+
+func (r *DockerRunner) start(ctx context.Context, name string) error { //nolint:gocyclo // Each step is a single Docker API call.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.containers[name]; ok {
+		return nil
+	}
+	image, ok := r.images[name]
+	if !ok {
+		return errors.Errorf(errFmtNoImage, name)
+	}
+
+	if r.pull {
+		rc, err := r.docker.ImagePull(ctx, image, types.ImagePullOptions{})
+		if err != nil {
+			return errors.Wrapf(err, errFmtPullImage, image)
+		}
+		// The pull completes when its progress stream has been read.
+		_, err = io.Copy(io.Discard, rc)
+		_ = rc.Close()
+		if err != nil {
+			return errors.Wrapf(err, errFmtPullImage, image)
+		}
+	}
+
+	cfg := &container.Config{
+		Image:        image,
+		Cmd:          []string{"--insecure"},
+		ExposedPorts: nat.PortSet{functionPort: struct{}{}},
+	}
+	hcfg := &container.HostConfig{
+		// Publish the Function's port on a random local port.
+		PortBindings: nat.PortMap{functionPort: []nat.PortBinding{{HostIP: "127.0.0.1"}}},
+		AutoRemove:   true,
+	}
+	created, err := r.docker.ContainerCreate(ctx, cfg, hcfg, nil, nil, "")
+	if err != nil {
+		return errors.Wrapf(err, errFmtCreateContainer, name)
+	}
+	if err := r.docker.ContainerStart(ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		// A container that never started is not removed automatically. The
+		// start error is more useful to the caller than a removal error.
+		_ = r.docker.ContainerRemove(context.Background(), created.ID, types.ContainerRemoveOptions{Force: true})
+		return errors.Wrapf(err, errFmtStartContainer, name)
+	}
+
+	// A container that can't be called is removed rather than recorded, so
+	// that it isn't left running and the next run starts a new one.
+	c, err := r.docker.ContainerInspect(ctx, created.ID)
+	if err != nil {
+		_ = r.docker.ContainerRemove(context.Background(), created.ID, types.ContainerRemoveOptions{Force: true})
+		return errors.Wrapf(err, errFmtInspect, name)
+	}
+	if c.NetworkSettings == nil || len(c.NetworkSettings.Ports[functionPort]) == 0 {
+		_ = r.docker.ContainerRemove(context.Background(), created.ID, types.ContainerRemoveOptions{Force: true})
+		return errors.Errorf(errFmtNoPort, name, functionPort)
+	}
+	r.containers[name] = created.ID
+	b := c.NetworkSettings.Ports[functionPort][0]
+	r.grpc.setAddress(name, net.JoinHostPort(b.HostIP, b.HostPort))
+	return nil
+}
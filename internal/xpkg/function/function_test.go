@@ -0,0 +1,224 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/google/go-cmp/cmp"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/crossplane/crossplane/apis/apiextensions/fn/proto/v1beta1"
+)
+
+// echoFunction returns the desired state of each request it receives.
+type echoFunction struct {
+	v1beta1.UnimplementedFunctionRunnerServiceServer
+}
+
+func (f *echoFunction) RunFunction(_ context.Context, req *v1beta1.RunFunctionRequest) (*v1beta1.RunFunctionResponse, error) {
+	return &v1beta1.RunFunctionResponse{Desired: req.GetDesired()}, nil
+}
+
+// serveFunction serves an echoFunction on a random local port and returns its
+// address.
+func serveFunction(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen(...): %s", err)
+	}
+	srv := grpc.NewServer()
+	v1beta1.RegisterFunctionRunnerServiceServer(srv, &echoFunction{})
+	go srv.Serve(lis) //nolint:errcheck
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func TestGRPCRunner(t *testing.T) {
+	addr := serveFunction(t)
+	req := &v1beta1.RunFunctionRequest{Desired: &v1beta1.State{Resources: map[string]*v1beta1.Resource{"a": {}}}}
+
+	type want struct {
+		rsp *v1beta1.RunFunctionResponse
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   want
+	}{
+		"RunFunction": {
+			reason: "A function with a known address should be run.",
+			name:   "function-echo",
+			want:   want{rsp: &v1beta1.RunFunctionResponse{Desired: req.GetDesired()}},
+		},
+		"UnknownFunction": {
+			reason: "A function without an address should return an error.",
+			name:   "function-unknown",
+			want:   want{err: errors.Errorf(errFmtNoAddress, "function-unknown")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewGRPCRunner(map[string]string{"function-echo": addr})
+			defer r.Close() //nolint:errcheck
+			rsp, err := r.RunFunction(context.Background(), tc.name, req)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRunFunction(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rsp, rsp, protocmp.Transform()); diff != "" {
+				t.Errorf("\n%s\nRunFunction(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// fakeDocker publishes the port of an already running Function for every
+// container it starts.
+type fakeDocker struct {
+	addr       string
+	startErr   error
+	inspectErr error
+	created    []string
+	pulled     []string
+	stopped    []string
+	removed    []string
+}
+
+func (d *fakeDocker) ImagePull(_ context.Context, ref string, _ types.ImagePullOptions) (io.ReadCloser, error) {
+	d.pulled = append(d.pulled, ref)
+	return io.NopCloser(strings.NewReader("{}")), nil
+}
+
+func (d *fakeDocker) ContainerCreate(_ context.Context, cfg *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ *specs.Platform, _ string) (container.ContainerCreateCreatedBody, error) {
+	d.created = append(d.created, "id-"+cfg.Image)
+	return container.ContainerCreateCreatedBody{ID: "id-" + cfg.Image}, nil
+}
+
+func (d *fakeDocker) ContainerStart(_ context.Context, _ string, _ types.ContainerStartOptions) error {
+	return d.startErr
+}
+
+func (d *fakeDocker) ContainerInspect(_ context.Context, _ string) (types.ContainerJSON, error) {
+	if d.inspectErr != nil {
+		return types.ContainerJSON{}, d.inspectErr
+	}
+	host, port, _ := net.SplitHostPort(d.addr)
+	return types.ContainerJSON{NetworkSettings: &types.NetworkSettings{
+		NetworkSettingsBase: types.NetworkSettingsBase{
+			Ports: nat.PortMap{functionPort: []nat.PortBinding{{HostIP: host, HostPort: port}}},
+		},
+	}}, nil
+}
+
+func (d *fakeDocker) ContainerStop(_ context.Context, id string, _ *time.Duration) error {
+	d.stopped = append(d.stopped, id)
+	return nil
+}
+
+func (d *fakeDocker) ContainerRemove(_ context.Context, id string, _ types.ContainerRemoveOptions) error {
+	d.removed = append(d.removed, id)
+	return nil
+}
+
+func TestDockerRunner(t *testing.T) {
+	d := &fakeDocker{addr: serveFunction(t)}
+	r, err := NewDockerRunner(map[string]string{"function-echo": "xpkg.upbound.io/example/function-echo:v0.1.0"}, WithDockerClient(d))
+	if err != nil {
+		t.Fatalf("NewDockerRunner(...): %s", err)
+	}
+
+	req := &v1beta1.RunFunctionRequest{Desired: &v1beta1.State{}}
+	for i := 0; i < 2; i++ {
+		if _, err := r.RunFunction(context.Background(), "function-echo", req); err != nil {
+			t.Fatalf("RunFunction(...) call "+strconv.Itoa(i)+": %s", err)
+		}
+	}
+	if _, err := r.RunFunction(context.Background(), "function-unknown", req); err == nil {
+		t.Errorf("RunFunction(...): expected error for function without an image")
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close(): %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"xpkg.upbound.io/example/function-echo:v0.1.0"}, d.pulled); diff != "" {
+		t.Errorf("ImagePull(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"id-xpkg.upbound.io/example/function-echo:v0.1.0"}, d.stopped); diff != "" {
+		t.Errorf("ContainerStop(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestDockerRunnerStartError(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		docker *fakeDocker
+	}{
+		"StartError": {
+			reason: "A container that failed to start should be removed.",
+			docker: &fakeDocker{startErr: errors.New("boom")},
+		},
+		"InspectError": {
+			reason: "A container that could not be inspected should be removed, not stopped, and a new one started by the next run.",
+			docker: &fakeDocker{inspectErr: errors.New("boom")},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := tc.docker
+			r, err := NewDockerRunner(map[string]string{"function-echo": "xpkg.upbound.io/example/function-echo:v0.1.0"}, WithDockerClient(d), WithPull(false))
+			if err != nil {
+				t.Fatalf("NewDockerRunner(...): %s", err)
+			}
+
+			req := &v1beta1.RunFunctionRequest{Desired: &v1beta1.State{}}
+			for i := 0; i < 2; i++ {
+				if _, err := r.RunFunction(context.Background(), "function-echo", req); err == nil {
+					t.Errorf("\n%s\nRunFunction(...) call %d: expected error", tc.reason, i)
+				}
+			}
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close(): %s", err)
+			}
+
+			ids := []string{"id-xpkg.upbound.io/example/function-echo:v0.1.0", "id-xpkg.upbound.io/example/function-echo:v0.1.0"}
+			if diff := cmp.Diff(ids, d.created); diff != "" {
+				t.Errorf("\n%s\nContainerCreate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(ids, d.removed); diff != "" {
+				t.Errorf("\n%s\nContainerRemove(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if len(d.stopped) != 0 {
+				t.Errorf("\n%s\nContainerStop(...): stopped %v, want no containers", tc.reason, d.stopped)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package function runs Composition Functions locally.
+package function
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane/apis/apiextensions/fn/proto/v1beta1"
+)
+
+const (
+	// runFunctionTimeout bounds how long a single Function may take to run,
+	// including waiting for its gRPC server to become ready.
+	runFunctionTimeout = 1 * time.Minute
+
+	errFmtNoAddress    = "no address is configured for function %q"
+	errFmtDialFunction = "cannot dial function %q at %q"
+	errFmtRunFunction  = "cannot run function %q"
+	errCloseConn       = "cannot close gRPC connection"
+)
+
+// GRPCRunner runs Composition Functions that are served over gRPC at known
+// addresses. Must be initialized with NewGRPCRunner(). Callers must call
+// Close() when finished with the runner.
+type GRPCRunner struct {
+	creds credentials.TransportCredentials
+
+	mu    sync.Mutex
+	addrs map[string]string
+	conns map[string]*grpc.ClientConn
+}
+
+// GRPCRunnerOption modifies a GRPCRunner.
+type GRPCRunnerOption func(*GRPCRunner)
+
+// WithTransportCredentials sets the credentials used to connect to Functions.
+// Connections are insecure by default.
+func WithTransportCredentials(c credentials.TransportCredentials) GRPCRunnerOption {
+	return func(r *GRPCRunner) {
+		r.creds = c
+	}
+}
+
+// NewGRPCRunner returns a runner that runs each Function at the address it is
+// mapped to by name in addrs.
+func NewGRPCRunner(addrs map[string]string, opts ...GRPCRunnerOption) *GRPCRunner {
+	r := &GRPCRunner{
+		creds: insecure.NewCredentials(),
+		addrs: make(map[string]string, len(addrs)),
+		conns: make(map[string]*grpc.ClientConn),
+	}
+	for name, addr := range addrs {
+		r.addrs[name] = addr
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// RunFunction runs the named Function with the supplied request.
+func (r *GRPCRunner) RunFunction(ctx context.Context, name string, req *v1beta1.RunFunctionRequest) (*v1beta1.RunFunctionResponse, error) {
+	conn, err := r.conn(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, runFunctionTimeout)
+	defer cancel()
+
+	// Wait for the Function to become ready rather than failing fast, so
+	// that Functions that are still starting up can be run.
+	rsp, err := v1beta1.NewFunctionRunnerServiceClient(conn).RunFunction(ctx, req, grpc.WaitForReady(true))
+	return rsp, errors.Wrapf(err, errFmtRunFunction, name)
+}
+
+// Close closes all connections to Functions.
+func (r *GRPCRunner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, conn := range r.conns {
+		if err := conn.Close(); err != nil {
+			return errors.Wrap(err, errCloseConn)
+		}
+		delete(r.conns, name)
+	}
+	return nil
+}
+
+// setAddress sets the address of the named Function.
+func (r *GRPCRunner) setAddress(name, addr string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addrs[name] = addr
+}
+
+func (r *GRPCRunner) conn(name string) (*grpc.ClientConn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if conn, ok := r.conns[name]; ok {
+		return conn, nil
+	}
+	addr, ok := r.addrs[name]
+	if !ok {
+		return nil, errors.Errorf(errFmtNoAddress, name)
+	}
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(r.creds))
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtDialFunction, name, addr)
+	}
+	r.conns[name] = conn
+	return conn, nil
+}
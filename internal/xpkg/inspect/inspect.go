@@ -0,0 +1,220 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inspect pulls packages and extracts their contents into typed
+// structs, without installing them into a cluster.
+package inspect
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg"
+	pxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+	"github.com/upbound/up/internal/xpkg/scheme"
+)
+
+const (
+	// ProviderConfigKind is the kind of the CRD a provider uses to configure
+	// credentials.
+	ProviderConfigKind = "ProviderConfig"
+
+	errParseReference = "failed to parse package reference"
+	errFetchPackage   = "failed to fetch package"
+	errPackageDigest  = "failed to get package digest"
+	errParsePackage   = "failed to parse package"
+	errConvertMeta    = "failed to convert package meta"
+)
+
+// Package is the typed contents of a package.
+type Package struct {
+	// Ref is the reference the package was pulled from.
+	Ref string `json:"ref"`
+	// Digest is the digest of the package image.
+	Digest string `json:"digest"`
+	// Type is the type of the package.
+	Type v1beta1.PackageType `json:"type"`
+
+	// Configuration is the package meta if the package is a Configuration.
+	Configuration *pkgmetav1.Configuration `json:"configuration,omitempty"`
+	// Provider is the package meta if the package is a Provider.
+	Provider *pkgmetav1.Provider `json:"provider,omitempty"`
+	// Dependencies are the packages the package depends on.
+	Dependencies []v1beta1.Dependency `json:"dependencies,omitempty"`
+
+	// XRDs are the CompositeResourceDefinitions the package ships.
+	XRDs []*xpv1.CompositeResourceDefinition `json:"xrds,omitempty"`
+	// Compositions are the Compositions the package ships.
+	Compositions []*xpv1.Composition `json:"compositions,omitempty"`
+	// CRDs are the CustomResourceDefinitions the package ships.
+	CRDs []*extv1.CustomResourceDefinition `json:"crds,omitempty"`
+}
+
+// Name returns the name of the package from its meta.
+func (p *Package) Name() string {
+	switch {
+	case p.Configuration != nil:
+		return p.Configuration.GetName()
+	case p.Provider != nil:
+		return p.Provider.GetName()
+	}
+	return ""
+}
+
+// ProviderConfigs returns the CRDs the package ships for configuring a
+// provider.
+func (p *Package) ProviderConfigs() []*extv1.CustomResourceDefinition {
+	out := []*extv1.CustomResourceDefinition{}
+	for _, crd := range p.CRDs {
+		if crd.Spec.Names.Kind == ProviderConfigKind {
+			out = append(out, crd)
+		}
+	}
+	return out
+}
+
+// Marshaler parses package images.
+type Marshaler interface {
+	FromImage(xpkg.Image) (*pxpkg.ParsedPackage, error)
+}
+
+// Option modifies an Inspector.
+type Option func(*Inspector)
+
+// WithFetcher sets the Fetcher used to pull packages.
+func WithFetcher(f image.Fetcher) Option {
+	return func(i *Inspector) {
+		i.f = f
+	}
+}
+
+// WithMarshaler sets the Marshaler used to parse packages.
+func WithMarshaler(m Marshaler) Option {
+	return func(i *Inspector) {
+		i.m = m
+	}
+}
+
+// WithDefaultRegistry sets the registry used for references that do not
+// specify one.
+func WithDefaultRegistry(r string) Option {
+	return func(i *Inspector) {
+		i.registry = r
+	}
+}
+
+// Inspector pulls packages and extracts their contents.
+type Inspector struct {
+	f        image.Fetcher
+	m        Marshaler
+	registry string
+}
+
+// New returns a new Inspector.
+func New(opts ...Option) (*Inspector, error) {
+	m, err := pxpkg.NewMarshaler()
+	if err != nil {
+		return nil, err
+	}
+	i := &Inspector{
+		f:        image.NewLocalFetcher(),
+		m:        m,
+		registry: name.DefaultRegistry,
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	return i, nil
+}
+
+// Inspect pulls the package with the supplied reference and returns its
+// contents.
+func (i *Inspector) Inspect(ctx context.Context, ref string) (*Package, error) {
+	r, err := name.ParseReference(ref, name.WithDefaultRegistry(i.registry))
+	if err != nil {
+		return nil, errors.Wrap(err, errParseReference)
+	}
+	img, err := i.f.Fetch(ctx, r)
+	if err != nil {
+		return nil, errors.Wrap(err, errFetchPackage)
+	}
+	return i.FromImage(r, img)
+}
+
+// FromImage returns the contents of the supplied package image.
+func (i *Inspector) FromImage(ref name.Reference, img v1.Image) (*Package, error) {
+	d, err := img.Digest()
+	if err != nil {
+		return nil, errors.Wrap(err, errPackageDigest)
+	}
+	p, err := i.m.FromImage(xpkg.Image{
+		Meta: xpkg.ImageMeta{
+			Repo:     ref.Context().Name(),
+			Registry: ref.Context().RegistryStr(),
+			Version:  ref.Identifier(),
+			Digest:   d.String(),
+		},
+		Image: img,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePackage)
+	}
+	out, err := FromParsed(p)
+	if err != nil {
+		return nil, err
+	}
+	out.Ref = ref.Name()
+	return out, nil
+}
+
+// FromParsed returns the typed contents of the supplied parsed package.
+func FromParsed(p *pxpkg.ParsedPackage) (*Package, error) {
+	out := &Package{
+		Ref:          p.Name(),
+		Digest:       p.Digest(),
+		Type:         p.Type(),
+		Dependencies: p.Dependencies(),
+	}
+
+	// Older meta versions are converted to v1.
+	meta, _ := scheme.TryConvert(p.Meta(), &pkgmetav1.Configuration{}, &pkgmetav1.Provider{})
+	switch m := meta.(type) {
+	case *pkgmetav1.Configuration:
+		out.Configuration = m
+	case *pkgmetav1.Provider:
+		out.Provider = m
+	default:
+		return nil, errors.New(errConvertMeta)
+	}
+
+	for _, o := range p.Objects() {
+		switch obj := o.(type) {
+		case *xpv1.CompositeResourceDefinition:
+			out.XRDs = append(out.XRDs, obj)
+		case *xpv1.Composition:
+			out.Compositions = append(out.Compositions, obj)
+		case *extv1.CustomResourceDefinition:
+			out.CRDs = append(out.CRDs, obj)
+		}
+	}
+	return out, nil
+}
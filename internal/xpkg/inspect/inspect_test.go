@@ -0,0 +1,257 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	"github.com/upbound/up/internal/xpkg"
+)
+
+var (
+	configurationPkg = `
+apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref
+spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws
+    version: ">=v0.40.0"
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: clusters.platform.example.org
+spec:
+  group: platform.example.org
+  names:
+    kind: Cluster
+    plural: clusters
+  claimNames:
+    kind: ClusterClaim
+    plural: clusterclaims
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: clusters.aws.platform.example.org
+spec:
+  compositeTypeRef:
+    apiVersion: platform.example.org/v1alpha1
+    kind: Cluster
+  resources: []
+`
+	providerPkg = `
+apiVersion: meta.pkg.crossplane.io/v1alpha1
+kind: Provider
+metadata:
+  name: provider-example
+spec:
+  controller:
+    image: example/provider-example:v0.1.0
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: buckets.example.org
+spec:
+  group: example.org
+  names:
+    kind: Bucket
+    plural: buckets
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: providerconfigs.example.org
+spec:
+  group: example.org
+  names:
+    kind: ProviderConfig
+    plural: providerconfigs
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+)
+
+type mockFetcher struct {
+	imgs map[string]v1.Image
+}
+
+func (m *mockFetcher) Fetch(_ context.Context, ref name.Reference, _ ...string) (v1.Image, error) {
+	img, ok := m.imgs[ref.Name()]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return img, nil
+}
+
+func (m *mockFetcher) Head(_ context.Context, _ name.Reference, _ ...string) (*v1.Descriptor, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockFetcher) Tags(_ context.Context, _ name.Reference, _ ...string) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func packageImage(t *testing.T, stream string) v1.Image {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name: xpkg.StreamFile,
+		Mode: int64(xpkg.StreamFileMode),
+		Size: int64(len(stream)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(tw, stream); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(empty.Image, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestInspect(t *testing.T) {
+	f := &mockFetcher{imgs: map[string]v1.Image{
+		"xpkg.upbound.io/example/platform-ref:v1.0.0":     packageImage(t, configurationPkg),
+		"xpkg.upbound.io/example/provider-example:v0.1.0": packageImage(t, providerPkg),
+	}}
+
+	type want struct {
+		name            string
+		typ             v1beta1.PackageType
+		deps            []v1beta1.Dependency
+		xrds            []string
+		compositions    []string
+		crds            []string
+		providerConfigs []string
+		err             error
+	}
+	cases := map[string]struct {
+		reason string
+		ref    string
+		want   want
+	}{
+		"Configuration": {
+			reason: "A Configuration's XRDs, Compositions and dependencies should be extracted.",
+			ref:    "example/platform-ref:v1.0.0",
+			want: want{
+				name: "platform-ref",
+				typ:  v1beta1.ConfigurationPackageType,
+				deps: []v1beta1.Dependency{{
+					Package:     "xpkg.upbound.io/upbound/provider-aws",
+					Type:        v1beta1.ProviderPackageType,
+					Constraints: ">=v0.40.0",
+				}},
+				xrds:         []string{"clusters.platform.example.org"},
+				compositions: []string{"clusters.aws.platform.example.org"},
+			},
+		},
+		"Provider": {
+			reason: "A Provider's CRDs and provider configs should be extracted, converting older meta.",
+			ref:    "example/provider-example:v0.1.0",
+			want: want{
+				name:            "provider-example",
+				typ:             v1beta1.ProviderPackageType,
+				deps:            []v1beta1.Dependency{},
+				crds:            []string{"buckets.example.org", "providerconfigs.example.org"},
+				providerConfigs: []string{"providerconfigs.example.org"},
+			},
+		},
+		"FetchError": {
+			reason: "Errors fetching the package should be returned.",
+			ref:    "example/missing:v0.1.0",
+			want: want{
+				err: errors.Wrap(errors.New("not found"), errFetchPackage),
+			},
+		},
+	}
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			i, err := New(WithFetcher(f), WithDefaultRegistry("xpkg.upbound.io"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkg, err := i.Inspect(context.Background(), tc.ref)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nInspect(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			got := want{
+				name: pkg.Name(),
+				typ:  pkg.Type,
+				deps: pkg.Dependencies,
+			}
+			for _, x := range pkg.XRDs {
+				got.xrds = append(got.xrds, x.GetName())
+			}
+			for _, c := range pkg.Compositions {
+				got.compositions = append(got.compositions, c.GetName())
+			}
+			for _, c := range pkg.CRDs {
+				got.crds = append(got.crds, c.GetName())
+			}
+			for _, c := range pkg.ProviderConfigs() {
+				got.providerConfigs = append(got.providerConfigs, c.GetName())
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nInspect(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint runs rule-based checks over the contents of a package and
+// reports findings with a severity.
+package lint
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	errFmtUnknownSeverity = "unknown severity %q: must be one of info, warning, error"
+)
+
+// A Severity of a finding.
+type Severity string
+
+// Severities in increasing order.
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+var severityRank = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// ParseSeverity parses the supplied severity.
+func ParseSeverity(s string) (Severity, error) {
+	if _, ok := severityRank[Severity(s)]; !ok {
+		return "", errors.Errorf(errFmtUnknownSeverity, s)
+	}
+	return Severity(s), nil
+}
+
+// AtLeast returns true if the severity is at least as severe as the supplied
+// one.
+func (s Severity) AtLeast(o Severity) bool {
+	return severityRank[s] >= severityRank[o]
+}
+
+// A Package to lint.
+type Package struct {
+	// Meta is the package metadata, i.e. the contents of crossplane.yaml.
+	Meta runtime.Object
+
+	// Objects are the objects shipped by the package.
+	Objects []runtime.Object
+
+	// Examples are the example objects of the package.
+	Examples []unstructured.Unstructured
+}
+
+// An Issue found by a rule.
+type Issue struct {
+	// Object identifies the object the issue was found in, if any.
+	Object string
+
+	// Message describes the issue.
+	Message string
+}
+
+// A CheckFn checks a package and returns the issues it found.
+type CheckFn func(pkg *Package) []Issue
+
+// A Rule checks packages for a class of issues.
+type Rule struct {
+	// Name uniquely identifies the rule.
+	Name string
+
+	// Description describes what the rule checks.
+	Description string
+
+	// Severity of the issues found by the rule, unless overridden.
+	Severity Severity
+
+	// Check runs the rule.
+	Check CheckFn
+}
+
+// A Finding is an issue found by a rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Object   string   `json:"object,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// A Report of linting a package.
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Count returns the number of findings at or above the supplied severity.
+func (r *Report) Count(s Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity.AtLeast(s) {
+			n++
+		}
+	}
+	return n
+}
+
+// A Linter lints packages.
+type Linter struct {
+	rules      []Rule
+	disabled   map[string]bool
+	severities map[string]Severity
+}
+
+// An Option modifies a Linter.
+type Option func(*Linter)
+
+// WithRules adds the supplied rules to the linter, e.g. custom rules in
+// addition to the DefaultRules.
+func WithRules(r ...Rule) Option {
+	return func(l *Linter) {
+		l.rules = append(l.rules, r...)
+	}
+}
+
+// WithDisabledRules disables the supplied rules.
+func WithDisabledRules(names ...string) Option {
+	return func(l *Linter) {
+		for _, n := range names {
+			l.disabled[n] = true
+		}
+	}
+}
+
+// WithSeverity overrides the severity of the issues found by a rule.
+func WithSeverity(rule string, s Severity) Option {
+	return func(l *Linter) {
+		l.severities[rule] = s
+	}
+}
+
+// New returns a Linter that runs the DefaultRules.
+func New(opts ...Option) *Linter {
+	l := &Linter{
+		rules:      DefaultRules(),
+		disabled:   map[string]bool{},
+		severities: map[string]Severity{},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// Rules returns the rules the linter runs, including disabled ones.
+func (l *Linter) Rules() []Rule {
+	return l.rules
+}
+
+// Lint runs all enabled rules against the supplied package. Findings are
+// reported in rule order.
+func (l *Linter) Lint(pkg *Package) *Report {
+	r := &Report{Findings: []Finding{}}
+	for _, rule := range l.rules {
+		if l.disabled[rule.Name] || rule.Check == nil {
+			continue
+		}
+		sev := rule.Severity
+		if s, ok := l.severities[rule.Name]; ok {
+			sev = s
+		}
+		for _, i := range rule.Check(pkg) {
+			r.Findings = append(r.Findings, Finding{
+				Rule:     rule.Name,
+				Severity: sev,
+				Object:   i.Object,
+				Message:  i.Message,
+			})
+		}
+	}
+	return r
+}
@@ -0,0 +1,268 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/upbound/up/internal/xpkg/parser/yaml"
+)
+
+const configurationPkg = `
+apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref
+  annotations:
+    meta.crossplane.io/description: A reference platform.
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: clusters.platform.example.org
+spec:
+  group: platform.example.org
+  names:
+    kind: Cluster
+    plural: clusters
+  claimNames:
+    kind: ClusterClaim
+    plural: clusterclaims
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              region:
+                type: string
+                description: Region to deploy to.
+              nodes:
+                type: array
+                description: Node pools.
+                items:
+                  type: object
+                  properties:
+                    size:
+                      type: string
+              parameters:
+                type: object
+                description: Free-form parameters.
+                x-kubernetes-preserve-unknown-fields: true
+          status:
+            type: object
+            properties:
+              endpoint:
+                type: string
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: clusters.aws.platform.example.org
+spec:
+  compositeTypeRef:
+    apiVersion: platform.example.org/v1alpha1
+    kind: Cluster
+  patchSets:
+  - name: common
+    patches:
+    - fromFieldPath: metadata.labels[team]
+      toFieldPath: metadata.labels[team]
+  resources:
+  - name: cluster
+    base:
+      apiVersion: eks.aws.upbound.io/v1beta1
+      kind: Cluster
+    patches:
+    - type: PatchSet
+      patchSetName: common
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    - fromFieldPath: spec.nodes[0].size
+      toFieldPath: spec.forProvider.size
+    - fromFieldPath: spec.parameters.anything.goes
+      toFieldPath: spec.forProvider.tags[x]
+    - type: ToCompositeFieldPath
+      fromFieldPath: status.atProvider.endpoint
+      toFieldPath: status.endpoint
+    - type: ToCompositeFieldPath
+      fromFieldPath: spec.compositionRef.name
+`
+
+func parse(t *testing.T, stream string) *Package {
+	t.Helper()
+	pp, err := yaml.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := pp.Parse(context.Background(), io.NopCloser(strings.NewReader(stream)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Package{Meta: p.GetMeta()[0], Objects: p.GetObjects()}
+}
+
+func example(t *testing.T, apiVersion, kind string) unstructured.Unstructured {
+	t.Helper()
+	u := unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetName("example")
+	return u
+}
+
+func TestLint(t *testing.T) {
+	clean := func(t *testing.T) *Package {
+		t.Helper()
+		pkg := parse(t, configurationPkg)
+		pkg.Examples = []unstructured.Unstructured{example(t, "platform.example.org/v1alpha1", "ClusterClaim")}
+		return pkg
+	}
+
+	cases := map[string]struct {
+		reason string
+		pkg    func(t *testing.T) *Package
+		opts   []Option
+		want   []Finding
+	}{
+		"Clean": {
+			reason: "A package without issues should have no findings.",
+			pkg:    clean,
+			want:   []Finding{},
+		},
+		"MissingDescription": {
+			reason: "A package and XRD spec fields without a description should be reported.",
+			pkg: func(t *testing.T) *Package {
+				t.Helper()
+				s := strings.Replace(configurationPkg, "  annotations:\n    meta.crossplane.io/description: A reference platform.\n", "", 1)
+				s = strings.Replace(s, "                description: Region to deploy to.\n", "", 1)
+				pkg := parse(t, s)
+				pkg.Examples = []unstructured.Unstructured{example(t, "platform.example.org/v1alpha1", "Cluster")}
+				return pkg
+			},
+			want: []Finding{
+				{Rule: RuleMissingDescription, Severity: SeverityWarning, Object: "Configuration/platform-ref", Message: "package has no meta.crossplane.io/description annotation"},
+				{Rule: RuleMissingDescription, Severity: SeverityWarning, Object: "CompositeResourceDefinition/clusters.platform.example.org", Message: "field spec.region of version v1alpha1 has no description"},
+			},
+		},
+		"DeprecatedAPIVersion": {
+			reason: "Objects and examples with deprecated API versions should be reported.",
+			pkg: func(t *testing.T) *Package {
+				t.Helper()
+				pkg := parse(t, strings.Replace(configurationPkg, "apiVersion: meta.pkg.crossplane.io/v1\n", "apiVersion: meta.pkg.crossplane.io/v1alpha1\n", 1))
+				pkg.Examples = []unstructured.Unstructured{
+					example(t, "platform.example.org/v1alpha1", "ClusterClaim"),
+					example(t, "apiextensions.crossplane.io/v1beta1", "Composition"),
+				}
+				return pkg
+			},
+			want: []Finding{
+				{Rule: RuleDeprecatedAPIVersion, Severity: SeverityWarning, Object: "Configuration/platform-ref", Message: "meta.pkg.crossplane.io/v1alpha1 is deprecated, use meta.pkg.crossplane.io/v1"},
+				{Rule: RuleDeprecatedAPIVersion, Severity: SeverityWarning, Object: "Composition/example", Message: "apiextensions.crossplane.io/v1beta1 is deprecated, use apiextensions.crossplane.io/v1"},
+			},
+		},
+		"PatchFieldPath": {
+			reason: "Patches referencing fields the XRD does not define should be reported.",
+			pkg: func(t *testing.T) *Package {
+				t.Helper()
+				s := strings.Replace(configurationPkg, "fromFieldPath: spec.region\n", "fromFieldPath: spec.regoin\n", 1)
+				s = strings.Replace(s, "toFieldPath: status.endpoint\n", "toFieldPath: status.endpoints[0]\n", 1)
+				pkg := parse(t, s)
+				pkg.Examples = []unstructured.Unstructured{example(t, "platform.example.org/v1alpha1", "ClusterClaim")}
+				return pkg
+			},
+			want: []Finding{
+				{Rule: RulePatchFieldPath, Severity: SeverityError, Object: "Composition/clusters.aws.platform.example.org", Message: `patch 1 of resource "cluster" references spec.regoin, which is not a field of Cluster`},
+				{Rule: RulePatchFieldPath, Severity: SeverityError, Object: "Composition/clusters.aws.platform.example.org", Message: `patch 4 of resource "cluster" references status.endpoints[0], which is not a field of Cluster`},
+			},
+		},
+		"MissingExamples": {
+			reason: "XRDs without an example should be reported.",
+			pkg: func(t *testing.T) *Package {
+				t.Helper()
+				return parse(t, configurationPkg)
+			},
+			want: []Finding{
+				{Rule: RuleMissingExamples, Severity: SeverityWarning, Object: "CompositeResourceDefinition/clusters.platform.example.org", Message: "no example of Cluster"},
+			},
+		},
+		"SeverityOverride": {
+			reason: "The severity of a rule should be configurable.",
+			pkg: func(t *testing.T) *Package {
+				t.Helper()
+				return parse(t, configurationPkg)
+			},
+			opts: []Option{WithSeverity(RuleMissingExamples, SeverityError)},
+			want: []Finding{
+				{Rule: RuleMissingExamples, Severity: SeverityError, Object: "CompositeResourceDefinition/clusters.platform.example.org", Message: "no example of Cluster"},
+			},
+		},
+		"DisabledRule": {
+			reason: "Disabled rules should not run.",
+			pkg: func(t *testing.T) *Package {
+				t.Helper()
+				return parse(t, configurationPkg)
+			},
+			opts: []Option{WithDisabledRules(RuleMissingExamples)},
+			want: []Finding{},
+		},
+		"CustomRule": {
+			reason: "Custom rules should run after the default rules.",
+			pkg:    clean,
+			opts: []Option{WithRules(Rule{
+				Name:     "always",
+				Severity: SeverityInfo,
+				Check: func(_ *Package) []Issue {
+					return []Issue{{Message: "custom"}}
+				},
+			})},
+			want: []Finding{
+				{Rule: "always", Severity: SeverityInfo, Message: "custom"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := New(tc.opts...).Lint(tc.pkg(t))
+			if diff := cmp.Diff(tc.want, got.Findings); diff != "" {
+				t.Errorf("\n%s\nLint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReportCount(t *testing.T) {
+	r := &Report{Findings: []Finding{
+		{Severity: SeverityInfo},
+		{Severity: SeverityWarning},
+		{Severity: SeverityError},
+	}}
+	for s, want := range map[Severity]int{SeverityInfo: 3, SeverityWarning: 2, SeverityError: 1} {
+		if got := r.Count(s); got != want {
+			t.Errorf("Count(%s): want %d, got %d", s, want, got)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/xcrd"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Names of the default rules.
+const (
+	RuleMissingDescription   = "missing-description"
+	RuleDeprecatedAPIVersion = "deprecated-api-version"
+	RulePatchFieldPath       = "patch-field-path"
+	RuleMissingExamples      = "missing-examples"
+)
+
+const (
+	descriptionAnnotation = "meta.crossplane.io/description"
+)
+
+// deprecatedAPIVersions maps deprecated API versions to their replacement.
+var deprecatedAPIVersions = map[string]string{
+	"apiextensions.k8s.io/v1beta1":         "apiextensions.k8s.io/v1",
+	"admissionregistration.k8s.io/v1beta1": "admissionregistration.k8s.io/v1",
+	"apiextensions.crossplane.io/v1alpha1": "apiextensions.crossplane.io/v1",
+	"apiextensions.crossplane.io/v1beta1":  "apiextensions.crossplane.io/v1",
+	"meta.pkg.crossplane.io/v1alpha1":      "meta.pkg.crossplane.io/v1",
+}
+
+// DefaultRules returns the rules run by a Linter by default.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:        RuleMissingDescription,
+			Description: "The package and the spec fields of its XRDs should be described.",
+			Severity:    SeverityWarning,
+			Check:       MissingDescription,
+		},
+		{
+			Name:        RuleDeprecatedAPIVersion,
+			Description: "Objects and examples should not use deprecated API versions.",
+			Severity:    SeverityWarning,
+			Check:       DeprecatedAPIVersion,
+		},
+		{
+			Name:        RulePatchFieldPath,
+			Description: "Composition patches should only reference fields of the composite resource that exist.",
+			Severity:    SeverityError,
+			Check:       PatchFieldPath,
+		},
+		{
+			Name:        RuleMissingExamples,
+			Description: "Every XRD should have an example of its composite resource or claim.",
+			Severity:    SeverityWarning,
+			Check:       MissingExamples,
+		},
+	}
+}
+
+// MissingDescription reports a package without a description and XRD spec
+// fields without a description.
+func MissingDescription(pkg *Package) []Issue {
+	var issues []Issue
+	if m, ok := pkg.Meta.(metav1.Object); ok && m.GetAnnotations()[descriptionAnnotation] == "" {
+		issues = append(issues, Issue{
+			Object:  objectName(pkg.Meta),
+			Message: fmt.Sprintf("package has no %s annotation", descriptionAnnotation),
+		})
+	}
+	for _, o := range pkg.Objects {
+		xrd, ok := o.(*xpv1.CompositeResourceDefinition)
+		if !ok {
+			continue
+		}
+		for _, v := range xrd.Spec.Versions {
+			s, err := versionSchema(v)
+			if err != nil || s == nil {
+				continue
+			}
+			spec, ok := s.Properties["spec"]
+			if !ok {
+				continue
+			}
+			for _, f := range sortedKeys(spec.Properties) {
+				if spec.Properties[f].Description == "" {
+					issues = append(issues, Issue{
+						Object:  objectName(o),
+						Message: fmt.Sprintf("field spec.%s of version %s has no description", f, v.Name),
+					})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// DeprecatedAPIVersion reports objects and examples that use a deprecated API
+// version.
+func DeprecatedAPIVersion(pkg *Package) []Issue {
+	var issues []Issue
+	check := func(name, apiVersion string) {
+		if r, ok := deprecatedAPIVersions[apiVersion]; ok {
+			issues = append(issues, Issue{
+				Object:  name,
+				Message: fmt.Sprintf("%s is deprecated, use %s", apiVersion, r),
+			})
+		}
+	}
+	if pkg.Meta != nil {
+		check(objectName(pkg.Meta), pkg.Meta.GetObjectKind().GroupVersionKind().GroupVersion().String())
+	}
+	for _, o := range pkg.Objects {
+		check(objectName(o), o.GetObjectKind().GroupVersionKind().GroupVersion().String())
+	}
+	for i := range pkg.Examples {
+		check(objectName(&pkg.Examples[i]), pkg.Examples[i].GetAPIVersion())
+	}
+	return issues
+}
+
+// PatchFieldPath reports Composition patches that read from or write to a
+// field of the composite resource that its XRD does not define. Compositions
+// of composite resources that are not defined by the package are skipped.
+func PatchFieldPath(pkg *Package) []Issue { //nolint:gocyclo // Just a walk over patches.
+	xrds := map[schema.GroupKind]*xpv1.CompositeResourceDefinition{}
+	for _, o := range pkg.Objects {
+		if xrd, ok := o.(*xpv1.CompositeResourceDefinition); ok {
+			xrds[schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.Names.Kind}] = xrd
+		}
+	}
+
+	var issues []Issue
+	for _, o := range pkg.Objects {
+		comp, ok := o.(*xpv1.Composition)
+		if !ok {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(comp.Spec.CompositeTypeRef.APIVersion)
+		if err != nil {
+			continue
+		}
+		xrd, ok := xrds[schema.GroupKind{Group: gv.Group, Kind: comp.Spec.CompositeTypeRef.Kind}]
+		if !ok {
+			continue
+		}
+		s := compositeSchema(xrd, gv.Version)
+		if s == nil {
+			continue
+		}
+		check := func(where string, patches []xpv1.Patch) {
+			for i, p := range patches {
+				for _, path := range compositeFieldPaths(p) {
+					ok, err := hasFieldPath(s, path)
+					switch {
+					case err != nil:
+						issues = append(issues, Issue{
+							Object:  objectName(o),
+							Message: fmt.Sprintf("patch %d of %s has invalid field path %q: %v", i, where, path, err),
+						})
+					case !ok:
+						issues = append(issues, Issue{
+							Object:  objectName(o),
+							Message: fmt.Sprintf("patch %d of %s references %s, which is not a field of %s", i, where, path, comp.Spec.CompositeTypeRef.Kind),
+						})
+					}
+				}
+			}
+		}
+		for _, ps := range comp.Spec.PatchSets {
+			check(fmt.Sprintf("patch set %q", ps.Name), ps.Patches)
+		}
+		for i, r := range comp.Spec.Resources {
+			name := strconv.Itoa(i)
+			if r.Name != nil {
+				name = strconv.Quote(*r.Name)
+			}
+			check("resource "+name, r.Patches)
+		}
+	}
+	return issues
+}
+
+// MissingExamples reports XRDs without an example of their composite
+// resource or claim.
+func MissingExamples(pkg *Package) []Issue {
+	examples := map[schema.GroupKind]bool{}
+	for _, e := range pkg.Examples {
+		examples[e.GroupVersionKind().GroupKind()] = true
+	}
+	var issues []Issue
+	for _, o := range pkg.Objects {
+		xrd, ok := o.(*xpv1.CompositeResourceDefinition)
+		if !ok {
+			continue
+		}
+		if examples[schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.Names.Kind}] {
+			continue
+		}
+		if xrd.Spec.ClaimNames != nil && examples[schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.ClaimNames.Kind}] {
+			continue
+		}
+		issues = append(issues, Issue{
+			Object:  objectName(o),
+			Message: fmt.Sprintf("no example of %s", xrd.Spec.Names.Kind),
+		})
+	}
+	return issues
+}
+
+// compositeFieldPaths returns the field paths of the composite resource that
+// the supplied patch reads from or writes to.
+func compositeFieldPaths(p xpv1.Patch) []string {
+	switch p.GetType() { //nolint:exhaustive // Other patches don't touch the composite resource.
+	case xpv1.PatchTypeFromCompositeFieldPath:
+		return []string{p.GetFromFieldPath()}
+	case xpv1.PatchTypeToCompositeFieldPath:
+		// The toFieldPath defaults to the fromFieldPath.
+		if to := p.GetToFieldPath(); to != "" {
+			return []string{to}
+		}
+		return []string{p.GetFromFieldPath()}
+	case xpv1.PatchTypeCombineToComposite:
+		return []string{p.GetToFieldPath()}
+	case xpv1.PatchTypeCombineFromComposite:
+		if p.Combine == nil {
+			return nil
+		}
+		paths := make([]string, len(p.Combine.Variables))
+		for i, v := range p.Combine.Variables {
+			paths[i] = v.FromFieldPath
+		}
+		return paths
+	}
+	return nil
+}
+
+// compositeSchema returns the schema of the supplied version of the composite
+// resource defined by the supplied XRD, including the fields Crossplane adds.
+func compositeSchema(xrd *xpv1.CompositeResourceDefinition, version string) *extv1.JSONSchemaProps {
+	for _, v := range xrd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+		// Without a schema any field may exist.
+		vs, err := versionSchema(v)
+		if err != nil || vs == nil {
+			return nil
+		}
+		s := xcrd.BaseProps()
+		for field, props := range map[string]map[string]extv1.JSONSchemaProps{
+			"spec":   xcrd.CompositeResourceSpecProps(),
+			"status": xcrd.CompositeResourceStatusProps(),
+		} {
+			p := s.Properties[field]
+			p.Properties = props
+			up := vs.Properties[field]
+			for k, v := range up.Properties {
+				p.Properties[k] = v
+			}
+			p.XPreserveUnknownFields = up.XPreserveUnknownFields
+			s.Properties[field] = p
+		}
+		return s
+	}
+	return nil
+}
+
+// hasFieldPath returns true if the supplied field path may exist in objects
+// of the supplied schema. Paths into parts of the schema that allow unknown
+// fields are assumed to exist.
+func hasFieldPath(s *extv1.JSONSchemaProps, path string) (bool, error) {
+	segs, err := fieldpath.Parse(path)
+	if err != nil {
+		return false, err
+	}
+	for _, seg := range segs {
+		if s == nil || (s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields) {
+			return true, nil
+		}
+		if seg.Type == fieldpath.SegmentIndex {
+			if s.Items == nil {
+				return true, nil
+			}
+			s = s.Items.Schema
+			continue
+		}
+		if p, ok := s.Properties[seg.Field]; ok {
+			s = &p
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			s = s.AdditionalProperties.Schema
+			continue
+		}
+		// An object without properties, such as metadata, is not
+		// described by the schema.
+		if len(s.Properties) == 0 {
+			return true, nil
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+func versionSchema(v xpv1.CompositeResourceDefinitionVersion) (*extv1.JSONSchemaProps, error) {
+	if v.Schema == nil || len(v.Schema.OpenAPIV3Schema.Raw) == 0 {
+		return nil, nil
+	}
+	s := &extv1.JSONSchemaProps{}
+	return s, json.Unmarshal(v.Schema.OpenAPIV3Schema.Raw, s)
+}
+
+func objectName(o runtime.Object) string {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	if m, ok := o.(metav1.Object); ok && m.GetName() != "" {
+		return kind + "/" + m.GetName()
+	}
+	return kind
+}
+
+func sortedKeys(m map[string]extv1.JSONSchemaProps) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
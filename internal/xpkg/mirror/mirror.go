@@ -0,0 +1,213 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror copies packages between registries.
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/sign"
+)
+
+const (
+	errFetchSource       = "failed to fetch source package"
+	errWritePackage      = "failed to write package to destination"
+	errHeadDestination   = "failed to get destination package descriptor"
+	errCopySignatures    = "failed to copy package signatures"
+	errListReferrers     = "failed to list package referrers"
+	errCopyReferrer      = "failed to copy package referrer"
+	errFmtParseLocked    = "failed to parse locked package %s"
+	errFmtRebase         = "failed to rebase %s onto %s"
+	errFmtDigestMismatch = "digest of %s is %s, expected %s"
+	errFmtCopy           = "failed to copy %s"
+)
+
+// A Mirror copies packages between registries. Packages are copied without
+// modification so they keep their digest, along with their signatures and
+// any artifacts referring to them, such as SBOMs.
+type Mirror struct {
+	opts     []remote.Option
+	nameOpts []name.Option
+}
+
+// An Option modifies a Mirror.
+type Option func(*Mirror)
+
+// WithRemoteOptions configures the options used to access registries, e.g.
+// how to authenticate.
+func WithRemoteOptions(o ...remote.Option) Option {
+	return func(m *Mirror) {
+		m.opts = append(m.opts, o...)
+	}
+}
+
+// WithNameOptions configures the options used to parse references.
+func WithNameOptions(o ...name.Option) Option {
+	return func(m *Mirror) {
+		m.nameOpts = append(m.nameOpts, o...)
+	}
+}
+
+// New returns a new Mirror.
+func New(opts ...Option) *Mirror {
+	m := &Mirror{}
+	for _, o := range opts {
+		o(m)
+	}
+	return m
+}
+
+// A Copy of a package.
+type Copy struct {
+	Source      name.Reference
+	Destination name.Reference
+	Digest      v1.Hash
+}
+
+// Copy copies the package at the source reference to the destination
+// reference and returns its digest. It returns an error if the copied
+// package's digest differs from the source package's.
+func (m *Mirror) Copy(ctx context.Context, src, dst name.Reference) (v1.Hash, error) {
+	opts := append([]remote.Option{remote.WithContext(ctx)}, m.opts...)
+	d, err := copyManifest(src, dst, opts...)
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	sd := src.Context().Digest(d.String())
+	dd := dst.Context().Digest(d.String())
+	if err := copySignatures(sd, dd, opts...); err != nil {
+		return v1.Hash{}, err
+	}
+	if err := copyReferrers(sd, dd, opts...); err != nil {
+		return v1.Hash{}, err
+	}
+	return d, nil
+}
+
+// CopyLocked copies every package pinned in the supplied lock file to the
+// destination registry, keeping its repository path. Packages are copied by
+// their locked digest and tagged with their locked version. It returns an
+// error if a package no longer has its locked digest.
+func (m *Mirror) CopyLocked(ctx context.Context, l *lock.Lock, to string) ([]Copy, error) {
+	copies := make([]Copy, 0, len(l.Packages))
+	for _, p := range l.Packages {
+		src, err := name.NewDigest(p.Package+"@"+p.Digest, m.nameOpts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtParseLocked, p.Package)
+		}
+		repo, err := Rebase(src.Context(), to, m.nameOpts...)
+		if err != nil {
+			return nil, err
+		}
+		dst := repo.Tag(p.Version)
+		d, err := m.Copy(ctx, src, dst)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtCopy, p.Package)
+		}
+		copies = append(copies, Copy{Source: src, Destination: dst, Digest: d})
+	}
+	return copies, nil
+}
+
+// Rebase returns the supplied repository in the supplied registry, which may
+// include a path prefix, e.g. registry.example.org/mirror.
+func Rebase(repo name.Repository, to string, opts ...name.Option) (name.Repository, error) {
+	r, err := name.NewRepository(strings.TrimSuffix(to, "/")+"/"+repo.RepositoryStr(), opts...)
+	return r, errors.Wrapf(err, errFmtRebase, repo, to)
+}
+
+// copyManifest copies the image or index at src to dst and returns its digest.
+func copyManifest(src, dst name.Reference, opts ...remote.Option) (v1.Hash, error) {
+	desc, err := remote.Get(src, opts...)
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errFetchSource)
+	}
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return v1.Hash{}, errors.Wrap(err, errFetchSource)
+		}
+		err = remote.WriteIndex(dst, idx, opts...)
+		if err != nil {
+			return v1.Hash{}, errors.Wrap(err, errWritePackage)
+		}
+	} else {
+		img, err := desc.Image()
+		if err != nil {
+			return v1.Hash{}, errors.Wrap(err, errFetchSource)
+		}
+		if err := remote.Write(dst, img, opts...); err != nil {
+			return v1.Hash{}, errors.Wrap(err, errWritePackage)
+		}
+	}
+
+	got, err := remote.Head(dst, opts...)
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errHeadDestination)
+	}
+	if got.Digest != desc.Digest {
+		return v1.Hash{}, errors.Errorf(errFmtDigestMismatch, dst, got.Digest, desc.Digest)
+	}
+	return desc.Digest, nil
+}
+
+// copySignatures copies the signatures of the package at src, if any.
+func copySignatures(src, dst name.Digest, opts ...remote.Option) error {
+	st, err := sign.SignatureTag(src)
+	if err != nil {
+		return errors.Wrap(err, errCopySignatures)
+	}
+	dt, err := sign.SignatureTag(dst)
+	if err != nil {
+		return errors.Wrap(err, errCopySignatures)
+	}
+	if _, err := copyManifest(st, dt, opts...); err != nil && !isNotFound(err) {
+		return errors.Wrap(err, errCopySignatures)
+	}
+	return nil
+}
+
+// copyReferrers copies the artifacts referring to the package at src.
+func copyReferrers(src, dst name.Digest, opts ...remote.Option) error {
+	idx, err := remote.Referrers(src, opts...)
+	if err != nil {
+		return errors.Wrap(err, errListReferrers)
+	}
+	m, err := idx.IndexManifest()
+	if err != nil {
+		return errors.Wrap(err, errListReferrers)
+	}
+	for _, desc := range m.Manifests {
+		d := desc.Digest.String()
+		if _, err := copyManifest(src.Context().Digest(d), dst.Context().Digest(d), opts...); err != nil {
+			return errors.Wrap(err, errCopyReferrer)
+		}
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/upbound/up/internal/xpkg/dep/lock"
+	"github.com/upbound/up/internal/xpkg/sbom"
+	"github.com/upbound/up/internal/xpkg/sign"
+)
+
+func newRegistry(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.WithReferrersSupport(true)))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func push(t *testing.T, ref string) (name.Reference, v1.Hash) {
+	t.Helper()
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(r, img); err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, d
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newRegistry(t), newRegistry(t)
+
+	ref, d := push(t, src+"/acme/platform:v1.0.0")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sign.Sign(ctx, ref, sign.NewKeySigner(key)); err != nil {
+		t.Fatal(err)
+	}
+	doc := []byte(`{"spdxVersion":"SPDX-2.3"}`)
+	if _, err := sbom.Attach(ctx, ref, doc, sbom.SPDX); err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := name.ParseReference(dst + "/acme/platform:v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := New().Copy(ctx, ref, to)
+	if err != nil {
+		t.Fatalf("Copy(...): %v", err)
+	}
+	if diff := cmp.Diff(d, got); diff != "" {
+		t.Errorf("Copy(...): -want digest, +got digest:\n%s", diff)
+	}
+	if _, err := sign.Verify(ctx, to, []sign.Verifier{sign.NewKeyVerifier(key.Public())}); err != nil {
+		t.Errorf("Verify(...): copied package signature does not verify: %v", err)
+	}
+	fetched, err := sbom.Fetch(ctx, to, sbom.SPDX)
+	if err != nil {
+		t.Fatalf("Fetch(...): copied SBOM not found: %v", err)
+	}
+	if diff := cmp.Diff(string(doc), string(fetched)); diff != "" {
+		t.Errorf("Fetch(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestCopyLocked(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newRegistry(t), newRegistry(t)
+
+	_, aws := push(t, src+"/crossplane/provider-aws:v0.40.0")
+	_, k8s := push(t, src+"/crossplane/provider-kubernetes:v0.9.0")
+	l := &lock.Lock{Version: lock.Version, Packages: []lock.Package{
+		{Package: src + "/crossplane/provider-aws", Version: "v0.40.0", Digest: aws.String()},
+		{Package: src + "/crossplane/provider-kubernetes", Version: "v0.9.0", Digest: k8s.String()},
+	}}
+
+	copies, err := New().CopyLocked(ctx, l, dst+"/mirror")
+	if err != nil {
+		t.Fatalf("CopyLocked(...): %v", err)
+	}
+	if len(copies) != 2 {
+		t.Fatalf("CopyLocked(...): want 2 copies, got %d", len(copies))
+	}
+	for ref, want := range map[string]v1.Hash{
+		dst + "/mirror/crossplane/provider-aws:v0.40.0":       aws,
+		dst + "/mirror/crossplane/provider-kubernetes:v0.9.0": k8s,
+	} {
+		r, err := name.ParseReference(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := remote.Head(r)
+		if err != nil {
+			t.Fatalf("Head(%s): %v", ref, err)
+		}
+		if diff := cmp.Diff(want, desc.Digest); diff != "" {
+			t.Errorf("CopyLocked(...): %s: -want digest, +got digest:\n%s", ref, diff)
+		}
+	}
+
+	// A package whose locked digest no longer exists must not be copied.
+	l.Packages[0].Digest = k8s.String()
+	if _, err := New().CopyLocked(ctx, l, dst+"/other"); err == nil {
+		t.Errorf("CopyLocked(...): expected error for package without its locked digest")
+	}
+}
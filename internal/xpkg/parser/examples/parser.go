@@ -40,6 +40,11 @@ func NewExamples() *Examples {
 	return &Examples{}
 }
 
+// GetObjects returns the example objects.
+func (e *Examples) GetObjects() []unstructured.Unstructured {
+	return e.objects
+}
+
 // New creates a new Package.
 func New() *Parser {
 	return &Parser{}
@@ -0,0 +1,315 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkgcache is a content-addressed, filesystem-backed cache for
+// package images and their package streams.
+package pkgcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/afero"
+)
+
+const (
+	// DefaultRoot is the default root of the cache, relative to the user's
+	// home directory.
+	DefaultRoot = ".up/pkgcache"
+
+	blobsDir   = "blobs"
+	streamsDir = "streams"
+	tmpDir     = "tmp"
+
+	errCreateDir      = "failed to create cache directory"
+	errWriteBlob      = "failed to write blob to cache"
+	errReadBlob       = "failed to read blob from cache"
+	errWriteStreamRef = "failed to write package stream reference"
+	errReadStreamRef  = "failed to read package stream reference"
+	errPrune          = "failed to prune cache"
+	errFmtMismatch    = "digest mismatch for %s: got %s"
+)
+
+// IsNotFound returns true if the supplied error indicates that an entry does
+// not exist in the cache.
+func IsNotFound(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// A DigestMismatchError is returned when cached content does not match the
+// digest it is stored under.
+type DigestMismatchError struct {
+	Want v1.Hash
+	Got  v1.Hash
+}
+
+func (e *DigestMismatchError) Error() string {
+	return errors.Errorf(errFmtMismatch, e.Want, e.Got).Error()
+}
+
+// IsDigestMismatch returns true if the supplied error is a
+// DigestMismatchError.
+func IsDigestMismatch(err error) bool {
+	var e *DigestMismatchError
+	return errors.As(err, &e)
+}
+
+// Option modifies a Cache.
+type Option func(*Cache)
+
+// WithFS sets the filesystem the cache is stored on.
+func WithFS(fs afero.Fs) Option {
+	return func(c *Cache) {
+		c.fs = fs
+	}
+}
+
+// Cache stores blobs by digest. Package images are stored as their manifest,
+// config and layer blobs, and package streams as a blob referenced by the
+// digest of the image they were extracted from. Content is verified against
+// its digest when it is read and entries that fail verification are removed.
+type Cache struct {
+	fs   afero.Fs
+	root string
+	now  func() time.Time
+}
+
+// New returns a Cache rooted at the supplied directory.
+func New(root string, opts ...Option) *Cache {
+	c := &Cache{
+		fs:   afero.NewOsFs(),
+		root: filepath.Clean(root),
+		now:  time.Now,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Default returns a Cache rooted at DefaultRoot in the user's home directory.
+func Default(opts ...Option) (*Cache, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return New(filepath.Join(home, DefaultRoot), opts...), nil
+}
+
+// Has returns true if a blob with the supplied digest is cached.
+func (c *Cache) Has(h v1.Hash) bool {
+	_, err := c.fs.Stat(c.blobPath(h))
+	return err == nil
+}
+
+// Put stores the content of the supplied reader as a blob and returns its
+// digest.
+func (c *Cache) Put(r io.Reader) (v1.Hash, error) {
+	return c.put(r, nil)
+}
+
+// Get returns a reader for the blob with the supplied digest. The reader
+// returns a DigestMismatchError when the end of the blob is reached if its
+// content does not match the digest, in which case the blob is removed from
+// the cache.
+func (c *Cache) Get(h v1.Hash) (io.ReadCloser, error) {
+	p := c.blobPath(h)
+	f, err := c.fs.Open(p)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadBlob)
+	}
+	// Record access so that pruning evicts least recently used blobs first.
+	now := c.now()
+	_ = c.fs.Chtimes(p, now, now)
+	return &verifyReader{
+		rc:   f,
+		h:    sha256.New(),
+		want: h,
+		invalid: func() {
+			_ = c.fs.Remove(p)
+		},
+	}, nil
+}
+
+// PutStream stores the package stream extracted from the image with the
+// supplied digest.
+func (c *Cache) PutStream(img v1.Hash, r io.Reader) error {
+	h, err := c.Put(r)
+	if err != nil {
+		return err
+	}
+	p := c.streamPath(img)
+	if err := c.fs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return errors.Wrap(err, errCreateDir)
+	}
+	return errors.Wrap(afero.WriteFile(c.fs, p, []byte(h.String()), 0o644), errWriteStreamRef)
+}
+
+// GetStream returns a reader for the package stream extracted from the image
+// with the supplied digest.
+func (c *Cache) GetStream(img v1.Hash) (io.ReadCloser, error) {
+	b, err := afero.ReadFile(c.fs, c.streamPath(img))
+	if err != nil {
+		return nil, errors.Wrap(err, errReadStreamRef)
+	}
+	h, err := v1.NewHash(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, errors.Wrap(err, errReadStreamRef)
+	}
+	return c.Get(h)
+}
+
+// PrunePolicy determines which blobs are removed by Prune.
+type PrunePolicy struct {
+	// TTL is how long a blob is kept after it was last read or written.
+	// Blobs are not removed based on age if it is zero.
+	TTL time.Duration
+	// MaxSize is the total size in bytes the cache is pruned to, removing
+	// the least recently used blobs first. The cache is not pruned based on
+	// size if it is zero.
+	MaxSize int64
+}
+
+// PruneResult summarizes the blobs removed by Prune.
+type PruneResult struct {
+	Removed int
+	Freed   int64
+}
+
+type blobInfo struct {
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+// Prune removes blobs from the cache according to the supplied policy.
+// Images that lose any of their blobs are treated as not cached.
+func (c *Cache) Prune(p PrunePolicy) (PruneResult, error) { //nolint:gocyclo
+	res := PruneResult{}
+	blobs := []blobInfo{}
+	var total int64
+	err := afero.Walk(c.fs, filepath.Join(c.root, blobsDir), func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		blobs = append(blobs, blobInfo{path: path, size: info.Size(), mtime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return res, errors.Wrap(err, errPrune)
+	}
+
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].mtime.Before(blobs[j].mtime) })
+	now := c.now()
+	for _, b := range blobs {
+		expired := p.TTL > 0 && now.Sub(b.mtime) > p.TTL
+		oversize := p.MaxSize > 0 && total > p.MaxSize
+		if !expired && !oversize {
+			continue
+		}
+		if err := c.fs.Remove(b.path); err != nil {
+			return res, errors.Wrap(err, errPrune)
+		}
+		total -= b.size
+		res.Removed++
+		res.Freed += b.size
+	}
+	return res, nil
+}
+
+func (c *Cache) put(r io.Reader, want *v1.Hash) (v1.Hash, error) {
+	if want != nil && c.Has(*want) {
+		return *want, nil
+	}
+	tmp := filepath.Join(c.root, tmpDir)
+	if err := c.fs.MkdirAll(tmp, 0o755); err != nil {
+		return v1.Hash{}, errors.Wrap(err, errCreateDir)
+	}
+	f, err := afero.TempFile(c.fs, tmp, "blob-")
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errWriteBlob)
+	}
+	defer c.fs.Remove(f.Name()) //nolint:errcheck // Best effort; renamed on success.
+
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hasher), r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errWriteBlob)
+	}
+	h := v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(hasher.Sum(nil))}
+	if want != nil && *want != h {
+		return v1.Hash{}, &DigestMismatchError{Want: *want, Got: h}
+	}
+
+	p := c.blobPath(h)
+	if err := c.fs.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return v1.Hash{}, errors.Wrap(err, errCreateDir)
+	}
+	return h, errors.Wrap(c.fs.Rename(f.Name(), p), errWriteBlob)
+}
+
+func (c *Cache) blobPath(h v1.Hash) string {
+	return filepath.Join(c.root, blobsDir, h.Algorithm, h.Hex)
+}
+
+func (c *Cache) streamPath(img v1.Hash) string {
+	return filepath.Join(c.root, streamsDir, img.Algorithm, img.Hex)
+}
+
+// verifyReader verifies the content it reads against a digest once the
+// underlying reader is exhausted.
+type verifyReader struct {
+	rc      io.ReadCloser
+	h       hash.Hash
+	want    v1.Hash
+	invalid func()
+}
+
+func (r *verifyReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.h.Write(p[:n]) //nolint:errcheck // Writing to a hash never fails.
+	if err != io.EOF {
+		return n, err
+	}
+	got := v1.Hash{Algorithm: r.want.Algorithm, Hex: hex.EncodeToString(r.h.Sum(nil))}
+	if got != r.want {
+		r.invalid()
+		return n, &DigestMismatchError{Want: r.want, Got: got}
+	}
+	return n, io.EOF
+}
+
+func (r *verifyReader) Close() error {
+	return r.rc.Close()
+}
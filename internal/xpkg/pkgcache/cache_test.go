@@ -0,0 +1,192 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgcache
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/spf13/afero"
+)
+
+func TestGetVerifiesDigest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := New("/cache", WithFS(fs))
+
+	h, err := c.Put(strings.NewReader("package"))
+	if err != nil {
+		t.Fatalf("Put(...): %v", err)
+	}
+	rc, err := c.Get(h)
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll(Get(...)): %v", err)
+	}
+	if diff := cmp.Diff("package", string(b)); diff != "" {
+		t.Errorf("Get(...): -want, +got:\n%s", diff)
+	}
+
+	// Corrupt the cached blob.
+	if err := afero.WriteFile(fs, c.blobPath(h), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rc, err = c.Get(h)
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+	if _, err := io.ReadAll(rc); !IsDigestMismatch(err) {
+		t.Errorf("ReadAll(Get(...)): want digest mismatch, got %v", err)
+	}
+	if c.Has(h) {
+		t.Errorf("Has(...): corrupt blob should have been removed")
+	}
+	if _, err := c.Get(h); !IsNotFound(err) {
+		t.Errorf("Get(...): want not found, got %v", err)
+	}
+}
+
+func TestImage(t *testing.T) {
+	c := New("/cache", WithFS(afero.NewMemMapFs()))
+
+	img, err := random.Image(256, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := img.Digest()
+
+	if _, err := c.GetImage(want); !IsNotFound(err) {
+		t.Errorf("GetImage(...): want not found before PutImage, got %v", err)
+	}
+	d, err := c.PutImage(img)
+	if err != nil {
+		t.Fatalf("PutImage(...): %v", err)
+	}
+	if diff := cmp.Diff(want, d); diff != "" {
+		t.Errorf("PutImage(...): -want digest, +got digest:\n%s", diff)
+	}
+
+	got, err := c.GetImage(d)
+	if err != nil {
+		t.Fatalf("GetImage(...): %v", err)
+	}
+	gd, _ := got.Digest()
+	if diff := cmp.Diff(want, gd); diff != "" {
+		t.Errorf("GetImage(...): -want digest, +got digest:\n%s", diff)
+	}
+	layers, err := got.Layers()
+	if err != nil {
+		t.Fatalf("Layers(): %v", err)
+	}
+	for _, l := range layers {
+		rc, err := l.Uncompressed()
+		if err != nil {
+			t.Fatalf("Uncompressed(): %v", err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Errorf("Uncompressed(): %v", err)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	c := New("/cache", WithFS(afero.NewMemMapFs()))
+	img := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("a", 64)}
+
+	if _, err := c.GetStream(img); !IsNotFound(err) {
+		t.Errorf("GetStream(...): want not found before PutStream, got %v", err)
+	}
+	if err := c.PutStream(img, strings.NewReader("kind: Provider")); err != nil {
+		t.Fatalf("PutStream(...): %v", err)
+	}
+	rc, err := c.GetStream(img)
+	if err != nil {
+		t.Fatalf("GetStream(...): %v", err)
+	}
+	b, _ := io.ReadAll(rc)
+	if diff := cmp.Diff("kind: Provider", string(b)); diff != "" {
+		t.Errorf("GetStream(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason string
+		policy PrunePolicy
+		want   PruneResult
+		kept   []string
+	}{
+		"NoPolicy": {
+			reason: "Nothing should be removed without a policy.",
+			kept:   []string{"old", "mid", "new"},
+		},
+		"TTL": {
+			reason: "Blobs not used within the TTL should be removed.",
+			policy: PrunePolicy{TTL: 36 * time.Hour},
+			want:   PruneResult{Removed: 1, Freed: 3},
+			kept:   []string{"mid", "new"},
+		},
+		"MaxSize": {
+			reason: "Least recently used blobs should be removed until the cache fits.",
+			policy: PrunePolicy{MaxSize: 4},
+			want:   PruneResult{Removed: 2, Freed: 6},
+			kept:   []string{"new"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			c := New("/cache", WithFS(fs))
+			c.now = func() time.Time { return now }
+
+			hashes := map[string]v1.Hash{}
+			for i, content := range []string{"old", "mid", "new"} {
+				h, err := c.Put(strings.NewReader(content))
+				if err != nil {
+					t.Fatal(err)
+				}
+				at := now.Add(-time.Duration(2-i) * 24 * time.Hour)
+				_ = fs.Chtimes(c.blobPath(h), at, at)
+				hashes[content] = h
+			}
+
+			res, err := c.Prune(tc.policy)
+			if err != nil {
+				t.Fatalf("\n%s\nPrune(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, res); diff != "" {
+				t.Errorf("\n%s\nPrune(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			kept := []string{}
+			for _, content := range []string{"old", "mid", "new"} {
+				if c.Has(hashes[content]) {
+					kept = append(kept, content)
+				}
+			}
+			if diff := cmp.Diff(tc.kept, kept); diff != "" {
+				t.Errorf("\n%s\nPrune(...): -want kept, +got kept:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgcache
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	errGetDigest     = "failed to get image digest"
+	errGetManifest   = "failed to get image manifest"
+	errGetConfig     = "failed to get image config"
+	errGetLayers     = "failed to get image layers"
+	errParseManifest = "failed to parse cached image manifest"
+	errFmtWriteLayer = "failed to cache layer %s"
+)
+
+// PutImage stores the supplied image and returns its digest. Blobs that are
+// already cached are not written again.
+func (c *Cache) PutImage(img v1.Image) (v1.Hash, error) {
+	d, err := img.Digest()
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errGetDigest)
+	}
+	if c.Has(d) {
+		return d, nil
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errGetLayers)
+	}
+	for _, l := range layers {
+		if err := c.putLayer(l); err != nil {
+			return v1.Hash{}, err
+		}
+	}
+	cfgName, err := img.ConfigName()
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errGetConfig)
+	}
+	cfg, err := img.RawConfigFile()
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errGetConfig)
+	}
+	if _, err := c.put(bytes.NewReader(cfg), &cfgName); err != nil {
+		return v1.Hash{}, err
+	}
+	// The manifest is written last so that an image is only considered
+	// cached once all of its blobs are.
+	m, err := img.RawManifest()
+	if err != nil {
+		return v1.Hash{}, errors.Wrap(err, errGetManifest)
+	}
+	_, err = c.put(bytes.NewReader(m), &d)
+	return d, err
+}
+
+// GetImage returns the cached image with the supplied digest. Layer content
+// is verified when it is read.
+func (c *Cache) GetImage(h v1.Hash) (v1.Image, error) {
+	raw, err := c.readAll(h)
+	if err != nil {
+		return nil, err
+	}
+	m, err := v1.ParseManifest(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.Wrap(err, errParseManifest)
+	}
+	cfg, err := c.readAll(m.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range m.Layers {
+		if !c.Has(l.Digest) {
+			return nil, errors.Wrap(fs.ErrNotExist, errReadBlob)
+		}
+	}
+	return partial.CompressedToImage(&image{c: c, manifest: m, rawManifest: raw, rawConfig: cfg})
+}
+
+func (c *Cache) putLayer(l v1.Layer) error {
+	d, err := l.Digest()
+	if err != nil {
+		return errors.Wrap(err, errGetLayers)
+	}
+	if c.Has(d) {
+		return nil
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return errors.Wrapf(err, errFmtWriteLayer, d)
+	}
+	defer rc.Close() //nolint:errcheck // Only read from.
+	_, err = c.put(rc, &d)
+	return errors.Wrapf(err, errFmtWriteLayer, d)
+}
+
+func (c *Cache) readAll(h v1.Hash) ([]byte, error) {
+	rc, err := c.Get(h)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close() //nolint:errcheck // Only read from.
+	b, err := io.ReadAll(rc)
+	return b, errors.Wrap(err, errReadBlob)
+}
+
+// image is a partial.CompressedImageCore backed by the cache.
+type image struct {
+	c           *Cache
+	manifest    *v1.Manifest
+	rawManifest []byte
+	rawConfig   []byte
+}
+
+func (i *image) RawManifest() ([]byte, error) {
+	return i.rawManifest, nil
+}
+
+func (i *image) RawConfigFile() ([]byte, error) {
+	return i.rawConfig, nil
+}
+
+func (i *image) MediaType() (types.MediaType, error) {
+	if i.manifest.MediaType != "" {
+		return i.manifest.MediaType, nil
+	}
+	return types.OCIManifestSchema1, nil
+}
+
+func (i *image) LayerByDigest(h v1.Hash) (partial.CompressedLayer, error) {
+	for _, d := range i.manifest.Layers {
+		if d.Digest == h {
+			return &layer{c: i.c, desc: d}, nil
+		}
+	}
+	return nil, errors.Wrap(fs.ErrNotExist, errReadBlob)
+}
+
+// layer is a partial.CompressedLayer backed by the cache.
+type layer struct {
+	c    *Cache
+	desc v1.Descriptor
+}
+
+func (l *layer) Digest() (v1.Hash, error) {
+	return l.desc.Digest, nil
+}
+
+func (l *layer) Compressed() (io.ReadCloser, error) {
+	return l.c.Get(l.desc.Digest)
+}
+
+func (l *layer) Size() (int64, error) {
+	return l.desc.Size, nil
+}
+
+func (l *layer) MediaType() (types.MediaType, error) {
+	return l.desc.MediaType, nil
+}
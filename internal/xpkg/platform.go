@@ -0,0 +1,108 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	errFmtInvalidPlatform   = "invalid platform %q: expected <OS>_<arch> or <OS>/<arch>"
+	errFmtPlatformMismatch  = "image is built for %s, not %s"
+	errMissingPlatform      = "package does not specify the platform it is built for"
+	errFmtDuplicatePlatform = "multiple packages are built for platform %s"
+	errGetConfig            = "failed to get package config"
+)
+
+// ParsePlatform parses a platform in either the <OS>_<arch> syntax used by
+// the build commands or the <OS>/<arch>[/<variant>] syntax used by OCI
+// tooling.
+func ParsePlatform(s string) (v1.Platform, error) {
+	sep := "/"
+	if strings.Contains(s, "_") {
+		sep = "_"
+	}
+	parts := strings.Split(s, sep)
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return v1.Platform{}, errors.Errorf(errFmtInvalidPlatform, s)
+	}
+	p := v1.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// PlatformSuffix returns the <OS>_<arch> form of the supplied platform, which
+// is safe to use in file names.
+func PlatformSuffix(p v1.Platform) string {
+	s := p.OS + "_" + p.Architecture
+	if p.Variant != "" {
+		s += "_" + p.Variant
+	}
+	return s
+}
+
+// ImagePlatform returns the platform the supplied image is built for.
+func ImagePlatform(img v1.Image) (v1.Platform, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return v1.Platform{}, errors.Wrap(err, errGetConfig)
+	}
+	return v1.Platform{
+		OS:           cfg.OS,
+		Architecture: cfg.Architecture,
+		OSVersion:    cfg.OSVersion,
+		Variant:      cfg.Variant,
+	}, nil
+}
+
+// CheckPlatform returns an error if the supplied image is not built for the
+// supplied platform. Registries fall back to returning a single-platform image
+// when a reference does not resolve to an index, so the platform of fetched
+// controller images must be checked explicitly.
+func CheckPlatform(img v1.Image, p v1.Platform) error {
+	got, err := ImagePlatform(img)
+	if err != nil {
+		return err
+	}
+	if got.OS != p.OS || got.Architecture != p.Architecture || (p.Variant != "" && got.Variant != p.Variant) {
+		return errors.Errorf(errFmtPlatformMismatch, got.String(), p.String())
+	}
+	return nil
+}
+
+// ValidatePlatforms returns an error unless every supplied image is built for
+// a distinct platform, as required for images referenced by an index.
+func ValidatePlatforms(imgs []v1.Image) error {
+	seen := make(map[string]bool, len(imgs))
+	for _, img := range imgs {
+		p, err := ImagePlatform(img)
+		if err != nil {
+			return err
+		}
+		if p.OS == "" || p.Architecture == "" {
+			return errors.New(errMissingPlatform)
+		}
+		if seen[p.String()] {
+			return errors.Errorf(errFmtDuplicatePlatform, p.String())
+		}
+		seen[p.String()] = true
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func withPlatform(t *testing.T, img v1.Image, p v1.Platform) v1.Image {
+	t.Helper()
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OS, cfg.Architecture, cfg.Variant = p.OS, p.Architecture, p.Variant
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestParsePlatform(t *testing.T) {
+	type want struct {
+		p   v1.Platform
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		s      string
+		want   want
+	}{
+		"Underscore": {
+			reason: "The <OS>_<arch> syntax should be parsed.",
+			s:      "linux_arm64",
+			want:   want{p: v1.Platform{OS: "linux", Architecture: "arm64"}},
+		},
+		"SlashWithVariant": {
+			reason: "The <OS>/<arch>/<variant> syntax should be parsed.",
+			s:      "linux/arm/v7",
+			want:   want{p: v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+		},
+		"Invalid": {
+			reason: "A platform without an architecture should be rejected.",
+			s:      "linux",
+			want:   want{err: errors.Errorf(errFmtInvalidPlatform, "linux")},
+		},
+	}
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			p, err := ParsePlatform(tc.s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParsePlatform(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.p, p); diff != "" {
+				t.Errorf("\n%s\nParsePlatform(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCheckPlatform(t *testing.T) {
+	base, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amd64 := v1.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := v1.Platform{OS: "linux", Architecture: "arm64"}
+
+	cases := map[string]struct {
+		reason string
+		img    v1.Image
+		p      v1.Platform
+		want   error
+	}{
+		"Match": {
+			reason: "An image built for the platform should pass.",
+			img:    withPlatform(t, base, amd64),
+			p:      amd64,
+		},
+		"Mismatch": {
+			reason: "An image built for another platform should fail.",
+			img:    withPlatform(t, base, amd64),
+			p:      arm64,
+			want:   errors.Errorf(errFmtPlatformMismatch, "linux/amd64", "linux/arm64"),
+		},
+	}
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			err := CheckPlatform(tc.img, tc.p)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCheckPlatform(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
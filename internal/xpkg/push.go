@@ -0,0 +1,368 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultPushRetries is the default number of times a failed push is
+	// retried.
+	DefaultPushRetries = 3
+
+	errNoTags         = "at least one tag must be supplied"
+	errNoImages       = "at least one package must be supplied"
+	errParseTag       = "failed to parse tag"
+	errAnnotateLayers = "failed to annotate package layers"
+	errPushImage      = "failed to push package"
+	errPushIndex      = "failed to push package index"
+	errTagPackage     = "failed to tag package"
+	errPackageDigest  = "failed to get package digest"
+)
+
+// A PushOpt modifies a Pusher.
+type PushOpt func(*Pusher)
+
+// WithKeychain resolves registry credentials from the supplied keychain. The
+// default keychain is used if no other auth is configured.
+func WithKeychain(kc authn.Keychain) PushOpt {
+	return func(p *Pusher) {
+		p.keychain = kc
+		p.auth = nil
+	}
+}
+
+// WithBasicAuth authenticates to the registry with a username and password.
+func WithBasicAuth(username, password string) PushOpt {
+	return func(p *Pusher) {
+		p.auth = &authn.Basic{Username: username, Password: password}
+	}
+}
+
+// WithBearerToken authenticates to the registry with a bearer token.
+func WithBearerToken(token string) PushOpt {
+	return func(p *Pusher) {
+		p.auth = &authn.Bearer{Token: token}
+	}
+}
+
+// WithAnonymous pushes to the registry without credentials.
+func WithAnonymous() PushOpt {
+	return func(p *Pusher) {
+		p.auth = authn.Anonymous
+	}
+}
+
+// WithDefaultRegistry sets the registry used for tags that do not specify one.
+func WithDefaultRegistry(r string) PushOpt {
+	return func(p *Pusher) {
+		p.registry = r
+	}
+}
+
+// WithInsecure allows pushing to registries served over plain HTTP or with
+// certificates that cannot be verified.
+func WithInsecure() PushOpt {
+	return func(p *Pusher) {
+		p.insecure = true
+	}
+}
+
+// WithPushRetries sets the number of times a push that failed with a
+// transient error is retried.
+func WithPushRetries(n int) PushOpt {
+	return func(p *Pusher) {
+		p.retries = n
+	}
+}
+
+// WithPushTransport sets the transport used to talk to the registry.
+func WithPushTransport(rt http.RoundTripper) PushOpt {
+	return func(p *Pusher) {
+		p.transport = rt
+	}
+}
+
+// Pusher pushes packages to OCI registries.
+type Pusher struct {
+	keychain  authn.Keychain
+	auth      authn.Authenticator
+	registry  string
+	insecure  bool
+	retries   int
+	backoff   time.Duration
+	transport http.RoundTripper
+}
+
+// NewPusher returns a new Pusher.
+func NewPusher(opts ...PushOpt) *Pusher {
+	p := &Pusher{
+		keychain: authn.DefaultKeychain,
+		registry: name.DefaultRegistry,
+		retries:  DefaultPushRetries,
+		backoff:  time.Second,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Push pushes the supplied packages to every supplied tag and returns the
+// digest they were pushed with. A single package is pushed as an image;
+// multiple packages are pushed by digest and referenced from an image index,
+// and must each be built for a distinct platform.
+// Failed pushes are retried. Blobs that were already uploaded by an earlier
+// attempt are not uploaded again, so retries resume where they failed.
+func (p *Pusher) Push(ctx context.Context, imgs []v1.Image, tags ...string) (v1.Hash, error) { //nolint:gocyclo
+	if len(tags) == 0 {
+		return v1.Hash{}, errors.New(errNoTags)
+	}
+	if len(imgs) == 0 {
+		return v1.Hash{}, errors.New(errNoImages)
+	}
+	refs := make([]name.Tag, len(tags))
+	for i, t := range tags {
+		ref, err := name.NewTag(t, p.nameOpts()...)
+		if err != nil {
+			return v1.Hash{}, errors.Wrap(err, errParseTag)
+		}
+		refs[i] = ref
+	}
+	opts := p.remoteOpts(ctx)
+
+	aimgs := make([]v1.Image, len(imgs))
+	for i, img := range imgs {
+		aimg, err := Annotate(img)
+		if err != nil {
+			return v1.Hash{}, errors.Wrap(err, errAnnotateLayers)
+		}
+		aimgs[i] = aimg
+	}
+
+	var pushed remote.Taggable
+	if len(aimgs) == 1 {
+		if err := p.retry(ctx, func() error {
+			return remote.Write(refs[0], aimgs[0], opts...)
+		}); err != nil {
+			return v1.Hash{}, errors.Wrap(err, errPushImage)
+		}
+		pushed = aimgs[0]
+	} else {
+		if err := ValidatePlatforms(aimgs); err != nil {
+			return v1.Hash{}, err
+		}
+		idx, err := p.pushIndex(ctx, refs[0], aimgs, opts)
+		if err != nil {
+			return v1.Hash{}, err
+		}
+		pushed = idx
+	}
+
+	for _, ref := range refs[1:] {
+		ref := ref
+		if err := p.retry(ctx, func() error {
+			return remote.Tag(ref, pushed, opts...)
+		}); err != nil {
+			return v1.Hash{}, errors.Wrap(err, errTagPackage)
+		}
+	}
+
+	d, err := pushed.(interface{ Digest() (v1.Hash, error) }).Digest()
+	return d, errors.Wrap(err, errPackageDigest)
+}
+
+// pushIndex pushes the supplied images by digest and an index referencing
+// them to the supplied tag.
+func (p *Pusher) pushIndex(ctx context.Context, tag name.Tag, imgs []v1.Image, opts []remote.Option) (v1.ImageIndex, error) {
+	adds := make([]mutate.IndexAddendum, len(imgs))
+
+	// NOTE(hasheddan): the errgroup context is passed to each image write,
+	// meaning that if one fails it will cancel others that are in progress.
+	g, gctx := errgroup.WithContext(ctx)
+	for i, img := range imgs {
+		// pin range variables for use in go func
+		i, img := i, img
+		g.Go(func() error {
+			d, err := img.Digest()
+			if err != nil {
+				return errors.Wrap(err, errPackageDigest)
+			}
+			mt, err := img.MediaType()
+			if err != nil {
+				return err
+			}
+			pl, err := ImagePlatform(img)
+			if err != nil {
+				return err
+			}
+			adds[i] = mutate.IndexAddendum{
+				Add: img,
+				Descriptor: v1.Descriptor{
+					MediaType: mt,
+					Platform:  &pl,
+				},
+			}
+			ref := tag.Digest(d.String())
+			return errors.Wrap(p.retry(gctx, func() error {
+				return remote.Write(ref, img, append(opts, remote.WithContext(gctx))...)
+			}), errPushImage)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	idx := mutate.AppendManifests(empty.Index, adds...)
+	if err := p.retry(ctx, func() error {
+		return remote.WriteIndex(tag, idx, opts...)
+	}); err != nil {
+		return nil, errors.Wrap(err, errPushIndex)
+	}
+	return idx, nil
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// transient, the context is done, or it has been retried the configured number
+// of times, waiting longer after each attempt.
+func (p *Pusher) retry(ctx context.Context, fn func() error) error {
+	wait := p.backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !transient(err) || attempt >= p.retries || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// transient returns true if err is a registry response with a 5xx or 429
+// status, or a temporary network error.
+func transient(err error) bool {
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		return terr.StatusCode == http.StatusTooManyRequests || terr.StatusCode >= http.StatusInternalServerError
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		t, ok := nerr.(interface{ Temporary() bool })
+		return ok && t.Temporary()
+	}
+	return false
+}
+
+func (p *Pusher) nameOpts() []name.Option {
+	opts := []name.Option{name.WithDefaultRegistry(p.registry)}
+	if p.insecure {
+		opts = append(opts, name.Insecure)
+	}
+	return opts
+}
+
+func (p *Pusher) remoteOpts(ctx context.Context) []remote.Option {
+	// Retries are handled by retry, so that they resume failed pushes and do
+	// not multiply with retries of individual requests.
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithRetryStatusCodes(),
+		remote.WithRetryBackoff(remote.Backoff{Steps: 1}),
+	}
+	if p.auth != nil {
+		opts = append(opts, remote.WithAuth(p.auth))
+	} else {
+		opts = append(opts, remote.WithAuthFromKeychain(p.keychain))
+	}
+	rt := p.transport
+	if rt == nil && p.insecure {
+		t := remote.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+		rt = t
+	}
+	if rt != nil {
+		opts = append(opts, remote.WithTransport(rt))
+	}
+	return opts
+}
+
+// Annotate reads in the layers of the given v1.Image and annotates the xpkg
+// layers with their corresponding annotations, returning a new v1.Image
+// containing the annotation details.
+func Annotate(i v1.Image) (v1.Image, error) {
+	cfgFile, err := i.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := i.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	addendums := make([]mutate.Addendum, 0)
+
+	for _, l := range layers {
+		d, err := l.Digest()
+		if err != nil {
+			return nil, err
+		}
+		if annotation, ok := cfgFile.Config.Labels[Label(d.String())]; ok {
+			addendums = append(addendums, mutate.Addendum{
+				Layer: l,
+				Annotations: map[string]string{
+					AnnotationKey: annotation,
+				},
+			})
+			continue
+		}
+		addendums = append(addendums, mutate.Addendum{
+			Layer: l,
+		})
+	}
+
+	// we didn't find any annotations, return original image
+	if len(addendums) == 0 {
+		return i, nil
+	}
+
+	img := empty.Image
+	for _, a := range addendums {
+		img, err = mutate.Append(img, a)
+		if err != nil {
+			return nil, errors.Wrap(err, errBuildImage)
+		}
+	}
+
+	return mutate.ConfigFile(img, cfgFile)
+}
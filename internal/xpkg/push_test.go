@@ -0,0 +1,252 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// flakyHandler fails the first n blob upload requests with a transient error
+// before passing requests through to the wrapped registry.
+func flakyHandler(h http.Handler, n int32) http.Handler {
+	var failed int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/blobs/uploads/") && atomic.AddInt32(&failed, 1) <= n {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func TestPush(t *testing.T) {
+
+	type args struct {
+		images    int
+		platforms []string
+		tags      []string
+		flaky     int32
+	}
+	type want struct {
+		index bool
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoTags": {
+			reason: "Pushing without a tag should fail.",
+			args: args{
+				images: 1,
+			},
+			want: want{
+				err: errors.New(errNoTags),
+			},
+		},
+		"NoImages": {
+			reason: "Pushing without a package should fail.",
+			args: args{
+				tags: []string{"org/pkg:v1"},
+			},
+			want: want{
+				err: errors.New(errNoImages),
+			},
+		},
+		"SingleImageMultipleTags": {
+			reason: "A single package should be pushed as an image to every tag.",
+			args: args{
+				images: 1,
+				tags:   []string{"org/pkg:v1", "org/pkg:latest", "org/other:v1"},
+			},
+		},
+		"MultipleImages": {
+			reason: "Multiple packages should be pushed as an index to every tag.",
+			args: args{
+				images:    2,
+				platforms: []string{"linux/amd64", "linux/arm64"},
+				tags:      []string{"org/pkg:v1", "org/pkg:latest"},
+			},
+			want: want{
+				index: true,
+			},
+		},
+		"DuplicatePlatforms": {
+			reason: "Multiple packages built for the same platform should not be pushed as an index.",
+			args: args{
+				images:    2,
+				platforms: []string{"linux/amd64", "linux/amd64"},
+				tags:      []string{"org/pkg:v1"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtDuplicatePlatform, "linux/amd64"),
+			},
+		},
+		"MissingPlatform": {
+			reason: "Multiple packages that do not specify their platform should not be pushed as an index.",
+			args: args{
+				images: 2,
+				tags:   []string{"org/pkg:v1"},
+			},
+			want: want{
+				err: errors.New(errMissingPlatform),
+			},
+		},
+		"TransientFailure": {
+			reason: "Transient registry failures should be retried.",
+			args: args{
+				images: 1,
+				tags:   []string{"org/pkg:v1"},
+				flaky:  5,
+			},
+		},
+	}
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			srv := httptest.NewServer(flakyHandler(registry.New(registry.Logger(log.New(io.Discard, "", 0))), tc.args.flaky))
+			defer srv.Close()
+			u, _ := url.Parse(srv.URL)
+
+			imgs := make([]v1.Image, tc.args.images)
+			for i := range imgs {
+				img, err := random.Image(64, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(tc.args.platforms) > i {
+					pl, err := ParsePlatform(tc.args.platforms[i])
+					if err != nil {
+						t.Fatal(err)
+					}
+					img = withPlatform(t, img, pl)
+				}
+				imgs[i] = img
+			}
+
+			p := NewPusher(WithDefaultRegistry(u.Host), WithAnonymous(), WithInsecure())
+			p.backoff = time.Millisecond
+			d, err := p.Push(context.Background(), imgs, tc.args.tags...)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPush(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			for _, tag := range tc.args.tags {
+				ref, err := name.NewTag(tag, name.WithDefaultRegistry(u.Host), name.Insecure)
+				if err != nil {
+					t.Fatal(err)
+				}
+				desc, err := remote.Head(ref)
+				if err != nil {
+					t.Fatalf("\n%s\nHead(%s): %v", tc.reason, tag, err)
+				}
+				if diff := cmp.Diff(d, desc.Digest); diff != "" {
+					t.Errorf("\n%s\nPush(...): -want digest, +got digest:\n%s", tc.reason, diff)
+				}
+				if diff := cmp.Diff(tc.want.index, desc.MediaType.IsIndex()); diff != "" {
+					t.Errorf("\n%s\nPush(...): -want index, +got index:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+// temporaryError is a net.Error that reports whether it is temporary.
+type temporaryError struct{ temporary bool }
+
+func (e temporaryError) Error() string   { return "network error" }
+func (e temporaryError) Timeout() bool   { return false }
+func (e temporaryError) Temporary() bool { return e.temporary }
+
+var _ net.Error = temporaryError{}
+
+func TestPusherRetry(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   int
+	}{
+		"ServerError": {
+			reason: "Registry responses with a 5xx status should be retried.",
+			err:    &transport.Error{StatusCode: http.StatusBadGateway},
+			want:   DefaultPushRetries + 1,
+		},
+		"TooManyRequests": {
+			reason: "Rate limited registry responses should be retried.",
+			err:    errors.Wrap(&transport.Error{StatusCode: http.StatusTooManyRequests}, "push"),
+			want:   DefaultPushRetries + 1,
+		},
+		"ClientError": {
+			reason: "Registry responses with a 4xx status should not be retried.",
+			err:    &transport.Error{StatusCode: http.StatusUnauthorized},
+			want:   1,
+		},
+		"TemporaryNetworkError": {
+			reason: "Temporary network errors should be retried.",
+			err:    &url.Error{Op: "Put", URL: "https://example.org", Err: temporaryError{temporary: true}},
+			want:   DefaultPushRetries + 1,
+		},
+		"NetworkError": {
+			reason: "Network errors that are not temporary should not be retried.",
+			err:    temporaryError{},
+			want:   1,
+		},
+		"OtherError": {
+			reason: "Other errors should not be retried.",
+			err:    errors.New("boom"),
+			want:   1,
+		},
+	}
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			p := NewPusher()
+			p.backoff = time.Millisecond
+			calls := 0
+			err := p.retry(context.Background(), func() error {
+				calls++
+				return tc.err
+			})
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nretry(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, calls); diff != "" {
+				t.Errorf("\n%s\nretry(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
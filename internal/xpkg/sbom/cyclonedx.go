@@ -0,0 +1,121 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	cdxFormat      = "CycloneDX"
+	cdxSpecVersion = "1.5"
+	cdxRootRef     = "package"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components,omitempty"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     []cdxTool    `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Name string `json:"name"`
+}
+
+type cdxComponent struct {
+	BOMRef      string        `json:"bom-ref"`
+	Type        string        `json:"type"`
+	Name        string        `json:"name"`
+	Version     string        `json:"version,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Hashes      []cdxHash     `json:"hashes,omitempty"`
+	Properties  []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func cycloneDX(p *Package) cdxDocument {
+	// The serial number is derived from the package digest so that the same
+	// package always produces the same SBOM.
+	serial := uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmtNamespace(p)))
+	doc := cdxDocument{
+		BOMFormat:    cdxFormat,
+		SpecVersion:  cdxSpecVersion,
+		SerialNumber: "urn:uuid:" + serial.String(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: p.Created.Format(time.RFC3339),
+			Tools:     []cdxTool{{Name: toolName}},
+			Component: cdxComponent{
+				BOMRef:      cdxRootRef,
+				Type:        "container",
+				Name:        p.Name,
+				Description: p.Kind,
+				Hashes:      []cdxHash{{Algorithm: "SHA-256", Content: p.Digest.Hex}},
+			},
+		},
+	}
+	root := cdxDependency{Ref: cdxRootRef}
+	for _, o := range p.Objects {
+		doc.Components = append(doc.Components, cdxComponent{
+			BOMRef: o.Path(),
+			Type:   "file",
+			Name:   o.Path(),
+			Hashes: []cdxHash{
+				{Algorithm: "SHA-1", Content: o.SHA1},
+				{Algorithm: "SHA-256", Content: o.SHA256},
+			},
+		})
+	}
+	for _, d := range p.Dependencies {
+		ref := "dependency:" + d.Package
+		doc.Components = append(doc.Components, cdxComponent{
+			BOMRef:      ref,
+			Type:        "container",
+			Name:        d.Package,
+			Version:     d.Constraints,
+			Description: d.Kind,
+			Properties:  []cdxProperty{{Name: "upbound:package:kind", Value: d.Kind}},
+		})
+		root.DependsOn = append(root.DependsOn, ref)
+	}
+	doc.Dependencies = []cdxDependency{root}
+	return doc
+}
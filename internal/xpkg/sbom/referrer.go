@@ -0,0 +1,125 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"context"
+	"io"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	errHeadSubject     = "failed to get package descriptor"
+	errBuildArtifact   = "failed to build SBOM artifact"
+	errPushArtifact    = "failed to push SBOM artifact"
+	errListReferrers   = "failed to list package referrers"
+	errFetchArtifact   = "failed to fetch SBOM artifact"
+	errReadArtifact    = "failed to read SBOM artifact"
+	errFmtNoSBOM       = "no %s SBOM attached to %s"
+	errFmtArtifactDesc = "unexpected SBOM artifact layout for %s"
+)
+
+// Attach pushes the supplied SBOM as an OCI referrer of the package at the
+// supplied reference and returns the digest of the SBOM artifact.
+func Attach(ctx context.Context, subject name.Reference, sbom []byte, f Format, opts ...remote.Option) (name.Digest, error) {
+	mt, err := f.MediaType()
+	if err != nil {
+		return name.Digest{}, err
+	}
+	opts = append(opts, remote.WithContext(ctx))
+	desc, err := remote.Head(subject, opts...)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errHeadSubject)
+	}
+
+	art, err := mutate.Append(empty.Image, mutate.Addendum{Layer: static.NewLayer(sbom, types.MediaType(mt))})
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errBuildArtifact)
+	}
+	art = mutate.MediaType(art, types.OCIManifestSchema1)
+	art = mutate.ConfigMediaType(art, types.MediaType(mt))
+	art = mutate.Subject(art, *desc).(v1.Image)
+	d, err := art.Digest()
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errBuildArtifact)
+	}
+	ref := subject.Context().Digest(d.String())
+	if err := remote.Write(ref, art, opts...); err != nil {
+		return name.Digest{}, errors.Wrap(err, errPushArtifact)
+	}
+	return ref, nil
+}
+
+// Fetch returns the SBOM in the supplied format that is attached to the
+// package at the supplied reference.
+func Fetch(ctx context.Context, subject name.Reference, f Format, opts ...remote.Option) ([]byte, error) {
+	mt, err := f.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, remote.WithContext(ctx))
+	d, ok := subject.(name.Digest)
+	if !ok {
+		desc, err := remote.Head(subject, opts...)
+		if err != nil {
+			return nil, errors.Wrap(err, errHeadSubject)
+		}
+		d = subject.Context().Digest(desc.Digest.String())
+	}
+	idx, err := remote.Referrers(d, append(opts, remote.WithFilter("artifactType", mt))...)
+	if err != nil {
+		return nil, errors.Wrap(err, errListReferrers)
+	}
+	m, err := idx.IndexManifest()
+	if err != nil {
+		return nil, errors.Wrap(err, errListReferrers)
+	}
+	for _, desc := range m.Manifests {
+		if desc.ArtifactType != mt {
+			continue
+		}
+		return readArtifact(subject.Context().Digest(desc.Digest.String()), opts...)
+	}
+	return nil, errors.Errorf(errFmtNoSBOM, f, d)
+}
+
+func readArtifact(ref name.Digest, opts ...remote.Option) ([]byte, error) {
+	img, err := remote.Image(ref, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, errFetchArtifact)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, errors.Wrap(err, errFetchArtifact)
+	}
+	if len(layers) != 1 {
+		return nil, errors.Errorf(errFmtArtifactDesc, ref)
+	}
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		return nil, errors.Wrap(err, errReadArtifact)
+	}
+	defer rc.Close() //nolint:errcheck // Only reading.
+	b, err := io.ReadAll(rc)
+	return b, errors.Wrap(err, errReadArtifact)
+}
@@ -0,0 +1,232 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom generates software bills of materials for packages and
+// attaches them to package images as OCI referrers.
+package sbom
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // SPDX requires SHA1 file checksums.
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/spf13/afero/tarfs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/parser/yaml"
+	"github.com/upbound/up/internal/xpkg/scheme"
+)
+
+// A Format of SBOM.
+type Format string
+
+// Supported SBOM formats.
+const (
+	// SPDX is SPDX 2.3 JSON.
+	SPDX Format = "spdx"
+	// CycloneDX is CycloneDX 1.5 JSON.
+	CycloneDX Format = "cyclonedx"
+)
+
+const (
+	toolName = "up"
+
+	errFmtUnknownFormat  = "unknown SBOM format %q"
+	errOpenPackageStream = "failed to open package stream file"
+	errParsePackage      = "failed to parse package"
+	errNotExactlyOneMeta = "not exactly one package meta type"
+	errConvertMeta       = "failed to convert package meta"
+	errPackageDigest     = "failed to get package digest"
+	errPackageConfig     = "failed to get package config"
+	errMarshalObject     = "failed to marshal package object"
+)
+
+// MediaType returns the media type of SBOMs in the format.
+func (f Format) MediaType() (string, error) {
+	switch f {
+	case SPDX:
+		return "application/spdx+json", nil
+	case CycloneDX:
+		return "application/vnd.cyclonedx+json", nil
+	}
+	return "", errors.Errorf(errFmtUnknownFormat, f)
+}
+
+// Extension returns the file extension of SBOMs in the format.
+func (f Format) Extension() string {
+	if f == CycloneDX {
+		return ".cdx.json"
+	}
+	return ".spdx.json"
+}
+
+// SidecarPath returns the path of the SBOM in the supplied format that is
+// written next to the package at the supplied path.
+func SidecarPath(pkg string, f Format) string {
+	return pkg + f.Extension()
+}
+
+// Package describes a package for an SBOM.
+type Package struct {
+	Name         string
+	Kind         string
+	Digest       v1.Hash
+	Created      time.Time
+	Objects      []Object
+	Dependencies []Dependency
+}
+
+// An Object shipped by a package.
+type Object struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	SHA1       string
+	SHA256     string
+}
+
+// Path returns a file-like path identifying the object within the package.
+func (o Object) Path() string {
+	return o.APIVersion + "/" + o.Kind + "/" + o.Name
+}
+
+// A Dependency of a package.
+type Dependency struct {
+	Package     string
+	Kind        string
+	Constraints string
+}
+
+// Generate returns an SBOM in the supplied format describing the supplied
+// package image.
+func Generate(_ context.Context, img v1.Image, f Format) ([]byte, error) {
+	if _, err := f.MediaType(); err != nil {
+		return nil, err
+	}
+	pkg, err := Describe(img)
+	if err != nil {
+		return nil, err
+	}
+	if f == CycloneDX {
+		return marshal(cycloneDX(pkg))
+	}
+	return marshal(spdx(pkg))
+}
+
+// marshal indents the supplied document without escaping version
+// constraints such as >=v1.0.0.
+func marshal(doc any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(doc)
+	return buf.Bytes(), err
+}
+
+// Describe returns the contents of the supplied package image.
+func Describe(img v1.Image) (*Package, error) { //nolint:gocyclo
+	d, err := img.Digest()
+	if err != nil {
+		return nil, errors.Wrap(err, errPackageDigest)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, errors.Wrap(err, errPackageConfig)
+	}
+
+	fs := tarfs.New(tar.NewReader(mutate.Extract(img)))
+	stream, err := fs.Open(xpkg.StreamFile)
+	if err != nil {
+		return nil, errors.Wrap(err, errOpenPackageStream)
+	}
+	pp, err := yaml.New()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := pp.Parse(context.Background(), stream)
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePackage)
+	}
+	if len(parsed.GetMeta()) != 1 {
+		return nil, errors.New(errNotExactlyOneMeta)
+	}
+	meta := parsed.GetMeta()[0]
+	mo, ok := meta.(metav1.Object)
+	if !ok {
+		return nil, errors.New(errConvertMeta)
+	}
+
+	pkg := &Package{
+		Name:    mo.GetName(),
+		Kind:    meta.GetObjectKind().GroupVersionKind().Kind,
+		Digest:  d,
+		Created: cfg.Created.UTC(),
+	}
+	if m, ok := scheme.TryConvertToPkg(meta, &pkgmetav1.Provider{}, &pkgmetav1.Configuration{}); ok {
+		for _, dep := range m.GetDependencies() {
+			pd := Dependency{Constraints: dep.Version}
+			switch {
+			case dep.Provider != nil:
+				pd.Package, pd.Kind = *dep.Provider, pkgmetav1.ProviderKind
+			case dep.Configuration != nil:
+				pd.Package, pd.Kind = *dep.Configuration, pkgmetav1.ConfigurationKind
+			default:
+				continue
+			}
+			pkg.Dependencies = append(pkg.Dependencies, pd)
+		}
+	}
+	for _, o := range parsed.GetObjects() {
+		obj, err := object(o)
+		if err != nil {
+			return nil, err
+		}
+		pkg.Objects = append(pkg.Objects, obj)
+	}
+	sort.Slice(pkg.Objects, func(i, j int) bool { return pkg.Objects[i].Path() < pkg.Objects[j].Path() })
+	return pkg, nil
+}
+
+func object(o runtime.Object) (Object, error) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return Object{}, errors.Wrap(err, errMarshalObject)
+	}
+	s1 := sha1.Sum(b) //nolint:gosec // SPDX requires SHA1 file checksums.
+	s256 := sha256.Sum256(b)
+	gvk := o.GetObjectKind().GroupVersionKind()
+	obj := Object{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		SHA1:       hex.EncodeToString(s1[:]),
+		SHA256:     hex.EncodeToString(s256[:]),
+	}
+	if mo, ok := o.(metav1.Object); ok {
+		obj.Name = mo.GetName()
+	}
+	return obj, nil
+}
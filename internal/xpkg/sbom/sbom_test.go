@@ -0,0 +1,234 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	"github.com/upbound/up/internal/xpkg"
+)
+
+const configurationPkg = `
+apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: platform-ref
+spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws
+    version: ">=v0.40.0"
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: clusters.aws.platform.example.org
+spec:
+  compositeTypeRef:
+    apiVersion: platform.example.org/v1alpha1
+    kind: Cluster
+  resources: []
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: clusters.platform.example.org
+spec:
+  group: platform.example.org
+  names:
+    kind: Cluster
+    plural: clusters
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+`
+
+func packageImage(t *testing.T, stream string) v1.Image {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name: xpkg.StreamFile,
+		Mode: int64(xpkg.StreamFileMode),
+		Size: int64(len(stream)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(tw, stream); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(empty.Image, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestDescribe(t *testing.T) {
+	img := packageImage(t, configurationPkg)
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Describe(img)
+	if err != nil {
+		t.Fatalf("Describe(...): %v", err)
+	}
+	// Checksums are covered by the generation tests.
+	for i := range got.Objects {
+		got.Objects[i].SHA1, got.Objects[i].SHA256 = "", ""
+	}
+	want := &Package{
+		Name:   "platform-ref",
+		Kind:   "Configuration",
+		Digest: d,
+		Objects: []Object{
+			{APIVersion: "apiextensions.crossplane.io/v1", Kind: "CompositeResourceDefinition", Name: "clusters.platform.example.org"},
+			{APIVersion: "apiextensions.crossplane.io/v1", Kind: "Composition", Name: "clusters.aws.platform.example.org"},
+		},
+		Dependencies: []Dependency{
+			{Package: "xpkg.upbound.io/upbound/provider-aws", Kind: "Provider", Constraints: ">=v0.40.0"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nDescribe(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	img := packageImage(t, configurationPkg)
+
+	type want struct {
+		keys []string
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		format Format
+		want   want
+	}{
+		"SPDX": {
+			reason: "An SPDX document should describe the package, its objects and dependencies.",
+			format: SPDX,
+			want: want{
+				keys: []string{`"spdxVersion": "SPDX-2.3"`, `"fileName": "apiextensions.crossplane.io/v1/Composition/clusters.aws.platform.example.org"`, `"relationshipType": "DEPENDS_ON"`, `"name": "xpkg.upbound.io/upbound/provider-aws"`},
+			},
+		},
+		"CycloneDX": {
+			reason: "A CycloneDX document should describe the package, its objects and dependencies.",
+			format: CycloneDX,
+			want: want{
+				keys: []string{`"bomFormat": "CycloneDX"`, `"bom-ref": "apiextensions.crossplane.io/v1/CompositeResourceDefinition/clusters.platform.example.org"`, `"dependsOn": [`, `"version": ">=v0.40.0"`},
+			},
+		},
+		"UnknownFormat": {
+			reason: "An unknown format should return an error.",
+			format: Format("swid"),
+			want: want{
+				err: errors.Errorf(errFmtUnknownFormat, "swid"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Generate(context.Background(), img, tc.format)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGenerate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if !json.Valid(got) {
+				t.Errorf("\n%s\nGenerate(...): invalid JSON:\n%s", tc.reason, got)
+			}
+			for _, k := range tc.want.keys {
+				if !strings.Contains(string(got), k) {
+					t.Errorf("\n%s\nGenerate(...): missing %s in:\n%s", tc.reason, k, got)
+				}
+			}
+			again, err := Generate(context.Background(), img, tc.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(got), string(again)); diff != "" {
+				t.Errorf("\n%s\nGenerate(...): not reproducible: -first, +second:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAttachFetch(t *testing.T) {
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.WithReferrersSupport(true)))
+	defer srv.Close()
+
+	ref, err := name.ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/upbound/platform-ref:v0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := packageImage(t, configurationPkg)
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Generate(context.Background(), img, SPDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Fetch(context.Background(), ref, SPDX); err == nil {
+		t.Errorf("Fetch(...): expected error before SBOM is attached")
+	}
+	if _, err := Attach(context.Background(), ref, doc, SPDX); err != nil {
+		t.Fatalf("Attach(...): %v", err)
+	}
+	got, err := Fetch(context.Background(), ref, SPDX)
+	if err != nil {
+		t.Fatalf("Fetch(...): %v", err)
+	}
+	if diff := cmp.Diff(string(doc), string(got)); diff != "" {
+		t.Errorf("\nFetch(...): -want, +got:\n%s", diff)
+	}
+	if _, err := Fetch(context.Background(), ref, CycloneDX); err == nil {
+		t.Errorf("Fetch(...): expected error for format that is not attached")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	spdxVersion      = "SPDX-2.3"
+	spdxDataLicense  = "CC0-1.0"
+	spdxDocumentID   = "SPDXRef-DOCUMENT"
+	spdxPackageID    = "SPDXRef-Package"
+	spdxNoAssertion  = "NOASSERTION"
+	spdxNamespaceFmt = "https://upbound.io/spdx/%s-%s"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files,omitempty"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string         `json:"SPDXID"`
+	Name             string         `json:"name"`
+	VersionInfo      string         `json:"versionInfo,omitempty"`
+	DownloadLocation string         `json:"downloadLocation"`
+	FilesAnalyzed    bool           `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum `json:"checksums,omitempty"`
+	PrimaryPurpose   string         `json:"primaryPackagePurpose,omitempty"`
+	Comment          string         `json:"comment,omitempty"`
+}
+
+type spdxFile struct {
+	SPDXID    string         `json:"SPDXID"`
+	FileName  string         `json:"fileName"`
+	Checksums []spdxChecksum `json:"checksums"`
+}
+
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+func spdx(p *Package) spdxDocument {
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       spdxDataLicense,
+		SPDXID:            spdxDocumentID,
+		Name:              p.Name,
+		DocumentNamespace: fmtNamespace(p),
+		CreationInfo: spdxCreationInfo{
+			Created:  p.Created.Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+		Packages: []spdxPackage{{
+			SPDXID:           spdxPackageID,
+			Name:             p.Name,
+			DownloadLocation: spdxNoAssertion,
+			FilesAnalyzed:    len(p.Objects) > 0,
+			Checksums:        []spdxChecksum{{Algorithm: strings.ToUpper(p.Digest.Algorithm), Value: p.Digest.Hex}},
+			PrimaryPurpose:   "CONTAINER",
+			Comment:          p.Kind,
+		}},
+		Relationships: []spdxRelationship{{Element: spdxDocumentID, Type: "DESCRIBES", Related: spdxPackageID}},
+	}
+	for i, o := range p.Objects {
+		id := spdxID("File", i)
+		doc.Files = append(doc.Files, spdxFile{
+			SPDXID:   id,
+			FileName: o.Path(),
+			Checksums: []spdxChecksum{
+				{Algorithm: "SHA1", Value: o.SHA1},
+				{Algorithm: "SHA256", Value: o.SHA256},
+			},
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: spdxPackageID, Type: "CONTAINS", Related: id})
+	}
+	for i, d := range p.Dependencies {
+		id := spdxID("Dependency", i)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           id,
+			Name:             d.Package,
+			VersionInfo:      d.Constraints,
+			DownloadLocation: spdxNoAssertion,
+			Comment:          d.Kind,
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: spdxPackageID, Type: "DEPENDS_ON", Related: id})
+	}
+	return doc
+}
+
+func spdxID(kind string, i int) string {
+	return "SPDXRef-" + kind + "-" + strconv.Itoa(i)
+}
+
+func fmtNamespace(p *Package) string {
+	return fmt.Sprintf(spdxNamespaceFmt, p.Name, p.Digest.Hex)
+}
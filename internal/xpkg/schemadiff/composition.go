@@ -0,0 +1,296 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/render"
+)
+
+// templates are the patch and transform templates of a Composition.
+type templates struct {
+	patchSets   map[string][]xpextv1.Patch
+	environment []xpextv1.EnvironmentPatch
+	resources   map[string]xpextv1.ComposedTemplate
+	order       []string
+}
+
+// Compositions reports the changes between two versions of a Composition.
+// Composed resource templates are matched by name, or by index if they are
+// unnamed, and their patches compared. Patch and transform templates are read
+// from the input of function-patch-and-transform steps of Compositions in
+// Pipeline mode, so converting a Composition to Pipeline mode is not reported
+// as a change of its templates.
+func Compositions(old, new *xpextv1.Composition) []Change {
+	obj := compositionObject(new)
+	changes := []Change{}
+
+	if old.Spec.CompositeTypeRef != new.Spec.CompositeTypeRef {
+		changes = append(changes, Change{
+			Object:   obj,
+			Path:     "spec.compositeTypeRef",
+			Type:     ChangeModified,
+			Breaking: true,
+			Message:  fmt.Sprintf("composite type changed from %s to %s", typeRef(old.Spec.CompositeTypeRef), typeRef(new.Spec.CompositeTypeRef)),
+		})
+	}
+	if om, nm := mode(old), mode(new); om != nm {
+		changes = append(changes, Change{Object: obj, Path: "spec.mode", Type: ChangeModified, Message: fmt.Sprintf("mode changed from %s to %s", om, nm)})
+	}
+
+	changes = append(changes, diffSteps(old, new)...)
+
+	ot, nt := compositionTemplates(old), compositionTemplates(new)
+	for name, ops := range ot.patchSets {
+		nps, ok := nt.patchSets[name]
+		path := fmt.Sprintf("patchSets[%s]", name)
+		if !ok {
+			changes = append(changes, Change{Path: path, Type: ChangeRemoved, Breaking: true, Message: "patch set removed"})
+			continue
+		}
+		changes = append(changes, diffPatches(path, ops, nps)...)
+	}
+	for name := range nt.patchSets {
+		if _, ok := ot.patchSets[name]; !ok {
+			changes = append(changes, Change{Path: fmt.Sprintf("patchSets[%s]", name), Type: ChangeAdded, Message: "patch set added"})
+		}
+	}
+	changes = append(changes, diffPatches("environment", environmentPatches(ot.environment), environmentPatches(nt.environment))...)
+
+	for _, name := range ot.order {
+		o := ot.resources[name]
+		path := fmt.Sprintf("resources[%s]", name)
+		n, ok := nt.resources[name]
+		if !ok {
+			changes = append(changes, Change{Path: path, Type: ChangeRemoved, Breaking: true, Message: "composed resource removed"})
+			continue
+		}
+		if ob, nb := baseKind(o), baseKind(n); ob != nb {
+			changes = append(changes, Change{Path: path + ".base", Type: ChangeTypeChanged, Breaking: true, Message: fmt.Sprintf("composed resource kind changed from %s to %s", ob, nb)})
+		}
+		changes = append(changes, diffPatches(path, o.Patches, n.Patches)...)
+	}
+	for _, name := range nt.order {
+		if _, ok := ot.resources[name]; !ok {
+			changes = append(changes, Change{Path: fmt.Sprintf("resources[%s]", name), Type: ChangeAdded, Message: "composed resource added"})
+		}
+	}
+
+	for i := range changes {
+		changes[i].Object = obj
+	}
+	sortChanges(changes)
+	return changes
+}
+
+func compositionObject(comp *xpextv1.Composition) string {
+	return xpextv1.CompositionKind + "/" + comp.GetName()
+}
+
+func typeRef(r xpextv1.TypeReference) string {
+	return r.Kind + "." + r.APIVersion
+}
+
+func mode(comp *xpextv1.Composition) xpextv1.CompositionMode {
+	if comp.Spec.Mode == nil {
+		return xpextv1.CompositionModeResources
+	}
+	return *comp.Spec.Mode
+}
+
+// patchAndTransformInput returns the input of the supplied pipeline step if
+// it is the input of function-patch-and-transform.
+func patchAndTransformInput(s xpextv1.PipelineStep) *render.PatchAndTransformInput {
+	if s.Input == nil {
+		return nil
+	}
+	in := &render.PatchAndTransformInput{}
+	if err := json.Unmarshal(s.Input.Raw, in); err != nil {
+		return nil
+	}
+	gv, _ := schema.ParseGroupVersion(in.APIVersion)
+	pt, _ := schema.ParseGroupVersion(render.PatchAndTransformAPIVersion)
+	if gv.Group != pt.Group || in.Kind != render.PatchAndTransformKind {
+		return nil
+	}
+	return in
+}
+
+// compositionTemplates returns the patch and transform templates of the
+// supplied Composition, whether they're in its spec or in the input of its
+// function-patch-and-transform pipeline steps.
+func compositionTemplates(comp *xpextv1.Composition) templates {
+	t := templates{patchSets: map[string][]xpextv1.Patch{}, resources: map[string]xpextv1.ComposedTemplate{}}
+	add := func(ps []xpextv1.PatchSet, env []xpextv1.EnvironmentPatch, rs []xpextv1.ComposedTemplate) {
+		for _, s := range ps {
+			t.patchSets[s.Name] = s.Patches
+		}
+		t.environment = append(t.environment, env...)
+		for i, r := range rs {
+			name := fmt.Sprintf("%d", i)
+			if r.Name != nil && *r.Name != "" {
+				name = *r.Name
+			}
+			t.resources[name] = r
+			t.order = append(t.order, name)
+		}
+	}
+
+	if mode(comp) != xpextv1.CompositionModePipeline {
+		var env []xpextv1.EnvironmentPatch
+		if comp.Spec.Environment != nil {
+			env = comp.Spec.Environment.Patches
+		}
+		add(comp.Spec.PatchSets, env, comp.Spec.Resources)
+		return t
+	}
+	for _, s := range comp.Spec.Pipeline {
+		in := patchAndTransformInput(s)
+		if in == nil {
+			continue
+		}
+		var env []xpextv1.EnvironmentPatch
+		if in.Environment != nil {
+			env = in.Environment.Patches
+		}
+		add(in.PatchSets, env, in.Resources)
+	}
+	return t
+}
+
+// diffSteps reports pipeline steps that were added or removed, or that run a
+// different function. Steps are matched by name.
+func diffSteps(old, new *xpextv1.Composition) []Change {
+	changes := []Change{}
+	if mode(old) != xpextv1.CompositionModePipeline || mode(new) != xpextv1.CompositionModePipeline {
+		return changes
+	}
+	ns := make(map[string]xpextv1.PipelineStep, len(new.Spec.Pipeline))
+	for _, s := range new.Spec.Pipeline {
+		ns[s.Step] = s
+	}
+	os := make(map[string]bool, len(old.Spec.Pipeline))
+	for _, o := range old.Spec.Pipeline {
+		os[o.Step] = true
+		path := fmt.Sprintf("pipeline[%s]", o.Step)
+		n, ok := ns[o.Step]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: path, Type: ChangeRemoved, Breaking: true, Message: "pipeline step removed"})
+		case o.FunctionRef.Name != n.FunctionRef.Name:
+			changes = append(changes, Change{Path: path, Type: ChangeModified, Breaking: true, Message: fmt.Sprintf("function changed from %s to %s", o.FunctionRef.Name, n.FunctionRef.Name)})
+		}
+	}
+	for _, s := range new.Spec.Pipeline {
+		if !os[s.Step] {
+			changes = append(changes, Change{Path: fmt.Sprintf("pipeline[%s]", s.Step), Type: ChangeAdded, Message: "pipeline step added"})
+		}
+	}
+	return changes
+}
+
+// environmentPatches returns the supplied environment patches as patches, so
+// they can be compared like any other.
+func environmentPatches(eps []xpextv1.EnvironmentPatch) []xpextv1.Patch {
+	ps := make([]xpextv1.Patch, len(eps))
+	for i, ep := range eps {
+		ps[i] = xpextv1.Patch{
+			Type:          ep.Type,
+			FromFieldPath: ep.FromFieldPath,
+			Combine:       ep.Combine,
+			ToFieldPath:   ep.ToFieldPath,
+			Transforms:    ep.Transforms,
+			Policy:        ep.Policy,
+		}
+	}
+	return ps
+}
+
+// diffPatches reports patches that were removed or added. Patches are
+// compared by value, so a modified patch is reported as removed and added.
+// Removing a patch is breaking, since composed resources no longer get the
+// field it patched.
+func diffPatches(path string, old, new []xpextv1.Patch) []Change {
+	changes := []Change{}
+	remaining := map[string]int{}
+	for _, p := range new {
+		remaining[patchKey(p)]++
+	}
+	for _, p := range old {
+		k := patchKey(p)
+		if remaining[k] > 0 {
+			remaining[k]--
+			continue
+		}
+		changes = append(changes, Change{Path: path + ".patches", Type: ChangeRemoved, Breaking: true, Message: "patch removed: " + patchSummary(p)})
+	}
+	for _, p := range new {
+		k := patchKey(p)
+		if remaining[k] == 0 {
+			continue
+		}
+		remaining[k]--
+		changes = append(changes, Change{Path: path + ".patches", Type: ChangeAdded, Message: "patch added: " + patchSummary(p)})
+	}
+	return changes
+}
+
+// patchKey returns a key that is equal for equivalent patches.
+func patchKey(p xpextv1.Patch) string {
+	p.Type = p.GetType()
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+// patchSummary returns a short human readable description of a patch.
+func patchSummary(p xpextv1.Patch) string {
+	t := p.GetType()
+	if t == xpextv1.PatchTypePatchSet {
+		return fmt.Sprintf("%s %s", t, deref(p.PatchSetName))
+	}
+	from := p.GetFromFieldPath()
+	if p.Combine != nil {
+		vs := make([]string, len(p.Combine.Variables))
+		for i, v := range p.Combine.Variables {
+			vs[i] = v.FromFieldPath
+		}
+		from = strings.Join(vs, ", ")
+	}
+	return fmt.Sprintf("%s %s to %s", t, from, p.GetToFieldPath())
+}
+
+// baseKind returns the kind and API version of the base of a template.
+func baseKind(t xpextv1.ComposedTemplate) string {
+	tm := struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}{}
+	_ = json.Unmarshal(t.Base.Raw, &tm)
+	return tm.Kind + "." + tm.APIVersion
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/render"
+)
+
+const compositionV1 = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xbuckets.example.org
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XBucket
+  patchSets:
+  - name: common
+    patches:
+    - fromFieldPath: metadata.labels
+      toFieldPath: metadata.labels
+  resources:
+  - name: bucket
+    base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: Bucket
+    patches:
+    - type: PatchSet
+      patchSetName: common
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    - fromFieldPath: spec.acl
+      toFieldPath: spec.forProvider.acl
+  - name: versioning
+    base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: BucketVersioning
+`
+
+func parseComposition(t *testing.T, s string) *xpextv1.Composition {
+	t.Helper()
+	comp := &xpextv1.Composition{}
+	if err := yaml.Unmarshal([]byte(s), comp); err != nil {
+		t.Fatalf("Unmarshal(...): %s", err)
+	}
+	return comp
+}
+
+func TestCompositions(t *testing.T) {
+	obj := "Composition/xbuckets.example.org"
+
+	converted, _, err := render.ConvertToPipeline(parseComposition(t, compositionV1))
+	if err != nil {
+		t.Fatalf("ConvertToPipeline(...): %s", err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		old    *xpextv1.Composition
+		new    *xpextv1.Composition
+		want   []Change
+	}{
+		"Unchanged": {
+			reason: "Identical Compositions should have no changes.",
+			old:    parseComposition(t, compositionV1),
+			new:    parseComposition(t, compositionV1),
+			want:   []Change{},
+		},
+		"ConvertedToPipeline": {
+			reason: "Converting a Composition to a function-patch-and-transform pipeline should only change its mode.",
+			old:    parseComposition(t, compositionV1),
+			new:    converted,
+			want: []Change{
+				{Object: obj, Path: "spec.mode", Type: ChangeModified, Message: "mode changed from Resources to Pipeline"},
+			},
+		},
+		"TemplateChanges": {
+			reason: "Removed patches, patch sets and resources should be breaking, added ones should not.",
+			old:    parseComposition(t, compositionV1),
+			new: parseComposition(t, `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xbuckets.example.org
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha2
+    kind: XBucket
+  resources:
+  - name: bucket
+    base:
+      apiVersion: storage.gcp.upbound.io/v1beta1
+      kind: Bucket
+    patches:
+    - type: FromCompositeFieldPath
+      fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    - fromFieldPath: spec.acl
+      toFieldPath: spec.forProvider.predefinedAcl
+  - name: policy
+    base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: BucketPolicy
+`),
+			want: []Change{
+				{Object: obj, Path: "patchSets[common]", Type: ChangeRemoved, Breaking: true, Message: "patch set removed"},
+				{Object: obj, Path: "resources[bucket].base", Type: ChangeTypeChanged, Breaking: true, Message: "composed resource kind changed from Bucket.s3.aws.upbound.io/v1beta1 to Bucket.storage.gcp.upbound.io/v1beta1"},
+				{Object: obj, Path: "resources[bucket].patches", Type: ChangeRemoved, Breaking: true, Message: "patch removed: PatchSet common"},
+				{Object: obj, Path: "resources[bucket].patches", Type: ChangeRemoved, Breaking: true, Message: "patch removed: FromCompositeFieldPath spec.acl to spec.forProvider.acl"},
+				{Object: obj, Path: "resources[bucket].patches", Type: ChangeAdded, Message: "patch added: FromCompositeFieldPath spec.acl to spec.forProvider.predefinedAcl"},
+				{Object: obj, Path: "resources[policy]", Type: ChangeAdded, Message: "composed resource added"},
+				{Object: obj, Path: "resources[versioning]", Type: ChangeRemoved, Breaking: true, Message: "composed resource removed"},
+				{Object: obj, Path: "spec.compositeTypeRef", Type: ChangeModified, Breaking: true, Message: "composite type changed from XBucket.example.org/v1alpha1 to XBucket.example.org/v1alpha2"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Compositions(tc.old, tc.new)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nCompositions(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
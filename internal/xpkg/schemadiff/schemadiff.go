@@ -0,0 +1,167 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schemadiff compares versions of CompositeResourceDefinitions and
+// Compositions and reports semantic changes between them, flagging those that
+// would break existing composite resources and claims.
+package schemadiff
+
+import (
+	"fmt"
+	"sort"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/inspect"
+)
+
+// A ChangeType is a kind of semantic change.
+type ChangeType string
+
+// Change types.
+const (
+	ChangeAdded          ChangeType = "Added"
+	ChangeRemoved        ChangeType = "Removed"
+	ChangeRenamed        ChangeType = "Renamed"
+	ChangeTypeChanged    ChangeType = "TypeChanged"
+	ChangeDefaultChanged ChangeType = "DefaultChanged"
+	ChangeRequired       ChangeType = "Required"
+	ChangeOptional       ChangeType = "Optional"
+	ChangeModified       ChangeType = "Modified"
+)
+
+// A Change between two versions of an object.
+type Change struct {
+	// Object identifies the changed object, e.g.
+	// CompositeResourceDefinition/xbuckets.example.org.
+	Object string `json:"object"`
+
+	// Version is the changed version of a CompositeResourceDefinition, if
+	// any.
+	Version string `json:"version,omitempty"`
+
+	// Path identifies what changed within the object, e.g. the field path of
+	// a schema field or resources[bucket].patches.
+	Path string `json:"path,omitempty"`
+
+	// Type of the change.
+	Type ChangeType `json:"type"`
+
+	// Breaking is true if the change may break existing composite resources
+	// or claims.
+	Breaking bool `json:"breaking"`
+
+	// Message describes the change.
+	Message string `json:"message"`
+}
+
+func (c Change) String() string {
+	s := c.Object
+	if c.Version != "" {
+		s += " " + c.Version
+	}
+	if c.Path != "" {
+		s += " " + c.Path
+	}
+	return fmt.Sprintf("%s: %s", s, c.Message)
+}
+
+// A Report of the changes between two versions of a set of objects.
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking returns the breaking changes of the report.
+func (r *Report) Breaking() []Change {
+	out := []Change{}
+	for _, c := range r.Changes {
+		if c.Breaking {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// HasBreaking returns true if the report includes a breaking change.
+func (r *Report) HasBreaking() bool {
+	return len(r.Breaking()) > 0
+}
+
+// Packages reports the changes to the CompositeResourceDefinitions and
+// Compositions between two versions of a package, typically a Configuration.
+// Objects are matched by name. Removing an object is breaking, adding one is
+// not.
+func Packages(old, new *inspect.Package) (*Report, error) {
+	r := &Report{Changes: []Change{}}
+
+	oldXRDs := make(map[string]*xpextv1.CompositeResourceDefinition, len(old.XRDs))
+	for _, xrd := range old.XRDs {
+		oldXRDs[xrd.GetName()] = xrd
+	}
+	newXRDs := make(map[string]*xpextv1.CompositeResourceDefinition, len(new.XRDs))
+	for _, xrd := range new.XRDs {
+		newXRDs[xrd.GetName()] = xrd
+		o, ok := oldXRDs[xrd.GetName()]
+		if !ok {
+			r.Changes = append(r.Changes, Change{Object: xrdObject(xrd), Type: ChangeAdded, Message: "CompositeResourceDefinition added"})
+			continue
+		}
+		c, err := XRDs(o, xrd)
+		if err != nil {
+			return nil, err
+		}
+		r.Changes = append(r.Changes, c...)
+	}
+	for name, xrd := range oldXRDs {
+		if _, ok := newXRDs[name]; !ok {
+			r.Changes = append(r.Changes, Change{Object: xrdObject(xrd), Type: ChangeRemoved, Breaking: true, Message: "CompositeResourceDefinition removed"})
+		}
+	}
+
+	oldComps := make(map[string]*xpextv1.Composition, len(old.Compositions))
+	for _, comp := range old.Compositions {
+		oldComps[comp.GetName()] = comp
+	}
+	newComps := make(map[string]*xpextv1.Composition, len(new.Compositions))
+	for _, comp := range new.Compositions {
+		newComps[comp.GetName()] = comp
+		o, ok := oldComps[comp.GetName()]
+		if !ok {
+			r.Changes = append(r.Changes, Change{Object: compositionObject(comp), Type: ChangeAdded, Message: "Composition added"})
+			continue
+		}
+		r.Changes = append(r.Changes, Compositions(o, comp)...)
+	}
+	for name, comp := range oldComps {
+		if _, ok := newComps[name]; !ok {
+			r.Changes = append(r.Changes, Change{Object: compositionObject(comp), Type: ChangeRemoved, Breaking: true, Message: "Composition removed"})
+		}
+	}
+
+	sortChanges(r.Changes)
+	return r, nil
+}
+
+// sortChanges sorts changes by object, version and path.
+func sortChanges(cs []Change) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		if cs[i].Object != cs[j].Object {
+			return cs[i].Object < cs[j].Object
+		}
+		if cs[i].Version != cs[j].Version {
+			return cs[i].Version < cs[j].Version
+		}
+		return cs[i].Path < cs[j].Path
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/inspect"
+)
+
+func TestPackages(t *testing.T) {
+	xrd := parseXRD(t, xrdV1)
+	comp := parseComposition(t, compositionV1)
+	other := comp.DeepCopy()
+	other.SetName("xbuckets-gcp.example.org")
+
+	type want struct {
+		report   *Report
+		breaking bool
+	}
+	cases := map[string]struct {
+		reason string
+		old    *inspect.Package
+		new    *inspect.Package
+		want   want
+	}{
+		"Unchanged": {
+			reason: "Packages with the same objects should have no changes.",
+			old:    &inspect.Package{XRDs: []*xpextv1.CompositeResourceDefinition{xrd}, Compositions: []*xpextv1.Composition{comp}},
+			new:    &inspect.Package{XRDs: []*xpextv1.CompositeResourceDefinition{xrd}, Compositions: []*xpextv1.Composition{comp}},
+			want:   want{report: &Report{Changes: []Change{}}},
+		},
+		"AddedObjects": {
+			reason: "Adding objects should not be breaking.",
+			old:    &inspect.Package{XRDs: []*xpextv1.CompositeResourceDefinition{xrd}, Compositions: []*xpextv1.Composition{comp}},
+			new:    &inspect.Package{XRDs: []*xpextv1.CompositeResourceDefinition{xrd}, Compositions: []*xpextv1.Composition{comp, other}},
+			want: want{report: &Report{Changes: []Change{
+				{Object: "Composition/xbuckets-gcp.example.org", Type: ChangeAdded, Message: "Composition added"},
+			}}},
+		},
+		"RemovedObjects": {
+			reason: "Removing objects should be breaking.",
+			old:    &inspect.Package{XRDs: []*xpextv1.CompositeResourceDefinition{xrd}, Compositions: []*xpextv1.Composition{comp}},
+			new:    &inspect.Package{},
+			want: want{
+				report: &Report{Changes: []Change{
+					{Object: "CompositeResourceDefinition/xbuckets.example.org", Type: ChangeRemoved, Breaking: true, Message: "CompositeResourceDefinition removed"},
+					{Object: "Composition/xbuckets.example.org", Type: ChangeRemoved, Breaking: true, Message: "Composition removed"},
+				}},
+				breaking: true,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Packages(tc.old, tc.new)
+			if err != nil {
+				t.Fatalf("Packages(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.report, got); diff != "" {
+				t.Errorf("\n%s\nPackages(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.breaking, got.HasBreaking()); diff != "" {
+				t.Errorf("\n%s\nHasBreaking(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const (
+	errFmtParseSchema = "cannot parse schema of version %q of CompositeResourceDefinition %q"
+)
+
+// XRDs reports the changes between two versions of a
+// CompositeResourceDefinition. Versions are matched by name and their schemas
+// compared field by field.
+func XRDs(old, new *xpextv1.CompositeResourceDefinition) ([]Change, error) {
+	obj := xrdObject(new)
+	changes := []Change{}
+
+	if old.Spec.Group != new.Spec.Group || old.Spec.Names.Kind != new.Spec.Names.Kind {
+		changes = append(changes, Change{
+			Object:   obj,
+			Path:     "spec.names.kind",
+			Type:     ChangeModified,
+			Breaking: true,
+			Message:  fmt.Sprintf("composite resource kind changed from %s.%s to %s.%s", old.Spec.Names.Kind, old.Spec.Group, new.Spec.Names.Kind, new.Spec.Group),
+		})
+	}
+	switch {
+	case old.Spec.ClaimNames != nil && new.Spec.ClaimNames == nil:
+		changes = append(changes, Change{Object: obj, Path: "spec.claimNames", Type: ChangeRemoved, Breaking: true, Message: fmt.Sprintf("claim kind %s removed", old.Spec.ClaimNames.Kind)})
+	case old.Spec.ClaimNames == nil && new.Spec.ClaimNames != nil:
+		changes = append(changes, Change{Object: obj, Path: "spec.claimNames", Type: ChangeAdded, Message: fmt.Sprintf("claim kind %s added", new.Spec.ClaimNames.Kind)})
+	case old.Spec.ClaimNames != nil && old.Spec.ClaimNames.Kind != new.Spec.ClaimNames.Kind:
+		changes = append(changes, Change{Object: obj, Path: "spec.claimNames.kind", Type: ChangeModified, Breaking: true, Message: fmt.Sprintf("claim kind changed from %s to %s", old.Spec.ClaimNames.Kind, new.Spec.ClaimNames.Kind)})
+	}
+
+	newVersions := make(map[string]xpextv1.CompositeResourceDefinitionVersion, len(new.Spec.Versions))
+	for _, v := range new.Spec.Versions {
+		newVersions[v.Name] = v
+	}
+	oldVersions := make(map[string]bool, len(old.Spec.Versions))
+	for _, ov := range old.Spec.Versions {
+		oldVersions[ov.Name] = true
+		nv, ok := newVersions[ov.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Object: obj, Version: ov.Name, Type: ChangeRemoved, Breaking: ov.Served, Message: "version removed"})
+			continue
+		case ov.Served && !nv.Served:
+			changes = append(changes, Change{Object: obj, Version: ov.Name, Type: ChangeModified, Breaking: true, Message: "version no longer served"})
+		case !ov.Served && nv.Served:
+			changes = append(changes, Change{Object: obj, Version: ov.Name, Type: ChangeModified, Message: "version now served"})
+		}
+
+		os, err := versionSchema(old, ov)
+		if err != nil {
+			return nil, err
+		}
+		ns, err := versionSchema(new, nv)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range diffSchemas(os, ns) {
+			c.Object = obj
+			c.Version = ov.Name
+			changes = append(changes, c)
+		}
+	}
+	for _, v := range new.Spec.Versions {
+		if !oldVersions[v.Name] {
+			changes = append(changes, Change{Object: obj, Version: v.Name, Type: ChangeAdded, Message: "version added"})
+		}
+	}
+
+	sortChanges(changes)
+	return changes, nil
+}
+
+func xrdObject(xrd *xpextv1.CompositeResourceDefinition) string {
+	return xpextv1.CompositeResourceDefinitionKind + "/" + xrd.GetName()
+}
+
+// versionSchema returns the OpenAPI schema of the supplied version.
+func versionSchema(xrd *xpextv1.CompositeResourceDefinition, v xpextv1.CompositeResourceDefinitionVersion) (*extv1.JSONSchemaProps, error) {
+	s := &extv1.JSONSchemaProps{}
+	if v.Schema == nil || len(v.Schema.OpenAPIV3Schema.Raw) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(v.Schema.OpenAPIV3Schema.Raw, s); err != nil {
+		return nil, errors.Wrapf(err, errFmtParseSchema, v.Name, xrd.GetName())
+	}
+	return s, nil
+}
+
+// A field of a schema.
+type field struct {
+	parent   string
+	schema   *extv1.JSONSchemaProps
+	required bool
+}
+
+// fields flattens the supplied schema into its fields, keyed by field path.
+// Array items are addressed as path[*].
+func fields(s *extv1.JSONSchemaProps) map[string]field {
+	out := map[string]field{}
+	var walk func(path string, s *extv1.JSONSchemaProps)
+	walk = func(path string, s *extv1.JSONSchemaProps) {
+		required := make(map[string]bool, len(s.Required))
+		for _, r := range s.Required {
+			required[r] = true
+		}
+		for name := range s.Properties {
+			p := s.Properties[name]
+			fp := name
+			if path != "" {
+				fp = path + "." + name
+			}
+			out[fp] = field{parent: path, schema: &p, required: required[name]}
+			walk(fp, &p)
+		}
+		if s.Items != nil && s.Items.Schema != nil {
+			fp := path + "[*]"
+			out[fp] = field{parent: path, schema: s.Items.Schema}
+			walk(fp, s.Items.Schema)
+		}
+	}
+	walk("", s)
+	return out
+}
+
+// diffSchemas reports the changes between the fields of two schemas. A field
+// removed from and a field with an identical schema added to the same parent
+// are reported as a rename. Only the outermost of removed or added fields are
+// reported.
+func diffSchemas(old, new *extv1.JSONSchemaProps) []Change {
+	of, nf := fields(old), fields(new)
+	changes := []Change{}
+
+	removed := map[string][]string{}
+	added := map[string][]string{}
+	for p, f := range of {
+		if _, ok := nf[p]; ok {
+			continue
+		}
+		if _, ok := nf[f.parent]; f.parent != "" && !ok {
+			continue
+		}
+		removed[f.parent] = append(removed[f.parent], p)
+	}
+	for p, f := range nf {
+		if _, ok := of[p]; ok {
+			continue
+		}
+		if _, ok := of[f.parent]; f.parent != "" && !ok {
+			continue
+		}
+		added[f.parent] = append(added[f.parent], p)
+	}
+
+	for parent := range removed {
+		for _, r := range removed[parent] {
+			matches := sameSchema(of[r].schema, nf, added[parent])
+			if len(matches) != 1 || len(sameSchema(nf[matches[0]].schema, of, removed[parent])) != 1 {
+				changes = append(changes, Change{Path: r, Type: ChangeRemoved, Breaking: true, Message: "field removed"})
+				continue
+			}
+			a := matches[0]
+			changes = append(changes, Change{Path: r, Type: ChangeRenamed, Breaking: true, Message: fmt.Sprintf("field renamed to %s", a)})
+			added[parent] = without(added[parent], a)
+		}
+	}
+	for _, as := range added {
+		for _, a := range as {
+			f := nf[a]
+			if f.required && f.schema.Default == nil {
+				changes = append(changes, Change{Path: a, Type: ChangeAdded, Breaking: true, Message: "required field without default added"})
+				continue
+			}
+			changes = append(changes, Change{Path: a, Type: ChangeAdded, Message: "field added"})
+		}
+	}
+
+	for p, o := range of {
+		n, ok := nf[p]
+		if !ok {
+			continue
+		}
+		if ot, nt := schemaType(o.schema), schemaType(n.schema); ot != nt {
+			changes = append(changes, Change{Path: p, Type: ChangeTypeChanged, Breaking: true, Message: fmt.Sprintf("type changed from %s to %s", ot, nt)})
+		}
+		if od, nd := defaultValue(o.schema), defaultValue(n.schema); od != nd {
+			changes = append(changes, defaultChange(p, od, nd))
+		}
+		switch {
+		case !o.required && n.required:
+			changes = append(changes, Change{Path: p, Type: ChangeRequired, Breaking: n.schema.Default == nil, Message: "field became required"})
+		case o.required && !n.required:
+			changes = append(changes, Change{Path: p, Type: ChangeOptional, Message: "field became optional"})
+		}
+	}
+
+	sortChanges(changes)
+	return changes
+}
+
+// sameSchema returns the candidate fields whose schema is identical to the
+// supplied one, ignoring descriptions.
+func sameSchema(s *extv1.JSONSchemaProps, fs map[string]field, candidates []string) []string {
+	out := []string{}
+	for _, c := range candidates {
+		if reflect.DeepEqual(withoutDescriptions(s), withoutDescriptions(fs[c].schema)) {
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func withoutDescriptions(s *extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	s = s.DeepCopy()
+	var strip func(s *extv1.JSONSchemaProps)
+	strip = func(s *extv1.JSONSchemaProps) {
+		s.Description = ""
+		for k, p := range s.Properties {
+			strip(&p)
+			s.Properties[k] = p
+		}
+		if s.Items != nil && s.Items.Schema != nil {
+			strip(s.Items.Schema)
+		}
+	}
+	strip(s)
+	return s
+}
+
+func without(ss []string, s string) []string {
+	out := make([]string, 0, len(ss))
+	for _, e := range ss {
+		if e != s {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// schemaType returns the type of the supplied schema.
+func schemaType(s *extv1.JSONSchemaProps) string {
+	switch {
+	case s.XIntOrString:
+		return "int-or-string"
+	case s.Type == "":
+		return "any"
+	case s.Format != "":
+		return s.Type + "(" + s.Format + ")"
+	}
+	return s.Type
+}
+
+// defaultValue returns the default of the supplied schema as compact JSON, or
+// an empty string if it has none.
+func defaultValue(s *extv1.JSONSchemaProps) string {
+	if s.Default == nil {
+		return ""
+	}
+	var v any
+	if err := json.Unmarshal(s.Default.Raw, &v); err != nil {
+		return strings.TrimSpace(string(s.Default.Raw))
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// defaultChange reports a change of the default of a field. Adding a default
+// doesn't change existing resources, while changing or removing one changes
+// what new resources that omit the field get.
+func defaultChange(path, old, new string) Change {
+	switch {
+	case old == "":
+		return Change{Path: path, Type: ChangeDefaultChanged, Message: fmt.Sprintf("default %s added", new)}
+	case new == "":
+		return Change{Path: path, Type: ChangeDefaultChanged, Breaking: true, Message: fmt.Sprintf("default %s removed", old)}
+	}
+	return Change{Path: path, Type: ChangeDefaultChanged, Breaking: true, Message: fmt.Sprintf("default changed from %s to %s", old, new)}
+}
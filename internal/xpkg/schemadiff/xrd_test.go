@@ -0,0 +1,228 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const xrdV1 = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xbuckets.example.org
+spec:
+  group: example.org
+  names:
+    kind: XBucket
+    plural: xbuckets
+  claimNames:
+    kind: Bucket
+    plural: buckets
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - region
+            properties:
+              region:
+                type: string
+                description: Region of the bucket.
+              versioning:
+                type: boolean
+                default: false
+              tags:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    key:
+                      type: string
+                    value:
+                      type: string
+              acl:
+                type: string
+                default: private
+`
+
+func parseXRD(t *testing.T, s string) *xpextv1.CompositeResourceDefinition {
+	t.Helper()
+	xrd := &xpextv1.CompositeResourceDefinition{}
+	if err := yaml.Unmarshal([]byte(s), xrd); err != nil {
+		t.Fatalf("Unmarshal(...): %s", err)
+	}
+	return xrd
+}
+
+func TestXRDs(t *testing.T) {
+	obj := "CompositeResourceDefinition/xbuckets.example.org"
+
+	type want struct {
+		changes []Change
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		old    string
+		new    string
+		want   want
+	}{
+		"Unchanged": {
+			reason: "Identical XRDs should have no changes.",
+			old:    xrdV1,
+			new:    xrdV1,
+			want:   want{changes: []Change{}},
+		},
+		"SchemaChanges": {
+			reason: "Field additions, removals, renames, type, default and requirement changes should be reported.",
+			old:    xrdV1,
+			new: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xbuckets.example.org
+spec:
+  group: example.org
+  names:
+    kind: XBucket
+    plural: xbuckets
+  claimNames:
+    kind: Bucket
+    plural: buckets
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - location
+            - size
+            properties:
+              location:
+                type: string
+                description: Location of the bucket.
+              versioning:
+                type: string
+                default: Suspended
+              labels:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    key:
+                      type: string
+                    value:
+                      type: integer
+              size:
+                type: integer
+              encrypted:
+                type: boolean
+`,
+			want: want{changes: []Change{
+				{Object: obj, Version: "v1alpha1", Path: "spec.acl", Type: ChangeRemoved, Breaking: true, Message: "field removed"},
+				{Object: obj, Version: "v1alpha1", Path: "spec.encrypted", Type: ChangeAdded, Message: "field added"},
+				{Object: obj, Version: "v1alpha1", Path: "spec.labels", Type: ChangeAdded, Message: "field added"},
+				{Object: obj, Version: "v1alpha1", Path: "spec.region", Type: ChangeRenamed, Breaking: true, Message: "field renamed to spec.location"},
+				{Object: obj, Version: "v1alpha1", Path: "spec.size", Type: ChangeAdded, Breaking: true, Message: "required field without default added"},
+				{Object: obj, Version: "v1alpha1", Path: "spec.tags", Type: ChangeRemoved, Breaking: true, Message: "field removed"},
+				{Object: obj, Version: "v1alpha1", Path: "spec.versioning", Type: ChangeTypeChanged, Breaking: true, Message: "type changed from boolean to string"},
+				{Object: obj, Version: "v1alpha1", Path: "spec.versioning", Type: ChangeDefaultChanged, Breaking: true, Message: `default changed from false to "Suspended"`},
+			}},
+		},
+		"Versions": {
+			reason: "Removing a served version and renaming the claim should be breaking, adding a version should not.",
+			old:    xrdV1,
+			new: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xbuckets.example.org
+spec:
+  group: example.org
+  names:
+    kind: XBucket
+    plural: xbuckets
+  claimNames:
+    kind: ObjectStore
+    plural: objectstores
+  versions:
+  - name: v1beta1
+    served: true
+    referenceable: true
+`,
+			want: want{changes: []Change{
+				{Object: obj, Path: "spec.claimNames.kind", Type: ChangeModified, Breaking: true, Message: "claim kind changed from Bucket to ObjectStore"},
+				{Object: obj, Version: "v1alpha1", Type: ChangeRemoved, Breaking: true, Message: "version removed"},
+				{Object: obj, Version: "v1beta1", Type: ChangeAdded, Message: "version added"},
+			}},
+		},
+		"InvalidSchema": {
+			reason: "A schema that can't be parsed should return an error.",
+			old:    xrdV1,
+			new: `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xbuckets.example.org
+spec:
+  group: example.org
+  names:
+    kind: XBucket
+    plural: xbuckets
+  claimNames:
+    kind: Bucket
+    plural: buckets
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: [object]
+`,
+			want: want{err: errors.Wrapf(errors.New("json: cannot unmarshal array into Go struct field JSONSchemaProps.type of type string"), errFmtParseSchema, "v1alpha1", "xbuckets.example.org")},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := XRDs(parseXRD(t, tc.old), parseXRD(t, tc.new))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nXRDs(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changes, got); diff != "" {
+				t.Errorf("\n%s\nXRDs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errReadKey          = "failed to read key"
+	errReadRoots        = "failed to read certificate roots"
+	errNoRoots          = "no certificates found in certificate roots"
+	errNoSigner         = "either --sign-key or --sign-keyless must be specified"
+	errNoIdentityToken  = "an identity token is required for keyless signing"
+	errNoVerifierFlags  = "either --verify-key or --certificate-identity must be specified"
+	errRootsNoIdentity  = "--certificate-roots must be specified with --certificate-identity"
+	errKeylessAndKeyed  = "--sign-key and --sign-keyless are mutually exclusive"
+	errIdentityNoIssuer = "--certificate-oidc-issuer must be specified with --certificate-identity"
+	errRekorNoIdentity  = "--rekor-key must be specified with --certificate-identity"
+)
+
+// SignFlags are flags used by commands that sign packages.
+type SignFlags struct {
+	SignKey         string `name:"sign-key" type:"path" help:"Path to the PEM encoded private key to sign with. Keys generated by cosign are supported."`
+	SignKeyPassword string `name:"sign-key-password" env:"COSIGN_PASSWORD" help:"Password of an encrypted signing key."`
+	SignKeyless     bool   `name:"sign-keyless" help:"Sign with a short-lived certificate issued for an OIDC identity."`
+	IdentityToken   string `name:"identity-token" env:"SIGSTORE_ID_TOKEN" help:"OIDC identity token used for keyless signing."`
+	FulcioURL       string `name:"fulcio-url" default:"https://fulcio.sigstore.dev" help:"URL of the certificate authority used for keyless signing."`
+	RekorURL        string `name:"rekor-url" default:"https://rekor.sigstore.dev" help:"URL of the transparency log keyless signatures are recorded in."`
+}
+
+// Enabled returns true if signing was requested.
+func (f *SignFlags) Enabled() bool {
+	return f.SignKey != "" || f.SignKeyless
+}
+
+// Signer returns the Signer configured by the flags.
+func (f *SignFlags) Signer() (Signer, error) {
+	switch {
+	case f.SignKey != "" && f.SignKeyless:
+		return nil, errors.New(errKeylessAndKeyed)
+	case f.SignKey != "":
+		b, err := os.ReadFile(filepath.Clean(f.SignKey))
+		if err != nil {
+			return nil, errors.Wrap(err, errReadKey)
+		}
+		k, err := LoadPrivateKey(b, []byte(f.SignKeyPassword))
+		if err != nil {
+			return nil, err
+		}
+		return NewKeySigner(k), nil
+	case f.SignKeyless:
+		if f.IdentityToken == "" {
+			return nil, errors.New(errNoIdentityToken)
+		}
+		return NewKeylessSigner(f.IdentityToken, WithFulcioURL(f.FulcioURL), WithRekorURL(f.RekorURL)), nil
+	}
+	return nil, errors.New(errNoSigner)
+}
+
+// VerifyFlags are flags used by commands that verify package signatures.
+type VerifyFlags struct {
+	VerifyKey             string `name:"verify-key" type:"path" help:"Path to the PEM encoded public key trusted to sign packages."`
+	CertificateIdentity   string `name:"certificate-identity" help:"Email address or URI trusted to sign packages keylessly."`
+	CertificateOIDCIssuer string `name:"certificate-oidc-issuer" help:"OIDC issuer that must have authenticated the certificate identity."`
+	CertificateRoots      string `name:"certificate-roots" type:"path" help:"Path to the PEM encoded root certificates trusted to issue keyless signing certificates."`
+	RekorKey              string `name:"rekor-key" type:"path" help:"Path to the PEM encoded public key of the transparency log keyless signatures must be recorded in."`
+}
+
+// Enabled returns true if any verification flags were supplied.
+func (f *VerifyFlags) Enabled() bool {
+	return f.VerifyKey != "" || f.CertificateIdentity != ""
+}
+
+// Verifiers returns the Verifiers configured by the flags.
+func (f *VerifyFlags) Verifiers() ([]Verifier, error) {
+	vs := []Verifier{}
+	if f.VerifyKey != "" {
+		b, err := os.ReadFile(filepath.Clean(f.VerifyKey))
+		if err != nil {
+			return nil, errors.Wrap(err, errReadKey)
+		}
+		k, err := LoadPublicKey(b)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, NewKeyVerifier(k))
+	}
+	if f.CertificateIdentity != "" {
+		if f.CertificateOIDCIssuer == "" {
+			return nil, errors.New(errIdentityNoIssuer)
+		}
+		if f.CertificateRoots == "" {
+			return nil, errors.New(errRootsNoIdentity)
+		}
+		if f.RekorKey == "" {
+			return nil, errors.New(errRekorNoIdentity)
+		}
+		b, err := os.ReadFile(filepath.Clean(f.CertificateRoots))
+		if err != nil {
+			return nil, errors.Wrap(err, errReadRoots)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(b) {
+			return nil, errors.New(errNoRoots)
+		}
+		b, err = os.ReadFile(filepath.Clean(f.RekorKey))
+		if err != nil {
+			return nil, errors.Wrap(err, errReadKey)
+		}
+		rekor, err := LoadPublicKey(b)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, NewKeylessVerifier(roots, rekor, CertificateIdentity{
+			Subject: f.CertificateIdentity,
+			Issuer:  f.CertificateOIDCIssuer,
+		}))
+	}
+	if len(vs) == 0 {
+		return nil, errors.New(errNoVerifierFlags)
+	}
+	return vs, nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	pemEncryptedKey       = "ENCRYPTED SIGSTORE PRIVATE KEY"
+	pemEncryptedCosignKey = "ENCRYPTED COSIGN PRIVATE KEY"
+	pemPrivateKey         = "PRIVATE KEY"
+	pemECPrivateKey       = "EC PRIVATE KEY"
+	pemRSAPrivateKey      = "RSA PRIVATE KEY"
+	pemPublicKey          = "PUBLIC KEY"
+
+	kdfScrypt        = "scrypt"
+	cipherSecretbox  = "nacl/secretbox"
+	secretboxKeySize = 32
+
+	errDecodePEM         = "failed to decode PEM block"
+	errFmtPEMType        = "unsupported PEM block type %q"
+	errParseKey          = "failed to parse key"
+	errNotSigner         = "private key cannot sign"
+	errDecryptKey        = "failed to decrypt private key, is the password correct?"
+	errFmtKDF            = "unsupported key derivation function %q"
+	errFmtCipher         = "unsupported cipher %q"
+	errUnsupportedKey    = "unsupported key type"
+	errInvalidSignature  = "invalid signature"
+	errParseEncryptedKey = "failed to parse encrypted private key"
+)
+
+// encryptedKey is the format cosign stores encrypted private keys in.
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadPrivateKey parses a PEM encoded private key. Keys encrypted by cosign
+// are decrypted with the supplied password.
+func LoadPrivateKey(b, password []byte) (crypto.Signer, error) { //nolint:gocyclo
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return nil, errors.New(errDecodePEM)
+	}
+	der := p.Bytes
+	switch p.Type {
+	case pemEncryptedKey, pemEncryptedCosignKey:
+		var err error
+		if der, err = decrypt(p.Bytes, password); err != nil {
+			return nil, err
+		}
+	case pemECPrivateKey:
+		k, err := x509.ParseECPrivateKey(der)
+		return k, errors.Wrap(err, errParseKey)
+	case pemRSAPrivateKey:
+		k, err := x509.ParsePKCS1PrivateKey(der)
+		return k, errors.Wrap(err, errParseKey)
+	case pemPrivateKey:
+	default:
+		return nil, errors.Errorf(errFmtPEMType, p.Type)
+	}
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseKey)
+	}
+	s, ok := k.(crypto.Signer)
+	if !ok {
+		return nil, errors.New(errNotSigner)
+	}
+	return s, nil
+}
+
+// LoadPublicKey parses a PEM encoded public key.
+func LoadPublicKey(b []byte) (crypto.PublicKey, error) {
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return nil, errors.New(errDecodePEM)
+	}
+	if p.Type != pemPublicKey {
+		return nil, errors.Errorf(errFmtPEMType, p.Type)
+	}
+	k, err := x509.ParsePKIXPublicKey(p.Bytes)
+	return k, errors.Wrap(err, errParseKey)
+}
+
+func decrypt(b, password []byte) ([]byte, error) {
+	k := &encryptedKey{}
+	if err := json.Unmarshal(b, k); err != nil {
+		return nil, errors.Wrap(err, errParseEncryptedKey)
+	}
+	if k.KDF.Name != kdfScrypt {
+		return nil, errors.Errorf(errFmtKDF, k.KDF.Name)
+	}
+	if k.Cipher.Name != cipherSecretbox {
+		return nil, errors.Errorf(errFmtCipher, k.Cipher.Name)
+	}
+	key, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, secretboxKeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, errDecryptKey)
+	}
+	var sk [secretboxKeySize]byte
+	var nonce [24]byte
+	copy(sk[:], key)
+	copy(nonce[:], k.Cipher.Nonce)
+	out, ok := secretbox.Open(nil, k.Ciphertext, &nonce, &sk)
+	if !ok {
+		return nil, errors.New(errDecryptKey)
+	}
+	return out, nil
+}
+
+// KeySigner signs payloads with a private key.
+type KeySigner struct {
+	key crypto.Signer
+}
+
+// NewKeySigner returns a Signer that signs with the supplied key.
+func NewKeySigner(key crypto.Signer) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+// Sign signs the supplied payload.
+func (s *KeySigner) Sign(_ context.Context, payload []byte) (*Signature, error) {
+	sig, err := signWith(s.key, payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{Payload: payload, Signature: sig}, nil
+}
+
+// KeyVerifier verifies signatures with a public key.
+type KeyVerifier struct {
+	key crypto.PublicKey
+}
+
+// NewKeyVerifier returns a Verifier that trusts signatures made with the
+// private key corresponding to the supplied public key.
+func NewKeyVerifier(key crypto.PublicKey) *KeyVerifier {
+	return &KeyVerifier{key: key}
+}
+
+// Verify returns an error if the supplied signature was not made with the
+// verifier's key.
+func (v *KeyVerifier) Verify(_ context.Context, sig *Signature) error {
+	return verifyWith(v.key, sig.Payload, sig.Signature)
+}
+
+func signWith(key crypto.Signer, payload []byte) ([]byte, error) {
+	if _, ok := key.Public().(ed25519.PublicKey); ok {
+		return key.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	h := sha256.Sum256(payload)
+	return key.Sign(rand.Reader, h[:], crypto.SHA256)
+}
+
+func verifyWith(key crypto.PublicKey, payload, sig []byte) error {
+	h := sha256.Sum256(payload)
+	ok := false
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, h[:], sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig) == nil
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, payload, sig)
+	default:
+		return errors.New(errUnsupportedKey)
+	}
+	if !ok {
+		return errors.New(errInvalidSignature)
+	}
+	return nil
+}
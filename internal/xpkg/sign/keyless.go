@@ -0,0 +1,360 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// DefaultFulcioURL is the URL of the public Sigstore certificate
+	// authority.
+	DefaultFulcioURL = "https://fulcio.sigstore.dev"
+
+	fulcioSigningCertPath = "/api/v2/signingCert"
+
+	errGenerateKey      = "failed to generate ephemeral signing key"
+	errParseToken       = "failed to parse identity token"
+	errNoTokenSubject   = "identity token has no subject or email"
+	errRequestCert      = "failed to request signing certificate"
+	errFmtFulcioStatus  = "certificate authority returned %d: %s"
+	errNoCertificate    = "certificate authority returned no certificates"
+	errNoSigCertificate = "signature has no certificate"
+	errParseCertificate = "failed to parse signing certificate"
+	errVerifyChain      = "signing certificate is not trusted"
+	errFmtIdentity      = "signing certificate identity %q issued by %q is not trusted"
+)
+
+var (
+	// oidIssuer is the Fulcio certificate extension holding the raw OIDC
+	// issuer URL.
+	oidIssuer = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	// oidIssuerV2 is the Fulcio certificate extension holding the DER
+	// encoded OIDC issuer URL.
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// KeylessOption modifies a KeylessSigner.
+type KeylessOption func(*KeylessSigner)
+
+// WithFulcioURL sets the URL of the certificate authority that issues signing
+// certificates.
+func WithFulcioURL(u string) KeylessOption {
+	return func(s *KeylessSigner) {
+		s.fulcio = strings.TrimSuffix(u, "/")
+	}
+}
+
+// WithRekorURL sets the URL of the transparency log signatures are recorded
+// in.
+func WithRekorURL(u string) KeylessOption {
+	return func(s *KeylessSigner) {
+		s.rekor = strings.TrimSuffix(u, "/")
+	}
+}
+
+// WithHTTPClient sets the client used to talk to the certificate authority
+// and the transparency log.
+func WithHTTPClient(c *http.Client) KeylessOption {
+	return func(s *KeylessSigner) {
+		s.client = c
+	}
+}
+
+// KeylessSigner signs payloads with an ephemeral key, certified by Fulcio for
+// the identity in an OIDC identity token, and records the signatures in the
+// Rekor transparency log.
+type KeylessSigner struct {
+	token  string
+	fulcio string
+	rekor  string
+	client *http.Client
+}
+
+// NewKeylessSigner returns a Signer that obtains a signing certificate for
+// the supplied OIDC identity token.
+func NewKeylessSigner(token string, opts ...KeylessOption) *KeylessSigner {
+	s := &KeylessSigner{
+		token:  token,
+		fulcio: DefaultFulcioURL,
+		rekor:  DefaultRekorURL,
+		client: http.DefaultClient,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+type fulcioRequest struct {
+	Credentials struct {
+		OIDCIdentityToken string `json:"oidcIdentityToken"`
+	} `json:"credentials"`
+	PublicKeyRequest struct {
+		PublicKey struct {
+			Algorithm string `json:"algorithm"`
+			Content   string `json:"content"`
+		} `json:"publicKey"`
+		ProofOfPossession []byte `json:"proofOfPossession"`
+	} `json:"publicKeyRequest"`
+}
+
+type fulcioChain struct {
+	Chain struct {
+		Certificates []string `json:"certificates"`
+	} `json:"chain"`
+}
+
+type fulcioResponse struct {
+	Embedded *fulcioChain `json:"signedCertificateEmbeddedSct,omitempty"`
+	Detached *fulcioChain `json:"signedCertificateDetachedSct,omitempty"`
+}
+
+// Sign signs the supplied payload with an ephemeral key, records the signature
+// in the transparency log, and returns it along with the certificate issued
+// for the key and the proof that it was recorded.
+func (s *KeylessSigner) Sign(ctx context.Context, payload []byte) (*Signature, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, errGenerateKey)
+	}
+	certs, err := s.certificate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signWith(key, payload)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.record(ctx, payload, sig, []byte(certs[0]))
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{
+		Payload:     payload,
+		Signature:   sig,
+		Certificate: []byte(certs[0]),
+		Chain:       []byte(strings.Join(certs[1:], "")),
+		Bundle:      b,
+	}, nil
+}
+
+func (s *KeylessSigner) certificate(ctx context.Context, key *ecdsa.PrivateKey) ([]string, error) {
+	sub, err := tokenSubject(s.token)
+	if err != nil {
+		return nil, err
+	}
+	// Fulcio requires proof that we hold the private key, in the form of a
+	// signature of the token's subject.
+	proof, err := signWith(key, []byte(sub))
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestCert)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestCert)
+	}
+
+	req := fulcioRequest{}
+	req.Credentials.OIDCIdentityToken = s.token
+	req.PublicKeyRequest.PublicKey.Algorithm = "ECDSA"
+	req.PublicKeyRequest.PublicKey.Content = string(pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}))
+	req.PublicKeyRequest.ProofOfPossession = proof
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestCert)
+	}
+
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.fulcio+fulcioSigningCertPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestCert)
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("Accept", "application/json")
+	hres, err := s.client.Do(hreq)
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestCert)
+	}
+	defer hres.Body.Close() //nolint:errcheck
+	b, err := io.ReadAll(hres.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, errRequestCert)
+	}
+	if hres.StatusCode != http.StatusOK && hres.StatusCode != http.StatusCreated {
+		return nil, errors.Errorf(errFmtFulcioStatus, hres.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	res := fulcioResponse{}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, errors.Wrap(err, errRequestCert)
+	}
+	chain := res.Embedded
+	if chain == nil {
+		chain = res.Detached
+	}
+	if chain == nil || len(chain.Chain.Certificates) == 0 {
+		return nil, errors.New(errNoCertificate)
+	}
+	return chain.Chain.Certificates, nil
+}
+
+// tokenSubject returns the identity Fulcio will certify for the supplied
+// token. The token is not verified; Fulcio does that.
+func tokenSubject(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New(errParseToken)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, errParseToken)
+	}
+	claims := struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+	}{}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return "", errors.Wrap(err, errParseToken)
+	}
+	switch {
+	case claims.Email != "":
+		return claims.Email, nil
+	case claims.Subject != "":
+		return claims.Subject, nil
+	}
+	return "", errors.New(errNoTokenSubject)
+}
+
+// CertificateIdentity is an identity trusted to sign packages keylessly.
+type CertificateIdentity struct {
+	// Subject is the email address or URI the signing certificate was issued
+	// to.
+	Subject string
+	// Issuer is the URL of the OIDC provider that authenticated the subject.
+	Issuer string
+}
+
+// KeylessVerifier verifies signatures made by trusted identities with
+// certificates issued by a trusted certificate authority, and recorded in a
+// trusted transparency log.
+//
+// Certificates issued by Fulcio are only valid for a few minutes. The
+// transparency log establishes that a signature was made while its
+// certificate was valid: its signed entry timestamp, and the inclusion proof
+// of its entry in a signed checkpoint, are verified offline with the log's
+// public key. Signed certificate timestamps (SCTs) are not verified.
+type KeylessVerifier struct {
+	roots      *x509.CertPool
+	rekor      crypto.PublicKey
+	identities []CertificateIdentity
+}
+
+// NewKeylessVerifier returns a Verifier that trusts signatures made by any of
+// the supplied identities with certificates chaining to the supplied roots,
+// and recorded in the transparency log with the supplied public key.
+func NewKeylessVerifier(roots *x509.CertPool, rekor crypto.PublicKey, identities ...CertificateIdentity) *KeylessVerifier {
+	return &KeylessVerifier{roots: roots, rekor: rekor, identities: identities}
+}
+
+// Verify returns an error if the supplied signature was not made by a trusted
+// identity.
+func (v *KeylessVerifier) Verify(_ context.Context, sig *Signature) error {
+	if len(sig.Certificate) == 0 {
+		return errors.New(errNoSigCertificate)
+	}
+	p, _ := pem.Decode(sig.Certificate)
+	if p == nil {
+		return errors.New(errParseCertificate)
+	}
+	cert, err := x509.ParseCertificate(p.Bytes)
+	if err != nil {
+		return errors.Wrap(err, errParseCertificate)
+	}
+	t, err := verifyBundle(v.rekor, sig, cert)
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	intermediates.AppendCertsFromPEM(sig.Chain)
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   t,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return errors.Wrap(err, errVerifyChain)
+	}
+
+	subjects := certSubjects(cert)
+	issuer := certIssuer(cert.Extensions)
+	if !v.trusted(subjects, issuer) {
+		return errors.Errorf(errFmtIdentity, strings.Join(subjects, ","), issuer)
+	}
+	return verifyWith(cert.PublicKey, sig.Payload, sig.Signature)
+}
+
+func (v *KeylessVerifier) trusted(subjects []string, issuer string) bool {
+	for _, id := range v.identities {
+		if id.Issuer != "" && id.Issuer != issuer {
+			continue
+		}
+		for _, s := range subjects {
+			if id.Subject == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func certSubjects(cert *x509.Certificate) []string {
+	out := append([]string{}, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		out = append(out, u.String())
+	}
+	return out
+}
+
+func certIssuer(exts []pkix.Extension) string {
+	for _, e := range exts {
+		switch {
+		case e.Id.Equal(oidIssuerV2):
+			var s string
+			if _, err := asn1.Unmarshal(e.Value, &s); err == nil {
+				return s
+			}
+		case e.Id.Equal(oidIssuer):
+			return string(e.Value)
+		}
+	}
+	return ""
+}
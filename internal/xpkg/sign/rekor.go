@@ -0,0 +1,367 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	// DefaultRekorURL is the URL of the public Sigstore transparency log.
+	DefaultRekorURL = "https://rekor.sigstore.dev"
+
+	rekorEntriesPath = "/api/v1/log/entries"
+
+	kindHashedRekord       = "hashedrekord"
+	hashedRekordAPIVersion = "0.0.1"
+	hashAlgorithmSHA256    = "sha256"
+
+	// Prefixes of the leaves and nodes of RFC 6962 Merkle trees.
+	leafHashPrefix = 0x00
+	nodeHashPrefix = 0x01
+
+	// checkpointSigPrefix starts each signature line of a signed note.
+	checkpointSigPrefix = "— "
+
+	errRecordEntry        = "failed to record signature in transparency log"
+	errFmtRekorStatus     = "transparency log returned %d: %s"
+	errNoEntry            = "transparency log returned no entry"
+	errNoBundle           = "signature has no transparency log entry"
+	errParseBundle        = "failed to parse transparency log entry"
+	errLogKey             = "failed to encode transparency log key"
+	errFmtLogID           = "transparency log entry is from untrusted log %s"
+	errVerifySET          = "transparency log entry is not signed by the trusted log"
+	errEntryMismatch      = "transparency log entry does not match signature"
+	errNoInclusionProof   = "transparency log entry has no inclusion proof"
+	errParseProof         = "failed to parse inclusion proof"
+	errFmtProofIndex      = "inclusion proof index %d is not in tree of size %d"
+	errProofLength        = "inclusion proof has the wrong number of hashes"
+	errProofRoot          = "inclusion proof does not match the tree root"
+	errParseCheckpoint    = "failed to parse transparency log checkpoint"
+	errCheckpointMismatch = "transparency log checkpoint does not match inclusion proof"
+	errVerifyCheckpoint   = "transparency log checkpoint is not signed by the trusted log"
+	errFmtIntegratedTime  = "signature was recorded at %s, outside the validity of its certificate"
+)
+
+// hashedRekord is the transparency log entry recorded for a signature.
+type hashedRekord struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Spec       hashedRekordSpec `json:"spec"`
+}
+
+type hashedRekordSpec struct {
+	Data struct {
+		Hash struct {
+			Algorithm string `json:"algorithm"`
+			Value     string `json:"value"`
+		} `json:"hash"`
+	} `json:"data"`
+	Signature struct {
+		Content   []byte `json:"content"`
+		PublicKey struct {
+			Content []byte `json:"content"`
+		} `json:"publicKey"`
+	} `json:"signature"`
+}
+
+// inclusionProof proves that an entry is included in the tree of the
+// transparency log committed to by the signed checkpoint.
+type inclusionProof struct {
+	Checkpoint string   `json:"checkpoint"`
+	Hashes     []string `json:"hashes"`
+	LogIndex   int64    `json:"logIndex"`
+	RootHash   string   `json:"rootHash"`
+	TreeSize   int64    `json:"treeSize"`
+}
+
+// logEntry is an entry of the transparency log as returned by its API.
+type logEntry struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+	Verification   struct {
+		InclusionProof       *inclusionProof `json:"inclusionProof,omitempty"`
+		SignedEntryTimestamp []byte          `json:"signedEntryTimestamp"`
+	} `json:"verification"`
+}
+
+// bundlePayload is the part of an entry the log signs in its signed entry
+// timestamp. Its fields are ordered so that it marshals to canonical JSON.
+type bundlePayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// bundle is the proof, stored next to a keyless signature, that it was
+// recorded in the transparency log. It is the bundle cosign stores, extended
+// with the inclusion proof of the entry.
+type bundle struct {
+	SignedEntryTimestamp []byte          `json:"SignedEntryTimestamp"`
+	Payload              bundlePayload   `json:"Payload"`
+	InclusionProof       *inclusionProof `json:"InclusionProof,omitempty"`
+}
+
+// record records the supplied signature, made with the key of the supplied
+// PEM encoded certificate, in the transparency log and returns the JSON
+// encoded bundle proving it was recorded.
+func (s *KeylessSigner) record(ctx context.Context, payload, sig, cert []byte) ([]byte, error) {
+	h := sha256.Sum256(payload)
+	e := hashedRekord{APIVersion: hashedRekordAPIVersion, Kind: kindHashedRekord}
+	e.Spec.Data.Hash.Algorithm = hashAlgorithmSHA256
+	e.Spec.Data.Hash.Value = hex.EncodeToString(h[:])
+	e.Spec.Signature.Content = sig
+	e.Spec.Signature.PublicKey.Content = cert
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, errors.Wrap(err, errRecordEntry)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.rekor+rekorEntriesPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, errRecordEntry)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errRecordEntry)
+	}
+	defer res.Body.Close() //nolint:errcheck
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, errRecordEntry)
+	}
+	if res.StatusCode != http.StatusCreated {
+		return nil, errors.Errorf(errFmtRekorStatus, res.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	// Entries are returned keyed by their UUID.
+	entries := map[string]logEntry{}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, errors.Wrap(err, errRecordEntry)
+	}
+	for _, le := range entries {
+		out, err := json.Marshal(bundle{
+			SignedEntryTimestamp: le.Verification.SignedEntryTimestamp,
+			Payload: bundlePayload{
+				Body:           le.Body,
+				IntegratedTime: le.IntegratedTime,
+				LogID:          le.LogID,
+				LogIndex:       le.LogIndex,
+			},
+			InclusionProof: le.Verification.InclusionProof,
+		})
+		return out, errors.Wrap(err, errRecordEntry)
+	}
+	return nil, errors.New(errNoEntry)
+}
+
+// verifyBundle verifies that the supplied signature, made with the key of the
+// supplied certificate, was recorded in the transparency log with the
+// supplied key, and returns the time it was recorded at.
+func verifyBundle(key crypto.PublicKey, sig *Signature, cert *x509.Certificate) (time.Time, error) { //nolint:gocyclo
+	if len(sig.Bundle) == 0 {
+		return time.Time{}, errors.New(errNoBundle)
+	}
+	b := bundle{}
+	if err := json.Unmarshal(sig.Bundle, &b); err != nil {
+		return time.Time{}, errors.Wrap(err, errParseBundle)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, errLogKey)
+	}
+	id := sha256.Sum256(der)
+	if b.Payload.LogID != hex.EncodeToString(id[:]) {
+		return time.Time{}, errors.Errorf(errFmtLogID, b.Payload.LogID)
+	}
+	set, err := json.Marshal(b.Payload)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, errParseBundle)
+	}
+	if err := verifyWith(key, set, b.SignedEntryTimestamp); err != nil {
+		return time.Time{}, errors.Wrap(err, errVerifySET)
+	}
+
+	body, err := base64.StdEncoding.DecodeString(b.Payload.Body)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, errParseBundle)
+	}
+	if err := checkEntry(body, sig, cert); err != nil {
+		return time.Time{}, err
+	}
+	if b.InclusionProof == nil {
+		return time.Time{}, errors.New(errNoInclusionProof)
+	}
+	if err := verifyInclusion(key, id[:4], body, b.InclusionProof); err != nil {
+		return time.Time{}, err
+	}
+
+	// The certificate is only valid for a few minutes. The log vouches that
+	// the signature existed while it was.
+	t := time.Unix(b.Payload.IntegratedTime, 0)
+	if t.Before(cert.NotBefore) || t.After(cert.NotAfter) {
+		return time.Time{}, errors.Errorf(errFmtIntegratedTime, t.UTC())
+	}
+	return t, nil
+}
+
+// checkEntry returns an error if the supplied log entry body does not record
+// the supplied signature made with the key of the supplied certificate.
+func checkEntry(body []byte, sig *Signature, cert *x509.Certificate) error {
+	e := hashedRekord{}
+	if err := json.Unmarshal(body, &e); err != nil {
+		return errors.Wrap(err, errParseBundle)
+	}
+	h := sha256.Sum256(sig.Payload)
+	p, _ := pem.Decode(e.Spec.Signature.PublicKey.Content)
+	switch {
+	case e.Kind != kindHashedRekord,
+		e.Spec.Data.Hash.Algorithm != hashAlgorithmSHA256,
+		e.Spec.Data.Hash.Value != hex.EncodeToString(h[:]),
+		!bytes.Equal(e.Spec.Signature.Content, sig.Signature),
+		p == nil || !bytes.Equal(p.Bytes, cert.Raw):
+		return errors.New(errEntryMismatch)
+	}
+	return nil
+}
+
+// verifyInclusion verifies that the supplied inclusion proof proves the
+// supplied entry body is included in the tree committed to by a checkpoint
+// signed with the supplied key, identified by the supplied hint.
+func verifyInclusion(key crypto.PublicKey, hint, body []byte, p *inclusionProof) error {
+	root, err := hex.DecodeString(p.RootHash)
+	if err != nil {
+		return errors.Wrap(err, errParseProof)
+	}
+	proof := make([][]byte, len(p.Hashes))
+	for i, h := range p.Hashes {
+		if proof[i], err = hex.DecodeString(h); err != nil {
+			return errors.Wrap(err, errParseProof)
+		}
+	}
+	got, err := rootFromInclusionProof(p.LogIndex, p.TreeSize, leafHash(body), proof)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, root) {
+		return errors.New(errProofRoot)
+	}
+	return verifyCheckpoint(key, hint, p.Checkpoint, p.TreeSize, root)
+}
+
+func leafHash(leaf []byte) []byte {
+	h := sha256.Sum256(append([]byte{leafHashPrefix}, leaf...))
+	return h[:]
+}
+
+func nodeHash(l, r []byte) []byte {
+	b := append([]byte{nodeHashPrefix}, l...)
+	h := sha256.Sum256(append(b, r...))
+	return h[:]
+}
+
+// rootFromInclusionProof returns the root of a tree of the supplied size,
+// computed from the supplied hash of the leaf at the supplied index and its
+// inclusion proof, as specified by RFC 9162 section 2.1.3.2.
+func rootFromInclusionProof(index, size int64, leaf []byte, proof [][]byte) ([]byte, error) {
+	if index < 0 || index >= size {
+		return nil, errors.Errorf(errFmtProofIndex, index, size)
+	}
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return nil, errors.New(errProofLength)
+		}
+		if fn%2 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return nil, errors.New(errProofLength)
+	}
+	return r, nil
+}
+
+// verifyCheckpoint verifies that the supplied checkpoint commits to a tree of
+// the supplied size and root, and is signed with the supplied key. A
+// checkpoint is a signed note: an origin, tree size and base64 encoded root
+// hash on separate lines, followed by a blank line and a line per signature.
+func verifyCheckpoint(key crypto.PublicKey, hint []byte, cp string, size int64, root []byte) error {
+	i := strings.Index(cp, "\n\n")
+	if i < 0 {
+		return errors.New(errParseCheckpoint)
+	}
+	note, sigs := cp[:i+1], cp[i+2:]
+	lines := strings.Split(note, "\n")
+	if len(lines) < 4 {
+		return errors.New(errParseCheckpoint)
+	}
+	n, err := strconv.ParseInt(lines[1], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, errParseCheckpoint)
+	}
+	r, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return errors.Wrap(err, errParseCheckpoint)
+	}
+	if n != size || !bytes.Equal(r, root) {
+		return errors.New(errCheckpointMismatch)
+	}
+
+	for _, l := range strings.Split(sigs, "\n") {
+		f := strings.Fields(strings.TrimPrefix(l, checkpointSigPrefix))
+		if !strings.HasPrefix(l, checkpointSigPrefix) || len(f) != 2 {
+			continue
+		}
+		s, err := base64.StdEncoding.DecodeString(f[1])
+		if err != nil || len(s) <= len(hint) || !bytes.Equal(s[:len(hint)], hint) {
+			continue
+		}
+		if verifyWith(key, []byte(note), s[len(hint):]) == nil {
+			return nil
+		}
+	}
+	return errors.New(errVerifyCheckpoint)
+}
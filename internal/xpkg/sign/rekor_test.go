@@ -0,0 +1,201 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// treeHead returns the RFC 6962 Merkle tree hash of the supplied leaf hashes.
+func treeHead(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(treeHead(leaves[:k]), treeHead(leaves[k:]))
+}
+
+// treePath returns the RFC 6962 inclusion proof of the leaf at the supplied
+// index.
+func treePath(index int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(treePath(index, leaves[:k]), treeHead(leaves[k:]))
+	}
+	return append(treePath(index-k, leaves[k:]), treeHead(leaves[:k]))
+}
+
+// split returns the largest power of two smaller than n.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// fakeRekor is a transparency log that records entries in an RFC 6962 Merkle
+// tree and signs its checkpoints and entry timestamps with the supplied key.
+func fakeRekor(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := sha256.Sum256(der)
+
+	mu := sync.Mutex{}
+	// Start with a few entries so that inclusion proofs are not trivial.
+	leaves := [][]byte{leafHash([]byte("a")), leafHash([]byte("b")), leafHash([]byte("c"))}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != rekorEntriesPath {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &hashedRekord{}); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		index := len(leaves)
+		leaves = append(leaves, leafHash(body))
+		root := treeHead(leaves)
+		note := fmt.Sprintf("rekor.example.org - 1\n%d\n%s\n", len(leaves), base64.StdEncoding.EncodeToString(root))
+		sig, err := signWith(key, []byte(note))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		proof := &inclusionProof{
+			Checkpoint: fmt.Sprintf("%s\n%srekor.example.org %s\n", note, checkpointSigPrefix, base64.StdEncoding.EncodeToString(append(id[:4:4], sig...))),
+			LogIndex:   int64(index),
+			RootHash:   hex.EncodeToString(root),
+			TreeSize:   int64(len(leaves)),
+		}
+		for _, h := range treePath(index, leaves) {
+			proof.Hashes = append(proof.Hashes, hex.EncodeToString(h))
+		}
+
+		le := logEntry{
+			Body:           base64.StdEncoding.EncodeToString(body),
+			IntegratedTime: time.Now().Unix(),
+			LogID:          hex.EncodeToString(id[:]),
+			// The index in the log may differ from the index in the tree of
+			// the shard the entry was recorded in.
+			LogIndex: int64(index) + 1000,
+		}
+		set, _ := json.Marshal(bundlePayload{Body: le.Body, IntegratedTime: le.IntegratedTime, LogID: le.LogID, LogIndex: le.LogIndex})
+		if le.Verification.SignedEntryTimestamp, err = signWith(key, set); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		le.Verification.InclusionProof = proof
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]logEntry{hex.EncodeToString(leafHash(body)): le})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRootFromInclusionProof(t *testing.T) {
+	for size := 1; size <= 9; size++ {
+		leaves := make([][]byte, size)
+		for i := range leaves {
+			leaves[i] = leafHash([]byte{byte(i)})
+		}
+		root := treeHead(leaves)
+		for i := range leaves {
+			got, err := rootFromInclusionProof(int64(i), int64(size), leaves[i], treePath(i, leaves))
+			if err != nil {
+				t.Errorf("rootFromInclusionProof(%d, %d, ...): %v", i, size, err)
+				continue
+			}
+			if hex.EncodeToString(got) != hex.EncodeToString(root) {
+				t.Errorf("rootFromInclusionProof(%d, %d, ...): want root %x, got %x", i, size, root, got)
+			}
+		}
+	}
+
+	leaves := [][]byte{leafHash([]byte("a")), leafHash([]byte("b")), leafHash([]byte("c"))}
+	root := treeHead(leaves)
+	cases := map[string]struct {
+		reason string
+		index  int64
+		size   int64
+		leaf   []byte
+		proof  [][]byte
+	}{
+		"WrongLeaf": {
+			reason: "A proof should not prove the inclusion of another leaf.",
+			index:  1,
+			size:   3,
+			leaf:   leafHash([]byte("z")),
+			proof:  treePath(1, leaves),
+		},
+		"WrongIndex": {
+			reason: "A proof should not prove the inclusion of a leaf at another index.",
+			index:  0,
+			size:   3,
+			leaf:   leaves[1],
+			proof:  treePath(1, leaves),
+		},
+		"ShortProof": {
+			reason: "A proof missing hashes should be rejected.",
+			index:  1,
+			size:   3,
+			leaf:   leaves[1],
+			proof:  treePath(1, leaves)[:1],
+		},
+		"IndexOutOfRange": {
+			reason: "A proof for an index outside the tree should be rejected.",
+			index:  3,
+			size:   3,
+			leaf:   leaves[1],
+			proof:  treePath(1, leaves),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := rootFromInclusionProof(tc.index, tc.size, tc.leaf, tc.proof)
+			if err == nil && hex.EncodeToString(got) == hex.EncodeToString(root) {
+				t.Errorf("\n%s\nrootFromInclusionProof(...): want error or other root", tc.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sign signs package images and verifies their signatures. Signatures
+// are stored in the same format as cosign, so packages signed with cosign can
+// be verified and vice versa.
+package sign
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// SimpleSigningMediaType is the media type of signature payload layers.
+	SimpleSigningMediaType types.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SignatureAnnotation is the layer annotation holding the base64 encoded
+	// signature of the payload.
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// CertificateAnnotation is the layer annotation holding the PEM encoded
+	// signing certificate for keyless signatures.
+	CertificateAnnotation = "dev.sigstore.cosign/certificate"
+	// ChainAnnotation is the layer annotation holding the PEM encoded
+	// certificate chain for keyless signatures.
+	ChainAnnotation = "dev.sigstore.cosign/chain"
+	// BundleAnnotation is the layer annotation holding the JSON encoded proof
+	// that a keyless signature was recorded in the transparency log.
+	BundleAnnotation = "dev.sigstore.cosign/bundle"
+
+	payloadType = "cosign container image signature"
+	sigTagFmt   = "%s-%s.sig"
+
+	errResolveDigest   = "failed to resolve package digest"
+	errMarshalPayload  = "failed to marshal signature payload"
+	errSignPayload     = "failed to sign payload"
+	errFetchSignatures = "failed to fetch signatures"
+	errBuildSignatures = "failed to build signature image"
+	errPushSignatures  = "failed to push signatures"
+)
+
+// A Signer signs payloads.
+type Signer interface {
+	// Sign returns the signature of the supplied payload and, for keyless
+	// signers, the PEM encoded signing certificate, its chain, and the proof
+	// that the signature was recorded in the transparency log.
+	Sign(ctx context.Context, payload []byte) (*Signature, error)
+}
+
+// A Signature of a payload.
+type Signature struct {
+	Payload     []byte
+	Signature   []byte
+	Certificate []byte
+	Chain       []byte
+	Bundle      []byte
+}
+
+// Payload is the simple signing payload that is signed for a package.
+type Payload struct {
+	Critical Critical       `json:"critical"`
+	Optional map[string]any `json:"optional"`
+}
+
+// Critical holds the signed identity of a package.
+type Critical struct {
+	Identity Identity `json:"identity"`
+	Image    Image    `json:"image"`
+	Type     string   `json:"type"`
+}
+
+// Identity is the repository a signature was created for.
+type Identity struct {
+	DockerReference string `json:"docker-reference"`
+}
+
+// Image is the digest of the signed package.
+type Image struct {
+	DockerManifestDigest string `json:"docker-manifest-digest"`
+}
+
+// SignatureTag returns the tag signatures for the supplied digest are stored
+// at.
+func SignatureTag(d name.Digest) (name.Tag, error) {
+	h, err := v1.NewHash(d.DigestStr())
+	if err != nil {
+		return name.Tag{}, err
+	}
+	return d.Context().Tag(fmt.Sprintf(sigTagFmt, h.Algorithm, h.Hex)), nil
+}
+
+// Resolve returns the digest the supplied reference currently points to.
+func Resolve(ref name.Reference, opts ...remote.Option) (name.Digest, error) {
+	if d, ok := ref.(name.Digest); ok {
+		return d, nil
+	}
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errResolveDigest)
+	}
+	return ref.Context().Digest(desc.Digest.String()), nil
+}
+
+// Sign signs the package the supplied reference points to and pushes the
+// signature to its repository. Existing signatures are kept.
+func Sign(ctx context.Context, ref name.Reference, s Signer, opts ...remote.Option) (name.Digest, error) {
+	opts = append(opts, remote.WithContext(ctx))
+	d, err := Resolve(ref, opts...)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	payload, err := json.Marshal(Payload{Critical: Critical{
+		Identity: Identity{DockerReference: d.Context().Name()},
+		Image:    Image{DockerManifestDigest: d.DigestStr()},
+		Type:     payloadType,
+	}})
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errMarshalPayload)
+	}
+	sig, err := s.Sign(ctx, payload)
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errSignPayload)
+	}
+
+	tag, err := SignatureTag(d)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	img, err := signatures(tag, opts...)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	ann := map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(sig.Signature)}
+	if len(sig.Certificate) > 0 {
+		ann[CertificateAnnotation] = string(sig.Certificate)
+		ann[ChainAnnotation] = string(sig.Chain)
+	}
+	if len(sig.Bundle) > 0 {
+		ann[BundleAnnotation] = string(sig.Bundle)
+	}
+	img, err = mutate.Append(img, mutate.Addendum{
+		Layer:       static.NewLayer(payload, SimpleSigningMediaType),
+		Annotations: ann,
+	})
+	if err != nil {
+		return name.Digest{}, errors.Wrap(err, errBuildSignatures)
+	}
+	return d, errors.Wrap(remote.Write(tag, img, opts...), errPushSignatures)
+}
+
+// signatures returns the signature image at the supplied tag, or an empty
+// signature image if none exists.
+func signatures(tag name.Tag, opts ...remote.Option) (v1.Image, error) {
+	img, err := remote.Image(tag, opts...)
+	if isNotFound(err) {
+		return mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON), nil
+	}
+	return img, errors.Wrap(err, errFetchSignatures)
+}
+
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
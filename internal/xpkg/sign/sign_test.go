@@ -0,0 +1,420 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// pushPackage pushes a random package to a test registry and returns its
+// reference.
+func pushPackage(t *testing.T) name.Reference {
+	t.Helper()
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	ref, err := name.NewTag(u.Host+"/acme/platform:v1.0.0", name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSignVerifyKey(t *testing.T) {
+	ctx := context.Background()
+	ref := pushPackage(t)
+	key, other := newKey(t), newKey(t)
+
+	if _, err := Verify(ctx, ref, []Verifier{NewKeyVerifier(key.Public())}); !IsUnsigned(err) {
+		t.Errorf("Verify(...): want unsigned error before signing, got %v", err)
+	}
+
+	d, err := Sign(ctx, ref, NewKeySigner(key))
+	if err != nil {
+		t.Fatalf("Sign(...): %v", err)
+	}
+	// Signatures are appended, so signing twice keeps the first signature.
+	if _, err := Sign(ctx, ref, NewKeySigner(other)); err != nil {
+		t.Fatalf("Sign(...): %v", err)
+	}
+
+	for name, k := range map[string]*ecdsa.PrivateKey{"First": key, "Second": other} {
+		got, err := Verify(ctx, ref, []Verifier{NewKeyVerifier(k.Public())})
+		if err != nil {
+			t.Errorf("%s: Verify(...): %v", name, err)
+		}
+		if got != d {
+			t.Errorf("%s: Verify(...): want digest %s, got %s", name, d, got)
+		}
+	}
+
+	_, err = Verify(ctx, ref, []Verifier{NewKeyVerifier(newKey(t).Public())})
+	if err == nil || IsUnsigned(err) {
+		t.Errorf("Verify(...): want untrusted error for unknown key, got %v", err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	ctx := context.Background()
+	ref := pushPackage(t)
+	key := newKey(t)
+	vs := []Verifier{NewKeyVerifier(key.Public())}
+
+	d, err := Resolve(ref)
+	if err != nil {
+		t.Fatalf("Resolve(...): %v", err)
+	}
+
+	warned := false
+	warn := &Policy{Mode: PolicyWarn, Verifiers: vs, Warn: func(error) { warned = true }}
+	got, err := warn.Check(ctx, ref)
+	if err != nil {
+		t.Errorf("Check(...): warn policy should admit unsigned package, got %v", err)
+	}
+	if !warned {
+		t.Errorf("Check(...): warn policy should warn about unsigned package")
+	}
+	if got != d {
+		t.Errorf("Check(...): warn policy should pin the resolved digest %s, got %s", d, got)
+	}
+
+	enforce := &Policy{Mode: PolicyEnforce, Verifiers: vs}
+	if _, err := enforce.Check(ctx, ref); !IsUnsigned(err) {
+		t.Errorf("Check(...): enforce policy should refuse unsigned package, got %v", err)
+	}
+
+	if _, err := Sign(ctx, ref, NewKeySigner(key)); err != nil {
+		t.Fatalf("Sign(...): %v", err)
+	}
+	got, err = enforce.Check(ctx, ref)
+	if err != nil {
+		t.Errorf("Check(...): enforce policy should admit signed package, got %v", err)
+	}
+	if got != d {
+		t.Errorf("Check(...): enforce policy should pin the verified digest %s, got %s", d, got)
+	}
+
+	ignore := &Policy{Mode: PolicyIgnore}
+	got, err = ignore.Check(ctx, ref)
+	if err != nil {
+		t.Errorf("Check(...): ignore policy should admit unsigned package, got %v", err)
+	}
+	if got != ref {
+		t.Errorf("Check(...): ignore policy should not resolve the reference, got %s", got)
+	}
+}
+
+func TestLoadPrivateKeyEncrypted(t *testing.T) {
+	key := newKey(t)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Encrypt the key the way cosign does.
+	password := []byte("hunter2")
+	ek := encryptedKey{}
+	ek.KDF.Name = kdfScrypt
+	ek.KDF.Params.N, ek.KDF.Params.R, ek.KDF.Params.P = 1024, 8, 1
+	ek.KDF.Salt = []byte("0123456789abcdef0123456789abcdef")
+	ek.Cipher.Name = cipherSecretbox
+	ek.Cipher.Nonce = []byte("0123456789abcdef01234567")
+	sk, err := scrypt.Key(password, ek.KDF.Salt, ek.KDF.Params.N, ek.KDF.Params.R, ek.KDF.Params.P, secretboxKeySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k [32]byte
+	var nonce [24]byte
+	copy(k[:], sk)
+	copy(nonce[:], ek.Cipher.Nonce)
+	ek.Ciphertext = secretbox.Seal(nil, der, &nonce, &k)
+	b, _ := json.Marshal(ek)
+	p := pem.EncodeToMemory(&pem.Block{Type: pemEncryptedKey, Bytes: b})
+
+	got, err := LoadPrivateKey(p, password)
+	if err != nil {
+		t.Fatalf("LoadPrivateKey(...): %v", err)
+	}
+	if !key.Equal(got) {
+		t.Errorf("LoadPrivateKey(...): decrypted key does not match")
+	}
+	if _, err := LoadPrivateKey(p, []byte("wrong")); err == nil {
+		t.Errorf("LoadPrivateKey(...): want error for wrong password")
+	}
+}
+
+// fakeFulcio is a certificate authority that issues code signing
+// certificates for the email and issuer in the identity token.
+func fakeFulcio(t *testing.T, issuer string) (*httptest.Server, *x509.CertPool) {
+	t.Helper()
+	caKey := newKey(t)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake-fulcio"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := fulcioRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		email, err := tokenSubject(req.Credentials.OIDCIdentityToken)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p, _ := pem.Decode([]byte(req.PublicKeyRequest.PublicKey.Content))
+		pub, err := x509.ParsePKIXPublicKey(p.Bytes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := verifyWith(pub, []byte(email), req.PublicKeyRequest.ProofOfPossession); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		iss, _ := asn1.Marshal(issuer)
+		tmpl := &x509.Certificate{
+			SerialNumber:    big.NewInt(2),
+			NotBefore:       time.Now().Add(-time.Minute),
+			NotAfter:        time.Now().Add(10 * time.Minute),
+			EmailAddresses:  []string{email},
+			KeyUsage:        x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: iss}},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, pub, caKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res := fulcioResponse{Embedded: &fulcioChain{}}
+		res.Embedded.Chain.Certificates = []string{
+			string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, roots
+}
+
+func identityToken(email string) string {
+	claims, _ := json.Marshal(map[string]string{"email": email, "sub": "1234"})
+	return "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+}
+
+func TestSignVerifyKeyless(t *testing.T) {
+	ctx := context.Background()
+	ref := pushPackage(t)
+	issuer := "https://accounts.example.org"
+	fulcio, roots := fakeFulcio(t, issuer)
+	rekorKey := newKey(t)
+	rekor := fakeRekor(t, rekorKey)
+
+	s := NewKeylessSigner(identityToken("dev@acme.io"), WithFulcioURL(fulcio.URL), WithRekorURL(rekor.URL))
+	if _, err := Sign(ctx, ref, s); err != nil {
+		t.Fatalf("Sign(...): %v", err)
+	}
+
+	cases := map[string]struct {
+		reason   string
+		identity CertificateIdentity
+		roots    *x509.CertPool
+		rekor    *ecdsa.PrivateKey
+		trusted  bool
+	}{
+		"TrustedIdentity": {
+			reason:   "A signature by a trusted identity recorded in the trusted log should be trusted.",
+			identity: CertificateIdentity{Subject: "dev@acme.io", Issuer: issuer},
+			roots:    roots,
+			rekor:    rekorKey,
+			trusted:  true,
+		},
+		"UntrustedSubject": {
+			reason:   "A signature by another subject should not be trusted.",
+			identity: CertificateIdentity{Subject: "ops@acme.io", Issuer: issuer},
+			roots:    roots,
+			rekor:    rekorKey,
+		},
+		"UntrustedIssuer": {
+			reason:   "A signature by the subject authenticated by another issuer should not be trusted.",
+			identity: CertificateIdentity{Subject: "dev@acme.io", Issuer: "https://evil.example.org"},
+			roots:    roots,
+			rekor:    rekorKey,
+		},
+		"UntrustedRoot": {
+			reason:   "A signature with a certificate from another authority should not be trusted.",
+			identity: CertificateIdentity{Subject: "dev@acme.io", Issuer: issuer},
+			roots:    x509.NewCertPool(),
+			rekor:    rekorKey,
+		},
+		"UntrustedLog": {
+			reason:   "A signature recorded in another transparency log should not be trusted.",
+			identity: CertificateIdentity{Subject: "dev@acme.io", Issuer: issuer},
+			roots:    roots,
+			rekor:    newKey(t),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Verify(ctx, ref, []Verifier{NewKeylessVerifier(tc.roots, tc.rekor.Public(), tc.identity)})
+			if tc.trusted && err != nil {
+				t.Errorf("\n%s\nVerify(...): %v", tc.reason, err)
+			}
+			if !tc.trusted && err == nil {
+				t.Errorf("\n%s\nVerify(...): want error", tc.reason)
+			}
+		})
+	}
+}
+
+func TestKeylessVerifierBundle(t *testing.T) {
+	ctx := context.Background()
+	issuer := "https://accounts.example.org"
+	fulcio, roots := fakeFulcio(t, issuer)
+	rekorKey := newKey(t)
+	rekor := fakeRekor(t, rekorKey)
+
+	s := NewKeylessSigner(identityToken("dev@acme.io"), WithFulcioURL(fulcio.URL), WithRekorURL(rekor.URL))
+	sig, err := s.Sign(ctx, []byte("payload"))
+	if err != nil {
+		t.Fatalf("Sign(...): %v", err)
+	}
+	other, err := s.Sign(ctx, []byte("other"))
+	if err != nil {
+		t.Fatalf("Sign(...): %v", err)
+	}
+	v := NewKeylessVerifier(roots, rekorKey.Public(), CertificateIdentity{Subject: "dev@acme.io", Issuer: issuer})
+
+	// withBundle returns a copy of sig whose bundle is modified by fn.
+	withBundle := func(fn func(b *bundle)) *Signature {
+		b := bundle{}
+		if err := json.Unmarshal(sig.Bundle, &b); err != nil {
+			t.Fatal(err)
+		}
+		fn(&b)
+		s := *sig
+		s.Bundle, _ = json.Marshal(b)
+		return &s
+	}
+
+	cases := map[string]struct {
+		reason  string
+		sig     *Signature
+		trusted bool
+	}{
+		"Recorded": {
+			reason:  "A signature recorded in the trusted log should be trusted.",
+			sig:     sig,
+			trusted: true,
+		},
+		"NoBundle": {
+			reason: "A signature that was not recorded in the log should not be trusted.",
+			sig:    &Signature{Payload: sig.Payload, Signature: sig.Signature, Certificate: sig.Certificate, Chain: sig.Chain},
+		},
+		"OtherEntry": {
+			reason: "A signature should not be trusted with the log entry of another signature.",
+			sig:    &Signature{Payload: sig.Payload, Signature: sig.Signature, Certificate: sig.Certificate, Chain: sig.Chain, Bundle: other.Bundle},
+		},
+		"NoInclusionProof": {
+			reason: "A signature whose log entry has no inclusion proof should not be trusted.",
+			sig:    withBundle(func(b *bundle) { b.InclusionProof = nil }),
+		},
+		"TamperedProof": {
+			reason: "A signature whose inclusion proof does not lead to the checkpoint's root should not be trusted.",
+			sig: withBundle(func(b *bundle) {
+				b.InclusionProof.Hashes[0] = hex.EncodeToString(leafHash([]byte("z")))
+			}),
+		},
+		"UnsignedCheckpoint": {
+			reason: "A signature whose checkpoint is not signed by the log should not be trusted.",
+			sig: withBundle(func(b *bundle) {
+				b.InclusionProof.Checkpoint = strings.SplitAfterN(b.InclusionProof.Checkpoint, "\n\n", 2)[0]
+			}),
+		},
+		"BackdatedEntry": {
+			reason: "A signature whose log entry timestamp was changed should not be trusted.",
+			sig:    withBundle(func(b *bundle) { b.Payload.IntegratedTime -= 3600 }),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := v.Verify(ctx, tc.sig)
+			if tc.trusted && err != nil {
+				t.Errorf("\n%s\nVerify(...): %v", tc.reason, err)
+			}
+			if !tc.trusted && err == nil {
+				t.Errorf("\n%s\nVerify(...): want error", tc.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const (
+	errFmtUnsigned      = "package %s is not signed"
+	errFmtUntrusted     = "package %s has no trusted signature"
+	errNoVerifiers      = "no signature verifiers configured"
+	errReadPayload      = "failed to read signature payload"
+	errParsePayload     = "failed to parse signature payload"
+	errDecodeSignature  = "failed to decode signature"
+	errFmtPayloadDigest = "signature is for digest %s"
+	errFmtUnknownPolicy = "unknown signature policy %q"
+	errPolicyRejected   = "signature policy rejected package"
+)
+
+// A Verifier verifies signatures.
+type Verifier interface {
+	// Verify returns an error if the supplied signature is not trusted.
+	Verify(ctx context.Context, sig *Signature) error
+}
+
+type unsignedError struct{ error }
+
+// IsUnsigned returns true if the supplied error indicates a package has no
+// signatures.
+func IsUnsigned(err error) bool {
+	return errors.As(err, &unsignedError{})
+}
+
+// Verify verifies that the package the supplied reference points to has a
+// signature trusted by at least one of the supplied verifiers, and returns its
+// digest.
+func Verify(ctx context.Context, ref name.Reference, verifiers []Verifier, opts ...remote.Option) (name.Digest, error) { //nolint:gocyclo
+	if len(verifiers) == 0 {
+		return name.Digest{}, errors.New(errNoVerifiers)
+	}
+	opts = append(opts, remote.WithContext(ctx))
+	d, err := Resolve(ref, opts...)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	tag, err := SignatureTag(d)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	img, err := remote.Image(tag, opts...)
+	if isNotFound(err) {
+		return d, unsignedError{errors.Errorf(errFmtUnsigned, d)}
+	}
+	if err != nil {
+		return d, errors.Wrap(err, errFetchSignatures)
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return d, errors.Wrap(err, errFetchSignatures)
+	}
+	if len(m.Layers) == 0 {
+		return d, unsignedError{errors.Errorf(errFmtUnsigned, d)}
+	}
+
+	var errs []error
+	for _, desc := range m.Layers {
+		l, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return d, errors.Wrap(err, errReadPayload)
+		}
+		sig, err := signature(l, desc.Annotations)
+		if err == nil {
+			err = checkPayload(sig.Payload, d)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, v := range verifiers {
+			if err := v.Verify(ctx, sig); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			return d, nil
+		}
+	}
+	return d, errors.Wrapf(errors.Join(errs...), errFmtUntrusted, d)
+}
+
+// signature returns the signature stored in the supplied layer.
+func signature(l v1.Layer, ann map[string]string) (*Signature, error) {
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, errors.Wrap(err, errReadPayload)
+	}
+	defer rc.Close() //nolint:errcheck
+	payload, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadPayload)
+	}
+	s, err := base64.StdEncoding.DecodeString(ann[SignatureAnnotation])
+	if err != nil {
+		return nil, errors.Wrap(err, errDecodeSignature)
+	}
+	return &Signature{
+		Payload:     payload,
+		Signature:   s,
+		Certificate: []byte(ann[CertificateAnnotation]),
+		Chain:       []byte(ann[ChainAnnotation]),
+		Bundle:      []byte(ann[BundleAnnotation]),
+	}, nil
+}
+
+// checkPayload returns an error if the supplied payload is not for the
+// supplied digest, preventing signatures from being copied between packages.
+func checkPayload(b []byte, d name.Digest) error {
+	p := Payload{}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return errors.Wrap(err, errParsePayload)
+	}
+	if p.Critical.Image.DockerManifestDigest != d.DigestStr() {
+		return errors.Errorf(errFmtPayloadDigest, p.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
+
+// PolicyMode determines how a Policy treats packages without a trusted
+// signature.
+type PolicyMode string
+
+// Policy modes.
+const (
+	// PolicyIgnore does not verify signatures.
+	PolicyIgnore PolicyMode = "ignore"
+	// PolicyWarn verifies signatures but only warns about packages without a
+	// trusted signature.
+	PolicyWarn PolicyMode = "warn"
+	// PolicyEnforce refuses packages without a trusted signature.
+	PolicyEnforce PolicyMode = "enforce"
+)
+
+// A Policy decides whether packages may be installed based on their
+// signatures.
+type Policy struct {
+	Mode      PolicyMode
+	Verifiers []Verifier
+	// Warn is called with the verification error of packages admitted by
+	// PolicyWarn.
+	Warn func(error)
+	// Options are used to talk to the registry.
+	Options []remote.Option
+}
+
+// Check returns an error if the policy refuses the package the supplied
+// reference points to. Otherwise it returns the reference the package should
+// be installed from: the digest that was verified, so that a tag can't be
+// repointed between verification and install, or the supplied reference if
+// signatures are ignored or the digest couldn't be resolved.
+func (p *Policy) Check(ctx context.Context, ref name.Reference) (name.Reference, error) {
+	switch p.Mode {
+	case PolicyIgnore, "":
+		return ref, nil
+	case PolicyWarn, PolicyEnforce:
+	default:
+		return nil, errors.Errorf(errFmtUnknownPolicy, p.Mode)
+	}
+	d, err := Verify(ctx, ref, p.Verifiers, p.Options...)
+	if err == nil {
+		return d, nil
+	}
+	if p.Mode == PolicyWarn {
+		if p.Warn != nil {
+			p.Warn(err)
+		}
+		if d.DigestStr() == "" {
+			return ref, nil
+		}
+		return d, nil
+	}
+	return nil, errors.Wrap(err, errPolicyRejected)
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/pkg/validation/apiextensions/v1/composition"
 
 	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
 	icompositions "github.com/crossplane/crossplane/controller/apiextensions/compositions"
@@ -48,13 +50,21 @@ const (
 // CompositionValidator defines a validator for compositions.
 type CompositionValidator struct {
 	s          *Snapshot
+	schema     *composition.Validator
 	validators []compositionValidator
 }
 
 // DefaultCompositionValidators returns a new Composition validator.
 func DefaultCompositionValidators(s *Snapshot) (validator.Validator, error) {
+	// Compositions are validated logically while rendering them, so only
+	// validate them against the schemas of the resources they reference.
+	sv, err := composition.NewValidator(composition.WithCRDGetter(&crdGetter{s: s}), composition.WithoutLogicalValidation())
+	if err != nil {
+		return nil, err
+	}
 	return &CompositionValidator{
-		s: s,
+		s:      s,
+		schema: sv,
 		validators: []compositionValidator{
 			NewPatchesValidator(s),
 		},
@@ -76,7 +86,11 @@ func (c *CompositionValidator) Validate(ctx context.Context, data any) *validate
 		comp.Spec.CompositeTypeRef.Kind,
 	)
 
-	r := icomposite.NewReconciler(resource.CompositeKind(compRefGVK), icomposite.WithLogger(c.s.log))
+	opts := []icomposite.ReconcilerOption{icomposite.WithLogger(c.s.log)}
+	if c.s.fn != nil {
+		opts = append(opts, icomposite.WithFunctionRunner(c.s.fn))
+	}
+	r := icomposite.NewReconciler(resource.CompositeKind(compRefGVK), opts...)
 	cds, err := r.Reconcile(ctx, comp)
 	if err != nil {
 		// some validation errors occur during reconciliation that we want to
@@ -92,9 +106,10 @@ func (c *CompositionValidator) Validate(ctx context.Context, data any) *validate
 	if len(errs) == 0 {
 		for i, cd := range cds {
 			for _, v := range c.validators {
-				errs = append(errs, v.validate(ctx, i, cd.Resource)...)
+				errs = append(errs, v.validate(ctx, i, cd)...)
 			}
 		}
+		errs = append(errs, c.validateSchemas(ctx, comp)...)
 	}
 
 	return &validate.Result{
@@ -102,6 +117,23 @@ func (c *CompositionValidator) Validate(ctx context.Context, data any) *validate
 	}
 }
 
+// validateSchemas validates the patches, readiness checks and connection
+// details of the supplied Composition against the schemas of its composite
+// resource and of the resources it composes, for example checking that field
+// paths exist and that transforms produce the types patches expect.
+func (c *CompositionValidator) validateSchemas(ctx context.Context, comp *xpextv1.Composition) []error {
+	_, ferrs := c.schema.Validate(ctx, comp)
+	errs := make([]error, len(ferrs))
+	for i, e := range ferrs {
+		errs[i] = &validator.Validation{
+			TypeCode: validator.ErrorTypeCode,
+			Message:  e.Error(),
+			Name:     e.Field,
+		}
+	}
+	return errs
+}
+
 func (c *CompositionValidator) marshal(data any) (*xpextv1.Composition, error) {
 	u, ok := data.(*unstructured.Unstructured)
 	if !ok {
@@ -129,7 +161,7 @@ func (c *CompositionValidator) marshal(data any) (*xpextv1.Composition, error) {
 }
 
 type compositionValidator interface {
-	validate(context.Context, int, resource.Composed) []error
+	validate(context.Context, int, icomposite.ComposedResourceState) []error
 }
 
 // PatchesValidator validates the patches fields of a Composition.
@@ -146,7 +178,8 @@ func NewPatchesValidator(s *Snapshot) *PatchesValidator {
 
 // Validate validates that the composed resource is valid per the base
 // resource's schema.
-func (p *PatchesValidator) validate(ctx context.Context, idx int, cd resource.Composed) []error {
+func (p *PatchesValidator) validate(ctx context.Context, idx int, cds icomposite.ComposedResourceState) []error {
+	cd := cds.Resource
 	cdgvk := cd.GetObjectKind().GroupVersionKind()
 	v, ok := p.s.validators[cdgvk]
 	if !ok {
@@ -155,12 +188,20 @@ func (p *PatchesValidator) validate(ctx context.Context, idx int, cd resource.Co
 
 	result := v.Validate(ctx, cd)
 	if result != nil {
+		targets := patchTargets(cds.Template)
 		errs := []error{}
 		for _, e := range result.Errors {
 			var ve *verrors.Validation
 			if !errors.As(e, &ve) {
 				return []error{fmt.Errorf(errIncorrectErrType)}
 			}
+			// Composed resources are rendered from a placeholder composite
+			// resource, so patches from fields of the composite resource
+			// are not applied. Don't report required fields that a patch
+			// would have set.
+			if ve.Code() == verrors.RequiredFailCode && isPatchTarget(targets, ve.Name) {
+				continue
+			}
 			ie := &validator.Validation{
 				TypeCode: ve.Code(),
 				Message:  fmt.Sprintf(errFmt, ve.Error(), cdgvk),
@@ -173,3 +214,40 @@ func (p *PatchesValidator) validate(ctx context.Context, idx int, cd resource.Co
 
 	return []error{fmt.Errorf(errInvalidValidationFmt, cdgvk)}
 }
+
+// patchTargets returns the field paths of the composed resource that the
+// patches of the supplied template write to.
+func patchTargets(t *xpextv1.ComposedTemplate) []string {
+	if t == nil {
+		return nil
+	}
+	targets := []string{}
+	for _, p := range t.Patches {
+		switch p.GetType() { //nolint:exhaustive
+		case xpextv1.PatchTypeFromCompositeFieldPath, xpextv1.PatchTypeFromEnvironmentFieldPath:
+			// The toFieldPath defaults to the fromFieldPath.
+			to := p.GetToFieldPath()
+			if to == "" {
+				to = p.GetFromFieldPath()
+			}
+			targets = append(targets, to)
+		case xpextv1.PatchTypeCombineFromComposite, xpextv1.PatchTypeCombineFromEnvironment:
+			targets = append(targets, p.GetToFieldPath())
+		}
+	}
+	return targets
+}
+
+// isPatchTarget returns true if the supplied field path, or one of its
+// parents, is one of the supplied patch targets.
+func isPatchTarget(targets []string, path string) bool {
+	for _, t := range targets {
+		if t == "" {
+			continue
+		}
+		if path == t || strings.HasPrefix(path, t+".") || strings.HasPrefix(path, t+"[") {
+			return true
+		}
+	}
+	return false
+}
@@ -374,6 +374,129 @@ func TestCompositionValidation(t *testing.T) {
 				},
 			},
 		},
+		"ComposedResourceRequiredFieldProvidedByCompositePatch": {
+			reason: "A required field patched from the composite resource is not reported as missing.",
+			args: args{
+				data: &v1.Composition{
+					TypeMeta: apimetav1.TypeMeta{
+						Kind:       v1.CompositionKind,
+						APIVersion: v1.SchemeGroupVersion.String(),
+					},
+					Spec: v1.CompositionSpec{
+						CompositeTypeRef: v1.TypeReference{
+							APIVersion: "example.org/v1alpha1",
+							Kind:       "XCertificate",
+						},
+						Resources: []v1.ComposedTemplate{
+							{
+								Base: runtime.RawExtension{Raw: []byte(`{
+									"apiVersion": "acm.aws.crossplane.io/v1alpha1",
+									"kind":"Certificate",
+									"spec": {
+										"forProvider": {
+											"domainName": "dn",
+											"region": "us-west-2",
+											"tags": [
+												{"key": "k", "value": "v"}
+											]
+										},
+										"writeConnectionSecretToRef": {
+											"namespace": "default"
+										}
+									}
+								}`)},
+								Patches: []v1.Patch{
+									{
+										Type:          v1.PatchTypeFromCompositeFieldPath,
+										FromFieldPath: pointer.String("spec.secretName"),
+										ToFieldPath:   pointer.String("spec.writeConnectionSecretToRef.name"),
+									},
+								},
+							},
+						},
+					},
+				},
+				validators: func() map[schema.GroupVersionKind]validator.Validator {
+					v, _ := s.validatorsFromBytes(ctx, testSingleVersionCRD)
+					x, _ := s.validatorsFromBytes(ctx, testXRD)
+					for gvk, val := range x {
+						v[gvk] = val
+					}
+					return v
+				}(),
+			},
+			want: want{
+				result: &validate.Result{Errors: []error{}},
+			},
+		},
+		"PatchToFieldPathNotInSchema": {
+			reason: "A patch to a field that is not in the composed resource schema is reported.",
+			args: args{
+				data: &v1.Composition{
+					TypeMeta: apimetav1.TypeMeta{
+						Kind:       v1.CompositionKind,
+						APIVersion: v1.SchemeGroupVersion.String(),
+					},
+					Spec: v1.CompositionSpec{
+						CompositeTypeRef: v1.TypeReference{
+							APIVersion: "example.org/v1alpha1",
+							Kind:       "XCertificate",
+						},
+						Resources: []v1.ComposedTemplate{
+							{
+								Base: runtime.RawExtension{Raw: []byte(`{
+									"apiVersion": "acm.aws.crossplane.io/v1alpha1",
+									"kind":"Certificate",
+									"spec": {
+										"forProvider": {
+											"domainName": "dn",
+											"region": "us-west-2",
+											"tags": [
+												{"key": "k", "value": "v"}
+											]
+										},
+										"writeConnectionSecretToRef": {
+											"namespace": "default"
+										}
+									}
+								}`)},
+								Patches: []v1.Patch{
+									{
+										Type:          v1.PatchTypeFromCompositeFieldPath,
+										FromFieldPath: pointer.String("spec.domain"),
+										ToFieldPath:   pointer.String("spec.forProvider.domainNam"),
+									},
+									{
+										Type:          v1.PatchTypeFromCompositeFieldPath,
+										FromFieldPath: pointer.String("spec.secretName"),
+										ToFieldPath:   pointer.String("spec.writeConnectionSecretToRef.name"),
+									},
+								},
+							},
+						},
+					},
+				},
+				validators: func() map[schema.GroupVersionKind]validator.Validator {
+					v, _ := s.validatorsFromBytes(ctx, testSingleVersionCRD)
+					x, _ := s.validatorsFromBytes(ctx, testXRD)
+					for gvk, val := range x {
+						v[gvk] = val
+					}
+					return v
+				}(),
+			},
+			want: want{
+				result: &validate.Result{
+					Errors: []error{
+						&validator.Validation{
+							TypeCode: validator.ErrorTypeCode,
+							Message:  `spec.resources[0].patches[0].toFieldPath: Invalid value: "spec.forProvider.domainNam": field 'domainNam' is not valid according to the schema`,
+							Name:     "spec.resources[0].patches[0].toFieldPath",
+						},
+					},
+				},
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -0,0 +1,89 @@
+// Copyright 2023 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/xcrd"
+)
+
+// addCRD records the CustomResourceDefinition defined by the supplied object,
+// if any, so that Compositions can be validated against it.
+func (s *Snapshot) addCRD(o runtime.Object) {
+	crd, err := crdForObj(o)
+	if err != nil || crd == nil {
+		return
+	}
+	if s.crds == nil {
+		s.crds = make(map[schema.GroupKind]apiextensions.CustomResourceDefinition)
+	}
+	s.crds[schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}] = *crd
+}
+
+// crdForObj returns the CustomResourceDefinition defined by the supplied
+// object. It returns nil if the object does not define one.
+func crdForObj(o runtime.Object) (*apiextensions.CustomResourceDefinition, error) {
+	internal := &apiextensions.CustomResourceDefinition{}
+	switch rd := o.(type) {
+	case *extv1beta1.CustomResourceDefinition:
+		if err := extv1beta1.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(rd, internal, nil); err != nil {
+			return nil, err
+		}
+	case *extv1.CustomResourceDefinition:
+		if err := extv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(rd, internal, nil); err != nil {
+			return nil, err
+		}
+	case *xpextv1.CompositeResourceDefinition:
+		crd, err := xcrd.ForCompositeResource(rd)
+		if err != nil {
+			return nil, err
+		}
+		if err := extv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(crd, internal, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return internal, nil
+}
+
+// crdGetter gets the CustomResourceDefinitions recorded by a Snapshot.
+type crdGetter struct {
+	s *Snapshot
+}
+
+// Get returns the CustomResourceDefinition of the supplied GroupKind. It
+// returns nil if the Snapshot has no such definition, so that validations
+// that depend on it are skipped.
+func (g *crdGetter) Get(_ context.Context, gk schema.GroupKind) (*apiextensions.CustomResourceDefinition, error) {
+	crd, ok := g.s.crds[gk]
+	if !ok {
+		return nil, nil
+	}
+	return &crd, nil
+}
+
+// GetAll returns all CustomResourceDefinitions recorded by the Snapshot.
+func (g *crdGetter) GetAll(_ context.Context) (map[schema.GroupKind]apiextensions.CustomResourceDefinition, error) {
+	return g.s.crds, nil
+}
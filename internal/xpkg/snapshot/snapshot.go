@@ -28,6 +28,7 @@ import (
 
 	"github.com/goccy/go-yaml/ast"
 
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -38,6 +39,7 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/token"
 	"github.com/golang/tools/lsp/protocol"
@@ -81,6 +83,9 @@ type Snapshot struct {
 	dm  DepManager
 	w   *workspace.Workspace
 	log logging.Logger
+	// fn runs Composition Functions when rendering Compositions in Pipeline
+	// mode. Pipelines are not rendered if it is nil.
+	fn icomposite.FunctionRunner
 
 	objScheme  *runtime.Scheme
 	metaScheme *runtime.Scheme
@@ -90,7 +95,10 @@ type Snapshot struct {
 	// validators includes validators for both the workspace as well as
 	// the external dependencies defined in the crossplane.yaml.
 	validators map[schema.GroupVersionKind]validator.Validator
-	wsview     *workspace.View
+	// crds includes the CustomResourceDefinitions defined by, or derived
+	// from, both the workspace and the external dependencies.
+	crds   map[schema.GroupKind]apiextensions.CustomResourceDefinition
+	wsview *workspace.View
 }
 
 // Factory is used to "stamp out" Snapshots while allowing
@@ -98,6 +106,7 @@ type Snapshot struct {
 type Factory struct {
 	log logging.Logger
 	m   DepManager
+	fn  icomposite.FunctionRunner
 
 	workdir string
 	// initialize the object scheme once for the factory as this won't change
@@ -146,9 +155,11 @@ func (f *Factory) New(ctx context.Context, opts ...Option) (*Snapshot, error) {
 		// log is not set to a default so that we can share the logger consistently
 		// with the corresponding subsystems.
 		log:        f.log,
+		fn:         f.fn,
 		objScheme:  f.objScheme,
 		metaScheme: f.metaScheme,
 		validators: make(map[schema.GroupVersionKind]validator.Validator),
+		crds:       make(map[schema.GroupKind]apiextensions.CustomResourceDefinition),
 	}
 
 	// use the manager instance from the Factory
@@ -204,6 +215,7 @@ func (s *Snapshot) init(ctx context.Context) error {
 		for _, pkg := range extView.Packages() {
 
 			for _, o := range pkg.Objects() {
+				s.addCRD(o)
 				validators, err := ValidatorsForObj(ctx, o, s)
 				if err != nil {
 					// skip adding the validator
@@ -244,6 +256,14 @@ func WithLogger(l logging.Logger) FactoryOption {
 	}
 }
 
+// WithFunctionRunner sets the FunctionRunner used to render Compositions in
+// Pipeline mode.
+func WithFunctionRunner(r icomposite.FunctionRunner) FactoryOption {
+	return func(f *Factory) {
+		f.fn = r
+	}
+}
+
 // Option modifies a Snapshot.
 type Option func(*Snapshot)
 
@@ -391,6 +411,7 @@ func (s *Snapshot) validatorsFromBytes(ctx context.Context, b []byte) (map[schem
 			}
 		}
 
+		s.addCRD(o)
 		validators, err := ValidatorsForObj(ctx, o, s)
 		if err != nil {
 			// skip YAML document if we cannot acquire validators for object
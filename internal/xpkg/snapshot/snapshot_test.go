@@ -34,11 +34,13 @@ import (
 var (
 	testSingleVersionCRD []byte
 	testMultiVersionCRD  []byte
+	testXRD              []byte
 )
 
 func init() {
 	testSingleVersionCRD, _ = afero.ReadFile(afero.NewOsFs(), "testdata/single-version-crd.yaml")
 	testMultiVersionCRD, _ = afero.ReadFile(afero.NewOsFs(), "testdata/multiple-version-crd.yaml")
+	testXRD, _ = afero.ReadFile(afero.NewOsFs(), "testdata/xrd.yaml")
 }
 
 func TestWSLoadValidators(t *testing.T) {